@@ -271,6 +271,10 @@ func NewImageBuilder(
 			builder.WorkingDir = instructions.Workdir
 		}
 
+		if instructions.User != "" {
+			builder.User = instructions.User
+		}
+
 		if len(instructions.Env) > 0 {
 			builder.Env = append(builder.Env, instructions.Env...)
 		}
@@ -12,11 +12,11 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/appbom"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/build"
-	//"github.com/slimtoolkit/slim/pkg/app/master/command/containerize"
-	//"github.com/slimtoolkit/slim/pkg/app/master/command/convert"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/containerize"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/convert"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/debug"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/dockerclipm"
-	//"github.com/slimtoolkit/slim/pkg/app/master/command/edit"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/edit"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/help"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/images"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/install"
@@ -26,7 +26,7 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/command/profile"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/registry"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/run"
-	//"github.com/slimtoolkit/slim/pkg/app/master/command/server"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/server"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/update"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/version"
 	"github.com/slimtoolkit/slim/pkg/app/master/command/vulnerability"
@@ -61,12 +61,12 @@ func registerCommands() {
 	help.RegisterCommand()
 	update.RegisterCommand()
 	install.RegisterCommand()
-	//edit.RegisterCommand() - not doing anything yet
+	edit.RegisterCommand()
 	probe.RegisterCommand()
-	//convert.RegisterCommand() - not doing anything yet
+	convert.RegisterCommand()
 	run.RegisterCommand()
-	//server.RegisterCommand() - not doing anything yet
-	//containerize.RegisterCommand() - not doing anything yet
+	server.RegisterCommand()
+	containerize.RegisterCommand()
 	dockerclipm.RegisterCommand()
 }
 
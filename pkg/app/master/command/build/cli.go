@@ -12,6 +12,7 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
 	"github.com/slimtoolkit/slim/pkg/app/master/config"
 	"github.com/slimtoolkit/slim/pkg/artifact"
+	"github.com/slimtoolkit/slim/pkg/sbom"
 )
 
 const (
@@ -96,6 +97,9 @@ var CLI = &cli.Command{
 		//Container Build Options
 		cflag(FlagImageBuildEngine),
 		cflag(FlagImageBuildArch),
+		cflag(FlagImageBuildBase),
+		cflag(FlagImageBuildPlatform),
+		cflag(FlagImageBuildOutputLayout),
 		cflag(FlagBuildFromDockerfile),
 		cflag(FlagDockerfileContext),
 		cflag(FlagTagFat),
@@ -164,11 +168,14 @@ var CLI = &cli.Command{
 		cflag(FlagExcludeMounts),
 		//"EXCLUDE" FLAGS - END
 		cflag(FlagObfuscateMetadata),
+		cflag(FlagHTTPProbeVerify),
 		command.Cflag(command.FlagContinueAfter),
 		command.Cflag(command.FlagUseLocalMounts),
 		command.Cflag(command.FlagUseSensorVolume),
 		command.Cflag(command.FlagRTAOnbuildBaseImage),
 		command.Cflag(command.FlagRTASourcePT),
+		command.Cflag(command.FlagSeccompMergeProfile),
+		command.Cflag(command.FlagSBOM),
 		//Sensor flags:
 		command.Cflag(command.FlagSensorIPCEndpoint),
 		command.Cflag(command.FlagSensorIPCMode),
@@ -734,9 +741,29 @@ var CLI = &cli.Command{
 
 		rtaOnbuildBaseImage := ctx.Bool(command.FlagRTAOnbuildBaseImage)
 		rtaSourcePT := ctx.Bool(command.FlagRTASourcePT)
+		seccompMergeProfiles := ctx.StringSlice(command.FlagSeccompMergeProfile)
+
+		sbomFormats, err := sbom.ParseFormats(ctx.StringSlice(command.FlagSBOM))
+		if err != nil {
+			xc.Out.Error("param.sbom", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
 
 		doObfuscateMetadata := ctx.Bool(FlagObfuscateMetadata)
 
+		doHTTPProbeVerify := ctx.Bool(FlagHTTPProbeVerify)
+		if doHTTPProbeVerify && !httpProbeOpts.Do {
+			doHTTPProbeVerify = false
+			xc.Out.Info("param.http.probe.verify",
+				ovars{
+					"message": "ignoring http-probe-verify because probing is disabled",
+				})
+		}
+
 		imageBuildEngine, err := getImageBuildEngine(ctx)
 		if err != nil {
 			xc.Out.Error("param.error.image-build-engine", err.Error())
@@ -757,6 +784,35 @@ var CLI = &cli.Command{
 			xc.Exit(-1)
 		}
 
+		if ctx.String(FlagImageBuildBase) != "" && imageBuildEngine != IBEInternal {
+			xc.Out.Error("param.error.image-build-base", "base images are supported only with the internal image build engine")
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
+		imageBuildPlatforms, err := getImageBuildPlatforms(ctx)
+		if err != nil {
+			xc.Out.Error("param.error.image-build-platform", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
+		imageBuildOutputLayout := ctx.String(FlagImageBuildOutputLayout)
+		if (len(imageBuildPlatforms) > 0 || imageBuildOutputLayout != "") && imageBuildEngine != IBEInternal {
+			xc.Out.Error("param.error.image-build-platform", "multi-platform images and OCI image layout outputs are supported only with the internal image build engine")
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
 		OnCommand(
 			xc,
 			gparams,
@@ -835,15 +891,21 @@ var CLI = &cli.Command{
 			execCmd,
 			string(execFileCmd),
 			deleteFatImage,
+			doHTTPProbeVerify,
 			rtaOnbuildBaseImage,
 			rtaSourcePT,
+			seccompMergeProfiles,
+			sbomFormats,
 			doObfuscateMetadata,
 			ctx.String(command.FlagSensorIPCEndpoint),
 			ctx.String(command.FlagSensorIPCMode),
 			kubeOpts,
 			GetAppNodejsInspectOptions(ctx),
 			imageBuildEngine,
-			imageBuildArch)
+			imageBuildArch,
+			ctx.String(FlagImageBuildBase),
+			imageBuildPlatforms,
+			imageBuildOutputLayout)
 
 		return nil
 	},
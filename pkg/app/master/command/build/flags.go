@@ -8,6 +8,9 @@ import (
 
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
 	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/imagebuilder"
+	"github.com/slimtoolkit/slim/pkg/imagebuilder/internalbuilder"
+	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
@@ -17,6 +20,10 @@ import (
 const (
 	FlagImageBuildEngine = "image-build-engine"
 	FlagImageBuildArch   = "image-build-arch"
+	FlagImageBuildBase   = "image-build-base"
+
+	FlagImageBuildPlatform     = "image-build-platform"
+	FlagImageBuildOutputLayout = "image-build-output-layout"
 
 	FlagDeleteFatImage = "delete-generated-fat-image"
 
@@ -150,12 +157,17 @@ const (
 
 	//Experimenal flags
 	FlagObfuscateMetadata = "obfuscate-metadata"
+	FlagHTTPProbeVerify   = "http-probe-verify"
 )
 
 // Build command flag usage info
 const (
 	FlagImageBuildEngineUsage = "Select image build engine: internal | docker | none"
 	FlagImageBuildArchUsage   = "Select output image build architecture"
+	FlagImageBuildBaseUsage   = "Base image for the output image (internal build engine only): IMAGE, docker-daemon:IMAGE, docker-archive:PATH[:IMAGE], oci:PATH[:REF] or registry:IMAGE"
+
+	FlagImageBuildPlatformUsage     = "Add a platform image to the output multi-platform image index (internal build engine only): ARCH[/VARIANT]=PATH, where PATH is the minified files tar or directory created by the build for that platform"
+	FlagImageBuildOutputLayoutUsage = "Save the output image (or the multi-platform image index) to the OCI image layout directory (internal build engine only)"
 
 	FlagDeleteFatImageUsage = "Delete generated fat image requires --dockerfile flag"
 
@@ -229,6 +241,7 @@ const (
 	FlagCBOCacheFromUsage        = "Add an image to the build cache"
 
 	FlagObfuscateMetadataUsage = "Obfuscate the standard system and application metadata to make it more challenging to identify the image components"
+	FlagHTTPProbeVerifyUsage   = "Replay the probes against the minified image container and fail the build if the probe results regress"
 )
 
 var Flags = map[string]cli.Flag{
@@ -592,6 +605,22 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagImageBuildArchUsage,
 		EnvVars: []string{"DSLIM_IMAGE_BUILD_ARCH"},
 	},
+	FlagImageBuildBase: &cli.StringFlag{
+		Name:    FlagImageBuildBase,
+		Usage:   FlagImageBuildBaseUsage,
+		EnvVars: []string{"DSLIM_IMAGE_BUILD_BASE"},
+	},
+	FlagImageBuildPlatform: &cli.StringSliceFlag{
+		Name:    FlagImageBuildPlatform,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagImageBuildPlatformUsage,
+		EnvVars: []string{"DSLIM_IMAGE_BUILD_PLATFORM"},
+	},
+	FlagImageBuildOutputLayout: &cli.StringFlag{
+		Name:    FlagImageBuildOutputLayout,
+		Usage:   FlagImageBuildOutputLayoutUsage,
+		EnvVars: []string{"DSLIM_IMAGE_BUILD_OUTPUT_LAYOUT"},
+	},
 	FlagDeleteFatImage: &cli.BoolFlag{
 		Name:    FlagDeleteFatImage,
 		Usage:   FlagDeleteFatImageUsage,
@@ -639,6 +668,11 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagObfuscateMetadataUsage,
 		EnvVars: []string{"DSLIM_OBFUSCATE_METADATA"},
 	},
+	FlagHTTPProbeVerify: &cli.BoolFlag{
+		Name:    FlagHTTPProbeVerify,
+		Usage:   FlagHTTPProbeVerifyUsage,
+		EnvVars: []string{"DSLIM_HTTP_PROBE_VERIFY"},
+	},
 }
 
 func cflag(name string) cli.Flag {
@@ -887,17 +921,74 @@ func getImageBuildEngine(ctx *cli.Context) (string, error) {
 }
 
 const (
-	ArchEmpty = ""
-	ArchAmd64 = "amd64"
-	ArchArm64 = "arm64"
+	ArchEmpty   = ""
+	ArchAmd64   = "amd64"
+	ArchArm64   = "arm64"
+	ArchArmV7   = "arm/v7"
+	ArchArmV6   = "arm/v6"
+	ArchPpc64le = "ppc64le"
+	ArchS390x   = "s390x"
+	ArchRiscv64 = "riscv64"
 )
 
 func getImageBuildArch(ctx *cli.Context) (string, error) {
 	value := ctx.String(FlagImageBuildArch)
 	switch value {
-	case ArchEmpty, ArchAmd64, ArchArm64:
+	case ArchEmpty, ArchAmd64, ArchArm64, ArchArmV7, ArchArmV6, ArchPpc64le, ArchS390x, ArchRiscv64:
 		return value, nil
 	default:
 		return "", fmt.Errorf("bad value")
 	}
 }
+
+func getImageBuildPlatforms(ctx *cli.Context) ([]imagebuilder.PlatformBuildOptions, error) {
+	var platforms []imagebuilder.PlatformBuildOptions
+	for _, value := range ctx.StringSlice(FlagImageBuildPlatform) {
+		platform, err := ParseImageBuildPlatform(value)
+		if err != nil {
+			return nil, err
+		}
+
+		platforms = append(platforms, *platform)
+	}
+
+	return platforms, nil
+}
+
+// ParseImageBuildPlatform parses the platform image param value (ARCH[/VARIANT]=PATH)
+// where PATH is the platform image data tar file or directory
+func ParseImageBuildPlatform(value string) (*imagebuilder.PlatformBuildOptions, error) {
+	platformName, dataPath, found := strings.Cut(value, "=")
+	if !found || platformName == "" || dataPath == "" {
+		return nil, fmt.Errorf("malformed platform image value - %s", value)
+	}
+
+	arch, variant, err := internalbuilder.NormalizePlatform(platformName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	layerInfo := imagebuilder.LayerDataInfo{
+		Source: dataPath,
+		Params: &imagebuilder.DataParams{
+			TargetPath: "/",
+		},
+	}
+
+	switch {
+	case fsutil.IsRegularFile(dataPath) && fsutil.IsTarFile(dataPath):
+		layerInfo.Type = imagebuilder.TarSource
+	case fsutil.IsDir(dataPath):
+		layerInfo.Type = imagebuilder.DirSource
+	default:
+		return nil, fmt.Errorf("platform image data is not a tar file or directory - %s", dataPath)
+	}
+
+	platform := &imagebuilder.PlatformBuildOptions{
+		Architecture: arch,
+		Variant:      variant,
+		Layers:       []imagebuilder.LayerDataInfo{layerInfo},
+	}
+
+	return platform, nil
+}
@@ -0,0 +1,64 @@
+package build
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/imagebuilder"
+)
+
+func TestParseImageBuildPlatform(t *testing.T) {
+	dataDir := t.TempDir()
+	dataFile := filepath.Join(t.TempDir(), "files.txt")
+	require.NoError(t, os.WriteFile(dataFile, []byte("data"), 0644))
+
+	dataTar := filepath.Join(t.TempDir(), "files.tar")
+	tf, err := os.Create(dataTar)
+	require.NoError(t, err)
+	tw := tar.NewWriter(tf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "app/run", Mode: 0755, Size: 4}))
+	_, err = tw.Write([]byte("data"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, tf.Close())
+
+	tt := []struct {
+		value           string
+		expectedArch    string
+		expectedVariant string
+		expectedType    imagebuilder.LayerSourceType
+		expectedSource  string
+		isErr           bool
+	}{
+		{value: "arm64=" + dataDir, expectedArch: "arm64", expectedType: imagebuilder.DirSource, expectedSource: dataDir},
+		{value: "arm/v7=" + dataTar, expectedArch: "arm", expectedVariant: "v7", expectedType: imagebuilder.TarSource, expectedSource: dataTar},
+		{value: "amd64", isErr: true},
+		{value: "=" + dataDir, isErr: true},
+		{value: "arm64=", isErr: true},
+		{value: "mips=" + dataDir, isErr: true},
+		{value: "arm64=" + filepath.Join(dataDir, "missing"), isErr: true},
+		//not a tar file
+		{value: "arm64=" + dataFile, isErr: true},
+	}
+
+	for _, test := range tt {
+		platform, err := ParseImageBuildPlatform(test.value)
+		if test.isErr {
+			assert.Error(t, err, test.value)
+			continue
+		}
+
+		require.NoError(t, err, test.value)
+		assert.Equal(t, test.expectedArch, platform.Architecture, test.value)
+		assert.Equal(t, test.expectedVariant, platform.Variant, test.value)
+		require.Len(t, platform.Layers, 1)
+		assert.Equal(t, test.expectedType, platform.Layers[0].Type)
+		assert.Equal(t, test.expectedSource, platform.Layers[0].Source)
+		assert.Equal(t, "/", platform.Layers[0].Params.TargetPath)
+	}
+}
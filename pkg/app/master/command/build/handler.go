@@ -25,9 +25,11 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
 	"github.com/slimtoolkit/slim/pkg/consts"
+	"github.com/slimtoolkit/slim/pkg/crt/crtclient"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerutil"
+	"github.com/slimtoolkit/slim/pkg/imagebuilder"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
@@ -56,6 +58,7 @@ const (
 	ecbKubernetesNoWorkload
 	ecbKubernetesNoWorkloadContainer
 	ecbNotImplementedYet
+	ecbProbeVerifyError
 )
 
 type ovars = app.OutVars
@@ -144,8 +147,11 @@ func OnCommand(
 	execCmd string,
 	execFileCmd string,
 	doDeleteFatImage bool,
+	doHTTPProbeVerify bool,
 	rtaOnbuildBaseImage bool,
 	rtaSourcePT bool,
+	seccompMergeProfiles []string,
+	sbomFormats []string,
 	doObfuscateMetadata bool,
 	sensorIPCEndpoint string,
 	sensorIPCMode string,
@@ -153,6 +159,9 @@ func OnCommand(
 	appNodejsInspectOpts config.AppNodejsInspectOptions,
 	imageBuildEngine string,
 	imageBuildArch string,
+	imageBuildBase string,
+	imageBuildPlatforms []imagebuilder.PlatformBuildOptions,
+	imageBuildOutputLayout string,
 ) {
 	printState := true
 	logger := log.WithFields(log.Fields{"app": appName, "cmd": Name})
@@ -260,6 +269,8 @@ func OnCommand(
 				CBOpts:                    cbOpts,
 				RtaOnbuildBaseImage:       rtaOnbuildBaseImage,
 				RtaSourcePT:               rtaSourcePT,
+				SeccompMergeProfiles:      seccompMergeProfiles,
+				SBOMFormats:               sbomFormats,
 				DockerConfigPath:          dockerConfigPath,
 				RegistryAccount:           registryAccount,
 				RegistrySecret:            registrySecret,
@@ -279,6 +290,9 @@ func OnCommand(
 				execCmd:                   execCmd,
 				imageBuildEngine:          imageBuildEngine,
 				imageBuildArch:            imageBuildArch,
+				imageBuildBase:            imageBuildBase,
+				imageBuildPlatforms:       imageBuildPlatforms,
+				imageBuildOutputLayout:    imageBuildOutputLayout,
 			})
 
 		vinfo := <-viChan
@@ -630,6 +644,7 @@ func OnCommand(
 		client,
 		logger,
 		cmdReport)
+	imageInspector.SeccompMergeProfiles = seccompMergeProfiles
 
 	loadExtraIncludePaths := func() {
 		if (includeLastImageLayers > 0) ||
@@ -640,40 +655,7 @@ func OnCommand(
 				includeLastImageLayers, appImageStartInstGroup, appImageStartInst, len(appImageDockerfileInsts))
 
 			includeLayerPaths := map[string]*fsutil.AccessInfo{}
-			imageID := dockerutil.CleanImageID(imageInspector.ImageInfo.ID)
-			iaName := fmt.Sprintf("%s.tar", imageID)
-			iaPath := filepath.Join(localVolumePath, "image", iaName)
-			iaPathReady := fmt.Sprintf("%s.ready", iaPath)
-
-			var doSave bool
-			if fsutil.IsRegularFile(iaPath) {
-				//if !doReuseSavedImage {
-				//	doSave = true
-				//}
-
-				if !fsutil.Exists(iaPathReady) {
-					doSave = true
-				}
-			} else {
-				doSave = true
-			}
-
-			if doSave {
-				if fsutil.Exists(iaPathReady) {
-					fsutil.Remove(iaPathReady)
-				}
-
-				xc.Out.Info("image.data.inspection.save.image.start")
-				err = dockerutil.SaveImage(client, imageID, iaPath, false, false)
-				errutil.FailOn(err)
-
-				err = fsutil.Touch(iaPathReady)
-				errutil.WarnOn(err)
-
-				xc.Out.Info("image.data.inspection.save.image.end")
-			} else {
-				logger.Debugf("exported image already exists - %s", iaPath)
-			}
+			iaPath, _ := command.SaveImageArchive(xc, client, logger, imageInspector.ImageInfo.ID, localVolumePath)
 
 			xc.Out.Info("image.data.inspection.list.files.start")
 			imgFiles, err := dockerimage.NewPackageFiles(iaPath)
@@ -1055,11 +1037,16 @@ func OnCommand(
 		hasClassicLinks = false
 	}
 
+	//the native runtime engine API is used for the sensor volume and the container data operations
+	crtClient, err := crtclient.NewWithDockerClient(gparams.ClientConfig, client)
+	xc.FailOn(err)
+
 	containerInspector, err := container.NewInspector(
 		xc,
 		crOpts,
 		logger,
 		client,
+		crtClient,
 		statePath,
 		imageInspector,
 		localVolumePath,
@@ -1166,7 +1153,7 @@ func OnCommand(
 
 	logger.Info("watching container monitor...")
 
-	monitorContainer(
+	fatProbe := monitorContainer(
 		xc,
 		targetRef,
 		continueAfter,
@@ -1225,6 +1212,15 @@ func OnCommand(
 
 	xc.Out.State("container.inspection.done")
 
+	pkgReport := createImageSBOM(
+		xc,
+		client,
+		logger,
+		imageInspector,
+		localVolumePath,
+		sbomFormats,
+		cmdReport)
+
 	minifiedImageName := buildOutputImage(
 		xc,
 		customImageTag,
@@ -1240,7 +1236,43 @@ func OnCommand(
 		logger,
 		cmdReport,
 		imageBuildEngine,
-		imageBuildArch)
+		imageBuildArch,
+		imageBuildBase,
+		imageBuildPlatforms,
+		imageBuildOutputLayout)
+
+	createMinifiedImageSBOM(
+		xc,
+		client,
+		logger,
+		imageInspector,
+		localVolumePath,
+		minifiedImageName,
+		pkgReport,
+		sbomFormats,
+		cmdReport)
+
+	if doHTTPProbeVerify {
+		if depServicesExe != nil {
+			xc.Out.Info("http.probe.verify",
+				ovars{
+					"status":  "skipped",
+					"message": "not supported for the targets with compose dependencies",
+				})
+		} else {
+			verifyMinifiedImage(
+				xc,
+				logger,
+				client,
+				minifiedImageName,
+				fatProbe,
+				containerInspector,
+				overrides,
+				explicitVolumeMounts,
+				cmdReport,
+				printState)
+		}
+	}
 
 	finishCommand(
 		xc,
@@ -1273,7 +1305,7 @@ func monitorContainer(
 	client *dockerapi.Client,
 	cmdReport *report.BuildCommand,
 	printState bool,
-) {
+) *http.CustomProbe {
 	if hasContinueAfterMode(continueAfter.Mode, config.CAMProbe) {
 		httpProbeOpts.Do = true
 	}
@@ -1493,6 +1525,8 @@ func monitorContainer(
 		cmdReport.Error = "exec.cmd.failure"
 		xc.Exit(exitCode)
 	}
+
+	return probe
 }
 
 func finishCommand(
@@ -1589,6 +1623,10 @@ func finishCommand(
 	cmdReport.ContainerReportName = report.DefaultContainerReportFileName
 	cmdReport.SeccompProfileName = imageInspector.SeccompProfileName
 	cmdReport.AppArmorProfileName = imageInspector.AppArmorProfileName
+	if fsutil.Exists(filepath.Join(imageInspector.ArtifactLocation, imageInspector.NetworkPolicyName)) {
+		cmdReport.NetworkPolicyName = imageInspector.NetworkPolicyName
+		cmdReport.DockerNetworkName = imageInspector.DockerNetworkName
+	}
 
 	//todo:
 	//need to enhance the 'docker' image builder to provide
@@ -1642,6 +1680,32 @@ func finishCommand(
 			"artifacts.apparmor": cmdReport.AppArmorProfileName,
 		})
 
+	if cmdReport.NetworkPolicyName != "" {
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.network.policy": cmdReport.NetworkPolicyName,
+			})
+
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.network.docker": cmdReport.DockerNetworkName,
+			})
+	}
+
+	for _, sbomFile := range cmdReport.SBOMFiles {
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.sbom.original": sbomFile,
+			})
+	}
+
+	for _, sbomFile := range cmdReport.MinifiedSBOMFiles {
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.sbom.minified": sbomFile,
+			})
+	}
+
 	if cmdReport.ArtifactLocation != "" {
 		creportPath := filepath.Join(cmdReport.ArtifactLocation, cmdReport.ContainerReportName)
 		if creportData, err := os.ReadFile(creportPath); err == nil {
@@ -1666,7 +1730,11 @@ func finishCommand(
 			report.DefaultContainerReportFileName,
 			imageInspector.SeccompProfileName,
 			imageInspector.AppArmorProfileName,
+			imageInspector.NetworkPolicyName,
+			imageInspector.DockerNetworkName,
 		}
+		toCopy = append(toCopy, cmdReport.SBOMFiles...)
+		toCopy = append(toCopy, cmdReport.MinifiedSBOMFiles...)
 		if !command.CopyMetaArtifacts(logger,
 			toCopy,
 			imageInspector.ArtifactLocation, copyMetaArtifactsLocation) {
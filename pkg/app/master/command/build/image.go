@@ -18,6 +18,7 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/image"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
 	"github.com/slimtoolkit/slim/pkg/consts"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerutil"
 	"github.com/slimtoolkit/slim/pkg/imagebuilder"
 	"github.com/slimtoolkit/slim/pkg/imagebuilder/internalbuilder"
 	"github.com/slimtoolkit/slim/pkg/report"
@@ -287,6 +288,9 @@ func buildOutputImage(
 	cmdReport *report.BuildCommand,
 	imageBuildEngine string,
 	imageBuildArch string,
+	imageBuildBase string,
+	imageBuildPlatforms []imagebuilder.PlatformBuildOptions,
+	imageBuildOutputLayout string,
 ) string {
 	onError := func(e error) {
 		xc.Out.Info("build.error",
@@ -332,7 +336,10 @@ func buildOutputImage(
 			false)
 		xc.FailOn(err)
 
+		engine.OutputImageLayout = imageBuildOutputLayout
+
 		opts := imagebuilder.SimpleBuildOptions{
+			From: imageBuildBase,
 			ImageConfig: imagebuilder.ImageConfig{
 				Architecture: imageBuildArch,
 				Config: imagebuilder.RunConfig{
@@ -352,7 +359,16 @@ func buildOutputImage(
 			opts.Tags = append(opts.Tags, additionalTags...)
 		}
 
-		UpdateBuildOptionsWithSrcImageInfo(&opts, imageInspector.ImageInfo)
+		var srcImageVariant string
+		if imageBuildArch == "" {
+			//the output image gets the source image platform
+			srcImageVariant, err = dockerutil.ImageVariant(client, imageInspector.ImageInfo.ID)
+			if err != nil {
+				logger.Warnf("error getting the source image variant - %v", err)
+			}
+		}
+
+		UpdateBuildOptionsWithSrcImageInfo(&opts, imageInspector.ImageInfo, srcImageVariant)
 		UpdateBuildOptionsWithOverrides(&opts, imageOverrideSelectors, overrides)
 
 		if imageInspector.ImageRef != "" {
@@ -411,6 +427,17 @@ func buildOutputImage(
 			}
 		}
 
+		if len(imageBuildPlatforms) > 0 {
+			//the image for the current build platform is the first image in the image index
+			//(it uses the common layers and the other platform images have their own layers)
+			opts.Platforms = append(opts.Platforms, imagebuilder.PlatformBuildOptions{
+				Architecture: opts.ImageConfig.Architecture,
+				Variant:      opts.ImageConfig.Variant,
+			})
+
+			opts.Platforms = append(opts.Platforms, imageBuildPlatforms...)
+		}
+
 		imageResult, err := engine.Build(opts)
 		if err != nil {
 			onError(err)
@@ -418,6 +445,10 @@ func buildOutputImage(
 
 		outputImageName = imageResult.Name // customImageTag // engine.RepoName
 		cmdReport.MinifiedImageID = imageResult.ID
+		if len(imageResult.Manifests) > 0 {
+			cmdReport.MinifiedImageID = imageResult.Manifests[0].ID
+		}
+
 		cmdReport.MinifiedImageDigest = imageResult.Digest
 		imageCreated = true
 	case IBEBuildKit:
@@ -501,6 +532,10 @@ func UpdateBuildOptionsWithNewInstructions(
 			options.ImageConfig.Config.WorkingDir = instructions.Workdir
 		}
 
+		if instructions.User != "" {
+			options.ImageConfig.Config.User = instructions.User
+		}
+
 		if len(instructions.Env) > 0 {
 			options.ImageConfig.Config.Env = append(options.ImageConfig.Config.Env, instructions.Env...)
 		}
@@ -618,7 +653,8 @@ func UpdateBuildOptionsWithOverrides(
 
 func UpdateBuildOptionsWithSrcImageInfo(
 	options *imagebuilder.SimpleBuildOptions,
-	imageInfo *dockerapi.Image) {
+	imageInfo *dockerapi.Image,
+	imageVariant string) {
 	labels := SourceToOutputImageLabels(imageInfo.Config.Labels)
 	for k, v := range labels {
 		options.ImageConfig.Config.Labels[k] = v
@@ -627,8 +663,11 @@ func UpdateBuildOptionsWithSrcImageInfo(
 	//note: not passing imageInfo.OS explicitly
 	//because it gets "hardcoded" to "linux" internally
 	//(other OS types are not supported)
+	//the variant is set only with the source image architecture
+	//(it's never guessed because it needs to match the image binaries)
 	if options.ImageConfig.Architecture == "" {
 		options.ImageConfig.Architecture = imageInfo.Architecture
+		options.ImageConfig.Variant = imageVariant
 	}
 
 	options.ImageConfig.Config.User = imageInfo.Config.User
@@ -16,6 +16,7 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/pod"
 	"github.com/slimtoolkit/slim/pkg/app/master/kubernetes"
 	"github.com/slimtoolkit/slim/pkg/app/master/probe/http"
+	"github.com/slimtoolkit/slim/pkg/imagebuilder"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
@@ -73,6 +74,8 @@ type kubeHandleOptions struct {
 	DoRmFileArtifacts         bool
 	RtaOnbuildBaseImage       bool
 	RtaSourcePT               bool
+	SeccompMergeProfiles      []string
+	SBOMFormats               []string
 	DockerConfigPath          string
 	RegistryAccount           string
 	RegistrySecret            string
@@ -93,11 +96,14 @@ type kubeHandleOptions struct {
 	PortBindings          map[dockerapi.Port][]dockerapi.PortBinding
 	DoPublishExposedPorts bool
 
-	httpProbeOpts    config.HTTPProbeOptions
-	continueAfter    *config.ContinueAfter
-	execCmd          string
-	imageBuildEngine string
-	imageBuildArch   string
+	httpProbeOpts          config.HTTPProbeOptions
+	continueAfter          *config.ContinueAfter
+	execCmd                string
+	imageBuildEngine       string
+	imageBuildArch         string
+	imageBuildBase         string
+	imageBuildPlatforms    []imagebuilder.PlatformBuildOptions
+	imageBuildOutputLayout string
 }
 
 func (h *kubeHandler) Handle(
@@ -123,7 +129,7 @@ func (h *kubeHandler) Handle(
 			targetOverride.Image)
 	}
 
-	imageInspector, _, statePath, stateKey := inspectFatImage(
+	imageInspector, localVolumePath, statePath, stateKey := inspectFatImage(
 		h.ExecutionContext,
 		workload.TargetContainer().Image,
 		opts.DoPull,
@@ -137,6 +143,7 @@ func (h *kubeHandler) Handle(
 		h.logger,
 		h.report)
 	workload.TargetContainer().Image = imageInspector.ImageRef
+	imageInspector.SeccompMergeProfiles = opts.SeccompMergeProfiles
 
 	// 3. Patch and run the workload
 	//    - patch: add the init container, the volume, replace the entrypoint
@@ -205,6 +212,15 @@ func (h *kubeHandler) Handle(
 	// 7. Build the slim image & create AppArmor and seccomp profiles
 	h.processCollectedDataOrFail(podInspector, imageInspector)
 
+	pkgReport := createImageSBOM(
+		h.ExecutionContext,
+		h.dockerClient,
+		h.logger,
+		imageInspector,
+		localVolumePath,
+		opts.SBOMFormats,
+		h.report)
+
 	minifiedImageName := buildOutputImage(
 		h.ExecutionContext,
 		opts.CustomImageTag,
@@ -220,7 +236,21 @@ func (h *kubeHandler) Handle(
 		h.logger,
 		h.report,
 		opts.imageBuildEngine,
-		opts.imageBuildArch)
+		opts.imageBuildArch,
+		opts.imageBuildBase,
+		opts.imageBuildPlatforms,
+		opts.imageBuildOutputLayout)
+
+	createMinifiedImageSBOM(
+		h.ExecutionContext,
+		h.dockerClient,
+		h.logger,
+		imageInspector,
+		localVolumePath,
+		minifiedImageName,
+		pkgReport,
+		opts.SBOMFormats,
+		h.report)
 
 	finishCommand(
 		h.ExecutionContext,
@@ -55,6 +55,7 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(command.FlagHTTPProbeRetryCount), Description: command.FlagHTTPProbeRetryCountUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeRetryWait), Description: command.FlagHTTPProbeRetryWaitUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbePorts), Description: command.FlagHTTPProbePortsUsage},
+		{Text: command.FullFlagName(command.FlagTCPProbePorts), Description: command.FlagTCPProbePortsUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeFull), Description: command.FlagHTTPProbeFullUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeExitOnFailure), Description: command.FlagHTTPProbeExitOnFailureUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeCrawl), Description: command.FlagHTTPProbeCrawlUsage},
@@ -64,6 +65,10 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(command.FlagHTTPMaxConcurrentCrawlers), Description: command.FlagHTTPMaxConcurrentCrawlersUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpec), Description: command.FlagHTTPProbeAPISpecUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpecFile), Description: command.FlagHTTPProbeAPISpecFileUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpecCred), Description: command.FlagHTTPProbeAPISpecCredUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQL), Description: command.FlagHTTPProbeGraphQLUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQLEndpoint), Description: command.FlagHTTPProbeGraphQLEndpointUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQLMutations), Description: command.FlagHTTPProbeGraphQLMutationsUsage},
 		{Text: command.FullFlagName(command.FlagPublishPort), Description: command.FlagPublishPortUsage},
 		{Text: command.FullFlagName(command.FlagPublishExposedPorts), Description: command.FlagPublishExposedPortsUsage},
 		{Text: command.FullFlagName(command.FlagHostExec), Description: command.FlagHostExecUsage},
@@ -156,11 +161,17 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(command.FlagDeleteFatImage), Description: command.FlagDeleteFatImageUsage},
 		{Text: command.FullFlagName(command.FlagRTAOnbuildBaseImage), Description: command.FlagRTAOnbuildBaseImageUsage},
 		{Text: command.FullFlagName(command.FlagRTASourcePT), Description: command.FlagRTASourcePTUsage},
+		{Text: command.FullFlagName(command.FlagSeccompMergeProfile), Description: command.FlagSeccompMergeProfileUsage},
+		{Text: command.FullFlagName(command.FlagSBOM), Description: command.FlagSBOMUsage},
 		{Text: command.FullFlagName(command.FlagSensorIPCMode), Description: command.FlagSensorIPCModeUsage},
 		{Text: command.FullFlagName(command.FlagSensorIPCEndpoint), Description: command.FlagSensorIPCEndpointUsage},
 		{Text: command.FullFlagName(FlagImageBuildEngine), Description: FlagImageBuildEngineUsage},
 		{Text: command.FullFlagName(FlagImageBuildArch), Description: FlagImageBuildArchUsage},
+		{Text: command.FullFlagName(FlagImageBuildBase), Description: FlagImageBuildBaseUsage},
+		{Text: command.FullFlagName(FlagImageBuildPlatform), Description: FlagImageBuildPlatformUsage},
+		{Text: command.FullFlagName(FlagImageBuildOutputLayout), Description: FlagImageBuildOutputLayoutUsage},
 		{Text: command.FullFlagName(FlagObfuscateMetadata), Description: FlagObfuscateMetadataUsage},
+		{Text: command.FullFlagName(FlagHTTPProbeVerify), Description: FlagHTTPProbeVerifyUsage},
 	},
 	Values: map[string]command.CompleteValue{
 		command.FullFlagName(command.FlagCommandParamsFile): command.CompleteFile,
@@ -184,6 +195,8 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		command.FullFlagName(command.FlagHTTPProbe):                      command.CompleteTBool,
 		command.FullFlagName(command.FlagHTTPProbeCmdFile):               command.CompleteFile,
 		command.FullFlagName(command.FlagHTTPProbeFull):                  command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeGraphQL):               command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeGraphQLMutations):      command.CompleteBool,
 		command.FullFlagName(command.FlagHTTPProbeExitOnFailure):         command.CompleteBool,
 		command.FullFlagName(command.FlagHTTPProbeCrawl):                 command.CompleteTBool,
 		command.FullFlagName(command.FlagHTTPProbeAPISpecFile):           command.CompleteFile,
@@ -232,10 +245,13 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		command.FullFlagName(command.FlagRTAOnbuildBaseImage):  command.CompleteBool,
 		command.FullFlagName(command.FlagRTASourcePT):          command.CompleteBool,
 		command.FullFlagName(command.FlagSensorIPCMode):        command.CompleteIPCMode,
+		command.FullFlagName(command.FlagSBOM):                 command.CompleteSBOMFormat,
 		command.FullFlagName(FlagImageBuildEngine):             CompleteImageBuildEngine,
 		command.FullFlagName(FlagImageBuildArch):               CompleteImageBuildArch,
+		command.FullFlagName(FlagImageBuildOutputLayout):       command.CompleteFile,
 		command.FullFlagName(FlagAppImageDockerfile):           command.CompleteFile,
 		command.FullFlagName(FlagObfuscateMetadata):            command.CompleteBool,
+		command.FullFlagName(FlagHTTPProbeVerify):              command.CompleteBool,
 	},
 }
 
@@ -253,6 +269,11 @@ func CompleteImageBuildEngine(ia *command.InteractiveApp, token string, params p
 var imageBuildArchValues = []prompt.Suggest{
 	{Text: ArchAmd64, Description: "amd64 architecture"},
 	{Text: ArchArm64, Description: "arm64 architecture"},
+	{Text: ArchArmV7, Description: "32-bit arm architecture (v7 variant)"},
+	{Text: ArchArmV6, Description: "32-bit arm architecture (v6 variant)"},
+	{Text: ArchPpc64le, Description: "ppc64le architecture"},
+	{Text: ArchS390x, Description: "s390x architecture"},
+	{Text: ArchRiscv64, Description: "riscv64 architecture"},
 }
 
 func CompleteImageBuildArch(ia *command.InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
@@ -0,0 +1,113 @@
+package build
+
+import (
+	"strings"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/app"
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/image"
+	"github.com/slimtoolkit/slim/pkg/report"
+	"github.com/slimtoolkit/slim/pkg/sbom"
+	"github.com/slimtoolkit/slim/pkg/util/errutil"
+)
+
+// createImageSBOM creates the SBOM documents for the original (fat) image
+// (must be called before the fat image is removed)
+func createImageSBOM(
+	xc *app.ExecutionContext,
+	client *dockerapi.Client,
+	logger *log.Entry,
+	imageInspector *image.Inspector,
+	localVolumePath string,
+	sbomFormats []string,
+	cmdReport *report.BuildCommand,
+) *sbom.Report {
+	if len(sbomFormats) == 0 {
+		return nil
+	}
+
+	xc.Out.State("sbom.original.start")
+	imageID := imageInspector.ImageInfo.ID
+	iaPath, _ := command.SaveImageArchive(xc, client, logger, imageID, localVolumePath)
+	imagePkg := command.LoadImagePackage(xc, iaPath, imageID, command.PackageProcessorParams(true))
+
+	pkgReport := imagePkg.ProcessPackageData()
+	sbomFiles, err := pkgReport.SaveDocuments(
+		imageInspector.ArtifactLocation,
+		sbomFormats,
+		false,
+		sbom.DocumentInfo{
+			ImageName: imageInspector.ImageRef,
+			ImageID:   imageID,
+		})
+	errutil.FailOn(err)
+
+	cmdReport.SBOMFiles = sbomFiles
+	xc.Out.State("sbom.original.done",
+		ovars{
+			"packages": len(pkgReport.Packages),
+			"files":    strings.Join(sbomFiles, ","),
+		})
+
+	return pkgReport
+}
+
+// createMinifiedImageSBOM creates the SBOM documents for the minified image
+// with the original image packages that have files in the minified image
+func createMinifiedImageSBOM(
+	xc *app.ExecutionContext,
+	client *dockerapi.Client,
+	logger *log.Entry,
+	imageInspector *image.Inspector,
+	localVolumePath string,
+	minifiedImageName string,
+	pkgReport *sbom.Report,
+	sbomFormats []string,
+	cmdReport *report.BuildCommand,
+) {
+	if pkgReport == nil || len(sbomFormats) == 0 {
+		return
+	}
+
+	minifiedInspector, err := image.NewInspector(client, minifiedImageName)
+	errutil.FailOn(err)
+
+	//no minified image with the 'none' image build engine
+	if noImage, err := minifiedInspector.NoImage(); err != nil || noImage {
+		logger.Debugf("createMinifiedImageSBOM: no minified image (%s) - %v", minifiedImageName, err)
+		return
+	}
+
+	xc.Out.State("sbom.minified.start")
+	err = minifiedInspector.Inspect()
+	errutil.FailOn(err)
+
+	imageID := minifiedInspector.ImageInfo.ID
+	iaPath, _ := command.SaveImageArchive(xc, client, logger, imageID, localVolumePath)
+	imagePkg := command.LoadImagePackage(xc, iaPath, imageID, command.PackageProcessorParams(false))
+
+	imageFiles, links := imagePkg.FileSystem()
+	minifiedReport := pkgReport.Minified(imageFiles, links)
+	sbomFiles, err := minifiedReport.SaveDocuments(
+		imageInspector.ArtifactLocation,
+		sbomFormats,
+		true,
+		sbom.DocumentInfo{
+			ImageName: minifiedImageName,
+			ImageID:   imageID,
+		})
+	errutil.FailOn(err)
+
+	//the minified image archive is not reused
+	command.RemoveImageArchive(logger, iaPath)
+
+	cmdReport.MinifiedSBOMFiles = sbomFiles
+	xc.Out.State("sbom.minified.done",
+		ovars{
+			"packages": len(minifiedReport.Packages),
+			"files":    strings.Join(sbomFiles, ","),
+		})
+}
@@ -0,0 +1,176 @@
+package build
+
+import (
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/app"
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	execution "github.com/slimtoolkit/slim/pkg/app/master/container"
+	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/container"
+	"github.com/slimtoolkit/slim/pkg/app/master/probe/http"
+	"github.com/slimtoolkit/slim/pkg/probediff"
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+const localHostIP = "127.0.0.1"
+
+// verifyMinifiedImage runs a container from the minified image, replays the probes
+// from the 'fat' container run and fails the build if the probe results regress
+func verifyMinifiedImage(
+	xc *app.ExecutionContext,
+	logger *log.Entry,
+	client *dockerapi.Client,
+	minifiedImageName string,
+	fatProbe *http.CustomProbe,
+	containerInspector *container.Inspector,
+	overrides *config.ContainerOverrides,
+	explicitVolumeMounts map[string]config.VolumeMount,
+	cmdReport *report.BuildCommand,
+	printState bool,
+) {
+	if fatProbe == nil || len(fatProbe.CallResults()) == 0 {
+		xc.Out.Info("http.probe.verify",
+			ovars{
+				"status":  "skipped",
+				"message": "no probe results for the 'fat' container",
+			})
+		return
+	}
+
+	xc.Out.State("http.probe.verify.start")
+
+	options := &execution.ExecutionOptions{
+		PublishPorts: map[dockerapi.Port][]dockerapi.PortBinding{},
+	}
+
+	for pk := range containerInspector.AvailablePorts {
+		//publishing to random host ports (the 'fat' container host ports could still be in use)
+		options.PublishPorts[pk] = []dockerapi.PortBinding{{}}
+	}
+
+	for _, volume := range explicitVolumeMounts {
+		options.Volumes = append(options.Volumes, volume)
+	}
+
+	var networkName string
+	if overrides != nil {
+		//the same runtime overrides as the 'fat' container
+		options.Entrypoint = overrides.Entrypoint
+		options.Cmd = overrides.Cmd
+		options.EnvVars = overrides.Env
+		networkName = overrides.Network
+	}
+
+	exe, err := execution.NewExecution(xc, logger, client, minifiedImageName, options, nil, false, printState)
+	xc.FailOn(err)
+	exe.NetworkMode = networkName
+
+	exeCleanup := func() {
+		_ = exe.Stop()
+		_ = exe.Cleanup()
+	}
+
+	logger.Info("starting 'slim' container to verify the probe results...")
+	if err := exe.Start(); err != nil {
+		xc.Out.Error("http.probe.verify.container.start", err.Error())
+		exe.ShowContainerLogs()
+		exeCleanup()
+
+		exitCode := command.ECTBuild | ecbProbeVerifyError
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": exitCode,
+			})
+
+		cmdReport.Error = "probe.verify.container.start"
+		xc.Exit(exitCode)
+	}
+
+	targetHost := containerInspector.TargetHost
+	isDirect := containerInspector.SensorIPCMode == container.SensorIPCModeDirect
+	if isDirect && containerInspector.SensorIPCEndpoint == "" {
+		targetHost = executionIP(exe, networkName)
+	}
+
+	targetPort := func(containerPort string) (string, bool) {
+		if isDirect {
+			return containerPort, true
+		}
+
+		bindings := exe.ContainerInfo.NetworkSettings.Ports[dockerapi.Port(containerPort+"/tcp")]
+		if len(bindings) == 0 || bindings[0].HostPort == "" {
+			return "", false
+		}
+
+		return bindings[0].HostPort, true
+	}
+
+	probe, err := http.NewReplayProbe(xc, fatProbe, targetHost, targetPort, printState)
+	xc.FailOn(err)
+
+	probe.Start()
+	<-probe.DoneChan()
+
+	verification := probediff.Compare(fatProbe.CallResults(), probe.CallResults())
+	cmdReport.ProbeVerification = verification
+
+	for _, regression := range verification.Regressions {
+		xc.Out.Info("http.probe.verify.regression",
+			ovars{
+				"call":   regression.Key,
+				"type":   regression.Type,
+				"target": regression.Target,
+				"fat":    regression.Fat,
+				"slim":   regression.Slim,
+			})
+	}
+
+	status := "ok"
+	if len(verification.Regressions) > 0 {
+		status = "regressions"
+	}
+
+	xc.Out.Info("http.probe.verify",
+		ovars{
+			"status":      status,
+			"calls":       len(verification.FatCalls),
+			"regressions": len(verification.Regressions),
+		})
+
+	if len(verification.Regressions) > 0 {
+		exe.ShowContainerLogs()
+		exeCleanup()
+
+		exitCode := command.ECTBuild | ecbProbeVerifyError
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": exitCode,
+			})
+
+		cmdReport.Error = "probe.verify.regressions"
+		xc.Exit(exitCode)
+	}
+
+	exeCleanup()
+	xc.Out.State("http.probe.verify.done")
+}
+
+func executionIP(exe *execution.Execution, networkName string) string {
+	if exe.ContainerInfo == nil || exe.ContainerInfo.NetworkSettings == nil {
+		return localHostIP
+	}
+
+	ipAddr := exe.ContainerInfo.NetworkSettings.IPAddress
+	if network, found := exe.ContainerInfo.NetworkSettings.Networks[networkName]; found && networkName != "" {
+		ipAddr = network.IPAddress
+	}
+
+	if ipAddr == "" {
+		//host networking
+		ipAddr = localHostIP
+	}
+
+	return ipAddr
+}
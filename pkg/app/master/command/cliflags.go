@@ -4,6 +4,8 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/slimtoolkit/slim/pkg/crt"
 )
 
 /////////////////////////////////////////////////////////
@@ -21,6 +23,7 @@ const (
 	FlagLog           = "log"
 	FlagLogFormat     = "log-format"
 	FlagAPIVersion    = "crt-api-version"
+	FlagCRTEngine     = "crt-engine"
 	FlagUseTLS        = "tls"
 	FlagVerifyTLS     = "tls-verify"
 	FlagTLSCertPath   = "tls-cert-path"
@@ -52,6 +55,7 @@ const (
 	FlagVerifyTLSUsage     = "verify TLS"
 	FlagTLSCertPathUsage   = "path to TLS cert files"
 	FlagAPIVersionUsage    = "Container runtime API version"
+	FlagCRTEngineUsage     = "Container runtime engine ('docker' (default), 'podman' or 'containerd')"
 	FlagHostUsage          = "Docker host address or socket (prefix with 'tcp://' or 'unix://')"
 	FlagStatePathUsage     = "app state base path"
 	FlagInContainerUsage   = "app is running in a container"
@@ -113,6 +117,7 @@ const (
 	FlagHTTPProbeRetryCount       = "http-probe-retry-count"
 	FlagHTTPProbeRetryWait        = "http-probe-retry-wait"
 	FlagHTTPProbePorts            = "http-probe-ports"
+	FlagTCPProbePorts             = "tcp-probe-ports"
 	FlagHTTPProbeFull             = "http-probe-full"
 	FlagHTTPProbeExitOnFailure    = "http-probe-exit-on-failure"
 	FlagHTTPProbeCrawl            = "http-probe-crawl"
@@ -122,6 +127,10 @@ const (
 	FlagHTTPMaxConcurrentCrawlers = "http-max-concurrent-crawlers"
 	FlagHTTPProbeAPISpec          = "http-probe-apispec"
 	FlagHTTPProbeAPISpecFile      = "http-probe-apispec-file"
+	FlagHTTPProbeAPISpecCred      = "http-probe-apispec-credential"
+	FlagHTTPProbeGraphQL          = "http-probe-graphql"
+	FlagHTTPProbeGraphQLEndpoint  = "http-probe-graphql-endpoint"
+	FlagHTTPProbeGraphQLMutations = "http-probe-graphql-mutations"
 	FlagHTTPProbeProxyEndpoint    = "http-probe-proxy-endpoint"
 	FlagHTTPProbeProxyPort        = "http-probe-proxy-port"
 
@@ -143,6 +152,12 @@ const (
 	FlagRTAOnbuildBaseImage = "rta-onbuild-base-image"
 	FlagRTASourcePT         = "rta-source-ptrace"
 
+	//Security Profile Options (for build and profile commands)
+	FlagSeccompMergeProfile = "seccomp-merge-profile"
+
+	//SBOM Options (for build and xray commands)
+	FlagSBOM = "sbom"
+
 	//Sensor IPC Options (for build and profile commands)
 	FlagSensorIPCEndpoint = "sensor-ipc-endpoint"
 	FlagSensorIPCMode     = "sensor-ipc-mode"
@@ -226,6 +241,7 @@ const (
 	FlagHTTPProbeRetryCountUsage       = "Number of retries for each HTTP probe"
 	FlagHTTPProbeRetryWaitUsage        = "Number of seconds to wait before retrying HTTP probe (doubles when target is not ready)"
 	FlagHTTPProbePortsUsage            = "Explicit list of ports to probe (in the order you want them to be probed)"
+	FlagTCPProbePortsUsage             = "List of ports to probe with the native protocol probes ('[protocol:]port' - redis, postgresql, mysql, mongodb, memcached, nats or tcp)"
 	FlagHTTPProbeFullUsage             = "Do full HTTP probe for all selected ports (if false, finish after first successful scan)"
 	FlagHTTPProbeExitOnFailureUsage    = "Exit when all HTTP probe commands fail"
 	FlagHTTPProbeCrawlUsage            = "Enable crawling for the default HTTP probe command"
@@ -235,6 +251,10 @@ const (
 	FlagHTTPMaxConcurrentCrawlersUsage = "Number of concurrent crawlers in the HTTP probe"
 	FlagHTTPProbeAPISpecUsage          = "Run HTTP probes for API spec"
 	FlagHTTPProbeAPISpecFileUsage      = "Run HTTP probes for API spec from file"
+	FlagHTTPProbeAPISpecCredUsage      = "Credential for the API spec security schemes (format => <scheme_name|bearer|basic|apikey>=<value>)"
+	FlagHTTPProbeGraphQLUsage          = "Detect the GraphQL endpoint and run the queries generated from its introspection schema"
+	FlagHTTPProbeGraphQLEndpointUsage  = "GraphQL endpoint path to probe (enables GraphQL probing)"
+	FlagHTTPProbeGraphQLMutationsUsage = "Also run the generated GraphQL mutations (they can change the app data)"
 	FlagHTTPProbeProxyEndpointUsage    = "Endpoint to proxy HTTP probes"
 	FlagHTTPProbeProxyPortUsage        = "Port to proxy HTTP probes (used with HTTP probe proxy endpoint)"
 
@@ -255,6 +275,10 @@ const (
 	FlagRTAOnbuildBaseImageUsage = "Enable runtime analysis for onbuild base images"
 	FlagRTASourcePTUsage         = "Enable PTRACE runtime analysis source"
 
+	FlagSeccompMergeProfileUsage = "Seccomp profile generated for another architecture to merge into the generated profile"
+
+	FlagSBOMUsage = "Generate SBOM for the image (values: spdx-json, cyclonedx-json)"
+
 	FlagSensorIPCEndpointUsage = "Override sensor IPC endpoint"
 	FlagSensorIPCModeUsage     = "Select sensor IPC mode: proxy | direct"
 
@@ -357,6 +381,12 @@ func GlobalFlags() []cli.Flag {
 			Usage:   FlagAPIVersionUsage,
 			EnvVars: []string{"DSLIM_CRT_API_VER"},
 		},
+		&cli.StringFlag{
+			Name:    FlagCRTEngine,
+			Value:   crt.DefaultEngine,
+			Usage:   FlagCRTEngineUsage,
+			EnvVars: []string{"DSLIM_CRT_ENGINE"},
+		},
 		&cli.StringFlag{
 			Name:  FlagHost,
 			Value: "",
@@ -619,6 +649,28 @@ var CommonFlags = map[string]cli.Flag{
 		Usage:   FlagHTTPProbeAPISpecFileUsage,
 		EnvVars: []string{"DSLIM_HTTP_PROBE_API_SPEC_FILE"},
 	},
+	FlagHTTPProbeAPISpecCred: &cli.StringSliceFlag{
+		Name:    FlagHTTPProbeAPISpecCred,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagHTTPProbeAPISpecCredUsage,
+		EnvVars: []string{"DSLIM_HTTP_PROBE_API_SPEC_CREDENTIAL"},
+	},
+	FlagHTTPProbeGraphQL: &cli.BoolFlag{
+		Name:    FlagHTTPProbeGraphQL,
+		Usage:   FlagHTTPProbeGraphQLUsage,
+		EnvVars: []string{"DSLIM_HTTP_PROBE_GRAPHQL"},
+	},
+	FlagHTTPProbeGraphQLEndpoint: &cli.StringSliceFlag{
+		Name:    FlagHTTPProbeGraphQLEndpoint,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagHTTPProbeGraphQLEndpointUsage,
+		EnvVars: []string{"DSLIM_HTTP_PROBE_GRAPHQL_ENDPOINT"},
+	},
+	FlagHTTPProbeGraphQLMutations: &cli.BoolFlag{
+		Name:    FlagHTTPProbeGraphQLMutations,
+		Usage:   FlagHTTPProbeGraphQLMutationsUsage,
+		EnvVars: []string{"DSLIM_HTTP_PROBE_GRAPHQL_MUTATIONS"},
+	},
 	FlagHTTPProbeStartWait: &cli.IntFlag{
 		Name:    FlagHTTPProbeStartWait,
 		Value:   0,
@@ -643,6 +695,12 @@ var CommonFlags = map[string]cli.Flag{
 		Usage:   FlagHTTPProbePortsUsage,
 		EnvVars: []string{"DSLIM_HTTP_PROBE_PORTS"},
 	},
+	FlagTCPProbePorts: &cli.StringFlag{
+		Name:    FlagTCPProbePorts,
+		Value:   "",
+		Usage:   FlagTCPProbePortsUsage,
+		EnvVars: []string{"DSLIM_TCP_PROBE_PORTS"},
+	},
 	FlagHTTPProbeFull: &cli.BoolFlag{
 		Name:    FlagHTTPProbeFull,
 		Usage:   FlagHTTPProbeFullUsage,
@@ -913,6 +971,18 @@ var CommonFlags = map[string]cli.Flag{
 		Usage:   FlagRTASourcePTUsage,
 		EnvVars: []string{"DSLIM_RTA_SRC_PT"},
 	},
+	FlagSeccompMergeProfile: &cli.StringSliceFlag{
+		Name:    FlagSeccompMergeProfile,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagSeccompMergeProfileUsage,
+		EnvVars: []string{"DSLIM_SECCOMP_MERGE_PROFILE"},
+	},
+	FlagSBOM: &cli.StringSliceFlag{
+		Name:    FlagSBOM,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagSBOMUsage,
+		EnvVars: []string{"DSLIM_SBOM"},
+	},
 }
 
 //var CommonFlags
@@ -942,6 +1012,7 @@ func HTTPProbeFlagsBasic() []cli.Flag {
 		Cflag(FlagHTTPProbeRetryCount),
 		Cflag(FlagHTTPProbeRetryWait),
 		Cflag(FlagHTTPProbePorts),
+		Cflag(FlagTCPProbePorts),
 		Cflag(FlagHTTPProbeFull),
 		Cflag(FlagHTTPProbeCrawl),
 		Cflag(FlagHTTPCrawlMaxDepth),
@@ -950,6 +1021,10 @@ func HTTPProbeFlagsBasic() []cli.Flag {
 		Cflag(FlagHTTPMaxConcurrentCrawlers),
 		Cflag(FlagHTTPProbeAPISpec),
 		Cflag(FlagHTTPProbeAPISpecFile),
+		Cflag(FlagHTTPProbeAPISpecCred),
+		Cflag(FlagHTTPProbeGraphQL),
+		Cflag(FlagHTTPProbeGraphQLEndpoint),
+		Cflag(FlagHTTPProbeGraphQLMutations),
 	}
 }
 
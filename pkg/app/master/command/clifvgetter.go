@@ -116,6 +116,20 @@ func GetHTTPProbeOptions(xc *app.ExecutionContext, ctx *cli.Context, doProbe boo
 	}
 	opts.Ports = ports
 
+	tcpProbes, err := ParseTCPProbePorts(ctx.String(FlagTCPProbePorts))
+	if err != nil {
+		xc.Out.Error("param.error.tcp.probe.ports", err.Error())
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": -1,
+			})
+		xc.Exit(-1)
+	}
+	opts.TCPProbes = tcpProbes
+	if len(opts.TCPProbes) > 0 {
+		opts.Do = true
+	}
+
 	opts.APISpecs = ctx.StringSlice(FlagHTTPProbeAPISpec)
 	apiSpecFiles, fileErrors := ValidateFiles(ctx.StringSlice(FlagHTTPProbeAPISpecFile))
 	if len(fileErrors) > 0 {
@@ -137,10 +151,28 @@ func GetHTTPProbeOptions(xc *app.ExecutionContext, ctx *cli.Context, doProbe boo
 	}
 	opts.APISpecFiles = apiSpecFiles
 
+	apiSpecCreds, err := ParseHTTPProbeAPISpecCredentials(ctx.StringSlice(FlagHTTPProbeAPISpecCred))
+	if err != nil {
+		xc.Out.Error("param.error.http.api.spec.credential", err.Error())
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": -1,
+			})
+		xc.Exit(-1)
+	}
+	opts.APISpecCredentials = apiSpecCreds
+
 	if len(opts.APISpecs)+len(opts.APISpecFiles) > 0 {
 		opts.Do = true
 	}
 
+	opts.GraphQLEndpoints = ctx.StringSlice(FlagHTTPProbeGraphQLEndpoint)
+	opts.GraphQL = ctx.Bool(FlagHTTPProbeGraphQL) || len(opts.GraphQLEndpoints) > 0
+	opts.GraphQLMutations = ctx.Bool(FlagHTTPProbeGraphQLMutations)
+	if opts.GraphQL {
+		opts.Do = true
+	}
+
 	return opts
 }
 
@@ -407,6 +439,7 @@ func GlobalFlagValues(ctx *cli.Context) *GenericParams {
 
 func GetDockerClientConfig(ctx *cli.Context) *config.DockerClient {
 	config := &config.DockerClient{
+		Engine:      ctx.String(FlagCRTEngine),
 		APIVersion:  ctx.String(FlagAPIVersion),
 		UseTLS:      ctx.Bool(FlagUseTLS),
 		VerifyTLS:   ctx.Bool(FlagVerifyTLS),
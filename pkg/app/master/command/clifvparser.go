@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
@@ -222,6 +223,26 @@ func ParseTokenMap(values []string) (map[string]string, error) {
 	return tokens, nil
 }
 
+// ParseHTTPProbeAPISpecCredentials parses the API spec credentials ('<scheme>=<value>')
+func ParseHTTPProbeAPISpecCredentials(values []string) (map[string]string, error) {
+	creds := map[string]string{}
+	for _, raw := range values {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		parts := strings.SplitN(raw, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid api spec credential format (expected '<scheme>=<value>')")
+		}
+
+		creds[strings.TrimSpace(parts[0])] = parts[1]
+	}
+
+	return creds, nil
+}
+
 func ParseCheckTags(values []string) (map[string]string, error) {
 	tags := map[string]string{}
 	for _, raw := range values {
@@ -712,6 +733,26 @@ func ParseHTTPProbesFile(filePath string) ([]config.HTTPProbeCmd, error) {
 				//will load the data at runtime
 			}
 
+			if cmd.GRPC != nil && cmd.GRPC.DescriptorSetFile != "" {
+				dsFullPath, err := filepath.Abs(cmd.GRPC.DescriptorSetFile)
+				if err != nil {
+					return nil, err
+				}
+
+				_, err = os.Stat(dsFullPath)
+				if err != nil {
+					return nil, err
+				}
+
+				cmd.GRPC.DescriptorSetFile = dsFullPath
+			}
+
+			if cmd.Expect != nil {
+				if err := validateHTTPProbeExpectation(cmd.Expect); err != nil {
+					return nil, fmt.Errorf("invalid HTTP probe command expectation (%s): %v", cmd.Resource, err)
+				}
+			}
+
 			probes = append(probes, cmd)
 		}
 	}
@@ -719,6 +760,28 @@ func ParseHTTPProbesFile(filePath string) ([]config.HTTPProbeCmd, error) {
 	return probes, nil
 }
 
+func validateHTTPProbeExpectation(expect *config.HTTPProbeExpectation) error {
+	for _, code := range expect.Status {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("bad status code - %d", code)
+		}
+	}
+
+	for name, pattern := range expect.Headers {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("bad header (%s) regex - %v", name, err)
+		}
+	}
+
+	if expect.BodyRegex != "" {
+		if _, err := regexp.Compile(expect.BodyRegex); err != nil {
+			return fmt.Errorf("bad body regex - %v", err)
+		}
+	}
+
+	return nil
+}
+
 func isMethod(value string) bool {
 	switch strings.ToUpper(value) {
 	case "HEAD", "GET", "POST", "PUT", "DELETE", "PATCH":
@@ -764,6 +827,62 @@ func ParseHTTPProbesPorts(portList string) ([]uint16, error) {
 	return ports, nil
 }
 
+var tcpProbeProtoAliases = map[string]string{
+	"postgres": config.TCPProtoPostgreSQL,
+	"mariadb":  config.TCPProtoMySQL,
+	"mongo":    config.TCPProtoMongoDB,
+}
+
+// ParseTCPProbePorts parses the TCP probe port list ('[protocol:]port,...')
+// (the protocol for the well-known ports is optional)
+func ParseTCPProbePorts(portList string) ([]config.TCPProbeTarget, error) {
+	var targets []config.TCPProbeTarget
+	if portList == "" {
+		return targets, nil
+	}
+
+	for _, part := range strings.Split(portList, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var proto string
+		portStr := part
+		if idx := strings.LastIndex(part, ":"); idx != -1 {
+			proto = strings.ToLower(part[:idx])
+			portStr = part[idx+1:]
+		}
+
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil || !isPortNum(int(port)) {
+			return nil, fmt.Errorf("malformed tcp probe port - %s", part)
+		}
+
+		if alias, found := tcpProbeProtoAliases[proto]; found {
+			proto = alias
+		}
+
+		if proto == "" {
+			proto = config.TCPProtoGeneric
+			if defaultProto, found := config.TCPProbeDefaultProtocols[uint16(port)]; found {
+				proto = defaultProto
+			}
+		}
+
+		if !config.IsTCPProbeProto(proto) {
+			return nil, fmt.Errorf("unsupported tcp probe protocol - %s", part)
+		}
+
+		targets = append(targets, config.TCPProbeTarget{
+			Protocol: proto,
+			Port:     uint16(port),
+		})
+	}
+
+	return targets, nil
+}
+
 func ParseHTTPProbeExecFile(filePath string) ([]string, error) {
 	var appCalls []string
 
@@ -19,8 +19,10 @@ import (
 
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/crt"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerutil"
+	"github.com/slimtoolkit/slim/pkg/sbom"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 	"github.com/slimtoolkit/slim/pkg/version"
@@ -364,6 +366,7 @@ var GlobalFlagSuggestions = []prompt.Suggest{
 	{Text: FullFlagName(FlagUseTLS), Description: FlagUseTLSUsage},
 	{Text: FullFlagName(FlagVerifyTLS), Description: FlagVerifyTLSUsage},
 	{Text: FullFlagName(FlagTLSCertPath), Description: FlagTLSCertPathUsage},
+	{Text: FullFlagName(FlagCRTEngine), Description: FlagCRTEngineUsage},
 	{Text: FullFlagName(FlagHost), Description: FlagHostUsage},
 	{Text: FullFlagName(FlagArchiveState), Description: FlagArchiveStateUsage},
 	{Text: FullFlagName(FlagInContainer), Description: FlagInContainerUsage},
@@ -374,6 +377,7 @@ var GlobalFlagSuggestions = []prompt.Suggest{
 var GlobalFlagValueSuggestions = map[string]CompleteValue{
 	FullFlagName(FlagQuietCLIMode): CompleteBool,
 	FullFlagName(FlagOutputFormat): CompleteOutputFormat,
+	FullFlagName(FlagCRTEngine):    CompleteCRTEngine,
 	FullFlagName(FlagDebug):        CompleteBool,
 	FullFlagName(FlagVerbose):      CompleteBool,
 	FullFlagName(FlagNoColor):      CompleteBool,
@@ -411,11 +415,22 @@ var consoleOutputValues = []prompt.Suggest{
 	{Text: OutputFormatJSON, Description: "JSON output format"},
 }
 
+var crtEngineValues = []prompt.Suggest{
+	{Text: crt.DockerEngine, Description: "Default, use Docker"},
+	{Text: crt.PodmanEngine, Description: "Use Podman (the Podman API service socket)"},
+	{Text: crt.ContainerdEngine, Description: "Use containerd (with nerdctl)"},
+}
+
 var ipcModeValues = []prompt.Suggest{
 	{Text: "proxy", Description: "Proxy sensor ipc mode"},
 	{Text: "direct", Description: "Direct sensor ipc mode"},
 }
 
+var sbomFormatValues = []prompt.Suggest{
+	{Text: sbom.FormatSPDXJSON, Description: "SPDX 2.3 JSON"},
+	{Text: sbom.FormatCycloneDXJSON, Description: "CycloneDX 1.5 JSON"},
+}
+
 func CompleteProgress(ia *InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
 	switch runtime.GOOS {
 	case "darwin":
@@ -441,10 +456,18 @@ func CompleteOutputFormat(ia *InteractiveApp, token string, params prompt.Docume
 	return prompt.FilterHasPrefix(consoleOutputValues, token, true)
 }
 
+func CompleteCRTEngine(ia *InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
+	return prompt.FilterHasPrefix(crtEngineValues, token, true)
+}
+
 func CompleteIPCMode(ia *InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
 	return prompt.FilterHasPrefix(ipcModeValues, token, true)
 }
 
+func CompleteSBOMFormat(ia *InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
+	return prompt.FilterHasPrefix(sbomFormatValues, token, true)
+}
+
 func CompleteImage(ia *InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
 	images, err := dockerutil.ListImages(ia.dclient, "")
 	if err != nil {
@@ -1,6 +1,9 @@
 package containerize
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/urfave/cli/v2"
 
 	"github.com/slimtoolkit/slim/pkg/app"
@@ -14,9 +17,21 @@ const (
 )
 
 var CLI = &cli.Command{
-	Name:    Name,
-	Aliases: []string{Alias},
-	Usage:   Usage,
+	Name:      Name,
+	Aliases:   []string{Alias},
+	Usage:     Usage,
+	ArgsUsage: "APP_PATH [APP_ARGS...]",
+	Flags: []cli.Flag{
+		command.Cflag(command.FlagTarget),
+		cflag(FlagTag),
+		cflag(FlagWorkdir),
+		cflag(FlagEnv),
+		cflag(FlagIncludePath),
+		cflag(FlagExcludePattern),
+		cflag(FlagIncludeShell),
+		cflag(FlagRunTimeout),
+		cflag(FlagImageOutputTar),
+	},
 	Action: func(ctx *cli.Context) error {
 		gcvalues := command.GlobalFlagValues(ctx)
 		xc := app.NewExecutionContext(
@@ -24,22 +39,79 @@ var CLI = &cli.Command{
 			gcvalues.QuietCLIMode,
 			gcvalues.OutputFormat)
 
+		args := ctx.Args().Slice()
 		targetRef := ctx.String(command.FlagTarget)
 		if targetRef == "" {
-			if ctx.Args().Len() < 1 {
+			if len(args) < 1 {
 				xc.Out.Error("param.target", "missing target")
 				cli.ShowCommandHelp(ctx, Name)
 				return nil
 			} else {
-				targetRef = ctx.Args().First()
+				targetRef = args[0]
+				args = args[1:]
 			}
 		}
 
+		cparams, err := CommandFlagValues(ctx)
+		if err != nil {
+			xc.Out.Error("param.error", err.Error())
+			cli.ShowCommandHelp(ctx, Name)
+			return nil
+		}
+
+		cparams.AppPath = targetRef
+		cparams.AppArgs = args
+
 		OnCommand(
 			xc,
 			gcvalues,
-			targetRef)
+			cparams)
 
 		return nil
 	},
 }
+
+type CommandParams struct {
+	AppPath         string   `json:"app_path"`
+	AppArgs         []string `json:"app_args,omitempty"`
+	OutputTags      []string `json:"output_tags,omitempty"`
+	Workdir         string   `json:"workdir,omitempty"`
+	Env             []string `json:"env,omitempty"`
+	IncludePaths    []string `json:"include_paths,omitempty"`
+	ExcludePatterns []string `json:"exclude_patterns,omitempty"`
+	IncludeShell    bool     `json:"include_shell,omitempty"`
+	RunTimeout      int      `json:"run_timeout,omitempty"`
+	ImageOutputTar  string   `json:"image_output_tar,omitempty"`
+}
+
+func CommandFlagValues(ctx *cli.Context) (*CommandParams, error) {
+	values := &CommandParams{
+		OutputTags:      ctx.StringSlice(FlagTag),
+		Workdir:         ctx.String(FlagWorkdir),
+		IncludePaths:    ctx.StringSlice(FlagIncludePath),
+		ExcludePatterns: ctx.StringSlice(FlagExcludePattern),
+		IncludeShell:    ctx.Bool(FlagIncludeShell),
+		RunTimeout:      ctx.Int(FlagRunTimeout),
+		ImageOutputTar:  ctx.String(FlagImageOutputTar),
+	}
+
+	for _, env := range ctx.StringSlice(FlagEnv) {
+		if !strings.Contains(env, "=") || strings.HasPrefix(env, "=") {
+			return nil, fmt.Errorf("invalid env value (expected NAME=VALUE) - '%s'", env)
+		}
+
+		values.Env = append(values.Env, env)
+	}
+
+	for _, p := range values.IncludePaths {
+		if !strings.HasPrefix(p, "/") {
+			return nil, fmt.Errorf("include-path value must be absolute - '%s'", p)
+		}
+	}
+
+	if values.RunTimeout < 0 {
+		return nil, fmt.Errorf("invalid run-timeout value - %d", values.RunTimeout)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,90 @@
+package containerize
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// Containerize command flag names
+const (
+	FlagTag            = "tag"
+	FlagWorkdir        = "workdir"
+	FlagEnv            = "env"
+	FlagIncludePath    = "include-path"
+	FlagExcludePattern = "exclude-pattern"
+	FlagIncludeShell   = "include-shell"
+	FlagRunTimeout     = "run-timeout"
+	FlagImageOutputTar = "image-output-tar"
+)
+
+// Containerize command flag usage info
+const (
+	FlagTagUsage            = "Custom tags for the output image"
+	FlagWorkdirUsage        = "Working directory for the target app (current directory by default)"
+	FlagEnvUsage            = "Environment variables for the target app (also saved in the output image)"
+	FlagIncludePathUsage    = "Keep path from the local file system in the output image"
+	FlagExcludePatternUsage = "Exclude path pattern (Glob/Match in Go and **) from the output image"
+	FlagIncludeShellUsage   = "Include basic shell functionality in the output image"
+	FlagRunTimeoutUsage     = "Stop the target app after the specified number of seconds (0 waits for the app to exit)"
+	FlagImageOutputTarUsage = "Save the output image to a tar file instead of loading it into the Docker daemon"
+)
+
+var Flags = map[string]cli.Flag{
+	FlagTag: &cli.StringSliceFlag{
+		Name:    FlagTag,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagTagUsage,
+		EnvVars: []string{"DSLIM_CONTAINERIZE_TAG"},
+	},
+	FlagWorkdir: &cli.StringFlag{
+		Name:    FlagWorkdir,
+		Value:   "",
+		Usage:   FlagWorkdirUsage,
+		EnvVars: []string{"DSLIM_CONTAINERIZE_WORKDIR"},
+	},
+	FlagEnv: &cli.StringSliceFlag{
+		Name:    FlagEnv,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagEnvUsage,
+		EnvVars: []string{"DSLIM_CONTAINERIZE_ENV"},
+	},
+	FlagIncludePath: &cli.StringSliceFlag{
+		Name:    FlagIncludePath,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagIncludePathUsage,
+		EnvVars: []string{"DSLIM_CONTAINERIZE_INCLUDE_PATH"},
+	},
+	FlagExcludePattern: &cli.StringSliceFlag{
+		Name:    FlagExcludePattern,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagExcludePatternUsage,
+		EnvVars: []string{"DSLIM_CONTAINERIZE_EXCLUDE_PATTERN"},
+	},
+	FlagIncludeShell: &cli.BoolFlag{
+		Name:    FlagIncludeShell,
+		Value:   false, //defaults to false
+		Usage:   FlagIncludeShellUsage,
+		EnvVars: []string{"DSLIM_CONTAINERIZE_INCLUDE_SHELL"},
+	},
+	FlagRunTimeout: &cli.IntFlag{
+		Name:    FlagRunTimeout,
+		Value:   0,
+		Usage:   FlagRunTimeoutUsage,
+		EnvVars: []string{"DSLIM_CONTAINERIZE_RUN_TIMEOUT"},
+	},
+	FlagImageOutputTar: &cli.StringFlag{
+		Name:    FlagImageOutputTar,
+		Value:   "",
+		Usage:   FlagImageOutputTarUsage,
+		EnvVars: []string{"DSLIM_CONTAINERIZE_IMAGE_OUTPUT_TAR"},
+	},
+}
+
+func cflag(name string) cli.Flag {
+	cf, ok := Flags[name]
+	if !ok {
+		log.Fatalf("unknown flag='%s'", name)
+	}
+
+	return cf
+}
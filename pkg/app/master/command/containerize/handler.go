@@ -1,11 +1,23 @@
 package containerize
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/sensor"
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
+	"github.com/slimtoolkit/slim/pkg/imagebuilder"
+	"github.com/slimtoolkit/slim/pkg/imagebuilder/internalbuilder"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
@@ -18,11 +30,13 @@ const appName = command.AppName
 
 type ovars = app.OutVars
 
+var invalidImageNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
 // OnCommand implements the 'containerize' command
 func OnCommand(
 	xc *app.ExecutionContext,
 	gparams *command.GenericParams,
-	targetRef string) {
+	cparams *CommandParams) {
 	const cmdName = Name
 	logger := log.WithFields(log.Fields{"app": appName, "cmd": cmdName})
 
@@ -34,39 +48,218 @@ func OnCommand(
 	xc.Out.State("started")
 	xc.Out.Info("params",
 		ovars{
-			"target": targetRef,
+			"target": cparams.AppPath,
+			"args":   strings.Join(cparams.AppArgs, " "),
 		})
 
-	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
-		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
-			exitMsg = "make sure to pass the Docker connect parameters to the slim app container"
-		}
+	if runtime.GOOS != "linux" {
+		xc.Out.Error("platform.not.supported", "containerize works only with local apps on Linux")
+
+		cmdReport.State = cmd.StateError
+		exitCode := command.ECTCommon | command.ECCOther
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": exitCode,
+			})
+		xc.Exit(exitCode)
+	}
 
-		xc.Out.Info("docker.connect.error",
+	if os.Geteuid() != 0 {
+		//the sensor monitors (fanotify and ptrace) need root privileges
+		xc.Out.Error("permissions", "containerize needs to run as root to trace the target app")
+
+		cmdReport.State = cmd.StateError
+		exitCode := command.ECTCommon | command.ECCOther
+		xc.Out.State("exited",
 			ovars{
-				"message": exitMsg,
+				"exit.code": exitCode,
 			})
+		xc.Exit(exitCode)
+	}
+
+	appPath, err := exec.LookPath(cparams.AppPath)
+	if err == nil {
+		appPath, err = filepath.Abs(appPath)
+	}
+
+	if err != nil {
+		xc.Out.Error("app.not.found", err.Error())
 
-		exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
+		cmdReport.State = cmd.StateError
+		exitCode := command.ECTCommon | command.ECCOther
 		xc.Out.State("exited",
 			ovars{
 				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
 			})
 		xc.Exit(exitCode)
 	}
-	errutil.FailOn(err)
 
-	if gparams.Debug {
-		version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+	cmdReport.AppPath = appPath
+	cmdReport.AppArgs = cparams.AppArgs
+
+	workDir := cparams.Workdir
+	if workDir == "" {
+		workDir, err = os.Getwd()
+		xc.FailOn(err)
+	}
+
+	workDir, err = filepath.Abs(workDir)
+	xc.FailOn(err)
+
+	pushToDaemon := cparams.ImageOutputTar == ""
+	if pushToDaemon {
+		//the Docker connection is needed only to load the output image
+		client, err := dockerclient.New(gparams.ClientConfig)
+		if err == dockerclient.ErrNoDockerInfo {
+			exitMsg := "missing Docker connection info"
+			if gparams.InContainer && gparams.IsDSImage {
+				exitMsg = "make sure to pass the Docker connect parameters to the slim app container"
+			}
+
+			xc.Out.Info("docker.connect.error",
+				ovars{
+					"message": exitMsg,
+				})
+
+			exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+					"version":   v.Current(),
+					"location":  fsutil.ExeDir(),
+				})
+			xc.Exit(exitCode)
+		}
+		errutil.FailOn(err)
+
+		if gparams.Debug {
+			version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		}
+	}
+
+	outputTags := cparams.OutputTags
+	if len(outputTags) == 0 {
+		outputTags = append(outputTags, defaultImageName(appPath))
+	}
+
+	sensorPath := sensor.EnsureLocalBinary(xc, logger, gparams.StatePath, true)
+
+	artifactsDir, err := os.MkdirTemp("", "slim-containerize-")
+	xc.FailOn(err)
+
+	//the artifacts dir is removed only after the output image is created
+	//(it's kept if the command fails, so the sensor logs can be checked)
+	failOn := func(err error) {
+		if err != nil {
+			xc.Out.Info("app.artifacts.kept",
+				ovars{
+					"location": artifactsDir,
+				})
+			xc.FailOn(err)
+		}
+	}
+
+	xc.Out.State("app.trace.start",
+		ovars{
+			"app":      appPath,
+			"workdir":  workDir,
+			"location": artifactsDir,
+		})
+
+	appRunner := &appSensor{
+		logger:       logger,
+		sensorPath:   sensorPath,
+		artifactsDir: artifactsDir,
+		workDir:      workDir,
+		env:          cparams.Env,
+		runTimeout:   time.Duration(cparams.RunTimeout) * time.Second,
+		debug:        gparams.Debug,
+		logLevel:     gparams.LogLevel,
+	}
+
+	startCmd := newStartMonitorCommand(appPath, cparams.AppArgs, cparams, artifactsDir)
+	err = appRunner.Run(startCmd)
+	failOn(err)
+
+	filesDir := filepath.Join(artifactsDir, app.ArtifactFilesDirName)
+	if !fsutil.DirExists(filesDir) {
+		failOn(fmt.Errorf("no app artifacts - %s", filesDir))
+	}
+
+	artifactCount := 0
+	err = filepath.Walk(filesDir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			artifactCount++
+		}
+		return nil
+	})
+	failOn(err)
+
+	cmdReport.ArtifactCount = artifactCount
+	xc.Out.Info("app.artifacts",
+		ovars{
+			"count": artifactCount,
+		})
+	xc.Out.State("app.trace.done")
+
+	xc.Out.State("output.image.generate.start")
+
+	ibo := imagebuilder.SimpleBuildOptions{
+		Tags: outputTags,
+		ImageConfig: imagebuilder.ImageConfig{
+			Architecture: runtime.GOARCH,
+			OS:           runtime.GOOS,
+			Config: imagebuilder.RunConfig{
+				Entrypoint: []string{appPath},
+				Cmd:        cparams.AppArgs,
+				Env:        cparams.Env,
+				WorkingDir: workDir,
+			},
+		},
+		Layers: []imagebuilder.LayerDataInfo{
+			{
+				Type:   imagebuilder.DirSource,
+				Source: filesDir,
+				Params: &imagebuilder.DataParams{
+					TargetPath: "/",
+				},
+			},
+		},
+	}
+
+	engine, err := internalbuilder.New(
+		false, //show build logs doShowBuildLogs,
+		pushToDaemon,
+		false)
+	failOn(err)
+
+	engine.OutputImageTar = cparams.ImageOutputTar
+
+	imageResult, err := engine.Build(ibo)
+	failOn(err)
+
+	if err := os.RemoveAll(artifactsDir); err != nil {
+		logger.Debugf("error removing the artifacts dir (%s) - %v", artifactsDir, err)
 	}
 
-	xc.Out.State("completed")
+	cmdReport.OutputImage = imageResult.Name
+	cmdReport.OutputImageID = imageResult.ID
+	cmdReport.OutputImageDigest = imageResult.Digest
+	cmdReport.OutputImageTar = cparams.ImageOutputTar
+
+	xc.Out.Info("results.output",
+		ovars{
+			"image.name":   imageResult.Name,
+			"image.id":     imageResult.ID,
+			"image.digest": imageResult.Digest,
+			"image.tar":    cparams.ImageOutputTar,
+		})
+
+	xc.Out.State("output.image.generate.done")
+
+	xc.Out.State(cmd.StateCompleted)
 	cmdReport.State = cmd.StateCompleted
-	xc.Out.State("done")
+	xc.Out.State(cmd.StateDone)
 
 	vinfo := <-viChan
 	version.PrintCheckVersion(xc, "", vinfo)
@@ -79,3 +272,15 @@ func OnCommand(
 			})
 	}
 }
+
+// defaultImageName creates the output image name from the app file name
+func defaultImageName(appPath string) string {
+	name := strings.ToLower(filepath.Base(appPath))
+	name = invalidImageNameChars.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "._-")
+	if name == "" {
+		name = "app"
+	}
+
+	return fmt.Sprintf("slim-%s:latest", name)
+}
@@ -0,0 +1,55 @@
+package containerize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slimtoolkit/slim/pkg/util/fsutil"
+)
+
+func TestDefaultImageName(t *testing.T) {
+	tt := []struct {
+		in       string
+		expected string
+	}{
+		{in: "/usr/local/bin/server", expected: "slim-server:latest"},
+		{in: "./MyApp", expected: "slim-myapp:latest"},
+		{in: "/opt/app/web server+v2", expected: "slim-web-server-v2:latest"},
+		{in: "/opt/app/.hidden_", expected: "slim-hidden:latest"},
+		{in: "/opt/app/app.py", expected: "slim-app.py:latest"},
+		{in: "/opt/app/+++", expected: "slim-app:latest"},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, defaultImageName(test.in), test.in)
+	}
+}
+
+func TestNewStartMonitorCommand(t *testing.T) {
+	cparams := &CommandParams{
+		IncludeShell:    true,
+		IncludePaths:    []string{"/etc/ssl/certs"},
+		ExcludePatterns: []string{"/tmp/**"},
+	}
+
+	cmd := newStartMonitorCommand("/usr/bin/app", []string{"--port", "8080"}, cparams, "/tmp/slim-containerize-1")
+	assert.Equal(t, "/usr/bin/app", cmd.AppName)
+	assert.Equal(t, []string{"--port", "8080"}, cmd.AppArgs)
+	assert.True(t, cmd.IncludeShell)
+	//only the files from the traced app processes are saved
+	assert.True(t, cmd.RTASourcePT)
+	assert.True(t, cmd.TracedProcessFilesOnly)
+	assert.True(t, cmd.ReportOnMainPidExit)
+	//the sensor artifacts are not saved
+	assert.Equal(t, []string{
+		"/tmp/slim-containerize-1",
+		"/tmp/slim-containerize-1/**",
+		"/tmp/**",
+	}, cmd.Excludes)
+	assert.Equal(t, map[string]*fsutil.AccessInfo{"/etc/ssl/certs": nil}, cmd.Includes)
+
+	cmd = newStartMonitorCommand("/usr/bin/app", nil, &CommandParams{}, "/tmp/slim-containerize-2")
+	assert.Nil(t, cmd.Includes)
+	assert.False(t, cmd.IncludeShell)
+}
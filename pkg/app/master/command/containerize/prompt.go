@@ -2,9 +2,28 @@ package containerize
 
 import (
 	"github.com/c-bata/go-prompt"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
 )
 
 var CommandSuggestion = prompt.Suggest{
 	Text:        Name,
 	Description: Usage,
 }
+
+var CommandFlagSuggestions = &command.FlagSuggestions{
+	Names: []prompt.Suggest{
+		{Text: command.FullFlagName(command.FlagTarget), Description: command.FlagTargetUsage},
+		{Text: command.FullFlagName(FlagTag), Description: FlagTagUsage},
+		{Text: command.FullFlagName(FlagWorkdir), Description: FlagWorkdirUsage},
+		{Text: command.FullFlagName(FlagEnv), Description: FlagEnvUsage},
+		{Text: command.FullFlagName(FlagIncludePath), Description: FlagIncludePathUsage},
+		{Text: command.FullFlagName(FlagExcludePattern), Description: FlagExcludePatternUsage},
+		{Text: command.FullFlagName(FlagIncludeShell), Description: FlagIncludeShellUsage},
+		{Text: command.FullFlagName(FlagRunTimeout), Description: FlagRunTimeoutUsage},
+		{Text: command.FullFlagName(FlagImageOutputTar), Description: FlagImageOutputTarUsage},
+	},
+	Values: map[string]command.CompleteValue{
+		command.FullFlagName(FlagIncludeShell): command.CompleteBool,
+	},
+}
@@ -0,0 +1,154 @@
+package containerize
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/ipc/command"
+	"github.com/slimtoolkit/slim/pkg/util/errutil"
+	"github.com/slimtoolkit/slim/pkg/util/fsutil"
+)
+
+const (
+	sensorCommandsFileName = "commands.json"
+	sensorLogFileName      = "sensor.log"
+)
+
+// appSensor runs the target app on the local host
+// using the sensor in the standalone mode (the sensor monitors
+// trace the app and the artifact processor saves the app files)
+type appSensor struct {
+	logger       *log.Entry
+	sensorPath   string
+	artifactsDir string
+	workDir      string
+	env          []string
+	runTimeout   time.Duration
+	debug        bool
+	logLevel     string
+}
+
+func newStartMonitorCommand(
+	appPath string,
+	appArgs []string,
+	cparams *CommandParams,
+	artifactsDir string) *command.StartMonitor {
+	cmd := &command.StartMonitor{
+		AppName:      appPath,
+		AppArgs:      appArgs,
+		KeepPerms:    true,
+		IncludeShell: cparams.IncludeShell,
+		//the sensor artifacts dir is on the same file system as the app,
+		//so it needs to be excluded from the monitored and saved files
+		Excludes: append([]string{
+			artifactsDir,
+			filepath.Join(artifactsDir, "**"),
+		}, cparams.ExcludePatterns...),
+		ReportOnMainPidExit: true,
+		//the sensor runs on the host, so only the files from
+		//the traced app processes are saved (needs ptrace)
+		RTASourcePT:            true,
+		TracedProcessFilesOnly: true,
+	}
+
+	if len(cparams.IncludePaths) > 0 {
+		cmd.Includes = map[string]*fsutil.AccessInfo{}
+		for _, p := range cparams.IncludePaths {
+			cmd.Includes[p] = nil
+		}
+	}
+
+	return cmd
+}
+
+// Run starts the sensor and waits for it to finish processing the app artifacts
+func (s *appSensor) Run(startCmd *command.StartMonitor) error {
+	logger := s.logger.WithField("op", "appSensor.Run")
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	cmdData, err := json.Marshal(startCmd)
+	if err != nil {
+		return err
+	}
+
+	commandsFile := filepath.Join(s.artifactsDir, sensorCommandsFileName)
+	if err := os.WriteFile(commandsFile, cmdData, 0644); err != nil {
+		return err
+	}
+
+	args := []string{
+		"-mode=standalone",
+		fmt.Sprintf("-command-file=%s", commandsFile),
+		fmt.Sprintf("-artifacts-dir=%s", s.artifactsDir),
+		fmt.Sprintf("-log-file=%s", filepath.Join(s.artifactsDir, sensorLogFileName)),
+	}
+
+	if s.debug {
+		args = append(args, "-debug")
+	}
+
+	if s.logLevel != "" {
+		args = append(args, fmt.Sprintf("-log-level=%s", s.logLevel))
+	}
+
+	sensorCmd := exec.Command(s.sensorPath, args...)
+	sensorCmd.Dir = s.workDir
+	sensorCmd.Env = append(os.Environ(), s.env...)
+	sensorCmd.Stdin = os.Stdin
+	sensorCmd.Stdout = os.Stdout
+	sensorCmd.Stderr = os.Stderr
+
+	logger.Debugf("starting sensor - %s %v", s.sensorPath, args)
+	if err := sensorCmd.Start(); err != nil {
+		return err
+	}
+
+	doneCh := make(chan error, 1)
+	go func() {
+		doneCh <- sensorCmd.Wait()
+	}()
+
+	//SIGINT from the terminal goes to the sensor directly (same process group),
+	//so it's only intercepted to keep the command running until the sensor is done
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	var timeoutCh <-chan time.Time
+	if s.runTimeout > 0 {
+		timer := time.NewTimer(s.runTimeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	for {
+		select {
+		case err := <-doneCh:
+			if err != nil {
+				logger.WithError(err).Debug("sensor exited with error")
+				return fmt.Errorf("sensor error (check %s) - %w",
+					filepath.Join(s.artifactsDir, sensorLogFileName), err)
+			}
+
+			return nil
+		case sig := <-sigCh:
+			logger.Debugf("received signal - %v", sig)
+			if sig == syscall.SIGTERM {
+				//the sensor forwards the signal to the target app
+				errutil.WarnOn(sensorCmd.Process.Signal(syscall.SIGTERM))
+			}
+		case <-timeoutCh:
+			logger.Debug("run timeout - stopping target app")
+			errutil.WarnOn(sensorCmd.Process.Signal(syscall.SIGTERM))
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package convert
 
 import (
+	"fmt"
+
 	"github.com/urfave/cli/v2"
 
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/imagereader"
 )
 
 const (
@@ -13,10 +16,22 @@ const (
 	Alias = "k"
 )
 
+// Media type formats
+const (
+	MediaTypesDocker = "docker"
+	MediaTypesOCI    = "oci"
+)
+
 var CLI = &cli.Command{
 	Name:    Name,
 	Aliases: []string{Alias},
 	Usage:   Usage,
+	Flags: []cli.Flag{
+		command.Cflag(command.FlagTarget),
+		cflag(FlagOutputImage),
+		cflag(FlagSquash),
+		cflag(FlagMediaTypes),
+	},
 	Action: func(ctx *cli.Context) error {
 		gcvalues := command.GlobalFlagValues(ctx)
 		xc := app.NewExecutionContext(
@@ -35,11 +50,62 @@ var CLI = &cli.Command{
 			}
 		}
 
+		cparams, err := CommandFlagValues(ctx, targetRef)
+		if err != nil {
+			xc.Out.Error("param.error", err.Error())
+			cli.ShowCommandHelp(ctx, Name)
+			return nil
+		}
+
 		OnCommand(
 			xc,
 			gcvalues,
-			targetRef)
+			cparams)
 
 		return nil
 	},
 }
+
+type CommandParams struct {
+	Source     *imagereader.Location `json:"source"`
+	Output     *imagereader.Location `json:"output"`
+	Squash     bool                  `json:"squash,omitempty"`
+	MediaTypes string                `json:"media_types,omitempty"`
+}
+
+func CommandFlagValues(ctx *cli.Context, targetRef string) (*CommandParams, error) {
+	values := &CommandParams{
+		Squash:     ctx.Bool(FlagSquash),
+		MediaTypes: ctx.String(FlagMediaTypes),
+	}
+
+	switch values.MediaTypes {
+	case "", MediaTypesDocker, MediaTypesOCI:
+	default:
+		return nil, fmt.Errorf("unsupported media types value - '%s'", values.MediaTypes)
+	}
+
+	var err error
+	values.Source, err = imagereader.ParseLocation(targetRef, imagereader.DockerDaemonLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	outputRef := ctx.String(FlagOutputImage)
+	if outputRef == "" {
+		return nil, fmt.Errorf("missing output image location")
+	}
+
+	values.Output, err = imagereader.ParseLocation(outputRef, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, loc := range []*imagereader.Location{values.Source, values.Output} {
+		if loc.Type == imagereader.RegistryLocation {
+			return nil, fmt.Errorf("registry image locations are not supported (use the 'registry' command)")
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,50 @@
+package convert
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// Convert command flag names
+const (
+	FlagOutputImage = "output-image"
+	FlagSquash      = "squash"
+	FlagMediaTypes  = "media-types"
+)
+
+// Convert command flag usage info
+const (
+	FlagOutputImageUsage = "Output image location (docker-daemon:IMAGE | docker-archive:PATH[:IMAGE] | oci:PATH[:REF])"
+	FlagSquashUsage      = "Squash all image layers into one layer"
+	FlagMediaTypesUsage  = "Rewrite the image media types ('docker' | 'oci'); keeps the original media types by default"
+)
+
+var Flags = map[string]cli.Flag{
+	FlagOutputImage: &cli.StringFlag{
+		Name:    FlagOutputImage,
+		Value:   "",
+		Usage:   FlagOutputImageUsage,
+		EnvVars: []string{"DSLIM_CONVERT_OUTPUT_IMAGE"},
+	},
+	FlagSquash: &cli.BoolFlag{
+		Name:    FlagSquash,
+		Value:   false, //defaults to false
+		Usage:   FlagSquashUsage,
+		EnvVars: []string{"DSLIM_CONVERT_SQUASH"},
+	},
+	FlagMediaTypes: &cli.StringFlag{
+		Name:    FlagMediaTypes,
+		Value:   "",
+		Usage:   FlagMediaTypesUsage,
+		EnvVars: []string{"DSLIM_CONVERT_MEDIA_TYPES"},
+	},
+}
+
+func cflag(name string) cli.Flag {
+	cf, ok := Flags[name]
+	if !ok {
+		log.Fatalf("unknown flag='%s'", name)
+	}
+
+	return cf
+}
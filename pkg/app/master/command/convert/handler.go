@@ -1,17 +1,34 @@
 package convert
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	log "github.com/sirupsen/logrus"
+
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/image"
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
+	"github.com/slimtoolkit/slim/pkg/imagereader"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 	v "github.com/slimtoolkit/slim/pkg/version"
-
-	log "github.com/sirupsen/logrus"
 )
 
 const appName = command.AppName
@@ -22,7 +39,7 @@ type ovars = app.OutVars
 func OnCommand(
 	xc *app.ExecutionContext,
 	gparams *command.GenericParams,
-	targetRef string) {
+	cparams *CommandParams) {
 	const cmdName = Name
 	logger := log.WithFields(log.Fields{"app": appName, "cmd": cmdName})
 
@@ -30,43 +47,139 @@ func OnCommand(
 
 	cmdReport := report.NewConvertCommand(gparams.ReportLocation, gparams.InContainer)
 	cmdReport.State = cmd.StateStarted
+	cmdReport.SourceImage = cparams.Source.String()
+	cmdReport.OutputImage = cparams.Output.String()
+	cmdReport.Squashed = cparams.Squash
+	cmdReport.MediaTypes = cparams.MediaTypes
 
 	xc.Out.State("started")
 	xc.Out.Info("params",
 		ovars{
-			"target": targetRef,
+			"target":      cparams.Source.String(),
+			"output":      cparams.Output.String(),
+			"squash":      cparams.Squash,
+			"media.types": cparams.MediaTypes,
 		})
 
-	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
-		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
-			exitMsg = "make sure to pass the Docker connect parameters to the slim app container"
+	//the Docker connection is needed only if one of the images is (or will be) in the Docker daemon
+	if cparams.Source.Type == imagereader.DockerDaemonLocation ||
+		cparams.Output.Type == imagereader.DockerDaemonLocation {
+		client, err := dockerclient.New(gparams.ClientConfig)
+		if err == dockerclient.ErrNoDockerInfo {
+			exitMsg := "missing Docker connection info"
+			if gparams.InContainer && gparams.IsDSImage {
+				exitMsg = "make sure to pass the Docker connect parameters to the slim app container"
+			}
+
+			xc.Out.Info("docker.connect.error",
+				ovars{
+					"message": exitMsg,
+				})
+
+			exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+					"version":   v.Current(),
+					"location":  fsutil.ExeDir(),
+				})
+			xc.Exit(exitCode)
 		}
+		errutil.FailOn(err)
 
-		xc.Out.Info("docker.connect.error",
-			ovars{
-				"message": exitMsg,
-			})
+		if gparams.Debug {
+			version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		}
 
-		exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
-		xc.Out.State("exited",
-			ovars{
-				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
-			})
-		xc.Exit(exitCode)
+		if cparams.Source.Type == imagereader.DockerDaemonLocation {
+			imageInspector, err := image.NewInspector(client, cparams.Source.Ref)
+			xc.FailOn(err)
+
+			noImage, err := imageInspector.NoImage()
+			errutil.FailOn(err)
+			if noImage {
+				xc.Out.Error("image.not.found", "make sure the target image already exists locally")
+
+				cmdReport.State = cmd.StateError
+				exitCode := command.ECTCommon | command.ECCImageNotFound
+				xc.Out.State("exited",
+					ovars{
+						"exit.code": exitCode,
+					})
+				xc.Exit(exitCode)
+			}
+
+			cparams.Source.Ref = imageInspector.ImageRef
+		}
+	} else if gparams.Debug {
+		version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
 	}
-	errutil.FailOn(err)
 
-	if gparams.Debug {
-		version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+	xc.Out.State("image.load.start")
+	img, err := imagereader.LoadImage(cparams.Source)
+	xc.FailOn(err)
+
+	srcMediaType, err := img.MediaType()
+	xc.FailOn(err)
+
+	srcLayers, err := img.Layers()
+	xc.FailOn(err)
+
+	xc.Out.Info("image.source",
+		ovars{
+			"media.type":  srcMediaType,
+			"layer.count": len(srcLayers),
+		})
+	xc.Out.State("image.load.done")
+
+	if cparams.Squash {
+		xc.Out.State("image.squash.start")
+		tmpDir, err := os.MkdirTemp("", "slim-convert-")
+		xc.FailOn(err)
+		//the squashed layer data is read from the temp dir when the output image is saved
+		defer os.RemoveAll(tmpDir)
+
+		img, err = squashImage(logger, img, tmpDir)
+		xc.FailOn(err)
+		xc.Out.State("image.squash.done")
 	}
 
-	xc.Out.State("completed")
+	if cparams.MediaTypes != "" {
+		xc.Out.State("image.media.types.rewrite.start")
+		img, err = rewriteMediaTypes(img, cparams.MediaTypes)
+		xc.FailOn(err)
+		xc.Out.State("image.media.types.rewrite.done")
+	}
+
+	xc.Out.State("image.save.start")
+	err = saveImage(logger, img, cparams.Output, cparams.Source)
+	xc.FailOn(err)
+	xc.Out.State("image.save.done")
+
+	outLayers, err := img.Layers()
+	xc.FailOn(err)
+
+	id, err := img.ConfigName()
+	xc.FailOn(err)
+
+	digest, err := img.Digest()
+	xc.FailOn(err)
+
+	cmdReport.OutputImageID = id.String()
+	cmdReport.OutputImageDigest = digest.String()
+	cmdReport.OutputLayerCount = len(outLayers)
+
+	xc.Out.Info("results.output",
+		ovars{
+			"image":        cparams.Output.String(),
+			"image.id":     cmdReport.OutputImageID,
+			"image.digest": cmdReport.OutputImageDigest,
+			"layer.count":  cmdReport.OutputLayerCount,
+		})
+
+	xc.Out.State(cmd.StateCompleted)
 	cmdReport.State = cmd.StateCompleted
-	xc.Out.State("done")
+	xc.Out.State(cmd.StateDone)
 
 	vinfo := <-viChan
 	version.PrintCheckVersion(xc, "", vinfo)
@@ -79,3 +192,217 @@ func OnCommand(
 			})
 	}
 }
+
+// squashImage creates a new image with all image layers merged into one layer
+func squashImage(logger *log.Entry, img v1.Image, tmpDir string) (v1.Image, error) {
+	logger = logger.WithField("op", "convert.squashImage")
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, err := img.MediaType()
+	if err != nil {
+		return nil, err
+	}
+
+	layerFile, err := os.Create(filepath.Join(tmpDir, "squashed-layer.tar"))
+	if err != nil {
+		return nil, err
+	}
+	defer layerFile.Close()
+
+	fsReader := mutate.Extract(img)
+	defer fsReader.Close()
+
+	if _, err := io.Copy(layerFile, fsReader); err != nil {
+		return nil, err
+	}
+
+	layer, err := tarball.LayerFromFile(layerFile.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	newConfig := cf.DeepCopy()
+	newConfig.RootFS.DiffIDs = nil
+	newConfig.History = nil
+
+	base := empty.Image
+	if mediaType == types.OCIManifestSchema1 {
+		base = mutate.MediaType(base, types.OCIManifestSchema1)
+		base = mutate.ConfigMediaType(base, types.OCIConfigJSON)
+	}
+
+	base, err = mutate.ConfigFile(base, newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	addendum := mutate.Addendum{
+		Layer: layer,
+		History: v1.History{
+			Created:   v1.Time{Time: time.Now()},
+			CreatedBy: "slim convert --squash",
+			Comment:   "squashed image layers",
+		},
+	}
+
+	if mediaType == types.OCIManifestSchema1 {
+		addendum.MediaType = types.OCILayer
+	}
+
+	return mutate.Append(base, addendum)
+}
+
+// rewriteMediaTypes creates a new image with the Docker or OCI media types
+// (the image layer data and the image config data stay the same)
+func rewriteMediaTypes(img v1.Image, format string) (v1.Image, error) {
+	manifestType := types.DockerManifestSchema2
+	configType := types.DockerConfigJSON
+	if format == MediaTypesOCI {
+		manifestType = types.OCIManifestSchema1
+		configType = types.OCIConfigJSON
+	}
+
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	base := mutate.MediaType(empty.Image, manifestType)
+	base = mutate.ConfigMediaType(base, configType)
+
+	var adds []mutate.Addendum
+	for _, layer := range layers {
+		layerType, err := layer.MediaType()
+		if err != nil {
+			return nil, err
+		}
+
+		newLayerType, err := convertLayerMediaType(layerType, format)
+		if err != nil {
+			return nil, err
+		}
+
+		adds = append(adds, mutate.Addendum{
+			Layer:     layer,
+			MediaType: newLayerType,
+		})
+	}
+
+	newImg, err := mutate.Append(base, adds...)
+	if err != nil {
+		return nil, err
+	}
+
+	//restore the original config (layer diff IDs and history are the same)
+	return mutate.ConfigFile(newImg, cf.DeepCopy())
+}
+
+func convertLayerMediaType(mt types.MediaType, format string) (types.MediaType, error) {
+	if format == MediaTypesOCI {
+		switch mt {
+		case types.DockerLayer:
+			return types.OCILayer, nil
+		case types.DockerUncompressedLayer:
+			return types.OCIUncompressedLayer, nil
+		case types.DockerForeignLayer:
+			return types.OCIRestrictedLayer, nil
+		default:
+			return mt, nil
+		}
+	}
+
+	switch mt {
+	case types.OCILayer:
+		return types.DockerLayer, nil
+	case types.OCIUncompressedLayer:
+		return types.DockerUncompressedLayer, nil
+	case types.OCIRestrictedLayer:
+		return types.DockerForeignLayer, nil
+	case types.OCILayerZStd:
+		return "", fmt.Errorf("zstd compressed layers can't use the docker media types")
+	default:
+		return mt, nil
+	}
+}
+
+func saveImage(
+	logger *log.Entry,
+	img v1.Image,
+	output *imagereader.Location,
+	source *imagereader.Location) error {
+	logger = logger.WithField("op", "convert.saveImage")
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	switch output.Type {
+	case imagereader.DockerDaemonLocation:
+		tag, err := name.NewTag(output.Ref)
+		if err != nil {
+			return err
+		}
+
+		resp, err := daemon.Write(tag, img)
+		if err != nil {
+			logger.WithError(err).Errorf("daemon.Write(%s)", output.Ref)
+			return err
+		}
+
+		logger.Debugf("daemon.Write response - %s", resp)
+		return nil
+	case imagereader.DockerArchiveLocation:
+		imageName := output.Ref
+		if imageName == "" {
+			imageName = source.Ref
+		}
+
+		if imageName == "" {
+			return fmt.Errorf("missing output image name (docker-archive:PATH:IMAGE)")
+		}
+
+		tag, err := name.NewTag(imageName)
+		if err != nil {
+			return err
+		}
+
+		if err := tarball.WriteToFile(output.Path, tag, img); err != nil {
+			logger.WithError(err).Errorf("tarball.WriteToFile(%s, %s)", output.Path, imageName)
+			return err
+		}
+
+		return nil
+	case imagereader.OCILayoutLocation:
+		layoutPath, err := layout.FromPath(output.Path)
+		if err != nil {
+			layoutPath, err = layout.Write(output.Path, empty.Index)
+			if err != nil {
+				logger.WithError(err).Errorf("layout.Write(%s)", output.Path)
+				return err
+			}
+		}
+
+		if output.Ref == "" {
+			return layoutPath.AppendImage(img)
+		}
+
+		annotations := map[string]string{
+			imagereader.AnnotationRefName: output.Ref,
+		}
+
+		return layoutPath.ReplaceImage(img,
+			match.Annotation(imagereader.AnnotationRefName, output.Ref),
+			layout.WithAnnotations(annotations))
+	default:
+		return fmt.Errorf("unsupported output image location type - '%s'", output.Type)
+	}
+}
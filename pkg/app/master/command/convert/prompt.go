@@ -2,9 +2,24 @@ package convert
 
 import (
 	"github.com/c-bata/go-prompt"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
 )
 
 var CommandSuggestion = prompt.Suggest{
 	Text:        Name,
 	Description: Usage,
 }
+
+var CommandFlagSuggestions = &command.FlagSuggestions{
+	Names: []prompt.Suggest{
+		{Text: command.FullFlagName(command.FlagTarget), Description: command.FlagTargetUsage},
+		{Text: command.FullFlagName(FlagOutputImage), Description: FlagOutputImageUsage},
+		{Text: command.FullFlagName(FlagSquash), Description: FlagSquashUsage},
+		{Text: command.FullFlagName(FlagMediaTypes), Description: FlagMediaTypesUsage},
+	},
+	Values: map[string]command.CompleteValue{
+		command.FullFlagName(command.FlagTarget): command.CompleteImage,
+		command.FullFlagName(FlagSquash):         command.CompleteBool,
+	},
+}
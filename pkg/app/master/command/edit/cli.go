@@ -1,10 +1,15 @@
 package edit
 
 import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
 )
 
 const (
@@ -17,6 +22,25 @@ var CLI = &cli.Command{
 	Name:    Name,
 	Aliases: []string{Alias},
 	Usage:   Usage,
+	Flags: []cli.Flag{
+		command.Cflag(command.FlagTarget),
+		cflag(FlagTag),
+		cflag(FlagNewEntrypoint),
+		cflag(FlagNewCmd),
+		cflag(FlagNewExpose),
+		cflag(FlagNewWorkdir),
+		cflag(FlagNewEnv),
+		cflag(FlagNewVolume),
+		cflag(FlagNewLabel),
+		cflag(FlagNewUser),
+		cflag(FlagRemoveExpose),
+		cflag(FlagRemoveEnv),
+		cflag(FlagRemoveLabel),
+		cflag(FlagRemoveVolume),
+		cflag(FlagAddFile),
+		cflag(FlagRemoveFile),
+		cflag(FlagImageOutputTar),
+	},
 	Action: func(ctx *cli.Context) error {
 		gcvalues := command.GlobalFlagValues(ctx)
 		xc := app.NewExecutionContext(
@@ -35,11 +59,160 @@ var CLI = &cli.Command{
 			}
 		}
 
+		cparams, err := CommandFlagValues(ctx)
+		if err != nil {
+			xc.Out.Error("param.error", err.Error())
+			cli.ShowCommandHelp(ctx, Name)
+			return nil
+		}
+
+		cparams.TargetRef = targetRef
+
 		OnCommand(
 			xc,
 			gcvalues,
-			targetRef)
+			cparams)
 
 		return nil
 	},
 }
+
+// FileAddInfo describes a local file or directory to add to the edited image
+type FileAddInfo struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+type CommandParams struct {
+	TargetRef      string                       `json:"target"`
+	OutputTags     []string                     `json:"output_tags,omitempty"`
+	Instructions   *config.ImageNewInstructions `json:"instructions,omitempty"`
+	AddFiles       []FileAddInfo                `json:"add_files,omitempty"`
+	RemoveFiles    []string                     `json:"remove_files,omitempty"`
+	ImageOutputTar string                       `json:"image_output_tar,omitempty"`
+}
+
+func CommandFlagValues(ctx *cli.Context) (*CommandParams, error) {
+	values := &CommandParams{
+		OutputTags:     ctx.StringSlice(FlagTag),
+		ImageOutputTar: ctx.String(FlagImageOutputTar),
+	}
+
+	instructions, err := GetImageInstructions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values.Instructions = instructions
+
+	for _, raw := range ctx.StringSlice(FlagAddFile) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		parts := strings.SplitN(raw, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid add-file value (expected local_path:image_path) - '%s'", raw)
+		}
+
+		if !strings.HasPrefix(parts[1], "/") {
+			return nil, fmt.Errorf("add-file image path must be absolute - '%s'", parts[1])
+		}
+
+		values.AddFiles = append(values.AddFiles, FileAddInfo{
+			Source: parts[0],
+			Target: parts[1],
+		})
+	}
+
+	for _, raw := range ctx.StringSlice(FlagRemoveFile) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(raw, "/") {
+			return nil, fmt.Errorf("remove-file path must be absolute - '%s'", raw)
+		}
+
+		values.RemoveFiles = append(values.RemoveFiles, raw)
+	}
+
+	return values, nil
+}
+
+func GetImageInstructions(ctx *cli.Context) (*config.ImageNewInstructions, error) {
+	entrypoint := ctx.String(FlagNewEntrypoint)
+	cmd := ctx.String(FlagNewCmd)
+	expose := ctx.StringSlice(FlagNewExpose)
+	removeExpose := ctx.StringSlice(FlagRemoveExpose)
+
+	instructions := &config.ImageNewInstructions{
+		Workdir: ctx.String(FlagNewWorkdir),
+		User:    ctx.String(FlagNewUser),
+		Env:     ctx.StringSlice(FlagNewEnv),
+	}
+
+	var err error
+	instructions.Volumes, err = command.ParseTokenSet(ctx.StringSlice(FlagNewVolume))
+	if err != nil {
+		return nil, err
+	}
+
+	instructions.Labels, err = command.ParseTokenMap(ctx.StringSlice(FlagNewLabel))
+	if err != nil {
+		return nil, err
+	}
+
+	instructions.RemoveLabels, err = command.ParseTokenSet(ctx.StringSlice(FlagRemoveLabel))
+	if err != nil {
+		return nil, err
+	}
+
+	instructions.RemoveEnvs, err = command.ParseTokenSet(ctx.StringSlice(FlagRemoveEnv))
+	if err != nil {
+		return nil, err
+	}
+
+	instructions.RemoveVolumes, err = command.ParseTokenSet(ctx.StringSlice(FlagRemoveVolume))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expose) > 0 {
+		instructions.ExposedPorts, err = command.ParseDockerExposeOpt(expose)
+		if err != nil {
+			log.Errorf("edit.GetImageInstructions: invalid expose options => %v", err)
+			return nil, err
+		}
+	}
+
+	if len(removeExpose) > 0 {
+		instructions.RemoveExposedPorts, err = command.ParseDockerExposeOpt(removeExpose)
+		if err != nil {
+			log.Errorf("edit.GetImageInstructions: invalid remove-expose options => %v", err)
+			return nil, err
+		}
+	}
+
+	instructions.Entrypoint, err = command.ParseExec(entrypoint)
+	if err != nil {
+		log.Errorf("edit.GetImageInstructions: invalid entrypoint option => %v", err)
+		return nil, err
+	}
+
+	//one space is a hacky way to indicate that you want to remove this instruction from the image
+	instructions.ClearEntrypoint = command.IsOneSpace(entrypoint)
+
+	instructions.Cmd, err = command.ParseExec(cmd)
+	if err != nil {
+		log.Errorf("edit.GetImageInstructions: invalid cmd option => %v", err)
+		return nil, err
+	}
+
+	//same hack to indicate you want to remove this instruction
+	instructions.ClearCmd = command.IsOneSpace(cmd)
+
+	return instructions, nil
+}
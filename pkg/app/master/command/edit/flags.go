@@ -0,0 +1,154 @@
+package edit
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// Edit command flag names
+const (
+	FlagTag            = "tag"
+	FlagNewEntrypoint  = "new-entrypoint"
+	FlagNewCmd         = "new-cmd"
+	FlagNewLabel       = "new-label"
+	FlagNewVolume      = "new-volume"
+	FlagNewExpose      = "new-expose"
+	FlagNewWorkdir     = "new-workdir"
+	FlagNewEnv         = "new-env"
+	FlagNewUser        = "new-user"
+	FlagRemoveVolume   = "remove-volume"
+	FlagRemoveExpose   = "remove-expose"
+	FlagRemoveEnv      = "remove-env"
+	FlagRemoveLabel    = "remove-label"
+	FlagAddFile        = "add-file"
+	FlagRemoveFile     = "remove-file"
+	FlagImageOutputTar = "image-output-tar"
+)
+
+// Edit command flag usage info
+const (
+	FlagTagUsage            = "Custom tags for the output image"
+	FlagNewEntrypointUsage  = "New ENTRYPOINT instruction for the edited image (one space value removes ENTRYPOINT)"
+	FlagNewCmdUsage         = "New CMD instruction for the edited image (one space value removes CMD)"
+	FlagNewVolumeUsage      = "New VOLUME instructions for the edited image"
+	FlagNewLabelUsage       = "New LABEL instructions for the edited image"
+	FlagNewExposeUsage      = "New EXPOSE instructions for the edited image"
+	FlagNewWorkdirUsage     = "New WORKDIR instruction for the edited image"
+	FlagNewEnvUsage         = "New ENV instructions for the edited image"
+	FlagNewUserUsage        = "New USER instruction for the edited image"
+	FlagRemoveExposeUsage   = "Remove EXPOSE instructions from the edited image"
+	FlagRemoveEnvUsage      = "Remove ENV instructions from the edited image"
+	FlagRemoveLabelUsage    = "Remove LABEL instructions from the edited image"
+	FlagRemoveVolumeUsage   = "Remove VOLUME instructions from the edited image"
+	FlagAddFileUsage        = "Add local file or directory to the edited image (format: local_path:image_path)"
+	FlagRemoveFileUsage     = "Remove file or directory from the edited image"
+	FlagImageOutputTarUsage = "Save the edited image to a tar file instead of loading it into the Docker daemon"
+)
+
+var Flags = map[string]cli.Flag{
+	FlagTag: &cli.StringSliceFlag{
+		Name:    FlagTag,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagTagUsage,
+		EnvVars: []string{"DSLIM_EDIT_TAG"},
+	},
+	FlagNewEntrypoint: &cli.StringFlag{
+		Name:    FlagNewEntrypoint,
+		Value:   "",
+		Usage:   FlagNewEntrypointUsage,
+		EnvVars: []string{"DSLIM_EDIT_NEW_ENTRYPOINT"},
+	},
+	FlagNewCmd: &cli.StringFlag{
+		Name:    FlagNewCmd,
+		Value:   "",
+		Usage:   FlagNewCmdUsage,
+		EnvVars: []string{"DSLIM_EDIT_NEW_CMD"},
+	},
+	FlagNewExpose: &cli.StringSliceFlag{
+		Name:    FlagNewExpose,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagNewExposeUsage,
+		EnvVars: []string{"DSLIM_EDIT_NEW_EXPOSE"},
+	},
+	FlagNewWorkdir: &cli.StringFlag{
+		Name:    FlagNewWorkdir,
+		Value:   "",
+		Usage:   FlagNewWorkdirUsage,
+		EnvVars: []string{"DSLIM_EDIT_NEW_WORKDIR"},
+	},
+	FlagNewEnv: &cli.StringSliceFlag{
+		Name:    FlagNewEnv,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagNewEnvUsage,
+		EnvVars: []string{"DSLIM_EDIT_NEW_ENV"},
+	},
+	FlagNewVolume: &cli.StringSliceFlag{
+		Name:    FlagNewVolume,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagNewVolumeUsage,
+		EnvVars: []string{"DSLIM_EDIT_NEW_VOLUME"},
+	},
+	FlagNewLabel: &cli.StringSliceFlag{
+		Name:    FlagNewLabel,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagNewLabelUsage,
+		EnvVars: []string{"DSLIM_EDIT_NEW_LABEL"},
+	},
+	FlagNewUser: &cli.StringFlag{
+		Name:    FlagNewUser,
+		Value:   "",
+		Usage:   FlagNewUserUsage,
+		EnvVars: []string{"DSLIM_EDIT_NEW_USER"},
+	},
+	FlagRemoveExpose: &cli.StringSliceFlag{
+		Name:    FlagRemoveExpose,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagRemoveExposeUsage,
+		EnvVars: []string{"DSLIM_EDIT_RM_EXPOSE"},
+	},
+	FlagRemoveEnv: &cli.StringSliceFlag{
+		Name:    FlagRemoveEnv,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagRemoveEnvUsage,
+		EnvVars: []string{"DSLIM_EDIT_RM_ENV"},
+	},
+	FlagRemoveLabel: &cli.StringSliceFlag{
+		Name:    FlagRemoveLabel,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagRemoveLabelUsage,
+		EnvVars: []string{"DSLIM_EDIT_RM_LABEL"},
+	},
+	FlagRemoveVolume: &cli.StringSliceFlag{
+		Name:    FlagRemoveVolume,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagRemoveVolumeUsage,
+		EnvVars: []string{"DSLIM_EDIT_RM_VOLUME"},
+	},
+	FlagAddFile: &cli.StringSliceFlag{
+		Name:    FlagAddFile,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagAddFileUsage,
+		EnvVars: []string{"DSLIM_EDIT_ADD_FILE"},
+	},
+	FlagRemoveFile: &cli.StringSliceFlag{
+		Name:    FlagRemoveFile,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagRemoveFileUsage,
+		EnvVars: []string{"DSLIM_EDIT_RM_FILE"},
+	},
+	FlagImageOutputTar: &cli.StringFlag{
+		Name:    FlagImageOutputTar,
+		Value:   "",
+		Usage:   FlagImageOutputTarUsage,
+		EnvVars: []string{"DSLIM_EDIT_IMAGE_OUTPUT_TAR"},
+	},
+}
+
+func cflag(name string) cli.Flag {
+	cf, ok := Flags[name]
+	if !ok {
+		log.Fatalf("unknown flag='%s'", name)
+	}
+
+	return cf
+}
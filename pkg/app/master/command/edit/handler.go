@@ -1,13 +1,33 @@
 package edit
 
 import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/image"
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
+	"github.com/slimtoolkit/slim/pkg/imagereader"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
@@ -22,7 +42,7 @@ type ovars = app.OutVars
 func OnCommand(
 	xc *app.ExecutionContext,
 	gparams *command.GenericParams,
-	targetRef string) {
+	cparams *CommandParams) {
 	const cmdName = Name
 	logger := log.WithFields(log.Fields{"app": appName, "cmd": cmdName})
 
@@ -30,43 +50,154 @@ func OnCommand(
 
 	cmdReport := report.NewEditCommand(gparams.ReportLocation, gparams.InContainer)
 	cmdReport.State = cmd.StateStarted
+	cmdReport.TargetReference = cparams.TargetRef
 
 	xc.Out.State("started")
 	xc.Out.Info("params",
 		ovars{
-			"target": targetRef,
+			"target":           cparams.TargetRef,
+			"output.tags":      cparams.OutputTags,
+			"add.files":        len(cparams.AddFiles),
+			"remove.files":     len(cparams.RemoveFiles),
+			"image.output.tar": cparams.ImageOutputTar,
 		})
 
-	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
-		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
-			exitMsg = "make sure to pass the Docker connect parameters to the slim app container"
+	target, err := imagereader.ParseLocation(cparams.TargetRef, imagereader.DockerDaemonLocation)
+	if err != nil {
+		xc.FailOn(fmt.Errorf("malformed target image location - %s (%v)", cparams.TargetRef, err))
+	}
+
+	//the Docker connection is needed only if the target image is in the Docker daemon
+	//or if the edited image is loaded into the Docker daemon
+	if target.Type == imagereader.DockerDaemonLocation || cparams.ImageOutputTar == "" {
+		client, err := dockerclient.New(gparams.ClientConfig)
+		if err == dockerclient.ErrNoDockerInfo {
+			exitMsg := "missing Docker connection info"
+			if gparams.InContainer && gparams.IsDSImage {
+				exitMsg = "make sure to pass the Docker connect parameters to the slim app container"
+			}
+
+			xc.Out.Info("docker.connect.error",
+				ovars{
+					"message": exitMsg,
+				})
+
+			exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+					"version":   v.Current(),
+					"location":  fsutil.ExeDir(),
+				})
+			xc.Exit(exitCode)
 		}
+		errutil.FailOn(err)
 
-		xc.Out.Info("docker.connect.error",
-			ovars{
-				"message": exitMsg,
-			})
+		if gparams.Debug {
+			version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		}
+
+		if target.Type == imagereader.DockerDaemonLocation {
+			imageInspector, err := image.NewInspector(client, target.Ref)
+			xc.FailOn(err)
+
+			noImage, err := imageInspector.NoImage()
+			errutil.FailOn(err)
+			if noImage {
+				xc.Out.Error("image.not.found", "make sure the target image already exists locally")
+
+				cmdReport.State = cmd.StateError
+				exitCode := command.ECTCommon | command.ECCImageNotFound
+				xc.Out.State("exited",
+					ovars{
+						"exit.code": exitCode,
+					})
+				xc.Exit(exitCode)
+			}
+
+			target.Ref = imageInspector.ImageRef
+			cparams.TargetRef = imageInspector.ImageRef
+			cmdReport.TargetReference = cparams.TargetRef
+		}
+	} else if gparams.Debug {
+		version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+	}
+
+	outputTags, err := outputImageTags(target, cparams.OutputTags)
+	xc.FailOn(err)
+
+	//the registry credentials are used only for the registry target images
+	imgReader, err := imagereader.NewFromLocation(target, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	xc.FailOn(err)
+
+	xc.Out.State("image.metadata.edit.start")
+	img := imgReader.Image()
+	configFile, err := img.ConfigFile()
+	xc.FailOn(err)
+
+	//config changes are applied to the original image (keeping its layers and history)
+	outConfigFile := configFile.DeepCopy()
+	applyImageInstructions(&outConfigFile.Config, cparams.Instructions)
+	img, err = mutate.ConfigFile(img, outConfigFile)
+	xc.FailOn(err)
+	xc.Out.State("image.metadata.edit.done")
+
+	if len(cparams.AddFiles) > 0 || len(cparams.RemoveFiles) > 0 {
+		xc.Out.State("image.data.edit.start")
+		removedCount, err := countRemovedPaths(imgReader.Image(), cparams.RemoveFiles)
+		xc.FailOn(err)
+
+		layerTarFileName, err := createEditLayerTar(logger, cparams.AddFiles, cparams.RemoveFiles)
+		xc.FailOn(err)
+		defer os.Remove(layerTarFileName)
+
+		layer, err := tarball.LayerFromFile(layerTarFileName)
+		xc.FailOn(err)
 
-		exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
-		xc.Out.State("exited",
+		img, err = mutate.Append(img, mutate.Addendum{
+			Layer: layer,
+			History: v1.History{
+				Created:   v1.Time{Time: time.Now().UTC()},
+				CreatedBy: editLayerCreatedBy(cparams.AddFiles, cparams.RemoveFiles),
+				Comment:   "slim edit",
+			},
+		})
+		xc.FailOn(err)
+
+		cmdReport.AddedFiles = len(cparams.AddFiles)
+		cmdReport.RemovedFiles = removedCount
+
+		xc.Out.Info("image.data.edit",
 			ovars{
-				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
+				"added":   len(cparams.AddFiles),
+				"removed": removedCount,
 			})
-		xc.Exit(exitCode)
+		xc.Out.State("image.data.edit.done")
 	}
-	errutil.FailOn(err)
 
-	if gparams.Debug {
-		version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
-	}
+	xc.Out.State("output.image.generate.start")
+
+	imageResult, err := saveImage(img, outputTags, cparams.ImageOutputTar)
+	xc.FailOn(err)
+
+	cmdReport.OutputImage = imageResult.Name
+	cmdReport.OutputImageID = imageResult.ID
+	cmdReport.OutputImageDigest = imageResult.Digest
+	cmdReport.OutputImageTar = cparams.ImageOutputTar
 
-	xc.Out.State("completed")
+	xc.Out.Info("results.output",
+		ovars{
+			"image.name":   imageResult.Name,
+			"image.id":     imageResult.ID,
+			"image.digest": imageResult.Digest,
+			"image.tar":    cparams.ImageOutputTar,
+		})
+
+	xc.Out.State("output.image.generate.done")
+
+	xc.Out.State(cmd.StateCompleted)
 	cmdReport.State = cmd.StateCompleted
-	xc.Out.State("done")
+	xc.Out.State(cmd.StateDone)
 
 	vinfo := <-viChan
 	version.PrintCheckVersion(xc, "", vinfo)
@@ -79,3 +210,355 @@ func OnCommand(
 			})
 	}
 }
+
+func applyImageInstructions(
+	imageConfig *v1.Config,
+	instructions *config.ImageNewInstructions) {
+	if instructions == nil {
+		return
+	}
+
+	//copy the map fields, so the original image config isn't modified
+	ports := map[string]struct{}{}
+	for k, v := range imageConfig.ExposedPorts {
+		ports[k] = v
+	}
+	imageConfig.ExposedPorts = ports
+
+	volumes := map[string]struct{}{}
+	for k, v := range imageConfig.Volumes {
+		volumes[k] = v
+	}
+	imageConfig.Volumes = volumes
+
+	labels := map[string]string{}
+	for k, v := range imageConfig.Labels {
+		labels[k] = v
+	}
+	imageConfig.Labels = labels
+
+	if instructions.Workdir != "" {
+		imageConfig.WorkingDir = instructions.Workdir
+	}
+
+	if instructions.User != "" {
+		imageConfig.User = instructions.User
+	}
+
+	if len(instructions.RemoveEnvs) > 0 || len(instructions.Env) > 0 {
+		//new env vars replace the existing env vars with the same name
+		newEnvNames := map[string]struct{}{}
+		for _, envPair := range instructions.Env {
+			envParts := strings.SplitN(envPair, "=", 2)
+			newEnvNames[envParts[0]] = struct{}{}
+		}
+
+		var env []string
+		for _, envPair := range imageConfig.Env {
+			envParts := strings.SplitN(envPair, "=", 2)
+			if _, ok := instructions.RemoveEnvs[envParts[0]]; ok {
+				continue
+			}
+
+			if _, ok := newEnvNames[envParts[0]]; ok {
+				continue
+			}
+
+			env = append(env, envPair)
+		}
+
+		imageConfig.Env = append(env, instructions.Env...)
+	}
+
+	for k := range instructions.RemoveExposedPorts {
+		delete(imageConfig.ExposedPorts, string(k))
+	}
+
+	for k, v := range instructions.ExposedPorts {
+		imageConfig.ExposedPorts[string(k)] = v
+	}
+
+	for k := range instructions.RemoveVolumes {
+		delete(imageConfig.Volumes, k)
+	}
+
+	for k, v := range instructions.Volumes {
+		imageConfig.Volumes[k] = v
+	}
+
+	for k := range instructions.RemoveLabels {
+		delete(imageConfig.Labels, k)
+	}
+
+	for k, v := range instructions.Labels {
+		imageConfig.Labels[k] = v
+	}
+
+	if instructions.ClearEntrypoint {
+		imageConfig.Entrypoint = nil
+	} else if len(instructions.Entrypoint) > 0 {
+		imageConfig.Entrypoint = instructions.Entrypoint
+	}
+
+	if instructions.ClearCmd {
+		imageConfig.Cmd = nil
+	} else if len(instructions.Cmd) > 0 {
+		imageConfig.Cmd = instructions.Cmd
+	}
+}
+
+// tarEntryPath returns the normalized absolute path for the tar entry name
+func tarEntryPath(name string) string {
+	return path.Clean("/" + strings.TrimPrefix(name, "./"))
+}
+
+const whiteoutPrefix = ".wh."
+
+func isRemovedPath(entryPath string, removePaths []string) bool {
+	for _, rp := range removePaths {
+		if entryPath == rp || strings.HasPrefix(entryPath, rp+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// outputImageTags returns the output image tags
+// (the default output image name is based on the target image reference)
+func outputImageTags(target *imagereader.Location, tags []string) ([]string, error) {
+	if len(tags) > 0 {
+		return tags, nil
+	}
+
+	if target.Ref == "" {
+		return nil, fmt.Errorf("missing output image name for the target image location - %s (use --%s)", target, FlagTag)
+	}
+
+	return []string{defaultOutputName(target.Ref)}, nil
+}
+
+// defaultOutputName returns the default edited image name
+// (the '.edited' suffix is added to the repository name, keeping the registry host port and the tag)
+func defaultOutputName(ref string) string {
+	if idx := strings.Index(ref, "@"); idx != -1 {
+		ref = ref[:idx]
+	}
+
+	nameStart := strings.LastIndex(ref, "/") + 1
+	if idx := strings.LastIndex(ref[nameStart:], ":"); idx != -1 {
+		tagIdx := nameStart + idx
+		return fmt.Sprintf("%s.edited%s", ref[:tagIdx], ref[tagIdx:])
+	}
+
+	return fmt.Sprintf("%s.edited", ref)
+}
+
+// countRemovedPaths returns the number of the image filesystem entries removed by the remove-file paths
+func countRemovedPaths(img v1.Image, removeFiles []string) (int, error) {
+	if len(removeFiles) == 0 {
+		return 0, nil
+	}
+
+	removePaths := cleanPaths(removeFiles)
+	fsReader := mutate.Extract(img)
+	defer fsReader.Close()
+
+	var count int
+	tr := tar.NewReader(fsReader)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+
+			return 0, err
+		}
+
+		if isRemovedPath(tarEntryPath(hdr.Name), removePaths) {
+			count++
+		}
+	}
+}
+
+// createEditLayerTar creates the layer tar with the whiteout entries for the removed paths
+// and the added local files (the whiteouts come first, so they don't hide the added files)
+func createEditLayerTar(
+	logger *log.Entry,
+	addFiles []FileAddInfo,
+	removeFiles []string) (layerTarPath string, err error) {
+	for _, info := range addFiles {
+		if !fsutil.Exists(info.Source) {
+			return "", fmt.Errorf("add-file source doesn't exist - %s", info.Source)
+		}
+	}
+
+	outFile, err := os.CreateTemp("", "image-edit-layer-*.tar")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		outFile.Close()
+		if err != nil {
+			os.Remove(outFile.Name())
+		}
+	}()
+
+	tw := tar.NewWriter(outFile)
+	for _, rp := range cleanPaths(removeFiles) {
+		if rp == "/" {
+			return "", fmt.Errorf("remove-file can't remove the root directory")
+		}
+
+		whiteout := path.Join(path.Dir(rp), whiteoutPrefix+path.Base(rp))
+		logger.Tracef("createEditLayerTar: whiteout %s", whiteout)
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     strings.TrimPrefix(whiteout, "/"),
+			Mode:     0644,
+			ModTime:  time.Now().UTC(),
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	for _, info := range addFiles {
+		if err := addLocalPathToTar(tw, info.Source, info.Target); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+
+	return outFile.Name(), nil
+}
+
+func editLayerCreatedBy(addFiles []FileAddInfo, removeFiles []string) string {
+	var parts []string
+	for _, info := range addFiles {
+		parts = append(parts, fmt.Sprintf("--add-file %s:%s", info.Source, info.Target))
+	}
+
+	for _, rp := range removeFiles {
+		parts = append(parts, fmt.Sprintf("--remove-file %s", rp))
+	}
+
+	return fmt.Sprintf("slim edit %s", strings.Join(parts, " "))
+}
+
+func cleanPaths(paths []string) []string {
+	var cleaned []string
+	for _, p := range paths {
+		cleaned = append(cleaned, path.Clean("/"+p))
+	}
+
+	return cleaned
+}
+
+type outputImageInfo struct {
+	Name   string
+	ID     string
+	Digest string
+}
+
+// saveImage saves the edited image to the Docker daemon or to the image tar file (if provided)
+func saveImage(img v1.Image, tags []string, outputTar string) (*outputImageInfo, error) {
+	var nameTags []name.Tag
+	for _, tag := range tags {
+		nameTag, err := name.NewTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		nameTags = append(nameTags, nameTag)
+	}
+
+	if outputTar != "" {
+		tagToImage := map[name.Tag]v1.Image{}
+		for _, tag := range nameTags {
+			tagToImage[tag] = img
+		}
+
+		if err := tarball.MultiWriteToFile(outputTar, tagToImage); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := daemon.Write(nameTags[0], img); err != nil {
+			return nil, err
+		}
+
+		for _, tag := range nameTags[1:] {
+			if err := daemon.Tag(nameTags[0], tag); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	id, err := img.ConfigName()
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	return &outputImageInfo{
+		Name:   tags[0],
+		ID:     id.String(),
+		Digest: digest.String(),
+	}, nil
+}
+
+func addLocalPathToTar(tw *tar.Writer, srcPath, targetPath string) error {
+	targetPath = path.Clean(targetPath)
+	return filepath.Walk(srcPath, func(fp string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcPath, fp)
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(fp); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = strings.TrimPrefix(path.Join(targetPath, filepath.ToSlash(rel)), "/")
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(fp)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
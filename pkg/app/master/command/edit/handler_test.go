@@ -0,0 +1,232 @@
+package edit
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/imagereader"
+)
+
+func TestDefaultOutputName(t *testing.T) {
+	tt := []struct {
+		in       string
+		expected string
+	}{
+		{in: "nginx", expected: "nginx.edited"},
+		{in: "nginx:1.25", expected: "nginx.edited:1.25"},
+		{in: "library/nginx:latest", expected: "library/nginx.edited:latest"},
+		{in: "host:5000/app:tag", expected: "host:5000/app.edited:tag"},
+		{in: "host:5000/app", expected: "host:5000/app.edited"},
+		{in: "host:5000/team/app@sha256:abcd", expected: "host:5000/team/app.edited"},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, defaultOutputName(test.in), test.in)
+	}
+}
+
+func TestApplyImageInstructions(t *testing.T) {
+	cfg := v1.Config{
+		Env:          []string{"A=1", "B=2", "C=3"},
+		Labels:       map[string]string{"keep": "1", "drop": "2"},
+		ExposedPorts: map[string]struct{}{"80/tcp": {}},
+		Volumes:      map[string]struct{}{"/data": {}},
+		Cmd:          []string{"run"},
+		Entrypoint:   []string{"/app"},
+	}
+
+	//the caller's maps (the image config file maps)
+	labels := cfg.Labels
+	ports := cfg.ExposedPorts
+	volumes := cfg.Volumes
+	applyImageInstructions(&cfg, &config.ImageNewInstructions{
+		Env:                []string{"B=20", "D=4"},
+		RemoveEnvs:         map[string]struct{}{"C": {}},
+		Labels:             map[string]string{"new": "3"},
+		RemoveLabels:       map[string]struct{}{"drop": {}},
+		ExposedPorts:       map[docker.Port]struct{}{"8080/tcp": {}},
+		RemoveExposedPorts: map[docker.Port]struct{}{"80/tcp": {}},
+		Volumes:            map[string]struct{}{"/cache": {}},
+		RemoveVolumes:      map[string]struct{}{"/data": {}},
+		ClearCmd:           true,
+		User:               "app",
+	})
+
+	assert.Equal(t, []string{"A=1", "B=20", "D=4"}, cfg.Env)
+	assert.Equal(t, map[string]string{"keep": "1", "new": "3"}, cfg.Labels)
+	assert.Equal(t, map[string]struct{}{"8080/tcp": {}}, cfg.ExposedPorts)
+	assert.Equal(t, map[string]struct{}{"/cache": {}}, cfg.Volumes)
+	assert.Nil(t, cfg.Cmd)
+	assert.Equal(t, []string{"/app"}, cfg.Entrypoint)
+	assert.Equal(t, "app", cfg.User)
+
+	//the original maps are not modified
+	assert.Equal(t, map[string]string{"keep": "1", "drop": "2"}, labels)
+	assert.Equal(t, map[string]struct{}{"80/tcp": {}}, ports)
+	assert.Equal(t, map[string]struct{}{"/data": {}}, volumes)
+}
+
+func TestOutputImageTags(t *testing.T) {
+	tt := []struct {
+		target   string
+		tags     []string
+		expected []string
+		err      bool
+	}{
+		{target: "nginx:1.25", expected: []string{"nginx.edited:1.25"}},
+		{target: "nginx:1.25", tags: []string{"my/nginx:v1", "my/nginx:latest"}, expected: []string{"my/nginx:v1", "my/nginx:latest"}},
+		{target: "docker-archive:/tmp/app.tar:app:v1", expected: []string{"app.edited:v1"}},
+		{target: "oci:/tmp/layout:app", expected: []string{"app.edited"}},
+		{target: "registry:ghcr.io/team/app:v1", expected: []string{"ghcr.io/team/app.edited:v1"}},
+		{target: "docker-archive:/tmp/app.tar", tags: []string{"app:edited"}, expected: []string{"app:edited"}},
+		//the image name is unknown
+		{target: "oci:/tmp/layout", err: true},
+	}
+
+	for _, test := range tt {
+		location, err := imagereader.ParseLocation(test.target, imagereader.DockerDaemonLocation)
+		require.NoError(t, err)
+
+		tags, err := outputImageTags(location, test.tags)
+		if test.err {
+			assert.Error(t, err, test.target)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, tags, test.target)
+	}
+}
+
+func TestSaveImageTar(t *testing.T) {
+	img, err := mutate.Config(empty.Image, v1.Config{User: "app"})
+	require.NoError(t, err)
+
+	outputTar := filepath.Join(t.TempDir(), "image.tar")
+	result, err := saveImage(img, []string{"my/app:v1", "my/app:latest"}, outputTar)
+	require.NoError(t, err)
+	assert.Equal(t, "my/app:v1", result.Name)
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, digest.String(), result.Digest)
+
+	//the edited image can be used as a 'docker-archive' target
+	location, err := imagereader.ParseLocation("docker-archive:"+outputTar+":my/app:latest", "")
+	require.NoError(t, err)
+
+	saved, err := imagereader.LoadImage(location)
+	require.NoError(t, err)
+
+	configFile, err := saved.ConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, "app", configFile.Config.User)
+}
+
+func TestCreateEditLayerTar(t *testing.T) {
+	srcDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "app.conf"), []byte("conf"), 0644))
+
+	layerPath, err := createEditLayerTar(
+		log.NewEntry(log.StandardLogger()),
+		[]FileAddInfo{{Source: filepath.Join(srcDir, "app.conf"), Target: "/etc/app/app.conf"}},
+		[]string{"/etc/app", "/tmp/cache/"})
+	require.NoError(t, err)
+	defer os.Remove(layerPath)
+
+	data, err := os.ReadFile(layerPath)
+	require.NoError(t, err)
+
+	names, contents := readTar(t, data)
+	assert.Equal(t, []string{"etc/.wh.app", "tmp/.wh.cache", "etc/app/app.conf"}, names)
+	assert.Equal(t, "conf", contents["etc/app/app.conf"])
+
+	_, err = createEditLayerTar(log.NewEntry(log.StandardLogger()), nil, []string{"/"})
+	assert.Error(t, err)
+
+	_, err = createEditLayerTar(log.NewEntry(log.StandardLogger()),
+		[]FileAddInfo{{Source: filepath.Join(srcDir, "missing"), Target: "/missing"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestCountRemovedPaths(t *testing.T) {
+	layerData := newTar(t, []tarEntry{
+		{name: "etc/", dir: true},
+		{name: "etc/app/", dir: true},
+		{name: "etc/app/a.conf", data: "a"},
+		{name: "etc/app/b.conf", data: "b"},
+		{name: "etc/apprc", data: "c"},
+		{name: "bin/tool", data: "t"},
+	})
+
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(layerData)), nil
+	})
+	require.NoError(t, err)
+
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	require.NoError(t, err)
+
+	count, err := countRemovedPaths(img, []string{"/etc/app", "/bin/tool", "/missing"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
+
+type tarEntry struct {
+	name string
+	data string
+	dir  bool
+}
+
+func newTar(t *testing.T, entries []tarEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		hdr := &tar.Header{Name: entry.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(entry.data))}
+		if entry.dir {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+		}
+
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(entry.data))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func readTar(t *testing.T, data []byte) ([]string, map[string]string) {
+	var names []string
+	contents := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		contents[hdr.Name] = string(content)
+	}
+
+	return names, contents
+}
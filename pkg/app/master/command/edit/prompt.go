@@ -2,9 +2,36 @@ package edit
 
 import (
 	"github.com/c-bata/go-prompt"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
 )
 
 var CommandSuggestion = prompt.Suggest{
 	Text:        Name,
 	Description: Usage,
 }
+
+var CommandFlagSuggestions = &command.FlagSuggestions{
+	Names: []prompt.Suggest{
+		{Text: command.FullFlagName(command.FlagTarget), Description: command.FlagTargetUsage},
+		{Text: command.FullFlagName(FlagTag), Description: FlagTagUsage},
+		{Text: command.FullFlagName(FlagNewEntrypoint), Description: FlagNewEntrypointUsage},
+		{Text: command.FullFlagName(FlagNewCmd), Description: FlagNewCmdUsage},
+		{Text: command.FullFlagName(FlagNewExpose), Description: FlagNewExposeUsage},
+		{Text: command.FullFlagName(FlagNewWorkdir), Description: FlagNewWorkdirUsage},
+		{Text: command.FullFlagName(FlagNewEnv), Description: FlagNewEnvUsage},
+		{Text: command.FullFlagName(FlagNewVolume), Description: FlagNewVolumeUsage},
+		{Text: command.FullFlagName(FlagNewLabel), Description: FlagNewLabelUsage},
+		{Text: command.FullFlagName(FlagNewUser), Description: FlagNewUserUsage},
+		{Text: command.FullFlagName(FlagRemoveExpose), Description: FlagRemoveExposeUsage},
+		{Text: command.FullFlagName(FlagRemoveEnv), Description: FlagRemoveEnvUsage},
+		{Text: command.FullFlagName(FlagRemoveLabel), Description: FlagRemoveLabelUsage},
+		{Text: command.FullFlagName(FlagRemoveVolume), Description: FlagRemoveVolumeUsage},
+		{Text: command.FullFlagName(FlagAddFile), Description: FlagAddFileUsage},
+		{Text: command.FullFlagName(FlagRemoveFile), Description: FlagRemoveFileUsage},
+		{Text: command.FullFlagName(FlagImageOutputTar), Description: FlagImageOutputTarUsage},
+	},
+	Values: map[string]command.CompleteValue{
+		command.FullFlagName(command.FlagTarget): command.CompleteImage,
+	},
+}
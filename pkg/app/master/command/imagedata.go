@@ -0,0 +1,179 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/app"
+	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/image"
+	"github.com/slimtoolkit/slim/pkg/crt"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerutil"
+	"github.com/slimtoolkit/slim/pkg/util/fsutil"
+	v "github.com/slimtoolkit/slim/pkg/version"
+)
+
+//Common image data handler code (used by the commands that look at the local image data)
+
+// ConnectDockerClient creates the Docker client
+// (exits with the 'no Docker connect info' exit code if the connection info is missing)
+func ConnectDockerClient(xc *app.ExecutionContext, gparams *GenericParams) *dockerapi.Client {
+	client, err := dockerclient.New(gparams.ClientConfig)
+	if err == dockerclient.ErrNoDockerInfo {
+		exitMsg := "missing Docker connection info"
+		if gparams.InContainer && gparams.IsDSImage {
+			exitMsg = "make sure to pass the Docker connect parameters to the slim app container"
+		}
+
+		xc.Out.Info("docker.connect.error",
+			ovars{
+				"message": exitMsg,
+			})
+
+		exitCode := ECTCommon | ECCNoDockerConnectInfo
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": exitCode,
+				"version":   v.Current(),
+				"location":  fsutil.ExeDir(),
+			})
+		xc.Exit(exitCode)
+	}
+	xc.FailOn(err)
+
+	return client
+}
+
+// InspectLocalImage inspects the target image
+// (exits with the 'image not found' exit code if the image doesn't exist locally)
+func InspectLocalImage(
+	xc *app.ExecutionContext,
+	client crt.APIClient,
+	imageRef string) *image.Inspector {
+	imageInspector, err := image.NewInspector(client, imageRef)
+	xc.FailOn(err)
+
+	noImage, err := imageInspector.NoImage()
+	xc.FailOn(err)
+	if noImage {
+		xc.Out.Error("image.not.found", fmt.Sprintf("make sure the target image already exists locally - %s", imageRef))
+
+		exitCode := ECTCommon | ECCImageNotFound
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": exitCode,
+			})
+		xc.Exit(exitCode)
+	}
+
+	err = imageInspector.Inspect()
+	xc.FailOn(err)
+
+	return imageInspector
+}
+
+// SaveImageArchive saves the image to the local volume image directory
+// (reusing the previously saved image archive if it's complete).
+// Returns the image archive path and true if the image archive was saved by the call.
+func SaveImageArchive(
+	xc *app.ExecutionContext,
+	client crt.APIClient,
+	logger *log.Entry,
+	imageID string,
+	localVolumePath string) (string, bool) {
+	imageID = dockerutil.CleanImageID(imageID)
+	iaPath := filepath.Join(localVolumePath, "image", fmt.Sprintf("%s.tar", imageID))
+	iaPathReady := ImageArchiveReadyPath(iaPath)
+
+	if fsutil.IsRegularFile(iaPath) && fsutil.Exists(iaPathReady) {
+		logger.Debugf("exported image already exists - %s", iaPath)
+		return iaPath, false
+	}
+
+	if fsutil.Exists(iaPathReady) {
+		fsutil.Remove(iaPathReady)
+	}
+
+	xc.Out.Info("image.data.inspection.save.image.start")
+	err := dockerutil.SaveImage(client, imageID, iaPath, false, false)
+	xc.FailOn(err)
+
+	if err := fsutil.Touch(iaPathReady); err != nil {
+		logger.Debugf("error creating the image archive ready file - %v", err)
+	}
+
+	xc.Out.Info("image.data.inspection.save.image.end")
+	return iaPath, true
+}
+
+// ImageArchiveReadyPath returns the path of the file that marks the image archive as complete
+func ImageArchiveReadyPath(iaPath string) string {
+	return fmt.Sprintf("%s.ready", iaPath)
+}
+
+// RemoveImageArchive removes the saved image archive
+func RemoveImageArchive(logger *log.Entry, iaPath string) {
+	for _, fpath := range []string{ImageArchiveReadyPath(iaPath), iaPath} {
+		if err := fsutil.Remove(fpath); err != nil {
+			logger.Debugf("error removing image archive file (%s) - %v", fpath, err)
+		}
+	}
+}
+
+// PackageProcessorParams returns the image data processor params for the image package inventory
+// (the package detection is skipped when only the image file system data is needed)
+func PackageProcessorParams(detectPackages bool) *dockerimage.ProcessorParams {
+	return &dockerimage.ProcessorParams{
+		DetectIdentities: &dockerimage.DetectOpParam{},
+		DetectPackages:   detectPackages,
+	}
+}
+
+// LoadImagePackage loads the image layer data from the saved image archive
+func LoadImagePackage(
+	xc *app.ExecutionContext,
+	iaPath string,
+	imageID string,
+	pp *dockerimage.ProcessorParams) *dockerimage.Package {
+	xc.Out.Info("image.data.inspection.process.image.start")
+	imagePkg, err := dockerimage.LoadPackage(
+		iaPath,
+		dockerutil.CleanImageID(imageID),
+		false,
+		-1,
+		false,
+		false,
+		nil,
+		nil,
+		nil,
+		nil,
+		pp)
+	xc.FailOn(err)
+	xc.Out.Info("image.data.inspection.process.image.end")
+
+	return imagePkg
+}
+
+// LoadLocalImagePackage saves the image archive to the image state directory
+// and loads the image layer data (the image archive is removed if it didn't exist before)
+func LoadLocalImagePackage(
+	xc *app.ExecutionContext,
+	client crt.APIClient,
+	logger *log.Entry,
+	statePath string,
+	imageInspector *image.Inspector,
+	pp *dockerimage.ProcessorParams) *dockerimage.Package {
+	imageID := imageInspector.ImageInfo.ID
+	localVolumePath, _, _, _ := fsutil.PrepareImageStateDirs(statePath, imageID)
+	iaPath, isSaved := SaveImageArchive(xc, client, logger, imageID, localVolumePath)
+	imagePkg := LoadImagePackage(xc, iaPath, imageID, pp)
+	if isSaved {
+		RemoveImageArchive(logger, iaPath)
+	}
+
+	return imagePkg
+}
@@ -5,11 +5,14 @@ import (
 
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/docker/linter"
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 )
 
 const (
 	Name  = "lint"
-	Usage = "Analyzes container instructions in Dockerfiles"
+	Usage = "Analyzes container instructions in Dockerfiles and container images"
 	Alias = "l"
 )
 
@@ -32,6 +35,11 @@ var CLI = &cli.Command{
 		cflag(FlagShowNoHits),
 		cflag(FlagShowSnippet),
 		cflag(FlagListChecks),
+		cflag(FlagOutput),
+		cflag(FlagOutputFile),
+		cflag(FlagRulesFile),
+		cflag(FlagFix),
+		cflag(FlagFixInPlace),
 	},
 	Action: func(ctx *cli.Context) error {
 		gcvalues := command.GlobalFlagValues(ctx)
@@ -46,7 +54,7 @@ var CLI = &cli.Command{
 		if !doListChecks {
 			if targetRef == "" {
 				if ctx.Args().Len() < 1 {
-					xc.Out.Error("param.target", "missing target Dockerfile")
+					xc.Out.Error("param.target", "missing target Dockerfile or image")
 					cli.ShowCommandHelp(ctx, Name)
 					return nil
 				} else {
@@ -56,6 +64,23 @@ var CLI = &cli.Command{
 		}
 
 		targetType := ctx.String(FlagTargetType)
+		switch targetType {
+		case linter.DockerfileTargetType, linter.ImageTargetType:
+		case "":
+			//the target is an image if it's not a local file
+			targetType = linter.DockerfileTargetType
+			if !doListChecks && !fsutil.Exists(targetRef) {
+				targetType = linter.ImageTargetType
+			}
+		default:
+			xc.Out.Error("param.error.invalid.target.type", targetType)
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
 		doSkipBuildContext := ctx.Bool(FlagSkipBuildContext)
 		buildContextDir := ctx.String(FlagBuildContextDir)
 		doSkipDockerignore := ctx.Bool(FlagSkipDockerignore)
@@ -133,6 +158,46 @@ var CLI = &cli.Command{
 		doShowNoHits := ctx.Bool(FlagShowNoHits)
 		doShowSnippet := ctx.Bool(FlagShowSnippet)
 
+		outputFormat := ctx.String(FlagOutput)
+		if outputFormat != "" && !linter.IsValidOutput(outputFormat) {
+			xc.Out.Error("param.error.invalid.output", outputFormat)
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
+		outputFile := ctx.String(FlagOutputFile)
+
+		doFixInPlace := ctx.Bool(FlagFixInPlace)
+		doFix := ctx.Bool(FlagFix) || doFixInPlace
+		if doFix && targetType == linter.ImageTargetType {
+			xc.Out.Error("param.error.fix", "fixes are available only for Dockerfile targets")
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
+		var policyChecks []check.Runner
+		if rulesFiles := ctx.StringSlice(FlagRulesFile); len(rulesFiles) > 0 {
+			checks, err := check.LoadPolicyChecks(rulesFiles)
+			if err != nil {
+				xc.Out.Error("param.error.invalid.rules.file", err.Error())
+				xc.Out.State("exited",
+					ovars{
+						"exit.code": -1,
+					})
+				xc.Exit(-1)
+			}
+
+			for _, pc := range checks {
+				policyChecks = append(policyChecks, pc)
+			}
+		}
+
 		OnCommand(
 			xc,
 			gcvalues,
@@ -147,7 +212,12 @@ var CLI = &cli.Command{
 			excludeCheckIDs,
 			doShowNoHits,
 			doShowSnippet,
-			doListChecks)
+			doListChecks,
+			outputFormat,
+			outputFile,
+			policyChecks,
+			doFix,
+			doFixInPlace)
 
 		return nil
 	},
@@ -22,6 +22,11 @@ const (
 	FlagShowNoHits         = "show-nohits"
 	FlagShowSnippet        = "show-snippet"
 	FlagListChecks         = "list-checks"
+	FlagOutput             = "output"
+	FlagOutputFile         = "output-file"
+	FlagRulesFile          = "rules-file"
+	FlagFix                = "fix"
+	FlagFixInPlace         = "fix-in-place"
 )
 
 // Lint command flag usage info
@@ -40,6 +45,11 @@ const (
 	FlagShowNoHitsUsage         = "Show checks with no matches"
 	FlagShowSnippetUsage        = "Show check match snippet"
 	FlagListChecksUsage         = "List available checks"
+	FlagOutputUsage             = "Lint results output format (values: sarif, junit)"
+	FlagOutputFileUsage         = "Lint results output file (used with the output flag)"
+	FlagRulesFileUsage          = "YAML or JSON file with user-defined lint rules"
+	FlagFixUsage                = "Show the Dockerfile fixes for the check hits (as a unified diff)"
+	FlagFixInPlaceUsage         = "Apply the Dockerfile fixes for the check hits to the target Dockerfile"
 )
 
 var Flags = map[string]cli.Flag{
@@ -123,6 +133,34 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagListChecksUsage,
 		EnvVars: []string{"DSLIM_LINT_LIST_CHECKS"},
 	},
+	FlagOutput: &cli.StringFlag{
+		Name:    FlagOutput,
+		Value:   "",
+		Usage:   FlagOutputUsage,
+		EnvVars: []string{"DSLIM_LINT_OUTPUT"},
+	},
+	FlagOutputFile: &cli.StringFlag{
+		Name:    FlagOutputFile,
+		Value:   "",
+		Usage:   FlagOutputFileUsage,
+		EnvVars: []string{"DSLIM_LINT_OUTPUT_FILE"},
+	},
+	FlagRulesFile: &cli.StringSliceFlag{
+		Name:    FlagRulesFile,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagRulesFileUsage,
+		EnvVars: []string{"DSLIM_LINT_RULES_FILE"},
+	},
+	FlagFix: &cli.BoolFlag{
+		Name:    FlagFix,
+		Usage:   FlagFixUsage,
+		EnvVars: []string{"DSLIM_LINT_FIX"},
+	},
+	FlagFixInPlace: &cli.BoolFlag{
+		Name:    FlagFixInPlace,
+		Usage:   FlagFixInPlaceUsage,
+		EnvVars: []string{"DSLIM_LINT_FIX_IN_PLACE"},
+	},
 }
 
 func cflag(name string) cli.Flag {
@@ -2,6 +2,7 @@ package lint
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	dockerapi "github.com/fsouza/go-dockerclient"
@@ -11,10 +12,14 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
 	"github.com/slimtoolkit/slim/pkg/docker/linter"
 	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+	"github.com/slimtoolkit/slim/pkg/docker/linter/fix"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
+	"github.com/slimtoolkit/slim/pkg/util/fsutil"
+	v "github.com/slimtoolkit/slim/pkg/version"
 )
 
 const appName = command.AppName
@@ -36,7 +41,12 @@ func OnCommand(
 	excludeCheckIDs map[string]struct{},
 	doShowNoHits bool,
 	doShowSnippet bool,
-	doListChecks bool) {
+	doListChecks bool,
+	outputFormat string,
+	outputFile string,
+	policyChecks []check.Runner,
+	doFix bool,
+	doFixInPlace bool) {
 	const cmdName = Name
 	logger := log.WithFields(log.Fields{"app": appName, "cmd": cmdName})
 
@@ -50,33 +60,46 @@ func OnCommand(
 		ovars{
 			"target":      targetRef,
 			"list.checks": doListChecks,
+			"rules":       len(policyChecks),
 		})
 
-	/*
-		do it only when targeting images
-		client, err := dockerclient.New(gparams.ClientConfig)
+	//connecting to Docker only when targeting images
+	var client *dockerapi.Client
+	if !doListChecks && targetType == linter.ImageTargetType {
+		var err error
+		client, err = dockerclient.New(gparams.ClientConfig)
 		if err == dockerclient.ErrNoDockerInfo {
 			exitMsg := "missing Docker connection info"
 			if gparams.InContainer && gparams.IsDSImage {
 				exitMsg = "make sure to pass the Docker connect parameters to the 'slim' container"
 			}
-			fmt.Printf("cmd=%s info=docker.connect.error message='%s'\n", cmdName, exitMsg)
-			fmt.Printf("cmd=%s state=exited version=%s location='%s'\n", cmdName, v.Current(), fsutil.ExeDir())
-			os.Exit(ectCommon | ecNoDockerConnectInfo)
+
+			xc.Out.Info("docker.connect.error",
+				ovars{
+					"message": exitMsg,
+				})
+
+			exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+					"version":   v.Current(),
+					"location":  fsutil.ExeDir(),
+				})
+			xc.Exit(exitCode)
 		}
 		errutil.FailOn(err)
-	*/
-	var client *dockerapi.Client
+	}
 
 	if gparams.Debug {
 		version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
 	}
 
 	if doListChecks {
-		checks := linter.ListChecks()
+		checks := linter.ListChecks(policyChecks...)
 		printLintChecks(xc, checks, appName, cmdName)
 	} else {
-		cmdReport.TargetType = linter.DockerfileTargetType
+		cmdReport.TargetType = targetType
 		cmdReport.TargetReference = targetRef
 
 		options := linter.Options{
@@ -90,6 +113,17 @@ func OnCommand(
 				ExcludeCheckLabels: excludeCheckLabels,
 				ExcludeCheckIDs:    excludeCheckIDs,
 			},
+			Checks: policyChecks,
+		}
+
+		if targetType == linter.ImageTargetType {
+			//the image Dockerfile is reconstructed from the image history
+			//and there's no build context for images
+			options.Image, options.Dockerfile = loadImageTarget(xc, gparams, client, logger, targetRef)
+			options.DockerfilePath = ""
+			options.SkipBuildContext = true
+			options.BuildContextDir = ""
+			options.SkipDockerignore = true
 		}
 
 		lintResults, err := linter.Execute(options)
@@ -100,6 +134,15 @@ func OnCommand(
 		cmdReport.Errors = lintResults.Errors
 
 		printLintResults(xc, lintResults, appName, cmdName, cmdReport, doShowNoHits, doShowSnippet)
+
+		if doFix {
+			fixLintHits(xc, lintResults, cmdReport, targetRef, doFixInPlace)
+		}
+
+		if outputFormat != "" {
+			cmdReport.OutputFormat = outputFormat
+			cmdReport.OutputFile = saveLintOutput(xc, lintResults, targetType, targetRef, outputFormat, outputFile)
+		}
 	}
 
 	xc.Out.State("completed")
@@ -118,6 +161,94 @@ func OnCommand(
 	}
 }
 
+// fixLintHits shows (and optionally applies) the Dockerfile fixes for the check hits
+func fixLintHits(
+	xc *app.ExecutionContext,
+	lintResults *linter.Report,
+	cmdReport *report.LintCommand,
+	dockerfilePath string,
+	doFixInPlace bool) {
+	fixResult, err := fix.Dockerfile(dockerfilePath, lintResults.Hits)
+	if err != nil {
+		xc.Out.Info("lint.fix.error",
+			ovars{
+				"message": err.Error(),
+			})
+		return
+	}
+
+	cmdReport.Fixes = fixResult.Edits
+	xc.Out.Info("lint.fix",
+		ovars{
+			"edits":   len(fixResult.Edits),
+			"skipped": len(fixResult.Skipped),
+		})
+
+	if !fixResult.Changed() {
+		return
+	}
+
+	for _, edit := range fixResult.Edits {
+		xc.Out.Info("lint.fix.edit",
+			ovars{
+				"checks":  strings.Join(edit.CheckIDs, ","),
+				"start":   edit.StartLine,
+				"end":     edit.EndLine,
+				"message": edit.Message,
+			})
+	}
+
+	diff, err := fixResult.Diff()
+	errutil.FailOn(err)
+	xc.Out.LogDump("lint.fix.diff", diff,
+		ovars{
+			"file": dockerfilePath,
+		})
+
+	if doFixInPlace {
+		err = fixResult.Save()
+		errutil.FailOn(err)
+
+		cmdReport.FixesSaved = true
+		xc.Out.Info("lint.fix.saved",
+			ovars{
+				"file": dockerfilePath,
+			})
+	}
+}
+
+// saveLintOutput saves the lint results in the selected output format
+// (the default output file is in the current directory)
+func saveLintOutput(
+	xc *app.ExecutionContext,
+	lintResults *linter.Report,
+	targetType string,
+	targetRef string,
+	outputFormat string,
+	outputFile string) string {
+	data, err := lintResults.Output(outputFormat,
+		linter.OutputTarget{
+			Type:      targetType,
+			Reference: targetRef,
+		})
+	errutil.FailOn(err)
+
+	if outputFile == "" {
+		outputFile = linter.OutputFileName(outputFormat)
+	}
+
+	err = os.WriteFile(outputFile, data, 0644)
+	errutil.FailOn(err)
+
+	xc.Out.Info("lint.output",
+		ovars{
+			"format": outputFormat,
+			"file":   outputFile,
+		})
+
+	return outputFile
+}
+
 func printLintChecks(
 	xc *app.ExecutionContext,
 	checks []*check.Info,
@@ -206,6 +337,10 @@ func printLintResults(
 						minfo["stage"] = fmt.Sprintf("%d:%s", m.Stage.Index, m.Stage.Name)
 					}
 
+					if m.Layer != nil {
+						minfo["layer"] = *m.Layer
+					}
+
 					minfo["message"] = m.Message
 					xc.Out.Info("lint.check.hit.match", minfo)
 
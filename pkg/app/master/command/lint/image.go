@@ -0,0 +1,62 @@
+package lint
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/app"
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/consts"
+	"github.com/slimtoolkit/slim/pkg/crt"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/parser"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/reverse"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/spec"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+)
+
+// loadImageTarget collects the image data for the image scope checks
+// and reconstructs the image Dockerfile (from the image history) for the Dockerfile checks
+func loadImageTarget(
+	xc *app.ExecutionContext,
+	gparams *command.GenericParams,
+	client crt.APIClient,
+	logger *log.Entry,
+	targetRef string) (*check.ImageTarget, *spec.Dockerfile) {
+	xc.Out.State("image.inspection.start")
+	imageInspector := command.InspectLocalImage(xc, client, targetRef)
+
+	var df *spec.Dockerfile
+	dfInfo, err := reverse.DockerfileFromHistory(imageInspector.APIClient, imageInspector.ImageRef)
+	if err == nil {
+		df, err = parser.FromReader(
+			strings.NewReader(strings.Join(dfInfo.Lines, "\n")),
+			consts.ReversedDockerfile,
+			"")
+	}
+
+	if err != nil {
+		//the image checks can still run without the Dockerfile
+		xc.Out.Info("image.dockerfile.error",
+			ovars{
+				"message": err.Error(),
+			})
+	}
+
+	pp := &dockerimage.ProcessorParams{
+		DetectIdentities:     &dockerimage.DetectOpParam{},
+		DetectAllCertPKFiles: true,
+	}
+
+	imagePkg := command.LoadLocalImagePackage(xc, client, logger, gparams.StatePath, imageInspector, pp)
+	xc.Out.State("image.inspection.done")
+
+	target := &check.ImageTarget{
+		Ref:     imageInspector.ImageRef,
+		Info:    imageInspector.ImageInfo,
+		Package: imagePkg,
+	}
+
+	return target, df
+}
@@ -1,9 +1,13 @@
 package lint
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/c-bata/go-prompt"
 
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/docker/linter"
 	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
 )
 
@@ -28,6 +32,11 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(FlagShowNoHits), Description: FlagShowNoHitsUsage},
 		{Text: command.FullFlagName(FlagShowSnippet), Description: FlagShowSnippetUsage},
 		{Text: command.FullFlagName(FlagListChecks), Description: FlagListChecksUsage},
+		{Text: command.FullFlagName(FlagOutput), Description: FlagOutputUsage},
+		{Text: command.FullFlagName(FlagOutputFile), Description: FlagOutputFileUsage},
+		{Text: command.FullFlagName(FlagRulesFile), Description: FlagRulesFileUsage},
+		{Text: command.FullFlagName(FlagFix), Description: FlagFixUsage},
+		{Text: command.FullFlagName(FlagFixInPlace), Description: FlagFixInPlaceUsage},
 	},
 	Values: map[string]command.CompleteValue{
 		command.FullFlagName(command.FlagTarget):     completeLintTarget,
@@ -42,13 +51,21 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		command.FullFlagName(FlagShowNoHits):         command.CompleteBool,
 		command.FullFlagName(FlagShowSnippet):        command.CompleteTBool,
 		command.FullFlagName(FlagListChecks):         command.CompleteBool,
+		command.FullFlagName(FlagOutput):             completeLintOutput,
+		command.FullFlagName(FlagOutputFile):         command.CompleteFile,
+		command.FullFlagName(FlagRulesFile):          command.CompleteFile,
+		command.FullFlagName(FlagFix):                command.CompleteBool,
+		command.FullFlagName(FlagFixInPlace):         command.CompleteBool,
 	},
 }
 
 func completeLintTarget(ia *command.InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
-	//for now only support selecting Dockerfiles
-	//later add an ability to choose (files or images)
-	//based on the target-type parameter
+	//selecting images only if the image target type is already selected
+	targetTypeImage := fmt.Sprintf("%s %s", command.FullFlagName(FlagTargetType), linter.ImageTargetType)
+	if strings.Contains(params.TextBeforeCursor(), targetTypeImage) {
+		return command.CompleteImage(ia, token, params)
+	}
+
 	return command.CompleteFile(ia, token, params)
 }
 
@@ -61,6 +78,15 @@ func completeLintTargetType(ia *command.InteractiveApp, token string, params pro
 	return prompt.FilterHasPrefix(lintTargetTypeValues, token, true)
 }
 
+var lintOutputValues = []prompt.Suggest{
+	{Text: linter.OutputSARIF, Description: "SARIF (2.1.0) output"},
+	{Text: linter.OutputJUnit, Description: "JUnit XML output"},
+}
+
+func completeLintOutput(ia *command.InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
+	return prompt.FilterHasPrefix(lintOutputValues, token, true)
+}
+
 func completeLintCheckID(ia *command.InteractiveApp, token string, params prompt.Document) []prompt.Suggest {
 	var values []prompt.Suggest
 	for _, check := range check.AllChecks {
@@ -21,6 +21,7 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(command.FlagHTTPProbeRetryCount), Description: command.FlagHTTPProbeRetryCountUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeRetryWait), Description: command.FlagHTTPProbeRetryWaitUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbePorts), Description: command.FlagHTTPProbePortsUsage},
+		{Text: command.FullFlagName(command.FlagTCPProbePorts), Description: command.FlagTCPProbePortsUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeFull), Description: command.FlagHTTPProbeFullUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeExitOnFailure), Description: command.FlagHTTPProbeExitOnFailureUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeCrawl), Description: command.FlagHTTPProbeCrawlUsage},
@@ -30,11 +31,17 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(command.FlagHTTPMaxConcurrentCrawlers), Description: command.FlagHTTPMaxConcurrentCrawlersUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpec), Description: command.FlagHTTPProbeAPISpecUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpecFile), Description: command.FlagHTTPProbeAPISpecFileUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpecCred), Description: command.FlagHTTPProbeAPISpecCredUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQL), Description: command.FlagHTTPProbeGraphQLUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQLEndpoint), Description: command.FlagHTTPProbeGraphQLEndpointUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQLMutations), Description: command.FlagHTTPProbeGraphQLMutationsUsage},
 	},
 	Values: map[string]command.CompleteValue{
-		command.FullFlagName(command.FlagHTTPProbeCmdFile):     command.CompleteFile,
-		command.FullFlagName(command.FlagHTTPProbeFull):        command.CompleteBool,
-		command.FullFlagName(command.FlagHTTPProbeCrawl):       command.CompleteTBool,
-		command.FullFlagName(command.FlagHTTPProbeAPISpecFile): command.CompleteFile,
+		command.FullFlagName(command.FlagHTTPProbeCmdFile):          command.CompleteFile,
+		command.FullFlagName(command.FlagHTTPProbeFull):             command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeGraphQL):          command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeGraphQLMutations): command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeCrawl):            command.CompleteTBool,
+		command.FullFlagName(command.FlagHTTPProbeAPISpecFile):      command.CompleteFile,
 	},
 }
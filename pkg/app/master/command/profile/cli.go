@@ -56,6 +56,7 @@ var CLI = &cli.Command{
 		command.Cflag(command.FlagRemoveFileArtifacts),
 		command.Cflag(command.FlagExec),
 		command.Cflag(command.FlagExecFile),
+		command.Cflag(command.FlagSeccompMergeProfile),
 		//Container Run Options
 		command.Cflag(command.FlagCRORuntime),
 		command.Cflag(command.FlagCROHostConfigFile),
@@ -320,6 +321,7 @@ var CLI = &cli.Command{
 			doUseSensorVolume,
 			//doKeepTmpArtifacts,
 			continueAfter,
+			ctx.StringSlice(command.FlagSeccompMergeProfile),
 			ctx.String(command.FlagSensorIPCEndpoint),
 			ctx.String(command.FlagSensorIPCMode),
 			ctx.String(command.FlagLogLevel),
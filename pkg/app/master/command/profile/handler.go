@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/probe/http"
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
+	"github.com/slimtoolkit/slim/pkg/crt/crtclient"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
@@ -77,6 +79,7 @@ func OnCommand(
 	doUseSensorVolume string,
 	//doKeepTmpArtifacts bool,
 	continueAfter *config.ContinueAfter,
+	seccompMergeProfiles []string,
 	sensorIPCEndpoint string,
 	sensorIPCMode string,
 	logLevel string,
@@ -159,6 +162,7 @@ func OnCommand(
 
 	imageInspector, err := image.NewInspector(client, targetRef)
 	errutil.FailOn(err)
+	imageInspector.SeccompMergeProfiles = seccompMergeProfiles
 
 	noImage, err := imageInspector.NoImage()
 	errutil.FailOn(err)
@@ -221,11 +225,16 @@ func OnCommand(
 	//(better yet refactor to share code)
 	hasClassicLinks := true //placeholder for now
 
+	//the native runtime engine API is used for the sensor volume and the container data operations
+	crtClient, err := crtclient.NewWithDockerClient(gparams.ClientConfig, client)
+	errutil.FailOn(err)
+
 	containerInspector, err := container.NewInspector(
 		xc,
 		crOpts,
 		logger,
 		client,
+		crtClient,
 		statePath,
 		imageInspector,
 		localVolumePath,
@@ -506,6 +515,10 @@ func OnCommand(
 
 	cmdReport.SeccompProfileName = imageInspector.SeccompProfileName
 	cmdReport.AppArmorProfileName = imageInspector.AppArmorProfileName
+	if fsutil.Exists(filepath.Join(artifactLocation, imageInspector.NetworkPolicyName)) {
+		cmdReport.NetworkPolicyName = imageInspector.NetworkPolicyName
+		cmdReport.DockerNetworkName = imageInspector.DockerNetworkName
+	}
 
 	xc.Out.Info("results",
 		ovars{
@@ -517,11 +530,25 @@ func OnCommand(
 			"artifacts.apparmor": cmdReport.AppArmorProfileName,
 		})
 
+	if cmdReport.NetworkPolicyName != "" {
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.network.policy": cmdReport.NetworkPolicyName,
+			})
+
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.network.docker": cmdReport.DockerNetworkName,
+			})
+	}
+
 	if copyMetaArtifactsLocation != "" {
 		toCopy := []string{
 			report.DefaultContainerReportFileName,
 			imageInspector.SeccompProfileName,
 			imageInspector.AppArmorProfileName,
+			imageInspector.NetworkPolicyName,
+			imageInspector.DockerNetworkName,
 		}
 		if !command.CopyMetaArtifacts(logger,
 			toCopy,
@@ -30,6 +30,7 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(command.FlagHTTPProbeRetryCount), Description: command.FlagHTTPProbeRetryCountUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeRetryWait), Description: command.FlagHTTPProbeRetryWaitUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbePorts), Description: command.FlagHTTPProbePortsUsage},
+		{Text: command.FullFlagName(command.FlagTCPProbePorts), Description: command.FlagTCPProbePortsUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeFull), Description: command.FlagHTTPProbeFullUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeExitOnFailure), Description: command.FlagHTTPProbeExitOnFailureUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeCrawl), Description: command.FlagHTTPProbeCrawlUsage},
@@ -39,6 +40,10 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(command.FlagHTTPMaxConcurrentCrawlers), Description: command.FlagHTTPMaxConcurrentCrawlersUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpec), Description: command.FlagHTTPProbeAPISpecUsage},
 		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpecFile), Description: command.FlagHTTPProbeAPISpecFileUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeAPISpecCred), Description: command.FlagHTTPProbeAPISpecCredUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQL), Description: command.FlagHTTPProbeGraphQLUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQLEndpoint), Description: command.FlagHTTPProbeGraphQLEndpointUsage},
+		{Text: command.FullFlagName(command.FlagHTTPProbeGraphQLMutations), Description: command.FlagHTTPProbeGraphQLMutationsUsage},
 		{Text: command.FullFlagName(command.FlagPublishPort), Description: command.FlagPublishPortUsage},
 		{Text: command.FullFlagName(command.FlagPublishExposedPorts), Description: command.FlagPublishExposedPortsUsage},
 		{Text: command.FullFlagName(command.FlagHostExec), Description: command.FlagHostExecUsage},
@@ -72,20 +77,22 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(command.FlagSensorIPCEndpoint), Description: command.FlagSensorIPCEndpointUsage},
 	},
 	Values: map[string]command.CompleteValue{
-		command.FullFlagName(command.FlagPull):                   command.CompleteTBool,
-		command.FullFlagName(command.FlagShowPullLogs):           command.CompleteBool,
-		command.FullFlagName(command.FlagTarget):                 command.CompleteImage,
-		command.FullFlagName(command.FlagShowContainerLogs):      command.CompleteBool,
-		command.FullFlagName(command.FlagEnableMondelLogs):       command.CompleteBool,
-		command.FullFlagName(command.FlagPublishExposedPorts):    command.CompleteBool,
-		command.FullFlagName(command.FlagHTTPProbeOff):           command.CompleteBool,
-		command.FullFlagName(command.FlagHTTPProbe):              command.CompleteTBool,
-		command.FullFlagName(command.FlagHTTPProbeCmdFile):       command.CompleteFile,
-		command.FullFlagName(command.FlagHTTPProbeFull):          command.CompleteBool,
-		command.FullFlagName(command.FlagHTTPProbeExitOnFailure): command.CompleteTBool,
-		command.FullFlagName(command.FlagHTTPProbeCrawl):         command.CompleteTBool,
-		command.FullFlagName(command.FlagHTTPProbeAPISpecFile):   command.CompleteFile,
-		command.FullFlagName(command.FlagHostExecFile):           command.CompleteFile,
+		command.FullFlagName(command.FlagPull):                      command.CompleteTBool,
+		command.FullFlagName(command.FlagShowPullLogs):              command.CompleteBool,
+		command.FullFlagName(command.FlagTarget):                    command.CompleteImage,
+		command.FullFlagName(command.FlagShowContainerLogs):         command.CompleteBool,
+		command.FullFlagName(command.FlagEnableMondelLogs):          command.CompleteBool,
+		command.FullFlagName(command.FlagPublishExposedPorts):       command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeOff):              command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbe):                 command.CompleteTBool,
+		command.FullFlagName(command.FlagHTTPProbeCmdFile):          command.CompleteFile,
+		command.FullFlagName(command.FlagHTTPProbeFull):             command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeGraphQL):          command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeGraphQLMutations): command.CompleteBool,
+		command.FullFlagName(command.FlagHTTPProbeExitOnFailure):    command.CompleteTBool,
+		command.FullFlagName(command.FlagHTTPProbeCrawl):            command.CompleteTBool,
+		command.FullFlagName(command.FlagHTTPProbeAPISpecFile):      command.CompleteFile,
+		command.FullFlagName(command.FlagHostExecFile):              command.CompleteFile,
 		//command.FullFlagName(command.FlagKeepPerms):              command.CompleteTBool,
 		command.FullFlagName(command.FlagRunTargetAsUser):     command.CompleteTBool,
 		command.FullFlagName(command.FlagRemoveFileArtifacts): command.CompleteBool,
@@ -1,9 +1,14 @@
 package registry
 
 import (
+	"fmt"
+
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	//log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/imagereader"
 )
 
 func ConfigureAuth(cparams *CommonCommandParams, remoteOpts []remote.Option) ([]remote.Option, error) {
@@ -24,3 +29,72 @@ func ConfigureAuth(cparams *CommonCommandParams, remoteOpts []remote.Option) ([]
 	//it's authn.Anonymous by default, but good to be explicit
 	return append(remoteOpts, remote.WithAuth(authn.Anonymous)), nil
 }
+
+// ConfigureCopyAuth adds the auth option for one side of the copy operation.
+// The side credentials are used if provided. The common credentials are used only
+// if they can't be sent to another registry (the other side is not a registry or it's the same registry).
+// Otherwise the Docker config credentials (keychain) are used.
+func ConfigureCopyAuth(
+	cparams *CopyCommandParams,
+	creds RegistryCreds,
+	location *imagereader.Location,
+	other *imagereader.Location,
+	remoteOpts []remote.Option) ([]remote.Option, error) {
+	auth, err := copyCredentials(cparams, creds, location, other)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth != nil {
+		return append(remoteOpts, remote.WithAuth(auth)), nil
+	}
+
+	return append(remoteOpts, remote.WithAuthFromKeychain(authn.DefaultKeychain)), nil
+}
+
+// copyCredentials returns the basic auth credentials for the copy location
+// (nil if the keychain credentials need to be used)
+func copyCredentials(
+	cparams *CopyCommandParams,
+	creds RegistryCreds,
+	location *imagereader.Location,
+	other *imagereader.Location) (*authn.Basic, error) {
+	if location.Type != imagereader.RegistryLocation {
+		return nil, nil
+	}
+
+	if creds.Account != "" && creds.Secret != "" {
+		return &authn.Basic{Username: creds.Account, Password: creds.Secret}, nil
+	}
+
+	if cparams.UseDockerCreds || cparams.CredsAccount == "" || cparams.CredsSecret == "" {
+		return nil, nil
+	}
+
+	if other != nil && other.Type == imagereader.RegistryLocation {
+		same, err := sameRegistry(location.Ref, other.Ref)
+		if err != nil {
+			return nil, err
+		}
+
+		if !same {
+			return nil, fmt.Errorf("the source and destination registries are different (use the source and destination credential flags)")
+		}
+	}
+
+	return &authn.Basic{Username: cparams.CredsAccount, Password: cparams.CredsSecret}, nil
+}
+
+func sameRegistry(ref, otherRef string) (bool, error) {
+	nameRef, err := name.ParseReference(ref, name.WeakValidation)
+	if err != nil {
+		return false, err
+	}
+
+	otherNameRef, err := name.ParseReference(otherRef, name.WeakValidation)
+	if err != nil {
+		return false, err
+	}
+
+	return nameRef.Context().RegistryStr() == otherNameRef.Context().RegistryStr(), nil
+}
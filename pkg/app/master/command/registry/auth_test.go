@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/imagereader"
+)
+
+func TestCopyCredentials(t *testing.T) {
+	srcRegistry := &imagereader.Location{Type: imagereader.RegistryLocation, Ref: "ghcr.io/org/app:1"}
+	dstRegistry := &imagereader.Location{Type: imagereader.RegistryLocation, Ref: "registry.example.com:5000/app:1"}
+	sameRegistry := &imagereader.Location{Type: imagereader.RegistryLocation, Ref: "ghcr.io/org/app-copy:1"}
+	dstLayout := &imagereader.Location{Type: imagereader.OCILayoutLocation, Path: "/tmp/layout"}
+
+	common := &CommonCommandParams{CredsAccount: "user", CredsSecret: "pass"}
+	srcCreds := RegistryCreds{Account: "src-user", Secret: "src-pass"}
+
+	tt := []struct {
+		name     string
+		common   *CommonCommandParams
+		creds    RegistryCreds
+		location *imagereader.Location
+		other    *imagereader.Location
+		expected *authn.Basic
+		isErr    bool
+	}{
+		{
+			name:     "side credentials",
+			common:   common,
+			creds:    srcCreds,
+			location: srcRegistry,
+			other:    dstRegistry,
+			expected: &authn.Basic{Username: "src-user", Password: "src-pass"},
+		},
+		{
+			name:     "no credentials uses the keychain",
+			common:   &CommonCommandParams{},
+			location: dstRegistry,
+			other:    srcRegistry,
+		},
+		{
+			name:     "common credentials with a different registry",
+			common:   common,
+			location: dstRegistry,
+			other:    srcRegistry,
+			isErr:    true,
+		},
+		{
+			name:     "common credentials with the same registry",
+			common:   common,
+			location: sameRegistry,
+			other:    srcRegistry,
+			expected: &authn.Basic{Username: "user", Password: "pass"},
+		},
+		{
+			name:     "common credentials with a local destination",
+			common:   common,
+			location: srcRegistry,
+			other:    dstLayout,
+			expected: &authn.Basic{Username: "user", Password: "pass"},
+		},
+		{
+			name:     "docker credentials",
+			common:   &CommonCommandParams{UseDockerCreds: true, CredsAccount: "user", CredsSecret: "pass"},
+			location: dstRegistry,
+			other:    srcRegistry,
+		},
+		{
+			name:     "local location",
+			common:   common,
+			creds:    srcCreds,
+			location: dstLayout,
+			other:    srcRegistry,
+		},
+	}
+
+	for _, test := range tt {
+		cparams := &CopyCommandParams{CommonCommandParams: test.common}
+		auth, err := copyCredentials(cparams, test.creds, test.location, test.other)
+		if test.isErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, auth, test.name)
+	}
+}
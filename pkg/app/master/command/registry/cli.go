@@ -21,7 +21,7 @@ const (
 	PushCmdNameUsage = "Push a container image to a registry"
 
 	CopyCmdName      = "copy"
-	CopyCmdNameUsage = "Copy a container image from one registry to another"
+	CopyCmdNameUsage = "Copy a container image between registries, OCI image layouts, Docker archives and the local Docker daemon"
 
 	ImageIndexCreateCmdName      = "image-index-create"
 	ImageIndexCreateCmdNameUsage = "Create an image index (aka manifest list) with the referenced images (already in the target registry)"
@@ -113,6 +113,52 @@ func PushCommandFlagValues(ctx *cli.Context) (*PushCommandParams, error) {
 	return values, nil
 }
 
+// RegistryCreds are the registry credentials for one side of the copy operation
+type RegistryCreds struct {
+	Account string
+	Secret  string
+}
+
+type CopyCommandParams struct {
+	*CommonCommandParams
+	SourceRef    string
+	TargetRef    string
+	Platform     string
+	InsecureRefs bool
+	SourceCreds  RegistryCreds
+	TargetCreds  RegistryCreds
+}
+
+func CopyCommandFlagValues(ctx *cli.Context) (*CopyCommandParams, error) {
+	common, err := CommonCommandFlagValues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := &CopyCommandParams{
+		CommonCommandParams: common,
+		Platform:            ctx.String(FlagPlatform),
+		InsecureRefs:        ctx.Bool(FlagInsecureRefs),
+		SourceCreds: RegistryCreds{
+			Account: ctx.String(FlagSourceAccount),
+			Secret:  ctx.String(FlagSourceSecret),
+		},
+		TargetCreds: RegistryCreds{
+			Account: ctx.String(FlagTargetAccount),
+			Secret:  ctx.String(FlagTargetSecret),
+		},
+	}
+
+	if ctx.Args().Len() != 2 {
+		return nil, fmt.Errorf("expected source and destination image locations")
+	}
+
+	values.SourceRef = ctx.Args().Get(0)
+	values.TargetRef = ctx.Args().Get(1)
+
+	return values, nil
+}
+
 type ImageIndexCreateCommandParams struct {
 	*CommonCommandParams
 	ImageIndexName  string
@@ -255,8 +301,17 @@ var CLI = &cli.Command{
 			},
 		},
 		{
-			Name:  CopyCmdName,
-			Usage: CopyCmdNameUsage,
+			Name:      CopyCmdName,
+			Usage:     CopyCmdNameUsage,
+			ArgsUsage: "SRC_IMAGE DST_IMAGE",
+			Flags: []cli.Flag{
+				cflag(FlagPlatform),
+				cflag(FlagInsecureRefs),
+				cflag(FlagSourceAccount),
+				cflag(FlagSourceSecret),
+				cflag(FlagTargetAccount),
+				cflag(FlagTargetSecret),
+			},
 			Action: func(ctx *cli.Context) error {
 				gcvalues := command.GlobalFlagValues(ctx)
 				xc := app.NewExecutionContext(
@@ -264,7 +319,14 @@ var CLI = &cli.Command{
 					gcvalues.QuietCLIMode,
 					gcvalues.OutputFormat)
 
-				OnCopyCommand(xc, gcvalues)
+				cparams, err := CopyCommandFlagValues(ctx)
+				if err != nil {
+					xc.Out.Error("params", err.Error())
+					cli.ShowCommandHelp(ctx, CopyCmdName)
+					return nil
+				}
+
+				OnCopyCommand(xc, gcvalues, cparams)
 				return nil
 			},
 		},
@@ -35,6 +35,23 @@ const (
 	FlagAs      = "as"
 	FlagAsUsage = "Tag the selected image with the specified name before pushing"
 
+	// Copy Flags
+
+	FlagPlatform      = "platform"
+	FlagPlatformUsage = "Copy only the image for the selected platform (os/arch[/variant]) from a multi-arch source image"
+
+	FlagSourceAccount      = "src-account"
+	FlagSourceAccountUsage = "Source registry credentials account"
+
+	FlagSourceSecret      = "src-secret"
+	FlagSourceSecretUsage = "Source registry credentials secret"
+
+	FlagTargetAccount      = "dst-account"
+	FlagTargetAccountUsage = "Destination registry credentials account"
+
+	FlagTargetSecret      = "dst-secret"
+	FlagTargetSecretUsage = "Destination registry credentials secret"
+
 	// Image Index Flags
 
 	FlagImageIndexName      = "image-index-name"
@@ -133,6 +150,37 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagAsUsage,
 		EnvVars: []string{"DSLIM_REG_PUSH_AS"},
 	},
+	// Copy Flags:
+	FlagPlatform: &cli.StringFlag{
+		Name:    FlagPlatform,
+		Value:   "",
+		Usage:   FlagPlatformUsage,
+		EnvVars: []string{"DSLIM_REG_COPY_PLATFORM"},
+	},
+	FlagSourceAccount: &cli.StringFlag{
+		Name:    FlagSourceAccount,
+		Value:   "",
+		Usage:   FlagSourceAccountUsage,
+		EnvVars: []string{"DSLIM_REG_COPY_SRC_ACCOUNT"},
+	},
+	FlagSourceSecret: &cli.StringFlag{
+		Name:    FlagSourceSecret,
+		Value:   "",
+		Usage:   FlagSourceSecretUsage,
+		EnvVars: []string{"DSLIM_REG_COPY_SRC_SECRET"},
+	},
+	FlagTargetAccount: &cli.StringFlag{
+		Name:    FlagTargetAccount,
+		Value:   "",
+		Usage:   FlagTargetAccountUsage,
+		EnvVars: []string{"DSLIM_REG_COPY_DST_ACCOUNT"},
+	},
+	FlagTargetSecret: &cli.StringFlag{
+		Name:    FlagTargetSecret,
+		Value:   "",
+		Usage:   FlagTargetSecretUsage,
+		EnvVars: []string{"DSLIM_REG_COPY_DST_SECRET"},
+	},
 	// Image Index Flags:
 	FlagImageIndexName: &cli.StringFlag{
 		Name:    FlagImageIndexName,
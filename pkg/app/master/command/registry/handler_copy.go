@@ -1,10 +1,18 @@
 package registry
 
 import (
-	//"github.com/google/go-containerregistry/pkg/crane"
-	//"github.com/google/go-containerregistry/pkg/name"
-	//gocrv1 "github.com/google/go-containerregistry/pkg/v1"
-	//"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slimtoolkit/slim/pkg/app"
@@ -12,16 +20,32 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
+	"github.com/slimtoolkit/slim/pkg/imagereader"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 	v "github.com/slimtoolkit/slim/pkg/version"
 )
 
+// copyArtifact is the copied image or image index (multi-arch image)
+type copyArtifact struct {
+	image v1.Image
+	index v1.ImageIndex
+}
+
+func (ref *copyArtifact) Digest() (v1.Hash, error) {
+	if ref.index != nil {
+		return ref.index.Digest()
+	}
+
+	return ref.image.Digest()
+}
+
 // OnCopyCommand implements the 'registry copy' command
 func OnCopyCommand(
 	xc *app.ExecutionContext,
-	gparams *command.GenericParams) {
+	gparams *command.GenericParams,
+	cparams *CopyCommandParams) {
 	cmdName := fullCmdName(CopyCmdName)
 	logger := log.WithFields(log.Fields{
 		"app": appName,
@@ -35,32 +59,156 @@ func OnCopyCommand(
 
 	xc.Out.State(cmd.StateStarted)
 
-	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
-		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
-			exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
+	source, err := imagereader.ParseLocation(cparams.SourceRef, imagereader.RegistryLocation)
+	if err != nil {
+		xc.FailOn(fmt.Errorf("malformed source image location - %s (%v)", cparams.SourceRef, err))
+	}
+
+	target, err := imagereader.ParseLocation(cparams.TargetRef, imagereader.RegistryLocation)
+	if err != nil {
+		xc.FailOn(fmt.Errorf("malformed destination image location - %s (%v)", cparams.TargetRef, err))
+	}
+
+	xc.Out.Info("params",
+		ovars{
+			"source":   source.String(),
+			"target":   target.String(),
+			"platform": cparams.Platform,
+		})
+
+	cmdReport.SourceReference = source.String()
+	cmdReport.TargetReference = target.String()
+
+	if source.Type == imagereader.DockerDaemonLocation ||
+		target.Type == imagereader.DockerDaemonLocation {
+		//the Docker connection is needed only for the local daemon images
+		client, err := dockerclient.New(gparams.ClientConfig)
+		if err == dockerclient.ErrNoDockerInfo {
+			exitMsg := "missing Docker connection info"
+			if gparams.InContainer && gparams.IsDSImage {
+				exitMsg = "make sure to pass the Docker connect parameters to the docker-slim container"
+			}
+
+			xc.Out.Info("docker.connect.error",
+				ovars{
+					"message": exitMsg,
+				})
+
+			exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": exitCode,
+					"version":   v.Current(),
+					"location":  fsutil.ExeDir(),
+				})
+			xc.Exit(exitCode)
+		}
+		errutil.FailOn(err)
+
+		if gparams.Debug {
+			version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		}
+	}
+
+	var platform *v1.Platform
+	if cparams.Platform != "" {
+		platform, err = v1.ParsePlatform(cparams.Platform)
+		if err != nil {
+			xc.FailOn(fmt.Errorf("malformed platform - %s (%v)", cparams.Platform, err))
+		}
+	}
+
+	//each side gets its own credentials (so they are not sent to the other registry)
+	sourceRemoteOpts, err := ConfigureCopyAuth(cparams, cparams.SourceCreds, source, target,
+		[]remote.Option{remote.WithContext(context.Background())})
+	xc.FailOn(err)
+
+	targetRemoteOpts, err := ConfigureCopyAuth(cparams, cparams.TargetCreds, target, source,
+		[]remote.Option{remote.WithContext(context.Background())})
+	xc.FailOn(err)
+
+	nameOpts := []name.Option{
+		name.WeakValidation,
+	}
+
+	if cparams.InsecureRefs {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+
+	xc.Out.State("image.load.start")
+	artifact, err := loadCopySource(logger, source, platform, nameOpts, sourceRemoteOpts)
+	if err != nil {
+		xc.FailOn(fmt.Errorf("source image load error - %s (%v)", source, err))
+	}
+
+	sourceDigest, err := artifact.Digest()
+	xc.FailOn(err)
+
+	cmdReport.SourceDigest = sourceDigest.String()
+	xc.Out.Info("image.source",
+		ovars{
+			"digest":      sourceDigest.String(),
+			"image.index": artifact.index != nil,
+		})
+	xc.Out.State("image.load.done")
+
+	if artifact.index != nil && !supportsImageIndex(target) {
+		//the Docker daemon and the Docker archives have only single platform images
+		if platform == nil {
+			platform = &v1.Platform{OS: "linux", Architecture: runtime.GOARCH}
+		}
+
+		img, err := selectPlatformImage(artifact.index, platform)
+		xc.FailOn(err)
+
+		artifact = &copyArtifact{image: img}
+		xc.Out.Info("image.platform.selected",
+			ovars{
+				"platform": platform.String(),
+				"message":  "destination supports only single platform images",
+			})
+	}
+
+	xc.Out.State("image.save.start")
+	err = saveCopyTarget(logger, artifact, target, source, nameOpts, targetRemoteOpts)
+	if err != nil {
+		xc.FailOn(fmt.Errorf("destination image save error - %s (%v)", target, err))
+	}
+
+	if supportsImageIndex(target) {
+		targetDigest, err := artifact.Digest()
+		xc.FailOn(err)
+
+		if target.Type == imagereader.RegistryLocation {
+			//checking what the registry actually has
+			ref, err := name.ParseReference(target.Ref, nameOpts...)
+			xc.FailOn(err)
+
+			desc, err := remote.Head(ref, targetRemoteOpts...)
+			xc.FailOn(err)
+
+			targetDigest = desc.Digest
 		}
 
-		xc.Out.Info("docker.connect.error",
+		cmdReport.TargetDigest = targetDigest.String()
+		xc.Out.Info("image.target",
 			ovars{
-				"message": exitMsg,
+				"digest":           targetDigest.String(),
+				"digest.preserved": targetDigest == sourceDigest,
 			})
+	} else {
+		//the Docker daemon and the Docker archives don't keep the original manifest
+		//(the image ID, which is the config digest, is preserved)
+		imageID, err := artifact.image.ConfigName()
+		xc.FailOn(err)
 
-		exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
-		xc.Out.State("exited",
+		xc.Out.Info("image.target",
 			ovars{
-				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
+				"id": imageID.String(),
 			})
-		xc.Exit(exitCode)
 	}
-	errutil.FailOn(err)
 
-	if gparams.Debug {
-		version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
-	}
+	xc.Out.State("image.save.done")
 
 	xc.Out.State(cmd.StateCompleted)
 	cmdReport.State = cmd.StateCompleted
@@ -77,3 +225,248 @@ func OnCopyCommand(
 			})
 	}
 }
+
+// supportsImageIndex returns true if the image location can store multi-arch images
+func supportsImageIndex(location *imagereader.Location) bool {
+	return location.Type == imagereader.RegistryLocation ||
+		location.Type == imagereader.OCILayoutLocation
+}
+
+func loadCopySource(
+	logger *log.Entry,
+	location *imagereader.Location,
+	platform *v1.Platform,
+	nameOpts []name.Option,
+	remoteOpts []remote.Option) (*copyArtifact, error) {
+	logger = logger.WithField("op", "registry.loadCopySource")
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	switch location.Type {
+	case imagereader.RegistryLocation:
+		ref, err := name.ParseReference(location.Ref, nameOpts...)
+		if err != nil {
+			logger.WithError(err).Errorf("name.ParseReference(%s)", location.Ref)
+			return nil, err
+		}
+
+		desc, err := remote.Get(ref, remoteOpts...)
+		if err != nil {
+			logger.WithError(err).Errorf("remote.Get(%s)", location.Ref)
+			return nil, err
+		}
+
+		if desc.MediaType.IsIndex() {
+			idx, err := desc.ImageIndex()
+			if err != nil {
+				return nil, err
+			}
+
+			return indexArtifact(idx, platform)
+		}
+
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+
+		return &copyArtifact{image: img}, nil
+	case imagereader.OCILayoutLocation:
+		layoutIndex, err := layout.ImageIndexFromPath(location.Path)
+		if err != nil {
+			logger.WithError(err).Errorf("layout.ImageIndexFromPath(%s)", location.Path)
+			return nil, err
+		}
+
+		desc, err := layoutManifest(layoutIndex, location.Ref)
+		if err != nil {
+			return nil, err
+		}
+
+		if desc.MediaType.IsIndex() {
+			idx, err := layoutIndex.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			return indexArtifact(idx, platform)
+		}
+
+		img, err := layoutIndex.Image(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		return &copyArtifact{image: img}, nil
+	default:
+		//docker-daemon and docker-archive have single platform images
+		img, err := imagereader.LoadImage(location)
+		if err != nil {
+			logger.WithError(err).Errorf("imagereader.LoadImage(%s)", location)
+			return nil, err
+		}
+
+		return &copyArtifact{image: img}, nil
+	}
+}
+
+func indexArtifact(idx v1.ImageIndex, platform *v1.Platform) (*copyArtifact, error) {
+	if platform == nil {
+		return &copyArtifact{index: idx}, nil
+	}
+
+	img, err := selectPlatformImage(idx, platform)
+	if err != nil {
+		return nil, err
+	}
+
+	return &copyArtifact{image: img}, nil
+}
+
+// layoutManifest selects the OCI image layout manifest using the reference name annotation
+func layoutManifest(idx v1.ImageIndex, refName string) (*v1.Descriptor, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	if refName != "" {
+		for i := range im.Manifests {
+			if im.Manifests[i].Annotations[imagereader.AnnotationRefName] == refName {
+				return &im.Manifests[i], nil
+			}
+		}
+
+		return nil, fmt.Errorf("no image with reference name - '%s'", refName)
+	}
+
+	switch len(im.Manifests) {
+	case 0:
+		return nil, fmt.Errorf("empty OCI image layout")
+	case 1:
+		return &im.Manifests[0], nil
+	default:
+		return nil, fmt.Errorf("multiple images in OCI image layout (select one with a reference name - oci:PATH:REF)")
+	}
+}
+
+// selectPlatformImage selects the image for the target platform from the image index
+func selectPlatformImage(idx v1.ImageIndex, platform *v1.Platform) (v1.Image, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, desc := range im.Manifests {
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			if img, err := selectPlatformImage(child, platform); err == nil {
+				return img, nil
+			}
+		case desc.MediaType.IsImage():
+			if desc.Platform != nil && desc.Platform.Satisfies(*platform) {
+				return idx.Image(desc.Digest)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no image for platform - %s", platform)
+}
+
+func saveCopyTarget(
+	logger *log.Entry,
+	artifact *copyArtifact,
+	target *imagereader.Location,
+	source *imagereader.Location,
+	nameOpts []name.Option,
+	remoteOpts []remote.Option) error {
+	logger = logger.WithField("op", "registry.saveCopyTarget")
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	switch target.Type {
+	case imagereader.RegistryLocation:
+		ref, err := name.ParseReference(target.Ref, nameOpts...)
+		if err != nil {
+			logger.WithError(err).Errorf("name.ParseReference(%s)", target.Ref)
+			return err
+		}
+
+		//the original manifests are pushed as-is, so the digests are preserved
+		if artifact.index != nil {
+			return remote.WriteIndex(ref, artifact.index, remoteOpts...)
+		}
+
+		return remote.Write(ref, artifact.image, remoteOpts...)
+	case imagereader.OCILayoutLocation:
+		layoutPath, err := layout.FromPath(target.Path)
+		if err != nil {
+			layoutPath, err = layout.Write(target.Path, empty.Index)
+			if err != nil {
+				logger.WithError(err).Errorf("layout.Write(%s)", target.Path)
+				return err
+			}
+		}
+
+		if target.Ref == "" {
+			if artifact.index != nil {
+				return layoutPath.AppendIndex(artifact.index)
+			}
+
+			return layoutPath.AppendImage(artifact.image)
+		}
+
+		annotations := map[string]string{
+			imagereader.AnnotationRefName: target.Ref,
+		}
+
+		matcher := match.Annotation(imagereader.AnnotationRefName, target.Ref)
+		if artifact.index != nil {
+			return layoutPath.ReplaceIndex(artifact.index, matcher, layout.WithAnnotations(annotations))
+		}
+
+		return layoutPath.ReplaceImage(artifact.image, matcher, layout.WithAnnotations(annotations))
+	case imagereader.DockerDaemonLocation:
+		tag, err := name.NewTag(target.Ref, nameOpts...)
+		if err != nil {
+			return err
+		}
+
+		resp, err := daemon.Write(tag, artifact.image)
+		if err != nil {
+			logger.WithError(err).Errorf("daemon.Write(%s)", target.Ref)
+			return err
+		}
+
+		logger.Debugf("daemon.Write response - %s", resp)
+		return nil
+	case imagereader.DockerArchiveLocation:
+		imageName := target.Ref
+		if imageName == "" && source.Type != imagereader.OCILayoutLocation {
+			imageName = source.Ref
+		}
+
+		if imageName == "" {
+			return fmt.Errorf("missing destination image name (docker-archive:PATH:IMAGE)")
+		}
+
+		tag, err := name.NewTag(imageName, nameOpts...)
+		if err != nil {
+			return err
+		}
+
+		if err := tarball.WriteToFile(target.Path, tag, artifact.image); err != nil {
+			logger.WithError(err).Errorf("tarball.WriteToFile(%s, %s)", target.Path, imageName)
+			return err
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported destination image location type - '%s'", target.Type)
+	}
+}
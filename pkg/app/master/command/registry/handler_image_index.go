@@ -8,12 +8,8 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1"
-	"github.com/google/go-containerregistry/pkg/v1/empty"
-	"github.com/google/go-containerregistry/pkg/v1/mutate"
-	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
-	"github.com/google/go-containerregistry/pkg/v1/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slimtoolkit/slim/pkg/app"
@@ -21,6 +17,7 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
+	"github.com/slimtoolkit/slim/pkg/imagebuilder/internalbuilder"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 	v "github.com/slimtoolkit/slim/pkg/version"
@@ -110,8 +107,7 @@ func OnImageIndexCreateCommand(
 		logger.Debug("image index is already in the registry")
 	}
 
-	imageIndex := v1.ImageIndex(empty.Index)
-	indexImageImgRefs := make([]mutate.IndexAddendum, 0, len(cparams.ImageNames))
+	images := make([]v1.Image, 0, len(cparams.ImageNames))
 	for _, imageName := range cparams.ImageNames {
 		imgRef, err := name.ParseReference(imageName, nameOpts...)
 		if err != nil {
@@ -128,31 +124,14 @@ func OnImageIndexCreateCommand(
 			xc.FailOn(err)
 
 			basicImageInfo(xc, imgMeta)
-
-			imgConfig, err := imgMeta.ConfigFile()
-			if err != nil {
-				xc.FailOn(err)
-			}
-			imgRefMeta, err := partial.Descriptor(imgMeta)
-			if err != nil {
-				xc.FailOn(err)
-			}
-			imgRefMeta.Platform = imgConfig.Platform()
-			indexImageImgRefs = append(indexImageImgRefs,
-				mutate.IndexAddendum{
-					Add:        imgMeta,
-					Descriptor: *imgRefMeta,
-				})
+			images = append(images, imgMeta)
 		} else {
 			xc.FailOn(fmt.Errorf("unexpected target image type - %s (%v)", imageName, meta.MediaType))
 		}
 	}
 
-	if cparams.AsManifestList {
-		imageIndex = mutate.IndexMediaType(imageIndex, types.DockerManifestList)
-	}
-
-	imageIndex = mutate.AppendManifests(imageIndex, indexImageImgRefs...)
+	imageIndex, err := internalbuilder.NewImageIndex(images, cparams.AsManifestList)
+	xc.FailOn(err)
 
 	if err := remote.WriteIndex(imageIndexRef, imageIndex, remoteOpts...); err != nil {
 		var terr *transport.Error
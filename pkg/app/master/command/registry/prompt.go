@@ -19,6 +19,7 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		//including sub-commands here too
 		{Text: PullCmdName, Description: PullCmdNameUsage},
 		{Text: PushCmdName, Description: PushCmdNameUsage},
+		{Text: CopyCmdName, Description: CopyCmdNameUsage},
 		{Text: ImageIndexCreateCmdName, Description: ImageIndexCreateCmdNameUsage},
 		{Text: ServerCmdName, Description: ServerCmdNameUsage},
 	},
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	apiPathPrefix   = "/api/v1"
+	maxRequestBytes = 1 << 20
+)
+
+type apiHandler struct {
+	logger   *log.Entry
+	jobs     *jobManager
+	apiToken string
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+type artifactInfo struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+func newAPIHandler(logger *log.Entry, jobs *jobManager, apiToken string) http.Handler {
+	ref := &apiHandler{
+		logger:   logger.WithField("com", "api"),
+		jobs:     jobs,
+		apiToken: apiToken,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", ref.onHealth)
+	mux.HandleFunc("POST "+apiPathPrefix+"/jobs", ref.auth(ref.onSubmitJob))
+	mux.HandleFunc("GET "+apiPathPrefix+"/jobs", ref.auth(ref.onListJobs))
+	mux.HandleFunc("GET "+apiPathPrefix+"/jobs/{id}", ref.auth(ref.onGetJob))
+	mux.HandleFunc("DELETE "+apiPathPrefix+"/jobs/{id}", ref.auth(ref.onCancelJob))
+	mux.HandleFunc("GET "+apiPathPrefix+"/jobs/{id}/report", ref.auth(ref.onGetJobReport))
+	mux.HandleFunc("GET "+apiPathPrefix+"/jobs/{id}/output", ref.auth(ref.onGetJobOutput))
+	mux.HandleFunc("GET "+apiPathPrefix+"/jobs/{id}/artifacts", ref.auth(ref.onListJobArtifacts))
+	mux.HandleFunc("GET "+apiPathPrefix+"/jobs/{id}/artifacts/{path...}", ref.auth(ref.onGetJobArtifact))
+
+	return mux
+}
+
+func (ref *apiHandler) auth(next http.HandlerFunc) http.HandlerFunc {
+	if ref.apiToken == "" {
+		return next
+	}
+
+	expected := []byte("Bearer " + ref.apiToken)
+	return func(w http.ResponseWriter, r *http.Request) {
+		actual := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(actual, expected) != 1 {
+			ref.writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (ref *apiHandler) onHealth(w http.ResponseWriter, r *http.Request) {
+	ref.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (ref *apiHandler) onSubmitJob(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		ref.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := ref.jobs.Submit(&req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrJobQueueFull) {
+			status = http.StatusServiceUnavailable
+		}
+
+		ref.writeError(w, status, err)
+		return
+	}
+
+	w.Header().Set("Location", apiPathPrefix+"/jobs/"+job.ID)
+	ref.writeJSON(w, http.StatusCreated, job)
+}
+
+func (ref *apiHandler) onListJobs(w http.ResponseWriter, r *http.Request) {
+	ref.writeJSON(w, http.StatusOK, ref.jobs.List())
+}
+
+func (ref *apiHandler) onGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := ref.jobs.Get(r.PathValue("id"))
+	if err != nil {
+		ref.writeJobError(w, err)
+		return
+	}
+
+	ref.writeJSON(w, http.StatusOK, job)
+}
+
+func (ref *apiHandler) onCancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := ref.jobs.Cancel(r.PathValue("id"))
+	if err != nil {
+		ref.writeJobError(w, err)
+		return
+	}
+
+	ref.writeJSON(w, http.StatusAccepted, job)
+}
+
+func (ref *apiHandler) onGetJobReport(w http.ResponseWriter, r *http.Request) {
+	ref.serveJobFile(w, r, jobReportFileName, "application/json")
+}
+
+func (ref *apiHandler) onGetJobOutput(w http.ResponseWriter, r *http.Request) {
+	ref.serveJobFile(w, r, jobOutputFileName, "text/plain; charset=utf-8")
+}
+
+func (ref *apiHandler) onListJobArtifacts(w http.ResponseWriter, r *http.Request) {
+	jobDir, err := ref.jobs.JobDir(r.PathValue("id"))
+	if err != nil {
+		ref.writeJobError(w, err)
+		return
+	}
+
+	artifactsDir := filepath.Join(jobDir, jobArtifactsDir)
+	artifacts := []artifactInfo{}
+	err = filepath.WalkDir(artifactsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(artifactsDir, p)
+		if err != nil {
+			return err
+		}
+
+		artifacts = append(artifacts, artifactInfo{
+			Path: filepath.ToSlash(rel),
+			Size: info.Size(),
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		ref.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ref.writeJSON(w, http.StatusOK, artifacts)
+}
+
+func (ref *apiHandler) onGetJobArtifact(w http.ResponseWriter, r *http.Request) {
+	jobDir, err := ref.jobs.JobDir(r.PathValue("id"))
+	if err != nil {
+		ref.writeJobError(w, err)
+		return
+	}
+
+	//the artifact path must stay in the job artifacts directory
+	rel := filepath.Clean("/" + r.PathValue("path"))
+	if rel == "/" || strings.Contains(rel, "\x00") {
+		ref.writeError(w, http.StatusBadRequest, errors.New("bad artifact path"))
+		return
+	}
+
+	artifactPath, err := resolveArtifactPath(filepath.Join(jobDir, jobArtifactsDir), rel)
+	if err != nil {
+		ref.writeError(w, http.StatusNotFound, errors.New("artifact not found"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeFile(w, r, artifactPath)
+}
+
+// resolveArtifactPath returns the artifact file path with the symlinks resolved
+// (the artifact file must be a regular file in the artifacts directory;
+// the symlinks in any path component can point outside of the directory)
+func resolveArtifactPath(artifactsDir, rel string) (string, error) {
+	baseDir, err := filepath.EvalSymlinks(artifactsDir)
+	if err != nil {
+		return "", err
+	}
+
+	artifactPath, err := filepath.EvalSymlinks(filepath.Join(artifactsDir, rel))
+	if err != nil {
+		return "", err
+	}
+
+	relPath, err := filepath.Rel(baseDir, artifactPath)
+	if err != nil {
+		return "", err
+	}
+
+	if relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("artifact path is outside of the artifacts directory - %s", rel)
+	}
+
+	info, err := os.Stat(artifactPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("artifact is not a regular file - %s", rel)
+	}
+
+	return artifactPath, nil
+}
+
+func (ref *apiHandler) serveJobFile(w http.ResponseWriter, r *http.Request, name, contentType string) {
+	jobDir, err := ref.jobs.JobDir(r.PathValue("id"))
+	if err != nil {
+		ref.writeJobError(w, err)
+		return
+	}
+
+	filePath := filepath.Join(jobDir, name)
+	if _, err := os.Stat(filePath); err != nil {
+		ref.writeError(w, http.StatusNotFound, errors.New("job file not available"))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	http.ServeFile(w, r, filePath)
+}
+
+func (ref *apiHandler) writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		ref.writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrJobDone):
+		ref.writeError(w, http.StatusConflict, err)
+	default:
+		ref.writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (ref *apiHandler) writeError(w http.ResponseWriter, status int, err error) {
+	ref.logger.Debugf("request error (%d) - %v", status, err)
+	ref.writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+func (ref *apiHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		ref.logger.Debugf("writeJSON error - %v", err)
+	}
+}
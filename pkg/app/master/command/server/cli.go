@@ -1,10 +1,12 @@
 package server
 
 import (
-	"github.com/slimtoolkit/slim/pkg/app"
-	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"fmt"
 
 	"github.com/urfave/cli/v2"
+
+	"github.com/slimtoolkit/slim/pkg/app"
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
 )
 
 const (
@@ -17,6 +19,13 @@ var CLI = &cli.Command{
 	Name:    Name,
 	Aliases: []string{Alias},
 	Usage:   Usage,
+	Flags: []cli.Flag{
+		cflag(FlagAddress),
+		cflag(FlagPort),
+		cflag(FlagJobsDir),
+		cflag(FlagMaxJobs),
+		cflag(FlagAPIToken),
+	},
 	Action: func(ctx *cli.Context) error {
 		gcvalues := command.GlobalFlagValues(ctx)
 		xc := app.NewExecutionContext(
@@ -24,10 +33,42 @@ var CLI = &cli.Command{
 			gcvalues.QuietCLIMode,
 			gcvalues.OutputFormat)
 
+		cparams, err := CommandFlagValues(ctx)
+		if err != nil {
+			xc.Out.Error("param.error", err.Error())
+			cli.ShowCommandHelp(ctx, Name)
+			return nil
+		}
+
 		OnCommand(
 			xc,
-			gcvalues)
+			gcvalues,
+			cparams)
 
 		return nil
 	},
 }
+
+type CommandParams struct {
+	Address  string `json:"address"`
+	Port     uint   `json:"port"`
+	JobsDir  string `json:"jobs_dir,omitempty"`
+	MaxJobs  uint   `json:"max_jobs"`
+	APIToken string `json:"-"`
+}
+
+func CommandFlagValues(ctx *cli.Context) (*CommandParams, error) {
+	values := &CommandParams{
+		Address:  ctx.String(FlagAddress),
+		Port:     ctx.Uint(FlagPort),
+		JobsDir:  ctx.String(FlagJobsDir),
+		MaxJobs:  ctx.Uint(FlagMaxJobs),
+		APIToken: ctx.String(FlagAPIToken),
+	}
+
+	if values.MaxJobs == 0 {
+		return nil, fmt.Errorf("max-jobs must be greater than 0")
+	}
+
+	return values, nil
+}
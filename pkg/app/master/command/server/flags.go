@@ -0,0 +1,66 @@
+package server
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// Server command flag names and usage descriptions
+const (
+	FlagAddress      = "address"
+	FlagAddressUsage = "API server address"
+
+	FlagPort      = "port"
+	FlagPortUsage = "API server port"
+
+	FlagJobsDir      = "jobs-dir"
+	FlagJobsDirUsage = "Directory for the job data (params, command reports, artifacts and output); app state path by default"
+
+	FlagMaxJobs      = "max-jobs"
+	FlagMaxJobsUsage = "Maximum number of jobs running at the same time"
+
+	FlagAPIToken      = "api-token"
+	FlagAPITokenUsage = "Bearer token required to call the API (required if the server address is not a loopback address)"
+)
+
+var Flags = map[string]cli.Flag{
+	FlagAddress: &cli.StringFlag{
+		Name:    FlagAddress,
+		Value:   "127.0.0.1",
+		Usage:   FlagAddressUsage,
+		EnvVars: []string{"DSLIM_SERVER_ADDR"},
+	},
+	FlagPort: &cli.UintFlag{
+		Name:    FlagPort,
+		Value:   7777,
+		Usage:   FlagPortUsage,
+		EnvVars: []string{"DSLIM_SERVER_PORT"},
+	},
+	FlagJobsDir: &cli.StringFlag{
+		Name:    FlagJobsDir,
+		Value:   "",
+		Usage:   FlagJobsDirUsage,
+		EnvVars: []string{"DSLIM_SERVER_JOBS_DIR"},
+	},
+	FlagMaxJobs: &cli.UintFlag{
+		Name:    FlagMaxJobs,
+		Value:   1,
+		Usage:   FlagMaxJobsUsage,
+		EnvVars: []string{"DSLIM_SERVER_MAX_JOBS"},
+	},
+	FlagAPIToken: &cli.StringFlag{
+		Name:    FlagAPIToken,
+		Value:   "",
+		Usage:   FlagAPITokenUsage,
+		EnvVars: []string{"DSLIM_SERVER_API_TOKEN"},
+	},
+}
+
+func cflag(name string) cli.Flag {
+	cf, ok := Flags[name]
+	if !ok {
+		log.Fatalf("unknown flag='%s'", name)
+	}
+
+	return cf
+}
@@ -1,6 +1,18 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
@@ -9,7 +21,6 @@ import (
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
-	v "github.com/slimtoolkit/slim/pkg/version"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -21,7 +32,8 @@ type ovars = app.OutVars
 // OnCommand implements the 'server' command
 func OnCommand(
 	xc *app.ExecutionContext,
-	gparams *command.GenericParams) {
+	gparams *command.GenericParams,
+	cparams *CommandParams) {
 	logger := log.WithFields(log.Fields{"app": appName, "cmd": Name})
 
 	viChan := version.CheckAsync(gparams.CheckVersion, gparams.InContainer, gparams.IsDSImage)
@@ -30,11 +42,32 @@ func OnCommand(
 	cmdReport.State = cmd.StateStarted
 
 	xc.Out.State(cmd.StateStarted)
+	xc.Out.Info("params",
+		ovars{
+			"address":  cparams.Address,
+			"port":     cparams.Port,
+			"max.jobs": cparams.MaxJobs,
+			"auth":     cparams.APIToken != "",
+		})
 
+	if cparams.APIToken == "" && !isLoopbackAddress(cparams.Address) {
+		xc.Out.Error("param.error.api.token",
+			fmt.Sprintf("the API token is required when the server address is not a loopback address ('%s')", cparams.Address))
+		xc.Out.State("exited",
+			ovars{
+				"exit.code": -1,
+			})
+		xc.Exit(-1)
+	}
+
+	//the job commands connect to Docker themselves
+	//(the server can still run the jobs that don't need Docker)
 	client, err := dockerclient.New(gparams.ClientConfig)
-	if err == dockerclient.ErrNoDockerInfo {
+	if err != nil {
 		exitMsg := "missing Docker connection info"
-		if gparams.InContainer && gparams.IsDSImage {
+		if err != dockerclient.ErrNoDockerInfo {
+			exitMsg = err.Error()
+		} else if gparams.InContainer && gparams.IsDSImage {
 			exitMsg = "make sure to pass the Docker connect parameters to the slim app container"
 		}
 
@@ -42,22 +75,79 @@ func OnCommand(
 			ovars{
 				"message": exitMsg,
 			})
+	} else if gparams.Debug {
+		version.Print(xc, Name, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+	}
 
-		exitCode := command.ECTCommon | command.ECCNoDockerConnectInfo
-		xc.Out.State("exited",
-			ovars{
-				"exit.code": exitCode,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
-			})
-		xc.Exit(exitCode)
+	jobsDir := cparams.JobsDir
+	if jobsDir == "" {
+		jobsDir = filepath.Join(fsutil.ResolveImageStateBasePath(gparams.StatePath), stateDirName, jobsDirName)
 	}
-	errutil.FailOn(err)
 
-	if gparams.Debug {
-		version.Print(xc, Name, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+	jobsDir, err = filepath.Abs(jobsDir)
+	xc.FailOn(err)
+
+	exePath, err := os.Executable()
+	xc.FailOn(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jobs, err := newJobManager(ctx, logger, exePath, jobGlobalArgs(gparams), jobsDir)
+	xc.FailOn(err)
+
+	jobs.Start(cparams.MaxJobs)
+
+	address := net.JoinHostPort(cparams.Address, strconv.FormatUint(uint64(cparams.Port), 10))
+	server := &http.Server{
+		Addr:              address,
+		Handler:           newAPIHandler(logger, jobs, cparams.APIToken),
+		ReadTimeout:       5 * time.Second,
+		ReadHeaderTimeout: 4 * time.Second,
+		//large artifacts might take a while to download
+		WriteTimeout: 10 * time.Minute,
+		IdleTimeout:  120 * time.Second,
 	}
 
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	go func() {
+		sig := <-sigCh
+		logger.Debugf("received signal - %v", sig)
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		errutil.WarnOn(server.Shutdown(shutdownCtx))
+	}()
+
+	cmdReport.Address = address
+	cmdReport.JobsDir = jobsDir
+
+	xc.Out.State("server.start",
+		ovars{
+			"address":  address,
+			"jobs.dir": jobsDir,
+		})
+
+	err = server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		xc.Out.Message("Server is done...")
+	} else {
+		xc.FailOn(err)
+	}
+
+	//stopping the running jobs (the job commands get SIGTERM)
+	cancel()
+	jobs.Wait()
+
+	cmdReport.JobCount = len(jobs.List())
+	xc.Out.State("server.done",
+		ovars{
+			"jobs": cmdReport.JobCount,
+		})
+
 	xc.Out.State(cmd.StateCompleted)
 	cmdReport.State = cmd.StateCompleted
 	xc.Out.State(cmd.StateDone)
@@ -73,3 +163,63 @@ func OnCommand(
 			})
 	}
 }
+
+const (
+	stateDirName = ".slim-state"
+	jobsDirName  = "server-jobs"
+)
+
+// isLoopbackAddress returns true if the server address is only available locally
+// (the empty address means all interfaces)
+func isLoopbackAddress(address string) bool {
+	if address == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
+// jobGlobalArgs creates the global flags for the job commands
+func jobGlobalArgs(gparams *command.GenericParams) []string {
+	args := []string{
+		fmt.Sprintf("--%s=false", command.FlagCheckVersion),
+		fmt.Sprintf("--%s=%s", command.FlagOutputFormat, command.OutputFormatJSON),
+	}
+
+	if gparams.Debug {
+		args = append(args, fmt.Sprintf("--%s", command.FlagDebug))
+	}
+
+	if gparams.LogLevel != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", command.FlagLogLevel, gparams.LogLevel))
+	}
+
+	if gparams.StatePath != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", command.FlagStatePath, gparams.StatePath))
+	}
+
+	if gparams.ClientConfig != nil {
+		if gparams.ClientConfig.Engine != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", command.FlagCRTEngine, gparams.ClientConfig.Engine))
+		}
+
+		if gparams.ClientConfig.Host != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", command.FlagHost, gparams.ClientConfig.Host))
+		}
+
+		args = append(args,
+			fmt.Sprintf("--%s=%t", command.FlagUseTLS, gparams.ClientConfig.UseTLS),
+			fmt.Sprintf("--%s=%t", command.FlagVerifyTLS, gparams.ClientConfig.VerifyTLS))
+
+		if gparams.ClientConfig.TLSCertPath != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", command.FlagTLSCertPath, gparams.ClientConfig.TLSCertPath))
+		}
+
+		if gparams.ClientConfig.APIVersion != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", command.FlagAPIVersion, gparams.ClientConfig.APIVersion))
+		}
+	}
+
+	return args
+}
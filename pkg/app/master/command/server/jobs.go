@@ -0,0 +1,764 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/lint"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/xray"
+	cmd "github.com/slimtoolkit/slim/pkg/command"
+	"github.com/slimtoolkit/slim/pkg/docker/linter"
+	"github.com/slimtoolkit/slim/pkg/imagereader"
+)
+
+// Job states (in addition to the command report states)
+const (
+	JobStateQueued   = "queued"
+	JobStateCanceled = "canceled"
+)
+
+const (
+	jobFileName       = "job.json"
+	jobReportFileName = "report.json"
+	jobOutputFileName = "output.log"
+	jobArtifactsDir   = "artifacts"
+
+	jobQueueSize       = 1024
+	jobStopGracePeriod = 30 * time.Second
+)
+
+var (
+	ErrUnsupportedJobCommand = errors.New("unsupported job command")
+	ErrJobNotFound           = errors.New("job not found")
+	ErrJobQueueFull          = errors.New("job queue is full")
+	ErrJobDone               = errors.New("job is already done")
+)
+
+// Commands that can be executed as jobs
+var jobCommands = map[string]struct{}{
+	string(cmd.Build):   {},
+	string(cmd.Xray):    {},
+	string(cmd.Lint):    {},
+	string(cmd.Profile): {},
+}
+
+// Params managed by the server (for each job command)
+var serverManagedParams = map[string]string{
+	command.FlagCopyMetaArtifacts:   "artifacts are saved in the job artifacts directory",
+	xray.FlagExportAllDataArtifacts: "artifacts are saved in the job artifacts directory",
+	command.FlagCommandReport:       "the command report is saved in the job directory",
+	lint.FlagTargetType:             "the lint jobs target images",
+}
+
+// Params the API clients can use for each job command
+// (the other params execute commands or access files on the server host;
+// the values for the params in jobParamValueChecks are checked too)
+var jobCommandParams = map[string]map[string]struct{}{
+	string(cmd.Build):   paramSet(containerParams, probeParams, buildParams),
+	string(cmd.Profile): paramSet(containerParams, probeParams),
+	string(cmd.Xray):    paramSet(xrayParams),
+	string(cmd.Lint):    paramSet(lintParams),
+}
+
+var containerParams = []string{
+	"target",
+	"pull",
+	"registry-account",
+	"registry-secret",
+	"show-clogs",
+	"show-plogs",
+	"remove-file-artifacts",
+	"continue-after",
+	"entrypoint",
+	"cmd",
+	"env",
+	"expose",
+	"label",
+	"hostname",
+	"etc-hosts-map",
+	"container-dns",
+	"container-dns-search",
+	"user",
+	"workdir",
+	"run-target-as-user",
+	"enable-mondel",
+}
+
+var probeParams = []string{
+	"http-probe",
+	"http-probe-off",
+	"http-probe-cmd",
+	"http-probe-start-wait",
+	"http-probe-retry-count",
+	"http-probe-retry-wait",
+	"http-probe-ports",
+	"http-probe-full",
+	"http-probe-exit-on-failure",
+	"http-probe-crawl",
+	"http-crawl-max-depth",
+	"http-crawl-max-page-count",
+	"http-crawl-concurrency",
+	"http-max-concurrent-crawlers",
+	"http-probe-apispec",
+	"http-probe-apispec-credential",
+	"http-probe-graphql",
+	"http-probe-graphql-endpoint",
+	"http-probe-graphql-mutations",
+	"tcp-probe-ports",
+}
+
+var buildParams = []string{
+	"tag",
+	"tag-fat",
+	"show-blogs",
+	"http-probe-verify",
+	"delete-generated-fat-image",
+	"image-overrides",
+	"new-entrypoint",
+	"new-cmd",
+	"new-expose",
+	"new-workdir",
+	"new-env",
+	"new-volume",
+	"new-label",
+	"remove-expose",
+	"remove-env",
+	"remove-label",
+	"remove-volume",
+	"exclude-pattern",
+	"exclude-varlock-files",
+	"exclude-mounts",
+	"include-path",
+	"include-bin",
+	"include-exe",
+	"include-dir-bins",
+	"include-shell",
+	"include-workdir",
+	"include-new",
+	"include-zoneinfo",
+	"include-oslibs-net",
+	"include-ssh-client",
+	"include-cert-all",
+	"include-cert-bundles-only",
+	"include-cert-dirs",
+	"include-cert-pk-all",
+	"include-cert-pk-dirs",
+	"include-node-package",
+	"include-app-image-all",
+	"include-app-next-dir",
+	"include-app-next-build-dir",
+	"include-app-next-dist-dir",
+	"include-app-next-static-dir",
+	"include-app-next-nodemodules-dir",
+	"include-app-nuxt-dir",
+	"include-app-nuxt-build-dir",
+	"include-app-nuxt-dist-dir",
+	"include-app-nuxt-static-dir",
+	"include-app-nuxt-nodemodules-dir",
+	"preserve-path",
+	"path-perms",
+	"keep-perms",
+	"obfuscate-metadata",
+	"image-build-engine",
+	"image-build-arch",
+	"image-build-base",
+	"rta-onbuild-base-image",
+	"rta-source-ptrace",
+	"sbom",
+}
+
+var xrayParams = []string{
+	"target",
+	"pull",
+	"registry-account",
+	"registry-secret",
+	"show-plogs",
+	"remove-file-artifacts",
+	"reuse-saved-image",
+	"changes",
+	"changes-output",
+	"layer",
+	"add-image-manifest",
+	"add-image-config",
+	"layer-changes-max",
+	"all-changes-max",
+	"add-changes-max",
+	"modify-changes-max",
+	"delete-changes-max",
+	"change-path",
+	"change-data",
+	"change-match-layers-only",
+	"change-data-hash",
+	"hash-data",
+	"detect-utf8",
+	"detect-duplicates",
+	"show-duplicates",
+	"show-special-perms",
+	"detect-all-certs",
+	"detect-all-cert-pks",
+	"detect-identities",
+	"detect-scheduled-tasks",
+	"detect-services",
+	"detect-system-hooks",
+	"detect-secrets",
+	"top-changes-max",
+	"sbom",
+}
+
+var lintParams = []string{
+	"target",
+	"include-check-id",
+	"include-check-label",
+	"exclude-check-id",
+	"exclude-check-label",
+	"show-nohits",
+	"show-snippet",
+	"list-checks",
+	"output",
+}
+
+// Value checks for the allowed params that can also reference files on the server host
+var jobParamValueChecks = map[string]func(value string) error{
+	"image-build-base": imageRefParamValue,
+}
+
+// imageRefParamValue accepts only the registry and Docker daemon image references
+// (the 'docker-archive' and 'oci' image locations are files on the server host)
+func imageRefParamValue(value string) error {
+	location, err := imagereader.ParseLocation(value, imagereader.RegistryLocation)
+	if err != nil {
+		return err
+	}
+
+	switch location.Type {
+	case imagereader.RegistryLocation, imagereader.DockerDaemonLocation:
+		return nil
+	default:
+		return fmt.Errorf("'%s' image locations are not allowed", location.Type)
+	}
+}
+
+func paramSet(lists ...[]string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, list := range lists {
+		for _, name := range list {
+			set[name] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+// JobRequest is the job submission data
+type JobRequest struct {
+	Command string `json:"command"`
+	// Params uses the command flag names for the keys
+	// and the same values as the command params file
+	Params map[string]interface{} `json:"params"`
+}
+
+// Job describes the command job
+type Job struct {
+	ID         string                 `json:"id"`
+	Command    string                 `json:"command"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	State      string                 `json:"state"`
+	Error      string                 `json:"error,omitempty"`
+	ExitCode   int                    `json:"exit_code"`
+	CreatedAt  time.Time              `json:"created_at"`
+	StartedAt  *time.Time             `json:"started_at,omitempty"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+
+	dir    string
+	cancel context.CancelFunc
+}
+
+// IsDone returns true if the job is not queued and not running
+func (ref *Job) IsDone() bool {
+	return ref.State != JobStateQueued && ref.State != cmd.StateStarted
+}
+
+type jobManager struct {
+	ctx      context.Context
+	logger   *log.Entry
+	exePath  string
+	baseArgs []string
+	jobsDir  string
+
+	mu    sync.Mutex
+	jobs  map[string]*Job
+	queue chan *Job
+	wg    sync.WaitGroup
+}
+
+func newJobManager(
+	ctx context.Context,
+	logger *log.Entry,
+	exePath string,
+	baseArgs []string,
+	jobsDir string) (*jobManager, error) {
+	if err := os.MkdirAll(jobsDir, 0755); err != nil {
+		return nil, err
+	}
+
+	ref := &jobManager{
+		ctx:      ctx,
+		logger:   logger.WithField("com", "job.manager"),
+		exePath:  exePath,
+		baseArgs: baseArgs,
+		jobsDir:  jobsDir,
+		jobs:     map[string]*Job{},
+		queue:    make(chan *Job, jobQueueSize),
+	}
+
+	if err := ref.loadJobs(); err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+// loadJobs loads the jobs from the previous server runs
+func (ref *jobManager) loadJobs() error {
+	entries, err := os.ReadDir(ref.jobsDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		jobDir := filepath.Join(ref.jobsDir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(jobDir, jobFileName))
+		if err != nil {
+			ref.logger.Debugf("loadJobs: skipping '%s' - %v", jobDir, err)
+			continue
+		}
+
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			ref.logger.Debugf("loadJobs: skipping '%s' - %v", jobDir, err)
+			continue
+		}
+
+		job.dir = jobDir
+		if !job.IsDone() {
+			//the server stopped before the job finished
+			job.State = cmd.StateError
+			job.Error = "job interrupted (server stopped)"
+			ref.saveJob(&job)
+		}
+
+		ref.jobs[job.ID] = &job
+	}
+
+	ref.logger.Debugf("loadJobs: %d jobs", len(ref.jobs))
+	return nil
+}
+
+// Start starts the job workers
+func (ref *jobManager) Start(workers uint) {
+	for i := uint(0); i < workers; i++ {
+		ref.wg.Add(1)
+		go func() {
+			defer ref.wg.Done()
+			for {
+				select {
+				case <-ref.ctx.Done():
+					return
+				case job := <-ref.queue:
+					ref.runJob(job)
+				}
+			}
+		}()
+	}
+}
+
+// Wait waits for the job workers to finish (after the manager context is canceled)
+func (ref *jobManager) Wait() {
+	ref.wg.Wait()
+}
+
+// Submit validates and queues the new job
+func (ref *jobManager) Submit(req *JobRequest) (*Job, error) {
+	if _, found := jobCommands[req.Command]; !found {
+		return nil, fmt.Errorf("%w - '%s'", ErrUnsupportedJobCommand, req.Command)
+	}
+
+	//validating the params (the job dir doesn't matter here)
+	if _, err := jobCommandArgs(req.Command, req.Params, ""); err != nil {
+		return nil, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		ID:        id,
+		Command:   req.Command,
+		Params:    req.Params,
+		State:     JobStateQueued,
+		CreatedAt: time.Now().UTC(),
+		dir:       filepath.Join(ref.jobsDir, id),
+	}
+
+	if err := os.MkdirAll(filepath.Join(job.dir, jobArtifactsDir), 0755); err != nil {
+		return nil, err
+	}
+
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+
+	select {
+	case ref.queue <- job:
+	default:
+		os.RemoveAll(job.dir)
+		return nil, ErrJobQueueFull
+	}
+
+	ref.jobs[job.ID] = job
+	ref.saveJob(job)
+
+	ref.logger.Debugf("Submit: job=%s command=%s", job.ID, job.Command)
+	return job.copy(), nil
+}
+
+// Get returns a copy of the job info
+func (ref *jobManager) Get(id string) (*Job, error) {
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+
+	job, found := ref.jobs[id]
+	if !found {
+		return nil, ErrJobNotFound
+	}
+
+	return job.copy(), nil
+}
+
+// List returns all jobs (the newest jobs first)
+func (ref *jobManager) List() []*Job {
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(ref.jobs))
+	for _, job := range ref.jobs {
+		jobs = append(jobs, job.copy())
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+
+	return jobs
+}
+
+// Cancel cancels the queued or running job
+func (ref *jobManager) Cancel(id string) (*Job, error) {
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+
+	job, found := ref.jobs[id]
+	if !found {
+		return nil, ErrJobNotFound
+	}
+
+	switch {
+	case job.State == JobStateQueued:
+		//the worker skips canceled jobs
+		job.State = JobStateCanceled
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		ref.saveJob(job)
+	case job.State == cmd.StateStarted && job.cancel != nil:
+		job.cancel()
+	default:
+		return nil, ErrJobDone
+	}
+
+	return job.copy(), nil
+}
+
+// JobDir returns the job data directory
+func (ref *jobManager) JobDir(id string) (string, error) {
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+
+	job, found := ref.jobs[id]
+	if !found {
+		return "", ErrJobNotFound
+	}
+
+	return job.dir, nil
+}
+
+func (ref *jobManager) runJob(job *Job) {
+	logger := ref.logger.WithField("job", job.ID)
+
+	ref.mu.Lock()
+	if job.State != JobStateQueued {
+		ref.mu.Unlock()
+		logger.Debugf("runJob: skipping job (state=%s)", job.State)
+		return
+	}
+
+	args, err := jobCommandArgs(job.Command, job.Params, job.dir)
+	if err != nil {
+		ref.finishJob(job, cmd.StateError, -1, err)
+		ref.mu.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ref.ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+	job.StartedAt = &now
+	job.State = cmd.StateStarted
+	job.cancel = cancel
+	ref.saveJob(job)
+	ref.mu.Unlock()
+
+	output, err := os.Create(filepath.Join(job.dir, jobOutputFileName))
+	if err != nil {
+		ref.mu.Lock()
+		ref.finishJob(job, cmd.StateError, -1, err)
+		ref.mu.Unlock()
+		return
+	}
+	defer output.Close()
+
+	fullArgs := append([]string{}, ref.baseArgs...)
+	fullArgs = append(fullArgs,
+		fmt.Sprintf("--%s=%s", command.FlagCommandReport, filepath.Join(job.dir, jobReportFileName)))
+	fullArgs = append(fullArgs, job.Command)
+	fullArgs = append(fullArgs, args...)
+
+	logger.Debugf("runJob: %s %v", ref.exePath, fullArgs)
+
+	jobCmd := exec.CommandContext(ctx, ref.exePath, fullArgs...)
+	jobCmd.Dir = job.dir
+	jobCmd.Stdout = output
+	jobCmd.Stderr = output
+	jobCmd.Cancel = func() error {
+		//give the command a chance to clean up (e.g., remove its containers)
+		return jobCmd.Process.Signal(syscall.SIGTERM)
+	}
+	jobCmd.WaitDelay = jobStopGracePeriod
+
+	err = jobCmd.Run()
+
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+
+	job.cancel = nil
+	exitCode := 0
+	if jobCmd.ProcessState != nil {
+		exitCode = jobCmd.ProcessState.ExitCode()
+	}
+
+	if ctx.Err() != nil {
+		ref.finishJob(job, JobStateCanceled, exitCode, nil)
+		return
+	}
+
+	//the command report has the final command state
+	state, reportErr := reportState(filepath.Join(job.dir, jobReportFileName))
+	switch {
+	case err != nil:
+		if state == "" || state == cmd.StateDone || state == cmd.StateCompleted {
+			state = cmd.StateError
+		}
+	case reportErr != nil:
+		logger.Debugf("runJob: no command report - %v", reportErr)
+		state = cmd.StateDone
+	}
+
+	ref.finishJob(job, state, exitCode, err)
+}
+
+// finishJob updates the job final state (must be called with the lock)
+func (ref *jobManager) finishJob(job *Job, state string, exitCode int, err error) {
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	job.State = state
+	job.ExitCode = exitCode
+	if err != nil {
+		job.Error = err.Error()
+	}
+
+	ref.saveJob(job)
+	ref.logger.Debugf("finishJob: job=%s state=%s exit.code=%d", job.ID, state, exitCode)
+}
+
+func (ref *jobManager) saveJob(job *Job) {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		ref.logger.Errorf("saveJob: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(filepath.Join(job.dir, jobFileName), data, 0644); err != nil {
+		ref.logger.Errorf("saveJob: %v", err)
+	}
+}
+
+func (ref *Job) copy() *Job {
+	out := *ref
+	out.cancel = nil
+	return &out
+}
+
+func newJobID() (string, error) {
+	data := make([]byte, 12)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(data), nil
+}
+
+func reportState(reportPath string) (string, error) {
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return "", err
+	}
+
+	var info struct {
+		State string `json:"state"`
+	}
+
+	if err := json.Unmarshal(data, &info); err != nil {
+		return "", err
+	}
+
+	return info.State, nil
+}
+
+// jobCommandArgs converts the job params to the command flags
+func jobCommandArgs(cmdName string, params map[string]interface{}, jobDir string) ([]string, error) {
+	cmdFlags := commandFlagNames(cmdName)
+	if cmdFlags == nil {
+		return nil, fmt.Errorf("%w - '%s'", ErrUnsupportedJobCommand, cmdName)
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		if reason, found := serverManagedParams[name]; found {
+			return nil, fmt.Errorf("param '%s' is managed by the server (%s)", name, reason)
+		}
+
+		if _, found := cmdFlags[name]; !found {
+			return nil, fmt.Errorf("unknown '%s' command param - '%s'", cmdName, name)
+		}
+
+		if _, found := jobCommandParams[cmdName][name]; !found {
+			//e.g., host-exec, mount or command-params-file
+			return nil, fmt.Errorf("param '%s' is not supported for '%s' jobs (not allowed to run commands or access files on the server host)", name, cmdName)
+		}
+
+		values, err := paramValues(name, params[name])
+		if err != nil {
+			return nil, err
+		}
+
+		if check, found := jobParamValueChecks[name]; found {
+			for _, v := range values {
+				if err := check(v); err != nil {
+					return nil, fmt.Errorf("param '%s' value is not supported for '%s' jobs - %v", name, cmdName, err)
+				}
+			}
+		}
+
+		for _, v := range values {
+			args = append(args, fmt.Sprintf("--%s=%s", name, v))
+		}
+	}
+
+	artifactsDir := filepath.Join(jobDir, jobArtifactsDir)
+	switch cmdName {
+	case string(cmd.Build), string(cmd.Profile):
+		args = append(args, fmt.Sprintf("--%s=%s", command.FlagCopyMetaArtifacts, artifactsDir))
+	case string(cmd.Xray):
+		args = append(args, fmt.Sprintf("--%s=%s",
+			xray.FlagExportAllDataArtifacts, filepath.Join(artifactsDir, "xray-data.tar")))
+	case string(cmd.Lint):
+		args = append(args, fmt.Sprintf("--%s=%s", lint.FlagTargetType, linter.ImageTargetType))
+	}
+
+	return args, nil
+}
+
+func paramValues(name string, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if _, isList := item.([]interface{}); isList {
+				return nil, fmt.Errorf("unsupported nested list value for param '%s'", name)
+			}
+
+			values, err := paramValues(name, item)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, values...)
+		}
+
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type for param '%s' - %T", name, value)
+	}
+}
+
+// commandFlagNames returns the flag names (and aliases) for the registered command
+func commandFlagNames(cmdName string) map[string]struct{} {
+	for _, c := range command.GetCommands() {
+		if c.Name != cmdName {
+			continue
+		}
+
+		names := map[string]struct{}{}
+		for _, f := range c.Flags {
+			for _, name := range f.Names() {
+				names[name] = struct{}{}
+			}
+		}
+
+		return names
+	}
+
+	return nil
+}
@@ -2,9 +2,24 @@ package server
 
 import (
 	"github.com/c-bata/go-prompt"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
 )
 
 var CommandSuggestion = prompt.Suggest{
 	Text:        Name,
 	Description: Usage,
 }
+
+var CommandFlagSuggestions = &command.FlagSuggestions{
+	Names: []prompt.Suggest{
+		{Text: command.FullFlagName(FlagAddress), Description: FlagAddressUsage},
+		{Text: command.FullFlagName(FlagPort), Description: FlagPortUsage},
+		{Text: command.FullFlagName(FlagJobsDir), Description: FlagJobsDirUsage},
+		{Text: command.FullFlagName(FlagMaxJobs), Description: FlagMaxJobsUsage},
+		{Text: command.FullFlagName(FlagAPIToken), Description: FlagAPITokenUsage},
+	},
+	Values: map[string]command.CompleteValue{
+		command.FullFlagName(FlagJobsDir): command.CompleteFile,
+	},
+}
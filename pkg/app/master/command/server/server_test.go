@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/command/build"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/lint"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/profile"
+	"github.com/slimtoolkit/slim/pkg/app/master/command/xray"
+)
+
+var registerOnce sync.Once
+
+func registerJobCommands() {
+	registerOnce.Do(func() {
+		build.RegisterCommand()
+		xray.RegisterCommand()
+		lint.RegisterCommand()
+		profile.RegisterCommand()
+	})
+}
+
+func TestJobCommandParamsAreCommandFlags(t *testing.T) {
+	registerJobCommands()
+
+	for cmdName, params := range jobCommandParams {
+		cmdFlags := commandFlagNames(cmdName)
+		require.NotNil(t, cmdFlags, cmdName)
+		for name := range params {
+			_, found := cmdFlags[name]
+			assert.True(t, found, "%s: unknown allowed param '%s'", cmdName, name)
+		}
+	}
+}
+
+func TestJobCommandArgs(t *testing.T) {
+	registerJobCommands()
+
+	tt := []struct {
+		cmd      string
+		params   map[string]interface{}
+		expected []string
+		errText  string
+	}{
+		{
+			cmd: "xray",
+			params: map[string]interface{}{
+				"target":  "nginx:latest",
+				"changes": []interface{}{"add", "modify"},
+			},
+			expected: []string{
+				"--changes=add",
+				"--changes=modify",
+				"--target=nginx:latest",
+				"--export-all-data-artifacts=/jobs/1/artifacts/xray-data.tar",
+			},
+		},
+		{
+			cmd: "build",
+			params: map[string]interface{}{
+				"target":                 "app",
+				"http-probe":             false,
+				"http-probe-retry-count": float64(3),
+			},
+			expected: []string{
+				"--http-probe=false",
+				"--http-probe-retry-count=3",
+				"--target=app",
+				"--copy-meta-artifacts=/jobs/1/artifacts",
+			},
+		},
+		{
+			cmd: "build",
+			params: map[string]interface{}{
+				"target":           "app",
+				"image-build-base": "registry:5000/base:v1",
+			},
+			expected: []string{
+				"--image-build-base=registry:5000/base:v1",
+				"--target=app",
+				"--copy-meta-artifacts=/jobs/1/artifacts",
+			},
+		},
+		{
+			cmd:      "build",
+			params:   map[string]interface{}{"image-build-base": "docker-daemon:base:v1"},
+			expected: []string{"--image-build-base=docker-daemon:base:v1", "--copy-meta-artifacts=/jobs/1/artifacts"},
+		},
+		{
+			cmd:      "lint",
+			params:   map[string]interface{}{"target": "app"},
+			expected: []string{"--target=app", "--target-type=image"},
+		},
+		{cmd: "build", params: map[string]interface{}{"host-exec": "id"}, errText: "not supported"},
+		{cmd: "build", params: map[string]interface{}{"host-exec-file": "/tmp/cmds"}, errText: "not supported"},
+		{cmd: "profile", params: map[string]interface{}{"exec": "id"}, errText: "not supported"},
+		{cmd: "build", params: map[string]interface{}{"mount": "/:/host"}, errText: "not supported"},
+		{cmd: "build", params: map[string]interface{}{"command-params-file": "/etc/passwd"}, errText: "not supported"},
+		{cmd: "build", params: map[string]interface{}{"http-probe-cmd-file": "/etc/passwd"}, errText: "not supported"},
+		{cmd: "xray", params: map[string]interface{}{"detect-secrets-param": "rules_file:/etc/passwd"}, errText: "not supported"},
+		{cmd: "lint", params: map[string]interface{}{"fix-in-place": true}, errText: "not supported"},
+		{cmd: "lint", params: map[string]interface{}{"target-type": "dockerfile"}, errText: "managed by the server"},
+		{cmd: "build", params: map[string]interface{}{"copy-meta-artifacts": "/tmp"}, errText: "managed by the server"},
+		{cmd: "build", params: map[string]interface{}{"image-build-base": "docker-archive:/etc/passwd"}, errText: "not supported"},
+		{cmd: "build", params: map[string]interface{}{"image-build-base": "oci:/var/lib/images:app"}, errText: "not supported"},
+		{cmd: "build", params: map[string]interface{}{"image-build-base": []interface{}{"alpine", "oci:/tmp"}}, errText: "not supported"},
+		{cmd: "build", params: map[string]interface{}{"no-such-flag": "x"}, errText: "unknown"},
+		{cmd: "build", params: map[string]interface{}{"target": map[string]interface{}{}}, errText: "unsupported value type"},
+		{cmd: "images", params: map[string]interface{}{}, errText: "unsupported job command"},
+	}
+
+	for _, test := range tt {
+		args, err := jobCommandArgs(test.cmd, test.params, "/jobs/1")
+		if test.errText != "" {
+			require.Error(t, err, "%s %v", test.cmd, test.params)
+			assert.Contains(t, err.Error(), test.errText)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, args)
+	}
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	tt := []struct {
+		in       string
+		expected bool
+	}{
+		{in: "127.0.0.1", expected: true},
+		{in: "127.0.0.2", expected: true},
+		{in: "::1", expected: true},
+		{in: "localhost", expected: true},
+		{in: "0.0.0.0", expected: false},
+		{in: "", expected: false},
+		{in: "192.168.1.10", expected: false},
+		{in: "example.com", expected: false},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, isLoopbackAddress(test.in), test.in)
+	}
+}
+
+func newTestAPIServer(t *testing.T, apiToken string) *httptest.Server {
+	registerJobCommands()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := log.WithField("test", t.Name())
+	jobs, err := newJobManager(ctx, logger, "/bin/false", nil, t.TempDir())
+	require.NoError(t, err)
+
+	server := httptest.NewServer(newAPIHandler(logger, jobs, apiToken))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func apiRequest(t *testing.T, method, url, token, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+func TestAPIToken(t *testing.T) {
+	server := newTestAPIServer(t, "secret")
+
+	res := apiRequest(t, http.MethodGet, server.URL+"/health", "", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = apiRequest(t, http.MethodGet, server.URL+apiPathPrefix+"/jobs", "", "")
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	res = apiRequest(t, http.MethodGet, server.URL+apiPathPrefix+"/jobs", "wrong", "")
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	res = apiRequest(t, http.MethodGet, server.URL+apiPathPrefix+"/jobs", "secret", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestAPISubmitJob(t *testing.T) {
+	server := newTestAPIServer(t, "")
+
+	res := apiRequest(t, http.MethodPost, server.URL+apiPathPrefix+"/jobs", "",
+		`{"command":"build","params":{"target":"app","host-exec":"touch /tmp/owned"}}`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	var apiErr apiError
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&apiErr))
+	assert.Contains(t, apiErr.Error, "host-exec")
+
+	res = apiRequest(t, http.MethodPost, server.URL+apiPathPrefix+"/jobs", "",
+		`{"command":"xray","params":{"target":"nginx:latest"}}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var job Job
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&job))
+	assert.Equal(t, JobStateQueued, job.State)
+	assert.Equal(t, apiPathPrefix+"/jobs/"+job.ID, res.Header.Get("Location"))
+
+	res = apiRequest(t, http.MethodGet, server.URL+apiPathPrefix+"/jobs/"+job.ID+"/artifacts/..%2f..%2fjob.json", "", "")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res = apiRequest(t, http.MethodDelete, server.URL+apiPathPrefix+"/jobs/"+job.ID, "", "")
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+
+	res = apiRequest(t, http.MethodDelete, server.URL+apiPathPrefix+"/jobs/"+job.ID, "", "")
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+}
+
+func TestResolveArtifactPath(t *testing.T) {
+	jobDir := t.TempDir()
+	artifactsDir := filepath.Join(jobDir, jobArtifactsDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(artifactsDir, "reports"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(artifactsDir, "reports", "creport.json"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(jobDir, jobFileName), []byte("{}"), 0644))
+
+	outsideDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outsideDir, "secret"), []byte("secret"), 0644))
+
+	//the symlinks to the files in and outside of the artifacts directory
+	require.NoError(t, os.Symlink(filepath.Join(artifactsDir, "reports", "creport.json"), filepath.Join(artifactsDir, "report.json")))
+	require.NoError(t, os.Symlink(filepath.Join(outsideDir, "secret"), filepath.Join(artifactsDir, "secret")))
+	require.NoError(t, os.Symlink(outsideDir, filepath.Join(artifactsDir, "outside")))
+	require.NoError(t, os.Symlink(jobDir, filepath.Join(artifactsDir, "job")))
+
+	tt := []struct {
+		rel      string
+		expected string
+	}{
+		{rel: "/reports/creport.json", expected: "reports/creport.json"},
+		{rel: "/report.json", expected: "reports/creport.json"},
+		{rel: "/secret"},
+		{rel: "/outside/secret"},
+		{rel: "/job/job.json"},
+		{rel: "/reports"},
+		{rel: "/missing"},
+	}
+
+	baseDir, err := filepath.EvalSymlinks(artifactsDir)
+	require.NoError(t, err)
+
+	for _, test := range tt {
+		artifactPath, err := resolveArtifactPath(artifactsDir, test.rel)
+		if test.expected == "" {
+			assert.Error(t, err, test.rel)
+			continue
+		}
+
+		require.NoError(t, err, test.rel)
+		assert.Equal(t, filepath.Join(baseDir, test.expected), artifactPath, test.rel)
+	}
+}
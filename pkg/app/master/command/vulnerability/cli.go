@@ -18,6 +18,9 @@ const (
 
 	EpssCmdName      = "epss"
 	EpssCmdNameUsage = "Get EPPS information for the target vulnerabilities"
+
+	ScanCmdName      = "scan"
+	ScanCmdNameUsage = "Find known vulnerabilities in the target image packages using local advisory databases"
 )
 
 const (
@@ -136,6 +139,40 @@ func EpssCommandFlagValues(ctx *cli.Context) (*EpssCommandParams, error) {
 	return values, nil
 }
 
+type ScanCommandParams struct {
+	TargetRef         string    `json:"target,omitempty"`
+	MinifiedTargetRef string    `json:"minified_target,omitempty"`
+	AdvisoryDBPaths   []string  `json:"advisory_db,omitempty"`
+	WithEPSS          bool      `json:"with_epss,omitempty"`
+	EPSSDataPath      string    `json:"epss_data,omitempty"`
+	EPSSDate          time.Time `json:"epss_date,omitempty"`
+}
+
+func ScanCommandFlagValues(ctx *cli.Context) (*ScanCommandParams, error) {
+	values := &ScanCommandParams{
+		TargetRef:         ctx.String(command.FlagTarget),
+		MinifiedTargetRef: ctx.String(FlagMinifiedTarget),
+		AdvisoryDBPaths:   ctx.StringSlice(FlagAdvisoryDB),
+		WithEPSS:          ctx.Bool(FlagWithEPSS),
+		EPSSDataPath:      ctx.String(FlagEPSSData),
+	}
+
+	if len(values.AdvisoryDBPaths) == 0 {
+		return nil, fmt.Errorf("missing advisory database")
+	}
+
+	if dateStr := ctx.String(FlagDate); dateStr != "" {
+		date, err := epss.DateFromString(dateStr)
+		if err != nil {
+			return nil, err
+		}
+
+		values.EPSSDate = date
+	}
+
+	return values, nil
+}
+
 var CLI = &cli.Command{
 	Name:    Name,
 	Aliases: []string{Alias},
@@ -183,5 +220,44 @@ var CLI = &cli.Command{
 				return nil
 			},
 		},
+		{
+			Name:  ScanCmdName,
+			Usage: ScanCmdNameUsage,
+			Flags: []cli.Flag{
+				command.Cflag(command.FlagTarget),
+				cflag(FlagMinifiedTarget),
+				cflag(FlagAdvisoryDB),
+				cflag(FlagWithEPSS),
+				cflag(FlagEPSSData),
+				cflag(FlagDate),
+			},
+			Action: func(ctx *cli.Context) error {
+				gcvalues, ok := command.CLIContextGet(ctx.Context, command.GlobalParams).(*command.GenericParams)
+				if !ok || gcvalues == nil {
+					return command.ErrNoGlobalParams
+				}
+
+				xc := app.NewExecutionContext(
+					fullCmdName(ScanCmdName),
+					gcvalues.QuietCLIMode,
+					gcvalues.OutputFormat)
+
+				cparams, err := ScanCommandFlagValues(ctx)
+				xc.FailOn(err)
+
+				if cparams.TargetRef == "" {
+					if ctx.Args().Len() < 1 {
+						xc.Out.Error("param.target", "missing target")
+						cli.ShowCommandHelp(ctx, ScanCmdName)
+						return nil
+					} else {
+						cparams.TargetRef = ctx.Args().First()
+					}
+				}
+
+				OnScanCommand(xc, gcvalues, cparams)
+				return nil
+			},
+		},
 	},
 }
@@ -47,6 +47,19 @@ const (
 
 	FlagFilterOrderRecords      = "filter-order-records"
 	FlagFilterOrderRecordsUsage = "'order returned records' ESPP list operation filter ('score-desc' | 'score-asc' | 'percentile-desc' | 'percentile-asc')"
+
+	// Scan Flags
+	FlagAdvisoryDB      = "advisory-db"
+	FlagAdvisoryDBUsage = "Local advisory database (OSV JSON file, directory with OSV JSON files or OSV zip archive)"
+
+	FlagMinifiedTarget      = "minified-target"
+	FlagMinifiedTargetUsage = "Minified image to compare with the target image"
+
+	FlagWithEPSS      = "with-epss"
+	FlagWithEPSSUsage = "Enrich the found vulnerabilities with EPSS scores"
+
+	FlagEPSSData      = "epss-data"
+	FlagEPSSDataUsage = "Local EPSS data file (epss_scores-YYYY-MM-DD.csv or .csv.gz) to use instead of the EPSS API"
 )
 
 const (
@@ -133,6 +146,30 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagFilterOrderRecordsUsage,
 		EnvVars: []string{"DSLIM_VULN_EPSS_FILTER_ORDER"},
 	},
+	FlagAdvisoryDB: &cli.StringSliceFlag{
+		Name:    FlagAdvisoryDB,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagAdvisoryDBUsage,
+		EnvVars: []string{"DSLIM_VULN_ADVISORY_DB"},
+	},
+	FlagMinifiedTarget: &cli.StringFlag{
+		Name:    FlagMinifiedTarget,
+		Value:   "",
+		Usage:   FlagMinifiedTargetUsage,
+		EnvVars: []string{"DSLIM_VULN_MINIFIED_TARGET"},
+	},
+	FlagWithEPSS: &cli.BoolFlag{
+		Name:    FlagWithEPSS,
+		Value:   true, //defaults to true
+		Usage:   FlagWithEPSSUsage,
+		EnvVars: []string{"DSLIM_VULN_WITH_EPSS"},
+	},
+	FlagEPSSData: &cli.StringFlag{
+		Name:    FlagEPSSData,
+		Value:   "",
+		Usage:   FlagEPSSDataUsage,
+		EnvVars: []string{"DSLIM_VULN_EPSS_DATA"},
+	},
 }
 
 func cflag(name string) cli.Flag {
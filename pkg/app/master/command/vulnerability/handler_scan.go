@@ -0,0 +1,333 @@
+package vulnerability
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/app"
+	"github.com/slimtoolkit/slim/pkg/app/master/command"
+	"github.com/slimtoolkit/slim/pkg/app/master/version"
+	cmd "github.com/slimtoolkit/slim/pkg/command"
+	"github.com/slimtoolkit/slim/pkg/report"
+	"github.com/slimtoolkit/slim/pkg/sbom"
+	"github.com/slimtoolkit/slim/pkg/util/jsonutil"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/epss"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/epss/client"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/osv"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/scan"
+)
+
+// max number of CVE IDs in one EPSS API lookup call
+const epssLookupBatchSize = 100
+
+const (
+	findingStatusRemoved   = "removed"
+	findingStatusRemaining = "remaining"
+)
+
+// OnScanCommand implements the 'vulnerability scan' command
+func OnScanCommand(
+	xc *app.ExecutionContext,
+	gparams *command.GenericParams,
+	cparams *ScanCommandParams) {
+	cmdName := fullCmdName(ScanCmdName)
+	logger := log.WithFields(log.Fields{
+		"app": appName,
+		"cmd": cmdName,
+		"sub": ScanCmdName})
+
+	viChan := version.CheckAsync(gparams.CheckVersion, gparams.InContainer, gparams.IsDSImage)
+
+	cmdReport := report.NewVulnerabilityCommand(gparams.ReportLocation, gparams.InContainer)
+	cmdReport.State = cmd.StateStarted
+	cmdReport.Operation = ScanCmdName
+
+	xc.Out.State(cmd.StateStarted)
+	xc.Out.Info("params",
+		ovars{
+			"cmd.params": jsonutil.ToString(cparams),
+		})
+
+	client := command.ConnectDockerClient(xc, gparams)
+	if gparams.Debug {
+		version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+	}
+
+	xc.Out.State("advisory.db.load.start")
+	db, err := osv.LoadDB(cparams.AdvisoryDBPaths)
+	xc.FailOn(err)
+	xc.Out.State("advisory.db.load.done",
+		ovars{
+			"advisories": db.Advisories,
+		})
+
+	scanInfo := &report.VulnerabilityScanInfo{
+		Advisories: db.Advisories,
+	}
+
+	xc.Out.State("image.scan.start", ovars{"image": cparams.TargetRef})
+	targetInspector := command.InspectLocalImage(xc, client, cparams.TargetRef)
+	imagePkg := command.LoadLocalImagePackage(xc, client, logger, gparams.StatePath, targetInspector, command.PackageProcessorParams(true))
+	pkgReport := imagePkg.ProcessPackageData()
+
+	scanInfo.Original = scan.Scan(db, pkgReport)
+	scanInfo.Original.Image = targetInspector.ImageRef
+	scanInfo.Original.ImageID = targetInspector.ImageInfo.ID
+	xc.Out.State("image.scan.done",
+		ovars{
+			"image":    cparams.TargetRef,
+			"packages": scanInfo.Original.PackageCount,
+			"os":       scanInfo.Original.OSEcosystem,
+			"findings": len(scanInfo.Original.Findings),
+		})
+
+	if cparams.MinifiedTargetRef != "" {
+		//the minified images usually don't have the package databases,
+		//so the minified image packages are the original image packages
+		//that still have files in the minified image
+		xc.Out.State("image.scan.start", ovars{"image": cparams.MinifiedTargetRef})
+		minifiedInspector := command.InspectLocalImage(xc, client, cparams.MinifiedTargetRef)
+		minifiedPkg := command.LoadLocalImagePackage(xc, client, logger, gparams.StatePath, minifiedInspector, command.PackageProcessorParams(false))
+		imageFiles, links := minifiedPkg.FileSystem()
+		var minifiedReport *sbom.Report
+		if pkgReport != nil {
+			minifiedReport = pkgReport.Minified(imageFiles, links)
+		}
+
+		scanInfo.Minified = scan.Scan(db, minifiedReport)
+		scanInfo.Minified.Image = minifiedInspector.ImageRef
+		scanInfo.Minified.ImageID = minifiedInspector.ImageInfo.ID
+		xc.Out.State("image.scan.done",
+			ovars{
+				"image":    cparams.MinifiedTargetRef,
+				"packages": scanInfo.Minified.PackageCount,
+				"os":       scanInfo.Minified.OSEcosystem,
+				"findings": len(scanInfo.Minified.Findings),
+			})
+	}
+
+	if cparams.EPSSDataPath != "" || cparams.WithEPSS {
+		scores, err := epssScores(gparams, cparams, scanInfo.Original.CVEs())
+		if err != nil {
+			//the EPSS data is optional, so the scan results are still reported
+			xc.Out.Info("epss.error",
+				ovars{
+					"message": err.Error(),
+				})
+		} else {
+			scanInfo.Original.ApplyEPSS(scores)
+			if scanInfo.Minified != nil {
+				scanInfo.Minified.ApplyEPSS(scores)
+			}
+		}
+	}
+
+	if scanInfo.Minified != nil {
+		scanInfo.Comparison = scan.Compare(scanInfo.Original, scanInfo.Minified)
+	}
+
+	cmdReport.Scan = scanInfo
+	showScanResults(xc, scanInfo)
+
+	xc.Out.State(cmd.StateCompleted)
+	cmdReport.State = cmd.StateCompleted
+	xc.Out.State(cmd.StateDone)
+
+	vinfo := <-viChan
+	version.PrintCheckVersion(xc, "", vinfo)
+
+	cmdReport.State = cmd.StateDone
+	if cmdReport.Save() {
+		xc.Out.Info("report",
+			ovars{
+				"file": cmdReport.ReportLocation(),
+			})
+	}
+}
+
+// epssScores returns the EPSS scores for the CVEs
+// (from the EPSS offline data file or from the EPSS API)
+func epssScores(
+	gparams *command.GenericParams,
+	cparams *ScanCommandParams,
+	cveIDs []string) (map[string]*epss.Score, error) {
+	if cparams.EPSSDataPath != "" {
+		return epss.LoadOfflineScores(cparams.EPSSDataPath)
+	}
+
+	scores := map[string]*epss.Score{}
+	epssClient := client.New(client.Options{Debug: gparams.Debug})
+	for start := 0; start < len(cveIDs); start += epssLookupBatchSize {
+		end := start + epssLookupBatchSize
+		if end > len(cveIDs) {
+			end = len(cveIDs)
+		}
+
+		batch, _, err := epssClient.LookupScores(
+			context.Background(),
+			cveIDs[start:end],
+			client.CallOptions{
+				Date:     cparams.EPSSDate,
+				PageSize: uint64(end - start),
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, score := range batch {
+			scores[score.CVE] = score
+		}
+	}
+
+	return scores, nil
+}
+
+func showScanResults(xc *app.ExecutionContext, info *report.VulnerabilityScanInfo) {
+	status := findingStatuses(info.Comparison)
+	if xc.Out.Quiet {
+		if xc.Out.OutputFormat == command.OutputFormatJSON {
+			fmt.Printf("%s\n", jsonutil.ToPretty(info))
+			return
+		}
+
+		printFindingsTable(info.Original.Findings, status)
+		return
+	}
+
+	for idx, finding := range info.Original.Findings {
+		fields := ovars{
+			"index":    idx,
+			"id":       finding.ID,
+			"cves":     strings.Join(finding.CVEs, ","),
+			"severity": finding.Severity,
+			"package":  finding.PackageName,
+			"version":  finding.PackageVersion,
+			"type":     finding.PackageType,
+			"fixed":    finding.FixedVersion,
+		}
+
+		if finding.CVSSScore > 0 {
+			fields["cvss"] = finding.CVSSScore
+		}
+
+		if finding.EPSS > 0 {
+			fields["epss"] = finding.EPSS
+			fields["epss.percentile"] = finding.EPSSPercentile
+		}
+
+		if value, found := status[finding.Key()]; found {
+			fields["status"] = value
+		}
+
+		xc.Out.Info("vulnerability", fields)
+	}
+
+	summary := ovars{
+		"image":      info.Original.Image,
+		"packages":   info.Original.PackageCount,
+		"findings":   len(info.Original.Findings),
+		"advisories": info.Advisories,
+	}
+
+	for severity, count := range info.Original.SeverityCounts() {
+		summary["severity."+strings.ToLower(severity)] = count
+	}
+
+	if info.Minified != nil && info.Comparison != nil {
+		summary["minified.image"] = info.Minified.Image
+		summary["minified.packages"] = info.Minified.PackageCount
+		summary["minified.findings"] = len(info.Minified.Findings)
+		summary["removed.findings"] = len(info.Comparison.Removed)
+		summary["removed.cves"] = len(uniqueCVEs(info.Comparison.Removed, info.Comparison.Remaining))
+	}
+
+	xc.Out.Info("vulnerability.summary", summary)
+}
+
+// findingStatuses returns the 'removed' or 'remaining' status for each original image finding
+func findingStatuses(comparison *scan.Comparison) map[string]string {
+	status := map[string]string{}
+	if comparison == nil {
+		return status
+	}
+
+	for _, finding := range comparison.Removed {
+		status[finding.Key()] = findingStatusRemoved
+	}
+
+	for _, finding := range comparison.Remaining {
+		status[finding.Key()] = findingStatusRemaining
+	}
+
+	return status
+}
+
+// uniqueCVEs returns the CVE IDs in the findings that are not in the excluded findings
+// (a CVE is removed only if none of the remaining findings have it)
+func uniqueCVEs(findings []*scan.Finding, excluded []*scan.Finding) []string {
+	excludedCVEs := map[string]struct{}{}
+	for _, finding := range excluded {
+		for _, cve := range finding.CVEs {
+			excludedCVEs[cve] = struct{}{}
+		}
+	}
+
+	var result []string
+	seen := map[string]struct{}{}
+	for _, finding := range findings {
+		for _, cve := range finding.CVEs {
+			if _, found := excludedCVEs[cve]; found {
+				continue
+			}
+
+			if _, found := seen[cve]; found {
+				continue
+			}
+
+			seen[cve] = struct{}{}
+			result = append(result, cve)
+		}
+	}
+
+	return result
+}
+
+func printFindingsTable(findings []*scan.Finding, status map[string]string) {
+	tw := table.NewWriter()
+	header := table.Row{"ID", "CVE", "Severity", "Package", "Version", "Fixed", "EPSS"}
+	if len(status) > 0 {
+		header = append(header, "Status")
+	}
+
+	tw.AppendHeader(header)
+	for _, finding := range findings {
+		var epssValue string
+		if finding.EPSS > 0 {
+			epssValue = fmt.Sprintf("%.5f", finding.EPSS)
+		}
+
+		row := table.Row{
+			finding.ID,
+			strings.Join(finding.CVEs, ","),
+			finding.Severity,
+			finding.PackageName,
+			finding.PackageVersion,
+			finding.FixedVersion,
+			epssValue,
+		}
+
+		if len(status) > 0 {
+			row = append(row, status[finding.Key()])
+		}
+
+		tw.AppendRow(row)
+	}
+
+	tw.SetStyle(table.StyleLight)
+	tw.Style().Options.DrawBorder = false
+	fmt.Printf("%s\n", tw.Render())
+}
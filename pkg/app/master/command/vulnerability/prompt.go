@@ -16,6 +16,18 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(FlagCVE), Description: FlagCVEUsage},
 		//including sub-commands here too
 		{Text: EpssCmdName, Description: EpssCmdNameUsage},
+		{Text: ScanCmdName, Description: ScanCmdNameUsage},
+		{Text: command.FullFlagName(command.FlagTarget), Description: command.FlagTargetUsage},
+		{Text: command.FullFlagName(FlagMinifiedTarget), Description: FlagMinifiedTargetUsage},
+		{Text: command.FullFlagName(FlagAdvisoryDB), Description: FlagAdvisoryDBUsage},
+		{Text: command.FullFlagName(FlagWithEPSS), Description: FlagWithEPSSUsage},
+		{Text: command.FullFlagName(FlagEPSSData), Description: FlagEPSSDataUsage},
+	},
+	Values: map[string]command.CompleteValue{
+		command.FullFlagName(command.FlagTarget): command.CompleteImage,
+		command.FullFlagName(FlagMinifiedTarget): command.CompleteImage,
+		command.FullFlagName(FlagAdvisoryDB):     command.CompleteFile,
+		command.FullFlagName(FlagWithEPSS):       command.CompleteTBool,
+		command.FullFlagName(FlagEPSSData):       command.CompleteFile,
 	},
-	Values: map[string]command.CompleteValue{},
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app"
 	"github.com/slimtoolkit/slim/pkg/app/master/command"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
+	"github.com/slimtoolkit/slim/pkg/sbom"
+	"github.com/slimtoolkit/slim/pkg/secretdiscover"
 )
 
 const (
@@ -38,10 +40,15 @@ type CommandParams struct {
 	DetectScheduledTasks *DetectOpParam `json:"detect_scheduled_tasks,omitempty"`
 	DetectServices       *DetectOpParam `json:"detect_services,omitempty"`
 	DetectSystemHooks    *DetectOpParam `json:"detect_system_hooks,omitempty"`
+	DetectSecrets        *DetectOpParam `json:"detect_secrets,omitempty"`
+
+	secretScanner *secretdiscover.Scanner
 
 	//todo: migrate simple bool param to DetectOpParam
 	DetectAllCertFiles   bool `json:"detect_all_cert_files,omitempty"`
 	DetectAllCertPKFiles bool `json:"detect_all_cert_pks,omitempty"`
+
+	SBOMFormats []string `json:"sbom_formats,omitempty"`
 }
 
 var CLI = &cli.Command{
@@ -92,6 +99,9 @@ var CLI = &cli.Command{
 		cflag(FlagDetectSystemHooks),
 		cflag(FlagDetectSystemHooksParam),
 		cflag(FlagDetectSystemHooksDumpRaw),
+		cflag(FlagDetectSecrets),
+		cflag(FlagDetectSecretsParam),
+		command.Cflag(command.FlagSBOM),
 		command.Cflag(command.FlagRemoveFileArtifacts),
 	},
 	Action: func(ctx *cli.Context) error {
@@ -179,6 +189,42 @@ var CLI = &cli.Command{
 			xc.Exit(-1)
 		}
 
+		//no raw data dumps for the detected secrets
+		detectSecrets, err := getDetectOpParam(ctx,
+			FlagDetectSecrets,
+			FlagDetectSecretsParam,
+			"",
+			"",
+			detectSecretsOpParamKeys)
+		if err != nil {
+			xc.Out.Error("param.detect_secrets", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
+		secretScanner, err := newSecretScanner(detectSecrets)
+		if err != nil {
+			xc.Out.Error("param.detect_secrets", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
+		sbomFormats, err := sbom.ParseFormats(ctx.StringSlice(command.FlagSBOM))
+		if err != nil {
+			xc.Out.Error("param.sbom", err.Error())
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+				})
+			xc.Exit(-1)
+		}
+
 		//todo:
 		//1. migrate all param fields to CommandParams
 		//2. load command params from file if command.FlagCommandParamsFile is provided
@@ -189,6 +235,9 @@ var CLI = &cli.Command{
 			DetectScheduledTasks: detectScheduledTasks,
 			DetectServices:       detectServices,
 			DetectSystemHooks:    detectSystemHooks,
+			DetectSecrets:        detectSecrets,
+			secretScanner:        secretScanner,
+			SBOMFormats:          sbomFormats,
 		}
 
 		xdArtifactsPath := ctx.String(FlagExportAllDataArtifacts)
@@ -680,10 +729,17 @@ func getDetectOpParam(
 			param.IsConsoleOut = true
 			return &param, nil
 		case "no":
+			param.DumpRaw = false
 			return &param, nil
 		}
 
 		parts := strings.SplitN(dumpRaw, ":", 2)
+		if len(parts) == 1 {
+			//tar archive file path
+			param.OutputPath = dumpRaw
+			return &param, nil
+		}
+
 		if parts[1] == "" {
 			param.IsConsoleOut = true
 			return &param, nil
@@ -692,6 +748,7 @@ func getDetectOpParam(
 		switch parts[0] {
 		case "dir":
 			param.IsDirOut = true
+			param.OutputPath = parts[1]
 		default: //"file"
 			param.OutputPath = parts[1]
 		}
@@ -700,6 +757,11 @@ func getDetectOpParam(
 	return &param, nil
 }
 
+const (
+	secretsParamRulesFile   = "rules_file"
+	secretsParamMaxFileSize = "max_file_size"
+)
+
 const (
 	detectIdentitiesDumpRawDefault     = "./raw-identities.tar"
 	detectScheduledTasksDumpRawDefault = "./raw-scheduled-tasks.tar"
@@ -712,4 +774,8 @@ var (
 	detectScheduledTasksOpParamKeys = map[string]struct{}{} //no keys yet
 	detectServicesOpParamKeys       = map[string]struct{}{} //no keys yet
 	detectSystemHooksOpParamKeys    = map[string]struct{}{} //no keys yet
+	detectSecretsOpParamKeys        = map[string]struct{}{
+		secretsParamRulesFile:   {},
+		secretsParamMaxFileSize: {},
+	}
 )
@@ -0,0 +1,284 @@
+package xray
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/slimtoolkit/slim/pkg/app"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
+	"github.com/slimtoolkit/slim/pkg/secretdiscover"
+	"github.com/slimtoolkit/slim/pkg/syshook"
+	"github.com/slimtoolkit/slim/pkg/sysservice"
+	"github.com/slimtoolkit/slim/pkg/systask"
+	"github.com/slimtoolkit/slim/pkg/util/errutil"
+)
+
+func (ref *DetectOpParam) processorParam() *dockerimage.DetectOpParam {
+	if ref == nil {
+		return nil
+	}
+
+	return &dockerimage.DetectOpParam{
+		Enabled:      ref.Enabled,
+		DumpRaw:      ref.DumpRaw,
+		IsConsoleOut: ref.IsConsoleOut,
+		IsDirOut:     ref.IsDirOut,
+		OutputPath:   ref.OutputPath,
+		InputParams:  ref.InputParams,
+	}
+}
+
+func printScheduledTasks(xc *app.ExecutionContext, report *systask.Report) {
+	if report == nil {
+		return
+	}
+
+	xc.Out.Info("image.scheduled_tasks.stats",
+		ovars{
+			"task_count":   len(report.Tasks),
+			"source_count": len(report.Sources),
+		})
+
+	for _, task := range report.Tasks {
+		xc.Out.Info("image.scheduled_tasks.task",
+			ovars{
+				"source":   task.Source,
+				"file":     task.FilePath,
+				"schedule": task.Schedule,
+				"user":     task.User,
+				"command":  task.Command,
+				"enabled":  task.Enabled,
+			})
+	}
+}
+
+func printServices(xc *app.ExecutionContext, report *sysservice.Report) {
+	if report == nil {
+		return
+	}
+
+	var enabledCount int
+	for _, service := range report.Services {
+		if service.Enabled {
+			enabledCount++
+		}
+	}
+
+	xc.Out.Info("image.services.stats",
+		ovars{
+			"service_count": len(report.Services),
+			"enabled_count": enabledCount,
+			"source_count":  len(report.Sources),
+		})
+
+	for _, service := range report.Services {
+		xc.Out.Info("image.services.service",
+			ovars{
+				"type":    service.Type,
+				"name":    service.Name,
+				"file":    service.FilePath,
+				"command": service.Command,
+				"user":    service.User,
+				"enabled": service.Enabled,
+				"masked":  service.Masked,
+			})
+	}
+}
+
+func printSystemHooks(xc *app.ExecutionContext, report *syshook.Report) {
+	if report == nil {
+		return
+	}
+
+	xc.Out.Info("image.system_hooks.stats",
+		ovars{
+			"hook_count":   len(report.Hooks),
+			"source_count": len(report.Sources),
+		})
+
+	for _, hook := range report.Hooks {
+		xc.Out.Info("image.system_hooks.hook",
+			ovars{
+				"type":    hook.Type,
+				"file":    hook.FilePath,
+				"line":    hook.Line,
+				"target":  hook.Target,
+				"command": hook.Command,
+			})
+	}
+}
+
+// newSecretScanner creates the secret scanner with the built-in rules
+// and the rules from the rule file (nil if the secret detection is disabled)
+func newSecretScanner(param *DetectOpParam) (*secretdiscover.Scanner, error) {
+	if param == nil || !param.Enabled {
+		return nil, nil
+	}
+
+	var extra []secretdiscover.Detector
+	if rulesFile := param.InputParams[secretsParamRulesFile]; rulesFile != "" {
+		rules, err := secretdiscover.LoadRules([]string{rulesFile})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rule := range rules {
+			extra = append(extra, rule)
+		}
+	}
+
+	scanner, err := secretdiscover.NewScanner(extra...)
+	if err != nil {
+		return nil, err
+	}
+
+	if value := param.InputParams[secretsParamMaxFileSize]; value != "" {
+		maxSize, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxSize <= 0 {
+			return nil, fmt.Errorf("invalid %s value - %s", secretsParamMaxFileSize, value)
+		}
+
+		scanner.MaxFileSize = maxSize
+	}
+
+	return scanner, nil
+}
+
+func printSecrets(xc *app.ExecutionContext, report *secretdiscover.Report) {
+	if report == nil {
+		return
+	}
+
+	xc.Out.Info("image.secrets.stats",
+		ovars{
+			"count":       len(report.Findings),
+			"active":      report.Stats[secretdiscover.StatusActive],
+			"deleted":     report.Stats[secretdiscover.StatusDeleted],
+			"overwritten": report.Stats[secretdiscover.StatusOverwritten],
+		})
+
+	for _, finding := range report.Findings {
+		params := ovars{
+			"rule":   finding.RuleID,
+			"source": finding.Source,
+			"status": finding.Status,
+			"layer":  finding.LayerIndex,
+			"match":  finding.Context,
+		}
+
+		if finding.FilePath != "" {
+			params["file"] = finding.FilePath
+			params["line"] = finding.Line
+		}
+
+		if finding.HistoryIndex != nil {
+			params["history"] = *finding.HistoryIndex
+		}
+
+		xc.Out.Info("image.secrets.finding", params)
+	}
+}
+
+// dumpDetectorRawData dumps the detector source files (and symlinks)
+// from the image filesystem to the console, a directory or a tar archive
+func dumpDetectorRawData(
+	xc *app.ExecutionContext,
+	name string,
+	param *DetectOpParam,
+	data *dockerimage.FileDataSet,
+	layerOrder []string) {
+	if param == nil || !param.Enabled || !param.DumpRaw || data == nil {
+		return
+	}
+
+	files, links := data.Files(layerOrder)
+	var paths []string
+	for fpath := range files {
+		paths = append(paths, fpath)
+	}
+
+	sort.Strings(paths)
+
+	var linkPaths []string
+	for fpath := range links {
+		linkPaths = append(linkPaths, fpath)
+	}
+
+	sort.Strings(linkPaths)
+
+	switch {
+	case param.IsConsoleOut:
+		for _, fpath := range paths {
+			xc.Out.LogDump("image.detector.raw."+name, string(files[fpath]),
+				ovars{
+					"file": fpath,
+				})
+		}
+
+		for _, fpath := range linkPaths {
+			xc.Out.Info("image.detector.raw."+name+".link",
+				ovars{
+					"file":   fpath,
+					"target": links[fpath],
+				})
+		}
+
+		return
+	case param.OutputPath == "":
+		return
+	case param.IsDirOut:
+		for _, fpath := range paths {
+			dstPath := filepath.Join(param.OutputPath, fpath)
+			errutil.FailOn(os.MkdirAll(filepath.Dir(dstPath), 0755))
+			errutil.FailOn(os.WriteFile(dstPath, files[fpath], 0644))
+		}
+
+		for _, fpath := range linkPaths {
+			dstPath := filepath.Join(param.OutputPath, fpath)
+			errutil.FailOn(os.MkdirAll(filepath.Dir(dstPath), 0755))
+			_ = os.Remove(dstPath)
+			errutil.FailOn(os.Symlink(links[fpath], dstPath))
+		}
+	default:
+		archive, err := dockerimage.NewTarWriter(param.OutputPath)
+		errutil.FailOn(err)
+
+		for _, fpath := range paths {
+			header := &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     strings.TrimPrefix(fpath, "/"),
+				Mode:     0644,
+				Size:     int64(len(files[fpath])),
+			}
+
+			errutil.FailOn(archive.Writer.WriteHeader(header))
+			_, err = archive.Writer.Write(files[fpath])
+			errutil.FailOn(err)
+		}
+
+		for _, fpath := range linkPaths {
+			header := &tar.Header{
+				Typeflag: tar.TypeSymlink,
+				Name:     strings.TrimPrefix(fpath, "/"),
+				Linkname: links[fpath],
+				Mode:     0777,
+			}
+
+			errutil.FailOn(archive.Writer.WriteHeader(header))
+		}
+
+		errutil.FailOn(archive.Close())
+	}
+
+	xc.Out.Info("image.detector.raw."+name,
+		ovars{
+			"files":  len(paths),
+			"links":  len(linkPaths),
+			"output": param.OutputPath,
+		})
+}
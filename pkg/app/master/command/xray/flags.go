@@ -47,6 +47,9 @@ const (
 	FlagDetectSystemHooks        = "detect-system-hooks"
 	FlagDetectSystemHooksParam   = "detect-system-hooks-param"
 	FlagDetectSystemHooksDumpRaw = "detect-system-hooks-dump-raw"
+
+	FlagDetectSecrets      = "detect-secrets"
+	FlagDetectSecretsParam = "detect-secrets-param"
 )
 
 // Xray command flag usage info
@@ -80,17 +83,20 @@ const (
 	FlagDetectIdentitiesParamUsage   = "Input parameters for system identities detection"
 	FlagDetectIdentitiesDumpRawUsage = "Raw data dump options for system identities detection (values: no, console, directory or a tar archive file path where setting value to `.` defaults tar file path to `./raw-identities.tar`"
 
-	FlagDetectScheduledTasksUsage        = "Detect scheduled tasks and their properties"
+	FlagDetectScheduledTasksUsage        = "Detect scheduled tasks (crontabs, periodic cron scripts, anacron jobs and systemd timers) and their properties"
 	FlagDetectScheduledTasksParamUsage   = "Input parameters for scheduled tasks detection"
-	FlagDetectScheduledTasksDumpRawUsage = "Raw data dump options for scheduled tasks detection (values: `no`, `console`, directory or a tar archive file path where setting value to `.` defaults tar file path to `./raw-scheduled-tasks.tar`"
+	FlagDetectScheduledTasksDumpRawUsage = "Raw data dump options for scheduled tasks detection (values: `no`, `console`, dir:<directory> or a tar archive file path where setting value to `.` defaults tar file path to `./raw-scheduled-tasks.tar`"
 
-	FlagDetectServicesUsage        = "Detect services and their properties"
+	FlagDetectServicesUsage        = "Detect services (systemd units, init.d scripts, supervisord programs, s6 and runit services) and their properties"
 	FlagDetectServicesParamUsage   = "Input parameters for services detection"
-	FlagDetectServicesDumpRawUsage = "Raw data dump options for services detection (values: no, console, directory or a tar archive file path where setting value to `.` defaults tar file path to `./raw-services.tar`"
+	FlagDetectServicesDumpRawUsage = "Raw data dump options for services detection (values: no, console, dir:<directory> or a tar archive file path where setting value to `.` defaults tar file path to `./raw-services.tar`"
 
-	FlagDetectSystemHooksUsage        = "Detect system hooks and their properties"
+	FlagDetectSystemHooksUsage        = "Detect system hooks (shell profile scripts, ld.so.preload and LD_PRELOAD libraries, udev rule programs and PAM modules) and their properties"
 	FlagDetectSystemHooksParamUsage   = "Input parameters for system hooks detection"
-	FlagDetectSystemHooksDumpRawUsage = "Raw data dump options for system hooks detection (values: no, console, directory or a tar archive file path where setting value to `.` defaults tar file path to `./raw-system-hooks.tar`"
+	FlagDetectSystemHooksDumpRawUsage = "Raw data dump options for system hooks detection (values: no, console, dir:<directory> or a tar archive file path where setting value to `.` defaults tar file path to `./raw-system-hooks.tar`"
+
+	FlagDetectSecretsUsage      = "Detect secrets and credentials (API tokens, cloud credentials, registry auths, .env files, secrets in ENV/ARG history) in all image layers"
+	FlagDetectSecretsParamUsage = "Input parameters for secrets detection (keys: rules_file - extra secret rule file, max_file_size - max size of the scanned files in bytes)"
 )
 
 var Flags = map[string]cli.Flag{
@@ -301,6 +307,18 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagDetectSystemHooksDumpRawUsage,
 		EnvVars: []string{"DSLIM_XRAY_DETECT_SYSTEM_HOOKS_DUMP_RAW"},
 	},
+
+	FlagDetectSecrets: &cli.BoolFlag{
+		Name:    FlagDetectSecrets,
+		Usage:   FlagDetectSecretsUsage,
+		EnvVars: []string{"DSLIM_XRAY_DETECT_SECRETS"},
+	},
+	FlagDetectSecretsParam: &cli.StringSliceFlag{
+		Name:    FlagDetectSecretsParam,
+		Value:   cli.NewStringSlice(),
+		Usage:   FlagDetectSecretsParamUsage,
+		EnvVars: []string{"DSLIM_XRAY_DETECT_SECRETS_PARAM"},
+	},
 }
 
 func cflag(name string) cli.Flag {
@@ -9,6 +9,7 @@ import (
 
 	//"github.com/bmatcuk/doublestar/v3"
 	"github.com/dustin/go-humanize"
+	dockerapi "github.com/fsouza/go-dockerclient"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slimtoolkit/slim/pkg/app"
@@ -16,11 +17,13 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/image"
 	"github.com/slimtoolkit/slim/pkg/app/master/version"
 	cmd "github.com/slimtoolkit/slim/pkg/command"
+	"github.com/slimtoolkit/slim/pkg/crt/crtclient"
 	"github.com/slimtoolkit/slim/pkg/docker/buildpackinfo"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerutil"
 	"github.com/slimtoolkit/slim/pkg/report"
+	"github.com/slimtoolkit/slim/pkg/sbom"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 	v "github.com/slimtoolkit/slim/pkg/version"
@@ -157,7 +160,8 @@ func OnCommand(
 			"rm-file-artifacts":  doRmFileArtifacts,
 		})
 
-	client, err := dockerclient.New(gparams.ClientConfig)
+	//xray needs only the image operations, so it works with all runtime engines
+	client, err := crtclient.New(gparams.ClientConfig)
 	if err == dockerclient.ErrNoDockerInfo {
 		exitMsg := "missing Docker connection info"
 		if gparams.InContainer && gparams.IsDSImage {
@@ -178,7 +182,12 @@ func OnCommand(
 	errutil.FailOn(err)
 
 	if gparams.Debug {
-		version.Print(xc, cmdName, logger, client, false, gparams.InContainer, gparams.IsDSImage)
+		if dclient, ok := client.(*dockerapi.Client); ok {
+			version.Print(xc, cmdName, logger, dclient, false, gparams.InContainer, gparams.IsDSImage)
+		} else {
+			//the native runtime engine API clients don't provide the Docker engine info
+			version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+		}
 	}
 
 	imageInspector, err := image.NewInspector(client, targetRef)
@@ -382,16 +391,14 @@ func OnCommand(
 	}
 
 	pp := &dockerimage.ProcessorParams{
-		DetectIdentities: &dockerimage.DetectOpParam{
-			Enabled:      cparams.DetectIdentities.Enabled,
-			DumpRaw:      cparams.DetectIdentities.DumpRaw,
-			IsConsoleOut: cparams.DetectIdentities.IsConsoleOut,
-			IsDirOut:     cparams.DetectIdentities.IsDirOut,
-			OutputPath:   cparams.DetectIdentities.OutputPath,
-			InputParams:  cparams.DetectIdentities.InputParams,
-		},
+		DetectIdentities:     cparams.DetectIdentities.processorParam(),
+		DetectScheduledTasks: cparams.DetectScheduledTasks.processorParam(),
+		DetectServices:       cparams.DetectServices.processorParam(),
+		DetectSystemHooks:    cparams.DetectSystemHooks.processorParam(),
 		DetectAllCertFiles:   cparams.DetectAllCertFiles,
 		DetectAllCertPKFiles: cparams.DetectAllCertPKFiles,
+		DetectPackages:       len(cparams.SBOMFormats) > 0,
+		SecretScanner:        cparams.secretScanner,
 	}
 
 	xc.Out.Info("image.data.inspection.process.image.start")
@@ -552,6 +559,30 @@ func OnCommand(
 
 	cmdReport.ImageReport.BuildInfo = imagePkg.Config.BuildInfoDecoded
 
+	dumpDetectorRawData(xc, "scheduled_tasks", cparams.DetectScheduledTasks, imagePkg.ScheduledTaskData, imagePkg.LayerOrder())
+	dumpDetectorRawData(xc, "services", cparams.DetectServices, imagePkg.ServiceData, imagePkg.LayerOrder())
+	dumpDetectorRawData(xc, "system_hooks", cparams.DetectSystemHooks, imagePkg.SystemHookData, imagePkg.LayerOrder())
+
+	if len(cparams.SBOMFormats) > 0 {
+		pkgReport := imagePkg.ProcessPackageData()
+		sbomFiles, err := pkgReport.SaveDocuments(
+			artifactLocation,
+			cparams.SBOMFormats,
+			false,
+			sbom.DocumentInfo{
+				ImageName: targetRef,
+				ImageID:   imageInspector.ImageInfo.ID,
+			})
+		errutil.FailOn(err)
+
+		cmdReport.SBOMFiles = sbomFiles
+		xc.Out.Info("image.sbom",
+			ovars{
+				"packages": len(pkgReport.Packages),
+				"files":    strings.Join(sbomFiles, ","),
+			})
+	}
+
 	if (cmdReport.SourceImage.BaseImageDigest == "" || cmdReport.SourceImage.BaseImageName == "") &&
 		cmdReport.ImageReport.BuildInfo != nil &&
 		len(cmdReport.ImageReport.BuildInfo.Sources) > 0 {
@@ -596,6 +627,13 @@ func OnCommand(
 			"artifacts.dockerfile.original": "Dockerfile.fat",
 		})
 
+	for _, sbomFile := range cmdReport.SBOMFiles {
+		xc.Out.Info("results",
+			ovars{
+				"artifacts.sbom": sbomFile,
+			})
+	}
+
 	vinfo := <-viChan
 	version.PrintCheckVersion(xc, "", vinfo)
 
@@ -611,6 +649,9 @@ func OnCommand(
 		var filesToExport []string
 		filesToExport = append(filesToExport, cmdReport.ReportLocation())
 		filesToExport = append(filesToExport, filepath.Join(cmdReport.ArtifactLocation, fatDockerfileName))
+		for _, sbomFile := range cmdReport.SBOMFiles {
+			filesToExport = append(filesToExport, filepath.Join(cmdReport.ArtifactLocation, sbomFile))
+		}
 		if utf8Detector.DumpArchive != "" {
 			filesToExport = append(filesToExport, utf8Detector.DumpArchive)
 		}
@@ -703,6 +744,26 @@ func printImagePackage(
 		}
 	}
 
+	if cparams.DetectScheduledTasks.Enabled {
+		cmdReport.ImageReport.ScheduledTasks = pkg.ProcessScheduledTaskData()
+		printScheduledTasks(xc, cmdReport.ImageReport.ScheduledTasks)
+	}
+
+	if cparams.DetectServices.Enabled {
+		cmdReport.ImageReport.Services = pkg.ProcessServiceData()
+		printServices(xc, cmdReport.ImageReport.Services)
+	}
+
+	if cparams.DetectSystemHooks.Enabled {
+		cmdReport.ImageReport.SystemHooks = pkg.ProcessSystemHookData()
+		printSystemHooks(xc, cmdReport.ImageReport.SystemHooks)
+	}
+
+	if cparams.secretScanner != nil {
+		cmdReport.ImageReport.Secrets = pkg.ProcessSecretData(cparams.secretScanner)
+		printSecrets(xc, cmdReport.ImageReport.Secrets)
+	}
+
 	for k := range pkg.Certs.Bundles {
 		cmdReport.ImageReport.Certs.Bundles =
 			append(cmdReport.ImageReport.Certs.Bundles, k)
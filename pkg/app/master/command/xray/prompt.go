@@ -45,7 +45,10 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		{Text: command.FullFlagName(FlagDetectIdentities), Description: FlagDetectIdentitiesUsage},
 		{Text: command.FullFlagName(FlagDetectIdentitiesParam), Description: FlagDetectIdentitiesParamUsage},
 		{Text: command.FullFlagName(FlagDetectIdentitiesDumpRaw), Description: FlagDetectIdentitiesDumpRawUsage},
+		{Text: command.FullFlagName(FlagDetectSecrets), Description: FlagDetectSecretsUsage},
+		{Text: command.FullFlagName(FlagDetectSecretsParam), Description: FlagDetectSecretsParamUsage},
 		{Text: command.FullFlagName(FlagExportAllDataArtifacts), Description: FlagExportAllDataArtifactsUsage},
+		{Text: command.FullFlagName(command.FlagSBOM), Description: command.FlagSBOMUsage},
 		{Text: command.FullFlagName(command.FlagRemoveFileArtifacts), Description: command.FlagRemoveFileArtifactsUsage},
 	},
 	Values: map[string]command.CompleteValue{
@@ -66,6 +69,8 @@ var CommandFlagSuggestions = &command.FlagSuggestions{
 		command.FullFlagName(FlagDetectAllCertFiles):          command.CompleteBool,
 		command.FullFlagName(FlagDetectAllCertPKFiles):        command.CompleteBool,
 		command.FullFlagName(FlagDetectIdentities):            command.CompleteTBool,
+		command.FullFlagName(FlagDetectSecrets):               command.CompleteBool,
+		command.FullFlagName(command.FlagSBOM):                command.CompleteSBOMFormat,
 		command.FullFlagName(command.FlagRemoveFileArtifacts): command.CompleteBool,
 	},
 }
@@ -87,6 +87,7 @@ type ImageNewInstructions struct {
 	Cmd                []string
 	ClearCmd           bool
 	Workdir            string
+	User               string
 	Env                []string
 	Volumes            map[string]struct{}
 	ExposedPorts       map[docker.Port]struct{}
@@ -141,6 +142,8 @@ const (
 	ProtoHTTP2C = "http2c"
 	ProtoWS     = "ws"
 	ProtoWSS    = "wss"
+	ProtoGRPC   = "grpc"
+	ProtoGRPCS  = "grpcs"
 )
 
 func IsProto(value string) bool {
@@ -150,13 +153,58 @@ func IsProto(value string) bool {
 		ProtoHTTP2,
 		ProtoHTTP2C,
 		ProtoWS,
-		ProtoWSS:
+		ProtoWSS,
+		ProtoGRPC,
+		ProtoGRPCS:
 		return true
 	default:
 		return false
 	}
 }
 
+// TCP probe protocols
+const (
+	TCPProtoRedis      = "redis"
+	TCPProtoPostgreSQL = "postgresql"
+	TCPProtoMySQL      = "mysql"
+	TCPProtoMongoDB    = "mongodb"
+	TCPProtoMemcached  = "memcached"
+	TCPProtoNATS       = "nats"
+	TCPProtoGeneric    = "tcp"
+)
+
+// TCPProbeDefaultProtocols has the protocols for the well-known ports
+// (used when the TCP probe port doesn't have an explicit protocol)
+var TCPProbeDefaultProtocols = map[uint16]string{
+	6379:  TCPProtoRedis,
+	5432:  TCPProtoPostgreSQL,
+	3306:  TCPProtoMySQL,
+	27017: TCPProtoMongoDB,
+	11211: TCPProtoMemcached,
+	4222:  TCPProtoNATS,
+}
+
+func IsTCPProbeProto(value string) bool {
+	switch value {
+	case TCPProtoRedis,
+		TCPProtoPostgreSQL,
+		TCPProtoMySQL,
+		TCPProtoMongoDB,
+		TCPProtoMemcached,
+		TCPProtoNATS,
+		TCPProtoGeneric:
+		return true
+	default:
+		return false
+	}
+}
+
+// TCPProbeTarget is a native protocol probe target port
+type TCPProbeTarget struct {
+	Protocol string
+	Port     uint16
+}
+
 // HTTPProbeCmd provides the HTTP probe parameters
 type HTTPProbeCmd struct {
 	Method   string   `json:"method"`
@@ -171,6 +219,47 @@ type HTTPProbeCmd struct {
 	Crawl    bool     `json:"crawl"`
 
 	FastCGI *FastCGIProbeWrapperConfig `json:"fastcgi,omitempty"`
+	GRPC    *GRPCProbeConfig           `json:"grpc,omitempty"`
+	Expect  *HTTPProbeExpectation      `json:"expect,omitempty"`
+}
+
+// HTTPProbeExpectation provides the expected HTTP probe command response values
+// (the failed expectations are reported for each probe call; for the gRPC commands
+// the status codes are the gRPC status codes, the headers are the response metadata
+// and the body is the first response message decoded to JSON)
+type HTTPProbeExpectation struct {
+	// The expected status codes (any of them).
+	Status []int `json:"status,omitempty"`
+
+	// The response header value regular expressions keyed by the header name.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// The expected JSON response body values keyed by their paths ('$.data.items[0].id' or 'data.items.0.id').
+	// The null values only check that the path exists.
+	JSON map[string]interface{} `json:"json,omitempty"`
+
+	// The response body regular expression.
+	BodyRegex string `json:"body_regex,omitempty"`
+}
+
+// GRPCProbeConfig provides the gRPC probe parameters
+// (used with the 'grpc' and 'grpcs' protocols; the command resource selects
+// the probed services: '/' for all services, '/package.Service' or '/package.Service/Method')
+type GRPCProbeConfig struct {
+	// DescriptorSetFile is a FileDescriptorSet file with the service descriptors
+	// (e.g., from 'protoc --include_imports --descriptor_set_out').
+	// The server reflection API is used if it's not set.
+	DescriptorSetFile string `json:"descriptor_set_file,omitempty"`
+
+	// Example request messages (JSON objects) keyed by the method name ('package.Service/Method').
+	// The methods without example messages are called with the default (empty) messages.
+	Messages map[string]map[string]interface{} `json:"messages,omitempty"`
+
+	// The duration used to set a deadline for the unary calls.
+	CallTimeout time.Duration `json:"call_timeout,omitempty"`
+
+	// The duration used to keep the server streams open.
+	StreamTimeout time.Duration `json:"stream_timeout,omitempty"`
 }
 
 // FastCGI permits fine-grained configuration of the fastcgi RoundTripper.
@@ -205,6 +294,8 @@ type HTTPProbeCmds struct {
 
 // DockerClient provides Docker client parameters
 type DockerClient struct {
+	//Engine is the container runtime engine (docker, podman or containerd)
+	Engine      string
 	UseTLS      bool
 	VerifyTLS   bool
 	TLSCertPath string
@@ -250,6 +341,17 @@ type HTTPProbeOptions struct {
 
 	APISpecs     []string
 	APISpecFiles []string
+	//APISpecCredentials has the credentials for the API spec security schemes
+	//(keyed by the security scheme name or by the 'bearer', 'basic' and 'apikey' scheme types)
+	APISpecCredentials map[string]string
+
+	GraphQL          bool
+	GraphQLEndpoints []string
+	GraphQLMutations bool
+
+	//TCPProbes has the ports probed with the native protocol probes
+	//(these ports are not probed with the HTTP probe commands)
+	TCPProbes []TCPProbeTarget
 
 	ProxyEndpoint string
 	ProxyPort     int
@@ -232,6 +232,12 @@ func (ref *Execution) Start() error {
 
 		if len(ref.options.PublishPorts) > 0 {
 			containerOptions.HostConfig.PortBindings = ref.options.PublishPorts
+			//the published ports need to be exposed too
+			//(they might not be exposed in the image)
+			containerOptions.Config.ExposedPorts = map[dockerapi.Port]struct{}{}
+			for port := range ref.options.PublishPorts {
+				containerOptions.Config.ExposedPorts[port] = struct{}{}
+			}
 		}
 	}
 
@@ -20,7 +20,9 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/ipc"
 	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/sensor"
 	"github.com/slimtoolkit/slim/pkg/app/master/security/apparmor"
+	"github.com/slimtoolkit/slim/pkg/app/master/security/netpolicy"
 	"github.com/slimtoolkit/slim/pkg/app/master/security/seccomp"
+	"github.com/slimtoolkit/slim/pkg/crt"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerutil"
 	"github.com/slimtoolkit/slim/pkg/ipc/channel"
 	"github.com/slimtoolkit/slim/pkg/ipc/command"
@@ -96,6 +98,7 @@ type Inspector struct {
 	DockerHostIP          string
 	ImageInspector        *image.Inspector
 	APIClient             *dockerapi.Client
+	CRTClient             crt.APIClient // Used for the sensor volume and the container data operations.
 	Overrides             *config.ContainerOverrides
 	ExplicitVolumeMounts  map[string]config.VolumeMount
 	BaseMounts            []dockerapi.HostMount
@@ -170,6 +173,7 @@ func NewInspector(
 	crOpts *config.ContainerRunOptions,
 	logger *log.Entry,
 	client *dockerapi.Client,
+	crtClient crt.APIClient,
 	statePath string,
 	imageInspector *image.Inspector,
 	localVolumePath string,
@@ -224,6 +228,10 @@ func NewInspector(
 	appNodejsInspectOpts config.AppNodejsInspectOptions) (*Inspector, error) {
 
 	logger = logger.WithFields(log.Fields{"component": "container.inspector"})
+	if crtClient == nil {
+		crtClient = client
+	}
+
 	inspector := &Inspector{
 		logger:                logger,
 		StatePath:             statePath,
@@ -235,6 +243,7 @@ func NewInspector(
 		EvtPort:               evtPortSpecDefault,
 		ImageInspector:        imageInspector,
 		APIClient:             client,
+		CRTClient:             crtClient,
 		Overrides:             overrides,
 		ExplicitVolumeMounts:  explicitVolumeMounts,
 		BaseMounts:            baseMounts,
@@ -426,7 +435,7 @@ func (i *Inspector) RunContainer() error {
 	var err error
 	var volumeName string
 	if !i.DoUseLocalMounts {
-		volumeName, err = ensureSensorVolume(i.logger, i.APIClient, sensorPath, i.SensorVolumeName)
+		volumeName, err = ensureSensorVolume(i.logger, i.APIClient, i.CRTClient, sensorPath, i.SensorVolumeName)
 		errutil.FailOn(err)
 	}
 
@@ -1209,7 +1218,7 @@ func (i *Inspector) ShutdownContainer(terminateOnly bool) error {
 			//copy the container report
 			reportLocalPath := filepath.Join(i.LocalVolumePath, ArtifactsDir, ReportArtifactTar)
 			reportRemotePath := filepath.Join(app.DefaultArtifactsDirPath, report.DefaultContainerReportFileName)
-			err := dockerutil.CopyFromContainer(i.APIClient, i.ContainerID, reportRemotePath, reportLocalPath, true, deleteOrig)
+			err := dockerutil.CopyFromContainer(i.CRTClient, i.ContainerID, reportRemotePath, reportLocalPath, true, deleteOrig)
 			if err != nil {
 				logger.WithError(err).WithField("container", i.ContainerID).Error("dockerutil.CopyFromContainer")
 				//can't call errutil.FailOn() because we won't cleanup the target container
@@ -1220,7 +1229,7 @@ func (i *Inspector) ShutdownContainer(terminateOnly bool) error {
 				//copy the monitor data event log (if available)
 				mondelLocalPath := filepath.Join(i.LocalVolumePath, ArtifactsDir, MondelArtifactTar)
 				mondelRemotePath := filepath.Join(app.DefaultArtifactsDirPath, report.DefaultMonDelFileName)
-				err = dockerutil.CopyFromContainer(i.APIClient, i.ContainerID, mondelRemotePath, mondelLocalPath, true, deleteOrig)
+				err = dockerutil.CopyFromContainer(i.CRTClient, i.ContainerID, mondelRemotePath, mondelLocalPath, true, deleteOrig)
 				if err != nil {
 					//not a failure because the log might not be there (just log it)
 					logger.WithFields(log.Fields{
@@ -1236,7 +1245,7 @@ func (i *Inspector) ShutdownContainer(terminateOnly bool) error {
 				//ALTERNATIVE WAY TO XFER THE FILE ARTIFACTS
 				filesOutLocalPath := filepath.Join(i.LocalVolumePath, ArtifactsDir, FileArtifactsArchiveTar)
 				filesTarRemotePath := filepath.Join(app.DefaultArtifactsDirPath, fileArtifactsTar)
-				err = dockerutil.CopyFromContainer(i.APIClient,
+				err = dockerutil.CopyFromContainer(i.CRTClient,
 					i.ContainerID,
 					filesTarRemotePath,
 					filesOutLocalPath,
@@ -1249,7 +1258,7 @@ func (i *Inspector) ShutdownContainer(terminateOnly bool) error {
 
 			filesOutLocalPath := filepath.Join(i.LocalVolumePath, ArtifactsDir, FileArtifactsOutTar)
 			filesRemotePath := filepath.Join(app.DefaultArtifactsDirPath, app.ArtifactFilesDirName)
-			err = dockerutil.CopyFromContainer(i.APIClient, i.ContainerID, filesRemotePath, filesOutLocalPath, false, false)
+			err = dockerutil.CopyFromContainer(i.CRTClient, i.ContainerID, filesRemotePath, filesOutLocalPath, false, false)
 			if err != nil {
 				logger.WithError(err).WithField("container", i.ContainerID).Error("dockerutil.CopyFromContainer")
 				//can't call errutil.FailOn() because we won't cleanup the target container
@@ -1421,7 +1430,17 @@ func (i *Inspector) ProcessCollectedData() error {
 		return err
 	}
 
-	return seccomp.GenProfile(i.ImageInspector.ArtifactLocation, i.ImageInspector.SeccompProfileName)
+	err = seccomp.GenProfile(i.ImageInspector.ArtifactLocation,
+		i.ImageInspector.SeccompProfileName,
+		i.ImageInspector.SeccompMergeProfiles...)
+	if err != nil {
+		return err
+	}
+
+	i.logger.Info("generating network policy...")
+	return netpolicy.GenProfile(i.ImageInspector.ArtifactLocation,
+		i.ImageInspector.NetworkPolicyName,
+		i.ImageInspector.DockerNetworkName)
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -1430,12 +1449,17 @@ func sensorVolumeName() string {
 	return fmt.Sprintf("%s.%s", sensorVolumeBaseName, v.Tag())
 }
 
-func ensureSensorVolume(logger *log.Entry, client *dockerapi.Client, localSensorPath, volumeName string) (string, error) {
+func ensureSensorVolume(
+	logger *log.Entry,
+	client *dockerapi.Client,
+	crtClient crt.APIClient,
+	localSensorPath string,
+	volumeName string) (string, error) {
 	if volumeName == "" {
 		volumeName = sensorVolumeName()
 	}
 
-	err := dockerutil.HasVolume(client, volumeName)
+	err := dockerutil.HasVolume(crtClient, volumeName)
 	switch {
 	case err == nil:
 		logger.Debugf("ensureSensorVolume: already have volume = %v", volumeName)
@@ -1450,7 +1474,7 @@ func ensureSensorVolume(logger *log.Entry, client *dockerapi.Client, localSensor
 			}
 		}
 
-		err = dockerutil.CreateVolumeWithData(client, localSensorPath, volumeName, nil)
+		err = dockerutil.CreateVolumeWithData(crtClient, localSensorPath, volumeName, nil)
 		if err != nil {
 			logger.Debugf("ensureSensorVolume: dockerutil.CreateVolumeWithData() - error = %v", err)
 			return "", err
@@ -11,6 +11,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slimtoolkit/slim/pkg/consts"
+	"github.com/slimtoolkit/slim/pkg/crt"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/reverse"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerutil"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
@@ -20,8 +21,12 @@ const (
 	slimImageRepo          = "slim"
 	appArmorProfileName    = "apparmor-profile"
 	seccompProfileName     = "seccomp-profile"
+	networkPolicyName      = "network-policy.yaml"
+	dockerNetworkName      = "docker-network.json"
 	appArmorProfileNamePat = "%s-apparmor-profile"
 	seccompProfileNamePat  = "%s-seccomp.json"
+	networkPolicyNamePat   = "%s-network-policy.yaml"
+	dockerNetworkNamePat   = "%s-docker-network.json"
 	https                  = "https://"
 	http                   = "http://"
 )
@@ -33,20 +38,26 @@ type Inspector struct {
 	SlimImageRepo       string
 	AppArmorProfileName string
 	SeccompProfileName  string
-	ImageInfo           *docker.Image
-	ImageRecordInfo     docker.APIImages
-	APIClient           *docker.Client
+	NetworkPolicyName   string
+	DockerNetworkName   string
+	//Seccomp profiles (generated for other architectures) to merge
+	SeccompMergeProfiles []string
+	ImageInfo            *docker.Image
+	ImageRecordInfo      docker.APIImages
+	APIClient            crt.APIClient
 	//fatImageDockerInstructions []string
 	DockerfileInfo *reverse.Dockerfile
 }
 
 // NewInspector creates a new container image inspector
-func NewInspector(client *docker.Client, imageRef string /*, artifactLocation string*/) (*Inspector, error) {
+func NewInspector(client crt.APIClient, imageRef string /*, artifactLocation string*/) (*Inspector, error) {
 	inspector := &Inspector{
 		ImageRef:            imageRef,
 		SlimImageRepo:       slimImageRepo,
 		AppArmorProfileName: appArmorProfileName,
 		SeccompProfileName:  seccompProfileName,
+		NetworkPolicyName:   networkPolicyName,
+		DockerNetworkName:   dockerNetworkName,
 		//ArtifactLocation:    artifactLocation,
 		APIClient: client,
 	}
@@ -304,6 +315,8 @@ func (i *Inspector) processImageName() {
 				i.AppArmorProfileName = rtInfo[0]
 				i.SeccompProfileName = rtInfo[0]
 			}
+			i.NetworkPolicyName = fmt.Sprintf(networkPolicyNamePat, i.SeccompProfileName)
+			i.DockerNetworkName = fmt.Sprintf(dockerNetworkNamePat, i.SeccompProfileName)
 			i.AppArmorProfileName = fmt.Sprintf(appArmorProfileNamePat, i.AppArmorProfileName)
 			i.SeccompProfileName = fmt.Sprintf(seccompProfileNamePat, i.SeccompProfileName)
 		}
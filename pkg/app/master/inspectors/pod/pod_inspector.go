@@ -18,6 +18,7 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/sensor"
 	"github.com/slimtoolkit/slim/pkg/app/master/kubernetes"
 	"github.com/slimtoolkit/slim/pkg/app/master/security/apparmor"
+	"github.com/slimtoolkit/slim/pkg/app/master/security/netpolicy"
 	"github.com/slimtoolkit/slim/pkg/app/master/security/seccomp"
 	"github.com/slimtoolkit/slim/pkg/ipc/channel"
 	"github.com/slimtoolkit/slim/pkg/ipc/command"
@@ -295,7 +296,17 @@ func (i *Inspector) ProcessCollectedData() error {
 		return err
 	}
 
-	return seccomp.GenProfile(i.imageInspector.ArtifactLocation, i.imageInspector.SeccompProfileName)
+	err = seccomp.GenProfile(i.imageInspector.ArtifactLocation,
+		i.imageInspector.SeccompProfileName,
+		i.imageInspector.SeccompMergeProfiles...)
+	if err != nil {
+		return err
+	}
+
+	i.logger.Info("generating network policy...")
+	return netpolicy.GenProfile(i.imageInspector.ArtifactLocation,
+		i.imageInspector.NetworkPolicyName,
+		i.imageInspector.DockerNetworkName)
 }
 
 func (i *Inspector) Exec(cmd string, args ...string) ([]byte, error) {
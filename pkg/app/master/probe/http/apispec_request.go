@@ -0,0 +1,659 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	//max schema nesting level for the generated values (stops recursive schemas)
+	maxSchemaValueDepth = 8
+	//the nested objects below this level have only the required properties
+	maxOptionalPropertyDepth = 2
+
+	contentTypeJSON      = "application/json"
+	contentTypeForm      = "application/x-www-form-urlencoded"
+	contentTypeMultipart = "multipart/form-data"
+	contentTypeText      = "text/plain"
+)
+
+// Credential keys for the security scheme types
+// (used when there's no credential for the security scheme name)
+const (
+	credKeyBearer = "bearer"
+	credKeyBasic  = "basic"
+	credKeyAPIKey = "apikey"
+)
+
+// apiSpecRequest is a request synthesized from an API spec operation
+type apiSpecRequest struct {
+	method      string
+	endpoint    string
+	contentType string
+	body        []byte
+	headers     http.Header
+	cookies     []*http.Cookie
+	username    string
+	password    string
+	basicAuth   bool
+}
+
+// httpRequest creates a new HTTP request
+// (a new request is needed for each call because the body reader is consumed)
+func (ref *apiSpecRequest) httpRequest() (*http.Request, error) {
+	var body io.Reader
+	if ref.body != nil {
+		body = bytes.NewReader(ref.body)
+	}
+
+	req, err := http.NewRequest(ref.method, ref.endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range ref.headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	if ref.contentType != "" {
+		req.Header.Set("Content-Type", ref.contentType)
+	}
+
+	for _, cookie := range ref.cookies {
+		req.AddCookie(cookie)
+	}
+
+	if ref.basicAuth {
+		req.SetBasicAuth(ref.username, ref.password)
+	}
+
+	return req, nil
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]*)\}`)
+
+// newAPISpecRequest creates a request for the API spec operation
+// using the typed parameter values, the request body schema and the security scheme credentials
+func newAPISpecRequest(
+	spec *openapi3.T,
+	addr string,
+	prefix string,
+	apiPath string,
+	method string,
+	pathInfo *openapi3.PathItem,
+	op *openapi3.Operation,
+	creds map[string]string) *apiSpecRequest {
+	req := &apiSpecRequest{
+		method:  strings.ToUpper(method),
+		headers: http.Header{},
+	}
+
+	query := url.Values{}
+	pathValues := map[string]string{}
+	for _, param := range operationParams(pathInfo, op) {
+		value, found := paramValue(param)
+		if !found {
+			continue
+		}
+
+		switch param.In {
+		case openapi3.ParameterInPath:
+			pathValues[param.Name] = url.PathEscape(strings.Join(paramStrings(value), ","))
+		case openapi3.ParameterInQuery:
+			for _, val := range paramStrings(value) {
+				query.Add(param.Name, val)
+			}
+		case openapi3.ParameterInHeader:
+			req.headers.Set(param.Name, strings.Join(paramStrings(value), ","))
+		case openapi3.ParameterInCookie:
+			req.cookies = append(req.cookies, &http.Cookie{
+				Name:  param.Name,
+				Value: strings.Join(paramStrings(value), ","),
+			})
+		}
+	}
+
+	apiPath = pathParamPattern.ReplaceAllStringFunc(apiPath, func(match string) string {
+		name := match[1 : len(match)-1]
+		if value, found := pathValues[name]; found {
+			return value
+		}
+
+		//undeclared path params (keep the param name as the value)
+		return name
+	})
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		req.contentType, req.body = requestBodyData(op.RequestBody.Value)
+	}
+
+	applySecurity(req, query, spec, op, creds)
+
+	req.endpoint = fmt.Sprintf("%s%s%s", addr, prefix, apiPath)
+	if len(query) > 0 {
+		req.endpoint = fmt.Sprintf("%s?%s", req.endpoint, query.Encode())
+	}
+
+	return req
+}
+
+// operationParams returns the path item and operation params
+// (the operation params override the path item params with the same name and location)
+func operationParams(pathInfo *openapi3.PathItem, op *openapi3.Operation) []*openapi3.Parameter {
+	var params []*openapi3.Parameter
+	index := map[string]int{}
+	add := func(refs openapi3.Parameters) {
+		for _, ref := range refs {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+
+			key := ref.Value.In + ":" + ref.Value.Name
+			if idx, found := index[key]; found {
+				params[idx] = ref.Value
+				continue
+			}
+
+			index[key] = len(params)
+			params = append(params, ref.Value)
+		}
+	}
+
+	if pathInfo != nil {
+		add(pathInfo.Parameters)
+	}
+
+	add(op.Parameters)
+	return params
+}
+
+// paramValue returns the param value from the param example or the param schema
+// (the optional params are included only if they have an example, a default or an enum value)
+func paramValue(param *openapi3.Parameter) (any, bool) {
+	if param.Example != nil {
+		return param.Example, true
+	}
+
+	if value := firstExample(param.Examples); value != nil {
+		return value, true
+	}
+
+	schema := param.Schema
+	if schema == nil {
+		//params with content have the schema in the media type
+		for _, mediaType := range sortedKeys(param.Content) {
+			if media := param.Content[mediaType]; media != nil {
+				if media.Example != nil {
+					return media.Example, true
+				}
+
+				schema = media.Schema
+				break
+			}
+		}
+	}
+
+	required := param.Required || param.In == openapi3.ParameterInPath
+	if !required && !hasExplicitValue(schema) {
+		return nil, false
+	}
+
+	value := schemaValue(schema, 0)
+	if value == nil {
+		value = "1"
+	}
+
+	return value, true
+}
+
+func hasExplicitValue(ref *openapi3.SchemaRef) bool {
+	if ref == nil || ref.Value == nil {
+		return false
+	}
+
+	return ref.Value.Example != nil || ref.Value.Default != nil || len(ref.Value.Enum) > 0
+}
+
+// paramStrings returns the string values for the param value
+// (the array values are separate values; the objects are JSON encoded)
+func paramStrings(value any) []string {
+	switch typed := value.(type) {
+	case []any:
+		var values []string
+		for _, item := range typed {
+			values = append(values, scalarString(item))
+		}
+
+		return values
+	default:
+		return []string{scalarString(value)}
+	}
+}
+
+func scalarString(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case map[string]any, []any:
+		data, err := json.Marshal(typed)
+		if err != nil {
+			return ""
+		}
+
+		return string(data)
+	case float64:
+		//no exponent format for the whole numbers
+		if typed == float64(int64(typed)) {
+			return fmt.Sprintf("%d", int64(typed))
+		}
+
+		return fmt.Sprintf("%v", typed)
+	default:
+		return fmt.Sprintf("%v", typed)
+	}
+}
+
+func firstExample(examples openapi3.Examples) any {
+	for _, name := range sortedKeys(examples) {
+		if ref := examples[name]; ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			return ref.Value.Value
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// requestBodyData returns the content type and the generated request body
+// (JSON is preferred if the request body supports multiple content types)
+func requestBodyData(body *openapi3.RequestBody) (string, []byte) {
+	mediaKey, mediaType := bodyMediaType(body.Content)
+	if mediaType == "" {
+		return "", nil
+	}
+
+	media := body.Content[mediaKey]
+	var value any
+	if media != nil {
+		value = media.Example
+		if value == nil {
+			value = firstExample(media.Examples)
+		}
+
+		if value == nil {
+			value = schemaValue(media.Schema, 0)
+		}
+	}
+
+	switch {
+	case mediaType == contentTypeForm:
+		form := url.Values{}
+		if fields, ok := value.(map[string]any); ok {
+			for _, name := range sortedKeys(fields) {
+				for _, val := range paramStrings(fields[name]) {
+					form.Add(name, val)
+				}
+			}
+		}
+
+		return mediaType, []byte(form.Encode())
+	case mediaType == contentTypeMultipart:
+		var data bytes.Buffer
+		writer := multipart.NewWriter(&data)
+		if fields, ok := value.(map[string]any); ok {
+			for _, name := range sortedKeys(fields) {
+				for _, val := range paramStrings(fields[name]) {
+					_ = writer.WriteField(name, val)
+				}
+			}
+		}
+
+		_ = writer.Close()
+		return writer.FormDataContentType(), data.Bytes()
+	case isJSONMediaType(mediaType):
+		if value == nil {
+			value = map[string]any{}
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return mediaType, nil
+		}
+
+		return mediaType, data
+	default:
+		if value == nil {
+			return mediaType, []byte{}
+		}
+
+		return mediaType, []byte(scalarString(value))
+	}
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == contentTypeJSON ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "/json")
+}
+
+// bodyMediaType returns the content key and the request content type
+// (they are different for the wildcard media types)
+func bodyMediaType(content openapi3.Content) (string, string) {
+	mediaTypes := sortedKeys(content)
+	if len(mediaTypes) == 0 {
+		return "", ""
+	}
+
+	for _, preferred := range []string{contentTypeJSON, contentTypeForm, contentTypeMultipart, contentTypeText} {
+		if _, found := content[preferred]; found {
+			return preferred, preferred
+		}
+	}
+
+	for _, mediaType := range mediaTypes {
+		if isJSONMediaType(mediaType) {
+			return mediaType, mediaType
+		}
+	}
+
+	if strings.Contains(mediaTypes[0], "*") {
+		//wildcard media types (e.g., '*/*')
+		return mediaTypes[0], contentTypeJSON
+	}
+
+	return mediaTypes[0], mediaTypes[0]
+}
+
+// schemaValue generates a value for the schema
+// (using the example, default and enum values first, then the type and format)
+func schemaValue(ref *openapi3.SchemaRef, depth int) any {
+	if ref == nil || ref.Value == nil || depth > maxSchemaValueDepth {
+		return nil
+	}
+
+	schema := ref.Value
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		merged := map[string]any{}
+		var last any
+		for _, sub := range schema.AllOf {
+			last = schemaValue(sub, depth+1)
+			if fields, ok := last.(map[string]any); ok {
+				for name, value := range fields {
+					merged[name] = value
+				}
+			}
+		}
+
+		if fields, ok := objectValue(schema, depth).(map[string]any); ok {
+			for name, value := range fields {
+				merged[name] = value
+			}
+		}
+
+		if len(merged) > 0 {
+			return merged
+		}
+
+		return last
+	}
+
+	if len(schema.OneOf) > 0 {
+		return schemaValue(schema.OneOf[0], depth+1)
+	}
+
+	if len(schema.AnyOf) > 0 {
+		return schemaValue(schema.AnyOf[0], depth+1)
+	}
+
+	switch {
+	case schema.Type.Includes(openapi3.TypeObject):
+		return objectValue(schema, depth)
+	case schema.Type.Includes(openapi3.TypeArray):
+		return arrayValue(schema, depth)
+	case schema.Type.Includes(openapi3.TypeString):
+		return stringValue(schema)
+	case schema.Type.Includes(openapi3.TypeInteger):
+		return int64(numberValue(schema, true))
+	case schema.Type.Includes(openapi3.TypeNumber):
+		return numberValue(schema, false)
+	case schema.Type.Includes(openapi3.TypeBoolean):
+		return true
+	case len(schema.Properties) > 0 || schema.AdditionalProperties.Schema != nil:
+		return objectValue(schema, depth)
+	case schema.Items != nil:
+		return arrayValue(schema, depth)
+	}
+
+	return nil
+}
+
+func objectValue(schema *openapi3.Schema, depth int) any {
+	required := map[string]struct{}{}
+	for _, name := range schema.Required {
+		required[name] = struct{}{}
+	}
+
+	fields := map[string]any{}
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		if prop != nil && prop.Value != nil && prop.Value.ReadOnly {
+			//the server sets the read only properties
+			continue
+		}
+
+		if _, found := required[name]; !found && depth > maxOptionalPropertyDepth {
+			continue
+		}
+
+		if value := schemaValue(prop, depth+1); value != nil {
+			fields[name] = value
+		}
+	}
+
+	if len(fields) == 0 && schema.AdditionalProperties.Schema != nil {
+		if value := schemaValue(schema.AdditionalProperties.Schema, depth+1); value != nil {
+			fields["key"] = value
+		}
+	}
+
+	return fields
+}
+
+func arrayValue(schema *openapi3.Schema, depth int) any {
+	count := int(schema.MinItems)
+	if count < 1 {
+		count = 1
+	}
+
+	items := []any{}
+	for i := 0; i < count; i++ {
+		value := schemaValue(schema.Items, depth+1)
+		if value == nil {
+			break
+		}
+
+		items = append(items, value)
+	}
+
+	return items
+}
+
+var stringFormatValues = map[string]string{
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"time":      "00:00:00",
+	"email":     "user@example.com",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uri":       "http://example.com",
+	"url":       "http://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "127.0.0.1",
+	"ipv6":      "::1",
+	"byte":      "c2xpbQ==",
+	"binary":    "slim",
+	"password":  "password",
+}
+
+func stringValue(schema *openapi3.Schema) string {
+	value, found := stringFormatValues[schema.Format]
+	if !found {
+		value = "string"
+	}
+
+	for uint64(len(value)) < schema.MinLength {
+		value += "x"
+	}
+
+	if schema.MaxLength != nil && uint64(len(value)) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+
+	return value
+}
+
+func numberValue(schema *openapi3.Schema, isInteger bool) float64 {
+	value := float64(1)
+	if schema.Min != nil {
+		value = *schema.Min
+		if schema.ExclusiveMin {
+			if isInteger {
+				value++
+			} else {
+				value += 0.5
+			}
+		}
+	}
+
+	if schema.Max != nil && value > *schema.Max {
+		value = *schema.Max
+	}
+
+	return value
+}
+
+// applySecurity adds the credentials for the first operation security requirement
+// where all security schemes have credentials
+func applySecurity(
+	req *apiSpecRequest,
+	query url.Values,
+	spec *openapi3.T,
+	op *openapi3.Operation,
+	creds map[string]string) {
+	if len(creds) == 0 || spec.Components == nil {
+		return
+	}
+
+	requirements := spec.Security
+	if op.Security != nil {
+		requirements = *op.Security
+	}
+
+	for _, requirement := range requirements {
+		schemes := map[string]*openapi3.SecurityScheme{}
+		values := map[string]string{}
+		for name := range requirement {
+			ref := spec.Components.SecuritySchemes[name]
+			if ref == nil || ref.Value == nil {
+				break
+			}
+
+			value, found := schemeCredential(name, ref.Value, creds)
+			if !found {
+				break
+			}
+
+			schemes[name] = ref.Value
+			values[name] = value
+		}
+
+		if len(requirement) == 0 || len(values) != len(requirement) {
+			continue
+		}
+
+		for _, name := range sortedKeys(schemes) {
+			applySecurityScheme(req, query, schemes[name], values[name])
+		}
+
+		return
+	}
+}
+
+func schemeCredential(name string, scheme *openapi3.SecurityScheme, creds map[string]string) (string, bool) {
+	if value, found := creds[name]; found {
+		return value, true
+	}
+
+	key := credKeyBearer
+	switch {
+	case scheme.Type == "apiKey":
+		key = credKeyAPIKey
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+		key = credKeyBasic
+	}
+
+	value, found := creds[key]
+	return value, found
+}
+
+func applySecurityScheme(req *apiSpecRequest, query url.Values, scheme *openapi3.SecurityScheme, value string) {
+	switch scheme.Type {
+	case "apiKey":
+		switch scheme.In {
+		case openapi3.ParameterInQuery:
+			query.Set(scheme.Name, value)
+		case openapi3.ParameterInCookie:
+			req.cookies = append(req.cookies, &http.Cookie{Name: scheme.Name, Value: value})
+		default:
+			req.headers.Set(scheme.Name, value)
+		}
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "basic":
+			if parts := strings.SplitN(value, ":", 2); len(parts) == 2 {
+				req.username = parts[0]
+				req.password = parts[1]
+				req.basicAuth = true
+			} else {
+				//already encoded basic credentials
+				req.headers.Set("Authorization", "Basic "+value)
+			}
+		case "bearer":
+			req.headers.Set("Authorization", "Bearer "+value)
+		default:
+			req.headers.Set("Authorization", scheme.Scheme+" "+value)
+		}
+	default:
+		//'oauth2' and 'openIdConnect' (the value is the access token)
+		req.headers.Set("Authorization", "Bearer "+value)
+	}
+}
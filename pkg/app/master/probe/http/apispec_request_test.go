@@ -0,0 +1,451 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAPISpec = `
+openapi: 3.0.3
+info:
+  title: test
+  version: "1.0"
+security:
+- bearerAuth: []
+paths:
+  /users/{id}/items/{item}:
+    parameters:
+    - name: id
+      in: path
+      required: true
+      schema:
+        type: integer
+        minimum: 10
+    - name: verbose
+      in: query
+      schema:
+        type: boolean
+    post:
+      parameters:
+      - name: id
+        in: path
+        required: true
+        example: 42
+      - name: item
+        in: path
+        required: true
+        schema:
+          type: string
+          format: uuid
+      - name: tags
+        in: query
+        required: true
+        schema:
+          type: array
+          minItems: 2
+          items:
+            type: string
+            enum: [red, green]
+      - name: limit
+        in: query
+        schema:
+          type: integer
+          default: 25
+      - name: X-Request-ID
+        in: header
+        required: true
+        examples:
+          b:
+            value: second
+          a:
+            value: first
+      - name: session
+        in: cookie
+        required: true
+        schema:
+          type: string
+          minLength: 8
+      requestBody:
+        content:
+          text/plain:
+            schema:
+              type: string
+          application/json:
+            schema:
+              $ref: '#/components/schemas/User'
+      responses:
+        "200":
+          description: ok
+  /forms:
+    put:
+      security:
+      - apiKeyQuery: []
+      - basicAuth: []
+        apiKeyHeader: []
+      requestBody:
+        content:
+          application/x-www-form-urlencoded:
+            example:
+              name: app
+              ids: [1, 2]
+      responses:
+        "200":
+          description: ok
+  /uploads:
+    post:
+      security: []
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                file:
+                  type: string
+                  format: binary
+                note:
+                  type: string
+                  maxLength: 3
+      responses:
+        "200":
+          description: ok
+  /any:
+    post:
+      requestBody:
+        content:
+          '*/*':
+            schema:
+              type: object
+              additionalProperties:
+                type: number
+                minimum: 1.5
+                exclusiveMinimum: true
+      responses:
+        "200":
+          description: ok
+components:
+  securitySchemes:
+    bearerAuth:
+      type: http
+      scheme: bearer
+    basicAuth:
+      type: http
+      scheme: basic
+    apiKeyQuery:
+      type: apiKey
+      in: query
+      name: api_key
+    apiKeyHeader:
+      type: apiKey
+      in: header
+      name: X-API-Key
+  schemas:
+    User:
+      type: object
+      required: [name, address]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+          example: slim
+        email:
+          type: string
+          format: email
+        roles:
+          type: array
+          items:
+            type: string
+            enum: [admin, user]
+        score:
+          type: number
+          maximum: 0.5
+        address:
+          $ref: '#/components/schemas/Address'
+        parent:
+          $ref: '#/components/schemas/User'
+    Address:
+      allOf:
+      - type: object
+        required: [city]
+        properties:
+          city:
+            type: string
+          zip:
+            type: string
+            default: "00000"
+      - type: object
+        properties:
+          country:
+            type: string
+            enum: [US, CA]
+`
+
+func loadTestAPISpec(t *testing.T) *openapi3.T {
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData([]byte(testAPISpec))
+	require.NoError(t, err)
+	return spec
+}
+
+func testAPISpecRequest(t *testing.T, spec *openapi3.T, apiPath, method string, creds map[string]string) *apiSpecRequest {
+	pathInfo := spec.Paths.Value(apiPath)
+	require.NotNil(t, pathInfo, apiPath)
+	op := pathInfo.GetOperation(strings.ToUpper(method))
+	require.NotNil(t, op, apiPath)
+	return newAPISpecRequest(spec, "http://127.0.0.1:8080", "/api", apiPath, method, pathInfo, op, creds)
+}
+
+func TestNewAPISpecRequest(t *testing.T) {
+	spec := loadTestAPISpec(t)
+	req := testAPISpecRequest(t, spec, "/users/{id}/items/{item}", "post", map[string]string{"bearer": "tkn"})
+	assert.Equal(t, http.MethodPost, req.method)
+
+	endpoint, err := url.Parse(req.endpoint)
+	require.NoError(t, err)
+	//the operation 'id' param overrides the path item param (the optional 'verbose' param is not included)
+	assert.Equal(t, "/api/users/42/items/3fa85f64-5717-4562-b3fc-2c963f66afa6", endpoint.Path)
+	assert.Equal(t, url.Values{
+		"tags":  {"red", "red"},
+		"limit": {"25"},
+	}, endpoint.Query())
+
+	assert.Equal(t, "first", req.headers.Get("X-Request-ID"))
+	assert.Equal(t, "Bearer tkn", req.headers.Get("Authorization"))
+	require.Len(t, req.cookies, 1)
+	assert.Equal(t, "session", req.cookies[0].Name)
+	assert.Equal(t, "stringxx", req.cookies[0].Value)
+
+	//JSON is preferred
+	assert.Equal(t, contentTypeJSON, req.contentType)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(req.body, &body))
+	assert.Equal(t, "slim", body["name"])
+	assert.Equal(t, "user@example.com", body["email"])
+	assert.Equal(t, []any{"admin"}, body["roles"])
+	assert.Equal(t, 0.5, body["score"])
+	assert.NotContains(t, body, "id")
+	assert.Equal(t, map[string]any{"city": "string", "zip": "00000", "country": "US"}, body["address"])
+
+	//the nested objects have only the required properties below the optional property depth
+	parent := body["parent"].(map[string]any)
+	grandparent := parent["parent"].(map[string]any)
+	assert.Equal(t, []string{"address", "name"}, sortedKeys(grandparent["parent"].(map[string]any)))
+
+	httpReq, err := req.httpRequest()
+	require.NoError(t, err)
+	assert.Equal(t, contentTypeJSON, httpReq.Header.Get("Content-Type"))
+	cookie, err := httpReq.Cookie("session")
+	require.NoError(t, err)
+	assert.Equal(t, "stringxx", cookie.Value)
+
+	//a new body reader for each request
+	for i := 0; i < 2; i++ {
+		httpReq, err := req.httpRequest()
+		require.NoError(t, err)
+		data, err := io.ReadAll(httpReq.Body)
+		require.NoError(t, err)
+		assert.Equal(t, req.body, data)
+	}
+}
+
+func TestNewAPISpecRequestBodies(t *testing.T) {
+	spec := loadTestAPISpec(t)
+
+	req := testAPISpecRequest(t, spec, "/forms", "put", nil)
+	assert.Equal(t, "http://127.0.0.1:8080/api/forms", req.endpoint)
+	assert.Equal(t, contentTypeForm, req.contentType)
+	assert.Equal(t, "ids=1&ids=2&name=app", string(req.body))
+
+	req = testAPISpecRequest(t, spec, "/uploads", "post", nil)
+	mediaType, params, err := mime.ParseMediaType(req.contentType)
+	require.NoError(t, err)
+	assert.Equal(t, contentTypeMultipart, mediaType)
+	form, err := multipart.NewReader(strings.NewReader(string(req.body)), params["boundary"]).ReadForm(1024)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"file": {"slim"}, "note": {"str"}}, form.Value)
+
+	//the wildcard media types use JSON
+	req = testAPISpecRequest(t, spec, "/any", "post", nil)
+	assert.Equal(t, contentTypeJSON, req.contentType)
+	assert.JSONEq(t, `{"key": 2}`, string(req.body))
+}
+
+func TestNewAPISpecRequestSecurity(t *testing.T) {
+	spec := loadTestAPISpec(t)
+	tt := []struct {
+		name     string
+		apiPath  string
+		method   string
+		creds    map[string]string
+		endpoint string
+		headers  http.Header
+		username string
+		password string
+	}{
+		{
+			name:     "no credentials",
+			apiPath:  "/forms",
+			method:   "put",
+			endpoint: "http://127.0.0.1:8080/api/forms",
+			headers:  http.Header{},
+		},
+		{
+			name:     "first requirement",
+			apiPath:  "/forms",
+			method:   "put",
+			creds:    map[string]string{"apikey": "k1", "basic": "user:pass"},
+			endpoint: "http://127.0.0.1:8080/api/forms?api_key=k1",
+			headers:  http.Header{},
+		},
+		{
+			name:     "second requirement",
+			apiPath:  "/forms",
+			method:   "put",
+			creds:    map[string]string{"apiKeyHeader": "k2", "basicAuth": "user:pass"},
+			endpoint: "http://127.0.0.1:8080/api/forms",
+			headers:  http.Header{"X-Api-Key": {"k2"}},
+			username: "user",
+			password: "pass",
+		},
+		{
+			name:     "encoded basic credentials",
+			apiPath:  "/forms",
+			method:   "put",
+			creds:    map[string]string{"apiKeyHeader": "k2", "basic": "dXNlcjpwYXNz"},
+			endpoint: "http://127.0.0.1:8080/api/forms",
+			headers:  http.Header{"X-Api-Key": {"k2"}, "Authorization": {"Basic dXNlcjpwYXNz"}},
+		},
+		{
+			name:     "no security",
+			apiPath:  "/uploads",
+			method:   "post",
+			creds:    map[string]string{"bearer": "tkn"},
+			endpoint: "http://127.0.0.1:8080/api/uploads",
+			headers:  http.Header{},
+		},
+		{
+			name:     "global security",
+			apiPath:  "/any",
+			method:   "post",
+			creds:    map[string]string{"bearerAuth": "tkn"},
+			endpoint: "http://127.0.0.1:8080/api/any",
+			headers:  http.Header{"Authorization": {"Bearer tkn"}},
+		},
+	}
+
+	for _, test := range tt {
+		req := testAPISpecRequest(t, spec, test.apiPath, test.method, test.creds)
+		assert.Equal(t, test.endpoint, req.endpoint, test.name)
+		assert.Equal(t, test.headers, req.headers, test.name)
+		assert.Equal(t, test.username != "", req.basicAuth, test.name)
+		assert.Equal(t, test.username, req.username, test.name)
+		assert.Equal(t, test.password, req.password, test.name)
+	}
+}
+
+func TestApplySecuritySchemeCookie(t *testing.T) {
+	req := &apiSpecRequest{headers: http.Header{}}
+	query := url.Values{}
+	applySecurityScheme(req, query, &openapi3.SecurityScheme{Type: "apiKey", In: "cookie", Name: "sid"}, "v1")
+	applySecurityScheme(req, query, &openapi3.SecurityScheme{Type: "http", Scheme: "Digest"}, "v2")
+	applySecurityScheme(req, query, &openapi3.SecurityScheme{Type: "oauth2"}, "v3")
+
+	require.Len(t, req.cookies, 1)
+	assert.Equal(t, "v1", req.cookies[0].Value)
+	assert.Equal(t, "Bearer v3", req.headers.Get("Authorization"))
+	assert.Empty(t, query)
+}
+
+func TestSchemaValue(t *testing.T) {
+	maxLength := uint64(4)
+	maxValue := float64(3)
+	minValue := float64(5)
+	tt := []struct {
+		name     string
+		schema   *openapi3.Schema
+		expected any
+	}{
+		{name: "example", schema: &openapi3.Schema{Type: &openapi3.Types{"string"}, Example: "ex", Default: "def"}, expected: "ex"},
+		{name: "enum", schema: &openapi3.Schema{Type: &openapi3.Types{"string"}, Enum: []any{"a", "b"}}, expected: "a"},
+		{name: "date", schema: &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "date"}, expected: "2024-01-01"},
+		{name: "max length", schema: &openapi3.Schema{Type: &openapi3.Types{"string"}, MaxLength: &maxLength}, expected: "stri"},
+		{name: "integer", schema: &openapi3.Schema{Type: &openapi3.Types{"integer"}}, expected: int64(1)},
+		{name: "exclusive min", schema: &openapi3.Schema{Type: &openapi3.Types{"integer"}, Min: &minValue, ExclusiveMin: true}, expected: int64(6)},
+		{name: "max", schema: &openapi3.Schema{Type: &openapi3.Types{"number"}, Min: &minValue, Max: &maxValue}, expected: float64(3)},
+		{name: "boolean", schema: &openapi3.Schema{Type: &openapi3.Types{"boolean"}}, expected: true},
+		{
+			name:     "one of",
+			schema:   &openapi3.Schema{OneOf: openapi3.SchemaRefs{openapi3.NewSchemaRef("", openapi3.NewBoolSchema())}},
+			expected: true,
+		},
+		{
+			name:     "untyped items",
+			schema:   &openapi3.Schema{Items: openapi3.NewSchemaRef("", openapi3.NewInt64Schema())},
+			expected: []any{int64(1)},
+		},
+		{name: "empty", schema: &openapi3.Schema{}},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, schemaValue(openapi3.NewSchemaRef("", test.schema), 0), test.name)
+	}
+
+	assert.Nil(t, schemaValue(nil, 0))
+}
+
+func TestParamStrings(t *testing.T) {
+	tt := []struct {
+		value    any
+		expected []string
+	}{
+		{value: "a", expected: []string{"a"}},
+		{value: float64(10), expected: []string{"10"}},
+		{value: 1.5, expected: []string{"1.5"}},
+		{value: float64(1e15), expected: []string{"1000000000000000"}},
+		{value: int64(7), expected: []string{"7"}},
+		{value: true, expected: []string{"true"}},
+		{value: []any{"a", float64(2)}, expected: []string{"a", "2"}},
+		{value: map[string]any{"k": "v"}, expected: []string{`{"k":"v"}`}},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, paramStrings(test.value), "%v", test.value)
+	}
+}
+
+func TestBodyMediaType(t *testing.T) {
+	tt := []struct {
+		types    []string
+		key      string
+		expected string
+	}{
+		{types: []string{"text/plain", "application/json"}, key: contentTypeJSON, expected: contentTypeJSON},
+		{types: []string{"application/xml", "application/vnd.api+json"}, key: "application/vnd.api+json", expected: "application/vnd.api+json"},
+		{types: []string{"application/xml", "text/csv"}, key: "application/xml", expected: "application/xml"},
+		{types: []string{"*/*"}, key: "*/*", expected: contentTypeJSON},
+		{},
+	}
+
+	for _, test := range tt {
+		content := openapi3.Content{}
+		for _, mediaType := range test.types {
+			content[mediaType] = openapi3.NewMediaType()
+		}
+
+		key, mediaType := bodyMediaType(content)
+		assert.Equal(t, test.key, key, "%v", test.types)
+		assert.Equal(t, test.expected, mediaType, "%v", test.types)
+	}
+}
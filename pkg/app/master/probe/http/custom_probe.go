@@ -19,6 +19,7 @@ import (
 	"github.com/slimtoolkit/slim/pkg/app/master/config"
 	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/container"
 	"github.com/slimtoolkit/slim/pkg/app/master/inspectors/pod"
+	"github.com/slimtoolkit/slim/pkg/probediff"
 )
 
 const (
@@ -38,7 +39,11 @@ type CustomProbe struct {
 	opts config.HTTPProbeOptions
 
 	ports      []string
+	tcpProbes  []tcpProbeTarget
 	targetHost string
+	//containerPorts maps the probe target ports to the container ports
+	//(when they are different)
+	containerPorts map[string]string
 
 	APISpecProbes []apiSpecInfo
 
@@ -47,6 +52,11 @@ type CustomProbe struct {
 	CallCount uint64
 	ErrCount  uint64
 	OkCount   uint64
+	//AssertionErrCount is the number of the failed probe command expectations
+	AssertionErrCount uint64
+
+	callResults []*probediff.CallResult
+	callKeys    map[string]int
 
 	doneChan           chan struct{}
 	workers            sync.WaitGroup
@@ -62,7 +72,7 @@ func NewEndpointProbe(
 	printState bool,
 ) (*CustomProbe, error) {
 	probe := newCustomProbe(xc, targetEndpoint, opts, printState)
-	if len(ports) == 0 {
+	if len(ports) == 0 && len(opts.TCPProbes) == 0 {
 		ports = []uint{80}
 	}
 
@@ -70,6 +80,10 @@ func NewEndpointProbe(
 		probe.ports = append(probe.ports, fmt.Sprintf("%d", pnum))
 	}
 
+	probe.addTCPProbeTargets(func(pnum uint16) (string, bool) {
+		return fmt.Sprintf("%d", pnum), true
+	})
+
 	if len(probe.opts.APISpecFiles) > 0 {
 		probe.loadAPISpecFiles()
 	}
@@ -105,6 +119,13 @@ func NewContainerProbe(
 
 	log.Debugf("HTTP probe - available host ports => %+v", availableHostPorts)
 
+	if inspector.SensorIPCMode != container.SensorIPCModeDirect {
+		probe.containerPorts = map[string]string{}
+		for hostPort, containerPort := range availableHostPorts {
+			probe.containerPorts[hostPort] = containerPort
+		}
+	}
+
 	if len(probe.opts.Ports) > 0 {
 		for _, pnum := range probe.opts.Ports {
 			pspec := dockerapi.Port(fmt.Sprintf("%v/tcp", pnum))
@@ -163,6 +184,19 @@ func NewContainerProbe(
 		log.Debugf("HTTP probe - probe.Ports => %+v", probe.ports)
 	}
 
+	probe.addTCPProbeTargets(func(pnum uint16) (string, bool) {
+		pspec := dockerapi.Port(fmt.Sprintf("%v/tcp", pnum))
+		if _, ok := inspector.AvailablePorts[pspec]; !ok {
+			return "", false
+		}
+
+		if inspector.SensorIPCMode == container.SensorIPCModeDirect {
+			return fmt.Sprintf("%d", pnum), true
+		}
+
+		return inspector.AvailablePorts[pspec].HostPort, true
+	})
+
 	if len(probe.opts.APISpecFiles) > 0 {
 		probe.loadAPISpecFiles()
 	}
@@ -185,6 +219,7 @@ func NewPodProbe(
 	}
 
 	log.Debugf("HTTP probe - available host ports => %+v", availableHostPorts)
+	probe.containerPorts = availableHostPorts
 
 	if len(probe.opts.Ports) > 0 {
 		for _, pnum := range probe.opts.Ports {
@@ -205,6 +240,59 @@ func NewPodProbe(
 		log.Debugf("HTTP probe - probe.Ports => %+v", probe.ports)
 	}
 
+	probe.addTCPProbeTargets(func(pnum uint16) (string, bool) {
+		pspec := dockerapi.Port(fmt.Sprintf("%v/tcp", pnum))
+		if port, ok := inspector.AvailablePorts()[pspec]; ok {
+			return port.HostPort, true
+		}
+
+		return "", false
+	})
+
+	if len(probe.opts.APISpecFiles) > 0 {
+		probe.loadAPISpecFiles()
+	}
+
+	return probe, nil
+}
+
+// NewReplayProbe creates a new custom HTTP probe for another container
+// with the same probe options and the same port order as the source probe
+// (targetPort maps the container ports to the new probe target ports)
+func NewReplayProbe(
+	xc *app.ExecutionContext,
+	source *CustomProbe,
+	targetHost string,
+	targetPort func(containerPort string) (string, bool),
+	printState bool,
+) (*CustomProbe, error) {
+	probe := newCustomProbe(xc, targetHost, source.opts, printState)
+	probe.containerPorts = map[string]string{}
+
+	for _, sourcePort := range source.ports {
+		containerPort := source.containerPort(sourcePort)
+		if port, ok := targetPort(containerPort); ok {
+			probe.ports = append(probe.ports, port)
+			probe.containerPorts[port] = containerPort
+		} else {
+			log.Debugf("HTTP probe - ignoring replay port => %v", containerPort)
+		}
+	}
+
+	for _, target := range source.tcpProbes {
+		if port, ok := targetPort(target.containerPort); ok {
+			probe.tcpProbes = append(probe.tcpProbes, tcpProbeTarget{
+				protocol:      target.protocol,
+				port:          port,
+				containerPort: target.containerPort,
+			})
+		} else {
+			log.Debugf("HTTP probe - ignoring replay tcp probe port => %v", target.containerPort)
+		}
+	}
+
+	log.Debugf("HTTP probe - replay probe ports => %+v (tcp => %+v)", probe.ports, probe.tcpProbes)
+
 	if len(probe.opts.APISpecFiles) > 0 {
 		probe.loadAPISpecFiles()
 	}
@@ -255,8 +343,14 @@ func newCustomProbe(
 	return probe
 }
 
+// Ports returns the probe target ports (including the TCP probe ports)
 func (p *CustomProbe) Ports() []string {
-	return p.ports
+	ports := append([]string{}, p.ports...)
+	for _, target := range p.tcpProbes {
+		ports = append(ports, target.port)
+	}
+
+	return ports
 }
 
 // Start starts the HTTP probe instance execution
@@ -316,6 +410,19 @@ func (p *CustomProbe) Start() {
 					"targets": strings.Join(p.ports, ","),
 				})
 
+			if len(p.tcpProbes) > 0 {
+				var tcpTargets []string
+				for _, target := range p.tcpProbes {
+					tcpTargets = append(tcpTargets, target.String())
+				}
+
+				p.xc.Out.Info("http.probe.tcp.ports",
+					ovars{
+						"count":   len(p.tcpProbes),
+						"targets": strings.Join(tcpTargets, ","),
+					})
+			}
+
 			var cmdListPreview []string
 			var cmdListTail string
 			for idx, c := range p.opts.Cmds {
@@ -368,7 +475,7 @@ func (p *CustomProbe) Start() {
 				// TODO: need a smarter and more dynamic way to determine the actual protocol type
 
 				// Set up FastCGI defaults if the default CGI port is used without a FastCGI config.
-				if port == defaultFastCGIPortStr && cmd.FastCGI == nil {
+				if port == defaultFastCGIPortStr && cmd.FastCGI == nil && !IsValidGRPCProto(cmd.Protocol) {
 					log.Debugf("HTTP probe - FastCGI default port (%s) used, setting up HTTP probe FastCGI wrapper defaults", port)
 
 					// Typicall the entrypoint into a PHP app.
@@ -484,6 +591,11 @@ func (p *CustomProbe) Start() {
 						continue
 					}
 
+					if IsValidGRPCProto(proto) {
+						p.grpcProbe(cmd, proto, port, maxRetryCount, notReadyErrorWait)
+						continue
+					}
+
 					var client *http.Client
 					switch {
 					case cmd.FastCGI != nil:
@@ -507,18 +619,13 @@ func (p *CustomProbe) Start() {
 						continue
 					}
 
+					var callResult *probediff.CallResult
 					for i := 0; i < maxRetryCount; i++ {
 						res, err := client.Do(req.Clone(context.Background()))
 						p.CallCount++
 						rbSeeker.Seek(0, 0)
 
-						if res != nil {
-							if res.Body != nil {
-								io.Copy(io.Discard, res.Body)
-							}
-
-							res.Body.Close()
-						}
+						callResult = newHTTPCallResult(cmd, proto, p.containerPort(port), res, err)
 
 						statusCode := "error"
 						callErrorStr := "none"
@@ -542,12 +649,14 @@ func (p *CustomProbe) Start() {
 
 						if err == nil {
 							p.OkCount++
+							p.printFailedAssertions(addr, callResult)
 
 							if p.OkCount == 1 {
 								if len(p.opts.APISpecs) != 0 && len(p.opts.APISpecFiles) != 0 && cmd.FastCGI != nil {
 									p.xc.Out.Info("HTTP probe - API spec probing not implemented for fastcgi")
 								} else {
 									p.probeAPISpecs(proto, p.targetHost, port)
+									p.probeGraphQL(proto, p.targetHost, port)
 								}
 							}
 
@@ -579,19 +688,30 @@ func (p *CustomProbe) Start() {
 						}
 
 					}
+
+					p.recordCall(callResult)
 				}
 			}
 		}
 
+		for _, target := range p.tcpProbes {
+			p.tcpProbe(target)
+		}
+
 		log.Info("HTTP probe done.")
 
 		if p.printState {
-			p.xc.Out.Info("http.probe.summary",
-				ovars{
-					"total":      p.CallCount,
-					"failures":   p.ErrCount,
-					"successful": p.OkCount,
-				})
+			summary := ovars{
+				"total":      p.CallCount,
+				"failures":   p.ErrCount,
+				"successful": p.OkCount,
+			}
+
+			if p.AssertionErrCount > 0 {
+				summary["assertion.failures"] = p.AssertionErrCount
+			}
+
+			p.xc.Out.Info("http.probe.summary", summary)
 
 			outVars := ovars{}
 			//warning := ""
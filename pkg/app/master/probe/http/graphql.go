@@ -0,0 +1,594 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/probediff"
+)
+
+const (
+	graphQLDetectQuery = `{"query":"{ __typename }"}`
+	//the introspection call result resource field
+	graphQLSchemaField = "__schema"
+	//max nesting level for the generated input object arguments
+	maxGraphQLInputDepth = 4
+	//max number of leaf fields in the generated selection sets
+	maxGraphQLSelectionFields = 10
+	maxGraphQLResponseSize    = 16 * 1024 * 1024
+)
+
+// GraphQL operation types
+const (
+	GraphQLOpQuery    = "query"
+	GraphQLOpMutation = "mutation"
+)
+
+// GraphQL type kinds
+const (
+	graphQLKindScalar      = "SCALAR"
+	graphQLKindObject      = "OBJECT"
+	graphQLKindInterface   = "INTERFACE"
+	graphQLKindUnion       = "UNION"
+	graphQLKindEnum        = "ENUM"
+	graphQLKindInputObject = "INPUT_OBJECT"
+	graphQLKindList        = "LIST"
+	graphQLKindNonNull     = "NON_NULL"
+)
+
+// DefaultGraphQLEndpoints are the endpoint paths used to detect the GraphQL endpoint
+var DefaultGraphQLEndpoints = []string{
+	"/graphql",
+	"/api/graphql",
+	"/graphql/api",
+	"/v1/graphql",
+	"/query",
+	"/gql",
+}
+
+const graphQLIntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    types {
+      kind
+      name
+      fields(includeDeprecated: true) {
+        name
+        args { name type { ...TypeRef } defaultValue }
+        type { ...TypeRef }
+      }
+      inputFields { name type { ...TypeRef } defaultValue }
+      enumValues(includeDeprecated: true) { name }
+    }
+  }
+}
+
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name
+  ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+type graphQLTypeRef struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name"`
+	OfType *graphQLTypeRef `json:"ofType"`
+}
+
+// namedType returns the type without the list and non-null wrappers
+func (ref *graphQLTypeRef) namedType() *graphQLTypeRef {
+	current := ref
+	for current != nil && current.OfType != nil &&
+		(current.Kind == graphQLKindList || current.Kind == graphQLKindNonNull) {
+		current = current.OfType
+	}
+
+	return current
+}
+
+type graphQLInputValue struct {
+	Name         string          `json:"name"`
+	Type         *graphQLTypeRef `json:"type"`
+	DefaultValue *string         `json:"defaultValue"`
+}
+
+func (ref *graphQLInputValue) isRequired() bool {
+	return ref.Type != nil && ref.Type.Kind == graphQLKindNonNull && ref.DefaultValue == nil
+}
+
+type graphQLField struct {
+	Name string               `json:"name"`
+	Args []*graphQLInputValue `json:"args"`
+	Type *graphQLTypeRef      `json:"type"`
+}
+
+type graphQLType struct {
+	Kind        string               `json:"kind"`
+	Name        string               `json:"name"`
+	Fields      []*graphQLField      `json:"fields"`
+	InputFields []*graphQLInputValue `json:"inputFields"`
+	EnumValues  []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+type graphQLSchema struct {
+	QueryType *struct {
+		Name string `json:"name"`
+	} `json:"queryType"`
+	MutationType *struct {
+		Name string `json:"name"`
+	} `json:"mutationType"`
+	Types []*graphQLType `json:"types"`
+
+	typeIndex map[string]*graphQLType
+}
+
+func (ref *graphQLSchema) typeByName(name string) *graphQLType {
+	if ref.typeIndex == nil {
+		ref.typeIndex = map[string]*graphQLType{}
+		for _, t := range ref.Types {
+			ref.typeIndex[t.Name] = t
+		}
+	}
+
+	return ref.typeIndex[name]
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage   `json:"data"`
+	Errors []json.RawMessage `json:"errors"`
+}
+
+// graphQLOperation is a generated GraphQL operation for one root field
+type graphQLOperation struct {
+	opType string
+	field  string
+	query  string
+}
+
+// graphQLOperations generates one operation for each root query field
+// (and for each mutation field if the mutations are enabled)
+func graphQLOperations(schema *graphQLSchema, withMutations bool) []*graphQLOperation {
+	var ops []*graphQLOperation
+	add := func(opType, typeName string) {
+		rootType := schema.typeByName(typeName)
+		if rootType == nil {
+			return
+		}
+
+		for _, field := range rootType.Fields {
+			if strings.HasPrefix(field.Name, "__") {
+				continue
+			}
+
+			ops = append(ops, &graphQLOperation{
+				opType: opType,
+				field:  field.Name,
+				query:  fmt.Sprintf("%s { %s }", opType, graphQLFieldQuery(schema, field)),
+			})
+		}
+	}
+
+	if schema.QueryType != nil {
+		add(GraphQLOpQuery, schema.QueryType.Name)
+	}
+
+	if withMutations && schema.MutationType != nil {
+		add(GraphQLOpMutation, schema.MutationType.Name)
+	}
+
+	return ops
+}
+
+// graphQLFieldQuery returns the field query with the placeholder arguments
+// and the minimal selection set
+func graphQLFieldQuery(schema *graphQLSchema, field *graphQLField) string {
+	var query strings.Builder
+	query.WriteString(field.Name)
+
+	var args []string
+	for _, arg := range field.Args {
+		if !arg.isRequired() {
+			continue
+		}
+
+		args = append(args, fmt.Sprintf("%s: %s", arg.Name, graphQLValue(schema, arg.Type, 0)))
+	}
+
+	if len(args) > 0 {
+		query.WriteString("(")
+		query.WriteString(strings.Join(args, ", "))
+		query.WriteString(")")
+	}
+
+	if selection := graphQLSelection(schema, field.Type); selection != "" {
+		query.WriteString(" ")
+		query.WriteString(selection)
+	}
+
+	return query.String()
+}
+
+// graphQLSelection returns the minimal selection set for the field type
+// (the scalar and enum fields without required arguments; '__typename' if there are none)
+func graphQLSelection(schema *graphQLSchema, typeRef *graphQLTypeRef) string {
+	named := typeRef.namedType()
+	if named == nil {
+		return ""
+	}
+
+	switch named.Kind {
+	case graphQLKindObject, graphQLKindInterface:
+	case graphQLKindUnion:
+		return "{ __typename }"
+	default:
+		return ""
+	}
+
+	var fields []string
+	if objType := schema.typeByName(named.Name); objType != nil {
+		for _, field := range objType.Fields {
+			if len(fields) == maxGraphQLSelectionFields {
+				break
+			}
+
+			fieldType := field.Type.namedType()
+			if fieldType == nil ||
+				(fieldType.Kind != graphQLKindScalar && fieldType.Kind != graphQLKindEnum) {
+				continue
+			}
+
+			var hasRequiredArgs bool
+			for _, arg := range field.Args {
+				if arg.isRequired() {
+					hasRequiredArgs = true
+					break
+				}
+			}
+
+			if !hasRequiredArgs {
+				fields = append(fields, field.Name)
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		fields = append(fields, "__typename")
+	}
+
+	return fmt.Sprintf("{ %s }", strings.Join(fields, " "))
+}
+
+// graphQLValue returns the placeholder value literal for the input type
+func graphQLValue(schema *graphQLSchema, typeRef *graphQLTypeRef, depth int) string {
+	if typeRef == nil {
+		return "null"
+	}
+
+	switch typeRef.Kind {
+	case graphQLKindNonNull:
+		return graphQLValue(schema, typeRef.OfType, depth)
+	case graphQLKindList:
+		return fmt.Sprintf("[%s]", graphQLValue(schema, typeRef.OfType, depth))
+	case graphQLKindEnum:
+		if enumType := schema.typeByName(typeRef.Name); enumType != nil && len(enumType.EnumValues) > 0 {
+			return enumType.EnumValues[0].Name
+		}
+
+		return "null"
+	case graphQLKindInputObject:
+		inputType := schema.typeByName(typeRef.Name)
+		if inputType == nil || depth > maxGraphQLInputDepth {
+			return "{}"
+		}
+
+		var fields []string
+		for _, field := range inputType.InputFields {
+			if !field.isRequired() {
+				continue
+			}
+
+			fields = append(fields, fmt.Sprintf("%s: %s", field.Name, graphQLValue(schema, field.Type, depth+1)))
+		}
+
+		if len(fields) == 0 {
+			return "{}"
+		}
+
+		return fmt.Sprintf("{ %s }", strings.Join(fields, ", "))
+	default:
+		return graphQLScalarValue(typeRef.Name)
+	}
+}
+
+func graphQLScalarValue(name string) string {
+	lowered := strings.ToLower(name)
+	switch {
+	case name == "Int":
+		return "1"
+	case name == "Float":
+		return "1.0"
+	case name == "Boolean":
+		return "true"
+	case name == "ID":
+		return `"1"`
+	case strings.Contains(lowered, "datetime") || strings.Contains(lowered, "timestamp"):
+		return `"2024-01-01T00:00:00Z"`
+	case strings.Contains(lowered, "date"):
+		return `"2024-01-01"`
+	case strings.Contains(lowered, "email"):
+		return `"user@example.com"`
+	case strings.Contains(lowered, "uuid"):
+		return `"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+	case strings.Contains(lowered, "url") || strings.Contains(lowered, "uri"):
+		return `"http://example.com"`
+	case strings.Contains(lowered, "json"):
+		return "{}"
+	case strings.Contains(lowered, "int") || strings.Contains(lowered, "long"):
+		return "1"
+	default:
+		return `"string"`
+	}
+}
+
+// graphQLCall makes a GraphQL call (the body is the GraphQL request JSON)
+func graphQLCall(client *http.Client, endpoint string, body []byte) (int, *graphQLResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxGraphQLResponseSize))
+	if err != nil {
+		return res.StatusCode, nil, err
+	}
+
+	var response graphQLResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		//not a GraphQL response
+		return res.StatusCode, nil, nil
+	}
+
+	if response.Data == nil && response.Errors == nil {
+		return res.StatusCode, nil, nil
+	}
+
+	return res.StatusCode, &response, nil
+}
+
+// newGraphQLCallResult returns the normalized call result for the GraphQL call
+// (the resource is the endpoint path and the root field, e.g., '/graphql#users';
+// the body shape has the 'data' and 'errors' response fields)
+func newGraphQLCallResult(
+	proto string,
+	containerPort string,
+	endpointPath string,
+	opType string,
+	field string,
+	statusCode int,
+	response *graphQLResponse,
+	callErr error) *probediff.CallResult {
+	result := &probediff.CallResult{
+		Protocol: proto,
+		Port:     containerPort,
+		Method:   opType,
+		Resource: fmt.Sprintf("%s#%s", endpointPath, field),
+	}
+
+	if callErr != nil {
+		result.Status = probediff.StatusError
+		result.Error = callErr.Error()
+		return result
+	}
+
+	result.Status = strconv.Itoa(statusCode)
+	if response != nil {
+		if data, err := json.Marshal(response); err == nil {
+			result.ContentType = "application/json"
+			result.BodySize = int64(len(data))
+			result.BodyShape, result.BodyHash = probediff.NormalizeBody(result.ContentType, data)
+		}
+	}
+
+	return result
+}
+
+func graphQLRequestBody(query string) []byte {
+	data, _ := json.Marshal(map[string]string{"query": query})
+	return data
+}
+
+// probeGraphQL detects the GraphQL endpoint, loads its introspection schema
+// and runs the generated operations
+func (p *CustomProbe) probeGraphQL(proto, targetHost, port string) {
+	if !p.opts.GraphQL {
+		return
+	}
+
+	addr := getHTTPAddr(proto, targetHost, port)
+	client, err := getHTTPClient(proto)
+	if err != nil {
+		p.xc.Out.Error("HTTP probe - construct client error - %v", err.Error())
+		return
+	}
+
+	endpoints := p.opts.GraphQLEndpoints
+	if len(endpoints) == 0 {
+		endpoints = DefaultGraphQLEndpoints
+	}
+
+	for _, endpointPath := range endpoints {
+		endpoint := fmt.Sprintf("%s%s", addr, endpointPath)
+		statusCode, response, err := graphQLCall(client, endpoint, []byte(graphQLDetectQuery))
+		p.CallCount++
+		if err != nil {
+			p.ErrCount++
+			log.Debugf("HTTP probe - graphql detect error (%s) - %v", endpoint, err)
+			continue
+		}
+
+		p.OkCount++
+		if response == nil {
+			log.Debugf("HTTP probe - not a graphql endpoint (%s) - status=%d", endpoint, statusCode)
+			continue
+		}
+
+		if p.printState {
+			p.xc.Out.Info("http.probe.graphql.endpoint",
+				ovars{
+					"endpoint": endpoint,
+					"status":   statusCode,
+				})
+		}
+
+		p.probeGraphQLEndpoint(client, proto, port, endpointPath, endpoint)
+		//one GraphQL endpoint per target is typical
+		return
+	}
+}
+
+func (p *CustomProbe) probeGraphQLEndpoint(client *http.Client, proto, port, endpointPath, endpoint string) {
+	statusCode, response, err := graphQLCall(client, endpoint, graphQLRequestBody(graphQLIntrospectionQuery))
+	p.CallCount++
+	p.recordCall(newGraphQLCallResult(proto, p.containerPort(port), endpointPath,
+		GraphQLOpQuery, graphQLSchemaField, statusCode, response, err))
+	if err != nil {
+		p.ErrCount++
+		p.xc.Out.Info("http.probe.graphql.introspection.error",
+			ovars{
+				"endpoint": endpoint,
+				"error":    err,
+			})
+		return
+	}
+
+	p.OkCount++
+
+	var schema *graphQLSchema
+	if response != nil && response.Data != nil {
+		var data struct {
+			Schema *graphQLSchema `json:"__schema"`
+		}
+
+		if err := json.Unmarshal(response.Data, &data); err == nil {
+			schema = data.Schema
+		}
+	}
+
+	if schema == nil {
+		p.xc.Out.Info("http.probe.graphql.introspection.error",
+			ovars{
+				"endpoint": endpoint,
+				"status":   statusCode,
+				"message":  "introspection is not available",
+			})
+		return
+	}
+
+	ops := graphQLOperations(schema, p.opts.GraphQLMutations)
+	sort.SliceStable(ops, func(i, j int) bool {
+		//the queries first
+		return ops[i].opType == GraphQLOpQuery && ops[j].opType != GraphQLOpQuery
+	})
+
+	if p.printState {
+		p.xc.Out.State("http.probe.graphql.probe.starting",
+			ovars{
+				"endpoint":   endpoint,
+				"operations": len(ops),
+			})
+	}
+
+	for _, op := range ops {
+		p.graphQLOperationCall(client, proto, port, endpointPath, endpoint, op)
+	}
+}
+
+// graphQLOperationCall runs the operation and records the last call result
+func (p *CustomProbe) graphQLOperationCall(
+	client *http.Client,
+	proto string,
+	port string,
+	endpointPath string,
+	endpoint string,
+	op *graphQLOperation) {
+	maxRetryCount := probeRetryCount
+	if p.opts.RetryCount > 0 {
+		maxRetryCount = p.opts.RetryCount
+	}
+
+	webErrorWait := time.Duration(8)
+	if p.opts.RetryWait > 0 {
+		webErrorWait = time.Duration(p.opts.RetryWait)
+	}
+
+	body := graphQLRequestBody(op.query)
+	var callResult *probediff.CallResult
+	for i := 0; i < maxRetryCount; i++ {
+		statusCode, response, err := graphQLCall(client, endpoint, body)
+		p.CallCount++
+
+		callResult = newGraphQLCallResult(proto, p.containerPort(port), endpointPath,
+			op.opType, op.field, statusCode, response, err)
+
+		status := "error"
+		callErrorStr := "none"
+		var gqlErrors int
+		if err == nil {
+			status = fmt.Sprintf("%v", statusCode)
+			if response != nil {
+				gqlErrors = len(response.Errors)
+			}
+		} else {
+			callErrorStr = err.Error()
+		}
+
+		if p.printState {
+			p.xc.Out.Info("http.probe.graphql.call",
+				ovars{
+					"status":   status,
+					"type":     op.opType,
+					"field":    op.field,
+					"endpoint": endpoint,
+					"errors":   gqlErrors,
+					"attempt":  i + 1,
+					"error":    callErrorStr,
+					"time":     time.Now().UTC().Format(time.RFC3339),
+				})
+		}
+
+		if err == nil {
+			//the GraphQL errors are still successful probe calls (the server ran the operation)
+			p.OkCount++
+			p.recordCall(callResult)
+			return
+		}
+
+		p.ErrCount++
+		log.Debugf("HTTP probe - graphql call error... retry again later...")
+		time.Sleep(webErrorWait * time.Second)
+	}
+
+	p.recordCall(callResult)
+}
@@ -0,0 +1,305 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+)
+
+// testGraphQLSchemaData is the introspection query data for the test schema
+const testGraphQLSchemaData = `{"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": {"name": "Mutation"},
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "args": [
+        {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+      ], "type": {"kind": "OBJECT", "name": "User"}},
+      {"name": "users", "args": [
+        {"name": "filter", "type": {"kind": "INPUT_OBJECT", "name": "UserFilter"}},
+        {"name": "first", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Int"}}, "defaultValue": "10"}
+      ], "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "OBJECT", "name": "User"}}}}},
+      {"name": "search", "args": [
+        {"name": "term", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+      ], "type": {"kind": "UNION", "name": "SearchResult"}},
+      {"name": "version", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "__debug", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+    ]},
+    {"kind": "OBJECT", "name": "Mutation", "fields": [
+      {"name": "createUser", "args": [
+        {"name": "input", "type": {"kind": "NON_NULL", "ofType": {"kind": "INPUT_OBJECT", "name": "CreateUserInput"}}}
+      ], "type": {"kind": "OBJECT", "name": "User"}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}},
+      {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "role", "args": [], "type": {"kind": "ENUM", "name": "Role"}},
+      {"name": "posts", "args": [
+        {"name": "first", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Int"}}}
+      ], "type": {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "String"}}},
+      {"name": "friends", "args": [], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "User"}}}
+    ]},
+    {"kind": "UNION", "name": "SearchResult"},
+    {"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "MEMBER"}]},
+    {"kind": "INPUT_OBJECT", "name": "UserFilter", "inputFields": [
+      {"name": "name", "type": {"kind": "SCALAR", "name": "String"}}
+    ]},
+    {"kind": "INPUT_OBJECT", "name": "CreateUserInput", "inputFields": [
+      {"name": "name", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}},
+      {"name": "email", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "EmailAddress"}}},
+      {"name": "role", "type": {"kind": "NON_NULL", "ofType": {"kind": "ENUM", "name": "Role"}}},
+      {"name": "tags", "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}}},
+      {"name": "createdAt", "type": {"kind": "SCALAR", "name": "DateTime"}},
+      {"name": "manager", "type": {"kind": "INPUT_OBJECT", "name": "CreateUserInput"}}
+    ]}
+  ]
+}}`
+
+func testGraphQLSchema(t *testing.T) *graphQLSchema {
+	var data struct {
+		Schema *graphQLSchema `json:"__schema"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(testGraphQLSchemaData), &data))
+	return data.Schema
+}
+
+func TestGraphQLOperations(t *testing.T) {
+	schema := testGraphQLSchema(t)
+
+	queries := []string{
+		`query { user(id: "1") { id name role } }`,
+		`query { users { id name role } }`,
+		`query { search(term: "string") { __typename } }`,
+		`query { version }`,
+	}
+
+	var result []string
+	for _, op := range graphQLOperations(schema, false) {
+		assert.Equal(t, GraphQLOpQuery, op.opType)
+		result = append(result, op.query)
+	}
+
+	assert.Equal(t, queries, result)
+
+	ops := graphQLOperations(schema, true)
+	require.Len(t, ops, len(queries)+1)
+	mutation := ops[len(ops)-1]
+	assert.Equal(t, GraphQLOpMutation, mutation.opType)
+	assert.Equal(t, "createUser", mutation.field)
+	assert.Equal(t,
+		`mutation { createUser(input: { name: "string", email: "user@example.com", role: ADMIN, tags: ["string"] }) { id name role } }`,
+		mutation.query)
+
+	//no root types
+	assert.Empty(t, graphQLOperations(&graphQLSchema{}, true))
+}
+
+func TestGraphQLValue(t *testing.T) {
+	schema := testGraphQLSchema(t)
+
+	tt := []struct {
+		typeRef  *graphQLTypeRef
+		expected string
+	}{
+		{typeRef: nil, expected: "null"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "Int"}, expected: "1"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "Float"}, expected: "1.0"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "Boolean"}, expected: "true"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "ID"}, expected: `"1"`},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "DateTime"}, expected: `"2024-01-01T00:00:00Z"`},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "Date"}, expected: `"2024-01-01"`},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "UUID"}, expected: `"3fa85f64-5717-4562-b3fc-2c963f66afa6"`},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "URL"}, expected: `"http://example.com"`},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "JSON"}, expected: "{}"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "BigInt"}, expected: "1"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "String"}, expected: `"string"`},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindEnum, Name: "Role"}, expected: "ADMIN"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindEnum, Name: "Missing"}, expected: "null"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindInputObject, Name: "UserFilter"}, expected: "{}"},
+		{typeRef: &graphQLTypeRef{Kind: graphQLKindInputObject, Name: "Missing"}, expected: "{}"},
+		{
+			typeRef: &graphQLTypeRef{
+				Kind:   graphQLKindList,
+				OfType: &graphQLTypeRef{Kind: graphQLKindNonNull, OfType: &graphQLTypeRef{Kind: graphQLKindScalar, Name: "Int"}},
+			},
+			expected: "[1]",
+		},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, graphQLValue(schema, test.typeRef, 0))
+	}
+
+	//the nested input objects are limited
+	assert.Equal(t, "{}", graphQLValue(schema, &graphQLTypeRef{Kind: graphQLKindInputObject, Name: "CreateUserInput"}, maxGraphQLInputDepth+1))
+}
+
+func TestGraphQLCall(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req["query"], "__typename") {
+			w.Write([]byte(`{"data":{"__typename":"Query"}}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errors":[{"message":"unknown field"}]}`))
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html></html>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := getHTTP1Client()
+
+	statusCode, response, err := graphQLCall(client, server.URL+"/graphql", []byte(graphQLDetectQuery))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	require.NotNil(t, response)
+	assert.JSONEq(t, `{"__typename":"Query"}`, string(response.Data))
+	assert.Empty(t, response.Errors)
+
+	statusCode, response, err = graphQLCall(client, server.URL+"/graphql", graphQLRequestBody("query { other }"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+	require.NotNil(t, response)
+	assert.Nil(t, response.Data)
+	assert.Len(t, response.Errors, 1)
+
+	//not GraphQL endpoints
+	for _, endpointPath := range []string{"/json", "/html", "/other"} {
+		_, response, err = graphQLCall(client, server.URL+endpointPath, []byte(graphQLDetectQuery))
+		require.NoError(t, err)
+		assert.Nil(t, response, endpointPath)
+	}
+
+	_, _, err = graphQLCall(client, "http://127.0.0.1:0/graphql", []byte(graphQLDetectQuery))
+	assert.Error(t, err)
+}
+
+func TestProbeGraphQL(t *testing.T) {
+	var lock sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		lock.Lock()
+		queries = append(queries, req["query"])
+		lock.Unlock()
+
+		switch {
+		case req["query"] == graphQLIntrospectionQuery:
+			w.Write([]byte(`{"data":` + testGraphQLSchemaData + `}`))
+		case strings.HasPrefix(req["query"], GraphQLOpMutation):
+			w.Write([]byte(`{"errors":[{"message":"not allowed"}]}`))
+		default:
+			w.Write([]byte(`{"data":{}}`))
+		}
+	}))
+	defer server.Close()
+
+	addr, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	p := &CustomProbe{
+		opts: config.HTTPProbeOptions{
+			GraphQL:          true,
+			GraphQLMutations: true,
+			GraphQLEndpoints: []string{"/graphql", "/api/graphql"},
+		},
+	}
+
+	p.probeGraphQL(config.ProtoHTTP, addr.Hostname(), addr.Port())
+
+	//the detect calls, the introspection call and the operations (the queries first)
+	require.Len(t, queries, 7)
+	assert.Equal(t, `{ __typename }`, queries[0])
+	assert.Equal(t, graphQLIntrospectionQuery, queries[1])
+	assert.True(t, strings.HasPrefix(queries[2], GraphQLOpQuery))
+	assert.True(t, strings.HasPrefix(queries[6], GraphQLOpMutation))
+	assert.Equal(t, uint64(8), p.CallCount)
+	assert.Equal(t, uint64(8), p.OkCount)
+	assert.Zero(t, p.ErrCount)
+
+	//the introspection and the operation calls are recorded
+	var keys []string
+	for _, result := range p.CallResults() {
+		keys = append(keys, result.Key)
+	}
+
+	assert.Equal(t, []string{
+		"query http:" + addr.Port() + "/api/graphql#__schema",
+		"query http:" + addr.Port() + "/api/graphql#user",
+		"query http:" + addr.Port() + "/api/graphql#users",
+		"query http:" + addr.Port() + "/api/graphql#search",
+		"query http:" + addr.Port() + "/api/graphql#version",
+		"mutation http:" + addr.Port() + "/api/graphql#createUser",
+	}, keys)
+
+	//disabled
+	queries = nil
+	p = &CustomProbe{}
+	p.probeGraphQL(config.ProtoHTTP, addr.Hostname(), addr.Port())
+	assert.Empty(t, queries)
+	assert.Zero(t, p.CallCount)
+}
+
+func TestNewGraphQLCallResult(t *testing.T) {
+	response := &graphQLResponse{
+		Data:   json.RawMessage(`{"users":[{"id":"1","name":"a"}]}`),
+		Errors: []json.RawMessage{json.RawMessage(`{"message":"partial","path":["users",0,"email"]}`)},
+	}
+
+	result := newGraphQLCallResult("http", "8080", "/graphql", GraphQLOpQuery, "users", 200, response, nil)
+	assert.Equal(t, "http", result.Protocol)
+	assert.Equal(t, "8080", result.Port)
+	assert.Equal(t, GraphQLOpQuery, result.Method)
+	assert.Equal(t, "/graphql#users", result.Resource)
+	assert.Equal(t, "200", result.Status)
+	assert.Equal(t, "application/json", result.ContentType)
+	assert.Equal(t, `{"data":{"users":[{"id":string,"name":string}]},"errors":[{"message":string,"path":[string]}]}`, result.BodyShape)
+	assert.NotEmpty(t, result.BodyHash)
+
+	//not a GraphQL response
+	result = newGraphQLCallResult("http", "8080", "/graphql", GraphQLOpMutation, "createUser", 404, nil, nil)
+	assert.Equal(t, "404", result.Status)
+	assert.Empty(t, result.ContentType)
+	assert.Empty(t, result.BodyShape)
+
+	result = newGraphQLCallResult("http", "8080", "/graphql", GraphQLOpQuery, graphQLSchemaField, 0, nil, errors.New("connection refused"))
+	assert.True(t, result.Failed())
+	assert.Equal(t, "connection refused", result.Error)
+	assert.Equal(t, "/graphql#__schema", result.Resource)
+}
@@ -0,0 +1,399 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/probediff"
+)
+
+const (
+	defaultGRPCCallTimeout   = 10 * time.Second
+	defaultGRPCStreamTimeout = 2 * time.Second
+	grpcReflectionPackage    = "grpc.reflection."
+	grpcStatusStreamTimeout  = "stream.timeout"
+)
+
+// gRPC method types
+const (
+	GRPCMethodUnary        = "unary"
+	GRPCMethodServerStream = "server.stream"
+	GRPCMethodClientStream = "client.stream"
+	GRPCMethodBidiStream   = "bidi.stream"
+)
+
+type grpcProbeMethod struct {
+	name       string //'package.Service/Method'
+	methodType string
+	desc       protoreflect.MethodDescriptor
+}
+
+func grpcMethodType(md protoreflect.MethodDescriptor) string {
+	switch {
+	case md.IsStreamingClient() && md.IsStreamingServer():
+		return GRPCMethodBidiStream
+	case md.IsStreamingClient():
+		return GRPCMethodClientStream
+	case md.IsStreamingServer():
+		return GRPCMethodServerStream
+	default:
+		return GRPCMethodUnary
+	}
+}
+
+// grpcProbeMethods returns the service methods selected by the probe command resource
+// ('/' for all services, '/package.Service' or '/package.Service/Method')
+// (the server reflection services are not probed)
+func grpcProbeMethods(files *protoregistry.Files, services []string, resource string) []*grpcProbeMethod {
+	selector := strings.Trim(resource, "/")
+	var methods []*grpcProbeMethod
+	for _, service := range withoutReflectionServices(services) {
+		if selector != "" && selector != service && !strings.HasPrefix(selector, service+"/") {
+			continue
+		}
+
+		desc, err := files.FindDescriptorByName(protoreflect.FullName(service))
+		if err != nil {
+			log.Debugf("HTTP probe - grpc service descriptor (%s) error - %v", service, err)
+			continue
+		}
+
+		sd, ok := desc.(protoreflect.ServiceDescriptor)
+		if !ok {
+			continue
+		}
+
+		for i := 0; i < sd.Methods().Len(); i++ {
+			md := sd.Methods().Get(i)
+			name := fmt.Sprintf("%s/%s", service, md.Name())
+			if selector != "" && selector != service && selector != name {
+				continue
+			}
+
+			methods = append(methods, &grpcProbeMethod{
+				name:       name,
+				methodType: grpcMethodType(md),
+				desc:       md,
+			})
+		}
+	}
+
+	return methods
+}
+
+func withoutReflectionServices(services []string) []string {
+	var filtered []string
+	for _, service := range services {
+		if !strings.HasPrefix(service, grpcReflectionPackage) {
+			filtered = append(filtered, service)
+		}
+	}
+
+	return filtered
+}
+
+// grpcFileServices returns the service names from the file descriptors
+func grpcFileServices(files *protoregistry.Files) []string {
+	var services []string
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len(); i++ {
+			services = append(services, string(fd.Services().Get(i).FullName()))
+		}
+
+		return true
+	})
+
+	sort.Strings(services)
+	return services
+}
+
+// grpcRequestMessage returns the example request message for the method
+// (or the default/empty message if there's no example)
+func grpcRequestMessage(cfg *config.GRPCProbeConfig, method *grpcProbeMethod) []byte {
+	if cfg == nil {
+		return []byte{}
+	}
+
+	example, found := cfg.Messages[method.name]
+	if !found {
+		example, found = cfg.Messages["/"+method.name]
+	}
+
+	if !found || method.desc.Input().IsPlaceholder() {
+		return []byte{}
+	}
+
+	msg, err := EncodeGRPCMessage(method.desc.Input(), example)
+	if err != nil {
+		log.Debugf("HTTP probe - grpc example message (%s) error - %v", method.name, err)
+		return []byte{}
+	}
+
+	return msg
+}
+
+// newGRPCCallResult returns the normalized call result for the method call
+// (the body is the first response message decoded to JSON; the command expectations
+// are checked using the gRPC status code, the response metadata and the body)
+func newGRPCCallResult(
+	cmd config.HTTPProbeCmd,
+	proto string,
+	containerPort string,
+	method *grpcProbeMethod,
+	res *GRPCCallResult,
+	callErr error) *probediff.CallResult {
+	result := &probediff.CallResult{
+		Protocol: proto,
+		Port:     containerPort,
+		Resource: "/" + method.name,
+	}
+
+	if res == nil || callErr != nil {
+		result.Status = probediff.StatusError
+		if callErr != nil {
+			result.Error = callErr.Error()
+		}
+
+		return result
+	}
+
+	result.Status = res.StatusName()
+	if res.TimedOut {
+		result.Status = grpcStatusStreamTimeout
+	}
+
+	var body []byte
+	if len(res.Messages) > 0 && !method.desc.Output().IsPlaceholder() {
+		msg, err := DecodeGRPCMessage(method.desc.Output(), res.Messages[0])
+		if err == nil {
+			body, _ = json.Marshal(msg)
+		} else {
+			log.Debugf("HTTP probe - grpc response message (%s) error - %v", method.name, err)
+		}
+	}
+
+	for _, msg := range res.Messages {
+		result.BodySize += int64(len(msg))
+	}
+
+	result.BodyShape, result.BodyHash = probediff.NormalizeBody("application/json", body)
+	if cmd.Expect != nil {
+		result.Assertions = checkExpectations(cmd.Expect, res.Status, res.Metadata, body)
+	}
+
+	return result
+}
+
+func newGRPCProbeClient(cmd config.HTTPProbeCmd, proto, targetHost, port string) (*GRPCClient, error) {
+	gc, err := NewGRPCClient(proto, targetHost, port)
+	if err != nil {
+		return nil, err
+	}
+
+	//the command headers are the call metadata
+	for _, hline := range cmd.Headers {
+		hparts := strings.SplitN(hline, ":", 2)
+		if len(hparts) != 2 {
+			log.Debugf("ignoring malformed header (%v)", hline)
+			continue
+		}
+
+		gc.Headers.Add(strings.TrimSpace(hparts[0]), strings.TrimSpace(hparts[1]))
+	}
+
+	if (cmd.Username != "") || (cmd.Password != "") {
+		credentials := base64.StdEncoding.EncodeToString([]byte(cmd.Username + ":" + cmd.Password))
+		gc.Headers.Set("Authorization", "Basic "+credentials)
+	}
+
+	return gc, nil
+}
+
+// grpcProbe lists the gRPC services (using the server reflection API or the descriptor set file)
+// and calls their methods (the streams are kept open only for the stream timeout)
+func (p *CustomProbe) grpcProbe(
+	cmd config.HTTPProbeCmd,
+	proto string,
+	port string,
+	maxRetryCount int,
+	notReadyErrorWait time.Duration) {
+	gc, err := newGRPCProbeClient(cmd, proto, p.targetHost, port)
+	if err != nil {
+		log.Debugf("HTTP probe - new grpc client error - %v", err)
+		return
+	}
+
+	cfg := cmd.GRPC
+	if cfg == nil {
+		cfg = &config.GRPCProbeConfig{}
+	}
+
+	callTimeout := defaultGRPCCallTimeout
+	if cfg.CallTimeout > 0 {
+		callTimeout = cfg.CallTimeout
+	}
+
+	streamTimeout := defaultGRPCStreamTimeout
+	if cfg.StreamTimeout > 0 {
+		streamTimeout = cfg.StreamTimeout
+	}
+
+	var files *protoregistry.Files
+	var services []string
+	if cfg.DescriptorSetFile != "" {
+		files, err = LoadGRPCDescriptorSet(cfg.DescriptorSetFile)
+		if err != nil {
+			p.xc.Out.Error("http.probe.grpc.descriptor.set.error", err.Error())
+			return
+		}
+
+		services = grpcFileServices(files)
+	} else {
+		for i := 0; i < maxRetryCount; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+			services, err = gc.ListServices(ctx)
+			if err == nil {
+				services = withoutReflectionServices(services)
+				files, err = gc.ReflectionFiles(ctx, services)
+			}
+
+			cancel()
+			p.CallCount++
+
+			if p.printState {
+				statusCode := "ok"
+				callErrorStr := "none"
+				if err != nil {
+					statusCode = "error"
+					callErrorStr = err.Error()
+				}
+
+				p.xc.Out.Info("http.probe.call.grpc.reflection",
+					ovars{
+						"status":   statusCode,
+						"services": len(services),
+						"target":   gc.Addr,
+						"attempt":  i + 1,
+						"error":    callErrorStr,
+						"time":     time.Now().UTC().Format(time.RFC3339),
+					})
+			}
+
+			if err == nil {
+				p.OkCount++
+				break
+			}
+
+			p.ErrCount++
+			if err == ErrGRPCNoReflection {
+				//no need to retry (use a descriptor set file for these servers)
+				return
+			}
+
+			log.Debugf("HTTP probe - grpc target not ready yet (retry again later) [err=%v]...", err)
+			time.Sleep(notReadyErrorWait * time.Second)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	methods := grpcProbeMethods(files, services, cmd.Resource)
+	if p.printState {
+		p.xc.Out.Info("http.probe.grpc.services",
+			ovars{
+				"target":   gc.Addr,
+				"services": strings.Join(services, ","),
+				"methods":  len(methods),
+			})
+	}
+
+	for _, method := range methods {
+		timeout := callTimeout
+		if method.methodType != GRPCMethodUnary {
+			timeout = streamTimeout
+		}
+
+		if ok := p.grpcCall(cmd, proto, port, gc, method, timeout, maxRetryCount, notReadyErrorWait); !ok {
+			//the target is not reachable anymore
+			return
+		}
+	}
+}
+
+// grpcCall calls the method (retrying only if the call fails before the server responds)
+// and records the last call result
+func (p *CustomProbe) grpcCall(
+	cmd config.HTTPProbeCmd,
+	proto string,
+	port string,
+	gc *GRPCClient,
+	method *grpcProbeMethod,
+	timeout time.Duration,
+	maxRetryCount int,
+	notReadyErrorWait time.Duration) bool {
+	request := grpcRequestMessage(cmd.GRPC, method)
+	var callResult *probediff.CallResult
+	for i := 0; i < maxRetryCount; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		result, err := gc.Call(ctx, "/"+method.name, [][]byte{request})
+		cancel()
+		p.CallCount++
+
+		callResult = newGRPCCallResult(cmd, proto, p.containerPort(port), method, result, err)
+
+		if p.printState {
+			statusCode := "error"
+			callErrorStr := "none"
+			var messageCount int
+			if err == nil {
+				statusCode = result.StatusName()
+				if result.TimedOut {
+					statusCode = grpcStatusStreamTimeout
+				}
+
+				messageCount = len(result.Messages)
+			} else {
+				callErrorStr = err.Error()
+			}
+
+			p.xc.Out.Info("http.probe.call.grpc",
+				ovars{
+					"status":   statusCode,
+					"method":   method.name,
+					"type":     method.methodType,
+					"messages": messageCount,
+					"target":   gc.Addr,
+					"attempt":  i + 1,
+					"error":    callErrorStr,
+					"time":     time.Now().UTC().Format(time.RFC3339),
+				})
+		}
+
+		if err == nil {
+			//the gRPC errors are still successful probe calls (the server handled the call)
+			p.OkCount++
+			p.printFailedAssertions(gc.Addr+"/"+method.name, callResult)
+			p.recordCall(callResult)
+			return true
+		}
+
+		p.ErrCount++
+		log.Debugf("HTTP probe - grpc call error (retry again later) [err=%v]...", err)
+		time.Sleep(notReadyErrorWait * time.Second)
+	}
+
+	p.recordCall(callResult)
+	return false
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/probediff"
+)
+
+func TestGRPCProbeMethods(t *testing.T) {
+	files := testGRPCFiles(t)
+	services := []string{"grpc.reflection.v1.ServerReflection", "test.Items", "test.Missing"}
+
+	names := func(methods []*grpcProbeMethod) []string {
+		var result []string
+		for _, method := range methods {
+			result = append(result, method.name+":"+method.methodType)
+		}
+
+		return result
+	}
+
+	all := []string{"test.Items/Get:unary", "test.Items/Watch:server.stream"}
+	assert.Equal(t, all, names(grpcProbeMethods(files, services, "/")))
+	assert.Equal(t, all, names(grpcProbeMethods(files, services, "/test.Items")))
+	assert.Equal(t, []string{"test.Items/Watch:server.stream"}, names(grpcProbeMethods(files, services, "/test.Items/Watch")))
+	assert.Empty(t, grpcProbeMethods(files, services, "/test.Other"))
+	assert.Equal(t, []string{"test.Items"}, grpcFileServices(files))
+}
+
+func TestGRPCRequestMessage(t *testing.T) {
+	methods := grpcProbeMethods(testGRPCFiles(t), []string{"test.Items"}, "/test.Items")
+	cfg := &config.GRPCProbeConfig{
+		Messages: map[string]map[string]interface{}{
+			"test.Items/Get":    {"id": 1.0},
+			"/test.Items/Watch": {"other": 1.0},
+		},
+	}
+
+	assert.Equal(t, []byte{0x08, 0x01}, grpcRequestMessage(cfg, methods[0]))
+	//the invalid examples are replaced with the default message
+	assert.Equal(t, []byte{}, grpcRequestMessage(cfg, methods[1]))
+	assert.Equal(t, []byte{}, grpcRequestMessage(nil, methods[0]))
+}
+
+func TestNewGRPCProbeClient(t *testing.T) {
+	cmd := config.HTTPProbeCmd{
+		Headers:  []string{"X-Token: secret", "malformed"},
+		Username: "user",
+		Password: "pass",
+	}
+
+	gc, err := newGRPCProbeClient(cmd, config.ProtoGRPC, "localhost", "50051")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", gc.Headers.Get("X-Token"))
+	assert.Equal(t, "Basic dXNlcjpwYXNz", gc.Headers.Get("Authorization"))
+	assert.Len(t, gc.Headers, 2)
+}
+
+func TestNewGRPCCallResult(t *testing.T) {
+	methods := grpcProbeMethods(testGRPCFiles(t), []string{"test.Items"}, "/test.Items/Get")
+	require.Len(t, methods, 1)
+
+	var msg []byte
+	msg = protowire.AppendTag(msg, 1, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, 42)
+	msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+	msg = protowire.AppendString(msg, "item")
+
+	cmd := config.HTTPProbeCmd{
+		Protocol: config.ProtoGRPC,
+		Resource: "/test.Items",
+		Expect: &config.HTTPProbeExpectation{
+			Status:  []int{GRPCStatusOK},
+			Headers: map[string]string{"X-Version": "^1"},
+			JSON:    map[string]interface{}{"$.id": "42"},
+		},
+	}
+
+	res := &GRPCCallResult{
+		Status:   GRPCStatusOK,
+		Messages: [][]byte{msg, msg},
+		Metadata: http.Header{"X-Version": []string{"1.2"}},
+	}
+
+	result := newGRPCCallResult(cmd, config.ProtoGRPC, "50051", methods[0], res, nil)
+	assert.Equal(t, config.ProtoGRPC, result.Protocol)
+	assert.Equal(t, "50051", result.Port)
+	assert.Empty(t, result.Method)
+	assert.Equal(t, "/test.Items/Get", result.Resource)
+	assert.Equal(t, "OK", result.Status)
+	assert.Equal(t, int64(2*len(msg)), result.BodySize)
+	assert.Equal(t, `{"displayName":string,"id":string}`, result.BodyShape)
+	assert.NotEmpty(t, result.BodyHash)
+	require.Len(t, result.Assertions, 3)
+	assert.Empty(t, result.FailedAssertions())
+
+	//the gRPC errors are still call results (the status is the gRPC status)
+	res = &GRPCCallResult{Status: GRPCStatusUnimplemented, Metadata: http.Header{}}
+	result = newGRPCCallResult(cmd, config.ProtoGRPC, "50051", methods[0], res, nil)
+	assert.Equal(t, "UNIMPLEMENTED", result.Status)
+	assert.False(t, result.Failed())
+	assert.Empty(t, result.BodyShape)
+	assert.Equal(t, []*probediff.AssertionResult{
+		{Type: AssertionStatus, Expected: "0", Actual: "12"},
+		{Type: AssertionHeader, Target: "X-Version", Expected: "^1", Actual: ""},
+		{Type: AssertionJSONPath, Target: "$.id", Expected: `"42"`, Actual: "(not json)"},
+	}, result.Assertions)
+
+	res = &GRPCCallResult{TimedOut: true, Messages: [][]byte{{0x80}}}
+	result = newGRPCCallResult(config.HTTPProbeCmd{}, config.ProtoGRPC, "50051", methods[0], res, nil)
+	assert.Equal(t, grpcStatusStreamTimeout, result.Status)
+	assert.Empty(t, result.BodyShape)
+	assert.Empty(t, result.Assertions)
+
+	result = newGRPCCallResult(cmd, config.ProtoGRPC, "50051", methods[0], nil, errors.New("connection refused"))
+	assert.True(t, result.Failed())
+	assert.Equal(t, "connection refused", result.Error)
+	assert.Empty(t, result.Assertions)
+}
@@ -0,0 +1,756 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+)
+
+const (
+	grpcContentType       = "application/grpc"
+	grpcFrameHeaderSize   = 5
+	grpcMaxMessageSize    = 16 * 1024 * 1024
+	grpcReflectionV1      = "grpc.reflection.v1.ServerReflection"
+	grpcReflectionV1Alpha = "grpc.reflection.v1alpha.ServerReflection"
+	grpcReflectionMethod  = "ServerReflectionInfo"
+	//max number of reflection calls to resolve the missing file dependencies
+	grpcMaxDependencyCalls = 100
+)
+
+// gRPC status codes
+const (
+	GRPCStatusOK            = 0
+	GRPCStatusUnimplemented = 12
+)
+
+var grpcStatusNames = []string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+var (
+	ErrGRPCMessageTooLarge = errors.New("grpc message is too large")
+	ErrGRPCNoReflection    = errors.New("grpc server reflection is not available")
+)
+
+func IsValidGRPCProto(proto string) bool {
+	switch proto {
+	case config.ProtoGRPC, config.ProtoGRPCS:
+		return true
+	default:
+		return false
+	}
+}
+
+// GRPCClient is a minimal gRPC client
+// (the gRPC message framing on top of the HTTP/2 client; 'grpc' is the cleartext version)
+type GRPCClient struct {
+	Addr    string
+	Headers http.Header
+
+	client            *http.Client
+	reflectionService string
+}
+
+// GRPCCallResult has the gRPC call status and the response messages
+type GRPCCallResult struct {
+	Status   int
+	Message  string
+	Messages [][]byte
+	//Metadata has the response headers and trailers
+	Metadata http.Header
+	//TimedOut is true if the call context expired before the stream ended
+	//(expected for the long running streams)
+	TimedOut bool
+}
+
+// StatusName returns the gRPC status code name
+func (ref *GRPCCallResult) StatusName() string {
+	if ref.Status >= 0 && ref.Status < len(grpcStatusNames) {
+		return grpcStatusNames[ref.Status]
+	}
+
+	return fmt.Sprintf("%d", ref.Status)
+}
+
+func NewGRPCClient(proto, host, port string) (*GRPCClient, error) {
+	if proto == "" {
+		proto = config.ProtoGRPC
+	}
+
+	if !IsValidGRPCProto(proto) {
+		return nil, fmt.Errorf("invalid grpc proto - %s", proto)
+	}
+
+	scheme := config.ProtoHTTP
+	if proto == config.ProtoGRPCS {
+		scheme = config.ProtoHTTPS
+	}
+
+	client := getHTTP2Client(proto == config.ProtoGRPC)
+	//the call deadlines are set using the call contexts
+	client.Timeout = 0
+
+	return &GRPCClient{
+		Addr:    fmt.Sprintf("%s://%s:%s", scheme, host, port),
+		Headers: http.Header{},
+		client:  client,
+	}, nil
+}
+
+// Call calls the method ('/package.Service/Method') with the request messages
+// (the response messages are read until the stream ends or until the context is done)
+func (gc *GRPCClient) Call(ctx context.Context, method string, requests [][]byte) (*GRPCCallResult, error) {
+	var body bytes.Buffer
+	for _, msg := range requests {
+		writeGRPCFrame(&body, msg)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gc.Addr+method, &body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = gc.Headers.Clone()
+	req.Header.Set("Content-Type", grpcContentType)
+	req.Header.Set("TE", "trailers")
+
+	res, err := gc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http status - %d", res.StatusCode)
+	}
+
+	result := &GRPCCallResult{Metadata: http.Header{}}
+	for name, values := range res.Header {
+		result.Metadata[name] = append(result.Metadata[name], values...)
+	}
+
+	for {
+		msg, err := readGRPCFrame(res.Body)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				result.TimedOut = true
+				return result, nil
+			}
+
+			return nil, err
+		}
+
+		result.Messages = append(result.Messages, msg)
+	}
+
+	status := res.Trailer.Get("Grpc-Status")
+	if status == "" {
+		//'trailers-only' responses
+		status = res.Header.Get("Grpc-Status")
+	}
+
+	message := res.Trailer.Get("Grpc-Message")
+	if message == "" {
+		message = res.Header.Get("Grpc-Message")
+	}
+
+	if status == "" {
+		return nil, fmt.Errorf("missing grpc status")
+	}
+
+	result.Status, err = strconv.Atoi(status)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grpc status - %s", status)
+	}
+
+	if unescaped, err := url.PathUnescape(message); err == nil {
+		message = unescaped
+	}
+
+	for name, values := range res.Trailer {
+		result.Metadata[name] = append(result.Metadata[name], values...)
+	}
+
+	result.Message = message
+	return result, nil
+}
+
+func writeGRPCFrame(w *bytes.Buffer, msg []byte) {
+	var header [grpcFrameHeaderSize]byte
+	//no compression
+	binary.BigEndian.PutUint32(header[1:], uint32(len(msg)))
+	w.Write(header[:])
+	w.Write(msg)
+}
+
+func readGRPCFrame(r io.Reader) ([]byte, error) {
+	var header [grpcFrameHeaderSize]byte
+	//io.EOF is returned only if the stream ends before the next message
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > grpcMaxMessageSize {
+		return nil, ErrGRPCMessageTooLarge
+	}
+
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// Server reflection request fields
+const (
+	reflectionFileByFilename       protowire.Number = 3
+	reflectionFileContainingSymbol protowire.Number = 4
+	reflectionListServices         protowire.Number = 7
+)
+
+// Server reflection response fields
+const (
+	reflectionFileDescriptorResponse protowire.Number = 4
+	reflectionListServicesResponse   protowire.Number = 6
+	reflectionErrorResponse          protowire.Number = 7
+)
+
+// reflectionCall makes a server reflection call with one request
+// (the v1 reflection service is tried first, then the v1alpha service)
+func (gc *GRPCClient) reflectionCall(ctx context.Context, field protowire.Number, value string) ([]byte, error) {
+	var request []byte
+	request = protowire.AppendTag(request, field, protowire.BytesType)
+	request = protowire.AppendString(request, value)
+
+	services := []string{grpcReflectionV1, grpcReflectionV1Alpha}
+	if gc.reflectionService != "" {
+		services = []string{gc.reflectionService}
+	}
+
+	for _, service := range services {
+		result, err := gc.Call(ctx, fmt.Sprintf("/%s/%s", service, grpcReflectionMethod), [][]byte{request})
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Status == GRPCStatusUnimplemented {
+			log.Debugf("GRPCClient.reflectionCall: %s is not implemented", service)
+			continue
+		}
+
+		if result.Status != GRPCStatusOK {
+			return nil, fmt.Errorf("grpc reflection error - %s: %s", result.StatusName(), result.Message)
+		}
+
+		if len(result.Messages) == 0 {
+			return nil, fmt.Errorf("grpc reflection error - no response")
+		}
+
+		gc.reflectionService = service
+		response := result.Messages[0]
+		for _, errData := range protoBytesFields(response, reflectionErrorResponse) {
+			var code uint64
+			var message string
+			walkProtoFields(errData, func(num protowire.Number, typ protowire.Type, data []byte, number uint64) {
+				switch {
+				case num == 1 && typ == protowire.VarintType:
+					code = number
+				case num == 2 && typ == protowire.BytesType:
+					message = string(data)
+				}
+			})
+
+			return nil, fmt.Errorf("grpc reflection error - %d: %s", code, message)
+		}
+
+		return response, nil
+	}
+
+	return nil, ErrGRPCNoReflection
+}
+
+// ListServices returns the service names from the server reflection API
+func (gc *GRPCClient) ListServices(ctx context.Context) ([]string, error) {
+	response, err := gc.reflectionCall(ctx, reflectionListServices, "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, listData := range protoBytesFields(response, reflectionListServicesResponse) {
+		for _, serviceData := range protoBytesFields(listData, 1) {
+			for _, name := range protoBytesFields(serviceData, 1) {
+				names = append(names, string(name))
+			}
+		}
+	}
+
+	return names, nil
+}
+
+func (gc *GRPCClient) fileDescriptors(ctx context.Context, field protowire.Number, value string) ([]*descriptorpb.FileDescriptorProto, error) {
+	response, err := gc.reflectionCall(ctx, field, value)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*descriptorpb.FileDescriptorProto
+	for _, fdrData := range protoBytesFields(response, reflectionFileDescriptorResponse) {
+		for _, fdData := range protoBytesFields(fdrData, 1) {
+			var fd descriptorpb.FileDescriptorProto
+			if err := proto.Unmarshal(fdData, &fd); err != nil {
+				return nil, err
+			}
+
+			files = append(files, &fd)
+		}
+	}
+
+	return files, nil
+}
+
+// ReflectionFiles returns the file descriptors for the services from the server reflection API
+// (including the file dependencies)
+func (gc *GRPCClient) ReflectionFiles(ctx context.Context, services []string) (*protoregistry.Files, error) {
+	files := map[string]*descriptorpb.FileDescriptorProto{}
+	addFiles := func(fds []*descriptorpb.FileDescriptorProto) {
+		for _, fd := range fds {
+			if _, found := files[fd.GetName()]; !found {
+				files[fd.GetName()] = fd
+			}
+		}
+	}
+
+	for _, service := range services {
+		fds, err := gc.fileDescriptors(ctx, reflectionFileContainingSymbol, service)
+		if err != nil {
+			return nil, err
+		}
+
+		addFiles(fds)
+	}
+
+	//the servers might not include all dependencies in the responses
+	requested := map[string]struct{}{}
+	for i := 0; i < grpcMaxDependencyCalls; i++ {
+		var missing string
+		for _, fd := range files {
+			for _, dep := range fd.Dependency {
+				if _, found := files[dep]; found {
+					continue
+				}
+
+				if _, found := requested[dep]; !found {
+					missing = dep
+					break
+				}
+			}
+
+			if missing != "" {
+				break
+			}
+		}
+
+		if missing == "" {
+			break
+		}
+
+		requested[missing] = struct{}{}
+		fds, err := gc.fileDescriptors(ctx, reflectionFileByFilename, missing)
+		if err != nil {
+			log.Debugf("GRPCClient.ReflectionFiles: dependency (%s) error - %v", missing, err)
+			continue
+		}
+
+		addFiles(fds)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range files {
+		set.File = append(set.File, fd)
+	}
+
+	return newGRPCFiles(set)
+}
+
+// LoadGRPCDescriptorSet loads the file descriptors from a FileDescriptorSet file
+func LoadGRPCDescriptorSet(filePath string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	return newGRPCFiles(&set)
+}
+
+func newGRPCFiles(set *descriptorpb.FileDescriptorSet) (*protoregistry.Files, error) {
+	//the services can still be called if some of the message dependencies are missing
+	return protodesc.FileOptions{AllowUnresolvable: true}.NewFiles(set)
+}
+
+// protoBytesFields returns the values for the length-delimited fields with the field number
+func protoBytesFields(data []byte, field protowire.Number) [][]byte {
+	var values [][]byte
+	walkProtoFields(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) {
+		if num == field && typ == protowire.BytesType {
+			values = append(values, value)
+		}
+	})
+
+	return values
+}
+
+func walkProtoFields(data []byte, fn func(num protowire.Number, typ protowire.Type, data []byte, number uint64)) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return
+		}
+
+		data = data[n:]
+		switch typ {
+		case protowire.BytesType:
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return
+			}
+
+			fn(num, typ, value, 0)
+			data = data[n:]
+		case protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return
+			}
+
+			fn(num, typ, nil, value)
+			data = data[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return
+			}
+
+			data = data[n:]
+		}
+	}
+}
+
+// EncodeGRPCMessage encodes the message value (JSON object) using the message descriptor
+// (the field names can be the proto names or the JSON names)
+func EncodeGRPCMessage(md protoreflect.MessageDescriptor, value map[string]interface{}) ([]byte, error) {
+	var data []byte
+	fields := md.Fields()
+	for _, name := range sortedKeys(value) {
+		fieldValue := value[name]
+		fd := fields.ByJSONName(name)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(name))
+		}
+
+		if fd == nil {
+			return nil, fmt.Errorf("unknown field %s in %s", name, md.FullName())
+		}
+
+		if fieldValue == nil {
+			continue
+		}
+
+		var err error
+		data, err = appendProtoField(data, fd, fieldValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", md.FullName(), name, err)
+		}
+	}
+
+	return data, nil
+}
+
+func appendProtoField(data []byte, fd protoreflect.FieldDescriptor, value interface{}) ([]byte, error) {
+	switch {
+	case fd.IsMap():
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object for map field")
+		}
+
+		for _, key := range sortedKeys(entries) {
+			entryValue := entries[key]
+			keyValue, err := mapKeyValue(fd.MapKey(), key)
+			if err != nil {
+				return nil, err
+			}
+
+			entry, err := appendProtoValue(nil, fd.MapKey(), keyValue)
+			if err != nil {
+				return nil, err
+			}
+
+			entry, err = appendProtoValue(entry, fd.MapValue(), entryValue)
+			if err != nil {
+				return nil, err
+			}
+
+			data = protowire.AppendTag(data, fd.Number(), protowire.BytesType)
+			data = protowire.AppendBytes(data, entry)
+		}
+
+		return data, nil
+	case fd.IsList():
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected array for repeated field")
+		}
+
+		var err error
+		for _, item := range items {
+			if data, err = appendProtoValue(data, fd, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return data, nil
+	default:
+		return appendProtoValue(data, fd, value)
+	}
+}
+
+func mapKeyValue(fd protoreflect.FieldDescriptor, key string) (interface{}, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return key, nil
+	case protoreflect.BoolKind:
+		return strconv.ParseBool(key)
+	default:
+		//the integer keys are the JSON object keys
+		return key, nil
+	}
+}
+
+func appendProtoValue(data []byte, fd protoreflect.FieldDescriptor, value interface{}) ([]byte, error) {
+	num := fd.Number()
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected bool value")
+		}
+
+		data = protowire.AppendTag(data, num, protowire.VarintType)
+		return protowire.AppendVarint(data, protowire.EncodeBool(flag)), nil
+	case protoreflect.EnumKind:
+		var number int64
+		if name, ok := value.(string); ok {
+			enumValue := fd.Enum().Values().ByName(protoreflect.Name(name))
+			if enumValue == nil {
+				return nil, fmt.Errorf("unknown enum value %s", name)
+			}
+
+			number = int64(enumValue.Number())
+		} else {
+			var err error
+			if number, err = toInt64(value); err != nil {
+				return nil, err
+			}
+		}
+
+		data = protowire.AppendTag(data, num, protowire.VarintType)
+		return protowire.AppendVarint(data, uint64(number)), nil
+	case protoreflect.Int32Kind, protoreflect.Int64Kind,
+		protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		number, err := toInt64(value)
+		if err != nil {
+			return nil, err
+		}
+
+		data = protowire.AppendTag(data, num, protowire.VarintType)
+		return protowire.AppendVarint(data, uint64(number)), nil
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		number, err := toInt64(value)
+		if err != nil {
+			return nil, err
+		}
+
+		data = protowire.AppendTag(data, num, protowire.VarintType)
+		return protowire.AppendVarint(data, protowire.EncodeZigZag(number)), nil
+	case protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind:
+		number, err := toInt64(value)
+		if err != nil {
+			return nil, err
+		}
+
+		data = protowire.AppendTag(data, num, protowire.Fixed32Type)
+		return protowire.AppendFixed32(data, uint32(number)), nil
+	case protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind:
+		number, err := toInt64(value)
+		if err != nil {
+			return nil, err
+		}
+
+		data = protowire.AppendTag(data, num, protowire.Fixed64Type)
+		return protowire.AppendFixed64(data, uint64(number)), nil
+	case protoreflect.FloatKind:
+		number, err := toFloat64(value)
+		if err != nil {
+			return nil, err
+		}
+
+		data = protowire.AppendTag(data, num, protowire.Fixed32Type)
+		return protowire.AppendFixed32(data, math.Float32bits(float32(number))), nil
+	case protoreflect.DoubleKind:
+		number, err := toFloat64(value)
+		if err != nil {
+			return nil, err
+		}
+
+		data = protowire.AppendTag(data, num, protowire.Fixed64Type)
+		return protowire.AppendFixed64(data, math.Float64bits(number)), nil
+	case protoreflect.StringKind:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string value")
+		}
+
+		data = protowire.AppendTag(data, num, protowire.BytesType)
+		return protowire.AppendString(data, str), nil
+	case protoreflect.BytesKind:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected base64 string value")
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			if raw, err = base64.URLEncoding.DecodeString(str); err != nil {
+				return nil, err
+			}
+		}
+
+		data = protowire.AppendTag(data, num, protowire.BytesType)
+		return protowire.AppendBytes(data, raw), nil
+	case protoreflect.MessageKind:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object value")
+		}
+
+		if fd.Message().IsPlaceholder() {
+			return nil, fmt.Errorf("unresolved message type %s", fd.Message().FullName())
+		}
+
+		msg, err := EncodeGRPCMessage(fd.Message(), fields)
+		if err != nil {
+			return nil, err
+		}
+
+		data = protowire.AppendTag(data, num, protowire.BytesType)
+		return protowire.AppendBytes(data, msg), nil
+	default:
+		return nil, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+}
+
+// toInt64 converts the JSON number (the 64-bit integers can also be strings)
+func toInt64(value interface{}) (int64, error) {
+	switch typed := value.(type) {
+	case float64:
+		return int64(typed), nil
+	case int:
+		return int64(typed), nil
+	case int64:
+		return typed, nil
+	case string:
+		return strconv.ParseInt(typed, 10, 64)
+	default:
+		return 0, fmt.Errorf("expected number value")
+	}
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, nil
+	case int:
+		return float64(typed), nil
+	case string:
+		return strconv.ParseFloat(typed, 64)
+	default:
+		return 0, fmt.Errorf("expected number value")
+	}
+}
+
+// DecodeGRPCMessage decodes the message to its JSON object value using the message descriptor
+// (the protobuf JSON mapping: the JSON field names, the 64-bit integers are strings
+// and the fields with the default values are omitted)
+func DecodeGRPCMessage(md protoreflect.MessageDescriptor, data []byte) (map[string]interface{}, error) {
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	jsonData, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var value map[string]interface{}
+	if err := json.Unmarshal(jsonData, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
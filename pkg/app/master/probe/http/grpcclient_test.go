@@ -0,0 +1,619 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+)
+
+func testProtoField(name string, num int32, fieldType descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(num),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   fieldType.Enum(),
+	}
+
+	if typeName != "" {
+		field.TypeName = proto.String(typeName)
+	}
+
+	return field
+}
+
+func testRepeatedProtoField(name string, num int32, fieldType descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := testProtoField(name, num, fieldType, typeName)
+	field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return field
+}
+
+// testGRPCFiles returns the file descriptors for the 'test.Items' service
+func testGRPCFiles(t *testing.T) *protoregistry.Files {
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("ACTIVE"), Number: proto.Int32(1)},
+				},
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					testProtoField("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+					testProtoField("display_name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					testProtoField("status", 3, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".test.Status"),
+					testRepeatedProtoField("codes", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+					testRepeatedProtoField("counts", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.Item.CountsEntry"),
+					testProtoField("data", 6, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+					testProtoField("score", 7, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ""),
+					testProtoField("child", 8, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.Item"),
+					testProtoField("delta", 9, descriptorpb.FieldDescriptorProto_TYPE_SINT32, ""),
+					testProtoField("ok", 10, descriptorpb.FieldDescriptorProto_TYPE_BOOL, ""),
+					testProtoField("ratio", 11, descriptorpb.FieldDescriptorProto_TYPE_FLOAT, ""),
+					testProtoField("checksum", 12, descriptorpb.FieldDescriptorProto_TYPE_FIXED64, ""),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("CountsEntry"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							testProtoField("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+							testProtoField("value", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+						},
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+					},
+				},
+			},
+			{
+				Name: proto.String("GetRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					testProtoField("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("Items"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("Get"),
+						InputType:  proto.String(".test.GetRequest"),
+						OutputType: proto.String(".test.Item"),
+					},
+					{
+						Name:            proto.String("Watch"),
+						InputType:       proto.String(".test.GetRequest"),
+						OutputType:      proto.String(".test.Item"),
+						ServerStreaming: proto.Bool(true),
+					},
+				},
+			},
+		},
+	}
+
+	files, err := newGRPCFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fd}})
+	require.NoError(t, err)
+	return files
+}
+
+func testMessageDescriptor(t *testing.T, name string) protoreflect.MessageDescriptor {
+	desc, err := testGRPCFiles(t).FindDescriptorByName(protoreflect.FullName(name))
+	require.NoError(t, err)
+	return desc.(protoreflect.MessageDescriptor)
+}
+
+// testGRPCClient returns a 'grpcs' client for a test HTTP/2 server with the handler
+func testGRPCClient(t *testing.T, handler http.Handler) *GRPCClient {
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	addr, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	gc, err := NewGRPCClient(config.ProtoGRPCS, addr.Hostname(), addr.Port())
+	require.NoError(t, err)
+	return gc
+}
+
+// writeTestGRPCResponse writes the response messages and the status trailers
+func writeTestGRPCResponse(w http.ResponseWriter, status int, message string, msgs ...[]byte) {
+	w.Header().Set("Content-Type", grpcContentType)
+	w.Header().Add("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+
+	var body bytes.Buffer
+	for _, msg := range msgs {
+		writeGRPCFrame(&body, msg)
+	}
+
+	w.Write(body.Bytes())
+	w.Header().Set("Grpc-Status", strconv.Itoa(status))
+	w.Header().Set("Grpc-Message", message)
+}
+
+func TestGRPCFrames(t *testing.T) {
+	var body bytes.Buffer
+	writeGRPCFrame(&body, []byte("hello"))
+	writeGRPCFrame(&body, []byte{})
+	assert.Equal(t, []byte{0, 0, 0, 0, 5, 'h', 'e', 'l', 'l', 'o', 0, 0, 0, 0, 0}, body.Bytes())
+
+	msg, err := readGRPCFrame(&body)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), msg)
+
+	msg, err = readGRPCFrame(&body)
+	require.NoError(t, err)
+	assert.Empty(t, msg)
+
+	_, err = readGRPCFrame(&body)
+	assert.Equal(t, io.EOF, err)
+
+	tt := []struct {
+		name  string
+		frame []byte
+		err   error
+	}{
+		{name: "truncated header", frame: []byte{0, 0, 0}, err: io.ErrUnexpectedEOF},
+		{name: "truncated message", frame: []byte{0, 0, 0, 0, 5, 'h'}, err: io.ErrUnexpectedEOF},
+		{name: "missing message", frame: []byte{0, 0, 0, 0, 5}, err: io.ErrUnexpectedEOF},
+		{name: "too large", frame: []byte{0, 0xff, 0xff, 0xff, 0xff}, err: ErrGRPCMessageTooLarge},
+	}
+
+	for _, test := range tt {
+		_, err := readGRPCFrame(bytes.NewReader(test.frame))
+		assert.Equal(t, test.err, err, test.name)
+	}
+}
+
+func TestNewGRPCClient(t *testing.T) {
+	gc, err := NewGRPCClient("", "localhost", "50051")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:50051", gc.Addr)
+
+	gc, err = NewGRPCClient(config.ProtoGRPCS, "localhost", "50051")
+	require.NoError(t, err)
+	assert.Equal(t, "https://localhost:50051", gc.Addr)
+
+	_, err = NewGRPCClient(config.ProtoHTTP, "localhost", "50051")
+	assert.Error(t, err)
+}
+
+func TestGRPCClientCall(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/test.Items/Get", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != grpcContentType || r.Header.Get("TE") != "trailers" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		//echoes the request messages
+		var msgs [][]byte
+		for {
+			msg, err := readGRPCFrame(r.Body)
+			if err != nil {
+				break
+			}
+
+			msgs = append(msgs, msg)
+		}
+
+		w.Header().Set("X-Version", "1.2")
+		w.Header().Set("X-Token", r.Header.Get("X-Token"))
+		w.Header().Set("Trailer", "X-Trailer")
+		writeTestGRPCResponse(w, GRPCStatusOK, "", msgs...)
+		w.Header().Set("X-Trailer", "done")
+	})
+	mux.HandleFunc("/test.Items/Missing", func(w http.ResponseWriter, r *http.Request) {
+		//'trailers-only' response
+		w.Header().Set("Content-Type", grpcContentType)
+		w.Header().Set("Grpc-Status", "5")
+		w.Header().Set("Grpc-Message", "item%20not%20found")
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/test.Items/NoStatus", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", grpcContentType)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/test.Items/BadStatus", func(w http.ResponseWriter, r *http.Request) {
+		writeTestGRPCResponse(w, 0, "")
+		w.Header().Set("Grpc-Status", "ok")
+	})
+	mux.HandleFunc("/test.Items/BadFrame", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", grpcContentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte{0, 0, 0, 0, 5, 'h'})
+	})
+	mux.HandleFunc("/test.Items/Watch", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", grpcContentType)
+		w.WriteHeader(http.StatusOK)
+
+		var body bytes.Buffer
+		writeGRPCFrame(&body, []byte("item"))
+		w.Write(body.Bytes())
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	gc := testGRPCClient(t, mux)
+	gc.Headers.Set("X-Token", "secret")
+
+	result, err := gc.Call(context.Background(), "/test.Items/Get", [][]byte{[]byte("one"), {}})
+	require.NoError(t, err)
+	assert.Equal(t, GRPCStatusOK, result.Status)
+	assert.Equal(t, "OK", result.StatusName())
+	assert.Equal(t, [][]byte{[]byte("one"), {}}, result.Messages)
+	assert.False(t, result.TimedOut)
+	assert.Equal(t, "1.2", result.Metadata.Get("X-Version"))
+	assert.Equal(t, "secret", result.Metadata.Get("X-Token"))
+	assert.Equal(t, "0", result.Metadata.Get("Grpc-Status"))
+	assert.Equal(t, "done", result.Metadata.Get("X-Trailer"))
+
+	result, err = gc.Call(context.Background(), "/test.Items/Missing", [][]byte{{}})
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Status)
+	assert.Equal(t, "NOT_FOUND", result.StatusName())
+	assert.Equal(t, "item not found", result.Message)
+	assert.Empty(t, result.Messages)
+
+	for _, method := range []string{"/test.Items/NoStatus", "/test.Items/BadStatus", "/test.Items/BadFrame", "/test.Items/Other"} {
+		_, err = gc.Call(context.Background(), method, [][]byte{{}})
+		assert.Error(t, err, method)
+	}
+
+	//the stream messages are returned when the call context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	result, err = gc.Call(ctx, "/test.Items/Watch", [][]byte{{}})
+	require.NoError(t, err)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, [][]byte{[]byte("item")}, result.Messages)
+
+	result = &GRPCCallResult{Status: 42}
+	assert.Equal(t, "42", result.StatusName())
+}
+
+// testReflectionFiles returns the 'api.proto' file for the 'api.Service' service
+// and its 'common.proto' dependency
+func testReflectionFiles() (*descriptorpb.FileDescriptorProto, *descriptorpb.FileDescriptorProto) {
+	common := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("common.proto"),
+		Package: proto.String("common"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Meta"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					testProtoField("version", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				},
+			},
+		},
+	}
+
+	api := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("api.proto"),
+		Package:    proto.String("api"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"common.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Request"),
+			},
+			{
+				Name: proto.String("Response"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					testProtoField("meta", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".common.Meta"),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("Service"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("Get"),
+						InputType:  proto.String(".api.Request"),
+						OutputType: proto.String(".api.Response"),
+					},
+				},
+			},
+		},
+	}
+
+	return api, common
+}
+
+// testReflectionHandler is a v1alpha server reflection service
+// (the v1 service is not implemented and the file dependencies are not included in the symbol responses)
+func testReflectionHandler(t *testing.T) http.Handler {
+	api, common := testReflectionFiles()
+	files := map[string]*descriptorpb.FileDescriptorProto{
+		api.GetName():    api,
+		common.GetName(): common,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+grpcReflectionV1+"/"+grpcReflectionMethod, func(w http.ResponseWriter, r *http.Request) {
+		writeTestGRPCResponse(w, GRPCStatusUnimplemented, "")
+	})
+	mux.HandleFunc("/"+grpcReflectionV1Alpha+"/"+grpcReflectionMethod, func(w http.ResponseWriter, r *http.Request) {
+		request, err := readGRPCFrame(r.Body)
+		if err != nil {
+			writeTestGRPCResponse(w, 3, err.Error())
+			return
+		}
+
+		var response []byte
+		appendFile := func(fd *descriptorpb.FileDescriptorProto) {
+			data, err := proto.Marshal(fd)
+			require.NoError(t, err)
+
+			var fdr []byte
+			fdr = protowire.AppendTag(fdr, 1, protowire.BytesType)
+			fdr = protowire.AppendBytes(fdr, data)
+			response = protowire.AppendTag(response, reflectionFileDescriptorResponse, protowire.BytesType)
+			response = protowire.AppendBytes(response, fdr)
+		}
+
+		appendError := func(code uint64, message string) {
+			var errData []byte
+			errData = protowire.AppendTag(errData, 1, protowire.VarintType)
+			errData = protowire.AppendVarint(errData, code)
+			errData = protowire.AppendTag(errData, 2, protowire.BytesType)
+			errData = protowire.AppendString(errData, message)
+			response = protowire.AppendTag(response, reflectionErrorResponse, protowire.BytesType)
+			response = protowire.AppendBytes(response, errData)
+		}
+
+		walkProtoFields(request, func(num protowire.Number, typ protowire.Type, data []byte, _ uint64) {
+			switch num {
+			case reflectionListServices:
+				var list []byte
+				for _, name := range []string{grpcReflectionV1Alpha, "api.Service"} {
+					var service []byte
+					service = protowire.AppendTag(service, 1, protowire.BytesType)
+					service = protowire.AppendString(service, name)
+					list = protowire.AppendTag(list, 1, protowire.BytesType)
+					list = protowire.AppendBytes(list, service)
+				}
+
+				response = protowire.AppendTag(response, reflectionListServicesResponse, protowire.BytesType)
+				response = protowire.AppendBytes(response, list)
+			case reflectionFileContainingSymbol:
+				if string(data) == "api.Service" {
+					appendFile(api)
+				} else {
+					appendError(5, "symbol not found")
+				}
+			case reflectionFileByFilename:
+				if fd, found := files[string(data)]; found {
+					appendFile(fd)
+				} else {
+					appendError(5, "file not found")
+				}
+			}
+		})
+
+		writeTestGRPCResponse(w, GRPCStatusOK, "", response)
+	})
+
+	return mux
+}
+
+func TestGRPCClientReflection(t *testing.T) {
+	gc := testGRPCClient(t, testReflectionHandler(t))
+
+	services, err := gc.ListServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{grpcReflectionV1Alpha, "api.Service"}, services)
+	//the available reflection service is used for the next calls
+	assert.Equal(t, grpcReflectionV1Alpha, gc.reflectionService)
+
+	files, err := gc.ReflectionFiles(context.Background(), withoutReflectionServices(services))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api.Service"}, grpcFileServices(files))
+
+	//the dependency is fetched by its file name
+	desc, err := files.FindDescriptorByName("api.Response")
+	require.NoError(t, err)
+	meta := desc.(protoreflect.MessageDescriptor).Fields().ByName("meta").Message()
+	assert.False(t, meta.IsPlaceholder())
+	assert.Equal(t, protoreflect.FullName("common.Meta"), meta.FullName())
+
+	_, err = gc.ReflectionFiles(context.Background(), []string{"api.Other"})
+	assert.EqualError(t, err, "grpc reflection error - 5: symbol not found")
+}
+
+func TestGRPCClientNoReflection(t *testing.T) {
+	gc := testGRPCClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeTestGRPCResponse(w, GRPCStatusUnimplemented, "")
+	}))
+
+	_, err := gc.ListServices(context.Background())
+	assert.Equal(t, ErrGRPCNoReflection, err)
+
+	gc = testGRPCClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeTestGRPCResponse(w, 7, "access%20denied")
+	}))
+
+	_, err = gc.ListServices(context.Background())
+	assert.EqualError(t, err, "grpc reflection error - PERMISSION_DENIED: access denied")
+}
+
+func TestLoadGRPCDescriptorSet(t *testing.T) {
+	//the missing dependencies are allowed
+	api, _ := testReflectionFiles()
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{api}})
+	require.NoError(t, err)
+
+	filePath := filepath.Join(t.TempDir(), "api.pb")
+	require.NoError(t, os.WriteFile(filePath, data, 0644))
+
+	files, err := LoadGRPCDescriptorSet(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api.Service"}, grpcFileServices(files))
+
+	desc, err := files.FindDescriptorByName("api.Response")
+	require.NoError(t, err)
+	assert.True(t, desc.(protoreflect.MessageDescriptor).Fields().ByName("meta").Message().IsPlaceholder())
+
+	require.NoError(t, os.WriteFile(filePath, []byte{0x80}, 0644))
+	_, err = LoadGRPCDescriptorSet(filePath)
+	assert.Error(t, err)
+
+	_, err = LoadGRPCDescriptorSet(filepath.Join(t.TempDir(), "missing.pb"))
+	assert.Error(t, err)
+}
+
+func TestEncodeGRPCMessage(t *testing.T) {
+	md := testMessageDescriptor(t, "test.Item")
+
+	//the JSON and the proto field names can be used
+	data, err := EncodeGRPCMessage(md, map[string]interface{}{
+		"id":          42.0,
+		"displayName": "item",
+		"status":      1.0,
+		"codes":       []interface{}{"1"},
+	})
+	require.NoError(t, err)
+
+	msg := dynamicpb.NewMessage(md)
+	require.NoError(t, proto.Unmarshal(data, msg))
+	assert.Equal(t, int64(42), msg.Get(md.Fields().ByName("id")).Int())
+	assert.Equal(t, "item", msg.Get(md.Fields().ByName("display_name")).String())
+	assert.Equal(t, protoreflect.EnumNumber(1), msg.Get(md.Fields().ByName("status")).Enum())
+	assert.Equal(t, 1, msg.Get(md.Fields().ByName("codes")).List().Len())
+
+	tt := []struct {
+		name  string
+		value map[string]interface{}
+	}{
+		{name: "unknown field", value: map[string]interface{}{"other": 1.0}},
+		{name: "bad int", value: map[string]interface{}{"id": "x"}},
+		{name: "bad enum", value: map[string]interface{}{"status": "OTHER"}},
+		{name: "bad list", value: map[string]interface{}{"codes": 1.0}},
+		{name: "bad message", value: map[string]interface{}{"child": "x"}},
+		{name: "bad bytes", value: map[string]interface{}{"data": "!"}},
+	}
+
+	for _, test := range tt {
+		_, err := EncodeGRPCMessage(md, test.value)
+		assert.Error(t, err, test.name)
+	}
+}
+
+func TestDecodeGRPCMessage(t *testing.T) {
+	md := testMessageDescriptor(t, "test.Item")
+	data, err := EncodeGRPCMessage(md, map[string]interface{}{
+		"id":           "12345678901",
+		"display_name": "item",
+		"status":       "ACTIVE",
+		"codes":        []interface{}{1.0, -2.0},
+		"counts":       map[string]interface{}{"a": 3.0},
+		"data":         "AQI=",
+		"score":        1.5,
+		"child":        map[string]interface{}{"displayName": "child"},
+		"delta":        -3.0,
+		"ok":           true,
+		"ratio":        0.5,
+		"checksum":     7.0,
+	})
+	require.NoError(t, err)
+
+	//the unknown fields are skipped
+	data = protowire.AppendTag(data, 99, protowire.VarintType)
+	data = protowire.AppendVarint(data, 1)
+
+	msg, err := DecodeGRPCMessage(md, data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":          "12345678901",
+		"displayName": "item",
+		"status":      "ACTIVE",
+		"codes":       []interface{}{1.0, -2.0},
+		"counts":      map[string]interface{}{"a": 3.0},
+		"data":        "AQI=",
+		"score":       1.5,
+		"child":       map[string]interface{}{"displayName": "child"},
+		"delta":       -3.0,
+		"ok":          true,
+		"ratio":       0.5,
+		"checksum":    "7",
+	}, msg)
+
+	msg, err = DecodeGRPCMessage(md, nil)
+	require.NoError(t, err)
+	assert.Empty(t, msg)
+}
+
+func TestDecodeGRPCMessageWireFormats(t *testing.T) {
+	md := testMessageDescriptor(t, "test.Item")
+
+	//packed repeated values, an unknown enum number and the last value for the same field
+	var packed []byte
+	packed = protowire.AppendVarint(packed, 5)
+	packed = protowire.AppendVarint(packed, 6)
+
+	var data []byte
+	data = protowire.AppendTag(data, 4, protowire.BytesType)
+	data = protowire.AppendBytes(data, packed)
+	data = protowire.AppendTag(data, 4, protowire.VarintType)
+	data = protowire.AppendVarint(data, 7)
+	data = protowire.AppendTag(data, 3, protowire.VarintType)
+	data = protowire.AppendVarint(data, 9)
+	data = protowire.AppendTag(data, 2, protowire.BytesType)
+	data = protowire.AppendString(data, "a")
+	data = protowire.AppendTag(data, 2, protowire.BytesType)
+	data = protowire.AppendString(data, "b")
+
+	msg, err := DecodeGRPCMessage(md, data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"codes":       []interface{}{5.0, 6.0, 7.0},
+		"status":      9.0,
+		"displayName": "b",
+	}, msg)
+
+	tt := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated tag", data: []byte{0x80}},
+		{name: "truncated value", data: []byte{0x12, 0x05, 'a'}},
+		{name: "truncated unknown field", data: []byte{0xf8, 0x06}},
+		{name: "invalid utf8 string", data: []byte{0x12, 0x01, 0xff}},
+		{name: "bad packed value", data: []byte{0x22, 0x01, 0x80}},
+		{name: "bad embedded message", data: []byte{0x42, 0x01, 0x80}},
+	}
+
+	for _, test := range tt {
+		_, err := DecodeGRPCMessage(md, test.data)
+		assert.Error(t, err, test.name)
+	}
+}
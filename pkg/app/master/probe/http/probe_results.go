@@ -0,0 +1,309 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/probediff"
+)
+
+const (
+	maxRecordedBodySize  = 1024 * 1024
+	maxAssertionValueLen = 120
+)
+
+// Assertion types
+const (
+	AssertionStatus    = "status"
+	AssertionHeader    = "header"
+	AssertionJSONPath  = "json"
+	AssertionBodyRegex = "body"
+)
+
+// CallResults returns the normalized probe command call results
+// (one result for each probe command, port and protocol; the last attempt is recorded)
+func (p *CustomProbe) CallResults() []*probediff.CallResult {
+	return p.callResults
+}
+
+func (p *CustomProbe) recordCall(result *probediff.CallResult) {
+	if result == nil {
+		return
+	}
+
+	key := fmt.Sprintf("%s:%s%s", result.Protocol, result.Port, result.Resource)
+	if result.Method != "" {
+		key = fmt.Sprintf("%s %s", result.Method, key)
+	}
+
+	//the same command can be used more than once
+	if p.callKeys == nil {
+		p.callKeys = map[string]int{}
+	}
+
+	p.callKeys[key]++
+	if count := p.callKeys[key]; count > 1 {
+		key = fmt.Sprintf("%s #%d", key, count)
+	}
+
+	result.Key = key
+	p.callResults = append(p.callResults, result)
+}
+
+// containerPort returns the container port for the probe target port
+func (p *CustomProbe) containerPort(port string) string {
+	if containerPort, found := p.containerPorts[port]; found {
+		return containerPort
+	}
+
+	return port
+}
+
+// newHTTPCallResult reads the response body, checks the command expectations
+// and returns the normalized call result (the response body is closed)
+func newHTTPCallResult(
+	cmd config.HTTPProbeCmd,
+	proto string,
+	containerPort string,
+	res *http.Response,
+	callErr error) *probediff.CallResult {
+	result := &probediff.CallResult{
+		Protocol: proto,
+		Port:     containerPort,
+		Method:   cmd.Method,
+		Resource: cmd.Resource,
+	}
+
+	if res == nil || callErr != nil {
+		if res != nil && res.Body != nil {
+			res.Body.Close()
+		}
+
+		result.Status = probediff.StatusError
+		if callErr != nil {
+			result.Error = callErr.Error()
+		}
+
+		return result
+	}
+
+	var body []byte
+	if res.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(res.Body, maxRecordedBodySize))
+		if err != nil {
+			log.Debugf("HTTP probe - response body read error - %v", err)
+		}
+
+		result.BodySize = int64(len(body))
+		extra, _ := io.Copy(io.Discard, res.Body)
+		result.BodySize += extra
+		res.Body.Close()
+	}
+
+	result.Status = strconv.Itoa(res.StatusCode)
+	result.ContentType = probediff.NormalizeContentType(res.Header.Get("Content-Type"))
+	result.BodyShape, result.BodyHash = probediff.NormalizeBody(result.ContentType, body)
+	if cmd.Expect != nil {
+		result.Assertions = checkExpectations(cmd.Expect, res.StatusCode, res.Header, body)
+	}
+
+	return result
+}
+
+// checkExpectations returns the assertion results for the response status code, headers and body
+// (in a stable order, so the results from different probe runs can be matched)
+func checkExpectations(
+	expect *config.HTTPProbeExpectation,
+	statusCode int,
+	header http.Header,
+	body []byte) []*probediff.AssertionResult {
+	var results []*probediff.AssertionResult
+	if len(expect.Status) > 0 {
+		result := &probediff.AssertionResult{
+			Type:   AssertionStatus,
+			Actual: strconv.Itoa(statusCode),
+		}
+
+		var codes []string
+		for _, code := range expect.Status {
+			codes = append(codes, strconv.Itoa(code))
+			if code == statusCode {
+				result.OK = true
+			}
+		}
+
+		result.Expected = strings.Join(codes, ",")
+		results = append(results, result)
+	}
+
+	for _, name := range sortedKeys(expect.Headers) {
+		pattern := expect.Headers[name]
+		value := header.Get(name)
+		result := &probediff.AssertionResult{
+			Type:     AssertionHeader,
+			Target:   name,
+			Expected: pattern,
+			Actual:   assertionValue(value),
+		}
+
+		if re, err := regexp.Compile(pattern); err == nil {
+			result.OK = len(header.Values(name)) > 0 && re.MatchString(value)
+		}
+
+		results = append(results, result)
+	}
+
+	if len(expect.JSON) > 0 {
+		var doc interface{}
+		docErr := json.Unmarshal(body, &doc)
+		for _, path := range sortedKeys(expect.JSON) {
+			expected := expect.JSON[path]
+			result := &probediff.AssertionResult{
+				Type:     AssertionJSONPath,
+				Target:   path,
+				Expected: "(any)",
+			}
+
+			if expected != nil {
+				result.Expected = jsonAssertionValue(expected)
+			}
+
+			if docErr != nil {
+				result.Actual = "(not json)"
+				results = append(results, result)
+				continue
+			}
+
+			actual, found := jsonPathValue(doc, path)
+			switch {
+			case !found:
+				result.Actual = "(missing)"
+			case expected == nil:
+				result.Actual = jsonAssertionValue(actual)
+				result.OK = true
+			default:
+				result.Actual = jsonAssertionValue(actual)
+				result.OK = jsonValuesEqual(expected, actual)
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	if expect.BodyRegex != "" {
+		result := &probediff.AssertionResult{
+			Type:     AssertionBodyRegex,
+			Expected: expect.BodyRegex,
+		}
+
+		if re, err := regexp.Compile(expect.BodyRegex); err == nil {
+			if match := re.Find(body); match != nil {
+				result.OK = true
+				result.Actual = assertionValue(string(match))
+			} else {
+				result.Actual = "(no match)"
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// jsonPathValue returns the value for the JSON path
+// ('$.data.items[0].id', 'data.items[0].id' or 'data.items.0.id')
+func jsonPathValue(doc interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+
+	current := doc
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			continue
+		}
+
+		switch typed := current.(type) {
+		case map[string]interface{}:
+			value, found := typed[part]
+			if !found {
+				return nil, false
+			}
+
+			current = value
+		case []interface{}:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(typed) {
+				return nil, false
+			}
+
+			current = typed[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// jsonValuesEqual compares the decoded JSON values (both values are decoded from JSON)
+func jsonValuesEqual(expected, actual interface{}) bool {
+	expectedData, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+
+	actualData, err := json.Marshal(actual)
+	if err != nil {
+		return false
+	}
+
+	return string(expectedData) == string(actualData)
+}
+
+func jsonAssertionValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return assertionValue(string(data))
+}
+
+func assertionValue(value string) string {
+	if len(value) > maxAssertionValueLen {
+		return value[:maxAssertionValueLen] + "..."
+	}
+
+	return value
+}
+
+func (p *CustomProbe) printFailedAssertions(target string, result *probediff.CallResult) {
+	failed := result.FailedAssertions()
+	p.AssertionErrCount += uint64(len(failed))
+	if !p.printState {
+		return
+	}
+
+	for _, assertion := range failed {
+		p.xc.Out.Info("http.probe.call.assertion",
+			ovars{
+				"status":   "failed",
+				"method":   result.Method,
+				"target":   target,
+				"type":     assertion.Type,
+				"field":    assertion.Target,
+				"expected": assertion.Expected,
+				"actual":   assertion.Actual,
+			})
+	}
+}
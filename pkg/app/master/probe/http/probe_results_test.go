@@ -0,0 +1,178 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/probediff"
+)
+
+const testResponseBody = `{"status":"ok","version":"1.2","data":{"items":[{"id":1,"tags":["a","b"]}],"next":null}}`
+
+func TestJSONPathValue(t *testing.T) {
+	var doc interface{} = map[string]interface{}{
+		"status": "ok",
+		"data": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"id": 1.0, "tags": []interface{}{"a", "b"}},
+			},
+			"next": nil,
+		},
+	}
+
+	tt := []struct {
+		path     string
+		expected interface{}
+		found    bool
+	}{
+		{path: "$.status", expected: "ok", found: true},
+		{path: "status", expected: "ok", found: true},
+		{path: " $.data.items[0].id ", expected: 1.0, found: true},
+		{path: "data.items.0.id", expected: 1.0, found: true},
+		{path: "$.data.items[0].tags[1]", expected: "b", found: true},
+		{path: "$.data.next", expected: nil, found: true},
+		{path: "$", expected: doc, found: true},
+		{path: "", expected: doc, found: true},
+		{path: "$.missing"},
+		{path: "$.data.items[1]"},
+		{path: "$.data.items[-1]"},
+		{path: "$.data.items.id"},
+		{path: "$.status.value"},
+		{path: "$.data.next.value"},
+	}
+
+	for _, test := range tt {
+		value, found := jsonPathValue(doc, test.path)
+		assert.Equal(t, test.found, found, test.path)
+		assert.Equal(t, test.expected, value, test.path)
+	}
+}
+
+func TestCheckExpectations(t *testing.T) {
+	expect := &config.HTTPProbeExpectation{
+		Status: []int{200, 201},
+		Headers: map[string]string{
+			"Content-Type": "^application/json",
+			"X-Bad":        "(",
+			"X-Missing":    ".*",
+		},
+		JSON: map[string]interface{}{
+			"$.status":         "ok",
+			"$.version":        nil,
+			"data.items[0].id": 1.0,
+			"$.data.items[0]":  map[string]interface{}{"tags": []interface{}{"a", "b"}, "id": 1.0},
+			"missing":          nil,
+			"$.data.next":      "x",
+		},
+		BodyRegex: `"status":\s*"\w+"`,
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set("X-Bad", "x")
+
+	results := checkExpectations(expect, 200, header, []byte(testResponseBody))
+	assert.Equal(t, []*probediff.AssertionResult{
+		{Type: AssertionStatus, Expected: "200,201", Actual: "200", OK: true},
+		{Type: AssertionHeader, Target: "Content-Type", Expected: "^application/json", Actual: "application/json; charset=utf-8", OK: true},
+		{Type: AssertionHeader, Target: "X-Bad", Expected: "(", Actual: "x"},
+		{Type: AssertionHeader, Target: "X-Missing", Expected: ".*", Actual: ""},
+		{Type: AssertionJSONPath, Target: "$.data.items[0]", Expected: `{"id":1,"tags":["a","b"]}`, Actual: `{"id":1,"tags":["a","b"]}`, OK: true},
+		{Type: AssertionJSONPath, Target: "$.data.next", Expected: `"x"`, Actual: "null"},
+		{Type: AssertionJSONPath, Target: "$.status", Expected: `"ok"`, Actual: `"ok"`, OK: true},
+		{Type: AssertionJSONPath, Target: "$.version", Expected: "(any)", Actual: `"1.2"`, OK: true},
+		{Type: AssertionJSONPath, Target: "data.items[0].id", Expected: "1", Actual: "1", OK: true},
+		{Type: AssertionJSONPath, Target: "missing", Expected: "(any)", Actual: "(missing)"},
+		{Type: AssertionBodyRegex, Expected: `"status":\s*"\w+"`, Actual: `"status":"ok"`, OK: true},
+	}, results)
+
+	//not a JSON body
+	results = checkExpectations(&config.HTTPProbeExpectation{
+		Status:    []int{200},
+		JSON:      map[string]interface{}{"$.status": "ok"},
+		BodyRegex: "^ok$",
+	}, 503, nil, []byte("unavailable"))
+	assert.Equal(t, []*probediff.AssertionResult{
+		{Type: AssertionStatus, Expected: "200", Actual: "503"},
+		{Type: AssertionJSONPath, Target: "$.status", Expected: `"ok"`, Actual: "(not json)"},
+		{Type: AssertionBodyRegex, Expected: "^ok$", Actual: "(no match)"},
+	}, results)
+
+	assert.Empty(t, checkExpectations(&config.HTTPProbeExpectation{}, 200, nil, nil))
+
+	//the long values are truncated
+	long := strings.Repeat("x", maxAssertionValueLen+10)
+	results = checkExpectations(&config.HTTPProbeExpectation{BodyRegex: "x+"}, 200, nil, []byte(long))
+	require.Len(t, results, 1)
+	assert.Equal(t, long[:maxAssertionValueLen]+"...", results[0].Actual)
+}
+
+func TestNewHTTPCallResult(t *testing.T) {
+	cmd := config.HTTPProbeCmd{
+		Method:   "GET",
+		Resource: "/api/info",
+		Expect:   &config.HTTPProbeExpectation{Status: []int{200}},
+	}
+
+	res := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+		Body:       io.NopCloser(strings.NewReader(testResponseBody + "\n")),
+	}
+
+	result := newHTTPCallResult(cmd, "http", "8080", res, nil)
+	assert.Equal(t, "http", result.Protocol)
+	assert.Equal(t, "8080", result.Port)
+	assert.Equal(t, "GET", result.Method)
+	assert.Equal(t, "/api/info", result.Resource)
+	assert.Equal(t, "200", result.Status)
+	assert.Equal(t, "application/json", result.ContentType)
+	assert.Equal(t, int64(len(testResponseBody)+1), result.BodySize)
+	assert.Equal(t, `{"data":{"items":[{"id":number,"tags":[string]}],"next":null},"status":string,"version":string}`, result.BodyShape)
+	assert.NotEmpty(t, result.BodyHash)
+	require.Len(t, result.Assertions, 1)
+	assert.True(t, result.Assertions[0].OK)
+
+	result = newHTTPCallResult(cmd, "http", "8080", nil, errors.New("connection refused"))
+	assert.True(t, result.Failed())
+	assert.Equal(t, "connection refused", result.Error)
+	assert.Empty(t, result.Assertions)
+}
+
+func TestRecordCall(t *testing.T) {
+	p := &CustomProbe{}
+	p.recordCall(nil)
+	p.recordCall(&probediff.CallResult{Protocol: "http", Port: "8080", Method: "GET", Resource: "/api"})
+	p.recordCall(&probediff.CallResult{Protocol: "http", Port: "8080", Method: "GET", Resource: "/api"})
+	p.recordCall(&probediff.CallResult{Protocol: "http", Port: "8080", Method: "POST", Resource: "/api"})
+	p.recordCall(&probediff.CallResult{Protocol: "redis", Port: "6379"})
+	p.recordCall(&probediff.CallResult{Protocol: "grpc", Port: "50051", Resource: "/test.Service/Get"})
+	p.recordCall(&probediff.CallResult{Protocol: "http", Port: "8080", Method: "query", Resource: "/graphql#users"})
+
+	var keys []string
+	for _, result := range p.CallResults() {
+		keys = append(keys, result.Key)
+	}
+
+	assert.Equal(t, []string{
+		"GET http:8080/api",
+		"GET http:8080/api #2",
+		"POST http:8080/api",
+		"redis:6379",
+		"grpc:50051/test.Service/Get",
+		"query http:8080/graphql#users",
+	}, keys)
+}
+
+func TestContainerPort(t *testing.T) {
+	p := &CustomProbe{containerPorts: map[string]string{"32768": "8080"}}
+	assert.Equal(t, "8080", p.containerPort("32768"))
+	assert.Equal(t, "9000", p.containerPort("9000"))
+}
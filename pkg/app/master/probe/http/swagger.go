@@ -279,7 +279,7 @@ func (p *CustomProbe) probeAPISpecEndpoints(proto, targetHost, port, prefix stri
 			ovars{
 				"addr":      addr,
 				"prefix":    prefix,
-				"endpoints": len(spec.Paths),
+				"endpoints": spec.Paths.Len(),
 			})
 	}
 
@@ -289,26 +289,17 @@ func (p *CustomProbe) probeAPISpecEndpoints(proto, targetHost, port, prefix stri
 		return
 	}
 
-	for apiPath, pathInfo := range spec.Paths {
-		//very primitive way to set the path params (will break for numeric values)
-		if strings.Contains(apiPath, "{") {
-			apiPath = strings.ReplaceAll(apiPath, "{", "")
-
-			if strings.Contains(apiPath, "}") {
-				apiPath = strings.ReplaceAll(apiPath, "}", "")
-			}
-		}
-
-		endpoint := fmt.Sprintf("%s%s%s", addr, prefix, apiPath)
+	for apiPath, pathInfo := range spec.Paths.Map() {
 		ops := pathOps(pathInfo)
-		for apiMethod := range ops {
-			//make a call (no params for now)
-			p.apiSpecEndpointCall(httpClient, endpoint, apiMethod)
+		for apiMethod, op := range ops {
+			//make a call with the params, body and credentials synthesized from the spec
+			req := newAPISpecRequest(spec, addr, prefix, apiPath, apiMethod, pathInfo, op, p.opts.APISpecCredentials)
+			p.apiSpecEndpointCall(httpClient, req)
 		}
 	}
 }
 
-func (p *CustomProbe) apiSpecEndpointCall(client *http.Client, endpoint, method string) {
+func (p *CustomProbe) apiSpecEndpointCall(client *http.Client, apiReq *apiSpecRequest) {
 	maxRetryCount := probeRetryCount
 	if p.opts.RetryCount > 0 {
 		maxRetryCount = p.opts.RetryCount
@@ -323,15 +314,14 @@ func (p *CustomProbe) apiSpecEndpointCall(client *http.Client, endpoint, method
 		otherErrorWait = time.Duration(p.opts.RetryWait / 2)
 	}
 
-	method = strings.ToUpper(method)
 	for i := 0; i < maxRetryCount; i++ {
-		req, err := http.NewRequest(method, endpoint, nil)
+		req, err := apiReq.httpRequest()
 		if err != nil {
 			p.xc.Out.Error("HTTP probe - construct request error - %v", err.Error())
 			// Break since the same args are passed to NewRequest() on each loop.
 			break
 		}
-		//no body, no request headers and no credentials for now
+
 		res, err := client.Do(req)
 		p.CallCount++
 
@@ -355,8 +345,8 @@ func (p *CustomProbe) apiSpecEndpointCall(client *http.Client, endpoint, method
 			p.xc.Out.Info("http.probe.api-spec.probe.endpoint.call",
 				ovars{
 					"status":   statusCode,
-					"method":   method,
-					"endpoint": endpoint,
+					"method":   apiReq.method,
+					"endpoint": apiReq.endpoint,
 					"attempt":  i + 1,
 					"error":    callErrorStr,
 					"time":     time.Now().UTC().Format(time.RFC3339),
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/probediff"
+)
+
+type tcpProbeTarget struct {
+	protocol      string
+	port          string
+	containerPort string
+}
+
+func (t tcpProbeTarget) String() string {
+	return fmt.Sprintf("%s:%s", t.protocol, t.port)
+}
+
+// addTCPProbeTargets adds the TCP probe targets (targetPort maps the container port to the probe port)
+// and removes their ports from the HTTP probe port list
+func (p *CustomProbe) addTCPProbeTargets(targetPort func(port uint16) (string, bool)) {
+	for _, target := range p.opts.TCPProbes {
+		port, ok := targetPort(target.Port)
+		if !ok {
+			log.Debugf("HTTP probe - ignoring tcp probe port => %v", target.Port)
+			continue
+		}
+
+		p.tcpProbes = append(p.tcpProbes, tcpProbeTarget{
+			protocol:      target.Protocol,
+			port:          port,
+			containerPort: fmt.Sprintf("%d", target.Port),
+		})
+
+		var httpPorts []string
+		for _, httpPort := range p.ports {
+			if httpPort != port {
+				httpPorts = append(httpPorts, httpPort)
+			}
+		}
+
+		p.ports = httpPorts
+	}
+
+	log.Debugf("HTTP probe - tcp probe targets => %+v", p.tcpProbes)
+}
+
+// tcpProbe runs the native protocol probe for the target
+// (retrying only if the connection or the protocol exchange fails)
+func (p *CustomProbe) tcpProbe(target tcpProbeTarget) {
+	maxRetryCount := probeRetryCount
+	if p.opts.RetryCount > 0 {
+		maxRetryCount = p.opts.RetryCount
+	}
+
+	notReadyErrorWait := time.Duration(16)
+	if p.opts.RetryWait > 0 {
+		notReadyErrorWait = time.Duration(p.opts.RetryWait * 2)
+	}
+
+	addr := net.JoinHostPort(p.targetHost, target.port)
+	callResult := &probediff.CallResult{
+		Protocol: target.protocol,
+		Port:     target.containerPort,
+	}
+
+	defer p.recordCall(callResult)
+	for i := 0; i < maxRetryCount; i++ {
+		result, err := TCPProbeCall(target.protocol, addr, defaultTCPProbeTimeout)
+		p.CallCount++
+
+		if err == nil {
+			callResult.Status = result.Status
+			callResult.Error = ""
+		} else {
+			callResult.Status = probediff.StatusError
+			callResult.Error = err.Error()
+		}
+
+		if p.printState {
+			statusCode := "error"
+			callErrorStr := "none"
+			var server, commands string
+			if err == nil {
+				statusCode = result.Status
+				server = result.Server
+				commands = strings.Join(result.Commands, ",")
+				if result.Message != "" {
+					callErrorStr = result.Message
+				}
+			} else {
+				callErrorStr = err.Error()
+			}
+
+			p.xc.Out.Info("http.probe.call.tcp",
+				ovars{
+					"status":   statusCode,
+					"protocol": target.protocol,
+					"server":   server,
+					"commands": commands,
+					"target":   addr,
+					"attempt":  i + 1,
+					"error":    callErrorStr,
+					"time":     time.Now().UTC().Format(time.RFC3339),
+				})
+		}
+
+		if err == nil {
+			p.OkCount++
+			return
+		}
+
+		p.ErrCount++
+		log.Debugf("HTTP probe - tcp target not ready yet (retry again later) [err=%v]...", err)
+		time.Sleep(notReadyErrorWait * time.Second)
+	}
+}
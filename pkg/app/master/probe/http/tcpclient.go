@@ -0,0 +1,1088 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+)
+
+const (
+	defaultTCPProbeTimeout   = 10 * time.Second
+	tcpProbeBannerWait       = 2 * time.Second
+	maxTCPProbeMessageSize   = 1024 * 1024
+	maxTCPProbeBannerSize    = 512
+	maxTCPProbeServerInfoLen = 80
+	maxRedisReplyDepth       = 8
+
+	tcpProbeClientName = "slim-probe"
+	postgresProbeUser  = "postgres"
+	mysqlProbeUser     = "root"
+)
+
+// TCP probe call statuses
+// (the auth and error replies are still successful probe calls, the server handled the commands)
+const (
+	TCPProbeStatusOK           = "ok"
+	TCPProbeStatusAuthRequired = "auth.required"
+	TCPProbeStatusTLSRequired  = "tls.required"
+	TCPProbeStatusErrorReply   = "error.reply"
+)
+
+var ErrTCPProbeMalformedReply = errors.New("malformed protocol reply")
+
+// TCPProbeResult has the protocol probe call results
+type TCPProbeResult struct {
+	Status   string
+	Server   string
+	Message  string
+	Commands []string
+}
+
+func (r *TCPProbeResult) done(command string) {
+	r.Commands = append(r.Commands, command)
+}
+
+func (r *TCPProbeResult) setServer(info string) {
+	info = strings.TrimSpace(info)
+	if len(info) > maxTCPProbeServerInfoLen {
+		info = info[:maxTCPProbeServerInfoLen]
+	}
+
+	r.Server = info
+}
+
+type tcpProtoHandler func(conn *bufio.ReadWriter, result *TCPProbeResult) error
+
+var tcpProtoHandlers = map[string]tcpProtoHandler{
+	config.TCPProtoRedis:      redisProbe,
+	config.TCPProtoPostgreSQL: postgresProbe,
+	config.TCPProtoMySQL:      mysqlProbe,
+	config.TCPProtoMongoDB:    mongoProbe,
+	config.TCPProtoMemcached:  memcachedProbe,
+	config.TCPProtoNATS:       natsProbe,
+}
+
+// TCPProbeCall connects to the target and runs the protocol handshake and commands
+// (the 'tcp' protocol only connects and reads the server banner if there's one)
+func TCPProbeCall(proto, addr string, timeout time.Duration) (*TCPProbeResult, error) {
+	if timeout <= 0 {
+		timeout = defaultTCPProbeTimeout
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	result := &TCPProbeResult{Status: TCPProbeStatusOK}
+	handler, found := tcpProtoHandlers[proto]
+	if !found {
+		return result, tcpBanner(conn, result)
+	}
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	if err := handler(rw, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func tcpBanner(conn net.Conn, result *TCPProbeResult) error {
+	if err := conn.SetReadDeadline(time.Now().Add(tcpProbeBannerWait)); err != nil {
+		return err
+	}
+
+	data := make([]byte, maxTCPProbeBannerSize)
+	n, err := conn.Read(data)
+	if n > 0 {
+		banner := string(data[:n])
+		if idx := strings.IndexAny(banner, "\r\n"); idx != -1 {
+			banner = banner[:idx]
+		}
+
+		result.setServer(strings.ToValidUTF8(banner, ""))
+		return nil
+	}
+
+	var netErr net.Error
+	if err == nil || err == io.EOF || (errors.As(err, &netErr) && netErr.Timeout()) {
+		//the servers that don't send a banner (or close the connection right away) are still reachable
+		return nil
+	}
+
+	return err
+}
+
+func writeFlush(rw *bufio.ReadWriter, data []byte) error {
+	if _, err := rw.Write(data); err != nil {
+		return err
+	}
+
+	return rw.Flush()
+}
+
+func readTextLine(rw *bufio.ReadWriter) (string, error) {
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	if len(line) > maxTCPProbeMessageSize {
+		return "", ErrTCPProbeMalformedReply
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func readFullMessage(rw *bufio.ReadWriter, size int) ([]byte, error) {
+	if size < 0 || size > maxTCPProbeMessageSize {
+		return nil, ErrTCPProbeMalformedReply
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(rw, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Redis (RESP)
+
+type redisReply struct {
+	isError bool
+	text    string
+}
+
+func redisCommand(rw *bufio.ReadWriter, args ...string) (*redisReply, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if err := writeFlush(rw, buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return readRedisReply(rw, 0)
+}
+
+func readRedisReply(rw *bufio.ReadWriter, depth int) (*redisReply, error) {
+	if depth > maxRedisReplyDepth {
+		return nil, ErrTCPProbeMalformedReply
+	}
+
+	line, err := readTextLine(rw)
+	if err != nil {
+		return nil, err
+	}
+
+	if line == "" {
+		return nil, ErrTCPProbeMalformedReply
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return &redisReply{text: line[1:]}, nil
+	case '-':
+		return &redisReply{isError: true, text: line[1:]}, nil
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, ErrTCPProbeMalformedReply
+		}
+
+		if size < 0 {
+			return &redisReply{}, nil
+		}
+
+		data, err := readFullMessage(rw, size+2)
+		if err != nil {
+			return nil, err
+		}
+
+		return &redisReply{text: string(data[:size])}, nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, ErrTCPProbeMalformedReply
+		}
+
+		for i := 0; i < count; i++ {
+			if _, err := readRedisReply(rw, depth+1); err != nil {
+				return nil, err
+			}
+		}
+
+		return &redisReply{}, nil
+	default:
+		return nil, ErrTCPProbeMalformedReply
+	}
+}
+
+func redisErrorStatus(text string) string {
+	switch {
+	case strings.HasPrefix(text, "NOAUTH"),
+		strings.HasPrefix(text, "WRONGPASS"),
+		strings.HasPrefix(text, "NOPERM"):
+		return TCPProbeStatusAuthRequired
+	default:
+		return TCPProbeStatusErrorReply
+	}
+}
+
+func redisProbe(rw *bufio.ReadWriter, result *TCPProbeResult) error {
+	reply, err := redisCommand(rw, "PING")
+	if err != nil {
+		return err
+	}
+
+	result.done("PING")
+	if reply.isError {
+		result.Status = redisErrorStatus(reply.text)
+		result.Message = reply.text
+		return nil
+	}
+
+	reply, err = redisCommand(rw, "INFO", "server")
+	if err != nil {
+		return err
+	}
+
+	result.done("INFO")
+	if reply.isError {
+		result.Status = redisErrorStatus(reply.text)
+		result.Message = reply.text
+		return nil
+	}
+
+	for _, line := range strings.Split(reply.text, "\n") {
+		if version := strings.TrimPrefix(line, "redis_version:"); version != line {
+			result.setServer("redis " + version)
+			break
+		}
+	}
+
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Memcached (text protocol)
+
+func memcachedProbe(rw *bufio.ReadWriter, result *TCPProbeResult) error {
+	if err := writeFlush(rw, []byte("version\r\n")); err != nil {
+		return err
+	}
+
+	line, err := readTextLine(rw)
+	if err != nil {
+		return err
+	}
+
+	result.done("version")
+	if version := strings.TrimPrefix(line, "VERSION "); version != line {
+		result.setServer("memcached " + version)
+	} else {
+		result.Status = TCPProbeStatusErrorReply
+		result.Message = line
+		return nil
+	}
+
+	if err := writeFlush(rw, []byte("stats\r\n")); err != nil {
+		return err
+	}
+
+	for {
+		line, err := readTextLine(rw)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "END":
+			result.done("stats")
+			return nil
+		case strings.HasPrefix(line, "STAT "):
+			continue
+		default:
+			result.done("stats")
+			result.Status = TCPProbeStatusErrorReply
+			result.Message = line
+			return nil
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// NATS
+
+type natsServerInfo struct {
+	Version      string `json:"version"`
+	AuthRequired bool   `json:"auth_required"`
+	TLSRequired  bool   `json:"tls_required"`
+}
+
+func natsProbe(rw *bufio.ReadWriter, result *TCPProbeResult) error {
+	line, err := readTextLine(rw)
+	if err != nil {
+		return err
+	}
+
+	infoData := strings.TrimPrefix(line, "INFO ")
+	if infoData == line {
+		return ErrTCPProbeMalformedReply
+	}
+
+	var info natsServerInfo
+	if err := json.Unmarshal([]byte(infoData), &info); err != nil {
+		return ErrTCPProbeMalformedReply
+	}
+
+	result.setServer("nats " + info.Version)
+	if info.TLSRequired {
+		result.Status = TCPProbeStatusTLSRequired
+		return nil
+	}
+
+	connectOpts, _ := json.Marshal(map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     tcpProbeClientName,
+		"lang":     "go",
+		"protocol": 0,
+	})
+
+	if err := writeFlush(rw, []byte(fmt.Sprintf("CONNECT %s\r\nPING\r\n", connectOpts))); err != nil {
+		return err
+	}
+
+	for {
+		line, err := readTextLine(rw)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			result.done("CONNECT")
+			result.done("PING")
+			return nil
+		case line == "PING":
+			if err := writeFlush(rw, []byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			result.done("CONNECT")
+			result.Status = TCPProbeStatusErrorReply
+			if info.AuthRequired || strings.Contains(strings.ToLower(line), "authorization") {
+				result.Status = TCPProbeStatusAuthRequired
+			}
+
+			result.Message = strings.Trim(strings.TrimPrefix(line, "-ERR "), "'")
+			return nil
+		case strings.HasPrefix(line, "+OK"), strings.HasPrefix(line, "INFO "):
+			continue
+		default:
+			return ErrTCPProbeMalformedReply
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PostgreSQL (frontend/backend protocol v3)
+
+const (
+	postgresProtocolVersion = 196608 //3.0
+	postgresAuthOK          = 0
+)
+
+func writePostgresMessage(rw *bufio.ReadWriter, msgType byte, payload []byte) error {
+	var buf bytes.Buffer
+	if msgType != 0 {
+		buf.WriteByte(msgType)
+	}
+
+	binary.Write(&buf, binary.BigEndian, int32(len(payload)+4))
+	buf.Write(payload)
+	return writeFlush(rw, buf.Bytes())
+}
+
+func readPostgresMessage(rw *bufio.ReadWriter) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(rw, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	size := int(binary.BigEndian.Uint32(header[1:]))
+	if size < 4 {
+		return 0, nil, ErrTCPProbeMalformedReply
+	}
+
+	payload, err := readFullMessage(rw, size-4)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], payload, nil
+}
+
+// postgresError returns the status and the message for the 'ErrorResponse' message
+func postgresError(payload []byte) (string, string) {
+	var code, message string
+	for _, field := range bytes.Split(payload, []byte{0}) {
+		if len(field) < 2 {
+			continue
+		}
+
+		switch field[0] {
+		case 'C':
+			code = string(field[1:])
+		case 'M':
+			message = string(field[1:])
+		}
+	}
+
+	//class 28 - invalid authorization specification
+	if strings.HasPrefix(code, "28") {
+		return TCPProbeStatusAuthRequired, message
+	}
+
+	return TCPProbeStatusErrorReply, message
+}
+
+// postgresProbe connects as the 'postgres' user without a password
+// and runs 'SELECT 1' if the server doesn't require authentication
+func postgresProbe(rw *bufio.ReadWriter, result *TCPProbeResult) error {
+	var startup bytes.Buffer
+	binary.Write(&startup, binary.BigEndian, int32(postgresProtocolVersion))
+	for _, param := range []string{
+		"user", postgresProbeUser,
+		"database", postgresProbeUser,
+		"application_name", tcpProbeClientName,
+	} {
+		startup.WriteString(param)
+		startup.WriteByte(0)
+	}
+	startup.WriteByte(0)
+
+	if err := writePostgresMessage(rw, 0, startup.Bytes()); err != nil {
+		return err
+	}
+
+	command := "startup"
+	for {
+		msgType, payload, err := readPostgresMessage(rw)
+		if err != nil {
+			return err
+		}
+
+		switch msgType {
+		case 'R':
+			if len(payload) < 4 {
+				return ErrTCPProbeMalformedReply
+			}
+
+			if binary.BigEndian.Uint32(payload) != postgresAuthOK {
+				result.done(command)
+				result.Status = TCPProbeStatusAuthRequired
+				return nil
+			}
+		case 'S':
+			parts := bytes.Split(payload, []byte{0})
+			if len(parts) > 1 && string(parts[0]) == "server_version" {
+				result.setServer("postgresql " + string(parts[1]))
+			}
+		case 'E':
+			result.done(command)
+			result.Status, result.Message = postgresError(payload)
+			return nil
+		case 'Z':
+			//ReadyForQuery
+			result.done(command)
+			if command != "startup" {
+				writePostgresMessage(rw, 'X', nil)
+				return nil
+			}
+
+			command = "SELECT 1"
+			if err := writePostgresMessage(rw, 'Q', append([]byte(command), 0)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// MySQL (client/server protocol)
+
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientTransactions     = 0x00002000
+	mysqlClientSecureConnection = 0x00008000
+	mysqlClientMultiResults     = 0x00020000
+	mysqlClientPluginAuth       = 0x00080000
+
+	mysqlComQuit  = 0x01
+	mysqlComQuery = 0x03
+	mysqlComPing  = 0x0e
+
+	mysqlPacketOK     = 0x00
+	mysqlPacketEOF    = 0xfe
+	mysqlPacketErr    = 0xff
+	mysqlAuthMoreData = 0x01
+	mysqlFastAuthOK   = 0x03
+
+	mysqlErrAccessDenied = 1045
+	mysqlCharsetUTF8     = 33
+	mysqlMaxPacketSize   = 16777215
+)
+
+type mysqlConn struct {
+	rw  *bufio.ReadWriter
+	seq byte
+}
+
+func (c *mysqlConn) readPacket() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	c.seq = header[3] + 1
+	payload, err := readFullMessage(c.rw, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) == 0 {
+		return nil, ErrTCPProbeMalformedReply
+	}
+
+	return payload, nil
+}
+
+func (c *mysqlConn) writePacket(payload []byte) error {
+	size := len(payload)
+	header := []byte{byte(size), byte(size >> 8), byte(size >> 16), c.seq}
+	c.seq++
+	return writeFlush(c.rw, append(header, payload...))
+}
+
+func (c *mysqlConn) command(cmd byte, arg string) error {
+	c.seq = 0
+	return c.writePacket(append([]byte{cmd}, arg...))
+}
+
+func isMySQLEOF(payload []byte) bool {
+	return payload[0] == mysqlPacketEOF && len(payload) < 9
+}
+
+// mysqlError returns the status and the message for the 'ERR' packet
+func mysqlError(payload []byte) (string, string) {
+	if len(payload) < 3 {
+		return TCPProbeStatusErrorReply, ""
+	}
+
+	code := binary.LittleEndian.Uint16(payload[1:3])
+	message := payload[3:]
+	if len(message) > 6 && message[0] == '#' {
+		//skip the SQL state
+		message = message[6:]
+	}
+
+	status := TCPProbeStatusErrorReply
+	if code == mysqlErrAccessDenied {
+		status = TCPProbeStatusAuthRequired
+	}
+
+	return status, string(message)
+}
+
+// mysqlGreeting returns the server version and the auth plugin name from the initial handshake packet
+func mysqlGreeting(payload []byte) (string, string, error) {
+	if payload[0] != 10 {
+		return "", "", ErrTCPProbeMalformedReply
+	}
+
+	rest := payload[1:]
+	idx := bytes.IndexByte(rest, 0)
+	if idx == -1 {
+		return "", "", ErrTCPProbeMalformedReply
+	}
+
+	version := string(rest[:idx])
+	//connection id (4), auth data part 1 (8), filler (1), capability flags (2),
+	//charset (1), status flags (2), capability flags (2), auth data length (1), reserved (10)
+	rest = rest[idx+1:]
+	const fixedLen = 4 + 8 + 1 + 2 + 1 + 2 + 2 + 1 + 10
+	if len(rest) < fixedLen {
+		return version, "", nil
+	}
+
+	authDataLen := int(rest[4+8+1+2+1+2+2])
+	rest = rest[fixedLen:]
+	part2Len := authDataLen - 8
+	if part2Len < 13 {
+		part2Len = 13
+	}
+
+	if len(rest) < part2Len {
+		return version, "", nil
+	}
+
+	plugin := rest[part2Len:]
+	if idx := bytes.IndexByte(plugin, 0); idx != -1 {
+		plugin = plugin[:idx]
+	}
+
+	return version, string(plugin), nil
+}
+
+// mysqlAuth completes the authentication exchange (for the user without a password)
+// and returns false if the server rejected the user
+func mysqlAuth(c *mysqlConn, result *TCPProbeResult) (bool, error) {
+	//allow one auth method switch
+	for switched := false; ; {
+		payload, err := c.readPacket()
+		if err != nil {
+			return false, err
+		}
+
+		switch payload[0] {
+		case mysqlPacketOK:
+			return true, nil
+		case mysqlPacketErr:
+			result.Status, result.Message = mysqlError(payload)
+			return false, nil
+		case mysqlAuthMoreData:
+			if len(payload) > 1 && payload[1] == mysqlFastAuthOK {
+				continue
+			}
+
+			//full authentication needs a secure connection
+			result.Status = TCPProbeStatusAuthRequired
+			return false, nil
+		case mysqlPacketEOF:
+			if switched {
+				return false, ErrTCPProbeMalformedReply
+			}
+
+			//auth switch request (the empty password has an empty auth response)
+			switched = true
+			if err := c.writePacket(nil); err != nil {
+				return false, err
+			}
+		default:
+			return false, ErrTCPProbeMalformedReply
+		}
+	}
+}
+
+// mysqlQuery runs the query and reads the result set
+func mysqlQuery(c *mysqlConn, query string, result *TCPProbeResult) error {
+	if err := c.command(mysqlComQuery, query); err != nil {
+		return err
+	}
+
+	payload, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+
+	result.done(query)
+	switch payload[0] {
+	case mysqlPacketOK:
+		return nil
+	case mysqlPacketErr:
+		result.Status, result.Message = mysqlError(payload)
+		return nil
+	}
+
+	//column definitions and rows (each terminated by an EOF packet)
+	for eofCount := 0; eofCount < 2; {
+		payload, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case isMySQLEOF(payload):
+			eofCount++
+		case payload[0] == mysqlPacketErr:
+			result.Status, result.Message = mysqlError(payload)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// mysqlProbe connects as the 'root' user without a password
+// and runs the 'PING' and 'SELECT 1' commands if the server accepts the user
+func mysqlProbe(rw *bufio.ReadWriter, result *TCPProbeResult) error {
+	c := &mysqlConn{rw: rw}
+	payload, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+
+	if payload[0] == mysqlPacketErr {
+		//e.g., the client host is not allowed to connect
+		result.Status, result.Message = mysqlError(payload)
+		return nil
+	}
+
+	version, plugin, err := mysqlGreeting(payload)
+	if err != nil {
+		return err
+	}
+
+	result.setServer("mysql " + version)
+
+	var response bytes.Buffer
+	binary.Write(&response, binary.LittleEndian, uint32(mysqlClientLongPassword|
+		mysqlClientProtocol41|
+		mysqlClientTransactions|
+		mysqlClientSecureConnection|
+		mysqlClientMultiResults|
+		mysqlClientPluginAuth))
+	binary.Write(&response, binary.LittleEndian, uint32(mysqlMaxPacketSize))
+	response.WriteByte(mysqlCharsetUTF8)
+	response.Write(make([]byte, 23))
+	response.WriteString(mysqlProbeUser)
+	response.WriteByte(0)
+	response.WriteByte(0) //empty auth response
+	response.WriteString(plugin)
+	response.WriteByte(0)
+
+	if err := c.writePacket(response.Bytes()); err != nil {
+		return err
+	}
+
+	ok, err := mysqlAuth(c, result)
+	result.done("handshake")
+	if err != nil || !ok {
+		return err
+	}
+
+	if err := c.command(mysqlComPing, ""); err != nil {
+		return err
+	}
+
+	if payload, err = c.readPacket(); err != nil {
+		return err
+	}
+
+	result.done("PING")
+	if payload[0] == mysqlPacketErr {
+		result.Status, result.Message = mysqlError(payload)
+		return nil
+	}
+
+	if err := mysqlQuery(c, "SELECT 1", result); err != nil {
+		return err
+	}
+
+	c.command(mysqlComQuit, "")
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// MongoDB (wire protocol)
+
+const (
+	mongoOpReply = 1
+	mongoOpQuery = 2004
+	mongoOpMsg   = 2013
+
+	//OP_MSG is supported starting with MongoDB 3.6
+	mongoOpMsgMinWireVersion = 6
+	mongoErrUnauthorized     = 13
+)
+
+type mongoConn struct {
+	rw        *bufio.ReadWriter
+	requestID int32
+}
+
+func (c *mongoConn) send(opCode int32, body []byte) error {
+	c.requestID++
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, int32(len(body)+16))
+	binary.Write(&buf, binary.LittleEndian, c.requestID)
+	binary.Write(&buf, binary.LittleEndian, int32(0))
+	binary.Write(&buf, binary.LittleEndian, opCode)
+	buf.Write(body)
+	return writeFlush(c.rw, buf.Bytes())
+}
+
+// reply returns the first reply document
+func (c *mongoConn) reply() (map[string]interface{}, error) {
+	var header [16]byte
+	if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := int(int32(binary.LittleEndian.Uint32(header[0:4])))
+	body, err := readFullMessage(c.rw, size-16)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opCode := binary.LittleEndian.Uint32(header[12:16]); opCode {
+	case mongoOpReply:
+		//response flags (4), cursor id (8), starting from (4), number returned (4)
+		if len(body) < 20 {
+			return nil, ErrTCPProbeMalformedReply
+		}
+
+		return decodeBSON(body[20:])
+	case mongoOpMsg:
+		//flag bits (4), section kind (1)
+		if len(body) < 5 || body[4] != 0 {
+			return nil, ErrTCPProbeMalformedReply
+		}
+
+		return decodeBSON(body[5:])
+	default:
+		return nil, ErrTCPProbeMalformedReply
+	}
+}
+
+// command runs the database command using the legacy OP_QUERY (for the handshake) or OP_MSG
+func (c *mongoConn) command(useOpMsg bool, name string) (map[string]interface{}, error) {
+	var body bytes.Buffer
+	if useOpMsg {
+		binary.Write(&body, binary.LittleEndian, uint32(0))
+		body.WriteByte(0)
+		body.Write(encodeBSON(name, "admin"))
+		if err := c.send(mongoOpMsg, body.Bytes()); err != nil {
+			return nil, err
+		}
+	} else {
+		binary.Write(&body, binary.LittleEndian, int32(0))
+		body.WriteString("admin.$cmd")
+		body.WriteByte(0)
+		binary.Write(&body, binary.LittleEndian, int32(0))
+		binary.Write(&body, binary.LittleEndian, int32(-1))
+		body.Write(encodeBSON(name, ""))
+		if err := c.send(mongoOpQuery, body.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.reply()
+}
+
+// mongoCommandError returns the status and the message for the failed commands
+func mongoCommandError(doc map[string]interface{}) (string, string, bool) {
+	if bsonNumber(doc["ok"]) == 1 {
+		return "", "", false
+	}
+
+	message, _ := doc["errmsg"].(string)
+	if bsonNumber(doc["code"]) == mongoErrUnauthorized {
+		return TCPProbeStatusAuthRequired, message, true
+	}
+
+	return TCPProbeStatusErrorReply, message, true
+}
+
+// mongoProbe runs the 'isMaster' handshake and the 'buildInfo' command
+func mongoProbe(rw *bufio.ReadWriter, result *TCPProbeResult) error {
+	c := &mongoConn{rw: rw}
+	doc, err := c.command(false, "isMaster")
+	if err != nil {
+		return err
+	}
+
+	result.done("isMaster")
+	if status, message, failed := mongoCommandError(doc); failed {
+		result.Status = status
+		result.Message = message
+		return nil
+	}
+
+	wireVersion := bsonNumber(doc["maxWireVersion"])
+	doc, err = c.command(wireVersion >= mongoOpMsgMinWireVersion, "buildInfo")
+	if err != nil {
+		return err
+	}
+
+	result.done("buildInfo")
+	if status, message, failed := mongoCommandError(doc); failed {
+		result.Status = status
+		result.Message = message
+		return nil
+	}
+
+	if version, ok := doc["version"].(string); ok {
+		result.setServer("mongodb " + version)
+	}
+
+	return nil
+}
+
+// encodeBSON returns the command document ({<name>: 1, $db: <db>})
+func encodeBSON(name, db string) []byte {
+	var elements bytes.Buffer
+	elements.WriteByte(0x10) //int32
+	elements.WriteString(name)
+	elements.WriteByte(0)
+	binary.Write(&elements, binary.LittleEndian, int32(1))
+	if db != "" {
+		elements.WriteByte(0x02) //string
+		elements.WriteString("$db")
+		elements.WriteByte(0)
+		binary.Write(&elements, binary.LittleEndian, int32(len(db)+1))
+		elements.WriteString(db)
+		elements.WriteByte(0)
+	}
+
+	var doc bytes.Buffer
+	binary.Write(&doc, binary.LittleEndian, int32(elements.Len()+5))
+	doc.Write(elements.Bytes())
+	doc.WriteByte(0)
+	return doc.Bytes()
+}
+
+// decodeBSON returns the top level scalar values from the BSON document
+// (the embedded documents and the other value types are skipped)
+func decodeBSON(data []byte) (map[string]interface{}, error) {
+	if len(data) < 5 {
+		return nil, ErrTCPProbeMalformedReply
+	}
+
+	size := int(int32(binary.LittleEndian.Uint32(data)))
+	if size < 5 || size > len(data) {
+		return nil, ErrTCPProbeMalformedReply
+	}
+
+	doc := map[string]interface{}{}
+	data = data[4 : size-1]
+	for len(data) > 0 {
+		valueType := data[0]
+		idx := bytes.IndexByte(data[1:], 0)
+		if idx == -1 {
+			return nil, ErrTCPProbeMalformedReply
+		}
+
+		name := string(data[1 : idx+1])
+		data = data[idx+2:]
+
+		valueLen, err := bsonValueLen(valueType, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if valueLen > len(data) {
+			return nil, ErrTCPProbeMalformedReply
+		}
+
+		value := data[:valueLen]
+		data = data[valueLen:]
+		switch valueType {
+		case 0x01:
+			doc[name] = math.Float64frombits(binary.LittleEndian.Uint64(value))
+		case 0x02:
+			if len(value) < 5 {
+				return nil, ErrTCPProbeMalformedReply
+			}
+
+			doc[name] = string(value[4 : len(value)-1])
+		case 0x08:
+			doc[name] = value[0] == 1
+		case 0x10:
+			doc[name] = int64(int32(binary.LittleEndian.Uint32(value)))
+		case 0x12:
+			doc[name] = int64(binary.LittleEndian.Uint64(value))
+		}
+	}
+
+	return doc, nil
+}
+
+func bsonNumber(value interface{}) float64 {
+	switch typed := value.(type) {
+	case float64:
+		return typed
+	case int64:
+		return float64(typed)
+	default:
+		return 0
+	}
+}
+
+func bsonValueLen(valueType byte, data []byte) (int, error) {
+	int32Len := func(extra int) (int, error) {
+		if len(data) < 4 {
+			return 0, ErrTCPProbeMalformedReply
+		}
+
+		size := int(int32(binary.LittleEndian.Uint32(data))) + extra
+		if size < 4 {
+			return 0, ErrTCPProbeMalformedReply
+		}
+
+		return size, nil
+	}
+
+	switch valueType {
+	case 0x06, 0x0A, 0x7F, 0xFF: //undefined, null, max key, min key
+		return 0, nil
+	case 0x08: //bool
+		return 1, nil
+	case 0x10: //int32
+		return 4, nil
+	case 0x01, 0x09, 0x11, 0x12: //double, datetime, timestamp, int64
+		return 8, nil
+	case 0x07: //object id
+		return 12, nil
+	case 0x13: //decimal128
+		return 16, nil
+	case 0x02, 0x0D, 0x0E: //string, javascript, symbol
+		return int32Len(4)
+	case 0x03, 0x04, 0x0F: //document, array, javascript with scope
+		return int32Len(0)
+	case 0x05: //binary
+		return int32Len(5)
+	case 0x0C: //db pointer
+		size, err := int32Len(4)
+		return size + 12, err
+	case 0x0B: //regex
+		first := bytes.IndexByte(data, 0)
+		if first == -1 {
+			return 0, ErrTCPProbeMalformedReply
+		}
+
+		second := bytes.IndexByte(data[first+1:], 0)
+		if second == -1 {
+			return 0, ErrTCPProbeMalformedReply
+		}
+
+		return first + second + 2, nil
+	default:
+		return 0, ErrTCPProbeMalformedReply
+	}
+}
@@ -0,0 +1,373 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConn returns the connection reading the server replies and the buffer with the client writes
+func testConn(replies string) (*bufio.ReadWriter, *bytes.Buffer) {
+	var out bytes.Buffer
+	return bufio.NewReadWriter(bufio.NewReader(strings.NewReader(replies)), bufio.NewWriter(&out)), &out
+}
+
+func mysqlPacket(seq byte, payload []byte) string {
+	size := len(payload)
+	return string(append([]byte{byte(size), byte(size >> 8), byte(size >> 16), seq}, payload...))
+}
+
+func mysqlHandshake(version, plugin string) []byte {
+	var payload bytes.Buffer
+	payload.WriteByte(10)
+	payload.WriteString(version)
+	payload.WriteByte(0)
+	payload.Write([]byte{1, 0, 0, 0})           //connection id
+	payload.Write(bytes.Repeat([]byte{'a'}, 8)) //auth data part 1
+	payload.WriteByte(0)                        //filler
+	payload.Write([]byte{0xff, 0xff})           //capability flags
+	payload.WriteByte(mysqlCharsetUTF8)
+	payload.Write([]byte{2, 0})       //status flags
+	payload.Write([]byte{0xff, 0xff}) //capability flags
+	payload.WriteByte(21)             //auth data length
+	payload.Write(make([]byte, 10))
+	payload.Write(bytes.Repeat([]byte{'b'}, 12)) //auth data part 2
+	payload.WriteByte(0)
+	payload.WriteString(plugin)
+	payload.WriteByte(0)
+	return payload.Bytes()
+}
+
+func mysqlErrPacket(code uint16, message string) []byte {
+	payload := []byte{mysqlPacketErr, byte(code), byte(code >> 8)}
+	return append(payload, message...)
+}
+
+// bsonDoc wraps the encoded elements into a BSON document
+func bsonDoc(elements ...[]byte) []byte {
+	data := bytes.Join(elements, nil)
+	var doc bytes.Buffer
+	binary.Write(&doc, binary.LittleEndian, int32(len(data)+5))
+	doc.Write(data)
+	doc.WriteByte(0)
+	return doc.Bytes()
+}
+
+func bsonElement(valueType byte, name string, value []byte) []byte {
+	element := append([]byte{valueType}, name...)
+	element = append(element, 0)
+	return append(element, value...)
+}
+
+func bsonString(value string) []byte {
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, int32(len(value)+1))
+	data.WriteString(value)
+	data.WriteByte(0)
+	return data.Bytes()
+}
+
+func TestReadRedisReply(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expected *redisReply
+		err      error
+	}{
+		{name: "simple string", data: "+PONG\r\n", expected: &redisReply{text: "PONG"}},
+		{name: "integer", data: ":42\r\n", expected: &redisReply{text: "42"}},
+		{name: "error", data: "-NOAUTH Authentication required.\r\n", expected: &redisReply{isError: true, text: "NOAUTH Authentication required."}},
+		{name: "bulk string", data: "$27\r\n# Server\r\nredis_version:7.2\r\n", expected: &redisReply{text: "# Server\r\nredis_version:7.2"}},
+		{name: "null bulk string", data: "$-1\r\n", expected: &redisReply{}},
+		{name: "array", data: "*2\r\n$3\r\nfoo\r\n*1\r\n:1\r\n", expected: &redisReply{}},
+		{name: "empty line", data: "\r\n", err: ErrTCPProbeMalformedReply},
+		{name: "unknown type", data: "?x\r\n", err: ErrTCPProbeMalformedReply},
+		{name: "bad bulk size", data: "$x\r\n", err: ErrTCPProbeMalformedReply},
+		{name: "bad array size", data: "*x\r\n", err: ErrTCPProbeMalformedReply},
+		{name: "large bulk string", data: "$2000000\r\n", err: ErrTCPProbeMalformedReply},
+		{name: "short bulk string", data: "$10\r\nabc\r\n", err: io.ErrUnexpectedEOF},
+		{name: "no line end", data: "+PONG", err: io.EOF},
+		{name: "nested arrays", data: strings.Repeat("*1\r\n", maxRedisReplyDepth+2), err: ErrTCPProbeMalformedReply},
+	}
+
+	for _, test := range tt {
+		rw, _ := testConn(test.data)
+		reply, err := readRedisReply(rw, 0)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err, test.name)
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, reply, test.name)
+	}
+}
+
+func TestRedisErrorStatus(t *testing.T) {
+	tt := map[string]string{
+		"NOAUTH Authentication required.":     TCPProbeStatusAuthRequired,
+		"WRONGPASS invalid username-password": TCPProbeStatusAuthRequired,
+		"NOPERM this user has no permissions": TCPProbeStatusAuthRequired,
+		"ERR unknown command 'INFO'":          TCPProbeStatusErrorReply,
+		"":                                    TCPProbeStatusErrorReply,
+	}
+
+	for text, expected := range tt {
+		assert.Equal(t, expected, redisErrorStatus(text), text)
+	}
+}
+
+func TestRedisProbe(t *testing.T) {
+	rw, out := testConn("+PONG\r\n$29\r\n# Server\r\nredis_version:7.2.4\r\n")
+	result := &TCPProbeResult{Status: TCPProbeStatusOK}
+	require.NoError(t, redisProbe(rw, result))
+	assert.Equal(t, "*1\r\n$4\r\nPING\r\n*2\r\n$4\r\nINFO\r\n$6\r\nserver\r\n", out.String())
+	assert.Equal(t, TCPProbeStatusOK, result.Status)
+	assert.Equal(t, "redis 7.2.4", result.Server)
+	assert.Equal(t, []string{"PING", "INFO"}, result.Commands)
+
+	rw, _ = testConn("-NOAUTH Authentication required.\r\n")
+	result = &TCPProbeResult{Status: TCPProbeStatusOK}
+	require.NoError(t, redisProbe(rw, result))
+	assert.Equal(t, TCPProbeStatusAuthRequired, result.Status)
+	assert.Equal(t, "NOAUTH Authentication required.", result.Message)
+	assert.Equal(t, []string{"PING"}, result.Commands)
+}
+
+func TestMySQLReadPacket(t *testing.T) {
+	rw, _ := testConn(mysqlPacket(3, []byte{mysqlPacketOK, 0, 0}))
+	c := &mysqlConn{rw: rw}
+	payload, err := c.readPacket()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{mysqlPacketOK, 0, 0}, payload)
+	assert.Equal(t, byte(4), c.seq)
+
+	tt := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{name: "empty payload", data: mysqlPacket(0, nil), err: ErrTCPProbeMalformedReply},
+		{name: "large payload", data: "\xff\xff\xff\x00", err: ErrTCPProbeMalformedReply},
+		{name: "short header", data: "\x01\x00", err: io.ErrUnexpectedEOF},
+		{name: "short payload", data: "\x05\x00\x00\x00abc", err: io.ErrUnexpectedEOF},
+	}
+
+	for _, test := range tt {
+		rw, _ := testConn(test.data)
+		_, err := (&mysqlConn{rw: rw}).readPacket()
+		assert.ErrorIs(t, err, test.err, test.name)
+	}
+}
+
+func TestMySQLWritePacket(t *testing.T) {
+	rw, out := testConn("")
+	c := &mysqlConn{rw: rw, seq: 5}
+	require.NoError(t, c.command(mysqlComQuery, "SELECT 1"))
+	require.NoError(t, c.writePacket(nil))
+	assert.Equal(t, mysqlPacket(0, []byte("\x03SELECT 1"))+mysqlPacket(1, nil), out.String())
+	assert.Equal(t, byte(2), c.seq)
+}
+
+func TestMySQLGreeting(t *testing.T) {
+	version, plugin, err := mysqlGreeting(mysqlHandshake("8.0.36", "caching_sha2_password"))
+	require.NoError(t, err)
+	assert.Equal(t, "8.0.36", version)
+	assert.Equal(t, "caching_sha2_password", plugin)
+
+	//no auth plugin name (or the truncated fixed fields)
+	payload := mysqlHandshake("5.5.62", "")
+	version, plugin, err = mysqlGreeting(payload[:len(payload)-1])
+	require.NoError(t, err)
+	assert.Equal(t, "5.5.62", version)
+	assert.Empty(t, plugin)
+
+	version, plugin, err = mysqlGreeting([]byte("\x0a5.1.73\x00\x01\x00"))
+	require.NoError(t, err)
+	assert.Equal(t, "5.1.73", version)
+	assert.Empty(t, plugin)
+
+	_, _, err = mysqlGreeting([]byte("\x098.0.36\x00"))
+	assert.ErrorIs(t, err, ErrTCPProbeMalformedReply)
+	_, _, err = mysqlGreeting([]byte("\x0a8.0.36"))
+	assert.ErrorIs(t, err, ErrTCPProbeMalformedReply)
+}
+
+func TestMySQLError(t *testing.T) {
+	tt := []struct {
+		name    string
+		payload []byte
+		status  string
+		message string
+	}{
+		{
+			name:    "access denied",
+			payload: mysqlErrPacket(mysqlErrAccessDenied, "#28000Access denied for user 'root'"),
+			status:  TCPProbeStatusAuthRequired,
+			message: "Access denied for user 'root'",
+		},
+		{
+			name:    "no sql state",
+			payload: mysqlErrPacket(1130, "Host is not allowed to connect"),
+			status:  TCPProbeStatusErrorReply,
+			message: "Host is not allowed to connect",
+		},
+		{
+			name:    "short packet",
+			payload: []byte{mysqlPacketErr, 1},
+			status:  TCPProbeStatusErrorReply,
+		},
+	}
+
+	for _, test := range tt {
+		status, message := mysqlError(test.payload)
+		assert.Equal(t, test.status, status, test.name)
+		assert.Equal(t, test.message, message, test.name)
+	}
+}
+
+func TestIsMySQLEOF(t *testing.T) {
+	assert.True(t, isMySQLEOF([]byte{mysqlPacketEOF, 0, 0, 2, 0}))
+	assert.False(t, isMySQLEOF(append([]byte{mysqlPacketEOF}, make([]byte, 8)...)))
+	assert.False(t, isMySQLEOF([]byte{mysqlPacketOK, 0, 0}))
+}
+
+func TestMySQLProbe(t *testing.T) {
+	eof := []byte{mysqlPacketEOF, 0, 0, 2, 0}
+	replies := mysqlPacket(0, mysqlHandshake("8.0.36", "caching_sha2_password")) +
+		mysqlPacket(2, []byte{mysqlAuthMoreData, mysqlFastAuthOK}) +
+		mysqlPacket(3, []byte{mysqlPacketOK, 0, 0}) +
+		mysqlPacket(1, []byte{mysqlPacketOK, 0, 0}) +
+		mysqlPacket(1, []byte{1}) +
+		mysqlPacket(2, []byte("\x03def\x00\x00\x00\x011\x00")) +
+		mysqlPacket(3, eof) +
+		mysqlPacket(4, []byte("\x011")) +
+		mysqlPacket(5, eof)
+
+	rw, out := testConn(replies)
+	result := &TCPProbeResult{Status: TCPProbeStatusOK}
+	require.NoError(t, mysqlProbe(rw, result))
+	assert.Equal(t, TCPProbeStatusOK, result.Status)
+	assert.Equal(t, "mysql 8.0.36", result.Server)
+	assert.Equal(t, []string{"handshake", "PING", "SELECT 1"}, result.Commands)
+	assert.Contains(t, out.String(), "root\x00\x00caching_sha2_password\x00")
+	assert.True(t, strings.HasSuffix(out.String(), mysqlPacket(0, []byte{mysqlComQuit})))
+
+	rw, _ = testConn(mysqlPacket(0, mysqlHandshake("8.0.36", "mysql_native_password")) +
+		mysqlPacket(2, mysqlErrPacket(mysqlErrAccessDenied, "#28000Access denied for user 'root'")))
+	result = &TCPProbeResult{Status: TCPProbeStatusOK}
+	require.NoError(t, mysqlProbe(rw, result))
+	assert.Equal(t, TCPProbeStatusAuthRequired, result.Status)
+	assert.Equal(t, "Access denied for user 'root'", result.Message)
+	assert.Equal(t, []string{"handshake"}, result.Commands)
+}
+
+func TestEncodeBSON(t *testing.T) {
+	int32One := []byte{1, 0, 0, 0}
+	assert.Equal(t, bsonDoc(bsonElement(0x10, "isMaster", int32One)), encodeBSON("isMaster", ""))
+	assert.Equal(t,
+		bsonDoc(bsonElement(0x10, "buildInfo", int32One), bsonElement(0x02, "$db", bsonString("admin"))),
+		encodeBSON("buildInfo", "admin"))
+
+	doc, err := decodeBSON(encodeBSON("buildInfo", "admin"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"buildInfo": int64(1), "$db": "admin"}, doc)
+}
+
+func TestDecodeBSON(t *testing.T) {
+	double := make([]byte, 8)
+	binary.LittleEndian.PutUint64(double, math.Float64bits(1))
+	int64Value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(int64Value, uint64(1<<40))
+
+	data := bsonDoc(
+		bsonElement(0x01, "ok", double),
+		bsonElement(0x02, "version", bsonString("7.0.5")),
+		bsonElement(0x08, "ismaster", []byte{1}),
+		bsonElement(0x10, "maxWireVersion", []byte{21, 0, 0, 0}),
+		bsonElement(0x12, "localTime", int64Value),
+		bsonElement(0x03, "topologyVersion", bsonDoc(bsonElement(0x0A, "x", nil))),
+		bsonElement(0x04, "versionArray", bsonDoc(bsonElement(0x10, "0", []byte{7, 0, 0, 0}))),
+		bsonElement(0x05, "binary", []byte{2, 0, 0, 0, 0, 'a', 'b'}),
+		bsonElement(0x07, "electionId", make([]byte, 12)),
+		bsonElement(0x0A, "null", nil),
+		bsonElement(0x0B, "regex", []byte("^a\x00i\x00")),
+		bsonElement(0x13, "decimal", make([]byte, 16)),
+	)
+
+	//extra bytes after the document are ignored
+	doc, err := decodeBSON(append(data, 0xff))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"ok":             float64(1),
+		"version":        "7.0.5",
+		"ismaster":       true,
+		"maxWireVersion": int64(21),
+		"localTime":      int64(1 << 40),
+	}, doc)
+
+	tt := []struct {
+		name string
+		data []byte
+	}{
+		{name: "short document", data: []byte{5, 0, 0}},
+		{name: "bad size", data: []byte{4, 0, 0, 0, 0}},
+		{name: "size over data", data: []byte{9, 0, 0, 0, 0}},
+		{name: "no name end", data: []byte{8, 0, 0, 0, 0x10, 'a', 'b', 0}},
+		{name: "unknown type", data: bsonDoc(bsonElement(0x20, "x", nil))},
+		{name: "short value", data: bsonDoc(bsonElement(0x12, "x", []byte{1, 0}))},
+		{name: "short string", data: bsonDoc(bsonElement(0x02, "x", []byte{0, 0, 0, 0}))},
+		{name: "bad string size", data: bsonDoc(bsonElement(0x02, "x", []byte{0xf0, 0xff, 0xff, 0xff}))},
+		{name: "no regex end", data: bsonDoc(bsonElement(0x0B, "x", []byte("^a\x00i")))},
+	}
+
+	for _, test := range tt {
+		_, err := decodeBSON(test.data)
+		assert.ErrorIs(t, err, ErrTCPProbeMalformedReply, test.name)
+	}
+}
+
+func TestBSONValueLen(t *testing.T) {
+	tt := []struct {
+		valueType byte
+		data      []byte
+		expected  int
+	}{
+		{valueType: 0x0A, expected: 0},
+		{valueType: 0x08, data: []byte{1}, expected: 1},
+		{valueType: 0x10, expected: 4},
+		{valueType: 0x09, expected: 8},
+		{valueType: 0x07, expected: 12},
+		{valueType: 0x13, expected: 16},
+		{valueType: 0x02, data: []byte{3, 0, 0, 0}, expected: 7},
+		{valueType: 0x03, data: []byte{5, 0, 0, 0}, expected: 5},
+		{valueType: 0x05, data: []byte{4, 0, 0, 0}, expected: 9},
+		{valueType: 0x0C, data: []byte{2, 0, 0, 0}, expected: 18},
+		{valueType: 0x0B, data: []byte("ab\x00c\x00"), expected: 5},
+	}
+
+	for _, test := range tt {
+		size, err := bsonValueLen(test.valueType, test.data)
+		require.NoError(t, err, test.valueType)
+		assert.Equal(t, test.expected, size, test.valueType)
+	}
+
+	_, err := bsonValueLen(0x02, []byte{1, 0})
+	assert.ErrorIs(t, err, ErrTCPProbeMalformedReply)
+	_, err = bsonValueLen(0x0B, []byte("ab"))
+	assert.ErrorIs(t, err, ErrTCPProbeMalformedReply)
+}
+
+func TestBSONNumber(t *testing.T) {
+	assert.Equal(t, 1.5, bsonNumber(1.5))
+	assert.Equal(t, 13.0, bsonNumber(int64(13)))
+	assert.Equal(t, 0.0, bsonNumber("13"))
+	assert.Equal(t, 0.0, bsonNumber(nil))
+}
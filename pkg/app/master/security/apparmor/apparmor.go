@@ -1,9 +1,11 @@
 package apparmor
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"text/template"
 
 	"github.com/slimtoolkit/slim/pkg/report"
@@ -12,13 +14,15 @@ import (
 const appArmorTemplate = `
 profile {{.ProfileName}} flags=(attach_disconnected,mediate_deleted) {
 
-  network,
-
-{{range $value := .ExeFileRules}}  {{$value.FilePath}} {{$value.PermSet}},
+{{range $value := .CapabilityRules}}  capability {{$value}},
+{{end}}
+{{range $value := .NetworkRules}}  {{$value}},
+{{end}}
+{{range $value := .ExeFileRules}}  {{if $value.Owner}}owner {{end}}{{$value.FilePath}} {{$value.PermSet}},
 {{end}}
-{{range $value := .WriteFileRules}}  {{$value.FilePath}} {{$value.PermSet}},
+{{range $value := .WriteFileRules}}  {{if $value.Owner}}owner {{end}}{{$value.FilePath}} {{$value.PermSet}},
 {{end}}
-{{range $value := .ReadFileRules}}  {{$value.FilePath}} {{$value.PermSet}},
+{{range $value := .ReadFileRules}}  {{if $value.Owner}}owner {{end}}{{$value.FilePath}} {{$value.PermSet}},
 {{end}}
 }
 `
@@ -26,13 +30,32 @@ profile {{.ProfileName}} flags=(attach_disconnected,mediate_deleted) {
 type appArmorFileRule struct {
 	FilePath string
 	PermSet  string
+	Owner    bool
 }
 
 type appArmorProfileData struct {
-	ProfileName    string
-	ExeFileRules   []appArmorFileRule
-	WriteFileRules []appArmorFileRule
-	ReadFileRules  []appArmorFileRule
+	ProfileName     string
+	CapabilityRules []string
+	NetworkRules    []string
+	ExeFileRules    []appArmorFileRule
+	WriteFileRules  []appArmorFileRule
+	ReadFileRules   []appArmorFileRule
+}
+
+// Socket address families (the 'socket' syscall 'domain' argument values)
+var socketFamilies = map[uint64]string{
+	1:  "unix",
+	2:  "inet",
+	10: "inet6",
+	16: "netlink",
+	17: "packet",
+}
+
+// Network monitor address families
+var netFamilies = map[string]string{
+	"ipv4": "inet",
+	"ipv6": "inet6",
+	"unix": "unix",
 }
 
 //TODO:
@@ -60,45 +83,41 @@ func GenProfile(artifactLocation string, profileName string) error {
 
 	profilePath := filepath.Join(artifactLocation, profileName)
 
-	profileFile, err := os.OpenFile(profilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return err
-	}
-
-	defer profileFile.Close()
-
-	profileData := appArmorProfileData{ProfileName: profileName}
-
+	files := map[string]*report.ArtifactProps{}
+	var exeFiles, writeFiles, readFiles []fileEntry
 	for _, aprops := range creport.Image.Files {
 		if aprops == nil {
 			continue
 		}
+
+		files[aprops.FilePath] = aprops
 		if aprops.Flags == nil {
 			//default to "R" (todo: double check flag creation...)
-			profileData.ReadFileRules = append(profileData.ReadFileRules,
-				appArmorFileRule{
-					FilePath: aprops.FilePath,
-					PermSet:  "r",
+			readFiles = append(readFiles,
+				fileEntry{
+					path:    aprops.FilePath,
+					permSet: "r",
 				})
 		} else {
 			switch {
 			case aprops.Flags["X"]:
-				profileData.ExeFileRules = append(profileData.ExeFileRules,
-					appArmorFileRule{
-						FilePath: aprops.FilePath,
-						PermSet:  report.PermSetFromFlags(aprops.Flags),
+				exeFiles = append(exeFiles,
+					fileEntry{
+						path:    aprops.FilePath,
+						permSet: report.PermSetFromFlags(aprops.Flags),
 					})
 			case aprops.Flags["W"]:
-				profileData.WriteFileRules = append(profileData.WriteFileRules,
-					appArmorFileRule{
-						FilePath: aprops.FilePath,
-						PermSet:  report.PermSetFromFlags(aprops.Flags),
+				writeFiles = append(writeFiles,
+					fileEntry{
+						path:    aprops.FilePath,
+						permSet: report.PermSetFromFlags(aprops.Flags),
+						owner:   fileOwnerAccess(&creport, aprops),
 					})
 			case aprops.Flags["R"]:
-				profileData.ReadFileRules = append(profileData.ReadFileRules,
-					appArmorFileRule{
-						FilePath: aprops.FilePath,
-						PermSet:  report.PermSetFromFlags(aprops.Flags),
+				readFiles = append(readFiles,
+					fileEntry{
+						path:    aprops.FilePath,
+						permSet: report.PermSetFromFlags(aprops.Flags),
 					})
 			default:
 				//logrus.Printf("slim: genAppArmorProfile - other artifact => %v\n", aprops)
@@ -107,14 +126,78 @@ func GenProfile(artifactLocation string, profileName string) error {
 		}
 	}
 
+	profileData := appArmorProfileData{
+		ProfileName:     profileName,
+		CapabilityRules: capabilityRules(&creport, files),
+		NetworkRules:    networkRules(&creport),
+		ExeFileRules:    fileRules(exeFiles),
+		WriteFileRules:  fileRules(writeFiles),
+		ReadFileRules:   fileRules(readFiles),
+	}
+
 	t, err := template.New("profile").Parse(appArmorTemplate)
 	if err != nil {
 		return err
 	}
 
-	if err := t.Execute(profileFile, profileData); err != nil {
+	var output bytes.Buffer
+	if err := t.Execute(&output, profileData); err != nil {
+		return err
+	}
+
+	//validating the generated profile before saving it
+	if _, err := ParseProfile(output.Bytes()); err != nil {
 		return err
 	}
 
-	return nil
+	return os.WriteFile(profilePath, output.Bytes(), 0644)
+}
+
+func fileRules(entries []fileEntry) []appArmorFileRule {
+	var rules []appArmorFileRule
+	for _, e := range collapseFileEntries(entries) {
+		rules = append(rules, appArmorFileRule{
+			FilePath: rulePath(e),
+			PermSet:  e.permSet,
+			Owner:    e.owner,
+		})
+	}
+
+	return rules
+}
+
+// networkRules creates the network rules for the observed socket families
+// (allowing all networking when the socket information is not available)
+func networkRules(creport *report.ContainerReport) []string {
+	pt := creport.Monitors.Pt
+	if pt == nil || !pt.Enabled || pt.SyscallArgs == nil {
+		return []string{"network"}
+	}
+
+	families := map[string]struct{}{}
+	for _, argInfo := range pt.SyscallArgs {
+		if argInfo == nil || argInfo.Name != "socket" {
+			continue
+		}
+
+		for val := range argInfo.Values {
+			if family, found := socketFamilies[val]; found {
+				families[family] = struct{}{}
+			}
+		}
+	}
+
+	forEachNetActivity(creport.Monitors.Net, func(info *report.NetActivityInfo, isListener bool) {
+		if family, found := netFamilies[info.Family]; found {
+			families[family] = struct{}{}
+		}
+	})
+
+	var rules []string
+	for family := range families {
+		rules = append(rules, "network "+family)
+	}
+
+	sort.Strings(rules)
+	return rules
 }
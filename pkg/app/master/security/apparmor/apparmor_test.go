@@ -0,0 +1,155 @@
+package apparmor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+func writeContainerReport(t *testing.T, creport *report.ContainerReport) string {
+	artifactLocation := t.TempDir()
+	data, err := json.Marshal(creport)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(artifactLocation, report.DefaultContainerReportFileName), data, 0644))
+	return artifactLocation
+}
+
+func TestGenProfile(t *testing.T) {
+	creport := &report.ContainerReport{}
+	creport.Image.Files = []*report.ArtifactProps{
+		{FilePath: "/app/bin/server", Flags: map[string]bool{"R": true, "X": true}, ModeText: "-rwxr-xr-x"},
+		{FilePath: "/app/data/app.db", Flags: map[string]bool{"R": true, "W": true}, ModeText: "-rw-------", UID: 1000},
+		{FilePath: "/app/out/app.log", Flags: map[string]bool{"W": true}, ModeText: "-rw-r--r--"},
+		{FilePath: "/tmp/upload-123", Flags: map[string]bool{"R": true, "W": true}, ModeText: "-rw-r--r--", UID: 1000},
+		{FilePath: "/app/my files/readme.txt", ModeText: "-rw-r--r--"},
+		{FilePath: "/lib/libc.so", Flags: map[string]bool{"R": false}, LinkRef: "libc.so.6"},
+	}
+
+	for i := 0; i < GlobThreshold; i++ {
+		creport.Image.Files = append(creport.Image.Files, &report.ArtifactProps{
+			FilePath: fmt.Sprintf("/app/static/file%d.js", i),
+			Flags:    map[string]bool{"R": true},
+			ModeText: "-rw-r--r--",
+		})
+	}
+
+	creport.Monitors.Fan = &report.FanMonitorReport{
+		Processes: map[string]*report.ProcessInfo{
+			"10": {Pid: 10, UID: 1000},
+			"11": {Pid: 11, UID: 0},
+		},
+		ProcessFiles: map[string]map[string]*report.FileInfo{
+			"10": {
+				"/app/data/app.db": {ReadCount: 1, WriteCount: 1},
+				"/tmp/upload-123":  {WriteCount: 1},
+				"/app/out/app.log": {WriteCount: 1},
+			},
+			"11": {
+				"/app/data/app.db": {ReadCount: 1},
+			},
+		},
+	}
+
+	creport.Monitors.Pt = &report.PtMonitorReport{
+		Enabled: true,
+		SyscallStats: map[string]report.SyscallStatInfo{
+			"92": {Name: "chown"},
+		},
+		SyscallArgs: map[string]*report.SyscallArgInfo{
+			"41": {Name: "socket", Values: map[uint64]uint64{2: 1, 1: 3}},
+		},
+	}
+
+	creport.Monitors.Net = &report.NetMonitorReport{
+		Listeners: map[string]*report.NetActivityInfo{
+			"tcp:0.0.0.0:80": {Protocol: "tcp", Family: "ipv4", Port: 80},
+		},
+	}
+
+	artifactLocation := writeContainerReport(t, creport)
+	require.NoError(t, GenProfile(artifactLocation, "app-apparmor-profile"))
+
+	data, err := os.ReadFile(filepath.Join(artifactLocation, "app-apparmor-profile"))
+	require.NoError(t, err)
+
+	profile, err := ParseProfile(data)
+	require.NoError(t, err)
+	assert.Equal(t, "app-apparmor-profile", profile.Name)
+
+	//root process reading the app.db file not readable by root (dac_read_search)
+	assert.Equal(t, []string{"chown", "dac_read_search", "net_bind_service"}, profile.Capabilities)
+	assert.Equal(t, []NetworkRule{{Family: "inet"}, {Family: "unix"}}, profile.Network)
+	assert.Equal(t, []FileRule{
+		{Path: "/app/bin/server", PermSet: "rix"},
+		{Path: "/app/data/app.db", PermSet: "rw"},
+		{Path: "/app/out/app.log", PermSet: "w"},
+		{Owner: true, Path: "/tmp/**", PermSet: "rw"},
+		{Path: "/app/my files/readme.txt", PermSet: "r"},
+		{Path: "/app/static/**", PermSet: "r"},
+	}, profile.Files)
+}
+
+func TestGenProfileNetworkFallback(t *testing.T) {
+	creport := &report.ContainerReport{}
+	creport.Image.Files = []*report.ArtifactProps{
+		{FilePath: "/app/server", Flags: map[string]bool{"R": true, "X": true}},
+	}
+
+	artifactLocation := writeContainerReport(t, creport)
+	require.NoError(t, GenProfile(artifactLocation, "app-apparmor-profile"))
+
+	data, err := os.ReadFile(filepath.Join(artifactLocation, "app-apparmor-profile"))
+	require.NoError(t, err)
+
+	profile, err := ParseProfile(data)
+	require.NoError(t, err)
+	assert.Empty(t, profile.Capabilities)
+	assert.Equal(t, []NetworkRule{{}}, profile.Network)
+
+	assert.Error(t, GenProfile(t.TempDir(), "app-apparmor-profile"))
+}
+
+func TestFileOwnerAccess(t *testing.T) {
+	creport := &report.ContainerReport{}
+	props := &report.ArtifactProps{FilePath: "/app/data/app.db", UID: 1000}
+	assert.False(t, fileOwnerAccess(creport, props))
+
+	creport.Monitors.Fan = &report.FanMonitorReport{
+		Processes: map[string]*report.ProcessInfo{
+			"10": {Pid: 10, UID: 1000},
+			"11": {Pid: 11, UID: 0},
+			"12": {Pid: 12, UID: -1},
+		},
+		ProcessFiles: map[string]map[string]*report.FileInfo{},
+	}
+
+	tt := []struct {
+		name     string
+		pids     []string
+		expected bool
+	}{
+		{name: "not accessed"},
+		{name: "owner process", pids: []string{"10"}, expected: true},
+		{name: "owner and root processes", pids: []string{"10", "11"}},
+		{name: "unknown uid", pids: []string{"12"}},
+		{name: "unknown process", pids: []string{"13"}},
+	}
+
+	for _, test := range tt {
+		creport.Monitors.Fan.ProcessFiles = map[string]map[string]*report.FileInfo{}
+		for _, pid := range test.pids {
+			creport.Monitors.Fan.ProcessFiles[pid] = map[string]*report.FileInfo{
+				props.FilePath: {WriteCount: 1},
+			}
+		}
+
+		assert.Equal(t, test.expected, fileOwnerAccess(creport, props), test.name)
+	}
+}
@@ -0,0 +1,295 @@
+package apparmor
+
+import (
+	"sort"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+// Privileged syscalls and the capabilities they (might) need
+var syscallCapabilities = map[string][]string{
+	"chown":              {"chown"},
+	"chown32":            {"chown"},
+	"fchown":             {"chown"},
+	"fchown32":           {"chown"},
+	"fchownat":           {"chown"},
+	"lchown":             {"chown"},
+	"lchown32":           {"chown"},
+	"chmod":              {"fowner"},
+	"fchmod":             {"fowner"},
+	"fchmodat":           {"fowner"},
+	"setuid":             {"setuid"},
+	"setuid32":           {"setuid"},
+	"setreuid":           {"setuid"},
+	"setreuid32":         {"setuid"},
+	"setresuid":          {"setuid"},
+	"setresuid32":        {"setuid"},
+	"setfsuid":           {"setuid"},
+	"setfsuid32":         {"setuid"},
+	"setgid":             {"setgid"},
+	"setgid32":           {"setgid"},
+	"setregid":           {"setgid"},
+	"setregid32":         {"setgid"},
+	"setresgid":          {"setgid"},
+	"setresgid32":        {"setgid"},
+	"setfsgid":           {"setgid"},
+	"setfsgid32":         {"setgid"},
+	"setgroups":          {"setgid"},
+	"setgroups32":        {"setgid"},
+	"capset":             {"setpcap"},
+	"chroot":             {"sys_chroot"},
+	"mknod":              {"mknod"},
+	"mknodat":            {"mknod"},
+	"mount":              {"sys_admin"},
+	"umount":             {"sys_admin"},
+	"umount2":            {"sys_admin"},
+	"pivot_root":         {"sys_admin"},
+	"unshare":            {"sys_admin"},
+	"setns":              {"sys_admin"},
+	"sethostname":        {"sys_admin"},
+	"setdomainname":      {"sys_admin"},
+	"swapon":             {"sys_admin"},
+	"swapoff":            {"sys_admin"},
+	"quotactl":           {"sys_admin"},
+	"ptrace":             {"sys_ptrace"},
+	"process_vm_readv":   {"sys_ptrace"},
+	"process_vm_writev":  {"sys_ptrace"},
+	"reboot":             {"sys_boot"},
+	"kexec_load":         {"sys_boot"},
+	"settimeofday":       {"sys_time"},
+	"clock_settime":      {"sys_time"},
+	"clock_adjtime":      {"sys_time"},
+	"adjtimex":           {"sys_time"},
+	"stime":              {"sys_time"},
+	"init_module":        {"sys_module"},
+	"finit_module":       {"sys_module"},
+	"delete_module":      {"sys_module"},
+	"iopl":               {"sys_rawio"},
+	"ioperm":             {"sys_rawio"},
+	"setpriority":        {"sys_nice"},
+	"sched_setscheduler": {"sys_nice"},
+	"sched_setparam":     {"sys_nice"},
+	"sched_setattr":      {"sys_nice"},
+	"setrlimit":          {"sys_resource"},
+	"prlimit64":          {"sys_resource"},
+	"acct":               {"sys_pacct"},
+	"syslog":             {"syslog"},
+}
+
+// Signal syscalls (the 'kill' capability is needed only to signal the processes of other users)
+var signalSyscalls = map[string]struct{}{
+	"kill":   {},
+	"tkill":  {},
+	"tgkill": {},
+}
+
+// All capability names (used to validate the generated profiles)
+var capabilityNames = map[string]struct{}{
+	"audit_control":      {},
+	"audit_read":         {},
+	"audit_write":        {},
+	"block_suspend":      {},
+	"bpf":                {},
+	"checkpoint_restore": {},
+	"chown":              {},
+	"dac_override":       {},
+	"dac_read_search":    {},
+	"fowner":             {},
+	"fsetid":             {},
+	"ipc_lock":           {},
+	"ipc_owner":          {},
+	"kill":               {},
+	"lease":              {},
+	"linux_immutable":    {},
+	"mac_admin":          {},
+	"mac_override":       {},
+	"mknod":              {},
+	"net_admin":          {},
+	"net_bind_service":   {},
+	"net_broadcast":      {},
+	"net_raw":            {},
+	"perfmon":            {},
+	"setfcap":            {},
+	"setgid":             {},
+	"setpcap":            {},
+	"setuid":             {},
+	"sys_admin":          {},
+	"sys_boot":           {},
+	"sys_chroot":         {},
+	"sys_module":         {},
+	"sys_nice":           {},
+	"sys_pacct":          {},
+	"sys_ptrace":         {},
+	"sys_rawio":          {},
+	"sys_resource":       {},
+	"sys_time":           {},
+	"sys_tty_config":     {},
+	"syslog":             {},
+	"wake_alarm":         {},
+}
+
+const (
+	privilegedPortMax = 1024
+	afPacket          = 17
+)
+
+// capabilityRules derives the capabilities from the observed privileged syscalls,
+// the network activity and the file access by the root processes
+func capabilityRules(creport *report.ContainerReport, files map[string]*report.ArtifactProps) []string {
+	caps := map[string]struct{}{}
+	if pt := creport.Monitors.Pt; pt != nil && pt.Enabled {
+		hasSignals := false
+		for _, scInfo := range pt.SyscallStats {
+			for _, name := range syscallCapabilities[scInfo.Name] {
+				caps[name] = struct{}{}
+			}
+
+			if _, found := signalSyscalls[scInfo.Name]; found {
+				hasSignals = true
+			}
+		}
+
+		if hasSignals && len(processUIDs(creport)) > 1 {
+			caps["kill"] = struct{}{}
+		}
+
+		for _, argInfo := range pt.SyscallArgs {
+			if argInfo != nil && argInfo.Name == "socket" {
+				if _, found := argInfo.Values[afPacket]; found {
+					caps["net_raw"] = struct{}{}
+				}
+			}
+		}
+	}
+
+	forEachNetActivity(creport.Monitors.Net, func(info *report.NetActivityInfo, isListener bool) {
+		if info.Protocol == "raw" {
+			caps["net_raw"] = struct{}{}
+		}
+
+		if isListener && info.Port > 0 && info.Port < privilegedPortMax {
+			caps["net_bind_service"] = struct{}{}
+		}
+	})
+
+	for name := range dacCapabilities(creport, files) {
+		caps[name] = struct{}{}
+	}
+
+	var result []string
+	for name := range caps {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// dacCapabilities returns the DAC capabilities needed by the root processes
+// to access the files owned by other users (when the file modes don't allow the access)
+func dacCapabilities(creport *report.ContainerReport, files map[string]*report.ArtifactProps) map[string]struct{} {
+	caps := map[string]struct{}{}
+	fan := creport.Monitors.Fan
+	if fan == nil {
+		return caps
+	}
+
+	for pid, pfiles := range fan.ProcessFiles {
+		pinfo, found := fan.Processes[pid]
+		if !found || pinfo == nil || pinfo.UID != 0 {
+			continue
+		}
+
+		for fpath, finfo := range pfiles {
+			props, found := files[fpath]
+			if !found || props.UID == 0 || finfo == nil {
+				continue
+			}
+
+			perms := modePerms(props.ModeText)
+			if finfo.WriteCount > 0 && perms[7] != 'w' {
+				caps["dac_override"] = struct{}{}
+			}
+
+			if (finfo.ReadCount > 0 || finfo.ExeCount > 0) && perms[6] != 'r' {
+				caps["dac_read_search"] = struct{}{}
+			}
+		}
+	}
+
+	return caps
+}
+
+// modePerms returns the 9 permission characters from the file mode text
+// (e.g., "-rw-r--r--" -> "rw-r--r--")
+func modePerms(modeText string) string {
+	if len(modeText) < 9 {
+		return "---------"
+	}
+
+	return modeText[len(modeText)-9:]
+}
+
+// processUIDs returns the known file system UIDs of the app processes
+func processUIDs(creport *report.ContainerReport) map[int]struct{} {
+	uids := map[int]struct{}{}
+	if creport.Monitors.Fan == nil {
+		return uids
+	}
+
+	for _, pinfo := range creport.Monitors.Fan.Processes {
+		if pinfo != nil && pinfo.UID >= 0 {
+			uids[pinfo.UID] = struct{}{}
+		}
+	}
+
+	return uids
+}
+
+// fileOwnerAccess returns true if all app processes that accessed the file
+// run with the file system UID of the file owner (so the 'owner' qualifier can be used)
+func fileOwnerAccess(creport *report.ContainerReport, props *report.ArtifactProps) bool {
+	fan := creport.Monitors.Fan
+	if fan == nil {
+		return false
+	}
+
+	accessed := false
+	for pid, pfiles := range fan.ProcessFiles {
+		if _, found := pfiles[props.FilePath]; !found {
+			continue
+		}
+
+		pinfo, found := fan.Processes[pid]
+		if !found || pinfo == nil || pinfo.UID < 0 || pinfo.UID != props.UID {
+			return false
+		}
+
+		accessed = true
+	}
+
+	return accessed
+}
+
+func forEachNetActivity(netReport *report.NetMonitorReport, fn func(info *report.NetActivityInfo, isListener bool)) {
+	if netReport == nil {
+		return
+	}
+
+	visit := func(activity map[string]*report.NetActivityInfo, isListener bool) {
+		for _, info := range activity {
+			if info != nil {
+				fn(info, isListener)
+			}
+		}
+	}
+
+	visit(netReport.Listeners, true)
+	visit(netReport.Connections, false)
+	for _, pinfo := range netReport.Processes {
+		if pinfo != nil {
+			visit(pinfo.Listeners, true)
+			visit(pinfo.Connections, false)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package apparmor
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GlobThreshold is the number of sibling paths (with the same permissions)
+// collapsed into one directory glob rule
+const GlobThreshold = 10
+
+// Volatile directories (the paths there are always collapsed into
+// directory glob rules because the file names change between the runs)
+var volatileDirs = []string{
+	"/tmp",
+	"/var/tmp",
+	"/dev/shm",
+	"/run",
+}
+
+type fileEntry struct {
+	path    string
+	glob    bool //path is a directory (rule for everything under it)
+	permSet string
+	owner   bool
+}
+
+type fileGroupKey struct {
+	dir     string
+	permSet string
+	owner   bool
+}
+
+// collapseFileEntries replaces the paths in the volatile directories
+// and the groups of sibling paths with the same permissions (above the glob threshold)
+// with directory glob entries and removes the paths covered by the globs
+func collapseFileEntries(entries []fileEntry) []fileEntry {
+	var result []fileEntry
+	seen := map[fileEntry]struct{}{}
+	add := func(e fileEntry) {
+		if _, found := seen[e]; !found {
+			seen[e] = struct{}{}
+			result = append(result, e)
+		}
+	}
+
+	for _, e := range entries {
+		if dir := volatileDir(e.path); dir != "" {
+			e.path = dir
+			e.glob = true
+		}
+
+		add(e)
+	}
+
+	for {
+		groups := map[fileGroupKey][]int{}
+		for idx, e := range result {
+			dir := filepath.Dir(e.path)
+			if dir == "/" || dir == "." {
+				continue
+			}
+
+			key := fileGroupKey{dir: dir, permSet: e.permSet, owner: e.owner}
+			groups[key] = append(groups[key], idx)
+		}
+
+		collapsed := map[int]struct{}{}
+		var globs []fileEntry
+		for key, idxList := range groups {
+			if len(idxList) < GlobThreshold {
+				continue
+			}
+
+			for _, idx := range idxList {
+				collapsed[idx] = struct{}{}
+			}
+
+			globs = append(globs, fileEntry{
+				path:    key.dir,
+				glob:    true,
+				permSet: key.permSet,
+				owner:   key.owner,
+			})
+		}
+
+		if len(globs) == 0 {
+			break
+		}
+
+		current := result
+		result = nil
+		seen = map[fileEntry]struct{}{}
+		for idx, e := range current {
+			if _, found := collapsed[idx]; !found {
+				add(e)
+			}
+		}
+
+		for _, e := range globs {
+			add(e)
+		}
+	}
+
+	//removing the paths covered by the globs with the same permissions
+	var final []fileEntry
+	for _, e := range result {
+		if !isCoveredEntry(e, result) {
+			final = append(final, e)
+		}
+	}
+
+	sort.Slice(final, func(i, j int) bool {
+		if final[i].path != final[j].path {
+			return final[i].path < final[j].path
+		}
+
+		return final[i].permSet < final[j].permSet
+	})
+
+	return final
+}
+
+func isCoveredEntry(e fileEntry, entries []fileEntry) bool {
+	for _, g := range entries {
+		if !g.glob || g == e || g.permSet != e.permSet || (g.owner && !e.owner) {
+			continue
+		}
+
+		if strings.HasPrefix(e.path, g.path+"/") {
+			return true
+		}
+
+		//the owner glob is redundant when the same glob is allowed for everybody
+		if e.glob && e.owner && !g.owner && e.path == g.path {
+			return true
+		}
+	}
+
+	return false
+}
+
+func volatileDir(fpath string) string {
+	for _, dir := range volatileDirs {
+		if strings.HasPrefix(fpath, dir+"/") {
+			return dir
+		}
+	}
+
+	return ""
+}
+
+// rulePath returns the AppArmor rule path for the file entry
+// (escaping the glob characters in the literal path names
+// and quoting the paths with whitespace and other special characters)
+func rulePath(e fileEntry) string {
+	var b strings.Builder
+	for _, ch := range e.path {
+		switch ch {
+		case '*', '?', '[', ']', '{', '}', '^', '"', '\\':
+			b.WriteRune('\\')
+		}
+
+		b.WriteRune(ch)
+	}
+
+	if e.glob {
+		b.WriteString("/**")
+	}
+
+	result := b.String()
+	if strings.ContainsAny(result, " \t,#") {
+		result = `"` + result + `"`
+	}
+
+	return result
+}
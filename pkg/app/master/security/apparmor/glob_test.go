@@ -0,0 +1,148 @@
+package apparmor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func siblingEntries(dir string, count int, permSet string, owner bool) []fileEntry {
+	var entries []fileEntry
+	for i := 0; i < count; i++ {
+		entries = append(entries, fileEntry{
+			path:    fmt.Sprintf("%s/file%02d", dir, i),
+			permSet: permSet,
+			owner:   owner,
+		})
+	}
+
+	return entries
+}
+
+func concatEntries(lists ...[]fileEntry) []fileEntry {
+	var result []fileEntry
+	for _, list := range lists {
+		result = append(result, list...)
+	}
+
+	return result
+}
+
+func TestCollapseFileEntries(t *testing.T) {
+	var nested []fileEntry
+	for i := 0; i < GlobThreshold; i++ {
+		nested = append(nested, siblingEntries(fmt.Sprintf("/app/cache/d%d", i), GlobThreshold, "rw", false)...)
+	}
+
+	tt := []struct {
+		name     string
+		entries  []fileEntry
+		expected []fileEntry
+	}{
+		{
+			name: "volatile dirs",
+			entries: []fileEntry{
+				{path: "/tmp/app.sock", permSet: "rw"},
+				{path: "/tmp/x/y/z.tmp", permSet: "rw"},
+				{path: "/run/app.pid", permSet: "rw", owner: true},
+				{path: "/var/tmp/cache", permSet: "r"},
+				{path: "/tmpfile", permSet: "r"},
+			},
+			expected: []fileEntry{
+				{path: "/run", glob: true, permSet: "rw", owner: true},
+				{path: "/tmp", glob: true, permSet: "rw"},
+				{path: "/tmpfile", permSet: "r"},
+				{path: "/var/tmp", glob: true, permSet: "r"},
+			},
+		},
+		{
+			name:     "below the threshold",
+			entries:  siblingEntries("/app/static", GlobThreshold-1, "r", false),
+			expected: siblingEntries("/app/static", GlobThreshold-1, "r", false),
+		},
+		{
+			name:    "at the threshold",
+			entries: siblingEntries("/app/static", GlobThreshold, "r", false),
+			expected: []fileEntry{
+				{path: "/app/static", glob: true, permSet: "r"},
+			},
+		},
+		{
+			name: "different permissions",
+			entries: concatEntries(
+				siblingEntries("/app/data", GlobThreshold, "r", false),
+				[]fileEntry{{path: "/app/data/db", permSet: "rw"}}),
+			expected: []fileEntry{
+				{path: "/app/data", glob: true, permSet: "r"},
+				{path: "/app/data/db", permSet: "rw"},
+			},
+		},
+		{
+			name: "owner groups",
+			entries: concatEntries(
+				siblingEntries("/app/logs", GlobThreshold, "rw", true),
+				siblingEntries("/app/logs", 2, "rw", false)),
+			expected: []fileEntry{
+				{path: "/app/logs", glob: true, permSet: "rw", owner: true},
+				{path: "/app/logs/file00", permSet: "rw"},
+				{path: "/app/logs/file01", permSet: "rw"},
+			},
+		},
+		{
+			name: "owner glob covers the owner paths",
+			entries: []fileEntry{
+				{path: "/run/app/app.sock", permSet: "rw", owner: true},
+				{path: "/run", glob: true, permSet: "rw"},
+			},
+			expected: []fileEntry{
+				{path: "/run", glob: true, permSet: "rw"},
+			},
+		},
+		{
+			name:    "nested globs",
+			entries: nested,
+			expected: []fileEntry{
+				{path: "/app/cache", glob: true, permSet: "rw"},
+			},
+		},
+		{
+			name:     "root dir",
+			entries:  siblingEntries("", GlobThreshold, "r", false),
+			expected: siblingEntries("", GlobThreshold, "r", false),
+		},
+		{
+			name: "duplicates",
+			entries: []fileEntry{
+				{path: "/etc/hosts", permSet: "r"},
+				{path: "/etc/hosts", permSet: "r"},
+			},
+			expected: []fileEntry{
+				{path: "/etc/hosts", permSet: "r"},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, collapseFileEntries(test.entries), test.name)
+	}
+}
+
+func TestRulePath(t *testing.T) {
+	tt := []struct {
+		entry    fileEntry
+		expected string
+	}{
+		{entry: fileEntry{path: "/etc/hosts"}, expected: "/etc/hosts"},
+		{entry: fileEntry{path: "/tmp", glob: true}, expected: "/tmp/**"},
+		{entry: fileEntry{path: "/app/[id]/*.js"}, expected: `/app/\[id\]/\*.js`},
+		{entry: fileEntry{path: "/app/{a,b}?"}, expected: `"/app/\{a,b\}\?"`},
+		{entry: fileEntry{path: "/app/my files", glob: true}, expected: `"/app/my files/**"`},
+		{entry: fileEntry{path: `/app/a"b\c`}, expected: `/app/a\"b\\c`},
+		{entry: fileEntry{path: "/app/#1"}, expected: `"/app/#1"`},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, rulePath(test.entry), test.entry.path)
+	}
+}
@@ -0,0 +1,229 @@
+package apparmor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Profile is a parsed AppArmor profile
+// (only the rule types created by the profile generator are supported)
+type Profile struct {
+	Name         string
+	Flags        []string
+	Capabilities []string
+	Network      []NetworkRule
+	Files        []FileRule
+}
+
+// NetworkRule is a parsed network rule (an empty rule allows all networking)
+type NetworkRule struct {
+	Family string
+	Type   string
+}
+
+// FileRule is a parsed file rule
+type FileRule struct {
+	Owner   bool
+	Path    string
+	PermSet string
+}
+
+var networkFamilies = map[string]struct{}{
+	"unix":    {},
+	"inet":    {},
+	"inet6":   {},
+	"netlink": {},
+	"packet":  {},
+}
+
+var networkTypes = map[string]struct{}{
+	"stream": {},
+	"dgram":  {},
+	"raw":    {},
+}
+
+const filePermChars = "rwaxilkmuUpPcC"
+
+// ParseProfile parses and validates an AppArmor profile created by the profile generator
+func ParseProfile(data []byte) (*Profile, error) {
+	var profile *Profile
+	closed := false
+	lineNum := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if closed {
+			return nil, fmt.Errorf("apparmor: line %d - unexpected content after the profile", lineNum)
+		}
+
+		if profile == nil {
+			var err error
+			if profile, err = parseProfileHeader(line); err != nil {
+				return nil, fmt.Errorf("apparmor: line %d - %w", lineNum, err)
+			}
+
+			continue
+		}
+
+		if line == "}" {
+			closed = true
+			continue
+		}
+
+		if !strings.HasSuffix(line, ",") {
+			return nil, fmt.Errorf("apparmor: line %d - rule is not terminated with a comma", lineNum)
+		}
+
+		rule := strings.TrimSpace(strings.TrimSuffix(line, ","))
+		if err := profile.parseRule(rule); err != nil {
+			return nil, fmt.Errorf("apparmor: line %d - %w", lineNum, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if profile == nil {
+		return nil, fmt.Errorf("apparmor: no profile")
+	}
+
+	if !closed {
+		return nil, fmt.Errorf("apparmor: profile is not closed")
+	}
+
+	return profile, nil
+}
+
+// parseProfileHeader parses the "profile NAME flags=(FLAGS) {" line
+func parseProfileHeader(line string) (*Profile, error) {
+	if !strings.HasSuffix(line, "{") {
+		return nil, fmt.Errorf("malformed profile header")
+	}
+
+	fields := strings.Fields(strings.TrimSuffix(line, "{"))
+	if len(fields) < 2 || fields[0] != "profile" {
+		return nil, fmt.Errorf("malformed profile header")
+	}
+
+	profile := &Profile{Name: fields[1]}
+	for _, field := range fields[2:] {
+		flags, found := strings.CutPrefix(field, "flags=(")
+		if !found || !strings.HasSuffix(flags, ")") {
+			return nil, fmt.Errorf("malformed profile header field - %s", field)
+		}
+
+		profile.Flags = strings.Split(strings.TrimSuffix(flags, ")"), ",")
+	}
+
+	return profile, nil
+}
+
+func (p *Profile) parseRule(rule string) error {
+	fields := strings.Fields(rule)
+	switch fields[0] {
+	case "capability":
+		if len(fields) != 2 {
+			return fmt.Errorf("malformed capability rule")
+		}
+
+		if _, found := capabilityNames[fields[1]]; !found {
+			return fmt.Errorf("unknown capability - %s", fields[1])
+		}
+
+		p.Capabilities = append(p.Capabilities, fields[1])
+	case "network":
+		var nr NetworkRule
+		if len(fields) > 1 {
+			nr.Family = fields[1]
+			if _, found := networkFamilies[nr.Family]; !found {
+				return fmt.Errorf("unknown network family - %s", nr.Family)
+			}
+		}
+
+		if len(fields) > 2 {
+			nr.Type = fields[2]
+			if _, found := networkTypes[nr.Type]; !found {
+				return fmt.Errorf("unknown network type - %s", nr.Type)
+			}
+		}
+
+		if len(fields) > 3 {
+			return fmt.Errorf("malformed network rule")
+		}
+
+		p.Network = append(p.Network, nr)
+	default:
+		fr, err := parseFileRule(rule)
+		if err != nil {
+			return err
+		}
+
+		p.Files = append(p.Files, *fr)
+	}
+
+	return nil
+}
+
+func parseFileRule(rule string) (*FileRule, error) {
+	var fr FileRule
+	if rest, found := strings.CutPrefix(rule, "owner "); found {
+		fr.Owner = true
+		rule = strings.TrimSpace(rest)
+	}
+
+	var rest string
+	if strings.HasPrefix(rule, `"`) {
+		end := closingQuote(rule)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated quoted path")
+		}
+
+		fr.Path = rule[1:end]
+		rest = rule[end+1:]
+	} else {
+		var found bool
+		fr.Path, rest, found = strings.Cut(rule, " ")
+		if !found {
+			return nil, fmt.Errorf("file rule without permissions")
+		}
+	}
+
+	if !strings.HasPrefix(fr.Path, "/") {
+		return nil, fmt.Errorf("file rule path is not absolute - %s", fr.Path)
+	}
+
+	fr.PermSet = strings.TrimSpace(rest)
+	if fr.PermSet == "" {
+		return nil, fmt.Errorf("file rule without permissions - %s", fr.Path)
+	}
+
+	for _, ch := range fr.PermSet {
+		if !strings.ContainsRune(filePermChars, ch) {
+			return nil, fmt.Errorf("unknown file permission (%c) - %s", ch, fr.Path)
+		}
+	}
+
+	return &fr, nil
+}
+
+// closingQuote returns the index of the (unescaped) closing quote
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
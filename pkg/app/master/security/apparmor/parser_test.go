@@ -0,0 +1,82 @@
+package apparmor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProfile(t *testing.T) {
+	data := `
+# generated profile
+profile app-apparmor-profile flags=(attach_disconnected,mediate_deleted) {
+
+  capability chown,
+  capability net_bind_service,
+
+  network inet,
+  network inet6 stream,
+
+  /app/bin/server rix,
+  owner /app/data/** rw,
+  "/app/my files/**" r,
+  "/app/a\"b" r,
+  /app/\[id\] r,
+}
+`
+
+	profile, err := ParseProfile([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, &Profile{
+		Name:         "app-apparmor-profile",
+		Flags:        []string{"attach_disconnected", "mediate_deleted"},
+		Capabilities: []string{"chown", "net_bind_service"},
+		Network: []NetworkRule{
+			{Family: "inet"},
+			{Family: "inet6", Type: "stream"},
+		},
+		Files: []FileRule{
+			{Path: "/app/bin/server", PermSet: "rix"},
+			{Owner: true, Path: "/app/data/**", PermSet: "rw"},
+			{Path: "/app/my files/**", PermSet: "r"},
+			{Path: `/app/a\"b`, PermSet: "r"},
+			{Path: `/app/\[id\]`, PermSet: "r"},
+		},
+	}, profile)
+
+	profile, err = ParseProfile([]byte("profile app {\n  network,\n}\n"))
+	require.NoError(t, err)
+	assert.Empty(t, profile.Flags)
+	assert.Equal(t, []NetworkRule{{}}, profile.Network)
+}
+
+func TestParseProfileErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: "# nothing\n"},
+		{name: "no header brace", data: "profile app\n}\n"},
+		{name: "no profile keyword", data: "app {\n}\n"},
+		{name: "bad header field", data: "profile app flags=attach_disconnected {\n}\n"},
+		{name: "not closed", data: "profile app {\n  network,\n"},
+		{name: "content after the profile", data: "profile app {\n}\n  network,\n"},
+		{name: "no comma", data: "profile app {\n  network\n}\n"},
+		{name: "unknown capability", data: "profile app {\n  capability fly,\n}\n"},
+		{name: "malformed capability", data: "profile app {\n  capability chown kill,\n}\n"},
+		{name: "unknown network family", data: "profile app {\n  network bluetooth,\n}\n"},
+		{name: "unknown network type", data: "profile app {\n  network inet seqpacket,\n}\n"},
+		{name: "malformed network rule", data: "profile app {\n  network inet stream tcp,\n}\n"},
+		{name: "relative path", data: "profile app {\n  app/bin r,\n}\n"},
+		{name: "no permissions", data: "profile app {\n  /app/bin,\n}\n"},
+		{name: "quoted path without permissions", data: "profile app {\n  \"/app/my bin\" ,\n}\n"},
+		{name: "unterminated quote", data: "profile app {\n  \"/app/my bin r,\n}\n"},
+		{name: "unknown permission", data: "profile app {\n  /app/bin rz,\n}\n"},
+	}
+
+	for _, test := range tt {
+		_, err := ParseProfile([]byte(test.data))
+		assert.Error(t, err, test.name)
+	}
+}
@@ -0,0 +1,404 @@
+package netpolicy
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+const (
+	// AnnotationEgressHostnames lists the host names resolved by the target app
+	// (NetworkPolicy peers can't reference DNS names)
+	AnnotationEgressHostnames = "slimtoolkit.org/egress-hostnames"
+	// LabelApp is the pod selector label
+	LabelApp = "app"
+
+	policyNameSuffix = "-network-policy"
+	dnsPort          = 53
+
+	// The default Linux ephemeral port range
+	// (the unconnected UDP client sockets are bound to the ephemeral ports)
+	ephemeralPortMin = 32768
+	ephemeralPortMax = 60999
+)
+
+// Port is an observed protocol port
+type Port struct {
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+}
+
+// Destination is an observed egress destination
+type Destination struct {
+	Address string `json:"address"`
+	Family  string `json:"family"`
+	Port
+}
+
+// Traffic is the network traffic observed monitoring the target container
+type Traffic struct {
+	Ingress   []Port        `json:"ingress,omitempty"`
+	Egress    []Destination `json:"egress,omitempty"`
+	HasDNS    bool          `json:"has_dns"`
+	Resolvers []string      `json:"resolvers,omitempty"`
+	Hostnames []string      `json:"hostnames,omitempty"`
+}
+
+// DockerNetworkConfig is the Docker container network config for the observed traffic
+// (the Docker Engine API container create config fields)
+type DockerNetworkConfig struct {
+	ExposedPorts nat.PortSet `json:"ExposedPorts,omitempty"`
+	HostConfig   struct {
+		NetworkMode  string      `json:"NetworkMode"`
+		PortBindings nat.PortMap `json:"PortBindings,omitempty"`
+		DNS          []string    `json:"Dns,omitempty"`
+	} `json:"HostConfig"`
+	// Informational (Docker doesn't have egress rules)
+	Egress []Destination `json:"Egress,omitempty"`
+}
+
+// GenProfile creates a Kubernetes NetworkPolicy manifest and a Docker network config
+// from the network activity observed monitoring the target container
+func GenProfile(artifactLocation string, policyName string, dockerConfigName string) error {
+	containerReportFilePath := filepath.Join(artifactLocation, report.DefaultContainerReportFileName)
+
+	if _, err := os.Stat(containerReportFilePath); err != nil {
+		return err
+	}
+	reportFile, err := os.Open(containerReportFilePath)
+	if err != nil {
+		return err
+	}
+	defer reportFile.Close()
+
+	var creport report.ContainerReport
+	if err = json.NewDecoder(reportFile).Decode(&creport); err != nil {
+		return err
+	}
+
+	if creport.Monitors.Net == nil || !creport.Monitors.Net.Enabled {
+		log.Debug("netpolicy.GenProfile: not generating network policy (NET mon disabled, no network info)")
+		return nil
+	}
+
+	traffic := ObservedTraffic(creport.Monitors.Net)
+
+	name := strings.TrimSuffix(policyName, filepath.Ext(policyName))
+	policy := NetworkPolicy(traffic, name)
+	policyData, err := yaml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	policyPath := filepath.Join(artifactLocation, policyName)
+	log.Debug("netpolicy.GenProfile: saving network policy to ", policyPath)
+	if err := os.WriteFile(policyPath, policyData, 0644); err != nil {
+		return err
+	}
+
+	configData, err := json.MarshalIndent(DockerConfig(traffic), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(artifactLocation, dockerConfigName)
+	log.Debug("netpolicy.GenProfile: saving Docker network config to ", configPath)
+	return os.WriteFile(configPath, configData, 0644)
+}
+
+// ObservedTraffic extracts the ingress ports and the egress destinations
+// from the network monitor report (ignoring the loopback and unix socket traffic)
+func ObservedTraffic(netReport *report.NetMonitorReport) *Traffic {
+	traffic := &Traffic{}
+	ingress := map[Port]struct{}{}
+	egress := map[Destination]struct{}{}
+	resolvers := map[string]struct{}{}
+
+	addListeners := func(listeners map[string]*report.NetActivityInfo) {
+		for _, info := range listeners {
+			if !isObservable(info) || isLoopback(info.Address) {
+				continue
+			}
+
+			//TCP sockets need to listen (bind alone is used by the clients too)
+			if info.Protocol == report.NetProtoTCP && info.Ops[report.NetOpListen] == 0 {
+				continue
+			}
+
+			//sampled UDP sockets need to be explicitly bound or use a non-ephemeral port
+			if info.Protocol == report.NetProtoUDP &&
+				info.Ops[report.NetOpBind] == 0 &&
+				info.Port >= ephemeralPortMin && info.Port <= ephemeralPortMax {
+				continue
+			}
+
+			ingress[Port{Protocol: info.Protocol, Port: info.Port}] = struct{}{}
+		}
+	}
+
+	addConnections := func(connections map[string]*report.NetActivityInfo) {
+		for _, info := range connections {
+			if !isObservable(info) || isLoopback(info.Address) || isUnspecified(info.Address) {
+				continue
+			}
+
+			if info.Port == dnsPort {
+				traffic.HasDNS = true
+				resolvers[info.Address] = struct{}{}
+				continue
+			}
+
+			egress[Destination{
+				Address: info.Address,
+				Family:  info.Family,
+				Port:    Port{Protocol: info.Protocol, Port: info.Port},
+			}] = struct{}{}
+		}
+	}
+
+	addListeners(netReport.Listeners)
+	addConnections(netReport.Connections)
+	for _, pinfo := range netReport.Processes {
+		addListeners(pinfo.Listeners)
+		addConnections(pinfo.Connections)
+
+		for _, lookup := range pinfo.DNSLookups {
+			traffic.HasDNS = true
+			for resolver := range lookup.Resolvers {
+				if host, _, err := net.SplitHostPort(resolver); err == nil {
+					resolver = host
+				}
+
+				if !isLoopback(resolver) {
+					resolvers[resolver] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for port := range ingress {
+		traffic.Ingress = append(traffic.Ingress, port)
+	}
+
+	sort.Slice(traffic.Ingress, func(i, j int) bool {
+		return portLess(traffic.Ingress[i], traffic.Ingress[j])
+	})
+
+	for dest := range egress {
+		traffic.Egress = append(traffic.Egress, dest)
+	}
+
+	sort.Slice(traffic.Egress, func(i, j int) bool {
+		if traffic.Egress[i].Address != traffic.Egress[j].Address {
+			return traffic.Egress[i].Address < traffic.Egress[j].Address
+		}
+
+		return portLess(traffic.Egress[i].Port, traffic.Egress[j].Port)
+	})
+
+	for resolver := range resolvers {
+		traffic.Resolvers = append(traffic.Resolvers, resolver)
+	}
+
+	sort.Strings(traffic.Resolvers)
+	traffic.Hostnames = append(traffic.Hostnames, netReport.Hostnames...)
+	return traffic
+}
+
+// NetworkPolicy creates a Kubernetes NetworkPolicy for the observed traffic
+// (ingress on the observed listen ports and egress to the observed peers and DNS)
+func NetworkPolicy(traffic *Traffic, name string) *networkingv1.NetworkPolicy {
+	name = resourceName(name)
+	appName := strings.TrimSuffix(name, policyNameSuffix)
+
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{LabelApp: appName},
+			},
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeIngress,
+				networkingv1.PolicyTypeEgress,
+			},
+			//no ingress/egress rules means no ingress/egress traffic is allowed
+			Ingress: []networkingv1.NetworkPolicyIngressRule{},
+			Egress:  []networkingv1.NetworkPolicyEgressRule{},
+		},
+	}
+
+	if len(traffic.Hostnames) > 0 {
+		policy.Annotations = map[string]string{
+			AnnotationEgressHostnames: strings.Join(traffic.Hostnames, ","),
+		}
+	}
+
+	if len(traffic.Ingress) > 0 {
+		var rule networkingv1.NetworkPolicyIngressRule
+		for _, port := range traffic.Ingress {
+			rule.Ports = append(rule.Ports, policyPort(port))
+		}
+
+		policy.Spec.Ingress = append(policy.Spec.Ingress, rule)
+	}
+
+	if traffic.HasDNS {
+		policy.Spec.Egress = append(policy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			Ports: []networkingv1.NetworkPolicyPort{
+				policyPort(Port{Protocol: report.NetProtoUDP, Port: dnsPort}),
+				policyPort(Port{Protocol: report.NetProtoTCP, Port: dnsPort}),
+			},
+		})
+	}
+
+	//one rule per destination address
+	var rules []networkingv1.NetworkPolicyEgressRule
+	ruleIdx := map[string]int{}
+	for _, dest := range traffic.Egress {
+		idx, found := ruleIdx[dest.Address]
+		if !found {
+			idx = len(rules)
+			ruleIdx[dest.Address] = idx
+			rules = append(rules, networkingv1.NetworkPolicyEgressRule{
+				To: []networkingv1.NetworkPolicyPeer{
+					{
+						IPBlock: &networkingv1.IPBlock{CIDR: hostCIDR(dest)},
+					},
+				},
+			})
+		}
+
+		rules[idx].Ports = append(rules[idx].Ports, policyPort(dest.Port))
+	}
+
+	policy.Spec.Egress = append(policy.Spec.Egress, rules...)
+	return policy
+}
+
+// DockerConfig creates a Docker network config for the observed traffic
+// (the observed listen ports are exposed and published and the observed
+// DNS resolvers are used as the DNS servers)
+func DockerConfig(traffic *Traffic) *DockerNetworkConfig {
+	config := &DockerNetworkConfig{
+		Egress: traffic.Egress,
+	}
+
+	config.HostConfig.NetworkMode = "bridge"
+	if len(traffic.Ingress) == 0 && len(traffic.Egress) == 0 && !traffic.HasDNS {
+		config.HostConfig.NetworkMode = "none"
+	}
+
+	for _, port := range traffic.Ingress {
+		if port.Protocol != report.NetProtoTCP && port.Protocol != report.NetProtoUDP {
+			continue
+		}
+
+		natPort := nat.Port(strconv.Itoa(port.Port) + "/" + port.Protocol)
+		if config.ExposedPorts == nil {
+			config.ExposedPorts = nat.PortSet{}
+			config.HostConfig.PortBindings = nat.PortMap{}
+		}
+
+		config.ExposedPorts[natPort] = struct{}{}
+		config.HostConfig.PortBindings[natPort] = []nat.PortBinding{
+			{HostPort: strconv.Itoa(port.Port)},
+		}
+	}
+
+	//private (RFC1918) resolvers are kept (they are often the host or the corporate DNS servers);
+	//the loopback resolvers (e.g., the Docker embedded DNS server) are already excluded
+	for _, resolver := range traffic.Resolvers {
+		if ip := net.ParseIP(resolver); ip != nil {
+			config.HostConfig.DNS = append(config.HostConfig.DNS, resolver)
+		}
+	}
+
+	return config
+}
+
+func isObservable(info *report.NetActivityInfo) bool {
+	if info.Protocol == report.NetProtoUnix || info.Address == "" {
+		return false
+	}
+
+	var total uint64
+	for _, count := range info.Ops {
+		total += count
+	}
+
+	//ignoring the endpoints where all operations failed
+	return total > info.ErrorCount
+}
+
+func isLoopback(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
+func isUnspecified(address string) bool {
+	ip := net.ParseIP(address)
+	return ip == nil || ip.IsUnspecified()
+}
+
+func hostCIDR(dest Destination) string {
+	if ip := net.ParseIP(dest.Address); ip != nil && ip.To4() != nil {
+		return ip.To4().String() + "/32"
+	}
+
+	return dest.Address + "/128"
+}
+
+func policyPort(port Port) networkingv1.NetworkPolicyPort {
+	protocol := corev1.Protocol(strings.ToUpper(port.Protocol))
+	portValue := intstr.FromInt(port.Port)
+	return networkingv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &portValue,
+	}
+}
+
+func portLess(a, b Port) bool {
+	if a.Port != b.Port {
+		return a.Port < b.Port
+	}
+
+	return a.Protocol < b.Protocol
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// resourceName converts the name to a valid Kubernetes resource name
+func resourceName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 253 {
+		name = strings.Trim(name[:253], "-")
+	}
+
+	if name == "" {
+		name = "slim" + policyNameSuffix
+	}
+
+	return name
+}
@@ -0,0 +1,399 @@
+package netpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+func activity(protocol, address string, port int, ops map[string]uint64) *report.NetActivityInfo {
+	family := report.NetFamilyIPv4
+	if protocol == report.NetProtoUnix {
+		family = report.NetFamilyUnix
+	}
+
+	return &report.NetActivityInfo{
+		Protocol: protocol,
+		Family:   family,
+		Address:  address,
+		Port:     port,
+		Ops:      ops,
+	}
+}
+
+func activities(infos ...*report.NetActivityInfo) map[string]*report.NetActivityInfo {
+	out := map[string]*report.NetActivityInfo{}
+	for _, info := range infos {
+		out[fmt.Sprintf("%s:%s:%d", info.Protocol, info.Address, info.Port)] = info
+	}
+
+	return out
+}
+
+func policyPorts(ports ...Port) []networkingv1.NetworkPolicyPort {
+	var out []networkingv1.NetworkPolicyPort
+	for _, port := range ports {
+		out = append(out, policyPort(port))
+	}
+
+	return out
+}
+
+func TestObservedTraffic(t *testing.T) {
+	listen := map[string]uint64{report.NetOpBind: 1, report.NetOpListen: 1}
+	bind := map[string]uint64{report.NetOpBind: 1}
+	//the sampled sockets are recorded as listeners (without the bind operation)
+	sampled := map[string]uint64{report.NetOpListen: 1}
+	connect := map[string]uint64{report.NetOpConnect: 2}
+
+	tt := []struct {
+		name     string
+		report   *report.NetMonitorReport
+		expected *Traffic
+	}{
+		{
+			name:     "empty",
+			report:   &report.NetMonitorReport{},
+			expected: &Traffic{},
+		},
+		{
+			name: "listeners",
+			report: &report.NetMonitorReport{
+				Listeners: activities(
+					activity(report.NetProtoTCP, "0.0.0.0", 8080, listen),
+					//bound client socket
+					activity(report.NetProtoTCP, "0.0.0.0", 9000, bind),
+					activity(report.NetProtoUDP, "0.0.0.0", 5353, bind),
+					//sampled UDP socket on a non-ephemeral port
+					activity(report.NetProtoUDP, "0.0.0.0", 514, sampled),
+					//sampled UDP client socket
+					activity(report.NetProtoUDP, "0.0.0.0", 40000, sampled),
+					activity(report.NetProtoTCP, "127.0.0.1", 6060, listen),
+					activity(report.NetProtoUnix, "/tmp/app.sock", 0, listen),
+				),
+			},
+			expected: &Traffic{
+				Ingress: []Port{
+					{Protocol: report.NetProtoUDP, Port: 514},
+					{Protocol: report.NetProtoUDP, Port: 5353},
+					{Protocol: report.NetProtoTCP, Port: 8080},
+				},
+			},
+		},
+		{
+			name: "port and peer aggregation",
+			report: &report.NetMonitorReport{
+				Listeners: activities(
+					activity(report.NetProtoTCP, "0.0.0.0", 8080, listen),
+				),
+				Connections: activities(
+					activity(report.NetProtoTCP, "10.0.0.2", 5432, connect),
+				),
+				Processes: map[string]*report.NetProcessInfo{
+					"1": {
+						Pid: 1,
+						Listeners: activities(
+							activity(report.NetProtoTCP, "0.0.0.0", 8080, listen),
+						),
+						Connections: activities(
+							activity(report.NetProtoTCP, "10.0.0.2", 5432, connect),
+							activity(report.NetProtoTCP, "10.0.0.2", 443, connect),
+							activity(report.NetProtoTCP, "1.2.3.4", 443, connect),
+						),
+					},
+					"2": {
+						Pid: 2,
+						Connections: activities(
+							activity(report.NetProtoTCP, "1.2.3.4", 443, connect),
+							activity(report.NetProtoTCP, "127.0.0.1", 6379, connect),
+							activity(report.NetProtoTCP, "0.0.0.0", 80, connect),
+						),
+					},
+				},
+			},
+			expected: &Traffic{
+				Ingress: []Port{{Protocol: report.NetProtoTCP, Port: 8080}},
+				Egress: []Destination{
+					{Address: "1.2.3.4", Family: report.NetFamilyIPv4, Port: Port{Protocol: report.NetProtoTCP, Port: 443}},
+					{Address: "10.0.0.2", Family: report.NetFamilyIPv4, Port: Port{Protocol: report.NetProtoTCP, Port: 443}},
+					{Address: "10.0.0.2", Family: report.NetFamilyIPv4, Port: Port{Protocol: report.NetProtoTCP, Port: 5432}},
+				},
+			},
+		},
+		{
+			name: "failed operations",
+			report: &report.NetMonitorReport{
+				Connections: activities(&report.NetActivityInfo{
+					Protocol:   report.NetProtoTCP,
+					Family:     report.NetFamilyIPv4,
+					Address:    "1.2.3.4",
+					Port:       443,
+					Ops:        map[string]uint64{report.NetOpConnect: 2},
+					ErrorCount: 2,
+				}),
+			},
+			expected: &Traffic{},
+		},
+		{
+			name: "dns",
+			report: &report.NetMonitorReport{
+				Connections: activities(
+					activity(report.NetProtoUDP, "8.8.8.8", 53, connect),
+				),
+				Processes: map[string]*report.NetProcessInfo{
+					"1": {
+						Pid: 1,
+						DNSLookups: map[string]*report.DNSLookupInfo{
+							"example.com": {
+								Name:  "example.com",
+								Count: 1,
+								Resolvers: map[string]uint64{
+									"192.168.1.1:53": 1,
+									"127.0.0.11:53":  1,
+									"8.8.8.8":        1,
+								},
+							},
+						},
+					},
+				},
+				Hostnames: []string{"example.com"},
+			},
+			expected: &Traffic{
+				HasDNS:    true,
+				Resolvers: []string{"192.168.1.1", "8.8.8.8"},
+				Hostnames: []string{"example.com"},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ObservedTraffic(test.report))
+		})
+	}
+}
+
+func TestNetworkPolicy(t *testing.T) {
+	tt := []struct {
+		name            string
+		traffic         *Traffic
+		policyName      string
+		expectedName    string
+		expectedApp     string
+		expectedIngress []networkingv1.NetworkPolicyIngressRule
+		expectedEgress  []networkingv1.NetworkPolicyEgressRule
+	}{
+		{
+			name:            "no traffic",
+			traffic:         &Traffic{},
+			policyName:      "my-app-network-policy",
+			expectedName:    "my-app-network-policy",
+			expectedApp:     "my-app",
+			expectedIngress: []networkingv1.NetworkPolicyIngressRule{},
+			expectedEgress:  []networkingv1.NetworkPolicyEgressRule{},
+		},
+		{
+			name: "ingress and egress",
+			traffic: &Traffic{
+				Ingress: []Port{
+					{Protocol: report.NetProtoUDP, Port: 514},
+					{Protocol: report.NetProtoTCP, Port: 8080},
+				},
+				Egress: []Destination{
+					{Address: "1.2.3.4", Family: report.NetFamilyIPv4, Port: Port{Protocol: report.NetProtoTCP, Port: 443}},
+					{Address: "10.0.0.2", Family: report.NetFamilyIPv4, Port: Port{Protocol: report.NetProtoTCP, Port: 443}},
+					{Address: "10.0.0.2", Family: report.NetFamilyIPv4, Port: Port{Protocol: report.NetProtoTCP, Port: 5432}},
+					{Address: "2001:db8::1", Family: report.NetFamilyIPv6, Port: Port{Protocol: report.NetProtoTCP, Port: 443}},
+				},
+			},
+			policyName:   "My_App",
+			expectedName: "my-app",
+			expectedApp:  "my-app",
+			expectedIngress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: policyPorts(
+						Port{Protocol: report.NetProtoUDP, Port: 514},
+						Port{Protocol: report.NetProtoTCP, Port: 8080},
+					),
+				},
+			},
+			expectedEgress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					To:    []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "1.2.3.4/32"}}},
+					Ports: policyPorts(Port{Protocol: report.NetProtoTCP, Port: 443}),
+				},
+				{
+					To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.2/32"}}},
+					Ports: policyPorts(
+						Port{Protocol: report.NetProtoTCP, Port: 443},
+						Port{Protocol: report.NetProtoTCP, Port: 5432},
+					),
+				},
+				{
+					To:    []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "2001:db8::1/128"}}},
+					Ports: policyPorts(Port{Protocol: report.NetProtoTCP, Port: 443}),
+				},
+			},
+		},
+		{
+			name: "dns",
+			traffic: &Traffic{
+				HasDNS:    true,
+				Resolvers: []string{"8.8.8.8"},
+			},
+			policyName:      "--",
+			expectedName:    "slim-network-policy",
+			expectedApp:     "slim",
+			expectedIngress: []networkingv1.NetworkPolicyIngressRule{},
+			expectedEgress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					Ports: policyPorts(
+						Port{Protocol: report.NetProtoUDP, Port: dnsPort},
+						Port{Protocol: report.NetProtoTCP, Port: dnsPort},
+					),
+				},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			policy := NetworkPolicy(test.traffic, test.policyName)
+			assert.Equal(t, "NetworkPolicy", policy.Kind)
+			assert.Equal(t, test.expectedName, policy.Name)
+			assert.Equal(t, map[string]string{LabelApp: test.expectedApp}, policy.Spec.PodSelector.MatchLabels)
+			assert.Equal(t, test.expectedIngress, policy.Spec.Ingress)
+			assert.Equal(t, test.expectedEgress, policy.Spec.Egress)
+		})
+	}
+
+	policy := NetworkPolicy(&Traffic{Hostnames: []string{"a.example.com", "b.example.com"}}, "app")
+	assert.Equal(t, "a.example.com,b.example.com", policy.Annotations[AnnotationEgressHostnames])
+}
+
+func TestDockerConfig(t *testing.T) {
+	tt := []struct {
+		name          string
+		traffic       *Traffic
+		expectedMode  string
+		expectedPorts []string
+		expectedDNS   []string
+	}{
+		{
+			name:         "no traffic",
+			traffic:      &Traffic{},
+			expectedMode: "none",
+		},
+		{
+			name: "ingress",
+			traffic: &Traffic{
+				Ingress: []Port{
+					{Protocol: report.NetProtoUDP, Port: 514},
+					{Protocol: report.NetProtoTCP, Port: 8080},
+					{Protocol: report.NetProtoRaw, Port: 1},
+				},
+			},
+			expectedMode:  "bridge",
+			expectedPorts: []string{"514/udp", "8080/tcp"},
+		},
+		{
+			name: "dns resolvers",
+			traffic: &Traffic{
+				HasDNS:    true,
+				Resolvers: []string{"10.0.0.53", "192.168.1.1", "8.8.8.8", "not-an-ip"},
+			},
+			expectedMode: "bridge",
+			expectedDNS:  []string{"10.0.0.53", "192.168.1.1", "8.8.8.8"},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			config := DockerConfig(test.traffic)
+			assert.Equal(t, test.expectedMode, config.HostConfig.NetworkMode)
+			assert.Equal(t, test.expectedDNS, config.HostConfig.DNS)
+
+			var ports []string
+			for port := range config.ExposedPorts {
+				ports = append(ports, string(port))
+				if assert.Len(t, config.HostConfig.PortBindings[port], 1) {
+					assert.Equal(t, port.Port(), config.HostConfig.PortBindings[port][0].HostPort)
+				}
+			}
+
+			assert.ElementsMatch(t, test.expectedPorts, ports)
+		})
+	}
+}
+
+func TestGenProfile(t *testing.T) {
+	tt := []struct {
+		name          string
+		netReport     *report.NetMonitorReport
+		expectedFiles bool
+	}{
+		{
+			name:      "no network monitor",
+			netReport: nil,
+		},
+		{
+			name:      "network monitor disabled",
+			netReport: &report.NetMonitorReport{Enabled: false},
+		},
+		{
+			name: "network monitor enabled",
+			netReport: &report.NetMonitorReport{
+				Enabled: true,
+				Listeners: activities(
+					activity(report.NetProtoTCP, "0.0.0.0", 8080, map[string]uint64{report.NetOpListen: 1}),
+				),
+			},
+			expectedFiles: true,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var creport report.ContainerReport
+			creport.Monitors.Net = test.netReport
+			data, err := json.Marshal(&creport)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, report.DefaultContainerReportFileName), data, 0644))
+
+			require.NoError(t, GenProfile(dir, "app-network-policy.yaml", "app-docker-network.json"))
+
+			policyData, err := os.ReadFile(filepath.Join(dir, "app-network-policy.yaml"))
+			if !test.expectedFiles {
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+
+			require.NoError(t, err)
+			var policy networkingv1.NetworkPolicy
+			require.NoError(t, yaml.Unmarshal(policyData, &policy))
+			assert.Equal(t, "app-network-policy", policy.Name)
+			require.Len(t, policy.Spec.Ingress, 1)
+			assert.Equal(t, policyPorts(Port{Protocol: report.NetProtoTCP, Port: 8080}), policy.Spec.Ingress[0].Ports)
+
+			configData, err := os.ReadFile(filepath.Join(dir, "app-docker-network.json"))
+			require.NoError(t, err)
+			var config DockerNetworkConfig
+			require.NoError(t, json.Unmarshal(configData, &config))
+			assert.Equal(t, "bridge", config.HostConfig.NetworkMode)
+			assert.Contains(t, config.ExposedPorts, nat.Port("8080/tcp"))
+		})
+	}
+
+	assert.Error(t, GenProfile(t.TempDir(), "policy.yaml", "config.json"))
+}
@@ -0,0 +1,183 @@
+package seccomp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/slimtoolkit/slim/pkg/third_party/opencontainers/specs"
+)
+
+// Docker (daemon) architecture names used in the rule filters (runtime.GOARCH values)
+var dockerArchNames = map[specs.Arch]string{
+	specs.ArchX86:     "386",
+	specs.ArchX86_64:  "amd64",
+	specs.ArchX32:     "amd64",
+	specs.ArchARM:     "arm",
+	specs.ArchAARCH64: "arm64",
+}
+
+type ruleKey struct {
+	name   string
+	action specs.Action
+	args   string
+}
+
+type mergedRule struct {
+	key    ruleKey
+	args   []*specs.Arg
+	arches map[string]struct{}
+}
+
+// MergeProfiles merges the profiles generated for different architectures
+// into one profile with an ArchMap entry for each architecture.
+// The rules observed only on some of the architectures are limited
+// to those architectures with the rule 'includes' filters.
+func MergeProfiles(profiles ...*specs.Seccomp) *specs.Seccomp {
+	merged := &specs.Seccomp{
+		DefaultAction: specs.ActErrno,
+	}
+
+	if len(profiles) == 0 {
+		return merged
+	}
+
+	if profiles[0].DefaultAction != "" {
+		merged.DefaultAction = profiles[0].DefaultAction
+	}
+
+	seenArches := map[specs.Arch]struct{}{}
+	allArches := map[string]struct{}{}
+	rules := map[ruleKey]*mergedRule{}
+	var ruleOrder []ruleKey
+	for _, profile := range profiles {
+		if profile == nil {
+			continue
+		}
+
+		var profileArches []specs.Arch
+		profileArches = append(profileArches, profile.Architectures...)
+		for _, am := range profile.ArchMap {
+			profileArches = append(profileArches, am.Arch)
+		}
+
+		archNames := map[string]struct{}{}
+		for _, arch := range profileArches {
+			if _, found := seenArches[arch]; !found {
+				seenArches[arch] = struct{}{}
+				merged.ArchMap = append(merged.ArchMap, specs.Architecture{
+					Arch:      arch,
+					SubArches: []specs.Arch{},
+				})
+			}
+
+			if name, found := dockerArchNames[arch]; found {
+				archNames[name] = struct{}{}
+				allArches[name] = struct{}{}
+			}
+		}
+
+		for _, call := range profile.Syscalls {
+			if call == nil {
+				continue
+			}
+
+			ruleArches := archNames
+			if len(call.Includes.Arches) > 0 {
+				ruleArches = map[string]struct{}{}
+				for _, name := range call.Includes.Arches {
+					ruleArches[name] = struct{}{}
+				}
+			}
+
+			names := call.Names
+			if call.Name != "" {
+				names = append([]string{call.Name}, names...)
+			}
+
+			for _, name := range names {
+				key := ruleKey{
+					name:   name,
+					action: call.Action,
+					args:   argsKey(call.Args),
+				}
+
+				rule, found := rules[key]
+				if !found {
+					rule = &mergedRule{
+						key:    key,
+						args:   call.Args,
+						arches: map[string]struct{}{},
+					}
+					rules[key] = rule
+					ruleOrder = append(ruleOrder, key)
+				}
+
+				for archName := range ruleArches {
+					rule.arches[archName] = struct{}{}
+				}
+			}
+		}
+	}
+
+	//grouping the syscall names with the same action, arguments and architectures
+	type groupKey struct {
+		action specs.Action
+		args   string
+		arches string
+	}
+
+	groups := map[groupKey]*specs.Syscall{}
+	var groupOrder []groupKey
+	for _, key := range ruleOrder {
+		rule := rules[key]
+		var arches []string
+		if len(rule.arches) < len(allArches) {
+			for name := range rule.arches {
+				arches = append(arches, name)
+			}
+			sort.Strings(arches)
+		}
+
+		gkey := groupKey{
+			action: key.action,
+			args:   key.args,
+			arches: strings.Join(arches, ","),
+		}
+
+		call, found := groups[gkey]
+		if !found {
+			call = &specs.Syscall{
+				Action: key.action,
+				Args:   rule.args,
+			}
+			call.Includes.Arches = arches
+			groups[gkey] = call
+			groupOrder = append(groupOrder, gkey)
+		}
+
+		call.Names = append(call.Names, key.name)
+	}
+
+	for _, gkey := range groupOrder {
+		call := groups[gkey]
+		sort.Strings(call.Names)
+		merged.Syscalls = append(merged.Syscalls, call)
+	}
+
+	return merged
+}
+
+func argsKey(args []*specs.Arg) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprintf("%v", args)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,135 @@
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/third_party/opencontainers/specs"
+)
+
+func TestMergeProfiles(t *testing.T) {
+	cloneArgs := []*specs.Arg{{Value: 0xffc2f000, Op: specs.OpMaskedEqual}}
+
+	amd64 := &specs.Seccomp{
+		DefaultAction: specs.ActErrno,
+		Architectures: []specs.Arch{specs.ArchX86_64},
+		Syscalls: []*specs.Syscall{
+			{Names: []string{"read", "write", "stat"}, Action: specs.ActAllow},
+			{Names: []string{"clone"}, Action: specs.ActAllow, Args: cloneArgs},
+		},
+	}
+
+	arm64 := &specs.Seccomp{
+		DefaultAction: specs.ActErrno,
+		Architectures: []specs.Arch{specs.ArchAARCH64},
+		Syscalls: []*specs.Syscall{
+			{Names: []string{"write", "read", "openat"}, Action: specs.ActAllow},
+			{Names: []string{"clone"}, Action: specs.ActAllow, Args: cloneArgs},
+		},
+	}
+
+	merged := MergeProfiles(amd64, nil, arm64)
+	assert.Equal(t, specs.ActErrno, merged.DefaultAction)
+	assert.Empty(t, merged.Architectures)
+	assert.Equal(t, []specs.Architecture{
+		{Arch: specs.ArchX86_64, SubArches: []specs.Arch{}},
+		{Arch: specs.ArchAARCH64, SubArches: []specs.Arch{}},
+	}, merged.ArchMap)
+
+	expected := []*specs.Syscall{
+		{Names: []string{"read", "write"}, Action: specs.ActAllow},
+		{Names: []string{"stat"}, Action: specs.ActAllow, Includes: specs.Filter{Arches: []string{"amd64"}}},
+		{Names: []string{"clone"}, Action: specs.ActAllow, Args: cloneArgs},
+		{Names: []string{"openat"}, Action: specs.ActAllow, Includes: specs.Filter{Arches: []string{"arm64"}}},
+	}
+
+	assert.Equal(t, expected, merged.Syscalls)
+}
+
+func TestMergeProfilesRuleFilters(t *testing.T) {
+	tt := []struct {
+		name     string
+		profiles []*specs.Seccomp
+		expected []*specs.Syscall
+	}{
+		{
+			name: "existing arch filter",
+			profiles: []*specs.Seccomp{
+				{
+					Architectures: []specs.Arch{specs.ArchX86_64},
+					Syscalls: []*specs.Syscall{
+						{Names: []string{"arch_prctl"}, Action: specs.ActAllow, Includes: specs.Filter{Arches: []string{"amd64"}}},
+					},
+				},
+				{ArchMap: []specs.Architecture{{Arch: specs.ArchAARCH64}}},
+			},
+			expected: []*specs.Syscall{
+				{Names: []string{"arch_prctl"}, Action: specs.ActAllow, Includes: specs.Filter{Arches: []string{"amd64"}}},
+			},
+		},
+		{
+			name: "single name rules",
+			profiles: []*specs.Seccomp{
+				{
+					Architectures: []specs.Arch{specs.ArchX86_64},
+					Syscalls: []*specs.Syscall{
+						{Name: "read", Action: specs.ActAllow},
+						{Name: "ptrace", Action: specs.ActErrno},
+					},
+				},
+			},
+			expected: []*specs.Syscall{
+				{Names: []string{"read"}, Action: specs.ActAllow},
+				{Names: []string{"ptrace"}, Action: specs.ActErrno},
+			},
+		},
+		{
+			name: "different argument values",
+			profiles: []*specs.Seccomp{
+				{
+					Architectures: []specs.Arch{specs.ArchX86_64},
+					Syscalls: []*specs.Syscall{
+						{Names: []string{"socket"}, Action: specs.ActAllow, Args: []*specs.Arg{{Value: 2, Op: specs.OpEqualTo}}},
+					},
+				},
+				{
+					Architectures: []specs.Arch{specs.ArchAARCH64},
+					Syscalls: []*specs.Syscall{
+						{Names: []string{"socket"}, Action: specs.ActAllow, Args: []*specs.Arg{{Value: 10, Op: specs.OpEqualTo}}},
+					},
+				},
+			},
+			expected: []*specs.Syscall{
+				{
+					Names:    []string{"socket"},
+					Action:   specs.ActAllow,
+					Args:     []*specs.Arg{{Value: 2, Op: specs.OpEqualTo}},
+					Includes: specs.Filter{Arches: []string{"amd64"}},
+				},
+				{
+					Names:    []string{"socket"},
+					Action:   specs.ActAllow,
+					Args:     []*specs.Arg{{Value: 10, Op: specs.OpEqualTo}},
+					Includes: specs.Filter{Arches: []string{"arm64"}},
+				},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		merged := MergeProfiles(test.profiles...)
+		require.NotNil(t, merged, test.name)
+		assert.Equal(t, test.expected, merged.Syscalls, test.name)
+	}
+}
+
+func TestMergeProfilesEmpty(t *testing.T) {
+	merged := MergeProfiles()
+	assert.Equal(t, specs.ActErrno, merged.DefaultAction)
+	assert.Empty(t, merged.Syscalls)
+
+	merged = MergeProfiles(&specs.Seccomp{DefaultAction: specs.ActTrace})
+	assert.Equal(t, specs.ActTrace, merged.DefaultAction)
+}
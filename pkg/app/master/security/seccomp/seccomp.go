@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/system"
@@ -62,8 +63,31 @@ var extraCalls = []string{
 	"getcwd", //safe to add
 }
 
+// Known values for the sensitive syscall arguments used by the container runtime
+// (runc) after the profile is applied, which are not visible to the sensor
+var extraArgValues = map[string][]uint64{
+	"prctl": {
+		1,  //PR_SET_PDEATHSIG
+		8,  //PR_SET_KEEPCAPS
+		24, //PR_CAPBSET_DROP
+		38, //PR_SET_NO_NEW_PRIVS
+		47, //PR_CAP_AMBIENT
+	},
+}
+
+// Flag arguments (the observed flags are combined into one masked rule)
+// with the flag bits that don't need to be checked
+// (the clone exit signal is in the low byte of the flags - CSIGNAL)
+var flagArgs = map[string]uint64{
+	"clone": 0xff,
+}
+
+// the flag args are 32 bit values (the upper clone flag bits are only used by clone3)
+const flagArgMask = 0xffffffff
+
 // GenProfile creates a SecComp profile
-func GenProfile(artifactLocation string, profileName string) error {
+// (merging it with the optional profiles generated for other architectures)
+func GenProfile(artifactLocation string, profileName string, mergeProfilePaths ...string) error {
 	containerReportFilePath := filepath.Join(artifactLocation, report.DefaultContainerReportFileName)
 
 	if _, err := os.Stat(containerReportFilePath); err != nil {
@@ -95,6 +119,10 @@ func GenProfile(artifactLocation string, profileName string) error {
 		},
 	}
 
+	if creport.Monitors.Pt.SyscallStats == nil {
+		creport.Monitors.Pt.SyscallStats = map[string]report.SyscallStatInfo{}
+	}
+
 	nameResolver := system.CallNameResolver(system.ArchName(creport.Monitors.Pt.ArchName))
 	if nameResolver != nil {
 		for _, xcall := range extraCalls {
@@ -107,15 +135,43 @@ func GenProfile(artifactLocation string, profileName string) error {
 		}
 	}
 
+	//the calls with the recorded sensitive argument values get their own rules
+	argRules := map[string]*report.SyscallArgInfo{}
+	for _, argInfo := range creport.Monitors.Pt.SyscallArgs {
+		if argInfo != nil && argInfo.Name != "" && len(argInfo.Values) > 0 {
+			argRules[argInfo.Name] = argInfo
+		}
+	}
+
 	scSpec := specs.Syscall{
 		Action: specs.ActAllow,
 	}
 
 	for _, scInfo := range creport.Monitors.Pt.SyscallStats {
+		if _, found := argRules[scInfo.Name]; found {
+			continue
+		}
+
 		scSpec.Names = append(scSpec.Names, scInfo.Name)
 	}
 
+	sort.Strings(scSpec.Names)
 	profile.Syscalls = append(profile.Syscalls, &scSpec)
+	profile.Syscalls = append(profile.Syscalls, argSyscalls(argRules)...)
+
+	if len(mergeProfilePaths) > 0 {
+		profiles := []*specs.Seccomp{profile}
+		for _, mpath := range mergeProfilePaths {
+			mprofile, err := loadProfile(mpath)
+			if err != nil {
+				return err
+			}
+
+			profiles = append(profiles, mprofile)
+		}
+
+		profile = MergeProfiles(profiles...)
+	}
 
 	profileData, err := json.MarshalIndent(profile, "", "  ")
 	if err != nil {
@@ -129,3 +185,89 @@ func GenProfile(artifactLocation string, profileName string) error {
 
 	return nil
 }
+
+// argSyscalls creates the argument-aware rules (one rule for each observed argument value;
+// the flag argument rules allow any combination of the observed flags)
+func argSyscalls(argRules map[string]*report.SyscallArgInfo) []*specs.Syscall {
+	var names []string
+	for name := range argRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var rules []*specs.Syscall
+	for _, name := range names {
+		argInfo := argRules[name]
+		if ignoredBits, found := flagArgs[name]; found {
+			rules = append(rules, flagArgSyscall(name, argInfo, ignoredBits))
+			continue
+		}
+
+		values := map[uint64]struct{}{}
+		for val := range argInfo.Values {
+			values[val] = struct{}{}
+		}
+
+		for _, val := range extraArgValues[name] {
+			values[val] = struct{}{}
+		}
+
+		var sortedValues []uint64
+		for val := range values {
+			sortedValues = append(sortedValues, val)
+		}
+		sort.Slice(sortedValues, func(i, j int) bool { return sortedValues[i] < sortedValues[j] })
+
+		for _, val := range sortedValues {
+			rules = append(rules, &specs.Syscall{
+				Names:  []string{name},
+				Action: specs.ActAllow,
+				Args: []*specs.Arg{
+					{
+						Index: argInfo.Index,
+						Value: val,
+						Op:    specs.OpEqualTo,
+					},
+				},
+			})
+		}
+	}
+
+	return rules
+}
+
+// flagArgSyscall creates the rule that allows the calls
+// where all flags set in the argument were observed ((arg & mask) == 0)
+func flagArgSyscall(name string, argInfo *report.SyscallArgInfo, ignoredBits uint64) *specs.Syscall {
+	allowed := ignoredBits
+	for val := range argInfo.Values {
+		allowed |= val
+	}
+
+	return &specs.Syscall{
+		Names:  []string{name},
+		Action: specs.ActAllow,
+		Args: []*specs.Arg{
+			{
+				Index:    argInfo.Index,
+				Value:    flagArgMask &^ allowed,
+				ValueTwo: 0,
+				Op:       specs.OpMaskedEqual,
+			},
+		},
+	}
+}
+
+func loadProfile(profilePath string) (*specs.Seccomp, error) {
+	data, err := os.ReadFile(profilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile specs.Seccomp
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("seccomp: malformed profile (%s) - %w", profilePath, err)
+	}
+
+	return &profile, nil
+}
@@ -0,0 +1,150 @@
+package seccomp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+	"github.com/slimtoolkit/slim/pkg/third_party/opencontainers/specs"
+)
+
+func argRule(name string, index uint, value uint64, op specs.Operator) *specs.Syscall {
+	return &specs.Syscall{
+		Names:  []string{name},
+		Action: specs.ActAllow,
+		Args:   []*specs.Arg{{Index: index, Value: value, Op: op}},
+	}
+}
+
+func TestArgSyscalls(t *testing.T) {
+	tt := []struct {
+		name     string
+		argRules map[string]*report.SyscallArgInfo
+		expected []*specs.Syscall
+	}{
+		{
+			name: "equal rule per value",
+			argRules: map[string]*report.SyscallArgInfo{
+				"socket": {Name: "socket", Values: map[uint64]uint64{10: 1, 2: 5}},
+				"ioctl":  {Name: "ioctl", Index: 1, Values: map[uint64]uint64{0x5401: 3}},
+			},
+			expected: []*specs.Syscall{
+				argRule("ioctl", 1, 0x5401, specs.OpEqualTo),
+				argRule("socket", 0, 2, specs.OpEqualTo),
+				argRule("socket", 0, 10, specs.OpEqualTo),
+			},
+		},
+		{
+			name: "extra runtime values",
+			argRules: map[string]*report.SyscallArgInfo{
+				"prctl": {Name: "prctl", Values: map[uint64]uint64{15: 1, 38: 1}},
+			},
+			expected: []*specs.Syscall{
+				argRule("prctl", 0, 1, specs.OpEqualTo),
+				argRule("prctl", 0, 8, specs.OpEqualTo),
+				argRule("prctl", 0, 15, specs.OpEqualTo),
+				argRule("prctl", 0, 24, specs.OpEqualTo),
+				argRule("prctl", 0, 38, specs.OpEqualTo),
+				argRule("prctl", 0, 47, specs.OpEqualTo),
+			},
+		},
+		{
+			name: "masked clone flags",
+			argRules: map[string]*report.SyscallArgInfo{
+				//thread creation and fork (with SIGCHLD)
+				"clone": {Name: "clone", Values: map[uint64]uint64{0x3d0f00: 4, 0x1200011: 1}},
+			},
+			expected: []*specs.Syscall{
+				argRule("clone", 0, 0xfec2f000, specs.OpMaskedEqual),
+			},
+		},
+		{
+			name: "clone exit signal only",
+			argRules: map[string]*report.SyscallArgInfo{
+				"clone": {Name: "clone", Values: map[uint64]uint64{0x11: 1}},
+			},
+			expected: []*specs.Syscall{
+				argRule("clone", 0, 0xffffff00, specs.OpMaskedEqual),
+			},
+		},
+		{name: "no rules"},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, argSyscalls(test.argRules), test.name)
+	}
+}
+
+func TestGenProfile(t *testing.T) {
+	creport := report.ContainerReport{}
+	creport.Monitors.Pt = &report.PtMonitorReport{
+		Enabled:  true,
+		ArchName: "amd64",
+		SyscallStats: map[string]report.SyscallStatInfo{
+			"0":  {Name: "read"},
+			"56": {Name: "clone"},
+		},
+		SyscallArgs: map[string]*report.SyscallArgInfo{
+			"56": {Number: 56, Name: "clone", Values: map[uint64]uint64{0x3d0f00: 2}},
+		},
+	}
+
+	artifactLocation := t.TempDir()
+	data, err := json.Marshal(&creport)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(artifactLocation, report.DefaultContainerReportFileName), data, 0644))
+
+	require.NoError(t, GenProfile(artifactLocation, "seccomp.json"))
+	profile, err := loadProfile(filepath.Join(artifactLocation, "seccomp.json"))
+	require.NoError(t, err)
+
+	assert.Equal(t, specs.ActErrno, profile.DefaultAction)
+	assert.Equal(t, []specs.Arch{specs.ArchX86_64}, profile.Architectures)
+	require.Len(t, profile.Syscalls, 2)
+	assert.Contains(t, profile.Syscalls[0].Names, "read")
+	assert.Contains(t, profile.Syscalls[0].Names, "execve")
+	assert.NotContains(t, profile.Syscalls[0].Names, "clone")
+	assert.Equal(t, argRule("clone", 0, 0xffc2f000, specs.OpMaskedEqual), profile.Syscalls[1])
+
+	//merging with the profile generated for another architecture
+	other := &specs.Seccomp{
+		DefaultAction: specs.ActErrno,
+		Architectures: []specs.Arch{specs.ArchAARCH64},
+		Syscalls: []*specs.Syscall{
+			{Names: []string{"read", "openat"}, Action: specs.ActAllow},
+		},
+	}
+
+	data, err = json.Marshal(other)
+	require.NoError(t, err)
+	otherPath := filepath.Join(t.TempDir(), "arm64.json")
+	require.NoError(t, os.WriteFile(otherPath, data, 0644))
+
+	require.NoError(t, GenProfile(artifactLocation, "merged.json", otherPath))
+	profile, err = loadProfile(filepath.Join(artifactLocation, "merged.json"))
+	require.NoError(t, err)
+	assert.Empty(t, profile.Architectures)
+	require.Len(t, profile.ArchMap, 2)
+	assert.Equal(t, specs.ArchX86_64, profile.ArchMap[0].Arch)
+	assert.Equal(t, specs.ArchAARCH64, profile.ArchMap[1].Arch)
+
+	assert.Error(t, GenProfile(artifactLocation, "merged.json", filepath.Join(t.TempDir(), "missing.json")))
+}
+
+func TestGenProfileNoPtMonitor(t *testing.T) {
+	creport := report.ContainerReport{}
+	creport.Monitors.Pt = &report.PtMonitorReport{}
+
+	artifactLocation := t.TempDir()
+	data, err := json.Marshal(&creport)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(artifactLocation, report.DefaultContainerReportFileName), data, 0644))
+
+	require.NoError(t, GenProfile(artifactLocation, "seccomp.json"))
+	assert.NoFileExists(t, filepath.Join(artifactLocation, "seccomp.json"))
+}
@@ -14,6 +14,7 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -273,6 +274,7 @@ type Processor interface {
 		peReport *report.PeMonitorReport,
 		fanReport *report.FanMonitorReport,
 		ptReport *report.PtMonitorReport,
+		netReport *report.NetMonitorReport,
 	) error
 
 	// Archives commands.json, creport.json, events.json, sensor.log, etc
@@ -438,6 +440,7 @@ func (a *processor) Process(
 	peReport *report.PeMonitorReport,
 	fanReport *report.FanMonitorReport,
 	ptReport *report.PtMonitorReport,
+	netReport *report.NetMonitorReport,
 ) error {
 	//TODO: when peReport is available filter file events from fanReport
 	logger := log.WithField("op", "processor.Process")
@@ -446,18 +449,48 @@ func (a *processor) Process(
 
 	logger.Debug("processing data...")
 
-	fileCount := 0
-	fileList := make([]string, 0, fileCount)
-	for _, processFileMap := range fanReport.ProcessFiles {
-		fileCount += len(processFileMap)
+	fileList := processFileList(logger, cmd, fanReport, ptReport)
+	logger.Debugf("len(fanReport.ProcessFiles)=%v / fileCount=%v", len(fanReport.ProcessFiles), len(fileList))
+	allFilesMap := findSymlinks(fileList, mountPoint, cmd.Excludes)
+	return saveResults(a.origPathMap, a.artifactsDirName, cmd, allFilesMap, fanReport, ptReport, netReport, peReport, a.seReport)
+}
+
+// processFileList returns the files accessed by the monitored processes
+// (only the files from the traced processes if the traced process files are requested)
+func processFileList(
+	logger *log.Entry,
+	cmd *command.StartMonitor,
+	fanReport *report.FanMonitorReport,
+	ptReport *report.PtMonitorReport) []string {
+	//when the sensor doesn't run in a dedicated container
+	//the fanotify monitor also sees the files accessed by the other processes
+	var tracedPids map[string]struct{}
+	if cmd.TracedProcessFilesOnly && ptReport != nil && ptReport.Enabled {
+		tracedPids = map[string]struct{}{}
+		for _, info := range ptReport.FSActivity {
+			for pid := range info.Pids {
+				tracedPids[strconv.Itoa(pid)] = struct{}{}
+			}
+		}
+
+		logger.Debugf("traced process files only - pids: %d", len(tracedPids))
+	}
+
+	var fileList []string
+	for pid, processFileMap := range fanReport.ProcessFiles {
+		if tracedPids != nil {
+			if _, found := tracedPids[pid]; !found {
+				logger.Debugf("skipping files from untraced process (pid=%s)", pid)
+				continue
+			}
+		}
+
 		for fpath := range processFileMap {
 			fileList = append(fileList, fpath)
 		}
 	}
 
-	logger.Debugf("len(fanReport.ProcessFiles)=%v / fileCount=%v", len(fanReport.ProcessFiles), fileCount)
-	allFilesMap := findSymlinks(fileList, mountPoint, cmd.Excludes)
-	return saveResults(a.origPathMap, a.artifactsDirName, cmd, allFilesMap, fanReport, ptReport, peReport, a.seReport)
+	return fileList
 }
 
 func (a *processor) Archive() error {
@@ -505,6 +538,7 @@ func saveResults(
 	fileNames map[string]*report.ArtifactProps,
 	fanMonReport *report.FanMonitorReport,
 	ptMonReport *report.PtMonitorReport,
+	netMonReport *report.NetMonitorReport,
 	peReport *report.PeMonitorReport,
 	seReport *report.SensorReport,
 ) error {
@@ -515,6 +549,7 @@ func saveResults(
 		fileNames,
 		fanMonReport,
 		ptMonReport,
+		netMonReport,
 		peReport,
 		seReport,
 		cmd)
@@ -536,6 +571,7 @@ type store struct {
 	storeLocation string
 	fanMonReport  *report.FanMonitorReport
 	ptMonReport   *report.PtMonitorReport
+	netMonReport  *report.NetMonitorReport
 	peMonReport   *report.PeMonitorReport
 	seReport      *report.SensorReport
 	rawNames      map[string]*report.ArtifactProps
@@ -554,6 +590,7 @@ func newStore(
 	rawNames map[string]*report.ArtifactProps,
 	fanMonReport *report.FanMonitorReport,
 	ptMonReport *report.PtMonitorReport,
+	netMonReport *report.NetMonitorReport,
 	peMonReport *report.PeMonitorReport,
 	seReport *report.SensorReport,
 	cmd *command.StartMonitor) *store {
@@ -562,6 +599,7 @@ func newStore(
 		storeLocation: storeLocation,
 		fanMonReport:  fanMonReport,
 		ptMonReport:   ptMonReport,
+		netMonReport:  netMonReport,
 		peMonReport:   peMonReport,
 		seReport:      seReport,
 		rawNames:      rawNames,
@@ -618,6 +656,7 @@ func (p *store) prepareArtifact(artifactFileName string) {
 		FileSize: srcLinkFileInfo.Size(),
 	}
 
+	setArtifactOwner(props, srcLinkFileInfo)
 	props.Flags = p.getArtifactFlags(artifactFileName)
 
 	log.Tracef("prepareArtifact - file mode:%v", srcLinkFileInfo.Mode())
@@ -767,6 +806,7 @@ func (p *store) prepareArtifacts() {
 				FileSize: bpathFileInfo.Size(),
 			}
 
+			setArtifactOwner(bprops, bpathFileInfo)
 			bprops.Flags = p.getArtifactFlags(bpath)
 
 			fsType := report.UnknownArtifactTypeName
@@ -2746,6 +2786,7 @@ func (p *store) saveReport() error {
 		Monitors: report.MonitorReports{
 			Pt:  p.ptMonReport,
 			Fan: p.fanMonReport,
+			Net: p.netMonReport,
 		},
 	}
 
@@ -3293,13 +3334,23 @@ func artifactProps(filename string) (*report.ArtifactProps, error) {
 		fileType = report.DirArtifactType
 	}
 
-	return &report.ArtifactProps{
+	props := &report.ArtifactProps{
 		FileType: fileType,
 		FilePath: filename,
 		Mode:     fileInfo.Mode(),
 		ModeText: fileInfo.Mode().String(),
 		FileSize: fileInfo.Size(),
-	}, nil
+	}
+
+	setArtifactOwner(props, fileInfo)
+	return props, nil
+}
+
+func setArtifactOwner(props *report.ArtifactProps, fileInfo os.FileInfo) {
+	if sysStat, ok := fileInfo.Sys().(*syscall.Stat_t); ok {
+		props.UID = int(sysStat.Uid)
+		props.GID = int(sysStat.Gid)
+	}
 }
 
 func list2map(l []string) map[string]bool {
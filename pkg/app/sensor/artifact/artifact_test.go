@@ -0,0 +1,62 @@
+//go:build linux
+// +build linux
+
+package artifact
+
+import (
+	"sort"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slimtoolkit/slim/pkg/ipc/command"
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+func TestProcessFileList(t *testing.T) {
+	fanReport := &report.FanMonitorReport{
+		ProcessFiles: map[string]map[string]*report.FileInfo{
+			"10": {"/app/server": nil, "/etc/app.conf": nil},
+			"11": {"/lib/libc.so.6": nil},
+			//another process on the host
+			"99": {"/var/log/syslog": nil},
+		},
+	}
+
+	ptReport := &report.PtMonitorReport{
+		Enabled: true,
+		FSActivity: map[string]*report.FSActivityInfo{
+			"/app/server":   {Pids: map[int]struct{}{10: {}}},
+			"/etc/app.conf": {Pids: map[int]struct{}{10: {}, 11: {}}},
+		},
+	}
+
+	allFiles := []string{"/app/server", "/etc/app.conf", "/lib/libc.so.6", "/var/log/syslog"}
+	tracedFiles := []string{"/app/server", "/etc/app.conf", "/lib/libc.so.6"}
+
+	tt := []struct {
+		name       string
+		tracedOnly bool
+		ptReport   *report.PtMonitorReport
+		expected   []string
+	}{
+		{name: "all process files", ptReport: ptReport, expected: allFiles},
+		{name: "traced process files", tracedOnly: true, ptReport: ptReport, expected: tracedFiles},
+		//the files are not filtered without the ptrace data
+		{name: "no ptrace report", tracedOnly: true, expected: allFiles},
+		{name: "ptrace disabled", tracedOnly: true, ptReport: &report.PtMonitorReport{}, expected: allFiles},
+		{
+			name:       "no traced processes",
+			tracedOnly: true,
+			ptReport:   &report.PtMonitorReport{Enabled: true},
+		},
+	}
+
+	for _, test := range tt {
+		cmd := &command.StartMonitor{TracedProcessFilesOnly: test.tracedOnly}
+		files := processFileList(log.NewEntry(log.StandardLogger()), cmd, fanReport, test.ptReport)
+		sort.Strings(files)
+		assert.Equal(t, test.expected, files, test.name)
+	}
+}
@@ -251,6 +251,7 @@ func (s *Sensor) processMonitoringResults(mon monitor.CompositeMonitor) error {
 		report.PeReport,
 		report.FanReport,
 		report.PtReport,
+		report.NetReport,
 	); err != nil {
 		log.WithError(err).Error("sensor: artifact.Process() failed")
 		return fmt.Errorf("saving reports failed: %w", err)
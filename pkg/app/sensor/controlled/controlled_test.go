@@ -45,6 +45,7 @@ func newStubMonitorFunc(
 			nil,
 			fanMon,
 			ptMon,
+			stubmonitor.NewNetMonitor(ctx),
 			nil,
 			nil,
 		), nil
@@ -77,6 +78,7 @@ func (a *artifactorStub) Process(
 	peReport *report.PeMonitorReport,
 	fanReport *report.FanMonitorReport,
 	ptReport *report.PtMonitorReport,
+	netReport *report.NetMonitorReport,
 ) error {
 	return nil
 }
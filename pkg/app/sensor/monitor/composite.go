@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -14,9 +15,11 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slimtoolkit/slim/pkg/app/sensor/monitor/fanotify"
+	"github.com/slimtoolkit/slim/pkg/app/sensor/monitor/network"
 	"github.com/slimtoolkit/slim/pkg/app/sensor/monitor/ptrace"
 	"github.com/slimtoolkit/slim/pkg/ipc/command"
 	"github.com/slimtoolkit/slim/pkg/mondel"
+	ptapi "github.com/slimtoolkit/slim/pkg/monitor/ptrace"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 )
@@ -42,6 +45,7 @@ type CompositeReport struct {
 	PeReport  *report.PeMonitorReport
 	FanReport *report.FanMonitorReport
 	PtReport  *report.PtMonitorReport
+	NetReport *report.NetMonitorReport
 }
 
 type CompositeMonitor interface {
@@ -91,6 +95,7 @@ type monitor struct {
 	// peMon  *pevent.Monitor
 	fanMon fanotify.Monitor
 	ptMon  ptrace.Monitor
+	netMon network.Monitor
 
 	// Inspired by os/exec.Cmd
 	closeAfterDone []io.Closer
@@ -163,6 +168,25 @@ func NewCompositeMonitor(
 
 	signalCh := make(chan os.Signal, signalChanBufSize)
 
+	// The process network activity is captured by the ptrace monitor
+	// and then it's aggregated by the network monitor
+	// (the arm64 ptrace monitor doesn't capture the network activity yet).
+	// The socket system calls are traced only when the network activity is reported.
+	netTraced := cmd.RTASourcePT && runtime.GOARCH != "arm64"
+	netEventCh := make(chan ptapi.NetEvent, network.EventBufSize)
+	netMon := network.NewMonitor(
+		ctx,
+		del,
+		netTraced,
+		netEventCh,
+		errorCh,
+	)
+
+	var ptNetEventCh chan<- ptapi.NetEvent
+	if netTraced {
+		ptNetEventCh = netEventCh
+	}
+
 	ptMon := ptrace.NewMonitor(
 		ctx,
 		del,
@@ -177,6 +201,7 @@ func NewCompositeMonitor(
 			RunAsUser:           cmd.RunTargetAsUser,
 			RTASourcePT:         cmd.RTASourcePT,
 			ReportOnMainPidExit: cmd.ReportOnMainPidExit,
+			NetEventCh:          ptNetEventCh,
 		},
 		cmd.IncludeNew,
 		origPaths,
@@ -184,7 +209,7 @@ func NewCompositeMonitor(
 		errorCh,
 	)
 
-	m := Compose(cmd, del, fanMon, ptMon, signalCh, errorCh)
+	m := Compose(cmd, del, fanMon, ptMon, netMon, signalCh, errorCh)
 	m.closeAfterDone = closeAfterDone
 
 	return m, nil
@@ -195,6 +220,7 @@ func Compose(
 	del mondel.Publisher,
 	fanMon fanotify.Monitor,
 	ptMon ptrace.Monitor,
+	netMon network.Monitor,
 	signalCh chan os.Signal,
 	errorCh chan error,
 ) *monitor {
@@ -204,6 +230,7 @@ func Compose(
 		// TODO: peMon:  peMon,
 		fanMon: fanMon,
 		ptMon:  ptMon,
+		netMon: netMon,
 
 		signalCh: signalCh,
 		errorCh:  errorCh,
@@ -232,6 +259,14 @@ func (m *monitor) Start() error {
 		return err
 	}
 
+	if err := m.netMon.Start(); err != nil {
+		log.WithError(err).Debug("sensor: composite monitor - NET error")
+		log.Error("sensor: composite monitor - NET failed to start running")
+
+		closeAll(m.closeAfterDone)
+		return err
+	}
+
 	if err := m.ptMon.Start(); err != nil {
 		log.WithError(err).Debug("sensor: composite monitor - PTAN error")
 		log.Error("sensor: composite monitor - PTAN failed to start running")
@@ -256,6 +291,7 @@ func (m *monitor) SignalTargetApp(s os.Signal) {
 func (m *monitor) Cancel() {
 	// m.peMon.Cancel()
 	m.fanMon.Cancel()
+	m.netMon.Cancel()
 	m.ptMon.Cancel()
 }
 
@@ -282,11 +318,15 @@ func (m *monitor) Done() <-chan struct{} {
 
 			// m.peMon.Cancel()
 			m.fanMon.Cancel()
+			m.netMon.Cancel()
 
 			// <-m.peMon.Done()
 			<-m.fanMon.Done()
 			log.Debug("sensor: composite monitor - fanmon is done")
 
+			<-m.netMon.Done()
+			log.Debug("sensor: composite monitor - netmon is done")
+
 			closeAll(m.closeAfterDone)
 
 			//need to call del.Stop here to make sure we get all drained monitor events
@@ -324,11 +364,12 @@ func (m *monitor) Status() (*CompositeReport, error) {
 	// peReport, peErr := m.peMon.Status()
 	fanReport, fanErr := m.fanMon.Status()
 	ptReport, ptErr := m.ptMon.Status()
+	netReport, netErr := m.netMon.Status()
 
-	if fanErr != nil || ptErr != nil {
+	if fanErr != nil || ptErr != nil || netErr != nil {
 		return nil, fmt.Errorf(
-			"one or more monitors failed: fanotify.error=%q, ptrace.error=%q",
-			fanErr, ptErr,
+			"one or more monitors failed: fanotify.error=%q, ptrace.error=%q, network.error=%q",
+			fanErr, ptErr, netErr,
 		)
 	}
 
@@ -336,6 +377,7 @@ func (m *monitor) Status() (*CompositeReport, error) {
 		// PeReport: peReport,
 		FanReport: fanReport,
 		PtReport:  ptReport,
+		NetReport: netReport,
 	}, nil
 }
 
@@ -19,6 +19,7 @@ func TestCompositeMonitor_Lifecycle(t *testing.T) {
 	mon := &monitor{
 		fanMon: stubmonitor.NewFanMonitor(context.Background()),
 		ptMon:  stubmonitor.NewPtMonitor(context.Background()),
+		netMon: stubmonitor.NewNetMonitor(context.Background()),
 	}
 
 	mon.Start()
@@ -441,5 +441,37 @@ func getProcessInfo(pid int32) (*report.ProcessInfo, error) {
 		info.ParentPid = int32(procPpid)
 	}
 
+	info.UID, info.GID = getProcessFSIDs(pid)
 	return info, nil
 }
+
+// getProcessFSIDs returns the file system UID and GID of the process
+// (the values used for the file access checks)
+func getProcessFSIDs(pid int32) (int, int) {
+	uid, gid := -1, -1
+	status, err := os.ReadFile(procFilePath(int(pid), "status"))
+	if err != nil {
+		return uid, gid
+	}
+
+	for _, line := range strings.Split(string(status), "\n") {
+		//Uid: <real> <effective> <saved> <file system>
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			continue
+		}
+
+		switch fields[0] {
+		case "Uid:":
+			if id, err := strconv.Atoi(fields[4]); err == nil {
+				uid = id
+			}
+		case "Gid:":
+			if id, err := strconv.Atoi(fields[4]); err == nil {
+				gid = id
+			}
+		}
+	}
+
+	return uid, gid
+}
@@ -0,0 +1,400 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/mondel"
+	"github.com/slimtoolkit/slim/pkg/monitor/ptrace"
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+const (
+	EventBufSize   = 2000
+	SampleInterval = 500 * time.Millisecond
+	procFsFilePath = "/proc/%v/%v"
+)
+
+type Monitor interface {
+	// Starts the long running monitoring. The method itself is not
+	// blocking and not reentrant!
+	Start() error
+
+	// Cancels the underlying event processing but doesn't make
+	// the current monitor done immediately. You still need to await
+	// the final cleanup with <-mon.Done() before accessing the status.
+	Cancel()
+
+	// With Done clients can await for the monitoring completion.
+	// The method is reentrant - every invocation returns the same
+	// instance of the channel.
+	Done() <-chan struct{}
+
+	Status() (*report.NetMonitorReport, error)
+}
+
+type status struct {
+	report *report.NetMonitorReport
+	err    error
+}
+
+// The network monitor is a passive monitor. It aggregates the network
+// activity events captured by the ptrace monitor (connect/bind/listen/accept
+// calls and DNS lookups) when the target app is traced and it samples
+// the listening and connected sockets from the /proc/net socket tables.
+type monitor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	del mondel.Publisher
+
+	traced  bool
+	eventCh <-chan ptrace.NetEvent
+
+	// Sampled socket inodes (to count each socket only once).
+	sampled map[string]struct{}
+
+	status  status
+	doneCh  chan struct{}
+	errorCh chan<- error
+
+	logger *log.Entry
+}
+
+func NewMonitor(
+	ctx context.Context,
+	del mondel.Publisher,
+	traced bool,
+	eventCh <-chan ptrace.NetEvent,
+	errorCh chan<- error,
+) Monitor {
+	logger := log.WithFields(log.Fields{
+		"app": "sensor",
+		"com": "netmon",
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &monitor{
+		ctx:    ctx,
+		cancel: cancel,
+
+		del: del,
+
+		traced:  traced,
+		eventCh: eventCh,
+		sampled: map[string]struct{}{},
+
+		doneCh:  make(chan struct{}),
+		errorCh: errorCh,
+		logger:  logger,
+	}
+}
+
+func (m *monitor) Start() error {
+	logger := m.logger.WithField("op", "Start")
+	logger.Info("call")
+	defer logger.Info("exit")
+
+	go func() {
+		logger := m.logger.WithField("op", "processor")
+		logger.Info("call")
+
+		netReport := &report.NetMonitorReport{
+			Enabled:   true,
+			Traced:    m.traced,
+			Processes: map[string]*report.NetProcessInfo{},
+		}
+
+		ticker := time.NewTicker(SampleInterval)
+		defer ticker.Stop()
+
+		m.sample(netReport)
+
+	process:
+		for {
+			select {
+			case <-m.ctx.Done():
+				logger.Info("process - done")
+				break process
+
+			case e := <-m.eventCh:
+				m.processEvent(e, netReport)
+
+			case <-ticker.C:
+				m.sample(netReport)
+			}
+		}
+
+		m.sample(netReport)
+
+		logger.Debug("done, drain - starting...")
+
+	drain:
+		for {
+			select {
+			case e := <-m.eventCh:
+				m.processEvent(e, netReport)
+
+			default:
+				logger.Debug("draining - done")
+				break drain
+			}
+		}
+
+		netReport.Hostnames = hostnames(netReport)
+
+		logger.Debugf("sending report (processed %v events)...", netReport.EventCount)
+		m.status.report = netReport
+		close(m.doneCh)
+		logger.Info("exit")
+	}()
+
+	return nil
+}
+
+func (m *monitor) Cancel() {
+	m.cancel()
+}
+
+func (m *monitor) Done() <-chan struct{} {
+	return m.doneCh
+}
+
+func (m *monitor) Status() (*report.NetMonitorReport, error) {
+	return m.status.report, m.status.err
+}
+
+func (m *monitor) processEvent(e ptrace.NetEvent, netReport *report.NetMonitorReport) {
+	netReport.EventCount++
+	logger := m.logger.WithField("op", "processEvent")
+	logger.Debugf("[%v] handling event %#v", netReport.EventCount, e)
+
+	if m.del != nil && e.Error == "" {
+		delEvent := &report.MonitorDataEvent{
+			Source:   report.MDESourceNet,
+			Type:     report.MDETypeNetwork,
+			Pid:      int32(e.Pid),
+			Artifact: eventArtifact(e),
+			Op:       e.Op,
+			OpNum:    e.CallNum,
+		}
+
+		if err := m.del.Publish(delEvent); err != nil {
+			logger.Errorf(
+				"mondel publish event failed - source=%v type=%v: %v",
+				delEvent.Source, delEvent.Type, err,
+			)
+		}
+	}
+
+	pidKey := strconv.Itoa(e.Pid)
+	pinfo, found := netReport.Processes[pidKey]
+	if !found {
+		pinfo = &report.NetProcessInfo{
+			Pid: int32(e.Pid),
+		}
+
+		// Best effort (the process might be already gone).
+		pinfo.Path, _ = os.Readlink(fmt.Sprintf(procFsFilePath, e.Pid, "exe"))
+		netReport.Processes[pidKey] = pinfo
+	}
+
+	switch e.Op {
+	case report.NetOpDNS:
+		if pinfo.DNSLookups == nil {
+			pinfo.DNSLookups = map[string]*report.DNSLookupInfo{}
+		}
+
+		lookup, found := pinfo.DNSLookups[e.Hostname]
+		if !found {
+			lookup = &report.DNSLookupInfo{
+				Name:       e.Hostname,
+				QueryTypes: map[string]uint64{},
+				Resolvers:  map[string]uint64{},
+			}
+			pinfo.DNSLookups[e.Hostname] = lookup
+		}
+
+		lookup.Count++
+		lookup.QueryTypes[e.QueryType]++
+		lookup.Resolvers[endpointAddress(e.Address, e.Port)]++
+
+	case report.NetOpConnect, report.NetOpSend:
+		if pinfo.Connections == nil {
+			pinfo.Connections = map[string]*report.NetActivityInfo{}
+		}
+
+		updateActivity(pinfo.Connections, e)
+
+	case report.NetOpBind, report.NetOpListen, report.NetOpAccept:
+		if pinfo.Listeners == nil {
+			pinfo.Listeners = map[string]*report.NetActivityInfo{}
+		}
+
+		updateActivity(pinfo.Listeners, e)
+
+	default:
+		logger.Debugf("unexpected network operation - %s", e.Op)
+	}
+}
+
+func (m *monitor) sample(netReport *report.NetMonitorReport) {
+	logger := m.logger.WithField("op", "sample")
+	sockets, err := readProcNetSockets()
+	if err != nil {
+		logger.Debugf("error reading socket tables - %v", err)
+		return
+	}
+
+	netReport.SampleCount++
+	selfInodes := selfSocketInodes()
+
+	var connections []*procNetSocket
+	listenPorts := map[string]struct{}{}
+	for _, s := range sockets {
+		if _, found := selfInodes[s.inode]; found {
+			continue
+		}
+
+		switch {
+		case s.isListener():
+			listenPorts[fmt.Sprintf("%s/%d", s.protocol, s.localPort)] = struct{}{}
+			if m.isNewSample(s) {
+				if netReport.Listeners == nil {
+					netReport.Listeners = map[string]*report.NetActivityInfo{}
+				}
+
+				updateActivity(netReport.Listeners, ptrace.NetEvent{
+					Op:       report.NetOpListen,
+					Protocol: s.protocol,
+					Family:   s.family,
+					Address:  s.localAddr,
+					Port:     s.localPort,
+				})
+			}
+		case s.isConnection():
+			connections = append(connections, s)
+		}
+	}
+
+	for _, s := range connections {
+		if !m.isNewSample(s) {
+			continue
+		}
+
+		if _, found := listenPorts[fmt.Sprintf("%s/%d", s.protocol, s.localPort)]; found {
+			//inbound connection (recorded as a listener peer)
+			for _, info := range netReport.Listeners {
+				if info.Protocol == s.protocol && info.Port == s.localPort {
+					info.Ops[report.NetOpAccept]++
+					if info.Peers == nil {
+						info.Peers = map[string]uint64{}
+					}
+
+					info.Peers[s.remoteAddr]++
+					break
+				}
+			}
+
+			continue
+		}
+
+		if netReport.Connections == nil {
+			netReport.Connections = map[string]*report.NetActivityInfo{}
+		}
+
+		updateActivity(netReport.Connections, ptrace.NetEvent{
+			Op:       report.NetOpConnect,
+			Protocol: s.protocol,
+			Family:   s.family,
+			Address:  s.remoteAddr,
+			Port:     s.remotePort,
+		})
+	}
+}
+
+func (m *monitor) isNewSample(s *procNetSocket) bool {
+	key := fmt.Sprintf("%s:%s", s.protocol, s.inode)
+	if _, found := m.sampled[key]; found {
+		return false
+	}
+
+	m.sampled[key] = struct{}{}
+	return true
+}
+
+func updateActivity(activity map[string]*report.NetActivityInfo, e ptrace.NetEvent) {
+	key := endpointKey(e.Protocol, e.Address, e.Port)
+	info, found := activity[key]
+	if !found {
+		info = &report.NetActivityInfo{
+			Protocol: e.Protocol,
+			Family:   e.Family,
+			Address:  e.Address,
+			Port:     e.Port,
+			Ops:      map[string]uint64{},
+		}
+		activity[key] = info
+	}
+
+	info.Ops[e.Op]++
+	if e.Error != "" {
+		info.ErrorCount++
+	}
+
+	if e.Peer != "" {
+		if info.Peers == nil {
+			info.Peers = map[string]uint64{}
+		}
+
+		info.Peers[e.Peer]++
+	}
+}
+
+func hostnames(netReport *report.NetMonitorReport) []string {
+	names := map[string]struct{}{}
+	for _, pinfo := range netReport.Processes {
+		for name := range pinfo.DNSLookups {
+			names[name] = struct{}{}
+		}
+	}
+
+	var result []string
+	for name := range names {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+func endpointAddress(address string, port int) string {
+	if port == 0 {
+		return address
+	}
+
+	return net.JoinHostPort(address, strconv.Itoa(port))
+}
+
+// endpointKey returns the network activity record key for an endpoint
+// (e.g., "tcp:10.1.1.1:443" or "unix:/var/run/app.sock")
+func endpointKey(protocol, address string, port int) string {
+	return fmt.Sprintf("%s:%s", protocol, endpointAddress(address, port))
+}
+
+// eventArtifact returns the mondel event artifact value for a network event
+// (the hostname for DNS lookups and the endpoint key for everything else)
+func eventArtifact(e ptrace.NetEvent) string {
+	if e.Op == report.NetOpDNS {
+		return e.Hostname
+	}
+
+	return endpointKey(e.Protocol, e.Address, e.Port)
+}
@@ -0,0 +1,194 @@
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+// The /proc/net socket tables describe the sockets in the network namespace
+// of the sensor process, which is the network namespace of the target container.
+const (
+	procNetPath  = "/proc/net"
+	procSelfFds  = "/proc/self/fd"
+	socketPrefix = "socket:["
+)
+
+// Socket states (include/net/tcp_states.h)
+const (
+	tcpEstablished = 0x01
+	tcpSynSent     = 0x02
+	tcpClose       = 0x07
+	tcpListen      = 0x0a
+)
+
+type procNetTable struct {
+	name     string
+	protocol string
+	family   string
+}
+
+var procNetTables = []procNetTable{
+	{name: "tcp", protocol: report.NetProtoTCP, family: report.NetFamilyIPv4},
+	{name: "tcp6", protocol: report.NetProtoTCP, family: report.NetFamilyIPv6},
+	{name: "udp", protocol: report.NetProtoUDP, family: report.NetFamilyIPv4},
+	{name: "udp6", protocol: report.NetProtoUDP, family: report.NetFamilyIPv6},
+}
+
+type procNetSocket struct {
+	protocol   string
+	family     string
+	localAddr  string
+	localPort  int
+	remoteAddr string
+	remotePort int
+	state      int
+	inode      string
+}
+
+// isListener returns true for the listening TCP sockets and the bound (unconnected) UDP sockets
+func (s *procNetSocket) isListener() bool {
+	if s.protocol == report.NetProtoTCP {
+		return s.state == tcpListen
+	}
+
+	return s.state == tcpClose && s.localPort != 0 && s.remotePort == 0
+}
+
+// isConnection returns true for the connected TCP and UDP sockets
+// (including the TCP connections that are being established)
+func (s *procNetSocket) isConnection() bool {
+	if s.protocol == report.NetProtoTCP {
+		return s.state == tcpEstablished || s.state == tcpSynSent
+	}
+
+	return s.state == tcpEstablished && s.remotePort != 0
+}
+
+func readProcNetSockets() ([]*procNetSocket, error) {
+	var sockets []*procNetSocket
+	for _, table := range procNetTables {
+		tableSockets, err := readProcNetTable(table)
+		if err != nil {
+			if os.IsNotExist(err) {
+				//no IPv6 in the container, for example
+				continue
+			}
+
+			return nil, err
+		}
+
+		sockets = append(sockets, tableSockets...)
+	}
+
+	return sockets, nil
+}
+
+func readProcNetTable(table procNetTable) ([]*procNetSocket, error) {
+	f, err := os.Open(filepath.Join(procNetPath, table.name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseProcNetTable(f, table)
+}
+
+// parseProcNetTable parses the /proc/net/{tcp,tcp6,udp,udp6} socket table data
+// (the malformed entries are skipped)
+func parseProcNetTable(r io.Reader, table procNetTable) ([]*procNetSocket, error) {
+	var sockets []*procNetSocket
+	scanner := bufio.NewScanner(r)
+	//skipping the header line
+	scanner.Scan()
+	for scanner.Scan() {
+		//sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		localAddr, localPort, ok := parseProcNetAddr(fields[1])
+		if !ok {
+			continue
+		}
+
+		remoteAddr, remotePort, ok := parseProcNetAddr(fields[2])
+		if !ok {
+			continue
+		}
+
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			continue
+		}
+
+		sockets = append(sockets, &procNetSocket{
+			protocol:   table.protocol,
+			family:     table.family,
+			localAddr:  localAddr,
+			localPort:  localPort,
+			remoteAddr: remoteAddr,
+			remotePort: remotePort,
+			state:      int(state),
+			inode:      fields[9],
+		})
+	}
+
+	return sockets, scanner.Err()
+}
+
+// parseProcNetAddr decodes the "ADDR:PORT" /proc/net address values
+// (the address is a sequence of 32-bit words in the host byte order)
+func parseProcNetAddr(value string) (string, int, bool) {
+	addrHex, portHex, found := strings.Cut(value, ":")
+	if !found {
+		return "", 0, false
+	}
+
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return "", 0, false
+	}
+
+	data, err := hex.DecodeString(addrHex)
+	if err != nil || (len(data) != net.IPv4len && len(data) != net.IPv6len) {
+		return "", 0, false
+	}
+
+	ip := make(net.IP, len(data))
+	for i := 0; i < len(data); i += 4 {
+		binary.NativeEndian.PutUint32(ip[i:], binary.BigEndian.Uint32(data[i:]))
+	}
+
+	return ip.String(), int(port), true
+}
+
+// selfSocketInodes returns the socket inodes owned by the sensor
+// (to exclude the sensor IPC sockets from the sampled network activity)
+func selfSocketInodes() map[string]struct{} {
+	inodes := map[string]struct{}{}
+	entries, err := os.ReadDir(procSelfFds)
+	if err != nil {
+		return inodes
+	}
+
+	for _, entry := range entries {
+		link, err := os.Readlink(filepath.Join(procSelfFds, entry.Name()))
+		if err != nil || !strings.HasPrefix(link, socketPrefix) {
+			continue
+		}
+
+		inodes[strings.TrimSuffix(strings.TrimPrefix(link, socketPrefix), "]")] = struct{}{}
+	}
+
+	return inodes
+}
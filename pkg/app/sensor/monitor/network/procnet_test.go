@@ -0,0 +1,206 @@
+package network
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+// The sample /proc/net data is from a little-endian host
+func skipOnBigEndian(t *testing.T) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("little-endian /proc/net sample data")
+	}
+}
+
+func TestParseProcNetAddr(t *testing.T) {
+	skipOnBigEndian(t)
+
+	tt := []struct {
+		value        string
+		expectedAddr string
+		expectedPort int
+		expectedOK   bool
+	}{
+		{value: "0100007F:1F90", expectedAddr: "127.0.0.1", expectedPort: 8080, expectedOK: true},
+		{value: "00000000:0035", expectedAddr: "0.0.0.0", expectedPort: 53, expectedOK: true},
+		{value: "0200000A:01BB", expectedAddr: "10.0.0.2", expectedPort: 443, expectedOK: true},
+		{value: "00000000000000000000000001000000:1F90", expectedAddr: "::1", expectedPort: 8080, expectedOK: true},
+		{value: "B80D0120000000000000000001000000:0050", expectedAddr: "2001:db8::1", expectedPort: 80, expectedOK: true},
+		{value: "0000000000000000FFFF00000200000A:01BB", expectedAddr: "10.0.0.2", expectedPort: 443, expectedOK: true},
+		{value: "0100007F"},
+		{value: "0100007F:XYZ"},
+		{value: "0100007F:10000"},
+		{value: "0100ZZ7F:1F90"},
+		{value: "0100007F00:1F90"},
+		{value: ""},
+	}
+
+	for _, test := range tt {
+		t.Run(test.value, func(t *testing.T) {
+			addr, port, ok := parseProcNetAddr(test.value)
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedAddr, addr)
+			assert.Equal(t, test.expectedPort, port)
+		})
+	}
+}
+
+func TestParseProcNetTable(t *testing.T) {
+	skipOnBigEndian(t)
+
+	tcpTable := procNetTable{name: "tcp", protocol: report.NetProtoTCP, family: report.NetFamilyIPv4}
+	udp6Table := procNetTable{name: "udp6", protocol: report.NetProtoUDP, family: report.NetFamilyIPv6}
+
+	tt := []struct {
+		name     string
+		table    procNetTable
+		data     string
+		expected []*procNetSocket
+	}{
+		{
+			name:  "tcp",
+			table: tcpTable,
+			data: `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 12345 1 0000000000000000 100 0 0 10 0
+   1: 0200000A:A2C4 0300000A:01BB 01 00000000:00000000 00:00000000 00000000     0        0 12346 1 0000000000000000 20 4 30 10 -1
+`,
+			expected: []*procNetSocket{
+				{
+					protocol:   report.NetProtoTCP,
+					family:     report.NetFamilyIPv4,
+					localAddr:  "0.0.0.0",
+					localPort:  8080,
+					remoteAddr: "0.0.0.0",
+					remotePort: 0,
+					state:      tcpListen,
+					inode:      "12345",
+				},
+				{
+					protocol:   report.NetProtoTCP,
+					family:     report.NetFamilyIPv4,
+					localAddr:  "10.0.0.2",
+					localPort:  41668,
+					remoteAddr: "10.0.0.3",
+					remotePort: 443,
+					state:      tcpEstablished,
+					inode:      "12346",
+				},
+			},
+		},
+		{
+			name:  "udp6",
+			table: udp6Table,
+			data: `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  0: 00000000000000000000000000000000:14E9 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 22222 2 0000000000000000 0
+`,
+			expected: []*procNetSocket{
+				{
+					protocol:   report.NetProtoUDP,
+					family:     report.NetFamilyIPv6,
+					localAddr:  "::",
+					localPort:  5353,
+					remoteAddr: "::",
+					remotePort: 0,
+					state:      tcpClose,
+					inode:      "22222",
+				},
+			},
+		},
+		{
+			name:  "header only",
+			table: tcpTable,
+			data:  "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n",
+		},
+		{
+			name:  "empty",
+			table: tcpTable,
+		},
+		{
+			name:  "malformed entries",
+			table: tcpTable,
+			data: `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A
+   1: 00000000 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1 0000000000000000 100 0 0 10 0
+   2: 00000000:1F90 0000:XYZ 0A 00000000:00000000 00:00000000 00000000     0        0 2 1 0000000000000000 100 0 0 10 0
+   3: 00000000:1F90 00000000:0000 ZZ 00000000:00000000 00:00000000 00000000     0        0 3 1 0000000000000000 100 0 0 10 0
+   4: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 4 1 0000000000000000 100 0 0 10 0
+`,
+			expected: []*procNetSocket{
+				{
+					protocol:   report.NetProtoTCP,
+					family:     report.NetFamilyIPv4,
+					localAddr:  "0.0.0.0",
+					localPort:  80,
+					remoteAddr: "0.0.0.0",
+					remotePort: 0,
+					state:      tcpListen,
+					inode:      "4",
+				},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			sockets, err := parseProcNetTable(strings.NewReader(test.data), test.table)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, sockets)
+		})
+	}
+}
+
+func TestProcNetSocketState(t *testing.T) {
+	tt := []struct {
+		name               string
+		socket             procNetSocket
+		expectedListener   bool
+		expectedConnection bool
+	}{
+		{
+			name:             "tcp listen",
+			socket:           procNetSocket{protocol: report.NetProtoTCP, localPort: 8080, state: tcpListen},
+			expectedListener: true,
+		},
+		{
+			name:               "tcp established",
+			socket:             procNetSocket{protocol: report.NetProtoTCP, localPort: 41668, remotePort: 443, state: tcpEstablished},
+			expectedConnection: true,
+		},
+		{
+			name:               "tcp syn sent",
+			socket:             procNetSocket{protocol: report.NetProtoTCP, localPort: 41668, remotePort: 443, state: tcpSynSent},
+			expectedConnection: true,
+		},
+		{
+			name:   "tcp closed",
+			socket: procNetSocket{protocol: report.NetProtoTCP, localPort: 41668, state: tcpClose},
+		},
+		{
+			name:             "udp bound",
+			socket:           procNetSocket{protocol: report.NetProtoUDP, localPort: 5353, state: tcpClose},
+			expectedListener: true,
+		},
+		{
+			name:               "udp connected",
+			socket:             procNetSocket{protocol: report.NetProtoUDP, localPort: 40000, remotePort: 53, state: tcpEstablished},
+			expectedConnection: true,
+		},
+		{
+			name:   "udp unbound",
+			socket: procNetSocket{protocol: report.NetProtoUDP, state: tcpClose},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedListener, test.socket.isListener())
+			assert.Equal(t, test.expectedConnection, test.socket.isConnection())
+		})
+	}
+}
@@ -17,13 +17,33 @@ import (
 	"github.com/slimtoolkit/slim/pkg/errors"
 	"github.com/slimtoolkit/slim/pkg/launcher"
 	"github.com/slimtoolkit/slim/pkg/mondel"
+	"github.com/slimtoolkit/slim/pkg/monitor/ptrace"
 	"github.com/slimtoolkit/slim/pkg/report"
 	"github.com/slimtoolkit/slim/pkg/system"
 )
 
 type syscallEvent struct {
-	callNum uint32
-	retVal  uint64
+	callNum   uint32
+	retVal    uint64
+	gotArgVal bool
+	argVal    uint64
+}
+
+func callParam(regs unix.PtraceRegsArm64, index uint) uint64 {
+	switch index {
+	case 0:
+		return system.CallFirstParam(regs)
+	case 1:
+		return system.CallSecondParam(regs)
+	case 2:
+		return system.CallThirdParam(regs)
+	case 3:
+		return system.CallFourthParam(regs)
+	case 4:
+		return system.CallFifthParam(regs)
+	default:
+		return system.CallSixthParam(regs)
+	}
 }
 
 const (
@@ -135,6 +155,7 @@ func (m *monitor) Start() error {
 		}
 
 		syscallStats := map[uint32]uint64{}
+		syscallArgs := ptrace.CallArgs{}
 		eventChan := make(chan syscallEvent, eventBufSize)
 		collectorDoneChan := make(chan int, 1)
 
@@ -220,6 +241,8 @@ func (m *monitor) Start() error {
 			gotRetVal := false
 			var callNum uint64
 			var retVal uint64
+			var gotArgVal bool
+			var argVal uint64
 			for wstat.Stopped() {
 				var regs unix.PtraceRegsArm64
 
@@ -235,6 +258,13 @@ func (m *monitor) Start() error {
 					syscallReturn = true
 					gotCallNum = true
 
+					//saving the sensitive argument value (if any)
+					var argIndex uint
+					argIndex, gotArgVal = ptrace.CallArgIndex(callNum)
+					if gotArgVal {
+						argVal = callParam(regs, argIndex)
+					}
+
 				case true:
 					if err := unix.PtraceGetRegSetArm64(targetPid, 1, &regs); err != nil {
 						//if err := syscall.PtraceGetRegs(pid, &regs); err != nil {
@@ -267,8 +297,10 @@ func (m *monitor) Start() error {
 
 					select {
 					case eventChan <- syscallEvent{
-						callNum: uint32(callNum),
-						retVal:  retVal,
+						callNum:   uint32(callNum),
+						retVal:    retVal,
+						gotArgVal: gotArgVal,
+						argVal:    argVal,
 					}:
 					case <-m.ctx.Done():
 						logger.Info("stopping...")
@@ -306,6 +338,10 @@ func (m *monitor) Start() error {
 				} else {
 					syscallStats[e.callNum] = 1
 				}
+
+				if e.gotArgVal {
+					syscallArgs.Add(e.callNum, e.argVal)
+				}
 			}
 		}
 
@@ -322,6 +358,7 @@ func (m *monitor) Start() error {
 		}
 
 		ptReport.SyscallNum = uint32(len(ptReport.SyscallStats))
+		ptReport.SyscallArgs = syscallArgs.Report()
 
 		m.status.report = ptReport
 		close(m.doneCh)
@@ -192,6 +192,7 @@ func (s *Sensor) run() error {
 		report.PeReport,
 		report.FanReport,
 		report.PtReport,
+		report.NetReport,
 	); err != nil {
 		log.WithError(err).Error("sensor: artifact.Process() failed")
 		return fmt.Errorf("saving reports failed: %w", err)
@@ -0,0 +1,924 @@
+// Package containerd implements the container runtime API client for containerd
+// (using nerdctl, the Docker-compatible containerd CLI, to execute the runtime operations).
+package containerd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/go-units"
+	docker "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/crt"
+)
+
+const (
+	DefaultBinary = "nerdctl"
+
+	//nerdctl reads the namespace from the CONTAINERD_NAMESPACE env var
+	EnvNamespace = "CONTAINERD_NAMESPACE"
+
+	imageCreatedAtLayout = "2006-01-02 15:04:05 -0700 MST"
+	missingImageID       = "<missing>"
+)
+
+// Error is the nerdctl command error
+type Error struct {
+	Args     []string
+	ExitCode int
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("nerdctl %s error (exit code %d): %s", strings.Join(e.Args, " "), e.ExitCode, e.Message)
+}
+
+func isNotFound(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	msg := strings.ToLower(e.Message)
+	return strings.Contains(msg, "not found") || strings.Contains(msg, "no such")
+}
+
+type execInstance struct {
+	options  docker.CreateExecOptions
+	running  bool
+	exitCode int
+}
+
+// Client is the containerd runtime API client (based on nerdctl)
+type Client struct {
+	Binary  string
+	Address string
+
+	execMu sync.Mutex
+	execs  map[string]*execInstance
+}
+
+var _ crt.APIClient = (*Client)(nil)
+
+// New creates a new containerd API client for the containerd address
+// (unix:///path/to/containerd.sock; the default containerd address is used if the address is empty)
+func New(address string) (*Client, error) {
+	binary, err := exec.LookPath(DefaultBinary)
+	if err != nil {
+		return nil, fmt.Errorf("%s is required for the containerd runtime engine - %w", DefaultBinary, err)
+	}
+
+	log.Debugf("containerd.New: binary=%s address=%s namespace=%s", binary, address, os.Getenv(EnvNamespace))
+	client := &Client{
+		Binary:  binary,
+		Address: address,
+		execs:   map[string]*execInstance{},
+	}
+
+	return client, nil
+}
+
+func (c *Client) command(ctx context.Context, args []string) *exec.Cmd {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var cmdArgs []string
+	if c.Address != "" {
+		cmdArgs = append(cmdArgs, "--address", c.Address)
+	}
+
+	cmdArgs = append(cmdArgs, args...)
+	log.Tracef("containerd.Client.command: %s %s", c.Binary, strings.Join(cmdArgs, " "))
+	return exec.CommandContext(ctx, c.Binary, cmdArgs...)
+}
+
+func (c *Client) run(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	cmd := c.command(ctx, args)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		cmdErr := &Error{
+			Args:     args,
+			ExitCode: -1,
+			Message:  strings.TrimSpace(stderr.String()),
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			cmdErr.ExitCode = exitErr.ExitCode()
+		}
+
+		if cmdErr.Message == "" {
+			cmdErr.Message = err.Error()
+		}
+
+		return cmdErr
+	}
+
+	return nil
+}
+
+func (c *Client) output(args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := c.run(context.Background(), nil, &stdout, args...); err != nil {
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// jsonLines decodes the output of the nerdctl commands using the '{{json .}}' format
+func jsonLines[T any](data []byte) ([]T, error) {
+	var records []T
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record T
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+func parseTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, imageCreatedAtLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func parseSize(value string) int64 {
+	if size, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return size
+	}
+
+	parse := units.FromHumanSize
+	if strings.Contains(value, "iB") {
+		//binary size units (e.g., MiB)
+		parse = units.RAMInBytes
+		value = strings.ReplaceAll(value, "iB", "B")
+	}
+
+	//the unknown sizes are reported as zero (not as -1 returned with the parsing errors)
+	size, err := parse(value)
+	if err != nil {
+		return 0
+	}
+
+	return size
+}
+
+// Ping checks the containerd connection
+func (c *Client) Ping() error {
+	return c.run(context.Background(), nil, io.Discard, "info")
+}
+
+// InspectImage returns the image information (in the Docker-compatible format)
+func (c *Client) InspectImage(name string) (*docker.Image, error) {
+	data, err := c.output("image", "inspect", "--mode=dockercompat", name)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, docker.ErrNoSuchImage
+		}
+
+		return nil, err
+	}
+
+	var images []docker.Image
+	if err := json.Unmarshal(data, &images); err != nil {
+		return nil, err
+	}
+
+	if len(images) == 0 {
+		return nil, docker.ErrNoSuchImage
+	}
+
+	return &images[0], nil
+}
+
+func digestID(id string) string {
+	if id != "" && !strings.Contains(id, ":") {
+		return fmt.Sprintf("sha256:%s", id)
+	}
+
+	return id
+}
+
+// ListImages returns the local images
+func (c *Client) ListImages(opts docker.ListImagesOptions) ([]docker.APIImages, error) {
+	args := []string{"images", "--no-trunc", "--format", "{{json .}}"}
+	if opts.All {
+		args = append(args, "--all")
+	}
+
+	for name, values := range opts.Filters {
+		for _, value := range values {
+			args = append(args, "--filter", fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+
+	data, err := c.output(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	type imageRecord struct {
+		ID         string
+		Repository string
+		Tag        string
+		Digest     string
+		CreatedAt  string
+		Size       string
+	}
+
+	records, err := jsonLines[imageRecord](data)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []docker.APIImages
+	imageIdx := map[string]int{}
+	for _, record := range records {
+		id := digestID(record.ID)
+		idx, found := imageIdx[id]
+		if !found {
+			idx = len(images)
+			imageIdx[id] = idx
+			images = append(images, docker.APIImages{
+				ID:      id,
+				Created: parseTime(record.CreatedAt).Unix(),
+				Size:    parseSize(record.Size),
+			})
+		}
+
+		image := &images[idx]
+		if record.Repository != "" && record.Repository != "<none>" {
+			if record.Tag != "" && record.Tag != "<none>" {
+				image.RepoTags = append(image.RepoTags, fmt.Sprintf("%s:%s", record.Repository, record.Tag))
+			}
+
+			if record.Digest != "" {
+				repoDigest := fmt.Sprintf("%s@%s", record.Repository, record.Digest)
+				if !slices.Contains(image.RepoDigests, repoDigest) {
+					image.RepoDigests = append(image.RepoDigests, repoDigest)
+				}
+			}
+		}
+	}
+
+	return images, nil
+}
+
+// ImageHistory returns the image history records
+// (the intermediate records don't have image IDs)
+func (c *Client) ImageHistory(name string) ([]docker.ImageHistory, error) {
+	image, err := c.InspectImage(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.output("image", "history", "--no-trunc", "--human=false", "--format", "{{json .}}", name)
+	if err != nil {
+		return nil, err
+	}
+
+	type historyRecord struct {
+		CreatedAt string
+		CreatedBy string
+		Size      string
+		Comment   string
+	}
+
+	records, err := jsonLines[historyRecord](data)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]docker.ImageHistory, 0, len(records))
+	for idx, record := range records {
+		item := docker.ImageHistory{
+			ID:        missingImageID,
+			Created:   parseTime(record.CreatedAt).Unix(),
+			CreatedBy: record.CreatedBy,
+			Size:      parseSize(record.Size),
+			Comment:   record.Comment,
+		}
+
+		if idx == 0 {
+			item.ID = image.ID
+			item.Tags = image.RepoTags
+		}
+
+		history = append(history, item)
+	}
+
+	return history, nil
+}
+
+// PullImage pulls the image from its registry
+// (the nerdctl registry credentials are used; the auth configuration is ignored)
+func (c *Client) PullImage(opts docker.PullImageOptions, auth docker.AuthConfiguration) error {
+	if opts.Repository == "" {
+		return docker.ErrNoSuchImage
+	}
+
+	reference := opts.Repository
+	switch {
+	case strings.HasPrefix(opts.Tag, "sha256:"):
+		reference = fmt.Sprintf("%s@%s", reference, opts.Tag)
+	case opts.Tag != "":
+		reference = fmt.Sprintf("%s:%s", reference, opts.Tag)
+	}
+
+	if auth != (docker.AuthConfiguration{}) {
+		log.Debug("containerd.Client.PullImage: using nerdctl registry credentials")
+	}
+
+	args := []string{"pull"}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+
+	args = append(args, reference)
+	output := opts.OutputStream
+	if output == nil {
+		output = io.Discard
+	}
+
+	return c.run(opts.Context, nil, output, args...)
+}
+
+// ExportImage saves the image as a 'docker save' compatible tar
+func (c *Client) ExportImage(opts docker.ExportImageOptions) error {
+	err := c.run(opts.Context, nil, opts.OutputStream, "save", opts.Name)
+	if isNotFound(err) {
+		return docker.ErrNoSuchImage
+	}
+
+	return err
+}
+
+// LoadImage loads the images from the image tar (docker-archive or oci-archive)
+func (c *Client) LoadImage(opts docker.LoadImageOptions) error {
+	output := opts.OutputStream
+	if output == nil {
+		output = io.Discard
+	}
+
+	return c.run(opts.Context, opts.InputStream, output, "load")
+}
+
+func createContainerArgs(opts docker.CreateContainerOptions) ([]string, error) {
+	if opts.Config == nil {
+		return nil, fmt.Errorf("no container config")
+	}
+
+	config := opts.Config
+	args := []string{"create"}
+	if opts.Name != "" {
+		args = append(args, "--name", opts.Name)
+	}
+
+	for _, kv := range config.Env {
+		args = append(args, "--env", kv)
+	}
+
+	var command []string
+	if len(config.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", config.Entrypoint[0])
+		command = append(command, config.Entrypoint[1:]...)
+	}
+
+	command = append(command, config.Cmd...)
+
+	if config.User != "" {
+		args = append(args, "--user", config.User)
+	}
+
+	if config.WorkingDir != "" {
+		args = append(args, "--workdir", config.WorkingDir)
+	}
+
+	if config.Hostname != "" {
+		args = append(args, "--hostname", config.Hostname)
+	}
+
+	for k, v := range config.Labels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	if config.Tty {
+		args = append(args, "--tty")
+	}
+
+	if config.OpenStdin {
+		args = append(args, "--interactive")
+	}
+
+	if hc := opts.HostConfig; hc != nil {
+		if hc.PublishAllPorts {
+			return nil, fmt.Errorf("%w - publish all ports", crt.ErrNotSupported)
+		}
+
+		if hc.AutoRemove {
+			args = append(args, "--rm")
+		}
+
+		if hc.Privileged {
+			args = append(args, "--privileged")
+		}
+
+		for _, capName := range hc.CapAdd {
+			args = append(args, "--cap-add", capName)
+		}
+
+		for _, capName := range hc.CapDrop {
+			args = append(args, "--cap-drop", capName)
+		}
+
+		for _, bind := range hc.Binds {
+			args = append(args, "--volume", bind)
+		}
+
+		for _, mount := range hc.Mounts {
+			mountType := mount.Type
+			if mountType == "" {
+				mountType = "bind"
+			}
+
+			spec := fmt.Sprintf("type=%s,target=%s", mountType, mount.Target)
+			if mount.Source != "" {
+				spec = fmt.Sprintf("%s,source=%s", spec, mount.Source)
+			}
+
+			if mount.ReadOnly {
+				spec = fmt.Sprintf("%s,readonly", spec)
+			}
+
+			args = append(args, "--mount", spec)
+		}
+
+		for port, bindings := range hc.PortBindings {
+			if len(bindings) == 0 {
+				bindings = []docker.PortBinding{{}}
+			}
+
+			for _, binding := range bindings {
+				var spec string
+				switch {
+				case binding.HostIP != "":
+					spec = fmt.Sprintf("%s:%s:%s", binding.HostIP, binding.HostPort, port)
+				case binding.HostPort != "":
+					spec = fmt.Sprintf("%s:%s", binding.HostPort, port)
+				default:
+					spec = string(port)
+				}
+
+				args = append(args, "--publish", spec)
+			}
+		}
+
+		if hc.NetworkMode != "" && hc.NetworkMode != "default" {
+			args = append(args, "--network", hc.NetworkMode)
+		}
+	}
+
+	args = append(args, config.Image)
+	args = append(args, command...)
+	return args, nil
+}
+
+// CreateContainer creates a new container
+func (c *Client) CreateContainer(opts docker.CreateContainerOptions) (*docker.Container, error) {
+	args, err := createContainerArgs(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	if err := c.run(opts.Context, nil, &stdout, args...); err != nil {
+		if isNotFound(err) {
+			return nil, docker.ErrNoSuchImage
+		}
+
+		return nil, err
+	}
+
+	//the container ID is the last output line (the image pull output might be there too)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	container := &docker.Container{
+		ID:         strings.TrimSpace(lines[len(lines)-1]),
+		Name:       opts.Name,
+		Config:     opts.Config,
+		HostConfig: opts.HostConfig,
+	}
+
+	return container, nil
+}
+
+// StartContainer starts the container
+// (the host config is configured when the container is created)
+func (c *Client) StartContainer(id string, hostConfig *docker.HostConfig) error {
+	err := c.run(context.Background(), nil, io.Discard, "start", id)
+	if isNotFound(err) {
+		return &docker.NoSuchContainer{ID: id}
+	}
+
+	return err
+}
+
+// StopContainer stops the container
+func (c *Client) StopContainer(id string, timeout uint) error {
+	err := c.run(context.Background(), nil, io.Discard, "stop", "--time", strconv.FormatUint(uint64(timeout), 10), id)
+	if isNotFound(err) {
+		return &docker.NoSuchContainer{ID: id}
+	}
+
+	return err
+}
+
+// RemoveContainer removes the container
+func (c *Client) RemoveContainer(opts docker.RemoveContainerOptions) error {
+	args := []string{"rm"}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+
+	if opts.RemoveVolumes {
+		args = append(args, "--volumes")
+	}
+
+	args = append(args, opts.ID)
+	err := c.run(opts.Context, nil, io.Discard, args...)
+	if isNotFound(err) {
+		return &docker.NoSuchContainer{ID: opts.ID}
+	}
+
+	return err
+}
+
+// CreateExec creates a new exec instance
+// (nerdctl executes the commands in one step, so the exec instance is executed by StartExec)
+func (c *Client) CreateExec(opts docker.CreateExecOptions) (*docker.Exec, error) {
+	if opts.Container == "" || len(opts.Cmd) == 0 {
+		return nil, fmt.Errorf("no exec container or command")
+	}
+
+	idData := make([]byte, 16)
+	if _, err := rand.Read(idData); err != nil {
+		return nil, err
+	}
+
+	id := hex.EncodeToString(idData)
+
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+	c.execs[id] = &execInstance{options: opts}
+
+	return &docker.Exec{ID: id}, nil
+}
+
+// StartExec executes the exec instance command
+func (c *Client) StartExec(id string, opts docker.StartExecOptions) error {
+	c.execMu.Lock()
+	instance, found := c.execs[id]
+	if found {
+		instance.running = true
+	}
+	c.execMu.Unlock()
+
+	if !found {
+		return &docker.NoSuchExec{ID: id}
+	}
+
+	execOpts := instance.options
+	args := []string{"exec"}
+	if opts.Detach {
+		args = append(args, "--detach")
+	}
+
+	if opts.Tty || execOpts.Tty {
+		args = append(args, "--tty")
+	}
+
+	if opts.InputStream != nil && execOpts.AttachStdin {
+		args = append(args, "--interactive")
+	}
+
+	if execOpts.Privileged {
+		args = append(args, "--privileged")
+	}
+
+	if execOpts.User != "" {
+		args = append(args, "--user", execOpts.User)
+	}
+
+	if execOpts.WorkingDir != "" {
+		args = append(args, "--workdir", execOpts.WorkingDir)
+	}
+
+	for _, kv := range execOpts.Env {
+		args = append(args, "--env", kv)
+	}
+
+	args = append(args, execOpts.Container)
+	args = append(args, execOpts.Cmd...)
+
+	cmd := c.command(opts.Context, args)
+	cmd.Stdin = opts.InputStream
+	cmd.Stdout = opts.OutputStream
+	cmd.Stderr = opts.ErrorStream
+	if opts.Tty || opts.RawTerminal {
+		cmd.Stderr = opts.OutputStream
+	}
+
+	if opts.Success != nil {
+		opts.Success <- struct{}{}
+		<-opts.Success
+	}
+
+	err := cmd.Run()
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			c.execMu.Lock()
+			instance.running = false
+			c.execMu.Unlock()
+			return err
+		}
+
+		//the same as with Docker, the command exit code is not an error
+		exitCode = exitErr.ExitCode()
+	}
+
+	c.execMu.Lock()
+	instance.running = false
+	instance.exitCode = exitCode
+	c.execMu.Unlock()
+
+	return nil
+}
+
+// InspectExec returns the exec instance information
+func (c *Client) InspectExec(id string) (*docker.ExecInspect, error) {
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+
+	instance, found := c.execs[id]
+	if !found {
+		return nil, &docker.NoSuchExec{ID: id}
+	}
+
+	info := &docker.ExecInspect{
+		ID:          id,
+		ContainerID: instance.options.Container,
+		Running:     instance.running,
+		ExitCode:    instance.exitCode,
+		OpenStdin:   instance.options.AttachStdin,
+		OpenStdout:  instance.options.AttachStdout,
+		OpenStderr:  instance.options.AttachStderr,
+		ProcessConfig: docker.ExecProcessConfig{
+			User:       instance.options.User,
+			Privileged: instance.options.Privileged,
+			Tty:        instance.options.Tty,
+			EntryPoint: instance.options.Cmd[0],
+			Arguments:  instance.options.Cmd[1:],
+		},
+	}
+
+	return info, nil
+}
+
+// DownloadFromContainer copies the container file or directory (as a tar stream)
+func (c *Client) DownloadFromContainer(id string, opts docker.DownloadFromContainerOptions) error {
+	tmpDir, err := os.MkdirTemp("", "slim-crt-download-")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	srcPath := path.Clean(opts.Path)
+	include := path.Base(srcPath)
+	if include == "/" {
+		srcPath = "/."
+		include = "."
+	}
+
+	src := fmt.Sprintf("%s:%s", id, srcPath)
+	if err := c.run(opts.Context, nil, io.Discard, "cp", src, tmpDir); err != nil {
+		if isNotFound(err) {
+			return &docker.NoSuchContainer{ID: id}
+		}
+
+		return err
+	}
+
+	tarOptions := &archive.TarOptions{
+		Compression:  archive.Uncompressed,
+		IncludeFiles: []string{include},
+	}
+
+	tarData, err := archive.TarWithOptions(tmpDir, tarOptions)
+	if err != nil {
+		return err
+	}
+
+	defer tarData.Close()
+	_, err = io.Copy(opts.OutputStream, tarData)
+	return err
+}
+
+// UploadToContainer extracts the tar stream to the container directory
+func (c *Client) UploadToContainer(id string, opts docker.UploadToContainerOptions) error {
+	tmpDir, err := os.MkdirTemp("", "slim-crt-upload-")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	tarOptions := &archive.TarOptions{
+		NoLchown: true,
+	}
+
+	if err := archive.Untar(opts.InputStream, tmpDir, tarOptions); err != nil {
+		return err
+	}
+
+	dst := fmt.Sprintf("%s:%s", id, opts.Path)
+	err = c.run(opts.Context, nil, io.Discard, "cp", fmt.Sprintf("%s/.", tmpDir), dst)
+	if isNotFound(err) {
+		return &docker.NoSuchContainer{ID: id}
+	}
+
+	return err
+}
+
+type volumeRecord struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	CreatedAt  string
+	Labels     map[string]string
+}
+
+func (r *volumeRecord) volume() *docker.Volume {
+	volume := &docker.Volume{
+		Name:       r.Name,
+		Driver:     r.Driver,
+		Mountpoint: r.Mountpoint,
+		Labels:     r.Labels,
+		CreatedAt:  parseTime(r.CreatedAt),
+	}
+
+	if volume.Driver == "" {
+		volume.Driver = "local"
+	}
+
+	return volume
+}
+
+// CreateVolume creates a new (local) volume
+func (c *Client) CreateVolume(opts docker.CreateVolumeOptions) (*docker.Volume, error) {
+	if opts.Driver != "" && opts.Driver != "local" {
+		return nil, fmt.Errorf("%w - volume driver (%s)", crt.ErrNotSupported, opts.Driver)
+	}
+
+	args := []string{"volume", "create"}
+	for k, v := range opts.Labels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	args = append(args, opts.Name)
+	if err := c.run(opts.Context, nil, io.Discard, args...); err != nil {
+		return nil, err
+	}
+
+	return c.InspectVolume(opts.Name)
+}
+
+// InspectVolume returns the volume information
+func (c *Client) InspectVolume(name string) (*docker.Volume, error) {
+	data, err := c.output("volume", "inspect", name)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, docker.ErrNoSuchVolume
+		}
+
+		return nil, err
+	}
+
+	var records []volumeRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, docker.ErrNoSuchVolume
+	}
+
+	return records[0].volume(), nil
+}
+
+// ListVolumes returns the volumes
+// (only the 'name' filter is supported; it matches the volume names containing the filter value)
+func (c *Client) ListVolumes(opts docker.ListVolumesOptions) ([]docker.Volume, error) {
+	for name := range opts.Filters {
+		if name != "name" {
+			return nil, fmt.Errorf("%w - volume filter (%s)", crt.ErrNotSupported, name)
+		}
+	}
+
+	data, err := c.output("volume", "ls", "--format", "{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+
+	//the 'volume ls' labels are formatted as strings
+	type listRecord struct {
+		Name       string
+		Driver     string
+		Mountpoint string
+	}
+
+	records, err := jsonLines[listRecord](data)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []docker.Volume
+	for _, record := range records {
+		matched := true
+		for _, value := range opts.Filters["name"] {
+			if !strings.Contains(record.Name, value) {
+				matched = false
+				break
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		vr := volumeRecord{
+			Name:       record.Name,
+			Driver:     record.Driver,
+			Mountpoint: record.Mountpoint,
+		}
+
+		volumes = append(volumes, *vr.volume())
+	}
+
+	return volumes, nil
+}
+
+// RemoveVolumeWithOptions removes the volume
+func (c *Client) RemoveVolumeWithOptions(opts docker.RemoveVolumeOptions) error {
+	err := c.run(opts.Context, nil, io.Discard, "volume", "rm", opts.Name)
+	if err != nil {
+		var e *Error
+		switch {
+		case isNotFound(err):
+			return docker.ErrNoSuchVolume
+		case errors.As(err, &e) && strings.Contains(strings.ToLower(e.Message), "in use"):
+			return docker.ErrVolumeInUse
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,460 @@
+package containerd
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/crt"
+)
+
+// testNerdctlScript is a fake nerdctl that logs its args and returns the canned command outputs
+const testNerdctlScript = `#!/bin/sh
+if [ "$1" = "--address" ]; then
+  echo "address $2" >> "$NERDCTL_TEST_LOG"
+  shift 2
+fi
+echo "$@" >> "$NERDCTL_TEST_LOG"
+eval last=\${$#}
+case "$1 $2" in
+"info "*)
+  exit 0 ;;
+"image inspect")
+  if [ "$last" = "missing" ]; then
+    echo "no such image: missing" >&2
+    exit 1
+  fi
+  echo '[{"Id":"sha256:1234","RepoTags":["app:latest"],"Architecture":"arm64"}]' ;;
+"image history")
+  echo '{"CreatedAt":"2024-05-01T10:00:00Z","CreatedBy":"CMD [\"app\"]","Size":"0","Comment":""}'
+  echo '{"CreatedAt":"2024-05-01T09:00:00Z","CreatedBy":"COPY app /app","Size":"2048","Comment":"buildkit"}' ;;
+"images "*)
+  echo '{"ID":"1234","Repository":"app","Tag":"latest","Digest":"sha256:d1","CreatedAt":"2024-05-01 10:00:00 +0000 UTC","Size":"10MiB"}'
+  echo '{"ID":"1234","Repository":"app","Tag":"v1","Digest":"sha256:d1","CreatedAt":"2024-05-01 10:00:00 +0000 UTC","Size":"10MiB"}'
+  echo '{"ID":"5678","Repository":"<none>","Tag":"<none>","Digest":"","CreatedAt":"bad","Size":"1.5 kB"}' ;;
+"create "*)
+  echo "pulling app..."
+  echo "c1" ;;
+"exec "*)
+  echo "out"
+  echo "err" >&2
+  exit 3 ;;
+"cp "*)
+  case "$2" in
+  c1:*)
+    echo "data" > "$3/$(basename "${2#c1:}")" ;;
+  *)
+    echo "no such container" >&2
+    exit 1 ;;
+  esac ;;
+"volume inspect")
+  if [ "$last" = "missing" ]; then
+    echo "volume \"missing\" not found" >&2
+    exit 1
+  fi
+  echo '[{"Name":"'"$last"'","Mountpoint":"/var/lib/nerdctl/volumes/'"$last"'","Labels":{"app":"slim"}}]' ;;
+"volume ls")
+  echo '{"Name":"slim-sensor.1","Driver":"local","Mountpoint":"/v/1"}'
+  echo '{"Name":"other","Driver":"local","Mountpoint":"/v/2"}' ;;
+"volume rm")
+  if [ "$last" = "used" ]; then
+    echo "volume \"used\" is in use" >&2
+    exit 1
+  fi ;;
+esac
+exit 0
+`
+
+func testClient(t *testing.T, address string) (*Client, func() []string) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "nerdctl")
+	require.NoError(t, os.WriteFile(binary, []byte(testNerdctlScript), 0755))
+
+	logPath := filepath.Join(dir, "commands.log")
+	t.Setenv("NERDCTL_TEST_LOG", logPath)
+
+	client := &Client{
+		Binary:  binary,
+		Address: address,
+		execs:   map[string]*execInstance{},
+	}
+
+	commands := func() []string {
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	return client, commands
+}
+
+func TestJSONLines(t *testing.T) {
+	type record struct {
+		Name string
+	}
+
+	records, err := jsonLines[record]([]byte("{\"Name\":\"a\"}\n\n  {\"Name\":\"b\"}  \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []record{{Name: "a"}, {Name: "b"}}, records)
+
+	records, err = jsonLines[record](nil)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = jsonLines[record]([]byte("{\"Name\":\"a\"}\nnot json\n"))
+	assert.Error(t, err)
+}
+
+func TestParseTime(t *testing.T) {
+	tt := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: "2024-05-01T10:00:00Z", expected: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "2024-05-01T10:00:00.5+02:00", expected: time.Date(2024, 5, 1, 8, 0, 0, 500000000, time.UTC)},
+		{value: "2024-05-01 10:00:00 +0000 UTC", expected: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "5 days ago"},
+		{value: ""},
+	}
+
+	for _, test := range tt {
+		assert.True(t, test.expected.Equal(parseTime(test.value)), test.value)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tt := []struct {
+		value    string
+		expected int64
+	}{
+		{value: "2048", expected: 2048},
+		{value: "10MiB", expected: 10 * 1024 * 1024},
+		{value: "1.5 KiB", expected: 1536},
+		{value: "1.5 kB", expected: 1500},
+		{value: "20MB", expected: 20000000},
+		{value: "unknown"},
+		{value: ""},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, parseSize(test.value), test.value)
+	}
+}
+
+func TestDigestID(t *testing.T) {
+	assert.Equal(t, "sha256:1234", digestID("1234"))
+	assert.Equal(t, "sha256:1234", digestID("sha256:1234"))
+	assert.Equal(t, "", digestID(""))
+}
+
+func TestIsNotFound(t *testing.T) {
+	tt := []struct {
+		err      error
+		expected bool
+	}{
+		{err: &Error{Message: "no such image: app"}, expected: true},
+		{err: &Error{Message: "Volume \"data\" Not Found"}, expected: true},
+		{err: &Error{Message: "permission denied"}},
+		{err: io.EOF},
+		{err: nil},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, isNotFound(test.err), "%v", test.err)
+	}
+}
+
+func TestCreateContainerArgs(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     docker.CreateContainerOptions
+		expected []string
+		isErr    bool
+	}{
+		{
+			name:  "no config",
+			opts:  docker.CreateContainerOptions{Name: "app"},
+			isErr: true,
+		},
+		{
+			name: "config only",
+			opts: docker.CreateContainerOptions{
+				Name: "app",
+				Config: &docker.Config{
+					Image:      "app:latest",
+					Entrypoint: []string{"/app/run", "--verbose"},
+					Cmd:        []string{"--port", "8080"},
+					Env:        []string{"MODE=prod"},
+					User:       "app",
+					WorkingDir: "/app",
+					Hostname:   "apphost",
+					Labels:     map[string]string{"owner": "team"},
+					Tty:        true,
+					OpenStdin:  true,
+				},
+			},
+			expected: []string{
+				"create", "--name", "app", "--env", "MODE=prod",
+				"--entrypoint", "/app/run",
+				"--user", "app", "--workdir", "/app", "--hostname", "apphost",
+				"--label", "owner=team", "--tty", "--interactive",
+				"app:latest", "--verbose", "--port", "8080",
+			},
+		},
+		{
+			name: "host config",
+			opts: docker.CreateContainerOptions{
+				Config: &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{
+					AutoRemove: true,
+					Privileged: true,
+					CapAdd:     []string{"SYS_ADMIN"},
+					CapDrop:    []string{"NET_RAW"},
+					Binds:      []string{"sensor-vol:/opt/_slim/bin:ro"},
+					Mounts: []docker.HostMount{
+						{Source: "/host/data", Target: "/data", ReadOnly: true},
+						{Type: "tmpfs", Target: "/run"},
+					},
+					PortBindings: map[docker.Port][]docker.PortBinding{
+						"8080/tcp": {{HostIP: "127.0.0.1", HostPort: "18080"}, {HostPort: "28080"}},
+					},
+					NetworkMode: "app-net",
+				},
+			},
+			expected: []string{
+				"create", "--rm", "--privileged", "--cap-add", "SYS_ADMIN", "--cap-drop", "NET_RAW",
+				"--volume", "sensor-vol:/opt/_slim/bin:ro",
+				"--mount", "type=bind,target=/data,source=/host/data,readonly",
+				"--mount", "type=tmpfs,target=/run",
+				"--publish", "127.0.0.1:18080:8080/tcp", "--publish", "28080:8080/tcp",
+				"--network", "app-net",
+				"app",
+			},
+		},
+		{
+			name: "dynamic host port and default network",
+			opts: docker.CreateContainerOptions{
+				Config: &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{
+					PortBindings: map[docker.Port][]docker.PortBinding{"65501/tcp": nil},
+					NetworkMode:  "default",
+				},
+			},
+			expected: []string{"create", "--publish", "65501/tcp", "app"},
+		},
+		{
+			name: "publish all ports",
+			opts: docker.CreateContainerOptions{
+				Config:     &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{PublishAllPorts: true},
+			},
+			isErr: true,
+		},
+	}
+
+	for _, test := range tt {
+		args, err := createContainerArgs(test.opts)
+		if test.isErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, args, test.name)
+	}
+
+	_, err := createContainerArgs(docker.CreateContainerOptions{
+		Config:     &docker.Config{Image: "app"},
+		HostConfig: &docker.HostConfig{PublishAllPorts: true},
+	})
+	assert.ErrorIs(t, err, crt.ErrNotSupported)
+}
+
+func TestClientImages(t *testing.T) {
+	client, commands := testClient(t, "unix:///run/containerd/containerd.sock")
+
+	require.NoError(t, client.Ping())
+
+	image, err := client.InspectImage("app:latest")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:1234", image.ID)
+	assert.Equal(t, "arm64", image.Architecture)
+
+	_, err = client.InspectImage("missing")
+	assert.Equal(t, docker.ErrNoSuchImage, err)
+
+	images, err := client.ListImages(docker.ListImagesOptions{
+		All:     true,
+		Filters: map[string][]string{"reference": {"app"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Equal(t, "sha256:1234", images[0].ID)
+	assert.Equal(t, []string{"app:latest", "app:v1"}, images[0].RepoTags)
+	assert.Equal(t, []string{"app@sha256:d1"}, images[0].RepoDigests)
+	assert.Equal(t, int64(10*1024*1024), images[0].Size)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC).Unix(), images[0].Created)
+	assert.Equal(t, "sha256:5678", images[1].ID)
+	assert.Empty(t, images[1].RepoTags)
+	assert.Equal(t, int64(1500), images[1].Size)
+
+	history, err := client.ImageHistory("app:latest")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "sha256:1234", history[0].ID)
+	assert.Equal(t, []string{"app:latest"}, history[0].Tags)
+	assert.Equal(t, missingImageID, history[1].ID)
+	assert.Equal(t, "COPY app /app", history[1].CreatedBy)
+	assert.Equal(t, int64(2048), history[1].Size)
+
+	_, err = client.ImageHistory("missing")
+	assert.Equal(t, docker.ErrNoSuchImage, err)
+
+	err = client.PullImage(docker.PullImageOptions{Repository: "app", Tag: "sha256:abcd", Platform: "linux/arm64"}, docker.AuthConfiguration{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"address unix:///run/containerd/containerd.sock",
+		"info",
+		"address unix:///run/containerd/containerd.sock",
+		"image inspect --mode=dockercompat app:latest",
+		"address unix:///run/containerd/containerd.sock",
+		"image inspect --mode=dockercompat missing",
+		"address unix:///run/containerd/containerd.sock",
+		"images --no-trunc --format {{json .}} --all --filter reference=app",
+		"address unix:///run/containerd/containerd.sock",
+		"image inspect --mode=dockercompat app:latest",
+		"address unix:///run/containerd/containerd.sock",
+		"image history --no-trunc --human=false --format {{json .}} app:latest",
+		"address unix:///run/containerd/containerd.sock",
+		"image inspect --mode=dockercompat missing",
+		"address unix:///run/containerd/containerd.sock",
+		"pull --platform linux/arm64 app@sha256:abcd",
+	}, commands())
+}
+
+func TestClientContainers(t *testing.T) {
+	client, commands := testClient(t, "")
+
+	config := &docker.Config{Image: "app", Cmd: []string{"run"}}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{Name: "app", Config: config})
+	require.NoError(t, err)
+	assert.Equal(t, "c1", container.ID)
+	assert.Equal(t, config, container.Config)
+
+	require.NoError(t, client.StartContainer("c1", nil))
+	require.NoError(t, client.StopContainer("c1", 9))
+	require.NoError(t, client.RemoveContainer(docker.RemoveContainerOptions{ID: "c1", Force: true, RemoveVolumes: true}))
+
+	exec, err := client.CreateExec(docker.CreateExecOptions{
+		Container: "c1",
+		Cmd:       []string{"ls", "/"},
+		User:      "root",
+		Env:       []string{"A=1"},
+	})
+	require.NoError(t, err)
+
+	_, err = client.CreateExec(docker.CreateExecOptions{Container: "c1"})
+	assert.Error(t, err)
+
+	var stdout, stderr bytes.Buffer
+	err = client.StartExec(exec.ID, docker.StartExecOptions{OutputStream: &stdout, ErrorStream: &stderr})
+	require.NoError(t, err)
+	assert.Equal(t, "out\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+
+	info, err := client.InspectExec(exec.ID)
+	require.NoError(t, err)
+	assert.False(t, info.Running)
+	assert.Equal(t, 3, info.ExitCode)
+	assert.Equal(t, "c1", info.ContainerID)
+	assert.Equal(t, "ls", info.ProcessConfig.EntryPoint)
+	assert.Equal(t, []string{"/"}, info.ProcessConfig.Arguments)
+
+	assert.IsType(t, &docker.NoSuchExec{}, client.StartExec("other", docker.StartExecOptions{}))
+	_, err = client.InspectExec("other")
+	assert.IsType(t, &docker.NoSuchExec{}, err)
+
+	assert.Equal(t, []string{
+		"create --name app app run",
+		"start c1",
+		"stop --time 9 c1",
+		"rm --force --volumes c1",
+		"exec --user root --env A=1 c1 ls /",
+	}, commands())
+}
+
+func TestClientDownloadFromContainer(t *testing.T) {
+	client, _ := testClient(t, "")
+
+	var output bytes.Buffer
+	err := client.DownloadFromContainer("c1", docker.DownloadFromContainerOptions{
+		Path:         "/opt/_slim/artifacts/creport.json",
+		OutputStream: &output,
+	})
+	require.NoError(t, err)
+
+	tr := tar.NewReader(&output)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "creport.json", hdr.Name)
+	data, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Equal(t, "data\n", string(data))
+
+	err = client.DownloadFromContainer("c2", docker.DownloadFromContainerOptions{
+		Path:         "/opt/_slim/artifacts/creport.json",
+		OutputStream: &output,
+	})
+	assert.IsType(t, &docker.NoSuchContainer{}, err)
+}
+
+func TestClientVolumes(t *testing.T) {
+	client, commands := testClient(t, "")
+
+	volume, err := client.CreateVolume(docker.CreateVolumeOptions{
+		Name:   "sensor",
+		Labels: map[string]string{"app": "slim"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sensor", volume.Name)
+	assert.Equal(t, "local", volume.Driver)
+	assert.Equal(t, "/var/lib/nerdctl/volumes/sensor", volume.Mountpoint)
+	assert.Equal(t, map[string]string{"app": "slim"}, volume.Labels)
+
+	_, err = client.CreateVolume(docker.CreateVolumeOptions{Name: "data", Driver: "nfs"})
+	assert.ErrorIs(t, err, crt.ErrNotSupported)
+
+	_, err = client.InspectVolume("missing")
+	assert.Equal(t, docker.ErrNoSuchVolume, err)
+
+	volumes, err := client.ListVolumes(docker.ListVolumesOptions{Filters: map[string][]string{"name": {"slim-sensor"}}})
+	require.NoError(t, err)
+	require.Len(t, volumes, 1)
+	assert.Equal(t, "slim-sensor.1", volumes[0].Name)
+	assert.Equal(t, "/v/1", volumes[0].Mountpoint)
+
+	_, err = client.ListVolumes(docker.ListVolumesOptions{Filters: map[string][]string{"label": {"app"}}})
+	assert.ErrorIs(t, err, crt.ErrNotSupported)
+
+	assert.NoError(t, client.RemoveVolumeWithOptions(docker.RemoveVolumeOptions{Name: "sensor"}))
+	assert.Equal(t, docker.ErrVolumeInUse, client.RemoveVolumeWithOptions(docker.RemoveVolumeOptions{Name: "used"}))
+
+	assert.Equal(t, []string{
+		"volume create --label app=slim sensor",
+		"volume inspect sensor",
+		"volume inspect missing",
+		"volume ls --format {{json .}}",
+		"volume rm sensor",
+		"volume rm used",
+	}, commands())
+}
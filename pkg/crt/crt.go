@@ -0,0 +1,86 @@
+// Package crt provides the container runtime (CRT) abstraction used to work
+// with the Docker, Podman and containerd runtime engines.
+package crt
+
+import (
+	"errors"
+	"fmt"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// Container runtime engine names
+const (
+	DockerEngine     = "docker"
+	PodmanEngine     = "podman"
+	ContainerdEngine = "containerd"
+)
+
+// DefaultEngine is the container runtime engine used when no engine is selected
+const DefaultEngine = DockerEngine
+
+var (
+	ErrBadEngine    = errors.New("bad container runtime engine")
+	ErrNotSupported = errors.New("operation not supported by the container runtime engine")
+)
+
+// APIClient is the container runtime API used for the image, container and volume operations.
+// The API data types are the Docker Engine API types (the Docker API client implements
+// the interface as is and the other runtime engines map their data to these types).
+// The commands that run containers (build, profile) need the Docker API for the container
+// lifecycle (events, logs, networks and port bindings) and they use this API
+// for the sensor volume and the container data operations.
+type APIClient interface {
+	Ping() error
+
+	//Images
+	InspectImage(name string) (*docker.Image, error)
+	ListImages(opts docker.ListImagesOptions) ([]docker.APIImages, error)
+	ImageHistory(name string) ([]docker.ImageHistory, error)
+	PullImage(opts docker.PullImageOptions, auth docker.AuthConfiguration) error
+	ExportImage(opts docker.ExportImageOptions) error
+	LoadImage(opts docker.LoadImageOptions) error
+
+	//Containers
+	CreateContainer(opts docker.CreateContainerOptions) (*docker.Container, error)
+	StartContainer(id string, hostConfig *docker.HostConfig) error
+	StopContainer(id string, timeout uint) error
+	RemoveContainer(opts docker.RemoveContainerOptions) error
+	CreateExec(opts docker.CreateExecOptions) (*docker.Exec, error)
+	StartExec(id string, opts docker.StartExecOptions) error
+	InspectExec(id string) (*docker.ExecInspect, error)
+	DownloadFromContainer(id string, opts docker.DownloadFromContainerOptions) error
+	UploadToContainer(id string, opts docker.UploadToContainerOptions) error
+
+	//Volumes
+	CreateVolume(opts docker.CreateVolumeOptions) (*docker.Volume, error)
+	InspectVolume(name string) (*docker.Volume, error)
+	ListVolumes(opts docker.ListVolumesOptions) ([]docker.Volume, error)
+	RemoveVolumeWithOptions(opts docker.RemoveVolumeOptions) error
+}
+
+var _ APIClient = (*docker.Client)(nil)
+
+// ValidateEngine checks the container runtime engine name
+// (an empty name selects the default engine)
+func ValidateEngine(name string) (string, error) {
+	switch name {
+	case "":
+		return DefaultEngine, nil
+	case DockerEngine, PodmanEngine, ContainerdEngine:
+		return name, nil
+	default:
+		return "", fmt.Errorf("%w - %s", ErrBadEngine, name)
+	}
+}
+
+// HasDockerAPI returns true if the container runtime engine provides
+// the Docker Engine API (needed for the commands that run containers)
+func HasDockerAPI(name string) bool {
+	switch name {
+	case "", DockerEngine, PodmanEngine:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,50 @@
+package crt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEngine(t *testing.T) {
+	tt := []struct {
+		name     string
+		expected string
+		isErr    bool
+	}{
+		{name: "", expected: DockerEngine},
+		{name: "docker", expected: DockerEngine},
+		{name: "podman", expected: PodmanEngine},
+		{name: "containerd", expected: ContainerdEngine},
+		{name: "Docker", isErr: true},
+		{name: "cri-o", isErr: true},
+	}
+
+	for _, test := range tt {
+		engine, err := ValidateEngine(test.name)
+		if test.isErr {
+			assert.ErrorIs(t, err, ErrBadEngine, test.name)
+			continue
+		}
+
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, engine)
+	}
+}
+
+func TestHasDockerAPI(t *testing.T) {
+	tt := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "", expected: true},
+		{name: DockerEngine, expected: true},
+		{name: PodmanEngine, expected: true},
+		{name: ContainerdEngine},
+		{name: "other"},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, HasDockerAPI(test.name), test.name)
+	}
+}
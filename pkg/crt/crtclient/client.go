@@ -0,0 +1,72 @@
+// Package crtclient creates the container runtime API clients for the selected runtime engines.
+package crtclient
+
+import (
+	dockerapi "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/crt"
+	"github.com/slimtoolkit/slim/pkg/crt/containerd"
+	"github.com/slimtoolkit/slim/pkg/crt/podman"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
+)
+
+// New creates a new container runtime API client for the configured runtime engine
+// (the Host config value is used as the runtime engine address)
+func New(config *config.DockerClient) (crt.APIClient, error) {
+	engine, err := crt.ValidateEngine(config.Engine)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("crtclient.New: engine=%s host=%s", engine, config.Host)
+
+	var client crt.APIClient
+	switch engine {
+	case crt.PodmanEngine:
+		client, err = podman.New(config.Host)
+	case crt.ContainerdEngine:
+		client, err = containerd.New(config.Host)
+	default:
+		//the Docker client connection is checked by the first API call
+		dclient, err := dockerclient.New(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return dclient, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.Ping(); err != nil {
+		log.Debugf("crtclient.New: %s ping error - %v", engine, err)
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// NewWithDockerClient creates a new container runtime API client for the configured runtime engine
+// reusing the Docker API client when the Docker engine is selected
+// (used by the commands that run containers with the Docker API and
+// use the container runtime API for the volume and container data operations)
+func NewWithDockerClient(config *config.DockerClient, dclient *dockerapi.Client) (crt.APIClient, error) {
+	engine, err := crt.ValidateEngine(config.Engine)
+	if err != nil {
+		return nil, err
+	}
+
+	if engine == crt.DockerEngine {
+		if dclient == nil {
+			return nil, dockerclient.ErrNoDockerInfo
+		}
+
+		return dclient, nil
+	}
+
+	return New(config)
+}
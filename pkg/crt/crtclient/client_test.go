@@ -0,0 +1,50 @@
+package crtclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/crt"
+	"github.com/slimtoolkit/slim/pkg/crt/podman"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
+)
+
+func TestNewWithDockerClient(t *testing.T) {
+	dclient, err := dockerapi.NewClient("tcp://127.0.0.1:2375")
+	require.NoError(t, err)
+
+	for _, engine := range []string{"", crt.DockerEngine} {
+		client, err := NewWithDockerClient(&config.DockerClient{Engine: engine}, dclient)
+		require.NoError(t, err, engine)
+		assert.Same(t, dclient, client, engine)
+	}
+
+	_, err = NewWithDockerClient(&config.DockerClient{}, nil)
+	assert.ErrorIs(t, err, dockerclient.ErrNoDockerInfo)
+
+	_, err = NewWithDockerClient(&config.DockerClient{Engine: "other"}, dclient)
+	assert.ErrorIs(t, err, crt.ErrBadEngine)
+
+	//the native Podman API client is used with the Podman engine
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/"+podman.APIVersion+"/libpod/_ping", r.URL.Path)
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	podmanConfig := &config.DockerClient{
+		Engine: crt.PodmanEngine,
+		Host:   strings.Replace(server.URL, "http://", "tcp://", 1),
+	}
+
+	client, err := NewWithDockerClient(podmanConfig, dclient)
+	require.NoError(t, err)
+	assert.IsType(t, &podman.Client{}, client)
+}
@@ -0,0 +1,893 @@
+// Package podman implements the container runtime API client for Podman
+// (using the native Podman/libpod REST API exposed by the Podman API service socket).
+package podman
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	docker "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/crt"
+)
+
+const (
+	APIVersion = "v4.0.0"
+
+	contentTypeJSON = "application/json"
+	contentTypeTar  = "application/x-tar"
+	headerAuth      = "X-Registry-Auth"
+)
+
+// Error is the Podman API error response
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("podman API error (status %d): %s", e.Status, e.Message)
+}
+
+func hasStatus(err error, status int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Status == status
+}
+
+// Client is the Podman (libpod) API client
+type Client struct {
+	Address    string
+	baseURL    string
+	httpClient *http.Client
+}
+
+var _ crt.APIClient = (*Client)(nil)
+
+// New creates a new Podman API client for the Podman API service address
+// (unix:///path/to/podman.sock or tcp://host:port; the local socket is used if the address is empty)
+func New(address string) (*Client, error) {
+	if address == "" {
+		var err error
+		address, err = SocketAddr()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	addrURL, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		Address: address,
+	}
+
+	switch addrURL.Scheme {
+	case "unix":
+		socketPath := addrURL.Path
+		client.baseURL = "http://d"
+		client.httpClient = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		}
+	case "tcp", "http":
+		client.baseURL = fmt.Sprintf("http://%s", addrURL.Host)
+		client.httpClient = &http.Client{}
+	default:
+		return nil, fmt.Errorf("unsupported podman API service address - %s", address)
+	}
+
+	log.Debugf("podman.New: address=%s", address)
+	return client, nil
+}
+
+type requestOptions struct {
+	ctx         context.Context
+	query       url.Values
+	body        io.Reader
+	contentType string
+	header      http.Header
+}
+
+func (c *Client) do(method, path string, opts requestOptions) (*http.Response, error) {
+	ctx := opts.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	reqURL := fmt.Sprintf("%s/%s/libpod%s", c.baseURL, APIVersion, path)
+	if len(opts.query) > 0 {
+		reqURL = fmt.Sprintf("%s?%s", reqURL, opts.query.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, opts.body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range opts.header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	if opts.contentType != "" {
+		req.Header.Set("Content-Type", opts.contentType)
+	}
+
+	log.Tracef("podman.Client.do: %s %s", method, reqURL)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+
+		var apiError struct {
+			Cause   string `json:"cause"`
+			Message string `json:"message"`
+		}
+
+		apiErr := &Error{
+			Status:  resp.StatusCode,
+			Message: strings.TrimSpace(string(data)),
+		}
+
+		if err := json.Unmarshal(data, &apiError); err == nil && apiError.Message != "" {
+			apiErr.Message = apiError.Message
+		}
+
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
+
+func (c *Client) doJSON(method, path string, query url.Values, in, out interface{}) (int, error) {
+	opts := requestOptions{
+		query: query,
+	}
+
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+
+		opts.body = bytes.NewReader(data)
+		opts.contentType = contentTypeJSON
+	}
+
+	resp, err := c.do(method, path, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, err
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (c *Client) stream(method, path string, opts requestOptions, output io.Writer) error {
+	resp, err := c.do(method, path, opts)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	if output == nil {
+		output = io.Discard
+	}
+
+	_, err = io.Copy(output, resp.Body)
+	return err
+}
+
+func filtersQuery(query url.Values, filters map[string][]string) error {
+	if len(filters) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return err
+	}
+
+	query.Set("filters", string(data))
+	return nil
+}
+
+// Ping checks the Podman API service connection
+func (c *Client) Ping() error {
+	return c.stream(http.MethodGet, "/_ping", requestOptions{}, nil)
+}
+
+// InspectImage returns the image information
+func (c *Client) InspectImage(name string) (*docker.Image, error) {
+	var image docker.Image
+	if _, err := c.doJSON(http.MethodGet, "/images/"+name+"/json", nil, nil, &image); err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return nil, docker.ErrNoSuchImage
+		}
+
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// ListImages returns the local images
+func (c *Client) ListImages(opts docker.ListImagesOptions) ([]docker.APIImages, error) {
+	query := url.Values{}
+	query.Set("all", strconv.FormatBool(opts.All))
+	if err := filtersQuery(query, opts.Filters); err != nil {
+		return nil, err
+	}
+
+	var images []docker.APIImages
+	if _, err := c.doJSON(http.MethodGet, "/images/json", query, nil, &images); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// ImageHistory returns the image history records
+func (c *Client) ImageHistory(name string) ([]docker.ImageHistory, error) {
+	var history []docker.ImageHistory
+	if _, err := c.doJSON(http.MethodGet, "/images/"+name+"/history", nil, nil, &history); err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return nil, docker.ErrNoSuchImage
+		}
+
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// PullImage pulls the image from its registry
+func (c *Client) PullImage(opts docker.PullImageOptions, auth docker.AuthConfiguration) error {
+	if opts.Repository == "" {
+		return docker.ErrNoSuchImage
+	}
+
+	reference := opts.Repository
+	switch {
+	case strings.HasPrefix(opts.Tag, "sha256:"):
+		reference = fmt.Sprintf("%s@%s", reference, opts.Tag)
+	case opts.Tag != "":
+		reference = fmt.Sprintf("%s:%s", reference, opts.Tag)
+	}
+
+	query := url.Values{}
+	query.Set("reference", reference)
+	if opts.All {
+		query.Set("allTags", "true")
+	}
+
+	if opts.Platform != "" {
+		parts := strings.SplitN(opts.Platform, "/", 3)
+		query.Set("OS", parts[0])
+		if len(parts) > 1 {
+			query.Set("Arch", parts[1])
+		}
+
+		if len(parts) > 2 {
+			query.Set("Variant", parts[2])
+		}
+	}
+
+	reqOpts := requestOptions{
+		ctx:    opts.Context,
+		query:  query,
+		header: http.Header{},
+	}
+
+	if auth != (docker.AuthConfiguration{}) {
+		data, err := json.Marshal(auth)
+		if err != nil {
+			return err
+		}
+
+		reqOpts.header.Set(headerAuth, base64.URLEncoding.EncodeToString(data))
+	}
+
+	resp, err := c.do(http.MethodPost, "/images/pull", reqOpts)
+	if err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return docker.ErrNoSuchImage
+		}
+
+		return err
+	}
+
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var report struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+			ID     string `json:"id"`
+		}
+
+		if err := decoder.Decode(&report); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		if report.Error != "" {
+			return fmt.Errorf("image pull error - %s", report.Error)
+		}
+
+		if opts.OutputStream != nil && report.Stream != "" {
+			if _, err := io.WriteString(opts.OutputStream, report.Stream); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ExportImage saves the image as a 'docker save' compatible tar
+func (c *Client) ExportImage(opts docker.ExportImageOptions) error {
+	query := url.Values{}
+	query.Set("format", "docker-archive")
+
+	reqOpts := requestOptions{
+		ctx:   opts.Context,
+		query: query,
+	}
+
+	err := c.stream(http.MethodGet, "/images/"+opts.Name+"/get", reqOpts, opts.OutputStream)
+	if hasStatus(err, http.StatusNotFound) {
+		return docker.ErrNoSuchImage
+	}
+
+	return err
+}
+
+// LoadImage loads the images from the image tar (docker-archive or oci-archive)
+func (c *Client) LoadImage(opts docker.LoadImageOptions) error {
+	reqOpts := requestOptions{
+		ctx:         opts.Context,
+		body:        opts.InputStream,
+		contentType: contentTypeTar,
+	}
+
+	resp, err := c.do(http.MethodPost, "/images/load", reqOpts)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	var report struct {
+		Names []string `json:"Names"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return err
+	}
+
+	if opts.OutputStream != nil {
+		for _, name := range report.Names {
+			if _, err := fmt.Fprintf(opts.OutputStream, "Loaded image: %s\n", name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type specMount struct {
+	Destination string   `json:"destination"`
+	Type        string   `json:"type"`
+	Source      string   `json:"source,omitempty"`
+	Options     []string `json:"options,omitempty"`
+}
+
+type specNamedVolume struct {
+	Name    string   `json:"Name"`
+	Dest    string   `json:"Dest"`
+	Options []string `json:"Options,omitempty"`
+}
+
+type specNamespace struct {
+	NSMode string `json:"nsmode"`
+	Value  string `json:"value,omitempty"`
+}
+
+type specPortMapping struct {
+	HostIP        string `json:"host_ip,omitempty"`
+	ContainerPort uint16 `json:"container_port"`
+	HostPort      uint16 `json:"host_port,omitempty"`
+	Protocol      string `json:"protocol,omitempty"`
+}
+
+// specGenerator is the (subset of the) libpod container spec
+type specGenerator struct {
+	Name              string                     `json:"name,omitempty"`
+	Image             string                     `json:"image"`
+	Entrypoint        []string                   `json:"entrypoint,omitempty"`
+	Command           []string                   `json:"command,omitempty"`
+	Env               map[string]string          `json:"env,omitempty"`
+	User              string                     `json:"user,omitempty"`
+	WorkDir           string                     `json:"work_dir,omitempty"`
+	Hostname          string                     `json:"hostname,omitempty"`
+	Labels            map[string]string          `json:"labels,omitempty"`
+	Terminal          bool                       `json:"terminal,omitempty"`
+	Stdin             bool                       `json:"stdin,omitempty"`
+	Remove            bool                       `json:"remove,omitempty"`
+	Privileged        bool                       `json:"privileged,omitempty"`
+	CapAdd            []string                   `json:"cap_add,omitempty"`
+	CapDrop           []string                   `json:"cap_drop,omitempty"`
+	Mounts            []specMount                `json:"mounts,omitempty"`
+	Volumes           []specNamedVolume          `json:"volumes,omitempty"`
+	NetNS             *specNamespace             `json:"netns,omitempty"`
+	Networks          map[string]json.RawMessage `json:"Networks,omitempty"`
+	PortMappings      []specPortMapping          `json:"portmappings,omitempty"`
+	Expose            map[uint16]string          `json:"expose,omitempty"`
+	PublishImagePorts bool                       `json:"publish_image_ports,omitempty"`
+}
+
+func newSpecGenerator(opts docker.CreateContainerOptions) (*specGenerator, error) {
+	if opts.Config == nil {
+		return nil, fmt.Errorf("no container config")
+	}
+
+	spec := &specGenerator{
+		Name:       opts.Name,
+		Image:      opts.Config.Image,
+		Entrypoint: opts.Config.Entrypoint,
+		Command:    opts.Config.Cmd,
+		User:       opts.Config.User,
+		WorkDir:    opts.Config.WorkingDir,
+		Hostname:   opts.Config.Hostname,
+		Labels:     opts.Config.Labels,
+		Terminal:   opts.Config.Tty,
+		Stdin:      opts.Config.OpenStdin,
+	}
+
+	if len(opts.Config.Env) > 0 {
+		spec.Env = map[string]string{}
+		for _, kv := range opts.Config.Env {
+			k, v, _ := strings.Cut(kv, "=")
+			spec.Env[k] = v
+		}
+	}
+
+	for port := range opts.Config.ExposedPorts {
+		pnum, err := strconv.ParseUint(port.Port(), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("bad exposed port - %s", port)
+		}
+
+		if spec.Expose == nil {
+			spec.Expose = map[uint16]string{}
+		}
+
+		spec.Expose[uint16(pnum)] = port.Proto()
+	}
+
+	hc := opts.HostConfig
+	if hc == nil {
+		return spec, nil
+	}
+
+	spec.Remove = hc.AutoRemove
+	spec.Privileged = hc.Privileged
+	spec.CapAdd = hc.CapAdd
+	spec.CapDrop = hc.CapDrop
+	spec.PublishImagePorts = hc.PublishAllPorts
+
+	for _, bind := range hc.Binds {
+		parts := strings.SplitN(bind, ":", 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("bad bind mount - %s", bind)
+		}
+
+		var options []string
+		if len(parts) == 3 {
+			options = strings.Split(parts[2], ",")
+		}
+
+		if strings.HasPrefix(parts[0], "/") {
+			spec.Mounts = append(spec.Mounts, specMount{
+				Destination: parts[1],
+				Type:        "bind",
+				Source:      parts[0],
+				Options:     options,
+			})
+		} else {
+			spec.Volumes = append(spec.Volumes, specNamedVolume{
+				Name:    parts[0],
+				Dest:    parts[1],
+				Options: options,
+			})
+		}
+	}
+
+	for _, mount := range hc.Mounts {
+		var options []string
+		if mount.ReadOnly {
+			options = append(options, "ro")
+		}
+
+		switch mount.Type {
+		case "volume":
+			spec.Volumes = append(spec.Volumes, specNamedVolume{
+				Name:    mount.Source,
+				Dest:    mount.Target,
+				Options: options,
+			})
+		case "", "bind", "tmpfs":
+			mountType := mount.Type
+			if mountType == "" {
+				mountType = "bind"
+			}
+
+			spec.Mounts = append(spec.Mounts, specMount{
+				Destination: mount.Target,
+				Type:        mountType,
+				Source:      mount.Source,
+				Options:     options,
+			})
+		default:
+			return nil, fmt.Errorf("%w - mount type (%s)", crt.ErrNotSupported, mount.Type)
+		}
+	}
+
+	for port, bindings := range hc.PortBindings {
+		pnum, err := strconv.ParseUint(port.Port(), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("bad port binding port - %s", port)
+		}
+
+		if len(bindings) == 0 {
+			bindings = []docker.PortBinding{{}}
+		}
+
+		for _, binding := range bindings {
+			mapping := specPortMapping{
+				HostIP:        binding.HostIP,
+				ContainerPort: uint16(pnum),
+				Protocol:      port.Proto(),
+			}
+
+			if binding.HostPort != "" {
+				hostPort, err := strconv.ParseUint(binding.HostPort, 10, 16)
+				if err != nil {
+					return nil, fmt.Errorf("bad port binding host port - %s", binding.HostPort)
+				}
+
+				mapping.HostPort = uint16(hostPort)
+			}
+
+			spec.PortMappings = append(spec.PortMappings, mapping)
+		}
+	}
+
+	switch mode := hc.NetworkMode; {
+	case mode == "", mode == "default", mode == "bridge":
+	case mode == "host", mode == "none":
+		spec.NetNS = &specNamespace{NSMode: mode}
+	case strings.HasPrefix(mode, "container:"):
+		spec.NetNS = &specNamespace{
+			NSMode: "container",
+			Value:  strings.TrimPrefix(mode, "container:"),
+		}
+	default:
+		spec.NetNS = &specNamespace{NSMode: "bridge"}
+		spec.Networks = map[string]json.RawMessage{mode: json.RawMessage("{}")}
+	}
+
+	return spec, nil
+}
+
+// CreateContainer creates a new container
+func (c *Client) CreateContainer(opts docker.CreateContainerOptions) (*docker.Container, error) {
+	spec, err := newSpecGenerator(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var created struct {
+		ID       string   `json:"Id"`
+		Warnings []string `json:"Warnings"`
+	}
+
+	if _, err := c.doJSON(http.MethodPost, "/containers/create", nil, spec, &created); err != nil {
+		switch {
+		case hasStatus(err, http.StatusNotFound):
+			return nil, docker.ErrNoSuchImage
+		case hasStatus(err, http.StatusConflict):
+			return nil, docker.ErrContainerAlreadyExists
+		}
+
+		return nil, err
+	}
+
+	for _, warning := range created.Warnings {
+		log.Debugf("podman.Client.CreateContainer: warning - %s", warning)
+	}
+
+	container := &docker.Container{
+		ID:         created.ID,
+		Name:       opts.Name,
+		Config:     opts.Config,
+		HostConfig: opts.HostConfig,
+	}
+
+	return container, nil
+}
+
+// StartContainer starts the container
+// (the host config is configured when the container is created)
+func (c *Client) StartContainer(id string, hostConfig *docker.HostConfig) error {
+	status, err := c.doJSON(http.MethodPost, "/containers/"+id+"/start", nil, nil, nil)
+	if err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return &docker.NoSuchContainer{ID: id}
+		}
+
+		return err
+	}
+
+	if status == http.StatusNotModified {
+		return &docker.ContainerAlreadyRunning{ID: id}
+	}
+
+	return nil
+}
+
+// StopContainer stops the container
+func (c *Client) StopContainer(id string, timeout uint) error {
+	query := url.Values{}
+	query.Set("timeout", strconv.FormatUint(uint64(timeout), 10))
+
+	status, err := c.doJSON(http.MethodPost, "/containers/"+id+"/stop", query, nil, nil)
+	if err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return &docker.NoSuchContainer{ID: id}
+		}
+
+		return err
+	}
+
+	if status == http.StatusNotModified {
+		return &docker.ContainerNotRunning{ID: id}
+	}
+
+	return nil
+}
+
+// RemoveContainer removes the container
+func (c *Client) RemoveContainer(opts docker.RemoveContainerOptions) error {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(opts.Force))
+	query.Set("v", strconv.FormatBool(opts.RemoveVolumes))
+
+	if _, err := c.doJSON(http.MethodDelete, "/containers/"+opts.ID, query, nil, nil); err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return &docker.NoSuchContainer{ID: opts.ID}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// CreateExec creates a new exec instance in the container
+func (c *Client) CreateExec(opts docker.CreateExecOptions) (*docker.Exec, error) {
+	var exec docker.Exec
+	if _, err := c.doJSON(http.MethodPost, "/containers/"+opts.Container+"/exec", nil, opts, &exec); err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return nil, &docker.NoSuchContainer{ID: opts.Container}
+		}
+
+		return nil, err
+	}
+
+	return &exec, nil
+}
+
+// StartExec starts the exec instance and (if it's not detached) waits for it to complete
+// (writing the exec output to the output and error streams; stdin isn't supported)
+func (c *Client) StartExec(id string, opts docker.StartExecOptions) error {
+	if opts.InputStream != nil {
+		return fmt.Errorf("%w - exec input stream", crt.ErrNotSupported)
+	}
+
+	startParams := map[string]bool{
+		"Detach": opts.Detach,
+		"Tty":    opts.Tty,
+	}
+
+	data, err := json.Marshal(startParams)
+	if err != nil {
+		return err
+	}
+
+	reqOpts := requestOptions{
+		ctx:         opts.Context,
+		body:        bytes.NewReader(data),
+		contentType: contentTypeJSON,
+	}
+
+	resp, err := c.do(http.MethodPost, "/exec/"+id+"/start", reqOpts)
+	if err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return &docker.NoSuchExec{ID: id}
+		}
+
+		return err
+	}
+
+	defer resp.Body.Close()
+	if opts.Success != nil {
+		opts.Success <- struct{}{}
+		<-opts.Success
+	}
+
+	if opts.Detach {
+		return nil
+	}
+
+	stdout := opts.OutputStream
+	if stdout == nil {
+		stdout = io.Discard
+	}
+
+	stderr := opts.ErrorStream
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	if opts.Tty || opts.RawTerminal {
+		_, err = io.Copy(stdout, resp.Body)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, resp.Body)
+	}
+
+	return err
+}
+
+// InspectExec returns the exec instance information
+func (c *Client) InspectExec(id string) (*docker.ExecInspect, error) {
+	var info docker.ExecInspect
+	if _, err := c.doJSON(http.MethodGet, "/exec/"+id+"/json", nil, nil, &info); err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return nil, &docker.NoSuchExec{ID: id}
+		}
+
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// DownloadFromContainer copies the container file or directory (as a tar stream)
+func (c *Client) DownloadFromContainer(id string, opts docker.DownloadFromContainerOptions) error {
+	query := url.Values{}
+	query.Set("path", opts.Path)
+
+	reqOpts := requestOptions{
+		ctx:   opts.Context,
+		query: query,
+	}
+
+	return c.stream(http.MethodGet, "/containers/"+id+"/archive", reqOpts, opts.OutputStream)
+}
+
+// UploadToContainer extracts the tar stream to the container directory
+func (c *Client) UploadToContainer(id string, opts docker.UploadToContainerOptions) error {
+	query := url.Values{}
+	query.Set("path", opts.Path)
+
+	reqOpts := requestOptions{
+		ctx:         opts.Context,
+		query:       query,
+		body:        opts.InputStream,
+		contentType: contentTypeTar,
+	}
+
+	return c.stream(http.MethodPut, "/containers/"+id+"/archive", reqOpts, nil)
+}
+
+// CreateVolume creates a new volume
+func (c *Client) CreateVolume(opts docker.CreateVolumeOptions) (*docker.Volume, error) {
+	params := struct {
+		Name    string            `json:"Name,omitempty"`
+		Driver  string            `json:"Driver,omitempty"`
+		Label   map[string]string `json:"Label,omitempty"`
+		Options map[string]string `json:"Options,omitempty"`
+	}{
+		Name:    opts.Name,
+		Driver:  opts.Driver,
+		Label:   opts.Labels,
+		Options: opts.DriverOpts,
+	}
+
+	var volume docker.Volume
+	if _, err := c.doJSON(http.MethodPost, "/volumes/create", nil, params, &volume); err != nil {
+		return nil, err
+	}
+
+	return &volume, nil
+}
+
+// InspectVolume returns the volume information
+func (c *Client) InspectVolume(name string) (*docker.Volume, error) {
+	var volume docker.Volume
+	if _, err := c.doJSON(http.MethodGet, "/volumes/"+name+"/json", nil, nil, &volume); err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return nil, docker.ErrNoSuchVolume
+		}
+
+		return nil, err
+	}
+
+	return &volume, nil
+}
+
+// ListVolumes returns the volumes
+func (c *Client) ListVolumes(opts docker.ListVolumesOptions) ([]docker.Volume, error) {
+	query := url.Values{}
+	if err := filtersQuery(query, opts.Filters); err != nil {
+		return nil, err
+	}
+
+	var volumes []docker.Volume
+	if _, err := c.doJSON(http.MethodGet, "/volumes/json", query, nil, &volumes); err != nil {
+		return nil, err
+	}
+
+	return volumes, nil
+}
+
+// RemoveVolumeWithOptions removes the volume
+func (c *Client) RemoveVolumeWithOptions(opts docker.RemoveVolumeOptions) error {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(opts.Force))
+
+	if _, err := c.doJSON(http.MethodDelete, "/volumes/"+opts.Name, query, nil, nil); err != nil {
+		switch {
+		case hasStatus(err, http.StatusNotFound):
+			return docker.ErrNoSuchVolume
+		case hasStatus(err, http.StatusConflict):
+			return docker.ErrVolumeInUse
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,663 @@
+package podman
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/crt"
+)
+
+type testRequest struct {
+	Method string
+	Path   string
+	Query  map[string][]string
+	Header http.Header
+	Body   []byte
+}
+
+// testPodmanServer is a fake Podman API service that records the API requests
+type testPodmanServer struct {
+	routes   map[string]http.HandlerFunc
+	requests []testRequest
+}
+
+func newTestPodmanServer() *testPodmanServer {
+	return &testPodmanServer{
+		routes: map[string]http.HandlerFunc{},
+	}
+}
+
+func (s *testPodmanServer) handle(method, path string, handler http.HandlerFunc) {
+	s.routes[method+" /"+APIVersion+"/libpod"+path] = handler
+}
+
+func (s *testPodmanServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	s.requests = append(s.requests, testRequest{
+		Method: r.Method,
+		Path:   strings.TrimPrefix(r.URL.Path, "/"+APIVersion+"/libpod"),
+		Query:  r.URL.Query(),
+		Header: r.Header,
+		Body:   body,
+	})
+
+	handler, found := s.routes[r.Method+" "+r.URL.Path]
+	if !found {
+		writeTestJSON(w, http.StatusNotFound, map[string]string{
+			"cause":   "no such object",
+			"message": "no such object: " + r.URL.Path,
+		})
+		return
+	}
+
+	handler(w, r)
+}
+
+func (s *testPodmanServer) lastRequest(t *testing.T) testRequest {
+	require.NotEmpty(t, s.requests)
+	return s.requests[len(s.requests)-1]
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func testStatus(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}
+}
+
+func testPodmanClient(t *testing.T) (*Client, *testPodmanServer) {
+	server := newTestPodmanServer()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	client, err := New(strings.Replace(httpServer.URL, "http://", "tcp://", 1))
+	require.NoError(t, err)
+	return client, server
+}
+
+func TestNew(t *testing.T) {
+	tt := []struct {
+		address     string
+		expectedURL string
+		isErr       bool
+	}{
+		{address: "unix:///run/podman/podman.sock", expectedURL: "http://d"},
+		{address: "tcp://127.0.0.1:8080", expectedURL: "http://127.0.0.1:8080"},
+		{address: "http://localhost:8080", expectedURL: "http://localhost:8080"},
+		{address: "ssh://core@localhost:2222/run/podman/podman.sock", isErr: true},
+		{address: "npipe:////./pipe/podman", isErr: true},
+	}
+
+	for _, test := range tt {
+		client, err := New(test.address)
+		if test.isErr {
+			assert.Error(t, err, test.address)
+			continue
+		}
+
+		require.NoError(t, err, test.address)
+		assert.Equal(t, test.address, client.Address)
+		assert.Equal(t, test.expectedURL, client.baseURL)
+	}
+
+	t.Setenv(EnvContainerHost, "tcp://127.0.0.1:9999")
+	client, err := New("")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp://127.0.0.1:9999", client.Address)
+}
+
+func TestSocketAddr(t *testing.T) {
+	t.Setenv(EnvContainerHost, "unix:///tmp/podman.sock")
+	addr, err := SocketAddr()
+	require.NoError(t, err)
+	assert.Equal(t, "unix:///tmp/podman.sock", addr)
+
+	//rootless Podman socket
+	runtimeDir := t.TempDir()
+	socketPath := filepath.Join(runtimeDir, userSocketSuffix)
+	require.NoError(t, os.MkdirAll(filepath.Dir(socketPath), 0755))
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	t.Setenv(EnvContainerHost, "")
+	t.Setenv(EnvRuntimeDir, runtimeDir)
+	addr, err = SocketAddr()
+	require.NoError(t, err)
+	assert.Equal(t, "unix://"+socketPath, addr)
+}
+
+func TestUnixSocketClient(t *testing.T) {
+	server := newTestPodmanServer()
+	server.handle(http.MethodGet, "/_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+
+	socketPath := filepath.Join(t.TempDir(), "podman.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	httpServer := httptest.NewUnstartedServer(server)
+	httpServer.Listener = listener
+	httpServer.Start()
+	defer httpServer.Close()
+
+	client, err := New("unix://" + socketPath)
+	require.NoError(t, err)
+	require.NoError(t, client.Ping())
+	assert.Equal(t, "/_ping", server.lastRequest(t).Path)
+}
+
+func TestClientImages(t *testing.T) {
+	client, server := testPodmanClient(t)
+	server.handle(http.MethodGet, "/images/app:latest/json", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, map[string]interface{}{
+			"Id":           "sha256:1234",
+			"RepoTags":     []string{"app:latest"},
+			"Architecture": "amd64",
+			"Size":         1024,
+		})
+	})
+	server.handle(http.MethodGet, "/images/json", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, []map[string]interface{}{
+			{"Id": "sha256:1234", "RepoTags": []string{"app:latest"}},
+		})
+	})
+	server.handle(http.MethodGet, "/images/app:latest/history", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, []map[string]interface{}{
+			{"Id": "sha256:1234", "CreatedBy": "/bin/sh -c #(nop) CMD [\"app\"]"},
+		})
+	})
+
+	image, err := client.InspectImage("app:latest")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:1234", image.ID)
+	assert.Equal(t, []string{"app:latest"}, image.RepoTags)
+	assert.Equal(t, "amd64", image.Architecture)
+
+	_, err = client.InspectImage("other:latest")
+	assert.Equal(t, docker.ErrNoSuchImage, err)
+
+	images, err := client.ListImages(docker.ListImagesOptions{
+		All:     true,
+		Filters: map[string][]string{"reference": {"app"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "sha256:1234", images[0].ID)
+	req := server.lastRequest(t)
+	assert.Equal(t, []string{"true"}, req.Query["all"])
+	assert.Equal(t, []string{`{"reference":["app"]}`}, req.Query["filters"])
+
+	history, err := client.ImageHistory("app:latest")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "sha256:1234", history[0].ID)
+
+	_, err = client.ImageHistory("other:latest")
+	assert.Equal(t, docker.ErrNoSuchImage, err)
+}
+
+func TestClientPullImage(t *testing.T) {
+	client, server := testPodmanClient(t)
+	server.handle(http.MethodPost, "/images/pull", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Query().Get("reference"), "bad") {
+			writeTestJSON(w, http.StatusOK, map[string]string{"error": "manifest unknown"})
+			return
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.Encode(map[string]string{"stream": "Trying to pull app...\n"})
+		encoder.Encode(map[string]string{"id": "sha256:1234"})
+	})
+
+	var output bytes.Buffer
+	auth := docker.AuthConfiguration{Username: "user", Password: "secret"}
+	err := client.PullImage(docker.PullImageOptions{
+		Repository:   "docker.io/library/app",
+		Tag:          "v1",
+		Platform:     "linux/arm/v7",
+		OutputStream: &output,
+	}, auth)
+	require.NoError(t, err)
+	assert.Equal(t, "Trying to pull app...\n", output.String())
+
+	req := server.lastRequest(t)
+	assert.Equal(t, []string{"docker.io/library/app:v1"}, req.Query["reference"])
+	assert.Equal(t, []string{"linux"}, req.Query["OS"])
+	assert.Equal(t, []string{"arm"}, req.Query["Arch"])
+	assert.Equal(t, []string{"v7"}, req.Query["Variant"])
+
+	authData, err := base64.URLEncoding.DecodeString(req.Header.Get(headerAuth))
+	require.NoError(t, err)
+	var reqAuth docker.AuthConfiguration
+	require.NoError(t, json.Unmarshal(authData, &reqAuth))
+	assert.Equal(t, auth, reqAuth)
+
+	err = client.PullImage(docker.PullImageOptions{Repository: "app", Tag: "sha256:abcd"}, docker.AuthConfiguration{})
+	require.NoError(t, err)
+	req = server.lastRequest(t)
+	assert.Equal(t, []string{"app@sha256:abcd"}, req.Query["reference"])
+	assert.Empty(t, req.Header.Get(headerAuth))
+
+	err = client.PullImage(docker.PullImageOptions{Repository: "bad"}, docker.AuthConfiguration{})
+	assert.ErrorContains(t, err, "manifest unknown")
+
+	err = client.PullImage(docker.PullImageOptions{}, docker.AuthConfiguration{})
+	assert.Equal(t, docker.ErrNoSuchImage, err)
+}
+
+func TestClientSaveAndLoadImage(t *testing.T) {
+	client, server := testPodmanClient(t)
+	server.handle(http.MethodGet, "/images/app:latest/get", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("image tar data"))
+	})
+	server.handle(http.MethodPost, "/images/load", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, map[string][]string{"Names": {"localhost/app:latest"}})
+	})
+
+	var output bytes.Buffer
+	err := client.ExportImage(docker.ExportImageOptions{Name: "app:latest", OutputStream: &output})
+	require.NoError(t, err)
+	assert.Equal(t, "image tar data", output.String())
+	assert.Equal(t, []string{"docker-archive"}, server.lastRequest(t).Query["format"])
+
+	err = client.ExportImage(docker.ExportImageOptions{Name: "other:latest", OutputStream: &output})
+	assert.Equal(t, docker.ErrNoSuchImage, err)
+
+	output.Reset()
+	err = client.LoadImage(docker.LoadImageOptions{
+		InputStream:  strings.NewReader("image tar data"),
+		OutputStream: &output,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Loaded image: localhost/app:latest\n", output.String())
+
+	req := server.lastRequest(t)
+	assert.Equal(t, contentTypeTar, req.Header.Get("Content-Type"))
+	assert.Equal(t, "image tar data", string(req.Body))
+}
+
+func TestNewSpecGenerator(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     docker.CreateContainerOptions
+		expected *specGenerator
+		isErr    bool
+	}{
+		{
+			name:  "no config",
+			opts:  docker.CreateContainerOptions{Name: "app"},
+			isErr: true,
+		},
+		{
+			name: "config only",
+			opts: docker.CreateContainerOptions{
+				Name: "app",
+				Config: &docker.Config{
+					Image:        "app:latest",
+					Entrypoint:   []string{"/app/run"},
+					Cmd:          []string{"--port", "8080"},
+					Env:          []string{"MODE=prod", "EMPTY=", "FLAG", "KV=a=b"},
+					User:         "app",
+					WorkingDir:   "/app",
+					Labels:       map[string]string{"owner": "team"},
+					ExposedPorts: map[docker.Port]struct{}{"8080/tcp": {}, "53/udp": {}},
+				},
+			},
+			expected: &specGenerator{
+				Name:       "app",
+				Image:      "app:latest",
+				Entrypoint: []string{"/app/run"},
+				Command:    []string{"--port", "8080"},
+				Env:        map[string]string{"MODE": "prod", "EMPTY": "", "FLAG": "", "KV": "a=b"},
+				User:       "app",
+				WorkDir:    "/app",
+				Labels:     map[string]string{"owner": "team"},
+				Expose:     map[uint16]string{8080: "tcp", 53: "udp"},
+			},
+		},
+		{
+			name: "host config",
+			opts: docker.CreateContainerOptions{
+				Config: &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{
+					AutoRemove:      true,
+					Privileged:      true,
+					CapAdd:          []string{"SYS_ADMIN"},
+					CapDrop:         []string{"NET_RAW"},
+					PublishAllPorts: true,
+					Binds:           []string{"/host/data:/data:ro,z", "sensor-vol:/opt/_slim/bin"},
+					Mounts: []docker.HostMount{
+						{Type: "volume", Source: "state", Target: "/state", ReadOnly: true},
+						{Source: "/host/tmp", Target: "/tmp"},
+						{Type: "tmpfs", Target: "/run"},
+					},
+					PortBindings: map[docker.Port][]docker.PortBinding{
+						"8080/tcp":  {{HostIP: "127.0.0.1", HostPort: "18080"}},
+						"65501/tcp": nil,
+					},
+					NetworkMode: "host",
+				},
+			},
+			expected: &specGenerator{
+				Image:             "app",
+				Remove:            true,
+				Privileged:        true,
+				CapAdd:            []string{"SYS_ADMIN"},
+				CapDrop:           []string{"NET_RAW"},
+				PublishImagePorts: true,
+				Mounts: []specMount{
+					{Destination: "/data", Type: "bind", Source: "/host/data", Options: []string{"ro", "z"}},
+					{Destination: "/tmp", Type: "bind", Source: "/host/tmp"},
+					{Destination: "/run", Type: "tmpfs"},
+				},
+				Volumes: []specNamedVolume{
+					{Name: "sensor-vol", Dest: "/opt/_slim/bin"},
+					{Name: "state", Dest: "/state", Options: []string{"ro"}},
+				},
+				PortMappings: []specPortMapping{
+					{HostIP: "127.0.0.1", ContainerPort: 8080, HostPort: 18080, Protocol: "tcp"},
+					{ContainerPort: 65501, Protocol: "tcp"},
+				},
+				NetNS: &specNamespace{NSMode: "host"},
+			},
+		},
+		{
+			name: "container network",
+			opts: docker.CreateContainerOptions{
+				Config:     &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{NetworkMode: "container:db"},
+			},
+			expected: &specGenerator{
+				Image: "app",
+				NetNS: &specNamespace{NSMode: "container", Value: "db"},
+			},
+		},
+		{
+			name: "user network",
+			opts: docker.CreateContainerOptions{
+				Config:     &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{NetworkMode: "app-net"},
+			},
+			expected: &specGenerator{
+				Image:    "app",
+				NetNS:    &specNamespace{NSMode: "bridge"},
+				Networks: map[string]json.RawMessage{"app-net": json.RawMessage("{}")},
+			},
+		},
+		{
+			name: "default network",
+			opts: docker.CreateContainerOptions{
+				Config:     &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{NetworkMode: "bridge"},
+			},
+			expected: &specGenerator{Image: "app"},
+		},
+		{
+			name: "bad bind",
+			opts: docker.CreateContainerOptions{
+				Config:     &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{Binds: []string{"/data"}},
+			},
+			isErr: true,
+		},
+		{
+			name: "unsupported mount type",
+			opts: docker.CreateContainerOptions{
+				Config:     &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{Mounts: []docker.HostMount{{Type: "npipe", Target: "/pipe"}}},
+			},
+			isErr: true,
+		},
+		{
+			name: "bad host port",
+			opts: docker.CreateContainerOptions{
+				Config: &docker.Config{Image: "app"},
+				HostConfig: &docker.HostConfig{
+					PortBindings: map[docker.Port][]docker.PortBinding{"8080/tcp": {{HostPort: "http"}}},
+				},
+			},
+			isErr: true,
+		},
+	}
+
+	for _, test := range tt {
+		spec, err := newSpecGenerator(test.opts)
+		if test.isErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+
+		//the port mappings are created from a map
+		assert.ElementsMatch(t, test.expected.PortMappings, spec.PortMappings, test.name)
+		expected := *test.expected
+		expected.PortMappings = spec.PortMappings
+		assert.Equal(t, &expected, spec, test.name)
+	}
+
+	_, err := newSpecGenerator(docker.CreateContainerOptions{
+		Config:     &docker.Config{Image: "app"},
+		HostConfig: &docker.HostConfig{Mounts: []docker.HostMount{{Type: "npipe", Target: "/pipe"}}},
+	})
+	assert.ErrorIs(t, err, crt.ErrNotSupported)
+}
+
+func TestClientContainers(t *testing.T) {
+	client, server := testPodmanClient(t)
+	server.handle(http.MethodPost, "/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		var spec specGenerator
+		json.NewDecoder(r.Body).Decode(&spec)
+		switch spec.Image {
+		case "missing":
+			writeTestJSON(w, http.StatusNotFound, map[string]string{"message": "image not known"})
+		case "conflict":
+			writeTestJSON(w, http.StatusConflict, map[string]string{"message": "name in use"})
+		default:
+			writeTestJSON(w, http.StatusCreated, map[string]interface{}{"Id": "c1", "Warnings": []string{"no cgroups"}})
+		}
+	})
+	server.handle(http.MethodPost, "/containers/c1/start", testStatus(http.StatusNoContent))
+	server.handle(http.MethodPost, "/containers/c2/start", testStatus(http.StatusNotModified))
+	server.handle(http.MethodPost, "/containers/c1/stop", testStatus(http.StatusNoContent))
+	server.handle(http.MethodPost, "/containers/c2/stop", testStatus(http.StatusNotModified))
+	server.handle(http.MethodDelete, "/containers/c1", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, []map[string]string{{"Id": "c1"}})
+	})
+
+	config := &docker.Config{Image: "app", Cmd: []string{"run"}}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{Name: "app", Config: config})
+	require.NoError(t, err)
+	assert.Equal(t, "c1", container.ID)
+	assert.Equal(t, "app", container.Name)
+	assert.Equal(t, config, container.Config)
+
+	var spec specGenerator
+	req := server.lastRequest(t)
+	assert.Equal(t, contentTypeJSON, req.Header.Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(req.Body, &spec))
+	assert.Equal(t, "app", spec.Name)
+	assert.Equal(t, []string{"run"}, spec.Command)
+
+	_, err = client.CreateContainer(docker.CreateContainerOptions{Config: &docker.Config{Image: "missing"}})
+	assert.Equal(t, docker.ErrNoSuchImage, err)
+
+	_, err = client.CreateContainer(docker.CreateContainerOptions{Config: &docker.Config{Image: "conflict"}})
+	assert.Equal(t, docker.ErrContainerAlreadyExists, err)
+
+	assert.NoError(t, client.StartContainer("c1", nil))
+	assert.IsType(t, &docker.ContainerAlreadyRunning{}, client.StartContainer("c2", nil))
+	assert.IsType(t, &docker.NoSuchContainer{}, client.StartContainer("c3", nil))
+
+	assert.NoError(t, client.StopContainer("c1", 9))
+	assert.Equal(t, []string{"9"}, server.lastRequest(t).Query["timeout"])
+	assert.IsType(t, &docker.ContainerNotRunning{}, client.StopContainer("c2", 9))
+	assert.IsType(t, &docker.NoSuchContainer{}, client.StopContainer("c3", 9))
+
+	assert.NoError(t, client.RemoveContainer(docker.RemoveContainerOptions{ID: "c1", Force: true, RemoveVolumes: true}))
+	req = server.lastRequest(t)
+	assert.Equal(t, []string{"true"}, req.Query["force"])
+	assert.Equal(t, []string{"true"}, req.Query["v"])
+	assert.IsType(t, &docker.NoSuchContainer{}, client.RemoveContainer(docker.RemoveContainerOptions{ID: "c3"}))
+}
+
+func TestClientExec(t *testing.T) {
+	client, server := testPodmanClient(t)
+	server.handle(http.MethodPost, "/containers/c1/exec", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusCreated, map[string]string{"Id": "e1"})
+	})
+	server.handle(http.MethodPost, "/exec/e1/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		stdcopy.NewStdWriter(w, stdcopy.Stdout).Write([]byte("out"))
+		stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte("err"))
+	})
+	server.handle(http.MethodGet, "/exec/e1/json", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, map[string]interface{}{"ID": "e1", "ContainerID": "c1", "ExitCode": 3})
+	})
+
+	exec, err := client.CreateExec(docker.CreateExecOptions{
+		Container:    "c1",
+		Cmd:          []string{"ls", "/"},
+		AttachStdout: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "e1", exec.ID)
+
+	var execOpts docker.CreateExecOptions
+	require.NoError(t, json.Unmarshal(server.lastRequest(t).Body, &execOpts))
+	assert.Equal(t, []string{"ls", "/"}, execOpts.Cmd)
+
+	_, err = client.CreateExec(docker.CreateExecOptions{Container: "c3", Cmd: []string{"ls"}})
+	assert.IsType(t, &docker.NoSuchContainer{}, err)
+
+	var stdout, stderr bytes.Buffer
+	err = client.StartExec("e1", docker.StartExecOptions{OutputStream: &stdout, ErrorStream: &stderr})
+	require.NoError(t, err)
+	assert.Equal(t, "out", stdout.String())
+	assert.Equal(t, "err", stderr.String())
+
+	err = client.StartExec("e1", docker.StartExecOptions{InputStream: strings.NewReader("data")})
+	assert.ErrorIs(t, err, crt.ErrNotSupported)
+
+	err = client.StartExec("e2", docker.StartExecOptions{})
+	assert.IsType(t, &docker.NoSuchExec{}, err)
+
+	info, err := client.InspectExec("e1")
+	require.NoError(t, err)
+	assert.Equal(t, "c1", info.ContainerID)
+	assert.Equal(t, 3, info.ExitCode)
+
+	_, err = client.InspectExec("e2")
+	assert.IsType(t, &docker.NoSuchExec{}, err)
+}
+
+func TestClientContainerArchive(t *testing.T) {
+	client, server := testPodmanClient(t)
+	server.handle(http.MethodGet, "/containers/c1/archive", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tar from " + r.URL.Query().Get("path")))
+	})
+	server.handle(http.MethodPut, "/containers/c1/archive", testStatus(http.StatusOK))
+
+	var output bytes.Buffer
+	err := client.DownloadFromContainer("c1", docker.DownloadFromContainerOptions{
+		Path:         "/opt/_slim/artifacts",
+		OutputStream: &output,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "tar from /opt/_slim/artifacts", output.String())
+
+	err = client.DownloadFromContainer("c2", docker.DownloadFromContainerOptions{Path: "/", OutputStream: &output})
+	assert.Error(t, err)
+
+	err = client.UploadToContainer("c1", docker.UploadToContainerOptions{
+		Path:        "/data",
+		InputStream: strings.NewReader("tar data"),
+	})
+	require.NoError(t, err)
+
+	req := server.lastRequest(t)
+	assert.Equal(t, []string{"/data"}, req.Query["path"])
+	assert.Equal(t, contentTypeTar, req.Header.Get("Content-Type"))
+	assert.Equal(t, "tar data", string(req.Body))
+}
+
+func TestClientVolumes(t *testing.T) {
+	client, server := testPodmanClient(t)
+	server.handle(http.MethodPost, "/volumes/create", func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&params)
+		writeTestJSON(w, http.StatusCreated, map[string]interface{}{
+			"Name":   params["Name"],
+			"Driver": "local",
+			"Labels": params["Label"],
+		})
+	})
+	server.handle(http.MethodGet, "/volumes/sensor/json", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, map[string]string{"Name": "sensor", "Mountpoint": "/var/lib/volumes/sensor"})
+	})
+	server.handle(http.MethodGet, "/volumes/json", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, []map[string]string{{"Name": "sensor"}})
+	})
+	server.handle(http.MethodDelete, "/volumes/sensor", testStatus(http.StatusNoContent))
+	server.handle(http.MethodDelete, "/volumes/used", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusConflict, map[string]string{"message": "volume is being used"})
+	})
+	server.handle(http.MethodDelete, "/volumes/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("storage error"))
+	})
+
+	volume, err := client.CreateVolume(docker.CreateVolumeOptions{
+		Name:   "sensor",
+		Labels: map[string]string{"app": "slim"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sensor", volume.Name)
+	assert.Equal(t, map[string]string{"app": "slim"}, volume.Labels)
+
+	volume, err = client.InspectVolume("sensor")
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/volumes/sensor", volume.Mountpoint)
+
+	_, err = client.InspectVolume("other")
+	assert.Equal(t, docker.ErrNoSuchVolume, err)
+
+	volumes, err := client.ListVolumes(docker.ListVolumesOptions{Filters: map[string][]string{"name": {"sensor"}}})
+	require.NoError(t, err)
+	require.Len(t, volumes, 1)
+	assert.Equal(t, []string{`{"name":["sensor"]}`}, server.lastRequest(t).Query["filters"])
+
+	assert.NoError(t, client.RemoveVolumeWithOptions(docker.RemoveVolumeOptions{Name: "sensor", Force: true}))
+	assert.Equal(t, []string{"true"}, server.lastRequest(t).Query["force"])
+	assert.Equal(t, docker.ErrNoSuchVolume, client.RemoveVolumeWithOptions(docker.RemoveVolumeOptions{Name: "other"}))
+	assert.Equal(t, docker.ErrVolumeInUse, client.RemoveVolumeWithOptions(docker.RemoveVolumeOptions{Name: "used"}))
+
+	//the API error message is used as is if it's not a JSON error
+	err = client.RemoveVolumeWithOptions(docker.RemoveVolumeOptions{Name: "broken"})
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.Status)
+	assert.Equal(t, "storage error", apiErr.Message)
+}
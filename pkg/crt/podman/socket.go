@@ -0,0 +1,53 @@
+package podman
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	EnvContainerHost = "CONTAINER_HOST"
+	EnvRuntimeDir    = "XDG_RUNTIME_DIR"
+	RootSocketPath   = "/run/podman/podman.sock"
+	userSocketSuffix = "podman/podman.sock"
+	userRuntimeDir   = "/run/user"
+)
+
+var (
+	ErrNoSocket = errors.New("podman socket not found")
+)
+
+// SocketAddr returns the Podman API service address
+// (from CONTAINER_HOST or the rootless and rootful Podman socket locations)
+func SocketAddr() (string, error) {
+	if addr := os.Getenv(EnvContainerHost); addr != "" {
+		log.Debugf("podman.SocketAddr: using %s - %s", EnvContainerHost, addr)
+		return addr, nil
+	}
+
+	var candidates []string
+	if runtimeDir := os.Getenv(EnvRuntimeDir); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, userSocketSuffix))
+	}
+
+	candidates = append(candidates,
+		filepath.Join(userRuntimeDir, strconv.Itoa(os.Getuid()), userSocketSuffix),
+		RootSocketPath)
+
+	for _, socketPath := range candidates {
+		info, err := os.Stat(socketPath)
+		if err != nil || info.Mode()&os.ModeSocket == 0 {
+			continue
+		}
+
+		log.Debugf("podman.SocketAddr: found - %s", socketPath)
+		return fmt.Sprintf("unix://%s", socketPath), nil
+	}
+
+	return "", ErrNoSocket
+}
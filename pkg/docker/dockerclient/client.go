@@ -10,6 +10,8 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slimtoolkit/slim/pkg/app/master/config"
+	"github.com/slimtoolkit/slim/pkg/crt"
+	"github.com/slimtoolkit/slim/pkg/crt/podman"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 	"github.com/slimtoolkit/slim/pkg/util/jsonutil"
@@ -34,6 +36,7 @@ var EnvVarNames = []string{
 
 var (
 	ErrNoDockerInfo = errors.New("no docker info")
+	ErrNoDockerAPI  = errors.New("the container runtime engine doesn't provide the Docker API (use the 'docker' or 'podman' engine)")
 )
 
 func UserDockerSocket() string {
@@ -131,10 +134,20 @@ func GetUnixSocketAddr() (*SocketInfo, error) {
 }
 
 // New creates a new Docker client instance
+// (with the Podman engine the client uses the Docker-compatible API of the Podman API service)
 func New(config *config.DockerClient) (*docker.Client, error) {
 	var client *docker.Client
 	var err error
 
+	engine, err := crt.ValidateEngine(config.Engine)
+	if err != nil {
+		return nil, err
+	}
+
+	if !crt.HasDockerAPI(engine) {
+		return nil, ErrNoDockerAPI
+	}
+
 	newTLSClient := func(host string, certPath string, verify bool, apiVersion string) (*docker.Client, error) {
 		var ca []byte
 
@@ -160,6 +173,25 @@ func New(config *config.DockerClient) (*docker.Client, error) {
 	}
 
 	switch {
+	case engine == crt.PodmanEngine &&
+		config.Host == "" &&
+		config.Env[EnvDockerHost] == "":
+		config.Host, err = podman.SocketAddr()
+		if err != nil {
+			return nil, err
+		}
+
+		client, err = docker.NewVersionedClient(config.Host, config.APIVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		if config.APIVersion != "" {
+			client.SkipServerVersionCheck = true
+		}
+
+		log.Debug("dockerclient.New: new Podman client (Docker-compatible API) [0]")
+
 	case config.Host != "" &&
 		config.UseTLS &&
 		config.VerifyTLS &&
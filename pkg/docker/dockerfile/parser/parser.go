@@ -3,6 +3,7 @@ package parser
 
 import (
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -19,7 +20,6 @@ var (
 
 //TODO:
 //* support incremental, partial and instruction level parsing
-//* support parsing from reader and from string
 
 func FromFile(fpath string) (*spec.Dockerfile, error) {
 	fo, err := os.Open(fpath)
@@ -29,7 +29,13 @@ func FromFile(fpath string) (*spec.Dockerfile, error) {
 
 	defer fo.Close()
 
-	astParsed, err := ast.Parse(fo)
+	return FromReader(fo, filepath.Base(fpath), filepath.Dir(fpath))
+}
+
+// FromReader parses the Dockerfile data from the reader
+// (the name and the location are the Dockerfile name and directory if it has them)
+func FromReader(input io.Reader, name, location string) (*spec.Dockerfile, error) {
+	astParsed, err := ast.Parse(input)
 	if err != nil {
 		return nil, err
 	}
@@ -39,8 +45,8 @@ func FromFile(fpath string) (*spec.Dockerfile, error) {
 	}
 
 	dockerfile := spec.NewDockerfile()
-	dockerfile.Name = filepath.Base(fpath)
-	dockerfile.Location = filepath.Dir(fpath)
+	dockerfile.Name = name
+	dockerfile.Location = location
 	dockerfile.Lines = astParsed.Lines
 
 	if astParsed.AST.StartLine > -1 && len(astParsed.AST.Children) > 0 {
@@ -105,12 +111,13 @@ func FromFile(fpath string) (*spec.Dockerfile, error) {
 				if len(inst.Args) > 0 {
 					var parts []string
 					var hasDigest bool
+					//checking the digest first (the digests have ':' too)
 					switch {
-					case strings.Contains(inst.Args[0], ":"):
-						parts = strings.Split(inst.Args[0], ":")
 					case strings.Contains(inst.Args[0], "@"):
-						parts = strings.Split(inst.Args[0], "@")
+						parts = strings.SplitN(inst.Args[0], "@", 2)
 						hasDigest = true
+					case strings.Contains(inst.Args[0], ":"):
+						parts = strings.Split(inst.Args[0], ":")
 					default:
 						parts = append(parts, inst.Args[0])
 					}
@@ -129,11 +136,11 @@ func FromFile(fpath string) (*spec.Dockerfile, error) {
 								if len(parts) == 1 {
 									if len(argVal) > 0 {
 										switch {
-										case strings.Contains(argVal, ":"):
-											parts = strings.Split(argVal, ":")
 										case strings.Contains(argVal, "@"):
-											parts = strings.Split(argVal, "@")
+											parts = strings.SplitN(argVal, "@", 2)
 											hasDigest = true
+										case strings.Contains(argVal, ":"):
+											parts = strings.Split(argVal, ":")
 										default:
 											parts = nil
 											parts = append(parts, argVal)
@@ -15,6 +15,8 @@ import (
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/google/shlex"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/crt"
 )
 
 var (
@@ -203,7 +205,7 @@ func DockerfileFromHistoryData(data string) (*Dockerfile, error) {
 }
 
 // DockerfileFromHistory recreates Dockerfile information from container image history
-func DockerfileFromHistory(apiClient *docker.Client, imageID string) (*Dockerfile, error) {
+func DockerfileFromHistory(apiClient crt.APIClient, imageID string) (*Dockerfile, error) {
 	//TODO: make it possible to pass the history information as a param
 	//TODO: pass the other image metadata (including OCI and buildkit base image info)
 	imageHistory, err := apiClient.ImageHistory(imageID)
@@ -25,7 +25,12 @@ import (
 
 	"github.com/slimtoolkit/slim/pkg/certdiscover"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerutil"
+	"github.com/slimtoolkit/slim/pkg/sbom"
+	"github.com/slimtoolkit/slim/pkg/secretdiscover"
+	"github.com/slimtoolkit/slim/pkg/syshook"
 	"github.com/slimtoolkit/slim/pkg/sysidentity"
+	"github.com/slimtoolkit/slim/pkg/sysservice"
+	"github.com/slimtoolkit/slim/pkg/systask"
 	"github.com/slimtoolkit/slim/pkg/system"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 	"github.com/slimtoolkit/slim/pkg/util/jsonutil"
@@ -46,20 +51,25 @@ const (
 
 // todo: rename 'Package' struct
 type Package struct {
-	Format          ImageFormatType
-	IsOCI           bool
-	ManifestOCI     *oci.Manifest
-	Manifest        *DockerManifestObject
-	Config          *ConfigObject
-	Layers          []*Layer
-	LayerIDRefs     map[string]*Layer
-	HashReferences  map[string]map[string]*ObjectMetadata
-	Stats           PackageStats
-	OSShells        map[string]*system.OSShell
-	SpecialPermRefs SpecialPermsRefsInfo
-	Certs           CertsRefInfo
-	CACerts         CertsRefInfo
-	IdentityData    *sysidentity.DataSet
+	Format            ImageFormatType
+	IsOCI             bool
+	ManifestOCI       *oci.Manifest
+	Manifest          *DockerManifestObject
+	Config            *ConfigObject
+	Layers            []*Layer
+	LayerIDRefs       map[string]*Layer
+	HashReferences    map[string]map[string]*ObjectMetadata
+	Stats             PackageStats
+	OSShells          map[string]*system.OSShell
+	SpecialPermRefs   SpecialPermsRefsInfo
+	Certs             CertsRefInfo
+	CACerts           CertsRefInfo
+	IdentityData      *sysidentity.DataSet
+	PackageData       *sbom.DataSet
+	ScheduledTaskData *FileDataSet
+	ServiceData       *FileDataSet
+	SystemHookData    *FileDataSet
+	SecretData        *secretdiscover.DataSet
 }
 
 type CertsRefInfo struct {
@@ -93,14 +103,18 @@ type SpecialPermsInfo struct {
 }
 
 type ImageReport struct {
-	Stats        PackageStats                     `json:"stats"`
-	Duplicates   map[string]*DuplicateFilesReport `json:"duplicates,omitempty"`
-	SpecialPerms *SpecialPermsInfo                `json:"special_perms,omitempty"`
-	OSShells     []*system.OSShell                `json:"shells,omitempty"`
-	Certs        CertsInfo                        `json:"certs"`
-	CACerts      CertsInfo                        `json:"ca_certs"`
-	BuildInfo    *BuildKitBuildInfo               `json:"build_info,omitempty"`
-	Identities   *sysidentity.Report              `json:"identities,omitempty"`
+	Stats          PackageStats                     `json:"stats"`
+	Duplicates     map[string]*DuplicateFilesReport `json:"duplicates,omitempty"`
+	SpecialPerms   *SpecialPermsInfo                `json:"special_perms,omitempty"`
+	OSShells       []*system.OSShell                `json:"shells,omitempty"`
+	Certs          CertsInfo                        `json:"certs"`
+	CACerts        CertsInfo                        `json:"ca_certs"`
+	BuildInfo      *BuildKitBuildInfo               `json:"build_info,omitempty"`
+	Identities     *sysidentity.Report              `json:"identities,omitempty"`
+	ScheduledTasks *systask.Report                  `json:"scheduled_tasks,omitempty"`
+	Services       *sysservice.Report               `json:"services,omitempty"`
+	SystemHooks    *syshook.Report                  `json:"system_hooks,omitempty"`
+	Secrets        *secretdiscover.Report           `json:"secrets,omitempty"`
 }
 
 type DuplicateFilesReport struct {
@@ -448,7 +462,12 @@ func newPackage() *Package {
 			PrivateKeys:     map[string]struct{}{},
 			PrivateKeyLinks: map[string]string{},
 		},
-		IdentityData: sysidentity.NewDataSet(),
+		IdentityData:      sysidentity.NewDataSet(),
+		PackageData:       sbom.NewDataSet(),
+		ScheduledTaskData: NewFileDataSet(),
+		ServiceData:       NewFileDataSet(),
+		SystemHookData:    NewFileDataSet(),
+		SecretData:        secretdiscover.NewDataSet(),
 	}
 
 	return &pkg
@@ -500,6 +519,49 @@ type ProcessorParams struct {
 
 	DetectAllCertFiles   bool
 	DetectAllCertPKFiles bool
+	//collect the OS and language package data (for the SBOMs)
+	DetectPackages bool
+	//scan the layer files for secrets (nil if the secret detection is disabled)
+	SecretScanner *secretdiscover.Scanner
+}
+
+type detectorFileData struct {
+	data     *FileDataSet
+	isSource func(fullPath string) bool
+}
+
+// fileDataSets returns the file data sets for the enabled
+// scheduled task, service and system hook detectors
+func (ref *ProcessorParams) fileDataSets(pkg *Package) []detectorFileData {
+	var result []detectorFileData
+	if ref.DetectScheduledTasks != nil &&
+		ref.DetectScheduledTasks.Enabled &&
+		pkg.ScheduledTaskData != nil {
+		result = append(result, detectorFileData{
+			data:     pkg.ScheduledTaskData,
+			isSource: systask.IsSourceFile,
+		})
+	}
+
+	if ref.DetectServices != nil &&
+		ref.DetectServices.Enabled &&
+		pkg.ServiceData != nil {
+		result = append(result, detectorFileData{
+			data:     pkg.ServiceData,
+			isSource: sysservice.IsSourceFile,
+		})
+	}
+
+	if ref.DetectSystemHooks != nil &&
+		ref.DetectSystemHooks.Enabled &&
+		pkg.SystemHookData != nil {
+		result = append(result, detectorFileData{
+			data:     pkg.SystemHookData,
+			isSource: syshook.IsSourceFile,
+		})
+	}
+
+	return result
 }
 
 type LayerLocation struct {
@@ -1411,6 +1473,139 @@ func (ref *Package) ProcessIdentityData() *sysidentity.Report {
 	return report
 }
 
+// ProcessScheduledTaskData creates the scheduled task report from the task data in the image layers
+func (ref *Package) ProcessScheduledTaskData() *systask.Report {
+	if ref.ScheduledTaskData == nil {
+		return nil
+	}
+
+	files, links := ref.ScheduledTaskData.Files(ref.LayerOrder())
+	return systask.NewReportFromData(files, links)
+}
+
+// ProcessServiceData creates the service report from the service data in the image layers
+func (ref *Package) ProcessServiceData() *sysservice.Report {
+	if ref.ServiceData == nil {
+		return nil
+	}
+
+	files, links := ref.ServiceData.Files(ref.LayerOrder())
+	return sysservice.NewReportFromData(files, links)
+}
+
+// ProcessSystemHookData creates the system hook report from the hook data in the image layers
+func (ref *Package) ProcessSystemHookData() *syshook.Report {
+	if ref.SystemHookData == nil {
+		return nil
+	}
+
+	files, _ := ref.SystemHookData.Files(ref.LayerOrder())
+	return syshook.NewReportFromData(files)
+}
+
+// ProcessSecretData creates the secret report from the secret matches in the image layers
+// and the secrets in the image history and config
+// (the secrets in the files deleted or replaced in a later layer still exist in the earlier layers)
+func (ref *Package) ProcessSecretData(scanner *secretdiscover.Scanner) *secretdiscover.Report {
+	if ref.SecretData == nil || scanner == nil {
+		return nil
+	}
+
+	finalLayers := map[string]int{}
+	for name, object := range ref.FinalObjects() {
+		finalLayers[name] = object.LayerIndex
+	}
+
+	findings := ref.SecretData.Findings(ref.LayerOrder(), finalLayers)
+	if ref.Config != nil {
+		var createdBy []string
+		var historyLayers []int
+		for _, history := range ref.Config.History {
+			createdBy = append(createdBy, history.CreatedBy)
+			layerIndex := history.LayerIndex
+			if history.EmptyLayer {
+				layerIndex = -1
+			}
+
+			historyLayers = append(historyLayers, layerIndex)
+		}
+
+		var env []string
+		if ref.Config.Config != nil {
+			env = ref.Config.Config.Env
+		}
+
+		findings = append(findings, scanner.ScanHistory(createdBy, historyLayers, env)...)
+	}
+
+	return secretdiscover.NewReport(findings)
+}
+
+// LayerOrder returns the layer IDs in the layer order
+func (ref *Package) LayerOrder() []string {
+	var layerOrder []string
+	for _, layer := range ref.Layers {
+		layerOrder = append(layerOrder, layer.ID)
+	}
+
+	return layerOrder
+}
+
+// ProcessPackageData creates the package report from the package data in the image layers
+func (ref *Package) ProcessPackageData() *sbom.Report {
+	if ref.PackageData == nil {
+		return nil
+	}
+
+	return sbom.NewReportFromData(ref.PackageData, ref.LayerOrder())
+}
+
+// FinalObjects returns the (non-directory) objects in the flattened image filesystem
+// (the objects from the last layer that added or modified them)
+func (ref *Package) FinalObjects() map[string]*ObjectMetadata {
+	objects := map[string]*ObjectMetadata{}
+	for _, layer := range ref.Layers {
+		for _, object := range layer.Objects {
+			if object.Change == ChangeDelete {
+				prefix := strings.TrimSuffix(object.Name, "*")
+				if !strings.HasSuffix(prefix, "/") {
+					delete(objects, object.Name)
+					prefix += "/"
+				}
+
+				for fpath := range objects {
+					if strings.HasPrefix(fpath, prefix) {
+						delete(objects, fpath)
+					}
+				}
+
+				continue
+			}
+
+			if object.Type != DirType {
+				objects[object.Name] = object
+			}
+		}
+	}
+
+	return objects
+}
+
+// FileSystem returns the (non-directory) files and the symlinks (with their link targets)
+// in the flattened image filesystem
+func (ref *Package) FileSystem() (map[string]struct{}, map[string]string) {
+	files := map[string]struct{}{}
+	links := map[string]string{}
+	for name, object := range ref.FinalObjects() {
+		files[name] = struct{}{}
+		if object.Type == SymlinkType {
+			links[name] = object.LinkTarget
+		}
+	}
+
+	return files, links
+}
+
 func hasChangePathMatcherDumps(changePathMatchers []*ChangePathMatcher) bool {
 	for _, cpm := range changePathMatchers {
 		if cpm.PathPattern != "" && cpm.Dump {
@@ -1513,6 +1708,17 @@ func layerFromStream(
 		layer.Stats.AllSize += uint64(object.Size)
 		layer.Stats.ObjectCount++
 
+		if isDeleted || isDeletedDirContent {
+			deletedPath := strings.TrimSuffix(object.Name, "/*")
+			if processorParams.DetectPackages {
+				pkg.PackageData.RemoveData(layerID, deletedPath)
+			}
+
+			for _, fds := range processorParams.fileDataSets(pkg) {
+				fds.data.RemoveData(layerID, deletedPath)
+			}
+		}
+
 		if isDeletedDirContent {
 			object.Change = ChangeDelete
 			idx := len(layer.Objects) - 1
@@ -1541,6 +1747,19 @@ func layerFromStream(
 			if isDeleted {
 				layer.Stats.DeletedLinkCount++
 				pkg.Stats.DeletedLinkCount++
+			} else {
+				//the links enable services and scheduled tasks (or mask them)
+				target := object.LinkTarget
+				if hdr.Typeflag == tar.TypeLink {
+					//hard link targets are archive paths
+					target = "/" + strings.TrimPrefix(filepath.Clean(target), "/")
+				}
+
+				for _, fds := range processorParams.fileDataSets(pkg) {
+					if fds.isSource(object.Name) {
+						fds.data.AddLink(layerID, object.Name, target)
+					}
+				}
 			}
 
 			nameOnly := filepath.Base(object.Name)
@@ -1749,8 +1968,22 @@ func inspectFile(
 		isKnownCertFile = true
 	}
 
-	if (processorParams.DetectIdentities.Enabled &&
-		sysidentity.IsSourceFile(fullPath)) ||
+	var isDetectorSource bool
+	for _, fds := range processorParams.fileDataSets(pkg) {
+		if fds.isSource(fullPath) {
+			isDetectorSource = true
+			break
+		}
+	}
+
+	isSecretCandidate := processorParams.SecretScanner.IsCandidate(fullPath, object.Size)
+
+	if isDetectorSource ||
+		isSecretCandidate ||
+		(processorParams.DetectIdentities.Enabled &&
+			sysidentity.IsSourceFile(fullPath)) ||
+		(processorParams.DetectPackages &&
+			sbom.IsSourceFile(fullPath)) ||
 		system.IsOSReleaseFile(fullPath) ||
 		system.IsOSShellsFile(fullPath) ||
 		len(changeDataMatchers) > 0 ||
@@ -1769,6 +2002,21 @@ func inspectFile(
 			pkg.IdentityData.AddData(fullPath, data)
 		}
 
+		if processorParams.DetectPackages &&
+			sbom.IsSourceFile(fullPath) {
+			pkg.PackageData.AddData(layer.ID, fullPath, data)
+		}
+
+		for _, fds := range processorParams.fileDataSets(pkg) {
+			if fds.isSource(fullPath) {
+				fds.data.AddData(layer.ID, fullPath, data)
+			}
+		}
+
+		if isSecretCandidate && pkg.SecretData != nil {
+			pkg.SecretData.AddMatches(layer.ID, fullPath, processorParams.SecretScanner.Scan(fullPath, data))
+		}
+
 		if !isKnownCertFile {
 			if processorParams.DetectAllCertFiles {
 				//NOTE:
@@ -0,0 +1,93 @@
+package dockerimage
+
+import (
+	"strings"
+)
+
+// FileDataSet has the detector source file data and symlinks from the image layers
+// (the layers can be processed in any order, so the data is saved for each layer)
+type FileDataSet struct {
+	Layers map[string]*LayerFileData
+}
+
+// LayerFileData has the source file data, the symlinks and the deleted paths from one layer
+type LayerFileData struct {
+	Files   map[string][]byte
+	Links   map[string]string
+	Deleted []string
+}
+
+func NewFileDataSet() *FileDataSet {
+	return &FileDataSet{
+		Layers: map[string]*LayerFileData{},
+	}
+}
+
+func (ref *FileDataSet) layer(layerID string) *LayerFileData {
+	ld, found := ref.Layers[layerID]
+	if !found {
+		ld = &LayerFileData{
+			Files: map[string][]byte{},
+			Links: map[string]string{},
+		}
+		ref.Layers[layerID] = ld
+	}
+
+	return ld
+}
+
+// AddData saves the source file data from the layer
+func (ref *FileDataSet) AddData(layerID string, filePath string, data []byte) {
+	ref.layer(layerID).Files[filePath] = data
+}
+
+// AddLink saves the source symlink (with its target) from the layer
+func (ref *FileDataSet) AddLink(layerID string, filePath string, target string) {
+	ref.layer(layerID).Links[filePath] = target
+}
+
+// RemoveData records the deleted path (file or directory) in the layer
+func (ref *FileDataSet) RemoveData(layerID string, filePath string) {
+	ld := ref.layer(layerID)
+	ld.Deleted = append(ld.Deleted, filePath)
+}
+
+// Files returns the source file data and the symlinks from the image filesystem
+// (applying the layer changes in the layer order)
+func (ref *FileDataSet) Files(layerOrder []string) (map[string][]byte, map[string]string) {
+	files := map[string][]byte{}
+	links := map[string]string{}
+	for _, layerID := range layerOrder {
+		ld, found := ref.Layers[layerID]
+		if !found {
+			continue
+		}
+
+		for _, deleted := range ld.Deleted {
+			prefix := deleted + "/"
+			for fpath := range files {
+				if fpath == deleted || strings.HasPrefix(fpath, prefix) {
+					delete(files, fpath)
+				}
+			}
+
+			for fpath := range links {
+				if fpath == deleted || strings.HasPrefix(fpath, prefix) {
+					delete(links, fpath)
+				}
+			}
+		}
+
+		for fpath, data := range ld.Files {
+			files[fpath] = data
+			delete(links, fpath)
+		}
+
+		for fpath, target := range ld.Links {
+			links[fpath] = target
+			delete(files, fpath)
+		}
+	}
+
+	return files, links
+}
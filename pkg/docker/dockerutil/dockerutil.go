@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -12,10 +13,12 @@ import (
 	"strings"
 	"time"
 
+	dockerapiclient "github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
 	dockerapi "github.com/fsouza/go-dockerclient"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/slimtoolkit/slim/pkg/crt"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerclient"
 	"github.com/slimtoolkit/slim/pkg/util/fsutil"
 )
@@ -46,6 +49,17 @@ type ImageIdentity struct {
 	RepoDigests  []string
 }
 
+// isNilClient returns true if the API client is nil
+// (including the nil Docker API client pointers passed as the API client interface values)
+func isNilClient(client crt.APIClient) bool {
+	if client == nil {
+		return true
+	}
+
+	dclient, ok := client.(*dockerapi.Client)
+	return ok && dclient == nil
+}
+
 func APIImagesToIdentity(info *dockerapi.APIImages) *ImageIdentity {
 	imageInfo := &dockerapi.Image{
 		ID:          info.ID,
@@ -103,7 +117,7 @@ func HasEmptyImage(dclient *dockerapi.Client) error {
 	return err
 }
 
-func HasImage(dclient *dockerapi.Client, imageRef string) (*ImageIdentity, error) {
+func HasImage(dclient crt.APIClient, imageRef string) (*ImageIdentity, error) {
 	//NOTES:
 	//ListImages doesn't filter by image ID (must use ImageInspect instead)
 	//Check images by name:tag, full or partial image ID or name@digest
@@ -112,7 +126,7 @@ func HasImage(dclient *dockerapi.Client, imageRef string) (*ImageIdentity, error
 	}
 
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return nil, err
@@ -141,7 +155,42 @@ func HasImage(dclient *dockerapi.Client, imageRef string) (*ImageIdentity, error
 	return ImageToIdentity(imageInfo), nil
 }
 
-func ListImages(dclient *dockerapi.Client, imageNameFilter string) (map[string]BasicImageProps, error) {
+// ImageVariant returns the CPU architecture variant of the image
+// (the go-dockerclient image info doesn't include the variant,
+// so the image is inspected using the Docker API client
+// configured with the same endpoint and HTTP client, including its TLS settings)
+func ImageVariant(dclient *dockerapi.Client, imageRef string) (string, error) {
+	if dclient == nil || dclient.HTTPClient == nil || imageRef == "" {
+		return "", ErrBadParam
+	}
+
+	//using a copy because the Docker API client wraps the HTTP client transport
+	httpClient := *dclient.HTTPClient
+	client, err := dockerapiclient.NewClientWithOpts(
+		//the host needs to be set before the HTTP client
+		//because it reconfigures the current client transport
+		dockerapiclient.WithHost(dclient.Endpoint()),
+		dockerapiclient.WithHTTPClient(&httpClient),
+		dockerapiclient.WithAPIVersionNegotiation())
+	if err != nil {
+		return "", err
+	}
+
+	//not closing the client because it would close
+	//the idle connections of the shared HTTP client transport
+	imageInfo, _, err := client.ImageInspectWithRaw(context.Background(), imageRef)
+	if err != nil {
+		if dockerapiclient.IsErrNotFound(err) {
+			return "", ErrNotFound
+		}
+
+		return "", err
+	}
+
+	return imageInfo.Variant, nil
+}
+
+func ListImages(dclient crt.APIClient, imageNameFilter string) (map[string]BasicImageProps, error) {
 	// python <- exact match only
 	// py* <- all image names starting with 'py' (no/default namespace)
 	// dslimexamples/* <- all image names in the 'dslimexamples' namespace
@@ -151,7 +200,7 @@ func ListImages(dclient *dockerapi.Client, imageNameFilter string) (map[string]B
 	// */*alpine <- all image names ending with 'alpine' in all namesapces (except the default namespace)
 	// * <- all image names with no/default namespace. note that no images with namespaces will be returned
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return nil, err
@@ -263,13 +312,13 @@ func BuildEmptyImage(dclient *dockerapi.Client) error {
 	return nil
 }
 
-func SaveImage(dclient *dockerapi.Client, imageRef, local string, extract, removeOrig bool) error {
+func SaveImage(dclient crt.APIClient, imageRef, local string, extract, removeOrig bool) error {
 	if local == "" {
 		return ErrBadParam
 	}
 
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return err
@@ -363,13 +412,13 @@ type VolumeInfo struct {
 	FileCount  uint64
 }
 
-func GetVolumeInfo(dclient *dockerapi.Client, name string, fileCount bool) (*VolumeInfo, error) {
+func GetVolumeInfo(dclient crt.APIClient, name string, fileCount bool) (*VolumeInfo, error) {
 	if name == "" {
 		return nil, ErrBadParam
 	}
 
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return nil, err
@@ -400,7 +449,7 @@ func GetVolumeInfo(dclient *dockerapi.Client, name string, fileCount bool) (*Vol
 	return info, nil
 }
 
-func ListVolumeFiles(dclient *dockerapi.Client, name string) ([]string, error) {
+func ListVolumeFiles(dclient crt.APIClient, name string) ([]string, error) {
 	if name == "" {
 		return nil, ErrBadParam
 	}
@@ -408,7 +457,7 @@ func ListVolumeFiles(dclient *dockerapi.Client, name string) ([]string, error) {
 	return nil, nil
 }
 
-func VolumePathExists(dclient *dockerapi.Client, volume string, pth string) (bool, error) {
+func VolumePathExists(dclient crt.APIClient, volume string, pth string) (bool, error) {
 	if volume == "" || pth == "" {
 		return false, ErrBadParam
 	}
@@ -416,13 +465,13 @@ func VolumePathExists(dclient *dockerapi.Client, volume string, pth string) (boo
 	return false, nil
 }
 
-func HasVolume(dclient *dockerapi.Client, name string) error {
+func HasVolume(dclient crt.APIClient, name string) error {
 	if name == "" {
 		return ErrBadParam
 	}
 
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return err
@@ -463,12 +512,12 @@ func HasVolume(dclient *dockerapi.Client, name string) error {
 	return ErrNotFound
 }
 
-func DeleteVolume(dclient *dockerapi.Client, name string) error {
+func DeleteVolume(dclient crt.APIClient, name string) error {
 	if name == "" {
 		return ErrBadParam
 	}
 
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return err
@@ -504,13 +553,13 @@ func DeleteVolume(dclient *dockerapi.Client, name string) error {
 }
 
 func CopyToVolume(
-	dclient *dockerapi.Client,
+	dclient crt.APIClient,
 	volumeName string,
 	source string,
 	dstRootDir string,
 	dstTargetDir string) error {
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return err
@@ -663,7 +712,7 @@ func GenStateDirsTar(rootDir, stateDir string) (io.Reader, error) {
 }
 
 func CreateVolumeWithData(
-	dclient *dockerapi.Client,
+	dclient crt.APIClient,
 	source string,
 	name string,
 	labels map[string]string) error {
@@ -679,7 +728,7 @@ func CreateVolumeWithData(
 	}
 
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return err
@@ -716,13 +765,13 @@ func CreateVolumeWithData(
 	return nil
 }
 
-func CopyFromContainer(dclient *dockerapi.Client, containerID, remote, local string, extract, removeOrig bool) error {
+func CopyFromContainer(dclient crt.APIClient, containerID, remote, local string, extract, removeOrig bool) error {
 	if containerID == "" || remote == "" || local == "" {
 		return ErrBadParam
 	}
 
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return err
@@ -912,9 +961,9 @@ func ListNetworks(dclient *dockerapi.Client, nameFilter string) ([]string, error
 	return names, nil
 }
 
-func ListVolumes(dclient *dockerapi.Client, nameFilter string) ([]string, error) {
+func ListVolumes(dclient crt.APIClient, nameFilter string) ([]string, error) {
 	var err error
-	if dclient == nil {
+	if isNilClient(dclient) {
 		socketInfo, err := dockerclient.GetUnixSocketAddr()
 		if err != nil {
 			return nil, err
@@ -0,0 +1,78 @@
+package dockerutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dockerapi "github.com/fsouza/go-dockerclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDockerHandler serves the image inspect API calls for the known image
+func testDockerHandler(imageID, variant string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", "1.41")
+		if strings.HasSuffix(r.URL.Path, "/_ping") {
+			w.Write([]byte("OK"))
+			return
+		}
+
+		if !strings.HasSuffix(r.URL.Path, "/images/"+imageID+"/json") {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such image"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"Id":           imageID,
+			"Architecture": "arm",
+			"Variant":      variant,
+		})
+	})
+}
+
+func TestImageVariant(t *testing.T) {
+	server := httptest.NewServer(testDockerHandler("app", "v7"))
+	defer server.Close()
+
+	dclient, err := dockerapi.NewClient(strings.Replace(server.URL, "http://", "tcp://", 1))
+	require.NoError(t, err)
+
+	variant, err := ImageVariant(dclient, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "v7", variant)
+
+	_, err = ImageVariant(dclient, "other")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = ImageVariant(dclient, "")
+	assert.ErrorIs(t, err, ErrBadParam)
+
+	_, err = ImageVariant(nil, "app")
+	assert.ErrorIs(t, err, ErrBadParam)
+}
+
+func TestImageVariantTLS(t *testing.T) {
+	server := httptest.NewTLSServer(testDockerHandler("app", "v6"))
+	defer server.Close()
+
+	//the configured client HTTP client (with the TLS settings) needs to be used
+	//because the test server certificate is trusted only by the test server client
+	dclient, err := dockerapi.NewClient(strings.Replace(server.URL, "https://", "tcp://", 1))
+	require.NoError(t, err)
+	dclient.HTTPClient = server.Client()
+
+	variant, err := ImageVariant(dclient, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "v6", variant)
+
+	//the configured client transport is not changed
+	_, isTransport := dclient.HTTPClient.Transport.(*http.Transport)
+	assert.True(t, isTransport)
+}
@@ -2,8 +2,11 @@
 package check
 
 import (
+	docker "github.com/fsouza/go-dockerclient"
+
 	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/spec"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerignore"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
 	"github.com/slimtoolkit/slim/pkg/docker/instruction"
 )
 
@@ -12,6 +15,16 @@ type Context struct {
 	Dockerfile      *spec.Dockerfile
 	BuildContextDir string
 	Dockerignore    *dockerignore.Matcher
+	Image           *ImageTarget
+}
+
+// ImageTarget is the target image data for the image scope checks
+type ImageTarget struct {
+	Ref string
+	//Info is the image metadata (including the image config)
+	Info *docker.Image
+	//Package is the image layer data
+	Package *dockerimage.Package
 }
 
 type Options struct {
@@ -53,11 +66,12 @@ const (
 	ScopeData         = "data"
 	ScopeApp          = "app"
 	ScopeShell        = "shell"
+	ScopeImage        = "image"
 )
 
 //Possible labels:
 //"level" -> "info", "warn", "error", "style"
-//"scope" -> "app", "shell", "instruction", "stage", "dockerfile", "all", "dockerignore", "data"
+//"scope" -> "app", "shell", "instruction", "stage", "dockerfile", "all", "dockerignore", "data", "image"
 //"instruction" -> "list,of,instructions" (negative with !instruction)
 //"app" -> "list,of,app names"
 //"shell" -> "general or specific shell name"
@@ -78,6 +92,9 @@ type Match struct {
 	Stage       *spec.BuildStage   `json:"stage,omitempty"`
 	Instruction *instruction.Field `json:"instruction,omitempty"`
 	Message     string             `json:"message,omitempty"`
+	//image scope match info (the image filesystem path and the image layer index)
+	Path  string `json:"path,omitempty"`
+	Layer *int   `json:"layer,omitempty"`
 }
 
 type Runner interface {
@@ -85,9 +102,4 @@ type Runner interface {
 	Run(opts *Options, ctx *Context) (*Result, error)
 }
 
-//regex-based (with rules)
-//type PolicyCheck struct {
-//	Info
-//}
-
 var AllChecks = []Runner{}
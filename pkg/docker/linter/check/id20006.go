@@ -38,7 +38,8 @@ func (c *StageFromLatest) Run(opts *Options, ctx *Context) (*Result, error) {
 	}
 
 	for _, stage := range ctx.Dockerfile.Stages {
-		if stage.Parent.Name != "" {
+		//'scratch' is the special empty image (it doesn't have tags)
+		if stage.Parent.Name != "" && stage.Parent.Name != "scratch" {
 			if (stage.Parent.Tag == "" || strings.ToLower(stage.Parent.Tag) == "latest") &&
 				stage.Parent.Digest == "" {
 				if !result.Hit {
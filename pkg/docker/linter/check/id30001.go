@@ -0,0 +1,55 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	check := &ImageRootUser{
+		Info: Info{
+			ID:           "ID.30001",
+			Name:         "Image runs as root",
+			Description:  "Image runs as root (no non-root user in the image config)",
+			DetailsURL:   "https://lint.dockersl.im/check/ID.30001",
+			MainMessage:  "Image runs as root",
+			MatchMessage: "Image user: '%s'",
+			Labels: map[string]string{
+				LabelLevel: LevelWarn,
+				LabelScope: ScopeImage,
+			},
+		},
+	}
+
+	AllChecks = append(AllChecks, check)
+}
+
+type ImageRootUser struct {
+	Info
+}
+
+func (c *ImageRootUser) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	if ctx.Image == nil || ctx.Image.Info == nil || ctx.Image.Info.Config == nil {
+		return result, nil
+	}
+
+	user := ctx.Image.Info.Config.User
+	name, _, _ := strings.Cut(user, ":")
+	if name == "" || name == "root" || name == "0" {
+		result.Hit = true
+		result.Message = c.MainMessage
+		result.Matches = append(result.Matches, &Match{
+			Message: fmt.Sprintf(c.MatchMessage, user),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,127 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	check := &ImageSecretFiles{
+		Info: Info{
+			ID:           "ID.30002",
+			Name:         "Secret files in image",
+			Description:  "Image has files that usually have secrets (credentials, tokens, keystores)",
+			DetailsURL:   "https://lint.dockersl.im/check/ID.30002",
+			MainMessage:  "Secret files in image",
+			MatchMessage: "Secret file: path='%s' layer=%d",
+			Labels: map[string]string{
+				LabelLevel: LevelError,
+				LabelScope: ScopeImage,
+			},
+		},
+	}
+
+	AllChecks = append(AllChecks, check)
+}
+
+var secretFilePathSuffixes = []string{
+	"/.aws/credentials",
+	"/.docker/config.json",
+	"/.kube/config",
+	"/.git-credentials",
+	"/.netrc",
+	"/.pgpass",
+	"/.vault-token",
+	"/.ssh/id_rsa",
+	"/.ssh/id_dsa",
+	"/.ssh/id_ecdsa",
+	"/.ssh/id_ed25519",
+	"/.config/gcloud/credentials.db",
+	"/.config/gcloud/application_default_credentials.json",
+}
+
+var secretFileNames = map[string]struct{}{
+	".env":      {},
+	".htpasswd": {},
+}
+
+// the keystore files are reported only outside of the system directories
+// (the system packages can have test or default keystores)
+var secretFileExtensions = map[string]struct{}{
+	".p12":      {},
+	".pfx":      {},
+	".jks":      {},
+	".keystore": {},
+}
+
+var systemDirPrefixes = []string{
+	"/usr/",
+	"/lib/",
+	"/lib64/",
+	"/etc/ssl/",
+	"/etc/pki/",
+}
+
+type ImageSecretFiles struct {
+	Info
+}
+
+func (c *ImageSecretFiles) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	if ctx.Image == nil || ctx.Image.Package == nil {
+		return result, nil
+	}
+
+	objects := ctx.Image.Package.FinalObjects()
+	for _, name := range imageObjectNames(objects) {
+		if !isSecretFilePath(name) {
+			continue
+		}
+
+		object := objects[name]
+		if !result.Hit {
+			result.Hit = true
+			result.Message = c.MainMessage
+		}
+
+		result.Matches = append(result.Matches, &Match{
+			Message: fmt.Sprintf(c.MatchMessage, name, object.LayerIndex),
+			Path:    name,
+			Layer:   layerRef(object.LayerIndex),
+		})
+	}
+
+	return result, nil
+}
+
+func isSecretFilePath(fpath string) bool {
+	for _, suffix := range secretFilePathSuffixes {
+		if strings.HasSuffix(fpath, suffix) {
+			return true
+		}
+	}
+
+	if _, found := secretFileNames[path.Base(fpath)]; found {
+		return true
+	}
+
+	if _, found := secretFileExtensions[strings.ToLower(path.Ext(fpath))]; found {
+		for _, prefix := range systemDirPrefixes {
+			if strings.HasPrefix(fpath, prefix) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
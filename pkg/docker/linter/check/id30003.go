@@ -0,0 +1,99 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	check := &ImageSecretEnvVars{
+		Info: Info{
+			ID:           "ID.30003",
+			Name:         "Secrets in image environment",
+			Description:  "Image config has environment variables that look like secrets",
+			DetailsURL:   "https://lint.dockersl.im/check/ID.30003",
+			MainMessage:  "Secrets in image environment variables",
+			MatchMessage: "Environment variable: name='%s'",
+			Labels: map[string]string{
+				LabelLevel: LevelError,
+				LabelScope: ScopeImage,
+			},
+		},
+	}
+
+	AllChecks = append(AllChecks, check)
+}
+
+var secretEnvNameSuffixes = []string{
+	"PASSWORD",
+	"PASSWD",
+	"SECRET",
+	"TOKEN",
+	"API_KEY",
+	"APIKEY",
+	"ACCESS_KEY",
+	"PRIVATE_KEY",
+	"SECRET_KEY",
+	"CREDENTIALS",
+}
+
+// the variables that reference the secrets (but don't have them)
+var secretEnvRefSuffixes = []string{
+	"_FILE",
+	"_PATH",
+	"_DIR",
+}
+
+type ImageSecretEnvVars struct {
+	Info
+}
+
+func (c *ImageSecretEnvVars) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	if ctx.Image == nil || ctx.Image.Info == nil || ctx.Image.Info.Config == nil {
+		return result, nil
+	}
+
+	for _, env := range ctx.Image.Info.Config.Env {
+		name, value, _ := strings.Cut(env, "=")
+		if value == "" || !isSecretEnvName(name) {
+			continue
+		}
+
+		if !result.Hit {
+			result.Hit = true
+			result.Message = c.MainMessage
+		}
+
+		//not including the value (it's a secret)
+		result.Matches = append(result.Matches, &Match{
+			Message: fmt.Sprintf(c.MatchMessage, name),
+		})
+	}
+
+	return result, nil
+}
+
+func isSecretEnvName(name string) bool {
+	name = strings.ToUpper(name)
+	for _, suffix := range secretEnvRefSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+
+	for _, suffix := range secretEnvNameSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	check := &ImagePrivateKeys{
+		Info: Info{
+			ID:           "ID.30004",
+			Name:         "Private keys in image",
+			Description:  "Image layers have private key files",
+			DetailsURL:   "https://lint.dockersl.im/check/ID.30004",
+			MainMessage:  "Private keys in image",
+			MatchMessage: "Private key: path='%s'",
+			Labels: map[string]string{
+				LabelLevel: LevelError,
+				LabelScope: ScopeImage,
+			},
+		},
+	}
+
+	AllChecks = append(AllChecks, check)
+}
+
+type ImagePrivateKeys struct {
+	Info
+}
+
+func (c *ImagePrivateKeys) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	if ctx.Image == nil || ctx.Image.Package == nil {
+		return result, nil
+	}
+
+	//the private keys are reported even if they are deleted in the later layers
+	//because they are still in the image layer where they were added
+	var keys []string
+	for fpath := range ctx.Image.Package.Certs.PrivateKeys {
+		keys = append(keys, fpath)
+	}
+
+	for fpath := range ctx.Image.Package.CACerts.PrivateKeys {
+		keys = append(keys, fpath)
+	}
+
+	sort.Strings(keys)
+	for _, fpath := range keys {
+		if !result.Hit {
+			result.Hit = true
+			result.Message = c.MainMessage
+		}
+
+		result.Matches = append(result.Matches, &Match{
+			Message: fmt.Sprintf(c.MatchMessage, fpath),
+			Path:    fpath,
+		})
+	}
+
+	return result, nil
+}
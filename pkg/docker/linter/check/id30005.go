@@ -0,0 +1,69 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	check := &ImageSetuidBinaries{
+		Info: Info{
+			ID:           "ID.30005",
+			Name:         "Setuid binaries in image",
+			Description:  "Image has setuid binaries (potential privilege escalation)",
+			DetailsURL:   "https://lint.dockersl.im/check/ID.30005",
+			MainMessage:  "Setuid binaries in image",
+			MatchMessage: "Setuid binary: path='%s' mode=%s uid=%d layer=%d",
+			Labels: map[string]string{
+				LabelLevel: LevelWarn,
+				LabelScope: ScopeImage,
+			},
+		},
+	}
+
+	AllChecks = append(AllChecks, check)
+}
+
+type ImageSetuidBinaries struct {
+	Info
+}
+
+func (c *ImageSetuidBinaries) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	if ctx.Image == nil || ctx.Image.Package == nil {
+		return result, nil
+	}
+
+	//only the setuid binaries that are still in the image filesystem
+	objects := ctx.Image.Package.FinalObjects()
+	var names []string
+	for name := range ctx.Image.Package.SpecialPermRefs.Setuid {
+		if _, found := objects[name]; found {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		object := objects[name]
+		if !result.Hit {
+			result.Hit = true
+			result.Message = c.MainMessage
+		}
+
+		result.Matches = append(result.Matches, &Match{
+			Message: fmt.Sprintf(c.MatchMessage, name, object.Mode, object.UID, object.LayerIndex),
+			Path:    name,
+			Layer:   layerRef(object.LayerIndex),
+		})
+	}
+
+	return result, nil
+}
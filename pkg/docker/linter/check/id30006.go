@@ -0,0 +1,141 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	check := &ImagePackageManagerCache{
+		Info: Info{
+			ID:           "ID.30006",
+			Name:         "Package manager cache in image",
+			Description:  "Image has package manager caches or package indexes left behind",
+			DetailsURL:   "https://lint.dockersl.im/check/ID.30006",
+			MainMessage:  "Package manager cache in image",
+			MatchMessage: "Package manager cache: path='%s' files=%d size=%s",
+			Labels: map[string]string{
+				LabelLevel: LevelWarn,
+				LabelScope: ScopeImage,
+			},
+		},
+	}
+
+	AllChecks = append(AllChecks, check)
+}
+
+// package manager cache directories
+// (the '*' path element matches any directory name, e.g., user home directories)
+var packageManagerCacheDirs = []string{
+	"/var/cache/apt/archives",
+	"/var/lib/apt/lists",
+	"/var/cache/apk",
+	"/var/cache/yum",
+	"/var/cache/dnf",
+	"/var/cache/zypp",
+	"/root/.cache/pip",
+	"/home/*/.cache/pip",
+	"/root/.npm/_cacache",
+	"/home/*/.npm/_cacache",
+	"/usr/local/share/.cache/yarn",
+	"/root/.cache/yarn",
+	"/root/.cache/go-build",
+	"/root/.composer/cache",
+	"/root/.cargo/registry/cache",
+	"/root/.m2/repository",
+	"/root/.gradle/caches",
+}
+
+// files that are expected in the cache directories
+var packageManagerCacheIgnore = map[string]struct{}{
+	"lock": {},
+}
+
+type ImagePackageManagerCache struct {
+	Info
+}
+
+type cacheDirInfo struct {
+	path  string
+	files int
+	size  int64
+}
+
+func (c *ImagePackageManagerCache) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	if ctx.Image == nil || ctx.Image.Package == nil {
+		return result, nil
+	}
+
+	var caches []*cacheDirInfo
+	cacheByDir := map[string]*cacheDirInfo{}
+	objects := ctx.Image.Package.FinalObjects()
+	for _, name := range imageObjectNames(objects) {
+		if _, found := packageManagerCacheIgnore[path.Base(name)]; found {
+			continue
+		}
+
+		dir := packageManagerCacheDir(name)
+		if dir == "" {
+			continue
+		}
+
+		info, found := cacheByDir[dir]
+		if !found {
+			info = &cacheDirInfo{path: dir}
+			cacheByDir[dir] = info
+			caches = append(caches, info)
+		}
+
+		info.files++
+		info.size += objects[name].Size
+	}
+
+	for _, info := range caches {
+		if !result.Hit {
+			result.Hit = true
+			result.Message = c.MainMessage
+		}
+
+		result.Matches = append(result.Matches, &Match{
+			Message: fmt.Sprintf(c.MatchMessage, info.path, info.files, humanize.Bytes(uint64(info.size))),
+			Path:    info.path,
+		})
+	}
+
+	return result, nil
+}
+
+// packageManagerCacheDir returns the cache directory for the file path (if it's in one)
+func packageManagerCacheDir(fpath string) string {
+	parts := strings.Split(fpath, "/")
+	for _, dir := range packageManagerCacheDirs {
+		dirParts := strings.Split(dir, "/")
+		if len(parts) <= len(dirParts) {
+			continue
+		}
+
+		matched := true
+		for idx, part := range dirParts {
+			if part != "*" && part != parts[idx] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return strings.Join(parts[:len(dirParts)], "/")
+		}
+	}
+
+	return ""
+}
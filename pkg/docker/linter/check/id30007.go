@@ -0,0 +1,68 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	check := &ImageHugeLayer{
+		Info: Info{
+			ID:           "ID.30007",
+			Name:         "Huge image layer",
+			Description:  "Image has huge layers",
+			DetailsURL:   "https://lint.dockersl.im/check/ID.30007",
+			MainMessage:  "Huge image layers",
+			MatchMessage: "Layer: index=%d id=%s size=%s files=%d",
+			Labels: map[string]string{
+				LabelLevel: LevelInfo,
+				LabelScope: ScopeImage,
+			},
+		},
+	}
+
+	AllChecks = append(AllChecks, check)
+}
+
+// HugeLayerSize is the layer data size that is considered to be huge
+const HugeLayerSize = 256 * 1024 * 1024
+
+type ImageHugeLayer struct {
+	Info
+}
+
+func (c *ImageHugeLayer) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	if ctx.Image == nil || ctx.Image.Package == nil {
+		return result, nil
+	}
+
+	for _, layer := range ctx.Image.Package.Layers {
+		if layer.Stats.AllSize < HugeLayerSize {
+			continue
+		}
+
+		if !result.Hit {
+			result.Hit = true
+			result.Message = c.MainMessage
+		}
+
+		result.Matches = append(result.Matches, &Match{
+			Message: fmt.Sprintf(c.MatchMessage,
+				layer.Index,
+				layer.ID,
+				humanize.Bytes(layer.Stats.AllSize),
+				layer.Stats.FileCount),
+			Layer: layerRef(layer.Index),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,47 @@
+// Package check contains the linter checks
+package check
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	check := &ImageNoHealthcheck{
+		Info: Info{
+			ID:          "ID.30008",
+			Name:        "Missing image healthcheck",
+			Description: "Image config doesn't have a healthcheck",
+			DetailsURL:  "https://lint.dockersl.im/check/ID.30008",
+			MainMessage: "No healthcheck in image config",
+			Labels: map[string]string{
+				LabelLevel: LevelInfo,
+				LabelScope: ScopeImage,
+			},
+		},
+	}
+
+	AllChecks = append(AllChecks, check)
+}
+
+type ImageNoHealthcheck struct {
+	Info
+}
+
+func (c *ImageNoHealthcheck) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	if ctx.Image == nil || ctx.Image.Info == nil || ctx.Image.Info.Config == nil {
+		return result, nil
+	}
+
+	hc := ctx.Image.Info.Config.Healthcheck
+	if hc == nil || len(hc.Test) == 0 || hc.Test[0] == "NONE" {
+		result.Hit = true
+		result.Message = c.MainMessage
+	}
+
+	return result, nil
+}
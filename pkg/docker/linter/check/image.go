@@ -0,0 +1,28 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"sort"
+
+	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
+)
+
+// imageObjectNames returns the sorted names of the image objects
+// (so the image check matches are reported in a stable order)
+func imageObjectNames(objects map[string]*dockerimage.ObjectMetadata) []string {
+	var names []string
+	for name := range objects {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func layerRef(index int) *int {
+	if index < 0 {
+		return nil
+	}
+
+	return &index
+}
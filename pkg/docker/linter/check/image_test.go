@@ -0,0 +1,329 @@
+package check
+
+import (
+	"os"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
+)
+
+func findCheck(t *testing.T, id string) Runner {
+	for _, c := range AllChecks {
+		if c.Get().ID == id {
+			return c
+		}
+	}
+
+	require.FailNow(t, "check not found", id)
+	return nil
+}
+
+func runImageCheck(t *testing.T, id string, target *ImageTarget) *Result {
+	result, err := findCheck(t, id).Run(&Options{}, &Context{Image: target})
+	require.NoError(t, err, id)
+	return result
+}
+
+func configTarget(config *docker.Config) *ImageTarget {
+	return &ImageTarget{Ref: "app:latest", Info: &docker.Image{Config: config}}
+}
+
+func fileObject(name string, size int64, mode os.FileMode) *dockerimage.ObjectMetadata {
+	return &dockerimage.ObjectMetadata{
+		Change: dockerimage.ChangeAdd,
+		Name:   name,
+		Size:   size,
+		Mode:   mode,
+	}
+}
+
+func deletedObject(name string) *dockerimage.ObjectMetadata {
+	return &dockerimage.ObjectMetadata{
+		Change: dockerimage.ChangeDelete,
+		Name:   name,
+	}
+}
+
+func packageTarget(layers ...[]*dockerimage.ObjectMetadata) *ImageTarget {
+	pkg := &dockerimage.Package{}
+	for idx, objects := range layers {
+		for _, object := range objects {
+			object.LayerIndex = idx
+		}
+
+		pkg.Layers = append(pkg.Layers, &dockerimage.Layer{Index: idx, Objects: objects})
+	}
+
+	return &ImageTarget{Ref: "app:latest", Package: pkg}
+}
+
+func matchPaths(result *Result) []string {
+	var paths []string
+	for _, m := range result.Matches {
+		paths = append(paths, m.Path)
+	}
+
+	return paths
+}
+
+func TestImageChecksNoImageData(t *testing.T) {
+	for _, id := range []string{
+		"ID.30001", "ID.30002", "ID.30003", "ID.30004",
+		"ID.30005", "ID.30006", "ID.30007", "ID.30008",
+	} {
+		for _, target := range []*ImageTarget{nil, {Ref: "app:latest"}} {
+			result := runImageCheck(t, id, target)
+			assert.False(t, result.Hit, id)
+			assert.Empty(t, result.Matches, id)
+		}
+	}
+}
+
+func TestImageRootUser(t *testing.T) {
+	tt := []struct {
+		user string
+		hit  bool
+	}{
+		{user: "", hit: true},
+		{user: "root", hit: true},
+		{user: "0", hit: true},
+		{user: "0:0", hit: true},
+		{user: "root:app", hit: true},
+		{user: "app"},
+		{user: "1000:1000"},
+		{user: "nobody:root"},
+	}
+
+	for _, test := range tt {
+		result := runImageCheck(t, "ID.30001", configTarget(&docker.Config{User: test.user}))
+		assert.Equal(t, test.hit, result.Hit, test.user)
+		if test.hit {
+			require.Len(t, result.Matches, 1)
+			assert.Equal(t, "Image user: '"+test.user+"'", result.Matches[0].Message)
+		}
+	}
+}
+
+func TestIsSecretFilePath(t *testing.T) {
+	tt := map[string]bool{
+		"/root/.aws/credentials":                true,
+		"/home/app/.docker/config.json":         true,
+		"/root/.ssh/id_ed25519":                 true,
+		"/app/.env":                             true,
+		"/etc/nginx/.htpasswd":                  true,
+		"/app/certs/server.p12":                 true,
+		"/opt/app/KEYS.JKS":                     true,
+		"/usr/lib/jvm/lib/security/cacerts.jks": false,
+		"/etc/pki/java/cacerts.keystore":        false,
+		"/root/.ssh/id_ed25519.pub":             false,
+		"/root/.ssh/known_hosts":                false,
+		"/app/.env.example":                     false,
+		"/app/config.json":                      false,
+	}
+
+	for fpath, expected := range tt {
+		assert.Equal(t, expected, isSecretFilePath(fpath), fpath)
+	}
+}
+
+func TestImageSecretFiles(t *testing.T) {
+	target := packageTarget(
+		[]*dockerimage.ObjectMetadata{
+			fileObject("/root/.netrc", 10, 0600),
+			fileObject("/root/.aws/credentials", 10, 0600),
+			fileObject("/app/main.go", 10, 0644),
+		},
+		[]*dockerimage.ObjectMetadata{
+			deletedObject("/root/.aws/credentials"),
+			fileObject("/app/.env", 10, 0644),
+		},
+	)
+
+	result := runImageCheck(t, "ID.30002", target)
+	assert.True(t, result.Hit)
+	assert.Equal(t, "Secret files in image", result.Message)
+	assert.Equal(t, []string{"/app/.env", "/root/.netrc"}, matchPaths(result))
+	require.NotNil(t, result.Matches[0].Layer)
+	assert.Equal(t, 1, *result.Matches[0].Layer)
+	assert.Equal(t, "Secret file: path='/root/.netrc' layer=0", result.Matches[1].Message)
+}
+
+func TestIsSecretEnvName(t *testing.T) {
+	tt := map[string]bool{
+		"DB_PASSWORD":           true,
+		"db_password":           true,
+		"GITHUB_TOKEN":          true,
+		"STRIPE_API_KEY":        true,
+		"AWS_SECRET_ACCESS_KEY": true,
+		"JWT_SECRET":            true,
+		"DB_PASSWORD_FILE":      false,
+		"TOKEN_PATH":            false,
+		"SECRETS_DIR":           false,
+		"PATH":                  false,
+		"TOKENIZER_MODEL":       false,
+	}
+
+	for name, expected := range tt {
+		assert.Equal(t, expected, isSecretEnvName(name), name)
+	}
+}
+
+func TestImageSecretEnvVars(t *testing.T) {
+	target := configTarget(&docker.Config{
+		Env: []string{
+			"PATH=/usr/local/bin:/usr/bin",
+			"DB_PASSWORD=hunter2",
+			"API_TOKEN=",
+			"DB_PASSWORD_FILE=/run/secrets/db",
+			"GITHUB_TOKEN",
+			"APP_SECRET=s3cr3t=value",
+		},
+	})
+
+	result := runImageCheck(t, "ID.30003", target)
+	assert.True(t, result.Hit)
+	require.Len(t, result.Matches, 2)
+	assert.Equal(t, "Environment variable: name='DB_PASSWORD'", result.Matches[0].Message)
+	assert.Equal(t, "Environment variable: name='APP_SECRET'", result.Matches[1].Message)
+	for _, m := range result.Matches {
+		assert.NotContains(t, m.Message, "hunter2")
+		assert.NotContains(t, m.Message, "s3cr3t")
+	}
+
+	result = runImageCheck(t, "ID.30003", configTarget(&docker.Config{Env: []string{"HOME=/root"}}))
+	assert.False(t, result.Hit)
+}
+
+func TestImagePrivateKeys(t *testing.T) {
+	target := packageTarget()
+	target.Package.Certs.PrivateKeys = map[string]struct{}{
+		"/etc/ssl/private/server.key": {},
+		"/app/tls/key.pem":            {},
+	}
+	target.Package.CACerts.PrivateKeys = map[string]struct{}{
+		"/etc/pki/ca/ca.key": {},
+	}
+
+	result := runImageCheck(t, "ID.30004", target)
+	assert.True(t, result.Hit)
+	assert.Equal(t, []string{
+		"/app/tls/key.pem",
+		"/etc/pki/ca/ca.key",
+		"/etc/ssl/private/server.key",
+	}, matchPaths(result))
+	assert.Equal(t, "Private key: path='/app/tls/key.pem'", result.Matches[0].Message)
+
+	result = runImageCheck(t, "ID.30004", packageTarget())
+	assert.False(t, result.Hit)
+}
+
+func TestImageSetuidBinaries(t *testing.T) {
+	su := fileObject("/bin/su", 100, os.ModeSetuid|0755)
+	mount := fileObject("/bin/mount", 100, os.ModeSetuid|0755)
+	target := packageTarget(
+		[]*dockerimage.ObjectMetadata{su, mount},
+		[]*dockerimage.ObjectMetadata{deletedObject("/bin/mount")},
+	)
+	target.Package.SpecialPermRefs.Setuid = map[string]*dockerimage.ObjectMetadata{
+		su.Name:    su,
+		mount.Name: mount,
+	}
+
+	result := runImageCheck(t, "ID.30005", target)
+	assert.True(t, result.Hit)
+	assert.Equal(t, []string{"/bin/su"}, matchPaths(result))
+	assert.Equal(t, "Setuid binary: path='/bin/su' mode=urwxr-xr-x uid=0 layer=0", result.Matches[0].Message)
+	require.NotNil(t, result.Matches[0].Layer)
+	assert.Equal(t, 0, *result.Matches[0].Layer)
+}
+
+func TestPackageManagerCacheDir(t *testing.T) {
+	tt := map[string]string{
+		"/var/cache/apt/archives/curl.deb":        "/var/cache/apt/archives",
+		"/var/lib/apt/lists/deb.debian.org_dists": "/var/lib/apt/lists",
+		"/home/app/.cache/pip/http/a/b":           "/home/app/.cache/pip",
+		"/root/.m2/repository/org/x.jar":          "/root/.m2/repository",
+		"/var/cache/apt/archives":                 "",
+		"/var/cache/apt/pkgcache.bin":             "",
+		"/home/.cache/pip/x":                      "",
+		"/usr/bin/pip":                            "",
+	}
+
+	for fpath, expected := range tt {
+		assert.Equal(t, expected, packageManagerCacheDir(fpath), fpath)
+	}
+}
+
+func TestImagePackageManagerCache(t *testing.T) {
+	target := packageTarget(
+		[]*dockerimage.ObjectMetadata{
+			fileObject("/var/lib/apt/lists/lock", 0, 0640),
+			fileObject("/var/lib/apt/lists/a_Packages", 1000, 0644),
+			fileObject("/var/lib/apt/lists/b_Packages", 500, 0644),
+			fileObject("/var/cache/apk/APKINDEX.tar.gz", 2000, 0644),
+			fileObject("/var/cache/apt/archives/lock", 0, 0640),
+			fileObject("/app/main", 5000, 0755),
+		},
+	)
+
+	result := runImageCheck(t, "ID.30006", target)
+	assert.True(t, result.Hit)
+	assert.Equal(t, []string{"/var/cache/apk", "/var/lib/apt/lists"}, matchPaths(result))
+	assert.Equal(t, "Package manager cache: path='/var/lib/apt/lists' files=2 size=1.5 kB", result.Matches[1].Message)
+
+	result = runImageCheck(t, "ID.30006", packageTarget(
+		[]*dockerimage.ObjectMetadata{fileObject("/var/lib/apt/lists/a_Packages", 1000, 0644)},
+		[]*dockerimage.ObjectMetadata{deletedObject("/var/lib/apt/lists/*")},
+	))
+	assert.False(t, result.Hit)
+}
+
+func TestImageHugeLayer(t *testing.T) {
+	target := packageTarget()
+	target.Package.Layers = []*dockerimage.Layer{
+		{Index: 0, ID: "base", Stats: dockerimage.LayerStats{AllSize: 80 * 1024 * 1024, FileCount: 3000}},
+		{Index: 1, ID: "deps", Stats: dockerimage.LayerStats{AllSize: HugeLayerSize, FileCount: 12000}},
+		{Index: 2, ID: "app", Stats: dockerimage.LayerStats{AllSize: 1024, FileCount: 1}},
+	}
+
+	result := runImageCheck(t, "ID.30007", target)
+	assert.True(t, result.Hit)
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "Layer: index=1 id=deps size=268 MB files=12000", result.Matches[0].Message)
+	require.NotNil(t, result.Matches[0].Layer)
+	assert.Equal(t, 1, *result.Matches[0].Layer)
+
+	target.Package.Layers = target.Package.Layers[:1]
+	result = runImageCheck(t, "ID.30007", target)
+	assert.False(t, result.Hit)
+}
+
+func TestImageNoHealthcheck(t *testing.T) {
+	tt := []struct {
+		name        string
+		healthcheck *docker.HealthConfig
+		hit         bool
+	}{
+		{name: "none", hit: true},
+		{name: "empty", healthcheck: &docker.HealthConfig{}, hit: true},
+		{name: "disabled", healthcheck: &docker.HealthConfig{Test: []string{"NONE"}}, hit: true},
+		{name: "cmd", healthcheck: &docker.HealthConfig{Test: []string{"CMD", "/app/healthcheck"}}},
+		{name: "shell", healthcheck: &docker.HealthConfig{Test: []string{"CMD-SHELL", "curl -f localhost"}}},
+	}
+
+	for _, test := range tt {
+		result := runImageCheck(t, "ID.30008", configTarget(&docker.Config{Healthcheck: test.healthcheck}))
+		assert.Equal(t, test.hit, result.Hit, test.name)
+		assert.Empty(t, result.Matches, test.name)
+	}
+}
+
+func TestLayerRef(t *testing.T) {
+	assert.Nil(t, layerRef(-1))
+	require.NotNil(t, layerRef(0))
+	assert.Equal(t, 3, *layerRef(3))
+}
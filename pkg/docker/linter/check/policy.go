@@ -0,0 +1,445 @@
+// Package check contains the linter checks
+package check
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/spec"
+	"github.com/slimtoolkit/slim/pkg/docker/instruction"
+)
+
+// Policy rule errors
+var (
+	ErrNoPolicyRules        = errors.New("no policy rules")
+	ErrMissingPolicyRuleID  = errors.New("missing policy rule ID")
+	ErrDuplicatePolicyRule  = errors.New("duplicate policy rule ID")
+	ErrInvalidPolicyLevel   = errors.New("invalid policy rule level")
+	ErrEmptyPolicyRuleMatch = errors.New("empty policy rule match")
+)
+
+// LabelSource is the label for the user-defined (policy) checks ("source:policy")
+const (
+	LabelSource  = "source"
+	SourcePolicy = "policy"
+)
+
+// PolicyFile is the user-defined lint rule file (YAML or JSON)
+//
+// Example:
+//
+//	rules:
+//	- id: ORG.001
+//	  name: Unapproved base image registry
+//	  level: error
+//	  message: Base image is not from an approved registry
+//	  labels:
+//	    team: platform
+//	  match:
+//	    base_image:
+//	      allowed:
+//	      - '^registry\.example\.com/'
+//	- id: ORG.002
+//	  name: Missing source label
+//	  level: warn
+//	  message: Final stage has no 'org.opencontainers.image.source' label
+//	  match:
+//	    stage:
+//	      last: true
+//	    instructions: [label]
+//	    args: ['org\.opencontainers\.image\.source=']
+//	    absent: true
+type PolicyFile struct {
+	Rules []*PolicyRule `json:"rules"`
+}
+
+// PolicyRule is a declarative lint rule
+type PolicyRule struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Level       string            `json:"level,omitempty"` //default: warn
+	Message     string            `json:"message,omitempty"`
+	DetailsURL  string            `json:"details_url,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Match       PolicyMatch       `json:"match"`
+}
+
+// PolicyMatch describes what the rule matches
+// (all configured conditions must match).
+// The stage and base image conditions select the stages.
+// The instruction conditions select the instructions in the selected stages.
+// If there are no instruction conditions the selected stages are the rule matches.
+type PolicyMatch struct {
+	//Instructions are the instruction names to match (any instruction if empty)
+	Instructions []string `json:"instructions,omitempty"`
+	//Args are the regular expressions that all must match the instruction arguments
+	Args []string `json:"args,omitempty"`
+	//NotArgs are the regular expressions that must not match the instruction arguments
+	NotArgs []string `json:"not_args,omitempty"`
+	//Absent makes the rule match the selected stages with no matching instructions
+	Absent    bool              `json:"absent,omitempty"`
+	Stage     *PolicyStageMatch `json:"stage,omitempty"`
+	BaseImage *PolicyBaseImage  `json:"base_image,omitempty"`
+}
+
+// PolicyStageMatch selects the stages
+type PolicyStageMatch struct {
+	//Name is the regular expression for the stage name (unnamed stages have an empty name)
+	Name string `json:"name,omitempty"`
+	//Last selects only the last stage (the stage for the final image)
+	Last bool `json:"last,omitempty"`
+}
+
+// PolicyBaseImage selects the stages by their base image reference
+// (the stages based on other stages are not selected)
+type PolicyBaseImage struct {
+	//Allowed selects the stages with base images that don't match any of the regular expressions
+	Allowed []string `json:"allowed,omitempty"`
+	//Denied selects the stages with base images that match any of the regular expressions
+	Denied []string `json:"denied,omitempty"`
+	//RequireDigest selects the stages with base images without a digest
+	RequireDigest bool `json:"require_digest,omitempty"`
+}
+
+// PolicyCheck runs a user-defined policy rule
+type PolicyCheck struct {
+	Info
+	Rule *PolicyRule
+
+	instructions map[string]struct{}
+	args         []*regexp.Regexp
+	notArgs      []*regexp.Regexp
+	stageName    *regexp.Regexp
+	allowed      []*regexp.Regexp
+	denied       []*regexp.Regexp
+}
+
+const (
+	policyInstructionMatchMessage = "Instruction: start=%d end=%d name='%s' global_index=%d stage_id=%d stage_index=%d"
+	policyStageMatchMessage       = "Stage: index=%d name='%s' start=%d end=%d parent='%s'"
+)
+
+// LoadPolicyChecks loads the policy rules from the rule files
+func LoadPolicyChecks(filePaths []string) ([]*PolicyCheck, error) {
+	var checks []*PolicyCheck
+	ids := map[string]string{}
+	for _, runner := range AllChecks {
+		ids[runner.Get().ID] = "built-in checks"
+	}
+
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		var policy PolicyFile
+		if err := yaml.Unmarshal(data, &policy); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+
+		for _, rule := range policy.Rules {
+			if rule == nil {
+				continue
+			}
+
+			if rule.ID == "" {
+				return nil, fmt.Errorf("%s: %w", filePath, ErrMissingPolicyRuleID)
+			}
+
+			if source, found := ids[rule.ID]; found {
+				return nil, fmt.Errorf("%s: %w - %s (%s)", filePath, ErrDuplicatePolicyRule, rule.ID, source)
+			}
+
+			pc, err := NewPolicyCheck(rule)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", filePath, err)
+			}
+
+			ids[rule.ID] = filePath
+			checks = append(checks, pc)
+		}
+	}
+
+	if len(checks) == 0 {
+		return nil, ErrNoPolicyRules
+	}
+
+	return checks, nil
+}
+
+// NewPolicyCheck creates a new policy check for the rule
+func NewPolicyCheck(rule *PolicyRule) (*PolicyCheck, error) {
+	level := strings.ToLower(rule.Level)
+	switch level {
+	case "":
+		level = LevelWarn
+	case LevelFatal, LevelError, LevelWarn, LevelInfo, LevelStyle:
+	default:
+		return nil, fmt.Errorf("%w - %s (%s)", ErrInvalidPolicyLevel, rule.Level, rule.ID)
+	}
+
+	match := rule.Match
+	if len(match.Instructions) == 0 &&
+		len(match.Args) == 0 &&
+		len(match.NotArgs) == 0 &&
+		match.Stage == nil &&
+		match.BaseImage == nil {
+		return nil, fmt.Errorf("%w (%s)", ErrEmptyPolicyRuleMatch, rule.ID)
+	}
+
+	name := rule.Name
+	if name == "" {
+		name = rule.ID
+	}
+
+	message := rule.Message
+	if message == "" {
+		message = name
+	}
+
+	description := rule.Description
+	if description == "" {
+		description = name
+	}
+
+	//the policy rules are stage scope checks (they run against the Dockerfile)
+	labels := map[string]string{}
+	for k, v := range rule.Labels {
+		labels[k] = v
+	}
+
+	labels[LabelLevel] = level
+	labels[LabelScope] = ScopeStage
+	labels[LabelSource] = SourcePolicy
+
+	pc := &PolicyCheck{
+		Info: Info{
+			ID:           rule.ID,
+			Name:         name,
+			Description:  description,
+			DetailsURL:   rule.DetailsURL,
+			MainMessage:  message,
+			MatchMessage: policyInstructionMatchMessage,
+			Labels:       labels,
+		},
+		Rule:         rule,
+		instructions: map[string]struct{}{},
+	}
+
+	for _, name := range match.Instructions {
+		pc.instructions[strings.ToLower(name)] = struct{}{}
+	}
+
+	var err error
+	if pc.args, err = compilePolicyPatterns(rule.ID, match.Args); err != nil {
+		return nil, err
+	}
+
+	if pc.notArgs, err = compilePolicyPatterns(rule.ID, match.NotArgs); err != nil {
+		return nil, err
+	}
+
+	if match.Stage != nil && match.Stage.Name != "" {
+		if pc.stageName, err = regexp.Compile(match.Stage.Name); err != nil {
+			return nil, fmt.Errorf("invalid stage name pattern (%s): %w", rule.ID, err)
+		}
+	}
+
+	if match.BaseImage != nil {
+		if pc.allowed, err = compilePolicyPatterns(rule.ID, match.BaseImage.Allowed); err != nil {
+			return nil, err
+		}
+
+		if pc.denied, err = compilePolicyPatterns(rule.ID, match.BaseImage.Denied); err != nil {
+			return nil, err
+		}
+	}
+
+	return pc, nil
+}
+
+func compilePolicyPatterns(id string, patterns []string) ([]*regexp.Regexp, error) {
+	var result []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern (%s): %w", id, err)
+		}
+
+		result = append(result, re)
+	}
+
+	return result, nil
+}
+
+func (c *PolicyCheck) Run(opts *Options, ctx *Context) (*Result, error) {
+	log.Debugf("linter.check[%s:'%s']", c.ID, c.Name)
+	result := &Result{
+		Source: &c.Info,
+	}
+
+	addMatch := func(match *Match) {
+		if !result.Hit {
+			result.Hit = true
+			result.Message = c.MainMessage
+		}
+
+		result.Matches = append(result.Matches, match)
+	}
+
+	match := c.Rule.Match
+	hasInstMatch := len(match.Instructions) > 0 ||
+		len(match.Args) > 0 ||
+		len(match.NotArgs) > 0
+
+	for _, stage := range ctx.Dockerfile.Stages {
+		if !c.isSelectedStage(ctx.Dockerfile, stage) {
+			continue
+		}
+
+		if !hasInstMatch {
+			addMatch(&Match{
+				Stage:       stage,
+				Instruction: stage.FromInstruction,
+				Message:     stageMatchMessage(stage),
+			})
+			continue
+		}
+
+		//all stage instructions ('COPY' and 'ONBUILD' are not in the current instructions)
+		var instMatches []*instruction.Field
+		for _, inst := range stage.AllInstructions {
+			if c.isMatchingInstruction(inst) {
+				instMatches = append(instMatches, inst)
+			}
+		}
+
+		if match.Absent {
+			if len(instMatches) == 0 {
+				addMatch(&Match{
+					Stage:   stage,
+					Message: stageMatchMessage(stage),
+				})
+			}
+
+			continue
+		}
+
+		for _, inst := range instMatches {
+			addMatch(&Match{
+				Stage:       stage,
+				Instruction: inst,
+				Message: fmt.Sprintf(c.MatchMessage,
+					inst.StartLine,
+					inst.EndLine,
+					inst.Name,
+					inst.GlobalIndex,
+					inst.StageID,
+					inst.StageIndex),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (c *PolicyCheck) isSelectedStage(df *spec.Dockerfile, stage *spec.BuildStage) bool {
+	if sm := c.Rule.Match.Stage; sm != nil {
+		if sm.Last && stage != df.LastStage {
+			return false
+		}
+
+		if c.stageName != nil && !c.stageName.MatchString(stage.Name) {
+			return false
+		}
+	}
+
+	bm := c.Rule.Match.BaseImage
+	if bm == nil {
+		return true
+	}
+
+	//the stages based on other stages and 'scratch' don't have base images
+	if stage.Parent.ParentStage != nil ||
+		stage.Parent.Name == "" ||
+		stage.Parent.Name == "scratch" {
+		return false
+	}
+
+	ref := parentImageRef(&stage.Parent)
+	if len(c.allowed) > 0 && !matchesAny(c.allowed, ref) {
+		return true
+	}
+
+	if matchesAny(c.denied, ref) {
+		return true
+	}
+
+	if bm.RequireDigest && stage.Parent.Digest == "" {
+		return true
+	}
+
+	return false
+}
+
+func (c *PolicyCheck) isMatchingInstruction(inst *instruction.Field) bool {
+	if len(c.instructions) > 0 {
+		if _, found := c.instructions[inst.Name]; !found {
+			return false
+		}
+	}
+
+	args := inst.ArgsRaw
+	if args == "" {
+		args = strings.Join(inst.Args, " ")
+	}
+
+	for _, re := range c.args {
+		if !re.MatchString(args) {
+			return false
+		}
+	}
+
+	return !matchesAny(c.notArgs, args)
+}
+
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parentImageRef returns the base image reference ('name:tag@digest')
+func parentImageRef(parent *spec.ParentImage) string {
+	ref := parent.Name
+	if parent.Tag != "" {
+		ref = fmt.Sprintf("%s:%s", ref, parent.Tag)
+	}
+
+	if parent.Digest != "" {
+		ref = fmt.Sprintf("%s@%s", ref, parent.Digest)
+	}
+
+	return ref
+}
+
+func stageMatchMessage(stage *spec.BuildStage) string {
+	return fmt.Sprintf(policyStageMatchMessage,
+		stage.Index,
+		stage.Name,
+		stage.StartLine,
+		stage.EndLine,
+		parentImageRef(&stage.Parent))
+}
@@ -0,0 +1,309 @@
+package check
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/parser"
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/spec"
+)
+
+const testPolicyDockerfile = `FROM golang:1.21 AS builder
+RUN go build -o /app .
+
+FROM registry.example.com/base@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef AS runtime
+LABEL org.opencontainers.image.source=https://github.com/example/app
+COPY --from=builder /app /app
+
+FROM builder AS test
+RUN go test ./...
+
+FROM alpine:3.19
+RUN apk add --no-cache curl
+RUN curl -fsSL https://example.com/install.sh | sh
+USER app
+`
+
+func runPolicyRule(t *testing.T, rule *PolicyRule) *Result {
+	df, err := parser.FromReader(strings.NewReader(testPolicyDockerfile), "Dockerfile", "")
+	require.NoError(t, err)
+
+	pc, err := NewPolicyCheck(rule)
+	require.NoError(t, err, rule.ID)
+
+	result, err := pc.Run(&Options{}, &Context{Dockerfile: df})
+	require.NoError(t, err, rule.ID)
+	return result
+}
+
+func matchLines(result *Result) []int {
+	var lines []int
+	for _, m := range result.Matches {
+		if m.Instruction != nil {
+			lines = append(lines, m.Instruction.StartLine)
+			continue
+		}
+
+		lines = append(lines, m.Stage.StartLine)
+	}
+
+	return lines
+}
+
+func TestNewPolicyCheck(t *testing.T) {
+	pc, err := NewPolicyCheck(&PolicyRule{
+		ID:     "ORG.001",
+		Labels: map[string]string{"team": "platform", LabelScope: ScopeImage},
+		Match:  PolicyMatch{Instructions: []string{"RUN"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ORG.001", pc.Name)
+	assert.Equal(t, "ORG.001", pc.Description)
+	assert.Equal(t, "ORG.001", pc.MainMessage)
+	assert.Equal(t, map[string]string{
+		"team":      "platform",
+		LabelLevel:  LevelWarn,
+		LabelScope:  ScopeStage,
+		LabelSource: SourcePolicy,
+	}, pc.Labels)
+	assert.Equal(t, map[string]struct{}{"run": {}}, pc.instructions)
+	assert.Same(t, &pc.Info, pc.Get())
+
+	pc, err = NewPolicyCheck(&PolicyRule{
+		ID:      "ORG.002",
+		Name:    "No curl",
+		Level:   "ERROR",
+		Message: "Curl is not allowed",
+		Match:   PolicyMatch{Args: []string{"curl"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "No curl", pc.Description)
+	assert.Equal(t, "Curl is not allowed", pc.MainMessage)
+	assert.Equal(t, LevelError, pc.Labels[LabelLevel])
+
+	tt := []struct {
+		name     string
+		rule     *PolicyRule
+		expected error
+	}{
+		{
+			name:     "bad level",
+			rule:     &PolicyRule{ID: "ORG.003", Level: "critical", Match: PolicyMatch{Instructions: []string{"run"}}},
+			expected: ErrInvalidPolicyLevel,
+		},
+		{
+			name:     "empty match",
+			rule:     &PolicyRule{ID: "ORG.004", Match: PolicyMatch{Absent: true}},
+			expected: ErrEmptyPolicyRuleMatch,
+		},
+		{
+			name: "bad args pattern",
+			rule: &PolicyRule{ID: "ORG.005", Match: PolicyMatch{Args: []string{"("}}},
+		},
+		{
+			name: "bad not args pattern",
+			rule: &PolicyRule{ID: "ORG.006", Match: PolicyMatch{NotArgs: []string{"["}}},
+		},
+		{
+			name: "bad stage name pattern",
+			rule: &PolicyRule{ID: "ORG.007", Match: PolicyMatch{Stage: &PolicyStageMatch{Name: "(?P<"}}},
+		},
+		{
+			name: "bad base image pattern",
+			rule: &PolicyRule{ID: "ORG.008", Match: PolicyMatch{BaseImage: &PolicyBaseImage{Denied: []string{"*"}}}},
+		},
+	}
+
+	for _, test := range tt {
+		_, err := NewPolicyCheck(test.rule)
+		require.Error(t, err, test.name)
+		assert.Contains(t, err.Error(), test.rule.ID, test.name)
+		if test.expected != nil {
+			assert.True(t, errors.Is(err, test.expected), test.name)
+		}
+	}
+}
+
+func TestPolicyCheckRun(t *testing.T) {
+	tt := []struct {
+		name  string
+		match PolicyMatch
+		lines []int
+	}{
+		{
+			name:  "instructions",
+			match: PolicyMatch{Instructions: []string{"RUN"}},
+			lines: []int{2, 9, 12, 13},
+		},
+		{
+			name:  "args",
+			match: PolicyMatch{Instructions: []string{"run"}, Args: []string{`curl`, `\|\s*sh`}},
+			lines: []int{13},
+		},
+		{
+			name:  "not args",
+			match: PolicyMatch{Instructions: []string{"run"}, NotArgs: []string{`^go `}},
+			lines: []int{12, 13},
+		},
+		{
+			name:  "stage name",
+			match: PolicyMatch{Instructions: []string{"run"}, Stage: &PolicyStageMatch{Name: "^(builder|test)$"}},
+			lines: []int{2, 9},
+		},
+		{
+			name:  "last stage",
+			match: PolicyMatch{Instructions: []string{"user"}, Stage: &PolicyStageMatch{Last: true}},
+			lines: []int{14},
+		},
+		{
+			name: "absent",
+			match: PolicyMatch{
+				Instructions: []string{"label"},
+				Args:         []string{`org\.opencontainers\.image\.source=`},
+				Absent:       true,
+			},
+			lines: []int{1, 8, 11},
+		},
+		{
+			name:  "stages",
+			match: PolicyMatch{Stage: &PolicyStageMatch{Name: "^$"}},
+			lines: []int{11},
+		},
+		{
+			//the stages based on other stages are not selected
+			name:  "allowed base images",
+			match: PolicyMatch{BaseImage: &PolicyBaseImage{Allowed: []string{`^registry\.example\.com/`}}},
+			lines: []int{1, 11},
+		},
+		{
+			name:  "denied base images",
+			match: PolicyMatch{BaseImage: &PolicyBaseImage{Denied: []string{`^alpine:`, `:latest$`}}},
+			lines: []int{11},
+		},
+		{
+			name:  "base image digest",
+			match: PolicyMatch{BaseImage: &PolicyBaseImage{RequireDigest: true}},
+			lines: []int{1, 11},
+		},
+		{
+			name:  "base image instructions",
+			match: PolicyMatch{Instructions: []string{"run"}, BaseImage: &PolicyBaseImage{Denied: []string{`^golang`}}},
+			lines: []int{2},
+		},
+		{
+			name:  "no matches",
+			match: PolicyMatch{Instructions: []string{"healthcheck"}},
+		},
+	}
+
+	for _, test := range tt {
+		result := runPolicyRule(t, &PolicyRule{ID: "ORG.001", Message: "Policy violation", Match: test.match})
+		assert.Equal(t, test.lines, matchLines(result), test.name)
+		assert.Equal(t, len(test.lines) > 0, result.Hit, test.name)
+		if result.Hit {
+			assert.Equal(t, "Policy violation", result.Message, test.name)
+		}
+	}
+}
+
+func TestPolicyCheckRunMatchMessages(t *testing.T) {
+	result := runPolicyRule(t, &PolicyRule{
+		ID:    "ORG.001",
+		Match: PolicyMatch{Instructions: []string{"copy"}},
+	})
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "Instruction: start=6 end=6 name='copy' global_index=4 stage_id=1 stage_index=2", result.Matches[0].Message)
+	assert.Equal(t, "runtime", result.Matches[0].Stage.Name)
+
+	result = runPolicyRule(t, &PolicyRule{
+		ID:    "ORG.002",
+		Match: PolicyMatch{BaseImage: &PolicyBaseImage{Denied: []string{`^alpine`}}},
+	})
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "Stage: index=3 name='' start=11 end=14 parent='alpine:3.19'", result.Matches[0].Message)
+	require.NotNil(t, result.Matches[0].Instruction)
+	assert.Equal(t, "from", result.Matches[0].Instruction.Name)
+}
+
+func TestLoadPolicyChecks(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy := func(name, data string) string {
+		fpath := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(fpath, []byte(data), 0644))
+		return fpath
+	}
+
+	yamlPolicy := writePolicy("policy.yaml", `
+rules:
+- id: ORG.001
+  name: No curl
+  level: error
+  match:
+    instructions: [run]
+    args: [curl]
+-
+`)
+
+	jsonPolicy := writePolicy("policy.json", `{"rules":[{"id":"ORG.002","match":{"stage":{"last":true}}}]}`)
+
+	checks, err := LoadPolicyChecks([]string{yamlPolicy, jsonPolicy})
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.Equal(t, "ORG.001", checks[0].ID)
+	assert.Equal(t, LevelError, checks[0].Labels[LabelLevel])
+	assert.Equal(t, "ORG.002", checks[1].ID)
+
+	tt := []struct {
+		name     string
+		files    []string
+		expected error
+	}{
+		{name: "no files", expected: ErrNoPolicyRules},
+		{name: "no rules", files: []string{writePolicy("empty.yaml", "rules: []\n")}, expected: ErrNoPolicyRules},
+		{name: "missing file", files: []string{filepath.Join(dir, "missing.yaml")}},
+		{name: "bad yaml", files: []string{writePolicy("bad.yaml", "rules: [\n")}},
+		{
+			name:     "missing rule id",
+			files:    []string{writePolicy("noid.yaml", "rules:\n- name: x\n  match:\n    instructions: [run]\n")},
+			expected: ErrMissingPolicyRuleID,
+		},
+		{name: "duplicate rule id", files: []string{yamlPolicy, yamlPolicy}, expected: ErrDuplicatePolicyRule},
+		{
+			name:     "built-in check id",
+			files:    []string{writePolicy("builtin.yaml", "rules:\n- id: ID.20001\n  match:\n    instructions: [run]\n")},
+			expected: ErrDuplicatePolicyRule,
+		},
+		{
+			name:     "bad rule",
+			files:    []string{writePolicy("badrule.yaml", "rules:\n- id: ORG.009\n  level: bad\n  match:\n    instructions: [run]\n")},
+			expected: ErrInvalidPolicyLevel,
+		},
+	}
+
+	for _, test := range tt {
+		_, err := LoadPolicyChecks(test.files)
+		require.Error(t, err, test.name)
+		if test.expected != nil {
+			assert.True(t, errors.Is(err, test.expected), "%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestParentImageRef(t *testing.T) {
+	df, err := parser.FromReader(strings.NewReader(testPolicyDockerfile), "Dockerfile", "")
+	require.NoError(t, err)
+	require.Len(t, df.Stages, 4)
+
+	assert.Equal(t, "golang:1.21", parentImageRef(&df.Stages[0].Parent))
+	assert.Equal(t,
+		"registry.example.com/base@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		parentImageRef(&df.Stages[1].Parent))
+	assert.NotNil(t, df.Stages[2].Parent.ParentStage)
+	assert.Equal(t, "app:1.0@sha256:abc", parentImageRef(&spec.ParentImage{Name: "app", Tag: "1.0", Digest: "sha256:abc"}))
+}
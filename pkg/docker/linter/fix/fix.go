@@ -0,0 +1,284 @@
+// Package fix applies the mechanical fixes for the linter check hits to Dockerfiles.
+// The fixes are applied to the Dockerfile AST instruction line ranges,
+// so the rest of the Dockerfile (comments and formatting) stays the same.
+package fix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/ast"
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+)
+
+var (
+	ErrNoDockerfile   = errors.New("no Dockerfile")
+	ErrInvalidFixData = errors.New("fixed Dockerfile can't be parsed")
+)
+
+// Edit is a Dockerfile fix (it replaces the line range with the new lines)
+type Edit struct {
+	CheckIDs  []string `json:"check_ids"`
+	StartLine int      `json:"start_line"`
+	EndLine   int      `json:"end_line"`
+	Lines     []string `json:"lines,omitempty"` //no lines if the instructions are removed
+	Message   string   `json:"message"`
+}
+
+func (ref *Edit) overlaps(other *Edit) bool {
+	return ref.StartLine <= other.EndLine && other.StartLine <= ref.EndLine
+}
+
+// Result is the Dockerfile fix result
+type Result struct {
+	FilePath string
+	Original []string
+	Fixed    []string
+	//Edits are the applied edits
+	Edits []*Edit
+	//Skipped are the edits that overlap with the applied edits
+	Skipped []*Edit
+
+	lineEnding    string
+	finalNewLine  bool
+	escapeToken   rune
+	nodes         []*ast.Node
+	nodesByLine   map[int]int
+	stageByNode   []int
+	originalBytes []byte
+}
+
+// Changed returns true if the Dockerfile has fixes
+func (ref *Result) Changed() bool {
+	return len(ref.Edits) > 0
+}
+
+// Data returns the fixed Dockerfile data
+func (ref *Result) Data() []byte {
+	if !ref.Changed() {
+		return ref.originalBytes
+	}
+
+	data := strings.Join(ref.Fixed, ref.lineEnding)
+	if ref.finalNewLine {
+		data += ref.lineEnding
+	}
+
+	return []byte(data)
+}
+
+// Diff returns the unified diff for the fixes
+func (ref *Result) Diff() (string, error) {
+	if !ref.Changed() {
+		return "", nil
+	}
+
+	diff := difflib.UnifiedDiff{
+		A:        withNewLines(ref.Original),
+		B:        withNewLines(ref.Fixed),
+		FromFile: ref.FilePath,
+		ToFile:   ref.FilePath,
+		Context:  3,
+	}
+
+	return difflib.GetUnifiedDiffString(diff)
+}
+
+func withNewLines(lines []string) []string {
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, line+"\n")
+	}
+
+	return result
+}
+
+// Save writes the fixed Dockerfile data to the Dockerfile
+func (ref *Result) Save() error {
+	if !ref.Changed() {
+		return nil
+	}
+
+	info, err := os.Stat(ref.FilePath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(ref.FilePath, ref.Data(), info.Mode().Perm())
+}
+
+// IsFixable returns true if there's a fix for the check
+func IsFixable(checkID string) bool {
+	for _, f := range fixers {
+		for _, id := range f.checkIDs {
+			if id == checkID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Dockerfile creates the fixes for the check hits in the Dockerfile
+func Dockerfile(filePath string, hits map[string]*check.Result) (*Result, error) {
+	if filePath == "" {
+		return nil, ErrNoDockerfile
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromData(filePath, data, hits)
+}
+
+// FromData creates the fixes for the check hits in the Dockerfile data
+func FromData(filePath string, data []byte, hits map[string]*check.Result) (*Result, error) {
+	parsed, err := ast.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	//the parsed lines don't include the instruction continuation lines
+	lines := splitLines(data)
+	result := &Result{
+		FilePath:      filePath,
+		Original:      lines,
+		Fixed:         lines,
+		lineEnding:    "\n",
+		finalNewLine:  bytes.HasSuffix(data, []byte("\n")),
+		escapeToken:   parsed.EscapeToken,
+		nodes:         parsed.AST.Children,
+		nodesByLine:   map[int]int{},
+		originalBytes: data,
+	}
+
+	if bytes.Contains(data, []byte("\r\n")) {
+		result.lineEnding = "\r\n"
+	}
+
+	stage := -1
+	for idx, node := range result.nodes {
+		result.nodesByLine[node.StartLine] = idx
+		if node.Value == "from" {
+			stage++
+		}
+
+		result.stageByNode = append(result.stageByNode, stage)
+	}
+
+	var edits []*Edit
+	for _, f := range fixers {
+		var checkIDs []string
+		nodeSet := map[int]struct{}{}
+		for _, id := range f.checkIDs {
+			hit, found := hits[id]
+			if !found || hit == nil {
+				continue
+			}
+
+			checkIDs = append(checkIDs, id)
+			for _, match := range hit.Matches {
+				if match.Instruction == nil {
+					continue
+				}
+
+				if idx, found := result.nodesByLine[match.Instruction.StartLine]; found {
+					nodeSet[idx] = struct{}{}
+				}
+			}
+		}
+
+		if len(nodeSet) == 0 {
+			continue
+		}
+
+		var nodeIndexes []int
+		for idx := range nodeSet {
+			nodeIndexes = append(nodeIndexes, idx)
+		}
+
+		sort.Ints(nodeIndexes)
+		for _, edit := range f.fix(result, nodeIndexes) {
+			edit.CheckIDs = checkIDs
+			edits = append(edits, edit)
+		}
+	}
+
+	//the earlier fixers have priority if the edits overlap
+	for _, edit := range edits {
+		var isSkipped bool
+		for _, applied := range result.Edits {
+			if edit.overlaps(applied) {
+				isSkipped = true
+				break
+			}
+		}
+
+		if isSkipped {
+			log.Debugf("fix.FromData: skipping overlapping edit - %s (%d-%d)", edit.Message, edit.StartLine, edit.EndLine)
+			result.Skipped = append(result.Skipped, edit)
+			continue
+		}
+
+		result.Edits = append(result.Edits, edit)
+	}
+
+	if !result.Changed() {
+		return result, nil
+	}
+
+	sort.Slice(result.Edits, func(i, j int) bool {
+		return result.Edits[i].StartLine < result.Edits[j].StartLine
+	})
+
+	var fixed []string
+	current := 1
+	for _, edit := range result.Edits {
+		fixed = append(fixed, result.Original[current-1:edit.StartLine-1]...)
+		fixed = append(fixed, edit.Lines...)
+		current = edit.EndLine + 1
+	}
+
+	fixed = append(fixed, result.Original[current-1:]...)
+	result.Fixed = fixed
+
+	if _, err := ast.Parse(bytes.NewReader(result.Data())); err != nil {
+		return nil, fmt.Errorf("%w - %v", ErrInvalidFixData, err)
+	}
+
+	return result, nil
+}
+
+func splitLines(data []byte) []string {
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSuffix(line, "\r")
+	}
+
+	return lines
+}
+
+// nodeLines returns the original Dockerfile lines for the instruction node
+func (ref *Result) nodeLines(node *ast.Node) []string {
+	return ref.Original[node.StartLine-1 : node.EndLine]
+}
+
+// sameStage returns true if both instruction nodes are in the same stage
+func (ref *Result) sameStage(a, b int) bool {
+	return ref.stageByNode[a] == ref.stageByNode[b]
+}
@@ -0,0 +1,303 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/docker/instruction"
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+)
+
+// checkHits creates the check hits with the instruction matches at the start lines
+func checkHits(matches map[string][]int) map[string]*check.Result {
+	hits := map[string]*check.Result{}
+	for id, lines := range matches {
+		result := &check.Result{Source: &check.Info{ID: id}, Hit: true}
+		for _, line := range lines {
+			result.Matches = append(result.Matches, &check.Match{
+				Instruction: &instruction.Field{StartLine: line},
+			})
+		}
+
+		hits[id] = result
+	}
+
+	return hits
+}
+
+func TestFromData(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		hits     map[string][]int
+		expected string
+		edits    int
+		skipped  int
+	}{
+		{
+			name: "overridden entrypoint and cmd",
+			data: "FROM alpine\n" +
+				"ENTRYPOINT [\"/bin/a\"]\n" +
+				"CMD [\"a\"]\n" +
+				"ENTRYPOINT [\"/bin/b\"]\n" +
+				"CMD [\"b\"]\n",
+			hits: map[string][]int{"ID.20010": {2, 4}, "ID.20011": {3}},
+			expected: "FROM alpine\n" +
+				"ENTRYPOINT [\"/bin/b\"]\n" +
+				"CMD [\"b\"]\n",
+			edits: 2,
+		},
+		{
+			name: "overridden cmd in another stage",
+			data: "FROM alpine AS build\n" +
+				"CMD [\"a\"]\n" +
+				"FROM alpine\n" +
+				"CMD [\"b\"]\n",
+			hits:     map[string][]int{"ID.20011": {2, 4}},
+			expected: "",
+		},
+		{
+			name: "exec form",
+			data: "FROM alpine\n" +
+				"ENTRYPOINT /app/server --port 8080\n" +
+				"CMD echo $HOME\n",
+			hits: map[string][]int{"ID.20012": {2, 3}},
+			expected: "FROM alpine\n" +
+				"ENTRYPOINT [\"/app/server\", \"--port\", \"8080\"]\n" +
+				"CMD [\"/bin/sh\", \"-c\", \"echo $HOME\"]\n",
+			edits: 2,
+		},
+		{
+			name: "exec form with stage shell",
+			data: "FROM alpine\n" +
+				"SHELL [\"/bin/bash\", \"-o\", \"pipefail\", \"-c\"]\n" +
+				"CMD cat /etc/os-release | head -1\n",
+			hits: map[string][]int{"ID.20012": {3}},
+			expected: "FROM alpine\n" +
+				"SHELL [\"/bin/bash\", \"-o\", \"pipefail\", \"-c\"]\n" +
+				"CMD [\"/bin/bash\", \"-o\", \"pipefail\", \"-c\", \"cat /etc/os-release | head -1\"]\n",
+			edits: 1,
+		},
+		{
+			//the overridden CMD is removed (the exec form edit for it is skipped)
+			name: "overlapping edits",
+			data: "FROM alpine\n" +
+				"CMD echo a\n" +
+				"CMD echo b\n",
+			hits: map[string][]int{"ID.20011": {2}, "ID.20012": {2, 3}},
+			expected: "FROM alpine\n" +
+				"CMD [\"echo\", \"b\"]\n",
+			edits:   2,
+			skipped: 1,
+		},
+		{
+			name: "merge run instructions",
+			data: "FROM alpine\n" +
+				"RUN apk update\n" +
+				"# install curl\n" +
+				"RUN apk add curl\n" +
+				"RUN rm -rf /var/cache/apk/*\n" +
+				"USER app\n",
+			hits: map[string][]int{"ID.20019": {4}, "ID.20021": {5}},
+			expected: "FROM alpine\n" +
+				"RUN apk update && \\\n" +
+				"# install curl\n" +
+				"    apk add curl && \\\n" +
+				"    rm -rf /var/cache/apk/*\n" +
+				"USER app\n",
+			edits: 1,
+		},
+		{
+			name: "merge run instructions with continuation lines",
+			data: "FROM alpine\n" +
+				"RUN apk add \\\n" +
+				"      curl \\\n" +
+				"      git\n" +
+				"RUN adduser -D app && \\\n" +
+				"    mkdir /app\n" +
+				"WORKDIR app\n",
+			hits: map[string][]int{"ID.20019": {5}, "ID.20015": {7}},
+			expected: "FROM alpine\n" +
+				"RUN apk add \\\n" +
+				"      curl \\\n" +
+				"      git && \\\n" +
+				"    adduser -D app && \\\n" +
+				"    mkdir /app\n" +
+				"WORKDIR /app\n",
+			edits: 2,
+		},
+		{
+			name: "unsafe run merges",
+			data: "FROM alpine\n" +
+				"RUN cd /tmp\n" +
+				"RUN make\n" +
+				"RUN --mount=type=cache,target=/root/.cache go build\n" +
+				"RUN [\"/bin/true\"]\n" +
+				"FROM alpine\n" +
+				"RUN echo a # comment\n" +
+				"RUN echo b\n",
+			hits:     map[string][]int{"ID.20019": {3, 4, 5, 6, 8}},
+			expected: "",
+		},
+		{
+			name: "escape directive",
+			data: "# escape=`\n" +
+				"FROM mcr.microsoft.com/windows/servercore\n" +
+				"RUN echo a\n" +
+				"RUN echo b\n",
+			hits: map[string][]int{"ID.20019": {4}},
+			expected: "# escape=`\n" +
+				"FROM mcr.microsoft.com/windows/servercore\n" +
+				"RUN echo a && `\n" +
+				"    echo b\n",
+			edits: 1,
+		},
+		{
+			name: "relative workdir",
+			data: "FROM alpine\n" +
+				"WORKDIR /srv\n" +
+				"WORKDIR app/../data\n" +
+				"WORKDIR $HOME\n" +
+				"WORKDIR cache\n" +
+				"FROM alpine\n" +
+				"WORKDIR tmp\n",
+			hits: map[string][]int{"ID.20015": {3, 5, 7}},
+			expected: "FROM alpine\n" +
+				"WORKDIR /srv\n" +
+				"WORKDIR /srv/data\n" +
+				"WORKDIR $HOME\n" +
+				"WORKDIR cache\n" +
+				"FROM alpine\n" +
+				"WORKDIR /tmp\n",
+			edits: 2,
+		},
+		{
+			name:     "no fixable hits",
+			data:     "FROM alpine\nRUN echo a\n",
+			hits:     map[string][]int{"ID.20001": {2}},
+			expected: "",
+		},
+	}
+
+	for _, test := range tt {
+		result, err := FromData("Dockerfile", []byte(test.data), checkHits(test.hits))
+		require.NoError(t, err, test.name)
+
+		expected := test.expected
+		if expected == "" {
+			expected = test.data
+		}
+
+		assert.Equal(t, expected, string(result.Data()), test.name)
+		assert.Equal(t, test.edits > 0, result.Changed(), test.name)
+		assert.Len(t, result.Edits, test.edits, test.name)
+		assert.Len(t, result.Skipped, test.skipped, test.name)
+	}
+}
+
+func TestFromDataLineEndings(t *testing.T) {
+	data := "FROM alpine\r\n" +
+		"RUN apk add \\\r\n" +
+		"      curl\r\n" +
+		"RUN rm -rf /var/cache/apk/*\r\n" +
+		"CMD echo done"
+
+	result, err := FromData("Dockerfile", []byte(data), checkHits(map[string][]int{
+		"ID.20021": {4},
+		"ID.20012": {5},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"FROM alpine",
+		"RUN apk add \\",
+		"      curl",
+		"RUN rm -rf /var/cache/apk/*",
+		"CMD echo done",
+	}, result.Original)
+
+	//keeping the CRLF line endings and no final new line
+	assert.Equal(t, "FROM alpine\r\n"+
+		"RUN apk add \\\r\n"+
+		"      curl && \\\r\n"+
+		"    rm -rf /var/cache/apk/*\r\n"+
+		"CMD [\"echo\", \"done\"]", string(result.Data()))
+
+	require.Len(t, result.Edits, 2)
+	assert.Equal(t, []string{"ID.20021"}, result.Edits[0].CheckIDs)
+	assert.Equal(t, 2, result.Edits[0].StartLine)
+	assert.Equal(t, 4, result.Edits[0].EndLine)
+	assert.Equal(t, 5, result.Edits[1].StartLine)
+}
+
+func TestResultDiff(t *testing.T) {
+	data := "FROM alpine\nWORKDIR app\nCMD [\"./app\"]\n"
+	result, err := FromData("Dockerfile", []byte(data), checkHits(map[string][]int{"ID.20015": {2}}))
+	require.NoError(t, err)
+
+	diff, err := result.Diff()
+	require.NoError(t, err)
+	assert.Equal(t, "--- Dockerfile\n"+
+		"+++ Dockerfile\n"+
+		"@@ -1,3 +1,3 @@\n"+
+		" FROM alpine\n"+
+		"-WORKDIR app\n"+
+		"+WORKDIR /app\n"+
+		" CMD [\"./app\"]\n", diff)
+
+	result, err = FromData("Dockerfile", []byte(data), nil)
+	require.NoError(t, err)
+	diff, err = result.Diff()
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+func TestDockerfileSave(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "Dockerfile")
+	require.NoError(t, os.WriteFile(fpath, []byte("FROM alpine\nWORKDIR app\n"), 0600))
+
+	result, err := Dockerfile(fpath, checkHits(map[string][]int{"ID.20015": {2}}))
+	require.NoError(t, err)
+	require.NoError(t, result.Save())
+
+	data, err := os.ReadFile(fpath)
+	require.NoError(t, err)
+	assert.Equal(t, "FROM alpine\nWORKDIR /app\n", string(data))
+
+	info, err := os.Stat(fpath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = Dockerfile("", nil)
+	assert.ErrorIs(t, err, ErrNoDockerfile)
+	_, err = Dockerfile(filepath.Join(t.TempDir(), "missing"), nil)
+	assert.Error(t, err)
+}
+
+func TestIsFixable(t *testing.T) {
+	for _, id := range []string{"ID.20010", "ID.20011", "ID.20012", "ID.20015", "ID.20019", "ID.20021"} {
+		assert.True(t, IsFixable(id), id)
+	}
+
+	assert.False(t, IsFixable("ID.20001"))
+	assert.False(t, IsFixable(""))
+}
+
+func TestSplitLines(t *testing.T) {
+	tt := map[string][]string{
+		"":                nil,
+		"\n":              nil,
+		"FROM a":          {"FROM a"},
+		"FROM a\n":        {"FROM a"},
+		"FROM a\r\nRUN b": {"FROM a", "RUN b"},
+		"FROM a\n\nRUN b": {"FROM a", "", "RUN b"},
+	}
+
+	for data, expected := range tt {
+		assert.Equal(t, expected, splitLines([]byte(data)), strings.ReplaceAll(data, "\n", "\\n"))
+	}
+}
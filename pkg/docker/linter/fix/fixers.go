@@ -0,0 +1,291 @@
+package fix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/google/shlex"
+
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/ast"
+	"github.com/slimtoolkit/slim/pkg/docker/instruction"
+)
+
+type fixer struct {
+	checkIDs []string
+	//fix creates the edits for the matched instruction nodes (sorted node indexes)
+	fix func(df *Result, nodes []int) []*Edit
+}
+
+// fixers are in the priority order (for the overlapping edits)
+var fixers = []*fixer{
+	{
+		checkIDs: []string{"ID.20010"},
+		fix: func(df *Result, nodes []int) []*Edit {
+			return removeOverridden(df, nodes, instruction.Entrypoint)
+		},
+	},
+	{
+		checkIDs: []string{"ID.20011"},
+		fix: func(df *Result, nodes []int) []*Edit {
+			return removeOverridden(df, nodes, instruction.Cmd)
+		},
+	},
+	{
+		checkIDs: []string{"ID.20019", "ID.20021"},
+		fix:      mergeRunInstructions,
+	},
+	{
+		checkIDs: []string{"ID.20012"},
+		fix:      toExecForm,
+	},
+	{
+		checkIDs: []string{"ID.20015"},
+		fix:      toAbsoluteWorkdir,
+	},
+}
+
+var instructionPrefix = regexp.MustCompile(`^(\s*)(\S+)\s*`)
+
+// instructionKeyword returns the instruction keyword (as it's written in the Dockerfile)
+// with its indentation
+func instructionKeyword(line string) string {
+	if m := instructionPrefix.FindStringSubmatch(line); m != nil {
+		return m[1] + m[2]
+	}
+
+	return line
+}
+
+// removeOverridden removes the ENTRYPOINT or CMD instructions overridden
+// by the last instruction of the same type in the stage
+func removeOverridden(df *Result, nodes []int, name string) []*Edit {
+	lastInStage := map[int]int{}
+	for idx, node := range df.nodes {
+		if node.Value == name {
+			lastInStage[df.stageByNode[idx]] = idx
+		}
+	}
+
+	var edits []*Edit
+	for _, idx := range nodes {
+		node := df.nodes[idx]
+		if node.Value != name || lastInStage[df.stageByNode[idx]] == idx {
+			continue
+		}
+
+		edits = append(edits, &Edit{
+			StartLine: node.StartLine,
+			EndLine:   node.EndLine,
+			Message:   fmt.Sprintf("remove overridden %s instruction", strings.ToUpper(name)),
+		})
+	}
+
+	return edits
+}
+
+// shellStateCommand matches the shell commands that change the shell state
+// for the commands that follow them (merging the RUN instructions is not safe with them)
+var shellStateCommand = regexp.MustCompile(`(^|[;&|(]\s*)(cd|pushd|popd|export|source|set|unset|umask|alias|shopt|\.)(\s|$)`)
+
+func isMergeableRun(node *ast.Node) bool {
+	return node.Value == instruction.Run &&
+		!node.Attributes["json"] &&
+		node.ArgsRaw != "" &&
+		//inline heredocs and trailing shell comments
+		!strings.Contains(node.ArgsRaw, "<<") &&
+		!strings.Contains(node.ArgsRaw, "#")
+}
+
+func sameFlags(a, b *ast.Node) bool {
+	return strings.Join(a.Flags, " ") == strings.Join(b.Flags, " ")
+}
+
+// mergeRunInstructions merges the matched RUN instructions
+// (unnecessary layers and separate 'rm' commands) with the previous RUN instructions
+func mergeRunInstructions(df *Result, nodes []int) []*Edit {
+	var edits []*Edit
+	var edit *Edit
+	var lastMerged int
+	for _, idx := range nodes {
+		if idx == 0 {
+			continue
+		}
+
+		prev, node := df.nodes[idx-1], df.nodes[idx]
+		if !df.sameStage(idx-1, idx) ||
+			!isMergeableRun(prev) ||
+			!isMergeableRun(node) ||
+			!sameFlags(prev, node) ||
+			shellStateCommand.MatchString(prev.ArgsRaw) {
+			continue
+		}
+
+		if edit == nil || lastMerged != idx-1 {
+			edit = &Edit{
+				StartLine: prev.StartLine,
+				Lines:     append([]string{}, df.nodeLines(prev)...),
+				Message:   "merge RUN instructions",
+			}
+
+			edits = append(edits, edit)
+		}
+
+		last := len(edit.Lines) - 1
+		edit.Lines[last] = fmt.Sprintf("%s && %c", strings.TrimRight(edit.Lines[last], " \t"), df.escapeToken)
+
+		//keeping the comments between the instructions (they are allowed in the continuation lines)
+		for _, line := range df.Original[prev.EndLine : node.StartLine-1] {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				edit.Lines = append(edit.Lines, line)
+			}
+		}
+
+		lines := df.nodeLines(node)
+		first := strings.TrimPrefix(lines[0], instructionKeyword(lines[0]))
+		edit.Lines = append(edit.Lines, "    "+strings.TrimLeft(first, " \t"))
+		edit.Lines = append(edit.Lines, lines[1:]...)
+		edit.EndLine = node.EndLine
+		lastMerged = idx
+	}
+
+	return edits
+}
+
+// shellSyntax matches the shell form commands that need the shell
+var shellSyntax = regexp.MustCompile("[$`|&;<>()*?\\[\\]{}~!#\\\\]")
+
+// toExecForm converts the shell form ENTRYPOINT and CMD instructions to the exec form
+// (the commands that need the shell run with the stage shell)
+func toExecForm(df *Result, nodes []int) []*Edit {
+	var edits []*Edit
+	for _, idx := range nodes {
+		node := df.nodes[idx]
+		if (node.Value != instruction.Entrypoint && node.Value != instruction.Cmd) ||
+			node.Attributes["json"] ||
+			strings.TrimSpace(node.ArgsRaw) == "" {
+			continue
+		}
+
+		command := strings.TrimSpace(node.ArgsRaw)
+		var args []string
+		if !shellSyntax.MatchString(command) {
+			if words, err := shlex.Split(command); err == nil &&
+				len(words) > 0 &&
+				!strings.Contains(words[0], "=") {
+				args = words
+			}
+		}
+
+		if len(args) == 0 {
+			args = append(stageShell(df, idx), command)
+		}
+
+		values, err := jsonStringList(args)
+		if err != nil {
+			continue
+		}
+
+		lines := df.nodeLines(node)
+		edits = append(edits, &Edit{
+			StartLine: node.StartLine,
+			EndLine:   node.EndLine,
+			Lines:     []string{fmt.Sprintf("%s %s", instructionKeyword(lines[0]), values)},
+			Message:   fmt.Sprintf("use the exec form for the %s instruction", strings.ToUpper(node.Value)),
+		})
+	}
+
+	return edits
+}
+
+// stageShell returns the shell for the shell form commands in the stage (at the node)
+func stageShell(df *Result, nodeIdx int) []string {
+	for idx := nodeIdx - 1; idx >= 0 && df.sameStage(idx, nodeIdx); idx-- {
+		node := df.nodes[idx]
+		if node.Value == instruction.Shell && node.Attributes["json"] {
+			var shell []string
+			for n := node.Next; n != nil; n = n.Next {
+				shell = append(shell, n.Value)
+			}
+
+			if len(shell) > 0 {
+				return shell
+			}
+		}
+	}
+
+	return []string{"/bin/sh", "-c"}
+}
+
+// jsonStringList returns the JSON form for the instruction args (without the HTML escaping)
+func jsonStringList(values []string) (string, error) {
+	var items []string
+	for _, value := range values {
+		var out bytes.Buffer
+		encoder := json.NewEncoder(&out)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return "", err
+		}
+
+		items = append(items, strings.TrimSpace(out.String()))
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(items, ", ")), nil
+}
+
+// toAbsoluteWorkdir converts the relative WORKDIR paths to absolute paths
+// (they are relative to the previous WORKDIR in the stage or to '/', the default working directory)
+func toAbsoluteWorkdir(df *Result, nodes []int) []*Edit {
+	matched := map[int]struct{}{}
+	for _, idx := range nodes {
+		matched[idx] = struct{}{}
+	}
+
+	var edits []*Edit
+	workdir := "/"
+	for idx, node := range df.nodes {
+		if node.Value == instruction.From {
+			workdir = "/"
+			continue
+		}
+
+		if node.Value != instruction.Workdir || node.Next == nil {
+			continue
+		}
+
+		current := strings.TrimSpace(node.Next.Value)
+		if strings.ContainsAny(current, "$:\\") {
+			//unknown (build arg, env var or Windows) paths
+			workdir = ""
+			continue
+		}
+
+		if strings.HasPrefix(current, "/") {
+			workdir = path.Clean(current)
+			continue
+		}
+
+		if workdir == "" {
+			continue
+		}
+
+		workdir = path.Join(workdir, current)
+		if _, found := matched[idx]; !found {
+			continue
+		}
+
+		lines := df.nodeLines(node)
+		edits = append(edits, &Edit{
+			StartLine: node.StartLine,
+			EndLine:   node.EndLine,
+			Lines:     []string{fmt.Sprintf("%s %s", instructionKeyword(lines[0]), workdir)},
+			Message:   fmt.Sprintf("use the absolute WORKDIR path (%s)", workdir),
+		})
+	}
+
+	return edits
+}
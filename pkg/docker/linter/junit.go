@@ -0,0 +1,191 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+)
+
+/////////////////////////////////////////////////////////////
+//
+// JUNIT XML DOCS:
+//
+// https://github.com/testmoapp/junitxml
+// https://llg.cubic.org/docs/junit/
+//
+/////////////////////////////////////////////////////////////
+
+const junitSuitesName = "slim.lint"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit returns the lint report as a JUnit XML test report
+// (one test case for each check: the check hits are failures and the check errors are errors)
+func (ref *Report) JUnit(target OutputTarget) ([]byte, error) {
+	dockerfileURI := ref.dockerfileURI()
+
+	suite := junitTestSuite{
+		Name: target.Reference,
+		Properties: []junitProperty{
+			{Name: "target.type", Value: target.Type},
+			{Name: "status", Value: ref.Status},
+		},
+		TestCases: []junitTestCase{},
+	}
+
+	if suite.Name == "" {
+		suite.Name = dockerfileURI
+	}
+
+	if dockerfileURI != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "dockerfile", Value: dockerfileURI})
+	}
+
+	for _, id := range sortedIDs(ref.Hits) {
+		result := ref.Hits[id]
+		info := result.Source
+		level := info.Labels[check.LabelLevel]
+
+		testCase := newJUnitTestCase(info)
+		testCase.Failure = &junitProblem{
+			Message: result.Message,
+			Type:    level,
+		}
+
+		var lines []string
+		for _, match := range result.Matches {
+			startLine, endLine := matchLineRange(match)
+			if startLine > 0 && testCase.Line == 0 {
+				testCase.File = dockerfileURI
+				testCase.Line = startLine
+			}
+
+			lines = append(lines, junitMatchLine(dockerfileURI, match, startLine, endLine))
+		}
+
+		testCase.Failure.Text = strings.Join(lines, "\n")
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Failures++
+	}
+
+	for _, id := range sortedIDs(ref.Errors) {
+		testCase := junitTestCase{
+			Name:      id,
+			ClassName: junitSuitesName,
+		}
+
+		for _, runner := range check.AllChecks {
+			if info := runner.Get(); info.ID == id {
+				testCase = newJUnitTestCase(info)
+				break
+			}
+		}
+
+		testCase.Error = &junitProblem{
+			Message: ref.Errors[id].Error(),
+			Type:    "check.error",
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Errors++
+	}
+
+	for _, id := range sortedIDs(ref.NoHits) {
+		suite.TestCases = append(suite.TestCases, newJUnitTestCase(ref.NoHits[id].Source))
+	}
+
+	suite.Tests = len(suite.TestCases)
+
+	output := junitTestSuites{
+		Name:     junitSuitesName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		return nil, err
+	}
+
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+func newJUnitTestCase(info *check.Info) junitTestCase {
+	className := junitSuitesName
+	if scope := info.Labels[check.LabelScope]; scope != "" {
+		className = fmt.Sprintf("%s.%s", junitSuitesName, scope)
+	}
+
+	return junitTestCase{
+		Name:      fmt.Sprintf("%s: %s", info.ID, info.Name),
+		ClassName: className,
+	}
+}
+
+func junitMatchLine(dockerfileURI string, match *check.Match, startLine, endLine int) string {
+	var location string
+	switch {
+	case match.Path != "":
+		location = match.Path
+	case match.Layer != nil:
+		location = fmt.Sprintf("layer:%d", *match.Layer)
+	case startLine > 0 && endLine > startLine:
+		location = fmt.Sprintf("%s:%d-%d", dockerfileURI, startLine, endLine)
+	case startLine > 0:
+		location = fmt.Sprintf("%s:%d", dockerfileURI, startLine)
+	default:
+		location = dockerfileURI
+	}
+
+	//the match message includes the stage or instruction info
+	if match.Message == "" {
+		return location
+	}
+
+	return fmt.Sprintf("%s %s", location, match.Message)
+}
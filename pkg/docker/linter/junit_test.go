@@ -0,0 +1,117 @@
+package linter
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/docker/instruction"
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+)
+
+func decodeJUnit(t *testing.T, data []byte) *junitTestSuites {
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &suites))
+	require.Len(t, suites.Suites, 1)
+	return &suites
+}
+
+func TestJUnit(t *testing.T) {
+	data, err := testReport().JUnit(OutputTarget{Type: DockerfileTargetType, Reference: "app/Dockerfile"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), xml.Header))
+
+	suites := decodeJUnit(t, data)
+	assert.Equal(t, junitSuitesName, suites.Name)
+	assert.Equal(t, 5, suites.Tests)
+	assert.Equal(t, 3, suites.Failures)
+	assert.Equal(t, 1, suites.Errors)
+
+	suite := suites.Suites[0]
+	assert.Equal(t, "app/Dockerfile", suite.Name)
+	assert.Equal(t, []junitProperty{
+		{Name: "target.type", Value: DockerfileTargetType},
+		{Name: "status", Value: StatusComplete},
+		{Name: "dockerfile", Value: "app/Dockerfile"},
+	}, suite.Properties)
+
+	//the check hits, the check errors and then the checks without hits
+	require.Len(t, suite.TestCases, 5)
+	assert.Equal(t, junitTestCase{
+		Name:      "ID.20001: Bad instruction",
+		ClassName: "slim.lint.instruction",
+		File:      "app/Dockerfile",
+		Line:      3,
+		Failure: &junitProblem{
+			Message: "Bad instruction",
+			Type:    check.LevelWarn,
+			Text:    "app/Dockerfile:3-5 RUN apt-get\napp/Dockerfile:7-9",
+		},
+	}, suite.TestCases[0])
+
+	assert.Equal(t, junitTestCase{
+		Name:      "ID.20002: No stage name",
+		ClassName: junitSuitesName,
+		Failure: &junitProblem{
+			Message: "No stage name",
+			Type:    check.LevelInfo,
+		},
+	}, suite.TestCases[1])
+
+	assert.Equal(t, "slim.lint.image", suite.TestCases[2].ClassName)
+	assert.Empty(t, suite.TestCases[2].File)
+	assert.Equal(t, "/root/.netrc Secret file: path='/root/.netrc'\nlayer:2", suite.TestCases[2].Failure.Text)
+
+	//the error for the unknown check uses the check ID
+	assert.Equal(t, junitTestCase{
+		Name:      "ID.90001",
+		ClassName: junitSuitesName,
+		Error: &junitProblem{
+			Message: "check failed",
+			Type:    "check.error",
+		},
+	}, suite.TestCases[3])
+
+	assert.Equal(t, junitTestCase{
+		Name:      "ID.30008: Missing image healthcheck",
+		ClassName: junitSuitesName,
+	}, suite.TestCases[4])
+}
+
+func TestJUnitKnownCheckError(t *testing.T) {
+	report := NewReport()
+	info := check.AllChecks[0].Get()
+	report.Errors[info.ID] = assert.AnError
+
+	data, err := report.JUnit(OutputTarget{Type: ImageTargetType, Reference: "app:latest"})
+	require.NoError(t, err)
+
+	suite := decodeJUnit(t, data).Suites[0]
+	assert.Equal(t, "app:latest", suite.Name)
+	assert.Len(t, suite.Properties, 2)
+	require.Len(t, suite.TestCases, 1)
+	assert.Equal(t, info.ID+": "+info.Name, suite.TestCases[0].Name)
+	assert.Equal(t, assert.AnError.Error(), suite.TestCases[0].Error.Message)
+}
+
+func TestJUnitMatchLine(t *testing.T) {
+	layer := 1
+	tt := []struct {
+		match    *check.Match
+		expected string
+	}{
+		{match: &check.Match{Path: "/etc/shadow", Layer: &layer}, expected: "/etc/shadow"},
+		{match: &check.Match{Layer: &layer, Message: "huge"}, expected: "layer:1 huge"},
+		{match: &check.Match{Instruction: &instruction.Field{StartLine: 4, EndLine: 6}}, expected: "Dockerfile:4-6"},
+		{match: &check.Match{Instruction: &instruction.Field{StartLine: 4, EndLine: 4}}, expected: "Dockerfile:4"},
+		{match: &check.Match{Message: "no location"}, expected: "Dockerfile no location"},
+	}
+
+	for _, test := range tt {
+		startLine, endLine := matchLineRange(test.match)
+		assert.Equal(t, test.expected, junitMatchLine("Dockerfile", test.match, startLine, endLine))
+	}
+}
@@ -1,4 +1,4 @@
-// Package linter implements a Dockerfile linter
+// Package linter implements a Dockerfile and container image linter
 package linter
 
 import (
@@ -36,6 +36,11 @@ type Options struct {
 	Dockerignore     *dockerignore.Matcher
 	Selector         CheckSelector
 	Config           map[string]*check.Options
+	//Image is the target image data (for the image targets)
+	//The image Dockerfile (reconstructed from the image history) is passed with the Dockerfile field.
+	Image *check.ImageTarget
+	//Checks are the extra (user-defined policy) checks that run with the built-in checks
+	Checks []check.Runner
 }
 
 type CheckContext struct {
@@ -90,7 +95,7 @@ type CheckState struct {
 
 func Execute(options Options) (*Report, error) {
 	df := options.Dockerfile
-	if df == nil {
+	if df == nil && options.Image == nil {
 		if options.DockerfilePath == "" {
 			return nil, ErrBadParams
 		}
@@ -128,51 +133,15 @@ func Execute(options Options) (*Report, error) {
 	report.Dockerignore = di
 
 	var selectedChecks []check.Runner
-	for _, check := range check.AllChecks {
+	for _, check := range allChecks(options.Checks) {
 		info := check.Get()
-
-		if len(options.Selector.IncludeCheckIDs) > 0 {
-			if _, ok := options.Selector.IncludeCheckIDs[info.ID]; ok {
-				selectedChecks = append(selectedChecks, check)
-				log.Debugf("linter.Execute: selected check - id=%v (IncludeCheckIDs)", info.ID)
-			}
-			continue
-		}
-
-		if len(options.Selector.IncludeCheckLabels) > 0 {
-			for k, v := range info.Labels {
-				if inval := options.Selector.IncludeCheckLabels[k]; inval == v {
-					if len(options.Selector.ExcludeCheckIDs) == 0 {
-						selectedChecks = append(selectedChecks, check)
-						log.Debugf("linter.Execute: selected check - id=%v label=%v:%v (IncludeCheckLabels)", info.ID, k, v)
-					} else if _, ok := options.Selector.ExcludeCheckIDs[info.ID]; !ok {
-						selectedChecks = append(selectedChecks, check)
-						log.Debugf("linter.Execute: selected check - id=%v label=%v:%v (IncludeCheckLabels/ExcludeCheckIDs)", info.ID, k, v)
-					}
-
-					continue
-				}
-			}
-
+		if !isTargetCheck(info, df != nil, options.Image != nil) {
 			continue
 		}
 
-		if len(options.Selector.ExcludeCheckLabels) > 0 {
-			for k, v := range info.Labels {
-				if inval := options.Selector.ExcludeCheckLabels[k]; inval == v {
-					continue
-				}
-			}
-		}
-
-		if len(options.Selector.ExcludeCheckIDs) > 0 {
-			if _, ok := options.Selector.ExcludeCheckIDs[info.ID]; ok {
-				continue
-			}
+		if isSelectedCheck(info, options.Selector) {
+			selectedChecks = append(selectedChecks, check)
 		}
-
-		selectedChecks = append(selectedChecks, check)
-		log.Debugf("linter.Execute: selected check - id=%v", info.ID)
 	}
 
 	if len(selectedChecks) == 0 {
@@ -187,6 +156,7 @@ func Execute(options Options) (*Report, error) {
 		Dockerfile:      df,
 		BuildContextDir: options.BuildContextDir,
 		Dockerignore:    di,
+		Image:           options.Image,
 	}
 
 	stateCh := make(chan *CheckState, len(selectedChecks))
@@ -251,9 +221,67 @@ done:
 	return report, nil
 }
 
-func ListChecks() []*check.Info {
+// isSelectedCheck returns true if the check is selected by the check selector
+func isSelectedCheck(info *check.Info, selector CheckSelector) bool {
+	if len(selector.IncludeCheckIDs) > 0 {
+		if _, ok := selector.IncludeCheckIDs[info.ID]; ok {
+			log.Debugf("linter.Execute: selected check - id=%v (IncludeCheckIDs)", info.ID)
+			return true
+		}
+
+		return false
+	}
+
+	if len(selector.IncludeCheckLabels) > 0 {
+		for k, v := range info.Labels {
+			if inval, ok := selector.IncludeCheckLabels[k]; ok && inval == v {
+				if _, ok := selector.ExcludeCheckIDs[info.ID]; ok {
+					return false
+				}
+
+				log.Debugf("linter.Execute: selected check - id=%v label=%v:%v (IncludeCheckLabels)", info.ID, k, v)
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for k, v := range info.Labels {
+		if inval, ok := selector.ExcludeCheckLabels[k]; ok && inval == v {
+			return false
+		}
+	}
+
+	if _, ok := selector.ExcludeCheckIDs[info.ID]; ok {
+		return false
+	}
+
+	log.Debugf("linter.Execute: selected check - id=%v", info.ID)
+	return true
+}
+
+// allChecks returns the built-in checks and the extra checks
+func allChecks(extra []check.Runner) []check.Runner {
+	checks := make([]check.Runner, 0, len(check.AllChecks)+len(extra))
+	checks = append(checks, check.AllChecks...)
+	return append(checks, extra...)
+}
+
+// isTargetCheck returns true if the check can run with the available target data
+// (the image scope checks need the image data and the other checks need the Dockerfile)
+func isTargetCheck(info *check.Info, hasDockerfile, hasImage bool) bool {
+	if info.Labels[check.LabelScope] == check.ScopeImage {
+		return hasImage
+	}
+
+	return hasDockerfile
+}
+
+// ListChecks returns the built-in checks and the extra checks
+func ListChecks(extra ...check.Runner) []*check.Info {
 	var list []*check.Info
-	for _, check := range check.AllChecks {
+	for _, check := range allChecks(extra) {
 		info := check.Get()
 		list = append(list, info)
 	}
@@ -0,0 +1,86 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+)
+
+func TestIsSelectedCheck(t *testing.T) {
+	imageCheck := &check.Info{
+		ID: "ID.30001",
+		Labels: map[string]string{
+			check.LabelLevel: check.LevelWarn,
+			check.LabelScope: check.ScopeImage,
+		},
+	}
+
+	dockerfileCheck := &check.Info{
+		ID: "ID.20001",
+		Labels: map[string]string{
+			check.LabelLevel: check.LevelError,
+			check.LabelScope: check.ScopeDockerfile,
+		},
+	}
+
+	tt := []struct {
+		name     string
+		selector CheckSelector
+		image    bool
+		dfile    bool
+	}{
+		{
+			name:  "all",
+			image: true,
+			dfile: true,
+		},
+		{
+			name:     "include ids",
+			selector: CheckSelector{IncludeCheckIDs: map[string]struct{}{"ID.30001": {}}},
+			image:    true,
+		},
+		{
+			name: "include ids ignore the labels",
+			selector: CheckSelector{
+				IncludeCheckIDs:    map[string]struct{}{"ID.20001": {}},
+				ExcludeCheckLabels: map[string]string{check.LabelScope: check.ScopeDockerfile},
+			},
+			dfile: true,
+		},
+		{
+			name:     "include labels",
+			selector: CheckSelector{IncludeCheckLabels: map[string]string{check.LabelLevel: check.LevelError}},
+			dfile:    true,
+		},
+		{
+			name: "include labels with excluded ids",
+			selector: CheckSelector{
+				IncludeCheckLabels: map[string]string{check.LabelScope: check.ScopeImage},
+				ExcludeCheckIDs:    map[string]struct{}{"ID.30001": {}},
+			},
+		},
+		{
+			name:     "exclude labels",
+			selector: CheckSelector{ExcludeCheckLabels: map[string]string{check.LabelScope: check.ScopeImage}},
+			dfile:    true,
+		},
+		{
+			name:     "exclude labels with a different value",
+			selector: CheckSelector{ExcludeCheckLabels: map[string]string{check.LabelLevel: check.LevelInfo}},
+			image:    true,
+			dfile:    true,
+		},
+		{
+			name:     "exclude ids",
+			selector: CheckSelector{ExcludeCheckIDs: map[string]struct{}{"ID.20001": {}}},
+			image:    true,
+		},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.image, isSelectedCheck(imageCheck, test.selector), test.name)
+		assert.Equal(t, test.dfile, isSelectedCheck(dockerfileCheck, test.selector), test.name)
+	}
+}
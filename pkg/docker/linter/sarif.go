@@ -0,0 +1,391 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+	v "github.com/slimtoolkit/slim/pkg/version"
+)
+
+// Lint result output formats
+const (
+	OutputSARIF = "sarif"
+	OutputJUnit = "junit"
+)
+
+func IsValidOutput(input string) bool {
+	switch input {
+	case OutputSARIF, OutputJUnit:
+		return true
+	}
+
+	return false
+}
+
+// OutputFileName returns the default output file name for the output format
+func OutputFileName(format string) string {
+	switch format {
+	case OutputSARIF:
+		return "slim.lint.sarif"
+	case OutputJUnit:
+		return "slim.lint.junit.xml"
+	}
+
+	return "slim.lint.out"
+}
+
+// Output returns the lint report in the selected output format
+func (ref *Report) Output(format string, target OutputTarget) ([]byte, error) {
+	switch format {
+	case OutputSARIF:
+		return ref.SARIF(target)
+	case OutputJUnit:
+		return ref.JUnit(target)
+	}
+
+	return nil, fmt.Errorf("unknown lint output format - %s", format)
+}
+
+// OutputTarget is the lint target info for the lint output formats
+type OutputTarget struct {
+	Type      string
+	Reference string
+}
+
+/////////////////////////////////////////////////////////////
+//
+// SARIF DOCS:
+//
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+// https://docs.github.com/en/code-security/code-scanning/integrating-with-code-scanning/sarif-support-for-code-scanning
+//
+/////////////////////////////////////////////////////////////
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "slim"
+	toolURI      = "https://github.com/slimtoolkit/slim"
+)
+
+// SARIF levels
+const (
+	sarifLevelError   = "error"
+	sarifLevelWarning = "warning"
+	sarifLevelNote    = "note"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name,omitempty"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	FullDescription      *sarifMessage       `json:"fullDescription,omitempty"`
+	HelpURI              string              `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration  `json:"defaultConfiguration"`
+	Properties           map[string][]string `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level      string           `json:"level"`
+	Message    sarifMessage     `json:"message"`
+	Descriptor *sarifDescriptor `json:"descriptor,omitempty"`
+}
+
+type sarifDescriptor struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// SARIF returns the lint report as a SARIF (2.1.0) log
+func (ref *Report) SARIF(target OutputTarget) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Version:        v.Tag(),
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	invocation := sarifInvocation{
+		ExecutionSuccessful: ref.Status == StatusComplete,
+	}
+
+	for _, id := range sortedIDs(ref.Errors) {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications,
+			sarifNotification{
+				Level:      sarifLevelError,
+				Message:    sarifMessage{Text: ref.Errors[id].Error()},
+				Descriptor: &sarifDescriptor{ID: id},
+			})
+	}
+
+	run.Invocations = append(run.Invocations, invocation)
+
+	dockerfileURI := ref.dockerfileURI()
+	for ruleIndex, id := range sortedIDs(ref.Hits) {
+		result := ref.Hits[id]
+		info := result.Source
+		level := sarifLevel(info.Labels[check.LabelLevel])
+
+		rule := sarifRule{
+			ID:                   info.ID,
+			Name:                 info.Name,
+			ShortDescription:     sarifMessage{Text: info.Name},
+			HelpURI:              info.DetailsURL,
+			DefaultConfiguration: sarifConfiguration{Level: level},
+		}
+
+		if info.Description != "" {
+			rule.FullDescription = &sarifMessage{Text: info.Description}
+		}
+
+		if scope := info.Labels[check.LabelScope]; scope != "" {
+			rule.Properties = map[string][]string{"tags": {scope}}
+		}
+
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+
+		if len(result.Matches) == 0 {
+			sr := sarifResult{
+				RuleID:    info.ID,
+				RuleIndex: ruleIndex,
+				Level:     level,
+				Message:   sarifMessage{Text: result.Message},
+			}
+
+			if location := targetLocation(dockerfileURI, target); location != nil {
+				sr.Locations = append(sr.Locations, *location)
+			}
+
+			run.Results = append(run.Results, sr)
+			continue
+		}
+
+		for _, match := range result.Matches {
+			sr := sarifResult{
+				RuleID:    info.ID,
+				RuleIndex: ruleIndex,
+				Level:     level,
+				Message:   sarifMessage{Text: matchMessage(result, match)},
+			}
+
+			if location := matchLocation(dockerfileURI, target, match); location != nil {
+				sr.Locations = append(sr.Locations, *location)
+			}
+
+			run.Results = append(run.Results, sr)
+		}
+	}
+
+	output := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func sarifLevel(level string) string {
+	switch level {
+	case check.LevelFatal, check.LevelError:
+		return sarifLevelError
+	case check.LevelWarn:
+		return sarifLevelWarning
+	}
+
+	return sarifLevelNote
+}
+
+// dockerfileURI returns the (relative, if possible) Dockerfile path
+// (for the image targets it's the name of the Dockerfile reconstructed from the image history)
+func (ref *Report) dockerfileURI() string {
+	if ref.Dockerfile == nil || ref.Dockerfile.Name == "" {
+		return ""
+	}
+
+	return filepath.ToSlash(filepath.Join(ref.Dockerfile.Location, ref.Dockerfile.Name))
+}
+
+func matchMessage(result *check.Result, match *check.Match) string {
+	if match.Message == "" {
+		return result.Message
+	}
+
+	if result.Message == "" {
+		return match.Message
+	}
+
+	return fmt.Sprintf("%s (%s)", result.Message, match.Message)
+}
+
+// matchLineRange returns the Dockerfile line range for the match (instruction or stage lines)
+func matchLineRange(match *check.Match) (int, int) {
+	switch {
+	case match.Instruction != nil && match.Instruction.StartLine > 0:
+		return match.Instruction.StartLine, match.Instruction.EndLine
+	case match.Stage != nil && match.Stage.StartLine > 0:
+		return match.Stage.StartLine, match.Stage.EndLine
+	}
+
+	return 0, 0
+}
+
+func matchLocation(dockerfileURI string, target OutputTarget, match *check.Match) *sarifLocation {
+	//image scope matches (image filesystem paths and image layers)
+	if match.Path != "" || match.Layer != nil {
+		name := match.Path
+		kind := "resource"
+		if name == "" {
+			name = fmt.Sprintf("layer:%d", *match.Layer)
+			kind = "module"
+		}
+
+		return &sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{
+				{
+					Name:               name,
+					FullyQualifiedName: fmt.Sprintf("%s:%s", target.Reference, name),
+					Kind:               kind,
+				},
+			},
+		}
+	}
+
+	if dockerfileURI == "" {
+		return targetLocation(dockerfileURI, target)
+	}
+
+	location := &sarifLocation{
+		PhysicalLocation: &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: dockerfileURI},
+		},
+	}
+
+	if startLine, endLine := matchLineRange(match); startLine > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{
+			StartLine: startLine,
+			EndLine:   endLine,
+		}
+	}
+
+	return location
+}
+
+// targetLocation returns the location for the results without the match info
+func targetLocation(dockerfileURI string, target OutputTarget) *sarifLocation {
+	if target.Type == ImageTargetType {
+		return &sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{
+				{
+					Name: target.Reference,
+					Kind: "module",
+				},
+			},
+		}
+	}
+
+	if dockerfileURI == "" {
+		return nil
+	}
+
+	return &sarifLocation{
+		PhysicalLocation: &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: dockerfileURI},
+		},
+	}
+}
+
+func sortedIDs[T any](m map[string]T) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,256 @@
+package linter
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/spec"
+	"github.com/slimtoolkit/slim/pkg/docker/instruction"
+	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+)
+
+var (
+	testDockerfileCheck = &check.Info{
+		ID:          "ID.20001",
+		Name:        "Bad instruction",
+		Description: "Instruction is bad",
+		DetailsURL:  "https://lint.dockersl.im/check/ID.20001",
+		Labels: map[string]string{
+			check.LabelLevel: check.LevelWarn,
+			check.LabelScope: check.ScopeInstruction,
+		},
+	}
+
+	testStageCheck = &check.Info{
+		ID:   "ID.20002",
+		Name: "No stage name",
+		Labels: map[string]string{
+			check.LabelLevel: check.LevelInfo,
+		},
+	}
+
+	testImageCheck = &check.Info{
+		ID:   "ID.30002",
+		Name: "Secret files in image",
+		Labels: map[string]string{
+			check.LabelLevel: check.LevelError,
+			check.LabelScope: check.ScopeImage,
+		},
+	}
+)
+
+func testReport() *Report {
+	layer := 2
+	report := NewReport()
+	report.Status = StatusComplete
+	report.Dockerfile = &spec.Dockerfile{Name: "Dockerfile", Location: "app"}
+	report.Hits[testDockerfileCheck.ID] = &check.Result{
+		Source:  testDockerfileCheck,
+		Hit:     true,
+		Message: "Bad instruction",
+		Matches: []*check.Match{
+			{
+				Instruction: &instruction.Field{StartLine: 3, EndLine: 5},
+				Message:     "RUN apt-get",
+			},
+			{
+				Stage: &spec.BuildStage{StartLine: 7, EndLine: 9},
+			},
+		},
+	}
+
+	report.Hits[testStageCheck.ID] = &check.Result{
+		Source:  testStageCheck,
+		Hit:     true,
+		Message: "No stage name",
+	}
+
+	report.Hits[testImageCheck.ID] = &check.Result{
+		Source:  testImageCheck,
+		Hit:     true,
+		Message: "Secret files in image",
+		Matches: []*check.Match{
+			{Path: "/root/.netrc", Layer: &layer, Message: "Secret file: path='/root/.netrc'"},
+			{Layer: &layer},
+		},
+	}
+
+	report.NoHits["ID.30008"] = &check.Result{
+		Source: &check.Info{ID: "ID.30008", Name: "Missing image healthcheck"},
+	}
+
+	report.Errors["ID.90001"] = errors.New("check failed")
+	return report
+}
+
+func decodeSARIF(t *testing.T, data []byte) *sarifLog {
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(data, &log))
+	require.Len(t, log.Runs, 1)
+	return &log
+}
+
+func TestSARIF(t *testing.T) {
+	data, err := testReport().SARIF(OutputTarget{Type: DockerfileTargetType, Reference: "app/Dockerfile"})
+	require.NoError(t, err)
+
+	log := decodeSARIF(t, data)
+	assert.Equal(t, sarifSchema, log.Schema)
+	assert.Equal(t, sarifVersion, log.Version)
+
+	run := log.Runs[0]
+	assert.Equal(t, toolName, run.Tool.Driver.Name)
+	require.Len(t, run.Invocations, 1)
+	assert.True(t, run.Invocations[0].ExecutionSuccessful)
+	assert.Equal(t, []sarifNotification{
+		{
+			Level:      sarifLevelError,
+			Message:    sarifMessage{Text: "check failed"},
+			Descriptor: &sarifDescriptor{ID: "ID.90001"},
+		},
+	}, run.Invocations[0].ToolExecutionNotifications)
+
+	//one rule for each check hit (sorted by the check ID)
+	require.Len(t, run.Tool.Driver.Rules, 3)
+	assert.Equal(t, sarifRule{
+		ID:                   "ID.20001",
+		Name:                 "Bad instruction",
+		ShortDescription:     sarifMessage{Text: "Bad instruction"},
+		FullDescription:      &sarifMessage{Text: "Instruction is bad"},
+		HelpURI:              "https://lint.dockersl.im/check/ID.20001",
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevelWarning},
+		Properties:           map[string][]string{"tags": {check.ScopeInstruction}},
+	}, run.Tool.Driver.Rules[0])
+	assert.Equal(t, "ID.20002", run.Tool.Driver.Rules[1].ID)
+	assert.Nil(t, run.Tool.Driver.Rules[1].FullDescription)
+	assert.Nil(t, run.Tool.Driver.Rules[1].Properties)
+	assert.Equal(t, sarifLevelNote, run.Tool.Driver.Rules[1].DefaultConfiguration.Level)
+	assert.Equal(t, sarifLevelError, run.Tool.Driver.Rules[2].DefaultConfiguration.Level)
+
+	//one result for each match (or one result for the check without matches)
+	require.Len(t, run.Results, 5)
+	assert.Equal(t, sarifResult{
+		RuleID:    "ID.20001",
+		RuleIndex: 0,
+		Level:     sarifLevelWarning,
+		Message:   sarifMessage{Text: "Bad instruction (RUN apt-get)"},
+		Locations: []sarifLocation{
+			{
+				PhysicalLocation: &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "app/Dockerfile"},
+					Region:           &sarifRegion{StartLine: 3, EndLine: 5},
+				},
+			},
+		},
+	}, run.Results[0])
+
+	assert.Equal(t, "Bad instruction", run.Results[1].Message.Text)
+	assert.Equal(t, &sarifRegion{StartLine: 7, EndLine: 9}, run.Results[1].Locations[0].PhysicalLocation.Region)
+
+	assert.Equal(t, sarifResult{
+		RuleID:    "ID.20002",
+		RuleIndex: 1,
+		Level:     sarifLevelNote,
+		Message:   sarifMessage{Text: "No stage name"},
+		Locations: []sarifLocation{
+			{
+				PhysicalLocation: &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "app/Dockerfile"},
+				},
+			},
+		},
+	}, run.Results[2])
+
+	assert.Equal(t, 2, run.Results[3].RuleIndex)
+	assert.Equal(t, []sarifLogicalLocation{
+		{
+			Name:               "/root/.netrc",
+			FullyQualifiedName: "app/Dockerfile:/root/.netrc",
+			Kind:               "resource",
+		},
+	}, run.Results[3].Locations[0].LogicalLocations)
+	assert.Equal(t, []sarifLogicalLocation{
+		{
+			Name:               "layer:2",
+			FullyQualifiedName: "app/Dockerfile:layer:2",
+			Kind:               "module",
+		},
+	}, run.Results[4].Locations[0].LogicalLocations)
+}
+
+func TestSARIFImageTarget(t *testing.T) {
+	report := testReport()
+	report.Status = StatusTimedOut
+	report.Dockerfile = nil
+	report.Errors = map[string]error{}
+
+	data, err := report.SARIF(OutputTarget{Type: ImageTargetType, Reference: "app:latest"})
+	require.NoError(t, err)
+
+	run := decodeSARIF(t, data).Runs[0]
+	assert.False(t, run.Invocations[0].ExecutionSuccessful)
+	assert.Empty(t, run.Invocations[0].ToolExecutionNotifications)
+
+	require.Len(t, run.Results, 5)
+	//the matches without the Dockerfile location are reported for the image
+	assert.Equal(t, []sarifLocation{
+		{LogicalLocations: []sarifLogicalLocation{{Name: "app:latest", Kind: "module"}}},
+	}, run.Results[0].Locations)
+	assert.Equal(t, []sarifLocation{
+		{LogicalLocations: []sarifLogicalLocation{{Name: "app:latest", Kind: "module"}}},
+	}, run.Results[2].Locations)
+	assert.Equal(t, "app:latest:/root/.netrc", run.Results[3].Locations[0].LogicalLocations[0].FullyQualifiedName)
+}
+
+func TestSARIFEmpty(t *testing.T) {
+	report := NewReport()
+	data, err := report.SARIF(OutputTarget{Type: DockerfileTargetType})
+	require.NoError(t, err)
+
+	run := decodeSARIF(t, data).Runs[0]
+	assert.NotNil(t, run.Tool.Driver.Rules)
+	assert.NotNil(t, run.Results)
+	assert.Contains(t, string(data), `"results": []`)
+	assert.Contains(t, string(data), `"rules": []`)
+}
+
+func TestOutput(t *testing.T) {
+	report := testReport()
+	target := OutputTarget{Type: DockerfileTargetType, Reference: "app/Dockerfile"}
+	for _, format := range []string{OutputSARIF, OutputJUnit} {
+		assert.True(t, IsValidOutput(format), format)
+		data, err := report.Output(format, target)
+		require.NoError(t, err, format)
+		assert.NotEmpty(t, data, format)
+	}
+
+	assert.False(t, IsValidOutput("json"))
+	_, err := report.Output("json", target)
+	assert.Error(t, err)
+
+	assert.Equal(t, "slim.lint.sarif", OutputFileName(OutputSARIF))
+	assert.Equal(t, "slim.lint.junit.xml", OutputFileName(OutputJUnit))
+	assert.Equal(t, "slim.lint.out", OutputFileName("json"))
+}
+
+func TestMatchMessage(t *testing.T) {
+	tt := []struct {
+		result   string
+		match    string
+		expected string
+	}{
+		{result: "Result", match: "Match", expected: "Result (Match)"},
+		{result: "Result", expected: "Result"},
+		{match: "Match", expected: "Match"},
+		{},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected,
+			matchMessage(&check.Result{Message: test.result}, &check.Match{Message: test.match}))
+	}
+}
@@ -95,6 +95,9 @@ type SimpleBuildOptions struct {
 	Tags        []string
 	Layers      []LayerDataInfo
 	ImageConfig ImageConfig
+	//Platforms are used to build multi-platform images (image index)
+	//where each platform image has its own layers
+	Platforms []PlatformBuildOptions
 
 	/*
 	   //todo:  add 'Healthcheck'
@@ -112,6 +115,15 @@ type SimpleBuildOptions struct {
 	*/
 }
 
+// PlatformBuildOptions describes the platform specific image in a multi-platform image
+type PlatformBuildOptions struct {
+	//Architecture can also include the variant (e.g., arm/v7)
+	Architecture string
+	Variant      string
+	//Layers for the platform image (the common layers are used if empty)
+	Layers []LayerDataInfo
+}
+
 type LayerSourceType string
 
 const (
@@ -136,6 +148,9 @@ type ImageResult struct {
 	Digest    string   `json:"digest,omitempty"`
 	Name      string   `json:"name,omitempty"`
 	OtherTags []string `json:"other_tags,omitempty"`
+	Platform  string   `json:"platform,omitempty"`
+	//Manifests are the platform images (for multi-platform images)
+	Manifests []*ImageResult `json:"manifests,omitempty"`
 }
 
 type SimpleBuildEngine interface {
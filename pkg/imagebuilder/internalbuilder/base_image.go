@@ -0,0 +1,177 @@
+package internalbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/imagereader"
+)
+
+// loadBaseImage loads the base image from its location. Supported base image values:
+// * docker-daemon:IMAGE
+// * docker-archive:PATH[:IMAGE]
+// * oci:PATH[:REF]
+// * registry:IMAGE (or docker://IMAGE)
+// * IMAGE (the local Docker daemon is checked first and then the registry)
+func (ref *Engine) loadBaseImage(from string, platform *v1.Platform) (v1.Image, error) {
+	remoteOpts := ref.remoteOptions()
+	if location, err := imagereader.ParseLocation(from, ""); err == nil {
+		return loadBaseImageFromLocation(location, platform, remoteOpts)
+	}
+
+	daemonLocation := &imagereader.Location{
+		Type: imagereader.DockerDaemonLocation,
+		Ref:  from,
+	}
+
+	img, err := loadBaseImageFromLocation(daemonLocation, platform, remoteOpts)
+	if err == nil {
+		return img, nil
+	}
+
+	log.Debugf("DefaultSimpleBuilder.loadBaseImage: no base image in Docker (%v), trying registry", err)
+	registryLocation := &imagereader.Location{
+		Type: imagereader.RegistryLocation,
+		Ref:  from,
+	}
+
+	return loadBaseImageFromLocation(registryLocation, platform, remoteOpts)
+}
+
+func (ref *Engine) remoteOptions() []remote.Option {
+	if len(ref.RemoteOptions) > 0 {
+		return ref.RemoteOptions
+	}
+
+	return []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+}
+
+func loadBaseImageFromLocation(
+	location *imagereader.Location,
+	platform *v1.Platform,
+	remoteOpts []remote.Option) (v1.Image, error) {
+	log.Debugf("DefaultSimpleBuilder.loadBaseImage: location=%s platform=%s", location, platform)
+	img, err := imagereader.LoadPlatformImage(location, platform, remoteOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	//also checking that the (lazy loaded) image is actually available
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if platform != nil {
+		imgPlatform := cf.Platform()
+		if imgPlatform == nil || !imgPlatform.Satisfies(*platform) {
+			return nil, fmt.Errorf("base image platform mismatch (%s) - %s", platform, location)
+		}
+	}
+
+	return img, nil
+}
+
+// mergeBaseConfig merges the base image config with the new image config
+// (the new config values take precedence; the same as in the Dockerfile builds)
+func mergeBaseConfig(base *v1.ConfigFile, config *v1.ConfigFile) {
+	config.RootFS = base.RootFS
+	config.History = base.History
+
+	if config.Architecture == "" {
+		config.Architecture = base.Architecture
+	}
+
+	if config.Variant == "" {
+		config.Variant = base.Variant
+	}
+
+	if config.OSVersion == "" {
+		config.OSVersion = base.OSVersion
+	}
+
+	if len(config.OSFeatures) == 0 {
+		config.OSFeatures = base.OSFeatures
+	}
+
+	bc := base.Config
+	c := &config.Config
+
+	c.Env = mergeEnv(bc.Env, c.Env)
+	c.Labels = mergeMaps(bc.Labels, c.Labels)
+	c.ExposedPorts = mergeMaps(bc.ExposedPorts, c.ExposedPorts)
+	c.Volumes = mergeMaps(bc.Volumes, c.Volumes)
+
+	//a new ENTRYPOINT resets the base image CMD
+	if len(c.Entrypoint) == 0 {
+		c.Entrypoint = bc.Entrypoint
+		if len(c.Cmd) == 0 {
+			c.Cmd = bc.Cmd
+		}
+	}
+
+	if c.User == "" {
+		c.User = bc.User
+	}
+
+	if c.WorkingDir == "" {
+		c.WorkingDir = bc.WorkingDir
+	}
+
+	if c.StopSignal == "" {
+		c.StopSignal = bc.StopSignal
+	}
+
+	if c.Healthcheck == nil {
+		c.Healthcheck = bc.Healthcheck
+	}
+
+	if len(c.Shell) == 0 {
+		c.Shell = bc.Shell
+	}
+}
+
+func mergeEnv(base, current []string) []string {
+	if len(base) == 0 {
+		return current
+	}
+
+	keyIdx := map[string]int{}
+	var out []string
+	for _, list := range [][]string{base, current} {
+		for _, kv := range list {
+			key, _, _ := strings.Cut(kv, "=")
+			if idx, found := keyIdx[key]; found {
+				out[idx] = kv
+				continue
+			}
+
+			keyIdx[key] = len(out)
+			out = append(out, kv)
+		}
+	}
+
+	return out
+}
+
+func mergeMaps[T any](base, current map[string]T) map[string]T {
+	if len(base) == 0 {
+		return current
+	}
+
+	out := make(map[string]T, len(base)+len(current))
+	for k, v := range base {
+		out[k] = v
+	}
+
+	for k, v := range current {
+		out[k] = v
+	}
+
+	return out
+}
@@ -0,0 +1,205 @@
+package internalbuilder
+
+import (
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeEnv(t *testing.T) {
+	tt := []struct {
+		name     string
+		base     []string
+		current  []string
+		expected []string
+	}{
+		{
+			name:     "no base env",
+			current:  []string{"PATH=/app/bin", "MODE=prod"},
+			expected: []string{"PATH=/app/bin", "MODE=prod"},
+		},
+		{
+			name:     "no new env",
+			base:     []string{"PATH=/usr/bin", "SSL_CERT_FILE=/etc/ssl/certs/ca-certificates.crt"},
+			expected: []string{"PATH=/usr/bin", "SSL_CERT_FILE=/etc/ssl/certs/ca-certificates.crt"},
+		},
+		{
+			//the new value keeps the base var position
+			name:     "new PATH overrides the base PATH",
+			base:     []string{"PATH=/usr/local/bin:/usr/bin", "LANG=C.UTF-8"},
+			current:  []string{"MODE=prod", "PATH=/app/bin:/usr/bin"},
+			expected: []string{"PATH=/app/bin:/usr/bin", "LANG=C.UTF-8", "MODE=prod"},
+		},
+		{
+			name:     "base PATH is kept",
+			base:     []string{"PATH=/usr/bin"},
+			current:  []string{"MODE=prod"},
+			expected: []string{"PATH=/usr/bin", "MODE=prod"},
+		},
+		{
+			name:     "empty and valueless vars",
+			base:     []string{"DEBUG=1", "FLAG"},
+			current:  []string{"DEBUG=", "FLAG=on", "EMPTY"},
+			expected: []string{"DEBUG=", "FLAG=on", "EMPTY"},
+		},
+		{
+			name:     "duplicate vars",
+			base:     []string{"A=1", "A=2"},
+			current:  []string{"A=3", "B=1", "B=2"},
+			expected: []string{"A=3", "B=2"},
+		},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, mergeEnv(test.base, test.current), test.name)
+	}
+}
+
+func TestMergeBaseConfig(t *testing.T) {
+	newBase := func() *v1.ConfigFile {
+		return &v1.ConfigFile{
+			Architecture: "arm",
+			Variant:      "v7",
+			OS:           "linux",
+			OSVersion:    "base",
+			RootFS:       v1.RootFS{Type: "layers", DiffIDs: []v1.Hash{{Algorithm: "sha256", Hex: "base"}}},
+			History:      []v1.History{{CreatedBy: "base"}},
+			Config: v1.Config{
+				Env:          []string{"PATH=/usr/bin", "LANG=C.UTF-8"},
+				Labels:       map[string]string{"vendor": "base", "version": "1"},
+				ExposedPorts: map[string]struct{}{"443/tcp": {}},
+				Volumes:      map[string]struct{}{"/data": {}},
+				Entrypoint:   []string{"/bin/sh", "-c"},
+				Cmd:          []string{"base-cmd"},
+				User:         "nonroot",
+				WorkingDir:   "/home/nonroot",
+				StopSignal:   "SIGQUIT",
+				Healthcheck:  &v1.HealthConfig{Test: []string{"CMD", "base-check"}},
+				Shell:        []string{"/bin/sh", "-c"},
+			},
+		}
+	}
+
+	tt := []struct {
+		name     string
+		config   v1.ConfigFile
+		expected v1.Config
+		check    func(t *testing.T, cf *v1.ConfigFile)
+	}{
+		{
+			name:   "base values",
+			config: v1.ConfigFile{},
+			expected: v1.Config{
+				Env:          []string{"PATH=/usr/bin", "LANG=C.UTF-8"},
+				Labels:       map[string]string{"vendor": "base", "version": "1"},
+				ExposedPorts: map[string]struct{}{"443/tcp": {}},
+				Volumes:      map[string]struct{}{"/data": {}},
+				Entrypoint:   []string{"/bin/sh", "-c"},
+				Cmd:          []string{"base-cmd"},
+				User:         "nonroot",
+				WorkingDir:   "/home/nonroot",
+				StopSignal:   "SIGQUIT",
+				Healthcheck:  &v1.HealthConfig{Test: []string{"CMD", "base-check"}},
+				Shell:        []string{"/bin/sh", "-c"},
+			},
+			check: func(t *testing.T, cf *v1.ConfigFile) {
+				assert.Equal(t, "arm", cf.Architecture)
+				assert.Equal(t, "v7", cf.Variant)
+				assert.Equal(t, "base", cf.OSVersion)
+			},
+		},
+		{
+			name: "new values take precedence",
+			config: v1.ConfigFile{
+				Architecture: "arm64",
+				Variant:      "v8",
+				Config: v1.Config{
+					Env:          []string{"PATH=/app/bin:/usr/bin", "MODE=prod"},
+					Labels:       map[string]string{"version": "2"},
+					ExposedPorts: map[string]struct{}{"8080/tcp": {}},
+					Entrypoint:   []string{"/app/server"},
+					Cmd:          []string{"--port", "8080"},
+					User:         "app",
+					WorkingDir:   "/app",
+					StopSignal:   "SIGTERM",
+					Healthcheck:  &v1.HealthConfig{Test: []string{"NONE"}},
+				},
+			},
+			expected: v1.Config{
+				Env:          []string{"PATH=/app/bin:/usr/bin", "LANG=C.UTF-8", "MODE=prod"},
+				Labels:       map[string]string{"vendor": "base", "version": "2"},
+				ExposedPorts: map[string]struct{}{"443/tcp": {}, "8080/tcp": {}},
+				Volumes:      map[string]struct{}{"/data": {}},
+				Entrypoint:   []string{"/app/server"},
+				Cmd:          []string{"--port", "8080"},
+				User:         "app",
+				WorkingDir:   "/app",
+				StopSignal:   "SIGTERM",
+				Healthcheck:  &v1.HealthConfig{Test: []string{"NONE"}},
+				Shell:        []string{"/bin/sh", "-c"},
+			},
+			check: func(t *testing.T, cf *v1.ConfigFile) {
+				assert.Equal(t, "arm64", cf.Architecture)
+				assert.Equal(t, "v8", cf.Variant)
+			},
+		},
+		{
+			//the same as a new ENTRYPOINT instruction in a Dockerfile
+			name: "new entrypoint resets the base cmd",
+			config: v1.ConfigFile{
+				Config: v1.Config{Entrypoint: []string{"/app/server"}},
+			},
+			expected: v1.Config{
+				Env:          []string{"PATH=/usr/bin", "LANG=C.UTF-8"},
+				Labels:       map[string]string{"vendor": "base", "version": "1"},
+				ExposedPorts: map[string]struct{}{"443/tcp": {}},
+				Volumes:      map[string]struct{}{"/data": {}},
+				Entrypoint:   []string{"/app/server"},
+				User:         "nonroot",
+				WorkingDir:   "/home/nonroot",
+				StopSignal:   "SIGQUIT",
+				Healthcheck:  &v1.HealthConfig{Test: []string{"CMD", "base-check"}},
+				Shell:        []string{"/bin/sh", "-c"},
+			},
+		},
+		{
+			name: "new cmd keeps the base entrypoint",
+			config: v1.ConfigFile{
+				Config: v1.Config{Cmd: []string{"serve"}},
+			},
+			expected: v1.Config{
+				Env:          []string{"PATH=/usr/bin", "LANG=C.UTF-8"},
+				Labels:       map[string]string{"vendor": "base", "version": "1"},
+				ExposedPorts: map[string]struct{}{"443/tcp": {}},
+				Volumes:      map[string]struct{}{"/data": {}},
+				Entrypoint:   []string{"/bin/sh", "-c"},
+				Cmd:          []string{"serve"},
+				User:         "nonroot",
+				WorkingDir:   "/home/nonroot",
+				StopSignal:   "SIGQUIT",
+				Healthcheck:  &v1.HealthConfig{Test: []string{"CMD", "base-check"}},
+				Shell:        []string{"/bin/sh", "-c"},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		base := newBase()
+		config := test.config
+		mergeBaseConfig(base, &config)
+		assert.Equal(t, test.expected, config.Config, test.name)
+
+		//the output image layers are added on top of the base image layers
+		assert.Equal(t, base.RootFS, config.RootFS, test.name)
+		assert.Equal(t, base.History, config.History, test.name)
+
+		//the base config maps are not modified
+		assert.Equal(t, newBase().Config.Labels, base.Config.Labels, test.name)
+		assert.Equal(t, newBase().Config.ExposedPorts, base.Config.ExposedPorts, test.name)
+
+		if test.check != nil {
+			test.check(t, &config)
+		}
+	}
+}
@@ -12,10 +12,11 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
-	"github.com/google/go-containerregistry/pkg/v1/daemon"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/slimtoolkit/slim/pkg/imagebuilder"
@@ -31,6 +32,14 @@ type Engine struct {
 	ShowBuildLogs  bool
 	PushToDaemon   bool
 	PushToRegistry bool
+	//OutputImageTar is an optional path where the image is saved (as a 'docker save' compatible tar)
+	OutputImageTar string
+	//OutputImageLayout is an optional OCI image layout directory path where the image
+	//(or the multi-platform image index) is saved
+	OutputImageLayout string
+	//RemoteOptions are used to pull the base images from registries and to push the output images
+	//(the default Docker config credentials are used if not set)
+	RemoteOptions []remote.Option
 }
 
 // New creates new Engine instances
@@ -49,7 +58,86 @@ func New(
 }
 
 func (ref *Engine) Build(options imagebuilder.SimpleBuildOptions) (*imagebuilder.ImageResult, error) {
-	if len(options.ImageConfig.Config.Entrypoint) == 0 &&
+	if len(options.Tags) == 0 {
+		return nil, fmt.Errorf("missing tags")
+	}
+
+	tag, err := name.NewTag(options.Tags[0])
+	if err != nil {
+		return nil, err
+	}
+
+	otherTags := options.Tags[1:]
+
+	if len(options.Platforms) > 0 {
+		return ref.buildImageIndex(options, tag, otherTags)
+	}
+
+	newImg, err := ref.buildImage(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.PushToDaemon {
+		if err := saveToDaemon(newImg, tag, otherTags); err != nil {
+			return nil, err
+		}
+
+		if ref.ShowBuildLogs {
+			//TBD (need execution context to display the build logs)
+		}
+	}
+
+	if ref.PushToRegistry {
+		log.Debug("DefaultSimpleBuilder.Build: pushing image to registry")
+		if err := remote.Write(tag, newImg, ref.remoteOptions()...); err != nil {
+			return nil, err
+		}
+
+		if err := ref.tagInRegistry(newImg, otherTags); err != nil {
+			return nil, err
+		}
+	}
+
+	if ref.OutputImageTar != "" {
+		log.Debugf("DefaultSimpleBuilder.Build: saving image to tar - %s", ref.OutputImageTar)
+		refToImage := map[name.Reference]v1.Image{tag: newImg}
+		for _, tagName := range otherTags {
+			ntag, err := name.NewTag(tagName)
+			if err != nil {
+				log.Errorf("DefaultSimpleBuilder.Build: error creating tag: %v", err)
+				continue
+			}
+
+			refToImage[ntag] = newImg
+		}
+
+		if err := tarball.MultiRefWriteToFile(ref.OutputImageTar, refToImage); err != nil {
+			return nil, err
+		}
+	}
+
+	if ref.OutputImageLayout != "" {
+		log.Debugf("DefaultSimpleBuilder.Build: saving image to OCI image layout - %s", ref.OutputImageLayout)
+		if err := saveToLayout(ref.OutputImageLayout, newImg, options.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := imageResult(newImg)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Name = options.Tags[0]
+	result.OtherTags = otherTags
+	return result, nil
+}
+
+// buildImage creates a single platform image
+func (ref *Engine) buildImage(options imagebuilder.SimpleBuildOptions) (v1.Image, error) {
+	if options.From == "" &&
+		len(options.ImageConfig.Config.Entrypoint) == 0 &&
 		len(options.ImageConfig.Config.Cmd) == 0 {
 		return nil, fmt.Errorf("missing startup info")
 	}
@@ -62,20 +150,70 @@ func (ref *Engine) Build(options imagebuilder.SimpleBuildOptions) (*imagebuilder
 		return nil, fmt.Errorf("too many layers")
 	}
 
-	switch options.ImageConfig.Architecture {
-	case "":
-		options.ImageConfig.Architecture = "amd64"
-	case "arm64", "amd64":
-	default:
-		return nil, fmt.Errorf("bad architecture value")
+	if options.ImageConfig.Architecture == "" {
+		if options.From == "" {
+			options.ImageConfig.Architecture = "amd64"
+		}
+	} else {
+		arch, variant, err := NormalizePlatform(options.ImageConfig.Architecture, options.ImageConfig.Variant)
+		if err != nil {
+			return nil, err
+		}
+
+		options.ImageConfig.Architecture = arch
+		options.ImageConfig.Variant = variant
 	}
 
 	var img v1.Image
+	var baseConfig *v1.ConfigFile
+	var layerOpts []tarball.LayerOption
 	if options.From == "" {
 		//same as FROM scratch
 		img = empty.Image
 	} else {
-		return nil, fmt.Errorf("custom base images are not supported yet")
+		var platform *v1.Platform
+		if options.ImageConfig.Architecture != "" {
+			platform = &v1.Platform{
+				OS:           options.ImageConfig.OS,
+				Architecture: options.ImageConfig.Architecture,
+				Variant:      options.ImageConfig.Variant,
+			}
+
+			if platform.OS == "" {
+				platform.OS = "linux"
+			}
+		}
+
+		log.Debugf("DefaultSimpleBuilder.Build: loading base image - %s", options.From)
+		baseImg, err := ref.loadBaseImage(options.From, platform)
+		if err != nil {
+			return nil, fmt.Errorf("base image load error - %s (%w)", options.From, err)
+		}
+
+		baseConfig, err = baseImg.ConfigFile()
+		if err != nil {
+			return nil, err
+		}
+
+		if baseConfig.OS != "" && baseConfig.OS != "linux" {
+			return nil, fmt.Errorf("unsupported base image OS - %s", baseConfig.OS)
+		}
+
+		if _, _, err := NormalizePlatform(baseConfig.Architecture, baseConfig.Variant); err != nil {
+			return nil, fmt.Errorf("unsupported base image platform - %w", err)
+		}
+
+		mediaType, err := baseImg.MediaType()
+		if err != nil {
+			return nil, err
+		}
+
+		if mediaType == types.OCIManifestSchema1 {
+			//keeping the layer media types consistent with the base image manifest
+			layerOpts = append(layerOpts, tarball.WithMediaType(types.OCILayer))
+		}
+
+		img = baseImg
 	}
 
 	imgRunConfig := v1.Config{
@@ -140,6 +278,14 @@ func (ref *Engine) Build(options imagebuilder.SimpleBuildOptions) (*imagebuilder
 		imgConfig.Created = v1.Time{Time: options.ImageConfig.Created}
 	}
 
+	if baseConfig != nil {
+		mergeBaseConfig(baseConfig, imgConfig)
+		if len(imgConfig.Config.Entrypoint) == 0 &&
+			len(imgConfig.Config.Cmd) == 0 {
+			return nil, fmt.Errorf("missing startup info")
+		}
+	}
+
 	log.Debug("DefaultSimpleBuilder.Build: config image")
 
 	img, err := mutate.ConfigFile(img, imgConfig)
@@ -171,7 +317,7 @@ func (ref *Engine) Build(options imagebuilder.SimpleBuildOptions) (*imagebuilder
 				return nil, fmt.Errorf("image layer data source path is not a tar file - %s", layerInfo.Source)
 			}
 
-			layer, err := layerFromTar(layerInfo)
+			layer, err := layerFromTar(layerInfo, layerOpts...)
 			if err != nil {
 				return nil, err
 			}
@@ -182,7 +328,7 @@ func (ref *Engine) Build(options imagebuilder.SimpleBuildOptions) (*imagebuilder
 				return nil, fmt.Errorf("image layer data source path is not a directory - %s", layerInfo.Source)
 			}
 
-			layer, err := layerFromDir(layerInfo)
+			layer, err := layerFromDir(layerInfo, layerOpts...)
 			if err != nil {
 				return nil, err
 			}
@@ -194,77 +340,43 @@ func (ref *Engine) Build(options imagebuilder.SimpleBuildOptions) (*imagebuilder
 	}
 
 	log.Debug("DefaultSimpleBuilder.Build: adding layers to image")
-	newImg, err := mutate.AppendLayers(img, layersToAdd...)
-	if err != nil {
-		return nil, err
-	}
+	var newImg v1.Image
+	if baseConfig != nil {
+		//the base image history needs to match its layers, so the new layers get history records too
+		var addendums []mutate.Addendum
+		for _, layer := range layersToAdd {
+			addendums = append(addendums, mutate.Addendum{
+				Layer: layer,
+				History: v1.History{
+					Created:   imgConfig.Created,
+					Author:    imgConfig.Author,
+					CreatedBy: "slim internal build engine",
+				},
+			})
+		}
 
-	if len(options.Tags) == 0 {
-		return nil, fmt.Errorf("missing tags")
+		newImg, err = mutate.Append(img, addendums...)
+	} else {
+		newImg, err = mutate.AppendLayers(img, layersToAdd...)
 	}
 
-	tag, err := name.NewTag(options.Tags[0])
 	if err != nil {
 		return nil, err
 	}
 
-	otherTags := options.Tags[1:]
-
-	if ref.PushToDaemon {
-		log.Debug("DefaultSimpleBuilder.Build: saving image to Docker")
-		imageLoadResponseStr, err := daemon.Write(tag, newImg)
-		if err != nil {
-			return nil, err
-		}
-
-		log.Debugf("DefaultSimpleBuilder.Build: pushed image to daemon - %s", imageLoadResponseStr)
-		if ref.ShowBuildLogs {
-			//TBD (need execution context to display the build logs)
-		}
-
-		if len(otherTags) > 0 {
-			log.Debug("DefaultSimpleBuilder.Build: adding other tags")
-
-			for _, tagName := range otherTags {
-				ntag, err := name.NewTag(tagName)
-				if err != nil {
-					log.Errorf("DefaultSimpleBuilder.Build: error creating tag: %v", err)
-					continue
-				}
-
-				if err := daemon.Tag(tag, ntag); err != nil {
-					log.Errorf("DefaultSimpleBuilder.Build: error tagging: %v", err)
-				}
-			}
-		}
-	}
-
-	if ref.PushToRegistry {
-		//TBD
-	}
-
-	id, _ := newImg.ConfigName()
-	digest, _ := newImg.Digest()
-	result := &imagebuilder.ImageResult{
-		Name:      options.Tags[0],
-		OtherTags: otherTags,
-		ID:        fmt.Sprintf("%s:%s", id.Algorithm, id.Hex),
-		Digest:    fmt.Sprintf("%s:%s", digest.Algorithm, digest.Hex),
-	}
-
-	return result, nil
+	return newImg, nil
 }
 
-func layerFromTar(input imagebuilder.LayerDataInfo) (v1.Layer, error) {
+func layerFromTar(input imagebuilder.LayerDataInfo, opts ...tarball.LayerOption) (v1.Layer, error) {
 	if !fsutil.Exists(input.Source) ||
 		!fsutil.IsRegularFile(input.Source) {
 		return nil, fmt.Errorf("bad input data")
 	}
 
-	return tarball.LayerFromFile(input.Source)
+	return tarball.LayerFromFile(input.Source, opts...)
 }
 
-func layerFromDir(input imagebuilder.LayerDataInfo) (v1.Layer, error) {
+func layerFromDir(input imagebuilder.LayerDataInfo, opts ...tarball.LayerOption) (v1.Layer, error) {
 	if !fsutil.Exists(input.Source) ||
 		!fsutil.IsDir(input.Source) {
 		return nil, fmt.Errorf("bad input data")
@@ -287,32 +399,37 @@ func layerFromDir(input imagebuilder.LayerDataInfo) (v1.Layer, error) {
 			return fmt.Errorf("failed to calculate relative path: %w", err)
 		}
 
-		hdr := &tar.Header{
-			Name: path.Join(layerBasePath, filepath.ToSlash(rel)),
-			Mode: int64(info.Mode()),
+		var linkTarget string
+		if info.Mode()&os.ModeSymlink != 0 {
+			linkTarget, err = os.Readlink(fp)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink: %w", err)
+			}
 		}
 
-		if !info.IsDir() {
-			hdr.Size = info.Size()
+		hdr, err := tar.FileInfoHeader(info, linkTarget)
+		if err != nil {
+			return fmt.Errorf("failed to create tar header: %w", err)
 		}
 
-		if info.Mode().IsDir() {
-			hdr.Typeflag = tar.TypeDir
-		} else if info.Mode().IsRegular() {
-			hdr.Typeflag = tar.TypeReg
-		} else {
+		hdr.Name = path.Join(layerBasePath, filepath.ToSlash(rel))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir, tar.TypeReg, tar.TypeSymlink:
+		default:
 			return fmt.Errorf("not implemented archiving file type %s (%s)", info.Mode(), rel)
 		}
 
 		if err := tw.WriteHeader(hdr); err != nil {
 			return fmt.Errorf("failed to write tar header: %w", err)
 		}
-		if !info.IsDir() {
+		if hdr.Typeflag == tar.TypeReg {
 			f, err := os.Open(fp)
 			if err != nil {
 				return err
 			}
 			if _, err := io.Copy(tw, f); err != nil {
+				f.Close()
 				return fmt.Errorf("failed to read file into the tar: %w", err)
 			}
 			f.Close()
@@ -327,5 +444,5 @@ func layerFromDir(input imagebuilder.LayerDataInfo) (v1.Layer, error) {
 		return nil, fmt.Errorf("failed to finish tar: %w", err)
 	}
 
-	return tarball.LayerFromReader(&b)
+	return tarball.LayerFromReader(&b, opts...)
 }
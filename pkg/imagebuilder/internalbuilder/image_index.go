@@ -0,0 +1,50 @@
+package internalbuilder
+
+import (
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// ImageIndexAddendum creates the image index record for the image
+// (the record platform is taken from the image config)
+func ImageIndexAddendum(img v1.Image) (*mutate.IndexAddendum, error) {
+	imgConfig, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := partial.Descriptor(img)
+	if err != nil {
+		return nil, err
+	}
+
+	desc.Platform = imgConfig.Platform()
+	return &mutate.IndexAddendum{
+		Add:        img,
+		Descriptor: *desc,
+	}, nil
+}
+
+// NewImageIndex creates a new image index (aka manifest list) with the images
+// (using the OCI image index media type by default)
+func NewImageIndex(images []v1.Image, asManifestList bool) (v1.ImageIndex, error) {
+	imageIndex := v1.ImageIndex(empty.Index)
+	if asManifestList {
+		imageIndex = mutate.IndexMediaType(imageIndex, types.DockerManifestList)
+	}
+
+	addendums := make([]mutate.IndexAddendum, 0, len(images))
+	for _, img := range images {
+		addendum, err := ImageIndexAddendum(img)
+		if err != nil {
+			return nil, err
+		}
+
+		addendums = append(addendums, *addendum)
+	}
+
+	return mutate.AppendManifests(imageIndex, addendums...), nil
+}
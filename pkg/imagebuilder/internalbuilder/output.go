@@ -0,0 +1,267 @@
+package internalbuilder
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/imagebuilder"
+	"github.com/slimtoolkit/slim/pkg/imagereader"
+)
+
+// NormalizePlatform validates the target image architecture and variant
+// (the architecture can also include the variant, e.g., arm/v7;
+// the variant is never defaulted because it needs to match the image binaries)
+func NormalizePlatform(arch, variant string) (string, string, error) {
+	if archName, archVariant, found := strings.Cut(arch, "/"); found {
+		if variant != "" && variant != archVariant {
+			return "", "", fmt.Errorf("conflicting architecture variant values (%s and %s)", archVariant, variant)
+		}
+
+		arch = archName
+		variant = archVariant
+	}
+
+	switch arch {
+	case "amd64":
+		switch variant {
+		case "", "v1", "v2", "v3", "v4":
+			return arch, variant, nil
+		}
+	case "arm64":
+		switch variant {
+		case "", "v8":
+			return arch, variant, nil
+		}
+	case "arm":
+		switch variant {
+		case "", "v5", "v6", "v7":
+			return arch, variant, nil
+		}
+	case "ppc64le", "s390x", "riscv64":
+		if variant == "" {
+			return arch, variant, nil
+		}
+	default:
+		return "", "", fmt.Errorf("bad architecture value - %s", arch)
+	}
+
+	return "", "", fmt.Errorf("bad architecture variant value - %s/%s", arch, variant)
+}
+
+// buildImageIndex creates a multi-platform image (image index) with an image for each target platform
+func (ref *Engine) buildImageIndex(
+	options imagebuilder.SimpleBuildOptions,
+	tag name.Tag,
+	otherTags []string) (*imagebuilder.ImageResult, error) {
+	if ref.OutputImageTar != "" {
+		return nil, fmt.Errorf("multi-platform images can't be saved as Docker image tar files (use OCI image layout)")
+	}
+
+	var images []v1.Image
+	var results []*imagebuilder.ImageResult
+	var nativeImage v1.Image
+	platforms := map[string]struct{}{}
+	for _, platformOptions := range options.Platforms {
+		arch, variant, err := NormalizePlatform(platformOptions.Architecture, platformOptions.Variant)
+		if err != nil {
+			return nil, err
+		}
+
+		platformName := arch
+		if variant != "" {
+			platformName = fmt.Sprintf("%s/%s", arch, variant)
+		}
+
+		if _, found := platforms[platformName]; found {
+			return nil, fmt.Errorf("duplicate image platform - %s", platformName)
+		}
+
+		platforms[platformName] = struct{}{}
+
+		imageOptions := options
+		imageOptions.Platforms = nil
+		imageOptions.ImageConfig.Architecture = arch
+		imageOptions.ImageConfig.Variant = variant
+		if len(platformOptions.Layers) > 0 {
+			imageOptions.Layers = platformOptions.Layers
+		}
+
+		log.Debugf("DefaultSimpleBuilder.Build: building platform image - %s", platformName)
+		img, err := ref.buildImage(imageOptions)
+		if err != nil {
+			return nil, fmt.Errorf("platform image build error - %s (%w)", platformName, err)
+		}
+
+		result, err := imageResult(img)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Platform = fmt.Sprintf("linux/%s", platformName)
+		if imageOptions.ImageConfig.OS != "" {
+			result.Platform = fmt.Sprintf("%s/%s", imageOptions.ImageConfig.OS, platformName)
+		}
+
+		images = append(images, img)
+		results = append(results, result)
+
+		if arch == runtime.GOARCH {
+			nativeImage = img
+		}
+	}
+
+	imageIndex, err := NewImageIndex(images, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.PushToDaemon {
+		//Docker (without the containerd image store) keeps only single platform images
+		if nativeImage != nil {
+			if err := saveToDaemon(nativeImage, tag, otherTags); err != nil {
+				return nil, err
+			}
+		} else {
+			log.Infof("DefaultSimpleBuilder.Build: no image for the current platform (%s) to save to Docker", runtime.GOARCH)
+		}
+	}
+
+	if ref.PushToRegistry {
+		log.Debug("DefaultSimpleBuilder.Build: pushing image index to registry")
+		if err := remote.WriteIndex(tag, imageIndex, ref.remoteOptions()...); err != nil {
+			return nil, err
+		}
+
+		if err := ref.tagInRegistry(imageIndex, otherTags); err != nil {
+			return nil, err
+		}
+	}
+
+	if ref.OutputImageLayout != "" {
+		log.Debugf("DefaultSimpleBuilder.Build: saving image index to OCI image layout - %s", ref.OutputImageLayout)
+		if err := saveToLayout(ref.OutputImageLayout, imageIndex, options.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	digest, err := imageIndex.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &imagebuilder.ImageResult{
+		Name:      options.Tags[0],
+		OtherTags: otherTags,
+		Digest:    digest.String(),
+		Manifests: results,
+	}
+
+	return result, nil
+}
+
+func imageResult(img v1.Image) (*imagebuilder.ImageResult, error) {
+	id, err := img.ConfigName()
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &imagebuilder.ImageResult{
+		ID:     fmt.Sprintf("%s:%s", id.Algorithm, id.Hex),
+		Digest: fmt.Sprintf("%s:%s", digest.Algorithm, digest.Hex),
+	}
+
+	return result, nil
+}
+
+func saveToDaemon(img v1.Image, tag name.Tag, otherTags []string) error {
+	log.Debug("DefaultSimpleBuilder.Build: saving image to Docker")
+	imageLoadResponseStr, err := daemon.Write(tag, img)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("DefaultSimpleBuilder.Build: pushed image to daemon - %s", imageLoadResponseStr)
+
+	if len(otherTags) > 0 {
+		log.Debug("DefaultSimpleBuilder.Build: adding other tags")
+
+		for _, tagName := range otherTags {
+			ntag, err := name.NewTag(tagName)
+			if err != nil {
+				log.Errorf("DefaultSimpleBuilder.Build: error creating tag: %v", err)
+				continue
+			}
+
+			if err := daemon.Tag(tag, ntag); err != nil {
+				log.Errorf("DefaultSimpleBuilder.Build: error tagging: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ref *Engine) tagInRegistry(target remote.Taggable, otherTags []string) error {
+	for _, tagName := range otherTags {
+		ntag, err := name.NewTag(tagName)
+		if err != nil {
+			log.Errorf("DefaultSimpleBuilder.Build: error creating tag: %v", err)
+			continue
+		}
+
+		if err := remote.Tag(ntag, target, ref.remoteOptions()...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveToLayout saves the image or the image index to the OCI image layout
+// (replacing the existing records with the same reference names)
+func saveToLayout(layoutDir string, target interface{}, tags []string) error {
+	layoutPath, err := layout.FromPath(layoutDir)
+	if err != nil {
+		layoutPath, err = layout.Write(layoutDir, empty.Index)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, tagName := range tags {
+		annotations := map[string]string{
+			imagereader.AnnotationRefName: tagName,
+		}
+
+		matcher := match.Annotation(imagereader.AnnotationRefName, tagName)
+		switch t := target.(type) {
+		case v1.ImageIndex:
+			err = layoutPath.ReplaceIndex(t, matcher, layout.WithAnnotations(annotations))
+		case v1.Image:
+			err = layoutPath.ReplaceImage(t, matcher, layout.WithAnnotations(annotations))
+		default:
+			err = fmt.Errorf("unexpected OCI image layout target type - %T", target)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
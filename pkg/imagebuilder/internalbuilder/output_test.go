@@ -0,0 +1,236 @@
+package internalbuilder
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/imagebuilder"
+	"github.com/slimtoolkit/slim/pkg/imagereader"
+)
+
+// testPlatformLayer creates a directory layer with the platform app file
+func testPlatformLayer(t *testing.T, data string) imagebuilder.LayerDataInfo {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "app"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app", "platform"), []byte(data), 0644))
+	return imagebuilder.LayerDataInfo{
+		Type:   imagebuilder.DirSource,
+		Source: dir,
+		Params: &imagebuilder.DataParams{
+			TargetPath: "/",
+		},
+	}
+}
+
+// testImageFile returns the file data from the image layers
+func testImageFile(t *testing.T, img v1.Image, filePath string) string {
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	for _, layer := range layers {
+		rc, err := layer.Uncompressed()
+		require.NoError(t, err)
+
+		tr := tar.NewReader(rc)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+
+			require.NoError(t, err)
+			if filepath.Clean("/"+hdr.Name) == filePath {
+				data, err := io.ReadAll(tr)
+				require.NoError(t, err)
+				rc.Close()
+				return string(data)
+			}
+		}
+
+		rc.Close()
+	}
+
+	return ""
+}
+
+func testIndexBuildOptions(t *testing.T) imagebuilder.SimpleBuildOptions {
+	return imagebuilder.SimpleBuildOptions{
+		Tags: []string{"app:multi", "app:latest"},
+		ImageConfig: imagebuilder.ImageConfig{
+			Config: imagebuilder.RunConfig{
+				Entrypoint: []string{"/app/run"},
+			},
+		},
+		Layers: []imagebuilder.LayerDataInfo{testPlatformLayer(t, "common")},
+	}
+}
+
+func TestNormalizePlatform(t *testing.T) {
+	tt := []struct {
+		arch            string
+		variant         string
+		expectedArch    string
+		expectedVariant string
+		isErr           bool
+	}{
+		{arch: "amd64", expectedArch: "amd64"},
+		{arch: "amd64", variant: "v3", expectedArch: "amd64", expectedVariant: "v3"},
+		{arch: "arm64", expectedArch: "arm64"},
+		{arch: "arm64/v8", expectedArch: "arm64", expectedVariant: "v8"},
+		{arch: "arm", expectedArch: "arm"},
+		{arch: "arm/v6", expectedArch: "arm", expectedVariant: "v6"},
+		{arch: "arm", variant: "v7", expectedArch: "arm", expectedVariant: "v7"},
+		{arch: "arm/v7", variant: "v7", expectedArch: "arm", expectedVariant: "v7"},
+		{arch: "ppc64le", expectedArch: "ppc64le"},
+		{arch: "s390x", expectedArch: "s390x"},
+		{arch: "riscv64", expectedArch: "riscv64"},
+		{arch: "arm/v7", variant: "v6", isErr: true},
+		{arch: "arm64/v7", isErr: true},
+		{arch: "s390x", variant: "v1", isErr: true},
+		{arch: "mips", isErr: true},
+	}
+
+	for _, test := range tt {
+		arch, variant, err := NormalizePlatform(test.arch, test.variant)
+		if test.isErr {
+			assert.Error(t, err, "%s %s", test.arch, test.variant)
+			continue
+		}
+
+		assert.NoError(t, err, "%s %s", test.arch, test.variant)
+		assert.Equal(t, test.expectedArch, arch)
+		assert.Equal(t, test.expectedVariant, variant, test.arch)
+	}
+}
+
+func TestBuildImageIndex(t *testing.T) {
+	layoutDir := t.TempDir()
+	engine, err := New(false, false, false)
+	require.NoError(t, err)
+	engine.OutputImageLayout = layoutDir
+
+	options := testIndexBuildOptions(t)
+	options.Platforms = []imagebuilder.PlatformBuildOptions{
+		{
+			Architecture: "amd64",
+			Layers:       []imagebuilder.LayerDataInfo{testPlatformLayer(t, "amd64")},
+		},
+		{
+			Architecture: "arm/v7",
+			Layers:       []imagebuilder.LayerDataInfo{testPlatformLayer(t, "arm/v7")},
+		},
+		{
+			//using the common layers
+			Architecture: "arm64",
+		},
+	}
+
+	result, err := engine.Build(options)
+	require.NoError(t, err)
+	assert.Equal(t, "app:multi", result.Name)
+	assert.Equal(t, []string{"app:latest"}, result.OtherTags)
+	assert.Empty(t, result.ID)
+	require.Len(t, result.Manifests, 3)
+	assert.Equal(t, "linux/amd64", result.Manifests[0].Platform)
+	assert.Equal(t, "linux/arm/v7", result.Manifests[1].Platform)
+	assert.Equal(t, "linux/arm64", result.Manifests[2].Platform)
+
+	layoutPath, err := layout.FromPath(layoutDir)
+	require.NoError(t, err)
+	rootIndex, err := layoutPath.ImageIndex()
+	require.NoError(t, err)
+	rootManifest, err := rootIndex.IndexManifest()
+	require.NoError(t, err)
+
+	//one index record for each tag
+	require.Len(t, rootManifest.Manifests, 2)
+	for _, desc := range rootManifest.Manifests {
+		assert.True(t, desc.MediaType.IsIndex())
+		assert.Equal(t, result.Digest, desc.Digest.String())
+	}
+
+	assert.Equal(t, "app:multi", rootManifest.Manifests[0].Annotations[imagereader.AnnotationRefName])
+	assert.Equal(t, "app:latest", rootManifest.Manifests[1].Annotations[imagereader.AnnotationRefName])
+
+	imageIndex, err := rootIndex.ImageIndex(rootManifest.Manifests[0].Digest)
+	require.NoError(t, err)
+	indexManifest, err := imageIndex.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, indexManifest.Manifests, 3)
+
+	expected := []struct {
+		platform v1.Platform
+		data     string
+	}{
+		{platform: v1.Platform{OS: "linux", Architecture: "amd64"}, data: "amd64"},
+		{platform: v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, data: "arm/v7"},
+		{platform: v1.Platform{OS: "linux", Architecture: "arm64"}, data: "common"},
+	}
+
+	for i, desc := range indexManifest.Manifests {
+		require.NotNil(t, desc.Platform)
+		assert.Equal(t, expected[i].platform, *desc.Platform)
+		assert.Equal(t, result.Manifests[i].Digest, desc.Digest.String())
+
+		img, err := imageIndex.Image(desc.Digest)
+		require.NoError(t, err)
+		imgConfig, err := img.ConfigFile()
+		require.NoError(t, err)
+		assert.Equal(t, expected[i].platform.Architecture, imgConfig.Architecture)
+		assert.Equal(t, expected[i].platform.Variant, imgConfig.Variant)
+		assert.Equal(t, []string{"/app/run"}, imgConfig.Config.Entrypoint)
+		assert.Equal(t, expected[i].data, testImageFile(t, img, "/app/platform"))
+	}
+
+	//the layout records with the same tags are replaced
+	_, err = engine.Build(options)
+	require.NoError(t, err)
+	rootIndex, err = layoutPath.ImageIndex()
+	require.NoError(t, err)
+	rootManifest, err = rootIndex.IndexManifest()
+	require.NoError(t, err)
+	assert.Len(t, rootManifest.Manifests, 2)
+}
+
+func TestBuildImageIndexErrors(t *testing.T) {
+	tt := []struct {
+		name      string
+		platforms []imagebuilder.PlatformBuildOptions
+		tarOutput bool
+	}{
+		{
+			name:      "duplicate platform",
+			platforms: []imagebuilder.PlatformBuildOptions{{Architecture: "arm/v7"}, {Architecture: "arm", Variant: "v7"}},
+		},
+		{
+			name:      "bad platform",
+			platforms: []imagebuilder.PlatformBuildOptions{{Architecture: "amd64"}, {Architecture: "mips"}},
+		},
+		{
+			name:      "image tar output",
+			platforms: []imagebuilder.PlatformBuildOptions{{Architecture: "amd64"}, {Architecture: "arm64"}},
+			tarOutput: true,
+		},
+	}
+
+	for _, test := range tt {
+		engine, err := New(false, false, false)
+		require.NoError(t, err)
+		engine.OutputImageLayout = t.TempDir()
+		if test.tarOutput {
+			engine.OutputImageTar = filepath.Join(t.TempDir(), "image.tar")
+		}
+
+		options := testIndexBuildOptions(t)
+		options.Platforms = test.platforms
+		_, err = engine.Build(options)
+		assert.Error(t, err, test.name)
+	}
+}
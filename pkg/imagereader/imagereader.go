@@ -107,10 +107,19 @@ func (ref *Instance) ImageConfig() (*imagebuilder.ImageConfig, error) {
 			NetworkDisabled: cf.Config.NetworkDisabled,
 			MacAddress:      cf.Config.MacAddress,
 			Shell:           cf.Config.Shell, //??
-			//Healthcheck  *HealthConfig       `json:"Healthcheck,omitempty"`
 		},
 	}
 
+	if cf.Config.Healthcheck != nil {
+		ref.imageConfig.Config.Healthcheck = &imagebuilder.HealthConfig{
+			Test:        cf.Config.Healthcheck.Test,
+			Interval:    cf.Config.Healthcheck.Interval,
+			Timeout:     cf.Config.Healthcheck.Timeout,
+			StartPeriod: cf.Config.Healthcheck.StartPeriod,
+			Retries:     cf.Config.Healthcheck.Retries,
+		}
+	}
+
 	return ref.imageConfig, nil
 }
 
@@ -0,0 +1,310 @@
+package imagereader
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	log "github.com/sirupsen/logrus"
+)
+
+// Image location types
+const (
+	DockerDaemonLocation  = "docker-daemon"
+	DockerArchiveLocation = "docker-archive"
+	OCILayoutLocation     = "oci"
+	RegistryLocation      = "registry"
+)
+
+const (
+	registryLocationAlias = "docker"
+	// AnnotationRefName is the OCI image layout annotation with the image reference name
+	AnnotationRefName = "org.opencontainers.image.ref.name"
+)
+
+// Location describes where the image is stored
+type Location struct {
+	Type string `json:"type"`
+	// Path is the tar file path (docker-archive) or the OCI image layout directory path (oci)
+	Path string `json:"path,omitempty"`
+	// Ref is the image reference (for docker-archive and oci it's optional)
+	Ref string `json:"ref,omitempty"`
+}
+
+func (ref *Location) String() string {
+	switch ref.Type {
+	case DockerArchiveLocation, OCILayoutLocation:
+		if ref.Ref != "" {
+			return fmt.Sprintf("%s:%s:%s", ref.Type, ref.Path, ref.Ref)
+		}
+
+		return fmt.Sprintf("%s:%s", ref.Type, ref.Path)
+	default:
+		return fmt.Sprintf("%s:%s", ref.Type, ref.Ref)
+	}
+}
+
+// IsKnownLocationType returns true if the value is a supported image location type
+func IsKnownLocationType(value string) bool {
+	switch value {
+	case DockerDaemonLocation,
+		DockerArchiveLocation,
+		OCILayoutLocation,
+		RegistryLocation:
+		return true
+	}
+
+	return false
+}
+
+// ParseLocation parses the image location string. Supported formats:
+// * docker-daemon:IMAGE
+// * docker-archive:PATH[:IMAGE]
+// * oci:PATH[:REF]
+// * registry:IMAGE (or docker://IMAGE)
+// * IMAGE (uses the default location type)
+// The location type prefix followed by a port is a registry host name
+// ('registry:5000/app:v1' is the 'registry:5000/app:v1' image in the default location type).
+func ParseLocation(raw string, defaultType string) (*Location, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("empty image location")
+	}
+
+	if strings.HasPrefix(raw, registryLocationAlias+"://") {
+		return &Location{
+			Type: RegistryLocation,
+			Ref:  strings.TrimPrefix(raw, registryLocationAlias+"://"),
+		}, nil
+	}
+
+	parts := strings.SplitN(raw, ":", 2)
+	//the prefix is a registry host name (not the location type)
+	//if it's followed by a port (e.g., 'registry:5000/app:v1')
+	if len(parts) == 2 && IsKnownLocationType(parts[0]) && !isPortContinuation(parts[1]) {
+		location := &Location{Type: parts[0]}
+		switch location.Type {
+		case DockerArchiveLocation, OCILayoutLocation:
+			//the image reference is optional and it's separated from the path
+			//with ':' (the reference itself may include ':')
+			pathParts := strings.SplitN(parts[1], ":", 2)
+			location.Path = pathParts[0]
+			if len(pathParts) == 2 {
+				location.Ref = pathParts[1]
+			}
+
+			if location.Path == "" {
+				return nil, fmt.Errorf("missing image location path - '%s'", raw)
+			}
+		default:
+			location.Ref = parts[1]
+			if location.Ref == "" {
+				return nil, fmt.Errorf("missing image reference - '%s'", raw)
+			}
+		}
+
+		return location, nil
+	}
+
+	if defaultType == "" {
+		return nil, fmt.Errorf("unknown image location type - '%s'", raw)
+	}
+
+	location := &Location{Type: defaultType}
+	switch defaultType {
+	case DockerArchiveLocation, OCILayoutLocation:
+		location.Path = raw
+	default:
+		location.Ref = raw
+	}
+
+	return location, nil
+}
+
+// isPortContinuation returns true if the value starts with a port number
+// (followed by the repository path or nothing)
+func isPortContinuation(value string) bool {
+	port := value
+	if idx := strings.Index(value, "/"); idx != -1 {
+		port = value[:idx]
+	}
+
+	if port == "" {
+		return false
+	}
+
+	for _, c := range port {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewFromLocation creates a new image reader instance for the image in the target location
+func NewFromLocation(location *Location, remoteOpts ...remote.Option) (*Instance, error) {
+	logger := log.WithFields(log.Fields{
+		"op":       "imagereader.NewFromLocation",
+		"location": location.String(),
+	})
+
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	if location.Type == DockerDaemonLocation {
+		return New(location.Ref)
+	}
+
+	img, err := LoadImage(location, remoteOpts...)
+	if err != nil {
+		logger.WithError(err).Error("LoadImage")
+		return nil, err
+	}
+
+	instance := &Instance{
+		imageName: location.String(),
+		imageRef:  img,
+	}
+
+	if location.Ref != "" {
+		if nameRef, err := name.ParseReference(location.Ref); err == nil {
+			instance.nameRef = nameRef
+		}
+	}
+
+	return instance, nil
+}
+
+// LoadImage loads the image from the target location
+// (selecting the current platform image from multi-arch images)
+func LoadImage(location *Location, remoteOpts ...remote.Option) (v1.Image, error) {
+	return LoadPlatformImage(location, nil, remoteOpts...)
+}
+
+// LoadPlatformImage loads the image from the target location
+// selecting the target platform image from multi-arch images
+// (the current platform is used if the platform is not provided)
+func LoadPlatformImage(location *Location, platform *v1.Platform, remoteOpts ...remote.Option) (v1.Image, error) {
+	if platform == nil {
+		platform = &v1.Platform{OS: "linux", Architecture: runtime.GOARCH}
+	}
+
+	switch location.Type {
+	case DockerDaemonLocation:
+		ref, err := name.ParseReference(location.Ref)
+		if err != nil {
+			return nil, err
+		}
+
+		return daemon.Image(ref)
+	case DockerArchiveLocation:
+		var tag *name.Tag
+		if location.Ref != "" {
+			t, err := name.NewTag(location.Ref)
+			if err != nil {
+				return nil, err
+			}
+
+			tag = &t
+		}
+
+		return tarball.ImageFromPath(location.Path, tag)
+	case OCILayoutLocation:
+		idx, err := layout.ImageIndexFromPath(location.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return imageFromIndex(idx, location.Ref, platform)
+	case RegistryLocation:
+		ref, err := name.ParseReference(location.Ref)
+		if err != nil {
+			return nil, err
+		}
+
+		remoteOpts = append(remoteOpts, remote.WithPlatform(*platform))
+		return remote.Image(ref, remoteOpts...)
+	default:
+		return nil, fmt.Errorf("unknown image location type - '%s'", location.Type)
+	}
+}
+
+// imageFromIndex selects the image from the image index
+// using the reference name annotation (if provided) and the target platform
+// (if the selected manifest is an image index too)
+func imageFromIndex(idx v1.ImageIndex, refName string, platform *v1.Platform) (v1.Image, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(im.Manifests) == 0 {
+		return nil, fmt.Errorf("empty image index")
+	}
+
+	var desc *v1.Descriptor
+	if refName != "" {
+		for i := range im.Manifests {
+			if im.Manifests[i].Annotations[AnnotationRefName] == refName {
+				desc = &im.Manifests[i]
+				break
+			}
+		}
+
+		if desc == nil {
+			return nil, fmt.Errorf("no image with reference name - '%s'", refName)
+		}
+	} else {
+		var images []*v1.Descriptor
+		for i := range im.Manifests {
+			if im.Manifests[i].MediaType.IsImage() || im.Manifests[i].MediaType.IsIndex() {
+				images = append(images, &im.Manifests[i])
+			}
+		}
+
+		switch len(images) {
+		case 0:
+			return nil, fmt.Errorf("no images in image index")
+		case 1:
+			desc = images[0]
+		default:
+			desc = matchPlatform(images, platform)
+			if desc == nil {
+				return nil, fmt.Errorf("multiple images in image index (select one with a reference name)")
+			}
+		}
+	}
+
+	if desc.MediaType.IsIndex() {
+		child, err := idx.ImageIndex(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		return imageFromIndex(child, "", platform)
+	}
+
+	return idx.Image(desc.Digest)
+}
+
+func matchPlatform(descs []*v1.Descriptor, platform *v1.Platform) *v1.Descriptor {
+	for _, desc := range descs {
+		if desc.Platform != nil && desc.Platform.Satisfies(*platform) {
+			return desc
+		}
+	}
+
+	return nil
+}
+
+// Image returns the underlying image object
+func (ref *Instance) Image() v1.Image {
+	return ref.imageRef
+}
@@ -0,0 +1,121 @@
+package imagereader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocation(t *testing.T) {
+	tt := []struct {
+		in          string
+		defaultType string
+		expected    *Location
+		isErr       bool
+	}{
+		{
+			in:          "nginx:latest",
+			defaultType: DockerDaemonLocation,
+			expected:    &Location{Type: DockerDaemonLocation, Ref: "nginx:latest"},
+		},
+		{
+			in:       "docker-daemon:localhost:5000/app:1.0",
+			expected: &Location{Type: DockerDaemonLocation, Ref: "localhost:5000/app:1.0"},
+		},
+		{
+			in:       "docker-archive:/tmp/app.tar",
+			expected: &Location{Type: DockerArchiveLocation, Path: "/tmp/app.tar"},
+		},
+		{
+			in:       "docker-archive:/tmp/app.tar:app:1.0",
+			expected: &Location{Type: DockerArchiveLocation, Path: "/tmp/app.tar", Ref: "app:1.0"},
+		},
+		{
+			in:       "oci:./layout:v1",
+			expected: &Location{Type: OCILayoutLocation, Path: "./layout", Ref: "v1"},
+		},
+		{
+			in:       "registry:ghcr.io/org/app:2",
+			expected: &Location{Type: RegistryLocation, Ref: "ghcr.io/org/app:2"},
+		},
+		{
+			in:       "docker://ghcr.io/org/app:2",
+			expected: &Location{Type: RegistryLocation, Ref: "ghcr.io/org/app:2"},
+		},
+		{
+			in:          "./layout",
+			defaultType: OCILayoutLocation,
+			expected:    &Location{Type: OCILayoutLocation, Path: "./layout"},
+		},
+		{
+			in:          "registry:5000/app:v1",
+			defaultType: RegistryLocation,
+			expected:    &Location{Type: RegistryLocation, Ref: "registry:5000/app:v1"},
+		},
+		{
+			in:          "registry:5000/app:v1",
+			defaultType: DockerDaemonLocation,
+			expected:    &Location{Type: DockerDaemonLocation, Ref: "registry:5000/app:v1"},
+		},
+		{
+			in:       "registry:registry:5000/team/app@sha256:abcd",
+			expected: &Location{Type: RegistryLocation, Ref: "registry:5000/team/app@sha256:abcd"},
+		},
+		{
+			in:          "oci:5000",
+			defaultType: RegistryLocation,
+			expected:    &Location{Type: RegistryLocation, Ref: "oci:5000"},
+		},
+		{
+			in:          "localhost:5000/app:v1",
+			defaultType: RegistryLocation,
+			expected:    &Location{Type: RegistryLocation, Ref: "localhost:5000/app:v1"},
+		},
+		{
+			in:       "docker-archive:/tmp/5000/app.tar:app:v1",
+			expected: &Location{Type: DockerArchiveLocation, Path: "/tmp/5000/app.tar", Ref: "app:v1"},
+		},
+		{
+			in:       "oci:layouts/app",
+			expected: &Location{Type: OCILayoutLocation, Path: "layouts/app"},
+		},
+		{in: "localhost:5000/app:v1", isErr: true},
+		{in: "registry:5000/app:v1", isErr: true},
+		{in: "", defaultType: DockerDaemonLocation, isErr: true},
+		{in: "docker-archive:", isErr: true},
+		{in: "registry:", isErr: true},
+		{in: "nginx:latest", isErr: true},
+	}
+
+	for _, test := range tt {
+		location, err := ParseLocation(test.in, test.defaultType)
+		if test.isErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.expected, location, test.in)
+	}
+}
+
+func TestLocationString(t *testing.T) {
+	tt := []struct {
+		location *Location
+		expected string
+	}{
+		{location: &Location{Type: DockerDaemonLocation, Ref: "app:1"}, expected: "docker-daemon:app:1"},
+		{location: &Location{Type: DockerArchiveLocation, Path: "/tmp/a.tar"}, expected: "docker-archive:/tmp/a.tar"},
+		{location: &Location{Type: OCILayoutLocation, Path: "dir", Ref: "v1"}, expected: "oci:dir:v1"},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, test.location.String())
+
+		//the string form can be parsed back
+		parsed, err := ParseLocation(test.location.String(), "")
+		require.NoError(t, err)
+		assert.Equal(t, test.location, parsed)
+	}
+}
@@ -51,6 +51,7 @@ type StartMonitor struct {
 	AppStderrToFile              bool                          `json:"app_stderr_to_file"`
 	RunTargetAsUser              bool                          `json:"run_tas_user,omitempty"`
 	ReportOnMainPidExit          bool                          `json:"report_on_main_pid_exit"`
+	TracedProcessFilesOnly       bool                          `json:"traced_process_files_only,omitempty"`
 	KeepPerms                    bool                          `json:"keep_perms,omitempty"`
 	Perms                        map[string]*fsutil.AccessInfo `json:"perms,omitempty"`
 	Excludes                     []string                      `json:"excludes,omitempty"`
@@ -0,0 +1,76 @@
+//go:build linux
+// +build linux
+
+package ptrace
+
+import (
+	"strconv"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+	"github.com/slimtoolkit/slim/pkg/system"
+)
+
+// Sensitive syscall arguments (the argument values are recorded
+// to generate the argument-aware seccomp profile rules)
+var sensitiveCallArgs = map[string]uint{
+	"clone":       0, //clone(unsigned long flags, ...)
+	"socket":      0, //socket(int domain, int type, int protocol)
+	"ioctl":       1, //ioctl(int fd, unsigned long request, ...)
+	"personality": 0, //personality(unsigned long persona)
+	"prctl":       0, //prctl(int option, ...)
+}
+
+type callArg struct {
+	name  string
+	index uint
+}
+
+// native syscall number -> sensitive argument
+var callArgs = map[uint64]callArg{}
+
+func init() {
+	for name, index := range sensitiveCallArgs {
+		if num, found := system.LookupCallNumber(name); found {
+			callArgs[uint64(num)] = callArg{name: name, index: index}
+		}
+	}
+}
+
+// CallArgIndex returns the index of the recorded (sensitive) syscall argument
+func CallArgIndex(callNum uint64) (uint, bool) {
+	arg, found := callArgs[callNum]
+	return arg.index, found
+}
+
+// CallArgs contains the observed sensitive syscall argument values
+// (syscall number -> argument value -> count)
+type CallArgs map[uint32]map[uint64]uint64
+
+func (ref CallArgs) Add(callNum uint32, val uint64) {
+	values, found := ref[callNum]
+	if !found {
+		values = map[uint64]uint64{}
+		ref[callNum] = values
+	}
+
+	values[val]++
+}
+
+func (ref CallArgs) Report() map[string]*report.SyscallArgInfo {
+	if len(ref) == 0 {
+		return nil
+	}
+
+	result := map[string]*report.SyscallArgInfo{}
+	for scNum, values := range ref {
+		arg := callArgs[uint64(scNum)]
+		result[strconv.FormatInt(int64(scNum), 10)] = &report.SyscallArgInfo{
+			Number: scNum,
+			Name:   arg.name,
+			Index:  arg.index,
+			Values: values,
+		}
+	}
+
+	return result
+}
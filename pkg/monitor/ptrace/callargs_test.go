@@ -0,0 +1,54 @@
+//go:build linux
+// +build linux
+
+package ptrace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/system"
+)
+
+func TestCallArgs(t *testing.T) {
+	assert.Nil(t, CallArgs{}.Report())
+
+	cloneNum, found := system.LookupCallNumber("clone")
+	require.True(t, found)
+	ioctlNum, found := system.LookupCallNumber("ioctl")
+	require.True(t, found)
+	readNum, found := system.LookupCallNumber("read")
+	require.True(t, found)
+
+	index, found := CallArgIndex(uint64(ioctlNum))
+	assert.True(t, found)
+	assert.Equal(t, uint(1), index)
+	_, found = CallArgIndex(uint64(readNum))
+	assert.False(t, found)
+
+	args := CallArgs{}
+	args.Add(uint32(cloneNum), 0x3d0f00)
+	args.Add(uint32(cloneNum), 0x3d0f00)
+	args.Add(uint32(cloneNum), 0x11)
+	args.Add(uint32(ioctlNum), 0x5401)
+
+	result := args.Report()
+	require.Len(t, result, 2)
+
+	for _, info := range result {
+		switch info.Number {
+		case uint32(cloneNum):
+			assert.Equal(t, "clone", info.Name)
+			assert.Equal(t, uint(0), info.Index)
+			assert.Equal(t, map[uint64]uint64{0x3d0f00: 2, 0x11: 1}, info.Values)
+		case uint32(ioctlNum):
+			assert.Equal(t, "ioctl", info.Name)
+			assert.Equal(t, uint(1), info.Index)
+			assert.Equal(t, map[uint64]uint64{0x5401: 1}, info.Values)
+		default:
+			t.Errorf("unexpected syscall: %+v", info)
+		}
+	}
+}
@@ -0,0 +1,793 @@
+//go:build !arm64
+// +build !arm64
+
+package ptrace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+	"github.com/slimtoolkit/slim/pkg/system"
+)
+
+const (
+	dnsPort           = 53
+	dnsHeaderSize     = 12
+	maxDNSMessageSize = 512
+	maxDNSQuestions   = 8
+	maxSockAddrSize   = 128
+	maxSendmmsgScan   = 4
+	sockTypeMask      = 0xf
+	procStatusPath    = "/proc/%d/status"
+)
+
+// the pointer size of the traced processes (same as the sensor)
+const ptrSize = strconv.IntSize / 8
+
+// struct msghdr / struct mmsghdr / struct iovec layouts
+const (
+	msghdrNameOffset    = 0
+	msghdrNameLenOffset = ptrSize
+	msghdrIovOffset     = 2 * ptrSize
+	msghdrIovLenOffset  = 3 * ptrSize
+	msghdrSize          = 7 * ptrSize
+	mmsghdrSize         = 8 * ptrSize
+	iovecLenOffset      = ptrSize
+	iovecSize           = 2 * ptrSize
+)
+
+type sockAddr struct {
+	family  string
+	address string
+	port    int
+}
+
+type socketInfo struct {
+	domain   int
+	sockType int
+	local    *sockAddr
+	remote   *sockAddr
+}
+
+type socketKey struct {
+	tgid int
+	fd   int
+}
+
+// socketTable tracks the sockets created by the traced processes.
+// The socket file descriptors are shared by all process threads,
+// so the table is keyed by the thread group IDs.
+// Note: the table is only used by the collector (tracing) goroutine.
+type socketTable struct {
+	tgids   map[int]int
+	sockets map[socketKey]*socketInfo
+}
+
+func newSocketTable() *socketTable {
+	return &socketTable{
+		tgids:   map[int]int{},
+		sockets: map[socketKey]*socketInfo{},
+	}
+}
+
+func (t *socketTable) tgid(pid int) int {
+	if tgid, found := t.tgids[pid]; found {
+		return tgid
+	}
+
+	tgid := pid
+	if data, err := os.ReadFile(fmt.Sprintf(procStatusPath, pid)); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "Tgid:") {
+				if val, err := strconv.Atoi(strings.TrimSpace(line[len("Tgid:"):])); err == nil {
+					tgid = val
+				}
+				break
+			}
+		}
+	}
+
+	t.tgids[pid] = tgid
+	return tgid
+}
+
+func (t *socketTable) get(pid, fd int) *socketInfo {
+	return t.sockets[socketKey{tgid: t.tgid(pid), fd: fd}]
+}
+
+func (t *socketTable) add(pid, fd int, info *socketInfo) {
+	t.sockets[socketKey{tgid: t.tgid(pid), fd: fd}] = info
+}
+
+func (t *socketTable) remove(pid, fd int) {
+	delete(t.sockets, socketKey{tgid: t.tgid(pid), fd: fd})
+}
+
+// inherit copies the parent process sockets to a forked child process
+func (t *socketTable) inherit(pid, childPid int) {
+	tgid := t.tgid(pid)
+	childTgid := t.tgid(childPid)
+	if tgid == childTgid {
+		return
+	}
+
+	for key, info := range t.sockets {
+		if key.tgid == tgid {
+			infoCopy := *info
+			t.sockets[socketKey{tgid: childTgid, fd: key.fd}] = &infoCopy
+		}
+	}
+}
+
+// forget drops the terminated thread (and the process sockets when it's the main thread)
+func (t *socketTable) forget(pid int) {
+	tgid, found := t.tgids[pid]
+	if !found {
+		return
+	}
+
+	delete(t.tgids, pid)
+	if tgid != pid {
+		return
+	}
+
+	for key := range t.sockets {
+		if key.tgid == tgid {
+			delete(t.sockets, key)
+		}
+	}
+}
+
+type dnsQuestion struct {
+	name  string
+	qtype string
+}
+
+// netCall holds the socket system call parameters captured by the network syscall processor
+type netCall struct {
+	tgid       int
+	name       string
+	op         string
+	fd         int
+	domain     int
+	sockType   int
+	addr       *sockAddr
+	peer       *sockAddr
+	addrPtr    uint64
+	addrLenPtr uint64
+	questions  []dnsQuestion
+}
+
+func (c *netCall) setSocket(info *socketInfo) {
+	if info == nil {
+		return
+	}
+
+	c.domain = info.domain
+	c.sockType = info.sockType
+}
+
+func (c *netCall) events(callNum uint32, retVal uint64) []NetEvent {
+	if c.op == "" || c.addr == nil {
+		return nil
+	}
+
+	errStr := ""
+	if ret := getIntVal(retVal); ret < 0 {
+		errno := syscall.Errno(-ret)
+		switch errno {
+		case syscall.EINPROGRESS:
+			//non-blocking connect
+		case syscall.EAGAIN, syscall.EINTR:
+			//non-blocking accept/send polling noise
+			return nil
+		default:
+			errStr = errnoName(retVal)
+		}
+	}
+
+	base := NetEvent{
+		Pid:      c.tgid,
+		CallNum:  callNum,
+		CallName: c.name,
+		Op:       c.op,
+		Protocol: protocolName(c.domain, c.sockType, c.addr.family),
+		Family:   c.addr.family,
+		Address:  c.addr.address,
+		Port:     c.addr.port,
+		Error:    errStr,
+	}
+
+	if c.peer != nil {
+		base.Peer = c.peer.address
+	}
+
+	if c.op != report.NetOpDNS {
+		return []NetEvent{base}
+	}
+
+	var events []NetEvent
+	for _, q := range c.questions {
+		evt := base
+		evt.Hostname = q.name
+		evt.QueryType = q.qtype
+		events = append(events, evt)
+	}
+
+	return events
+}
+
+func protocolName(domain, sockType int, family string) string {
+	if domain == syscall.AF_UNIX || family == FamilyUnix {
+		return ProtoUnix
+	}
+
+	switch sockType {
+	case syscall.SOCK_STREAM:
+		return ProtoTCP
+	case syscall.SOCK_DGRAM:
+		return ProtoUDP
+	case syscall.SOCK_RAW:
+		return ProtoRaw
+	}
+
+	return ProtoUnknown
+}
+
+func familyDomain(family string) int {
+	switch family {
+	case FamilyIPv4:
+		return syscall.AF_INET
+	case FamilyIPv6:
+		return syscall.AF_INET6
+	case FamilyUnix:
+		return syscall.AF_UNIX
+	}
+
+	return 0
+}
+
+func isNetDomain(domain int) bool {
+	return domain == syscall.AF_INET ||
+		domain == syscall.AF_INET6 ||
+		domain == syscall.AF_UNIX
+}
+
+type netSyscallProcessor struct {
+	*syscallProcessorCore
+}
+
+func (ref *netSyscallProcessor) FailedCall(cstate *syscallState) bool {
+	return ref.FailedReturnStatus(cstate.retVal)
+}
+
+func (ref *netSyscallProcessor) FailedReturnStatus(retVal uint64) bool {
+	return !ref.OKReturnStatus(retVal)
+}
+
+func (ref *netSyscallProcessor) OKCall(cstate *syscallState) bool {
+	return ref.OKReturnStatus(cstate.retVal)
+}
+
+func (ref *netSyscallProcessor) OKReturnStatus(retVal uint64) bool {
+	ret := getIntVal(retVal)
+	return ret >= 0 || syscall.Errno(-ret) == syscall.EINPROGRESS
+}
+
+func (ref *netSyscallProcessor) EventOnCall() bool {
+	return false
+}
+
+func (ref *netSyscallProcessor) OnCall(pid int, regs syscall.PtraceRegs, cstate *syscallState) {
+	cstate.netCall = nil
+	sockets := cstate.sockets
+	if sockets == nil {
+		return
+	}
+
+	switch ref.Name {
+	case "socket":
+		//socket(int domain, int type, int protocol)
+		domain := getIntVal(system.CallFirstParam(regs))
+		if !isNetDomain(domain) {
+			return
+		}
+
+		cstate.netCall = &netCall{
+			name:     ref.Name,
+			domain:   domain,
+			sockType: getIntVal(system.CallSecondParam(regs)) & sockTypeMask,
+		}
+
+	case "connect", "bind":
+		//connect(int fd, struct sockaddr *uservaddr, int addrlen)
+		//bind(int fd, struct sockaddr *umyaddr, int addrlen)
+		fd := getIntVal(system.CallFirstParam(regs))
+		addr := readSockAddr(pid, system.CallSecondParam(regs), getIntVal(system.CallThirdParam(regs)))
+		if addr == nil {
+			return
+		}
+
+		call := &netCall{
+			name:   ref.Name,
+			op:     report.NetOpConnect,
+			fd:     fd,
+			domain: familyDomain(addr.family),
+			addr:   addr,
+		}
+
+		if ref.Name == "bind" {
+			call.op = report.NetOpBind
+		}
+
+		call.setSocket(sockets.get(pid, fd))
+		cstate.netCall = call
+
+	case "listen":
+		//listen(int fd, int backlog)
+		fd := getIntVal(system.CallFirstParam(regs))
+		call := &netCall{
+			name: ref.Name,
+			op:   report.NetOpListen,
+			fd:   fd,
+		}
+
+		if info := sockets.get(pid, fd); info != nil {
+			call.setSocket(info)
+			call.addr = info.local
+		}
+
+		cstate.netCall = call
+
+	case "accept", "accept4":
+		//accept(int fd, struct sockaddr *upeer_sockaddr, int *upeer_addrlen)
+		//accept4(int fd, struct sockaddr *upeer_sockaddr, int *upeer_addrlen, int flags)
+		fd := getIntVal(system.CallFirstParam(regs))
+		call := &netCall{
+			name:       ref.Name,
+			op:         report.NetOpAccept,
+			fd:         fd,
+			addrPtr:    system.CallSecondParam(regs),
+			addrLenPtr: system.CallThirdParam(regs),
+		}
+
+		if info := sockets.get(pid, fd); info != nil {
+			call.setSocket(info)
+			call.addr = info.local
+		}
+
+		cstate.netCall = call
+
+	case "sendto":
+		//sendto(int fd, void *buff, size_t len, unsigned int flags, struct sockaddr *addr, int addr_len)
+		fd := getIntVal(system.CallFirstParam(regs))
+		info := sockets.get(pid, fd)
+		dest := readSockAddr(pid, system.CallFifthParam(regs), getIntVal(system.CallSixthParam(regs)))
+		call := newSendCall(ref.Name, fd, info, dest)
+		if call == nil {
+			return
+		}
+
+		call.addDNSQuestions(pid, system.CallSecondParam(regs), system.CallThirdParam(regs))
+		cstate.netCall = call.checkSend(dest)
+
+	case "sendmsg":
+		//sendmsg(int fd, struct user_msghdr *msg, unsigned int flags)
+		fd := getIntVal(system.CallFirstParam(regs))
+		info := sockets.get(pid, fd)
+		msgPtr := system.CallSecondParam(regs)
+		dest := readMsgDest(pid, msgPtr)
+		call := newSendCall(ref.Name, fd, info, dest)
+		if call == nil {
+			return
+		}
+
+		call.addMsgDNSQuestions(pid, msgPtr)
+		cstate.netCall = call.checkSend(dest)
+
+	case "sendmmsg":
+		//sendmmsg(int fd, struct mmsghdr *mmsg, unsigned int vlen, unsigned int flags)
+		fd := getIntVal(system.CallFirstParam(regs))
+		info := sockets.get(pid, fd)
+		vecPtr := system.CallSecondParam(regs)
+		vlen := int(uint32(system.CallThirdParam(regs)))
+		if vlen > maxSendmmsgScan {
+			vlen = maxSendmmsgScan
+		}
+
+		if vlen == 0 {
+			return
+		}
+
+		dest := readMsgDest(pid, vecPtr)
+		call := newSendCall(ref.Name, fd, info, dest)
+		if call == nil {
+			return
+		}
+
+		for i := 0; i < vlen; i++ {
+			call.addMsgDNSQuestions(pid, vecPtr+uint64(i*mmsghdrSize))
+		}
+
+		cstate.netCall = call.checkSend(dest)
+
+	case "write":
+		//write(unsigned int fd, const char *buf, size_t count)
+		//(only tracking the DNS queries sent using connected sockets)
+		fd := getIntVal(system.CallFirstParam(regs))
+		info := sockets.get(pid, fd)
+		if info == nil || info.remote == nil || info.remote.port != dnsPort {
+			return
+		}
+
+		call := newSendCall(ref.Name, fd, info, nil)
+		call.addDNSQuestions(pid, system.CallSecondParam(regs), system.CallThirdParam(regs))
+		cstate.netCall = call.checkSend(nil)
+
+	case "close":
+		//close(unsigned int fd)
+		//(the fd is released even if the call is interrupted)
+		sockets.remove(pid, getIntVal(system.CallFirstParam(regs)))
+	}
+
+	if cstate.netCall != nil {
+		//reporting the process activity (not the thread activity)
+		cstate.netCall.tgid = sockets.tgid(pid)
+	}
+}
+
+func (ref *netSyscallProcessor) OnReturn(pid int, regs syscall.PtraceRegs, cstate *syscallState) {
+	call := cstate.netCall
+	if call == nil || cstate.sockets == nil {
+		return
+	}
+
+	log.Tracef("netSyscallProcessor.OnReturn: [%d] {%d}%s(fd=%d) = %d/%s",
+		pid, cstate.callNum, ref.Name, call.fd, getIntVal(cstate.retVal), errnoName(cstate.retVal))
+
+	if !ref.OKReturnStatus(cstate.retVal) {
+		if call.op == "" {
+			cstate.netCall = nil
+		}
+		return
+	}
+
+	sockets := cstate.sockets
+	switch ref.Name {
+	case "socket":
+		sockets.add(pid, getIntVal(cstate.retVal), &socketInfo{
+			domain:   call.domain,
+			sockType: call.sockType,
+		})
+		//not generating events for the new sockets
+		cstate.netCall = nil
+
+	case "connect", "bind":
+		info := sockets.get(pid, call.fd)
+		if info == nil {
+			info = &socketInfo{domain: call.domain}
+			sockets.add(pid, call.fd, info)
+		}
+
+		if ref.Name == "connect" {
+			info.remote = call.addr
+		} else {
+			info.local = call.addr
+		}
+
+	case "accept", "accept4":
+		if call.addrPtr != 0 && call.addrLenPtr != 0 {
+			if data, err := readMemory(pid, call.addrLenPtr, 4); err == nil && len(data) == 4 {
+				size := int(int32(binary.NativeEndian.Uint32(data)))
+				call.peer = readSockAddr(pid, call.addrPtr, size)
+			}
+		}
+
+		sockets.add(pid, getIntVal(cstate.retVal), &socketInfo{
+			domain:   call.domain,
+			sockType: call.sockType,
+			local:    call.addr,
+			remote:   call.peer,
+		})
+	}
+}
+
+func newSendCall(name string, fd int, info *socketInfo, dest *sockAddr) *netCall {
+	call := &netCall{
+		name: name,
+		op:   report.NetOpSend,
+		fd:   fd,
+		addr: dest,
+	}
+
+	if info != nil {
+		call.setSocket(info)
+		if call.addr == nil {
+			call.addr = info.remote
+		}
+	}
+
+	if call.addr == nil {
+		return nil
+	}
+
+	if call.domain == 0 {
+		call.domain = familyDomain(call.addr.family)
+	}
+
+	return call
+}
+
+// checkSend returns the call if it's a DNS query or if it's sent to an explicit destination
+// (the connected socket sends are already captured by the 'connect' calls)
+func (c *netCall) checkSend(dest *sockAddr) *netCall {
+	if len(c.questions) > 0 {
+		c.op = report.NetOpDNS
+		return c
+	}
+
+	if dest != nil {
+		return c
+	}
+
+	return nil
+}
+
+func (c *netCall) addDNSQuestions(pid int, bufPtr uint64, size uint64) {
+	if c.addr == nil || c.addr.port != dnsPort || bufPtr == 0 || size == 0 {
+		return
+	}
+
+	if size > maxDNSMessageSize {
+		size = maxDNSMessageSize
+	}
+
+	data, err := readMemory(pid, bufPtr, int(size))
+	if err != nil {
+		return
+	}
+
+	if c.sockType == syscall.SOCK_STREAM {
+		//DNS over TCP messages have a two byte length prefix
+		if len(data) < 2 {
+			return
+		}
+
+		data = data[2:]
+	}
+
+	c.questions = append(c.questions, parseDNSQuery(data)...)
+}
+
+func (c *netCall) addMsgDNSQuestions(pid int, msgPtr uint64) {
+	if c.addr == nil || c.addr.port != dnsPort || msgPtr == 0 {
+		return
+	}
+
+	msg, err := readMemory(pid, msgPtr, msghdrSize)
+	if err != nil || len(msg) < msghdrSize {
+		return
+	}
+
+	iovPtr := readPtr(msg, msghdrIovOffset)
+	iovLen := readPtr(msg, msghdrIovLenOffset)
+	if iovPtr == 0 || iovLen == 0 {
+		return
+	}
+
+	//the DNS query messages are expected to be in the first buffer
+	iov, err := readMemory(pid, iovPtr, iovecSize)
+	if err != nil || len(iov) < iovecSize {
+		return
+	}
+
+	c.addDNSQuestions(pid, readPtr(iov, 0), readPtr(iov, iovecLenOffset))
+}
+
+func readMsgDest(pid int, msgPtr uint64) *sockAddr {
+	if msgPtr == 0 {
+		return nil
+	}
+
+	msg, err := readMemory(pid, msgPtr, msghdrSize)
+	if err != nil || len(msg) < msghdrSize {
+		return nil
+	}
+
+	namePtr := readPtr(msg, msghdrNameOffset)
+	nameLen := int(int32(binary.NativeEndian.Uint32(msg[msghdrNameLenOffset:])))
+	return readSockAddr(pid, namePtr, nameLen)
+}
+
+func readPtr(data []byte, offset int) uint64 {
+	if ptrSize == 8 {
+		return binary.NativeEndian.Uint64(data[offset:])
+	}
+
+	return uint64(binary.NativeEndian.Uint32(data[offset:]))
+}
+
+func readMemory(pid int, ptr uint64, size int) ([]byte, error) {
+	data := make([]byte, size)
+	count, err := syscall.PtracePeekData(pid, uintptr(ptr), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data[:count], nil
+}
+
+func readSockAddr(pid int, ptr uint64, size int) *sockAddr {
+	if ptr == 0 || size < 2 {
+		return nil
+	}
+
+	if size > maxSockAddrSize {
+		size = maxSockAddrSize
+	}
+
+	data, err := readMemory(pid, ptr, size)
+	if err != nil {
+		return nil
+	}
+
+	return parseSockAddr(data)
+}
+
+func parseSockAddr(data []byte) *sockAddr {
+	if len(data) < 2 {
+		return nil
+	}
+
+	switch int(binary.NativeEndian.Uint16(data)) {
+	case syscall.AF_INET:
+		//struct sockaddr_in: family(2) port(2) addr(4)
+		if len(data) < 8 {
+			return nil
+		}
+
+		return &sockAddr{
+			family:  FamilyIPv4,
+			address: net.IP(data[4:8]).String(),
+			port:    int(binary.BigEndian.Uint16(data[2:4])),
+		}
+
+	case syscall.AF_INET6:
+		//struct sockaddr_in6: family(2) port(2) flowinfo(4) addr(16) scope_id(4)
+		if len(data) < 24 {
+			return nil
+		}
+
+		return &sockAddr{
+			family:  FamilyIPv6,
+			address: net.IP(data[8:24]).String(),
+			port:    int(binary.BigEndian.Uint16(data[2:4])),
+		}
+
+	case syscall.AF_UNIX:
+		//struct sockaddr_un: family(2) path(108)
+		path := data[2:]
+		if len(path) == 0 {
+			//unnamed socket
+			return nil
+		}
+
+		name := ""
+		if path[0] == 0 {
+			//abstract socket address
+			name = "@" + string(bytes.TrimRight(path[1:], "\x00"))
+		} else {
+			if idx := bytes.IndexByte(path, 0); idx != -1 {
+				path = path[:idx]
+			}
+			name = string(path)
+		}
+
+		return &sockAddr{
+			family:  FamilyUnix,
+			address: name,
+		}
+	}
+
+	return nil
+}
+
+var dnsQueryTypes = map[uint16]string{
+	1:   "A",
+	2:   "NS",
+	5:   "CNAME",
+	6:   "SOA",
+	12:  "PTR",
+	15:  "MX",
+	16:  "TXT",
+	28:  "AAAA",
+	33:  "SRV",
+	64:  "SVCB",
+	65:  "HTTPS",
+	255: "ANY",
+}
+
+func dnsQueryTypeName(qtype uint16) string {
+	if name, found := dnsQueryTypes[qtype]; found {
+		return name
+	}
+
+	return fmt.Sprintf("TYPE%d", qtype)
+}
+
+// parseDNSQuery extracts the questions from a DNS query message
+func parseDNSQuery(data []byte) []dnsQuestion {
+	if len(data) < dnsHeaderSize {
+		return nil
+	}
+
+	flags := binary.BigEndian.Uint16(data[2:4])
+	if flags&0x8000 != 0 {
+		//not a query
+		return nil
+	}
+
+	qdcount := int(binary.BigEndian.Uint16(data[4:6]))
+	if qdcount == 0 || qdcount > maxDNSQuestions {
+		return nil
+	}
+
+	var questions []dnsQuestion
+	offset := dnsHeaderSize
+	for i := 0; i < qdcount; i++ {
+		name, next, ok := parseDNSName(data, offset)
+		if !ok || next+4 > len(data) {
+			break
+		}
+
+		questions = append(questions, dnsQuestion{
+			name:  name,
+			qtype: dnsQueryTypeName(binary.BigEndian.Uint16(data[next : next+2])),
+		})
+
+		offset = next + 4
+	}
+
+	return questions
+}
+
+func parseDNSName(data []byte, offset int) (string, int, bool) {
+	var labels []string
+	for {
+		if offset >= len(data) {
+			return "", 0, false
+		}
+
+		size := int(data[offset])
+		if size == 0 {
+			offset++
+			break
+		}
+
+		//no name compression in the query questions
+		if size&0xc0 != 0 || offset+1+size > len(data) {
+			return "", 0, false
+		}
+
+		labels = append(labels, string(data[offset+1:offset+1+size]))
+		offset += 1 + size
+	}
+
+	if len(labels) == 0 {
+		return ".", offset, true
+	}
+
+	return strings.ToLower(strings.Join(labels, ".")), offset, true
+}
@@ -0,0 +1,192 @@
+//go:build !arm64
+// +build !arm64
+
+package ptrace
+
+import (
+	"encoding/binary"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/report"
+)
+
+func sockAddrData(family int, rest ...byte) []byte {
+	data := make([]byte, 2)
+	binary.NativeEndian.PutUint16(data, uint16(family))
+	return append(data, rest...)
+}
+
+func TestParseSockAddr(t *testing.T) {
+	ipv6 := []byte{0x1f, 0x90, 0, 0, 0, 0}
+	ipv6 = append(ipv6, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1)
+
+	tt := []struct {
+		name     string
+		data     []byte
+		expected *sockAddr
+	}{
+		{
+			name:     "ipv4",
+			data:     sockAddrData(syscall.AF_INET, 0x01, 0xbb, 10, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0),
+			expected: &sockAddr{family: FamilyIPv4, address: "10.0.0.1", port: 443},
+		},
+		{
+			name:     "ipv6",
+			data:     sockAddrData(syscall.AF_INET6, append(ipv6, 0, 0, 0, 0)...),
+			expected: &sockAddr{family: FamilyIPv6, address: "2001:db8::1", port: 8080},
+		},
+		{
+			name:     "unix path",
+			data:     sockAddrData(syscall.AF_UNIX, append([]byte("/run/app.sock"), 0, 0, 0)...),
+			expected: &sockAddr{family: FamilyUnix, address: "/run/app.sock"},
+		},
+		{
+			name:     "unix abstract",
+			data:     sockAddrData(syscall.AF_UNIX, append([]byte{0}, []byte("app")...)...),
+			expected: &sockAddr{family: FamilyUnix, address: "@app"},
+		},
+		{name: "unnamed unix", data: sockAddrData(syscall.AF_UNIX)},
+		{name: "short ipv4", data: sockAddrData(syscall.AF_INET, 0x01, 0xbb, 10)},
+		{name: "short ipv6", data: sockAddrData(syscall.AF_INET6, ipv6[:10]...)},
+		{name: "other family", data: sockAddrData(syscall.AF_NETLINK, 0, 0, 0, 0)},
+		{name: "no family", data: []byte{2}},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, parseSockAddr(test.data), test.name)
+	}
+}
+
+func dnsQueryData(flags uint16, questions ...[]byte) []byte {
+	data := make([]byte, dnsHeaderSize)
+	binary.BigEndian.PutUint16(data[0:2], 0x1234)
+	binary.BigEndian.PutUint16(data[2:4], flags)
+	binary.BigEndian.PutUint16(data[4:6], uint16(len(questions)))
+	for _, q := range questions {
+		data = append(data, q...)
+	}
+
+	return data
+}
+
+func dnsQuestionData(qtype uint16, labels ...string) []byte {
+	var data []byte
+	for _, label := range labels {
+		data = append(data, byte(len(label)))
+		data = append(data, label...)
+	}
+
+	data = append(data, 0, 0, 0, 0, 1)
+	binary.BigEndian.PutUint16(data[len(data)-4:], qtype)
+	return data
+}
+
+func TestParseDNSQuery(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     []byte
+		expected []dnsQuestion
+	}{
+		{
+			name:     "single question",
+			data:     dnsQueryData(0x0100, dnsQuestionData(1, "API", "Example", "com")),
+			expected: []dnsQuestion{{name: "api.example.com", qtype: "A"}},
+		},
+		{
+			name: "multiple questions",
+			data: dnsQueryData(0x0100,
+				dnsQuestionData(28, "example", "com"),
+				dnsQuestionData(99, "svc", "local")),
+			expected: []dnsQuestion{
+				{name: "example.com", qtype: "AAAA"},
+				{name: "svc.local", qtype: "TYPE99"},
+			},
+		},
+		{
+			name:     "root name",
+			data:     dnsQueryData(0, dnsQuestionData(2)),
+			expected: []dnsQuestion{{name: ".", qtype: "NS"}},
+		},
+		{
+			name: "truncated second question",
+			data: dnsQueryData(0,
+				dnsQuestionData(1, "example", "com"),
+				dnsQuestionData(1, "other", "com")[:5]),
+			expected: []dnsQuestion{{name: "example.com", qtype: "A"}},
+		},
+		{name: "response", data: dnsQueryData(0x8180, dnsQuestionData(1, "example", "com"))},
+		{name: "no questions", data: dnsQueryData(0x0100)},
+		{name: "compressed name", data: dnsQueryData(0, []byte{0xc0, 0x0c, 0, 1, 0, 1})},
+		{name: "label past the end", data: dnsQueryData(0, []byte{20, 'a', 'b'})},
+		{name: "short header", data: []byte{0x12, 0x34, 0x01}},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, parseDNSQuery(test.data), test.name)
+	}
+}
+
+func errnoRetVal(errno syscall.Errno) uint64 {
+	ret := -int64(errno)
+	return uint64(ret)
+}
+
+func TestNetCallEvents(t *testing.T) {
+	call := &netCall{
+		tgid:     10,
+		name:     "sendto",
+		op:       report.NetOpDNS,
+		domain:   syscall.AF_INET,
+		sockType: syscall.SOCK_DGRAM,
+		addr:     &sockAddr{family: FamilyIPv4, address: "10.0.0.2", port: 53},
+		questions: []dnsQuestion{
+			{name: "example.com", qtype: "A"},
+			{name: "example.com", qtype: "AAAA"},
+		},
+	}
+
+	events := call.events(44, 29)
+	require.Len(t, events, 2)
+	assert.Equal(t, ProtoUDP, events[0].Protocol)
+	assert.Equal(t, "example.com", events[0].Hostname)
+	assert.Equal(t, "AAAA", events[1].QueryType)
+	assert.Empty(t, events[1].Error)
+
+	//the polling errors are not reported
+	assert.Empty(t, call.events(44, errnoRetVal(syscall.EAGAIN)))
+
+	call.op = report.NetOpConnect
+	call.questions = nil
+	call.sockType = syscall.SOCK_STREAM
+	events = call.events(42, errnoRetVal(syscall.ECONNREFUSED))
+	require.Len(t, events, 1)
+	assert.Equal(t, ProtoTCP, events[0].Protocol)
+	assert.NotEmpty(t, events[0].Error)
+
+	assert.Empty(t, (&netCall{op: report.NetOpConnect}).events(42, 0))
+}
+
+func TestSyscallStateProcessor(t *testing.T) {
+	var netCallNum int
+	for num, p := range syscallProcessors {
+		if p.SyscallType() == NetworkType {
+			netCallNum = num
+			break
+		}
+	}
+
+	require.NotZero(t, netCallNum)
+
+	//the socket syscalls are processed only when the network activity is reported
+	cstate := &syscallState{callNum: uint64(netCallNum)}
+	_, found := cstate.processor()
+	assert.False(t, found)
+
+	cstate.sockets = newSocketTable()
+	_, found = cstate.processor()
+	assert.True(t, found)
+}
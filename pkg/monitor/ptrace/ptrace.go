@@ -119,6 +119,10 @@ type syscallState struct {
 	exiting      bool
 	pathParam    string
 	pathParamErr error
+	netCall      *netCall
+	sockets      *socketTable
+	gotArgVal    bool
+	argVal       uint64
 }
 
 type App struct {
@@ -146,12 +150,15 @@ type App struct {
 	StateCh  chan AppState
 	ReportCh chan *report.PtMonitorReport
 
+	netEventCh chan<- NetEvent
+
 	cmd    *exec.Cmd
 	pgid   int
 	Report report.PtMonitorReport
 
 	fsActivity      map[string]*report.FSActivityInfo
 	syscallActivity map[uint32]uint64
+	syscallArgs     CallArgs
 	//syscallResolver system.NumberResolverFunc
 
 	eventCh         chan syscallEvent
@@ -176,6 +183,9 @@ type syscallEvent struct {
 	callNum   uint32
 	retVal    uint64
 	pathParam string
+	netCall   *netCall
+	gotArgVal bool
+	argVal    uint64
 }
 
 func newApp(
@@ -218,6 +228,8 @@ func newApp(
 		StateCh:  make(chan AppState, 5),
 		ReportCh: make(chan *report.PtMonitorReport),
 
+		netEventCh: runOpt.NetEventCh,
+
 		Report: report.PtMonitorReport{
 			ArchName:     string(archName),
 			SyscallStats: map[string]report.SyscallStatInfo{},
@@ -226,6 +238,7 @@ func newApp(
 
 		fsActivity:      map[string]*report.FSActivityInfo{},
 		syscallActivity: map[uint32]uint64{},
+		syscallArgs:     CallArgs{},
 
 		eventCh:         make(chan syscallEvent, eventBufSize),
 		collectorDoneCh: make(chan int, 2),
@@ -381,6 +394,21 @@ func (app *App) processFileActivity(e *syscallEvent) {
 	}
 }
 
+func (app *App) processNetActivity(e *syscallEvent) {
+	if e.netCall == nil || app.netEventCh == nil {
+		return
+	}
+
+	for _, evt := range e.netCall.events(e.callNum, e.retVal) {
+		select {
+		case app.netEventCh <- evt:
+		default:
+			app.logger.WithField("op", "processNetActivity").
+				Debugf("net event dropped (evt=%#v)", evt)
+		}
+	}
+}
+
 func (app *App) process() {
 	logger := app.logger.WithField("op", "process")
 	logger.Debug("call")
@@ -409,7 +437,9 @@ done:
 			logger.Tracef("event ==> {pid=%v cn=%d}", e.pid, e.callNum)
 
 			app.processSyscallActivity(&e)
+			app.processSyscallArgs(&e)
 			app.processFileActivity(&e)
+			app.processNetActivity(&e)
 
 		case rc := <-app.collectorDoneCh:
 			logger.Debugf("collector finished => %v", rc)
@@ -434,7 +464,9 @@ drain:
 			logger.Tracef("event (drained) ==> {pid=%v cn=%d}", e.pid, e.callNum)
 
 			app.processSyscallActivity(&e)
+			app.processSyscallArgs(&e)
 			app.processFileActivity(&e)
+			app.processNetActivity(&e)
 
 		default:
 			logger.Trace("event draining is finished")
@@ -458,6 +490,7 @@ drain:
 	}
 
 	app.Report.SyscallNum = uint32(len(app.Report.SyscallStats))
+	app.Report.SyscallArgs = app.syscallArgs.Report()
 	app.Report.FSActivity = app.FileActivity()
 
 	app.StateCh <- state
@@ -667,8 +700,13 @@ func (app *App) collect() {
 
 	logger.Debugf("trace syscall mainPID=%v", callPid)
 
+	var sockets *socketTable
+	if app.netEventCh != nil {
+		sockets = newSocketTable()
+	}
+
 	pidSyscallState := map[int]*syscallState{}
-	pidSyscallState[callPid] = &syscallState{pid: callPid}
+	pidSyscallState[callPid] = &syscallState{pid: callPid, sockets: sockets}
 
 	mainExiting := false
 	waitFor := -1
@@ -778,6 +816,9 @@ func (app *App) collect() {
 			}
 
 			delete(pidSyscallState, wpid)
+			if sockets != nil {
+				sockets.forget(wpid)
+			}
 			if app.MainPID() == wpid {
 				logger.Debugf("[%d/%d]: wpid(%v) is main PID and terminated...",
 					app.cmd.Process.Pid, app.pgid, wpid)
@@ -813,7 +854,7 @@ func (app *App) collect() {
 				logger.Debugf("[%d/%d]: collector loop - new pid - mainPid=%v pid=%v (prevPid=%v) - add state",
 					app.cmd.Process.Pid, app.pgid, app.MainPID(), wpid, prevPid)
 				//TODO: create new process records from clones/forks
-				cstate = &syscallState{pid: wpid}
+				cstate = &syscallState{pid: wpid, sockets: sockets}
 				pidSyscallState[wpid] = cstate
 			}
 
@@ -858,15 +899,21 @@ func (app *App) collect() {
 					callNum:   uint32(cstate.callNum),
 					retVal:    cstate.retVal,
 					pathParam: cstate.pathParam,
+					netCall:   cstate.netCall,
+					gotArgVal: cstate.gotArgVal,
+					argVal:    cstate.argVal,
 				}
 
 				cstate.gotCallNum = false
 				cstate.gotRetVal = false
 				cstate.pathParam = ""
 				cstate.pathParamErr = nil
+				cstate.netCall = nil
+				cstate.gotArgVal = false
+				cstate.argVal = 0
 
 				_, ok := app.origPaths[evt.pathParam]
-				if app.includeNew {
+				if app.includeNew || evt.netCall != nil || evt.gotArgVal {
 					ok = true
 				}
 
@@ -902,7 +949,11 @@ func (app *App) collect() {
 							app.cmd.Process.Pid, app.pgid, newPid)
 						pidSyscallState[int(newPid)].started = true
 					} else {
-						pidSyscallState[int(newPid)] = &syscallState{pid: int(newPid), started: true}
+						pidSyscallState[int(newPid)] = &syscallState{pid: int(newPid), started: true, sockets: sockets}
+					}
+
+					if sockets != nil {
+						sockets.inherit(wpid, int(newPid))
 					}
 				}
 
@@ -995,8 +1046,9 @@ func onSyscall(pid int, cstate *syscallState) (bool, error) {
 	cstate.callNum = system.CallNumber(regs)
 	cstate.expectReturn = true
 	cstate.gotCallNum = true
+	onCallArg(regs, cstate)
 
-	if processor, found := syscallProcessors[int(cstate.callNum)]; found && processor != nil {
+	if processor, found := cstate.processor(); found {
 		processor.OnCall(pid, regs, cstate)
 		doGenEvent := processor.EventOnCall()
 		return doGenEvent, nil
@@ -1015,13 +1067,28 @@ func onSyscallReturn(pid int, cstate *syscallState) error {
 	cstate.expectReturn = false
 	cstate.gotRetVal = true
 
-	if processor, found := syscallProcessors[int(cstate.callNum)]; found {
+	if processor, found := cstate.processor(); found {
 		processor.OnReturn(pid, regs, cstate)
 	}
 
 	return nil
 }
 
+// processor returns the syscall processor for the current call
+// (skipping the socket syscall processors if the network activity is not reported)
+func (cstate *syscallState) processor() (SyscallProcessor, bool) {
+	processor, found := syscallProcessors[int(cstate.callNum)]
+	if !found || processor == nil {
+		return nil, false
+	}
+
+	if processor.SyscallType() == NetworkType && cstate.sockets == nil {
+		return nil, false
+	}
+
+	return processor, true
+}
+
 ///////////////////////////////////
 
 func fdName(fd int) string {
@@ -1088,6 +1155,7 @@ const (
 	CheckFileType SyscallTypeName = "type.checkfile"
 	OpenFileType  SyscallTypeName = "type.openfile"
 	ExecType      SyscallTypeName = "type.exec"
+	NetworkType   SyscallTypeName = "type.network"
 )
 
 type SyscallProcessor interface {
@@ -1432,6 +1500,28 @@ func init() {
 			StringParam: SPPTwo,
 		},
 	})
+
+	//socket system calls (see netactivity.go for the call params):
+	for _, name := range []string{
+		"socket",
+		"connect",
+		"bind",
+		"listen",
+		"accept",
+		"accept4",
+		"sendto",
+		"sendmsg",
+		"sendmmsg",
+		"write",
+		"close",
+	} {
+		addSyscallProcessor(&netSyscallProcessor{
+			syscallProcessorCore: &syscallProcessorCore{
+				Name: name,
+				Type: NetworkType,
+			},
+		})
+	}
 }
 
 func addSyscallProcessor(p SyscallProcessor) {
@@ -0,0 +1,46 @@
+//go:build !arm64
+// +build !arm64
+
+package ptrace
+
+import (
+	"syscall"
+
+	"github.com/slimtoolkit/slim/pkg/system"
+)
+
+func callParam(regs syscall.PtraceRegs, index uint) uint64 {
+	switch index {
+	case 0:
+		return system.CallFirstParam(regs)
+	case 1:
+		return system.CallSecondParam(regs)
+	case 2:
+		return system.CallThirdParam(regs)
+	case 3:
+		return system.CallFourthParam(regs)
+	case 4:
+		return system.CallFifthParam(regs)
+	default:
+		return system.CallSixthParam(regs)
+	}
+}
+
+// onCallArg saves the sensitive argument value of the current syscall (if any)
+func onCallArg(regs syscall.PtraceRegs, cstate *syscallState) {
+	index, found := CallArgIndex(cstate.callNum)
+	if !found {
+		return
+	}
+
+	cstate.gotArgVal = true
+	cstate.argVal = callParam(regs, index)
+}
+
+func (app *App) processSyscallArgs(e *syscallEvent) {
+	if !e.gotArgVal {
+		return
+	}
+
+	app.syscallArgs.Add(e.callNum, e.argVal)
+}
@@ -2,6 +2,8 @@ package ptrace
 
 import (
 	"io"
+
+	"github.com/slimtoolkit/slim/pkg/report"
 )
 
 type AppRunOpt struct {
@@ -14,4 +16,42 @@ type AppRunOpt struct {
 	RunAsUser           bool
 	RTASourcePT         bool
 	ReportOnMainPidExit bool
+	// Optional network activity event sink (used by the network monitor;
+	// the socket system calls are not processed if it's not set)
+	NetEventCh chan<- NetEvent
+}
+
+// Network address families
+const (
+	FamilyIPv4 = report.NetFamilyIPv4
+	FamilyIPv6 = report.NetFamilyIPv6
+	FamilyUnix = report.NetFamilyUnix
+)
+
+// Network protocols
+const (
+	ProtoTCP     = report.NetProtoTCP
+	ProtoUDP     = report.NetProtoUDP
+	ProtoRaw     = report.NetProtoRaw
+	ProtoUnix    = report.NetProtoUnix
+	ProtoUnknown = report.NetProtoUnknown
+)
+
+// NetEvent is a network activity event captured from the traced socket system calls
+// (Pid is the process ID, not the thread ID).
+// The endpoint address is the remote address for the connect, send and dns operations
+// and the local address for the bind, listen and accept operations.
+type NetEvent struct {
+	Pid       int
+	CallNum   uint32
+	CallName  string
+	Op        string
+	Protocol  string
+	Family    string
+	Address   string
+	Port      int
+	Peer      string
+	Hostname  string
+	QueryType string
+	Error     string
 }
@@ -0,0 +1,279 @@
+// Package probediff has the normalized probe call results
+// and compares the probe runs for the original ('fat') and minified containers.
+package probediff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// StatusError is the call result status for the calls without a response
+const StatusError = "error"
+
+// Regression types
+const (
+	//RegressionMissing is for the calls not made in the minified container probe run
+	RegressionMissing     = "missing"
+	RegressionCallError   = "call.error"
+	RegressionStatus      = "status"
+	RegressionContentType = "content.type"
+	RegressionBodyShape   = "body.shape"
+	RegressionAssertion   = "assertion"
+)
+
+const (
+	maxBodyShapeLength = 256
+	shapeHashPrefix    = "sha256:"
+)
+
+// CallResult is the normalized probe call result
+type CallResult struct {
+	//Key identifies the probe call in the probe runs
+	//(the port is the container port, so it's the same for the different containers)
+	Key      string `json:"key"`
+	Protocol string `json:"protocol"`
+	Port     string `json:"port"`
+	Method   string `json:"method,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	//Status is the HTTP status code or the protocol probe status
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	BodySize    int64  `json:"body_size,omitempty"`
+	//BodyShape is the JSON body structure (the keys and the value types)
+	BodyShape string `json:"body_shape,omitempty"`
+	//BodyHash is the normalized body hash
+	//(it's not compared because the bodies often have volatile values)
+	BodyHash   string             `json:"body_hash,omitempty"`
+	Assertions []*AssertionResult `json:"assertions,omitempty"`
+}
+
+// Failed returns true if the call didn't get a response
+func (ref *CallResult) Failed() bool {
+	return ref.Status == StatusError
+}
+
+// FailedAssertions returns the failed call assertions
+func (ref *CallResult) FailedAssertions() []*AssertionResult {
+	var failed []*AssertionResult
+	for _, assertion := range ref.Assertions {
+		if !assertion.OK {
+			failed = append(failed, assertion)
+		}
+	}
+
+	return failed
+}
+
+// AssertionResult is the probe call expectation check result
+type AssertionResult struct {
+	Type     string `json:"type"`
+	Target   string `json:"target,omitempty"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	OK       bool   `json:"ok"`
+}
+
+// Regression is a probe call result difference where the minified container does worse
+type Regression struct {
+	Key    string `json:"key"`
+	Type   string `json:"type"`
+	Target string `json:"target,omitempty"`
+	Fat    string `json:"fat"`
+	Slim   string `json:"slim"`
+}
+
+// Report has the probe call results for both containers and their regressions
+type Report struct {
+	FatCalls    []*CallResult `json:"fat_calls"`
+	SlimCalls   []*CallResult `json:"slim_calls"`
+	Regressions []*Regression `json:"regressions"`
+}
+
+// Compare returns the probe run report with the regressions in the minified container probe calls
+// (the calls without a response in the 'fat' container probe run don't have a baseline,
+// so they are not compared)
+func Compare(fatCalls, slimCalls []*CallResult) *Report {
+	report := &Report{
+		FatCalls:    fatCalls,
+		SlimCalls:   slimCalls,
+		Regressions: []*Regression{},
+	}
+
+	slimByKey := map[string]*CallResult{}
+	for _, call := range slimCalls {
+		slimByKey[call.Key] = call
+	}
+
+	for _, fat := range fatCalls {
+		if fat.Failed() {
+			continue
+		}
+
+		slim, found := slimByKey[fat.Key]
+		if !found {
+			report.Regressions = append(report.Regressions, &Regression{
+				Key:  fat.Key,
+				Type: RegressionMissing,
+				Fat:  fat.Status,
+			})
+
+			continue
+		}
+
+		report.Regressions = append(report.Regressions, compareCalls(fat, slim)...)
+	}
+
+	return report
+}
+
+func compareCalls(fat, slim *CallResult) []*Regression {
+	if slim.Failed() {
+		return []*Regression{
+			{
+				Key:  fat.Key,
+				Type: RegressionCallError,
+				Fat:  fat.Status,
+				Slim: slim.Error,
+			},
+		}
+	}
+
+	var regressions []*Regression
+	addRegression := func(regressionType, target, fatValue, slimValue string) {
+		regressions = append(regressions, &Regression{
+			Key:    fat.Key,
+			Type:   regressionType,
+			Target: target,
+			Fat:    fatValue,
+			Slim:   slimValue,
+		})
+	}
+
+	if fat.Status != slim.Status {
+		addRegression(RegressionStatus, "", fat.Status, slim.Status)
+	}
+
+	if fat.ContentType != "" && fat.ContentType != slim.ContentType {
+		addRegression(RegressionContentType, "", fat.ContentType, slim.ContentType)
+	}
+
+	if fat.BodyShape != "" && fat.BodyShape != slim.BodyShape {
+		addRegression(RegressionBodyShape, "", fat.BodyShape, slim.BodyShape)
+	}
+
+	//the assertions are created from the same probe command expectations
+	for idx, fatAssertion := range fat.Assertions {
+		if !fatAssertion.OK || idx >= len(slim.Assertions) {
+			continue
+		}
+
+		slimAssertion := slim.Assertions[idx]
+		if slimAssertion.Type != fatAssertion.Type || slimAssertion.Target != fatAssertion.Target {
+			continue
+		}
+
+		if !slimAssertion.OK {
+			target := fatAssertion.Type
+			if fatAssertion.Target != "" {
+				target = fmt.Sprintf("%s:%s", fatAssertion.Type, fatAssertion.Target)
+			}
+
+			addRegression(RegressionAssertion, target, fatAssertion.Actual, slimAssertion.Actual)
+		}
+	}
+
+	return regressions
+}
+
+// NormalizeContentType returns the media type without the parameters
+func NormalizeContentType(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0]))
+	}
+
+	return mediaType
+}
+
+// IsJSONContentType returns true for the JSON media types (including the '+json' types)
+func IsJSONContentType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// NormalizeBody returns the body shape (for the JSON bodies) and the normalized body hash
+// (the JSON bodies are hashed in their canonical form, the other bodies without the surrounding whitespace)
+func NormalizeBody(mediaType string, data []byte) (string, string) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return "", ""
+	}
+
+	if IsJSONContentType(mediaType) || json.Valid(data) {
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err == nil {
+			//json.Marshal sorts the object keys
+			if canonical, err := json.Marshal(doc); err == nil {
+				data = canonical
+			}
+
+			shape := JSONShape(doc)
+			if len(shape) > maxBodyShapeLength {
+				shape = shapeHashPrefix + hash([]byte(shape))
+			}
+
+			return shape, hash(data)
+		}
+	}
+
+	return "", hash(data)
+}
+
+// JSONShape returns the JSON value structure
+// (the object keys and the value types; the arrays are represented by their first element)
+func JSONShape(value interface{}) string {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		var fields []string
+		for _, key := range keys {
+			fields = append(fields, fmt.Sprintf("%q:%s", key, JSONShape(typed[key])))
+		}
+
+		return "{" + strings.Join(fields, ",") + "}"
+	case []interface{}:
+		if len(typed) == 0 {
+			return "[]"
+		}
+
+		return "[" + JSONShape(typed[0]) + "]"
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	default:
+		return "null"
+	}
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+package probediff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func okAssertion(assertionType, target, actual string) *AssertionResult {
+	return &AssertionResult{Type: assertionType, Target: target, Expected: "x", Actual: actual, OK: true}
+}
+
+func failedAssertion(assertionType, target, actual string) *AssertionResult {
+	return &AssertionResult{Type: assertionType, Target: target, Expected: "x", Actual: actual}
+}
+
+func TestCompare(t *testing.T) {
+	fatCalls := []*CallResult{
+		{Key: "GET http:8080/", Status: "200", ContentType: "text/html"},
+		{Key: "GET http:8080/api", Status: "200", ContentType: "application/json", BodyShape: `{"id":number}`},
+		{Key: "GET http:8080/missing", Status: "200"},
+		{Key: "GET http:8080/down", Status: "200"},
+		{Key: "GET http:8080/fat-error", Status: StatusError, Error: "timeout"},
+		{
+			Key:    "GET http:8080/checks",
+			Status: "200",
+			Assertions: []*AssertionResult{
+				okAssertion("status", "", "200"),
+				okAssertion("json", "$.status", `"ok"`),
+				failedAssertion("header", "X-Version", ""),
+				okAssertion("body", "", "ok"),
+				okAssertion("json", "$.extra", "1"),
+			},
+		},
+		{Key: "redis:6379", Status: "ok"},
+	}
+
+	slimCalls := []*CallResult{
+		{Key: "GET http:8080/", Status: "200", ContentType: "text/html"},
+		{Key: "GET http:8080/api", Status: "500", ContentType: "text/plain"},
+		{Key: "GET http:8080/down", Status: StatusError, Error: "connection refused"},
+		{Key: "GET http:8080/fat-error", Status: StatusError, Error: "timeout"},
+		{
+			Key:    "GET http:8080/checks",
+			Status: "200",
+			Assertions: []*AssertionResult{
+				okAssertion("status", "", "200"),
+				failedAssertion("json", "$.status", `"error"`),
+				failedAssertion("header", "X-Version", ""),
+				failedAssertion("json", "$.other", ""),
+			},
+		},
+		{Key: "redis:6379", Status: "auth.required"},
+		{Key: "GET http:8080/new", Status: StatusError},
+	}
+
+	report := Compare(fatCalls, slimCalls)
+	assert.Equal(t, fatCalls, report.FatCalls)
+	assert.Equal(t, slimCalls, report.SlimCalls)
+	assert.Equal(t, []*Regression{
+		{Key: "GET http:8080/api", Type: RegressionStatus, Fat: "200", Slim: "500"},
+		{Key: "GET http:8080/api", Type: RegressionContentType, Fat: "application/json", Slim: "text/plain"},
+		{Key: "GET http:8080/api", Type: RegressionBodyShape, Fat: `{"id":number}`},
+		{Key: "GET http:8080/missing", Type: RegressionMissing, Fat: "200"},
+		{Key: "GET http:8080/down", Type: RegressionCallError, Fat: "200", Slim: "connection refused"},
+		{Key: "GET http:8080/checks", Type: RegressionAssertion, Target: "json:$.status", Fat: `"ok"`, Slim: `"error"`},
+		{Key: "redis:6379", Type: RegressionStatus, Fat: "ok", Slim: "auth.required"},
+	}, report.Regressions)
+
+	report = Compare(fatCalls[:1], slimCalls[:1])
+	assert.NotNil(t, report.Regressions)
+	assert.Empty(t, report.Regressions)
+
+	//the regressions are an empty JSON array (not null)
+	data, err := json.Marshal(Compare(nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, `{"fat_calls":null,"slim_calls":null,"regressions":[]}`, string(data))
+}
+
+func TestCallResultFailedAssertions(t *testing.T) {
+	result := &CallResult{
+		Status: "200",
+		Assertions: []*AssertionResult{
+			okAssertion("status", "", "200"),
+			failedAssertion("header", "X-Version", ""),
+		},
+	}
+
+	assert.False(t, result.Failed())
+	assert.Equal(t, []*AssertionResult{result.Assertions[1]}, result.FailedAssertions())
+	assert.True(t, (&CallResult{Status: StatusError}).Failed())
+	assert.Empty(t, (&CallResult{}).FailedAssertions())
+}
+
+func TestNormalizeContentType(t *testing.T) {
+	tt := map[string]string{
+		"":                                  "",
+		"application/json":                  "application/json",
+		"Application/JSON; charset=UTF-8":   "application/json",
+		"text/html;charset=utf-8":           "text/html",
+		" text/plain ; bad parameter":       "text/plain",
+		"application/problem+json; x=\"y\"": "application/problem+json",
+	}
+
+	for value, expected := range tt {
+		assert.Equal(t, expected, NormalizeContentType(value), value)
+	}
+}
+
+func TestIsJSONContentType(t *testing.T) {
+	assert.True(t, IsJSONContentType("application/json"))
+	assert.True(t, IsJSONContentType("application/vnd.api+json"))
+	assert.False(t, IsJSONContentType("text/plain"))
+	assert.False(t, IsJSONContentType(""))
+}
+
+func TestNormalizeBody(t *testing.T) {
+	shape, bodyHash := NormalizeBody("application/json", []byte(` {"b":[1,2],"a":{"c":true,"d":null}} `))
+	assert.Equal(t, `{"a":{"c":bool,"d":null},"b":[number]}`, shape)
+
+	//the JSON bodies are hashed in their canonical form
+	_, otherHash := NormalizeBody("text/plain", []byte(`{"a":{"d":null,"c":true},"b":[1,2]}`))
+	assert.Equal(t, bodyHash, otherHash)
+
+	_, changedHash := NormalizeBody("application/json", []byte(`{"a":{"c":true,"d":null},"b":[1,3]}`))
+	assert.NotEqual(t, bodyHash, changedHash)
+
+	shape, bodyHash = NormalizeBody("text/html", []byte("\n<html></html>\n"))
+	assert.Empty(t, shape)
+	_, otherHash = NormalizeBody("text/html", []byte("<html></html>"))
+	assert.Equal(t, otherHash, bodyHash)
+
+	//not a valid JSON body
+	shape, bodyHash = NormalizeBody("application/json", []byte("{"))
+	assert.Empty(t, shape)
+	assert.NotEmpty(t, bodyHash)
+
+	shape, bodyHash = NormalizeBody("application/json", []byte("  "))
+	assert.Empty(t, shape)
+	assert.Empty(t, bodyHash)
+
+	//the long shapes are hashed
+	var fields []string
+	for i := 0; i < 50; i++ {
+		fields = append(fields, `"field`+strings.Repeat("x", i)+`":1`)
+	}
+
+	shape, _ = NormalizeBody("application/json", []byte("{"+strings.Join(fields, ",")+"}"))
+	assert.True(t, strings.HasPrefix(shape, shapeHashPrefix), shape)
+	assert.Len(t, shape, len(shapeHashPrefix)+64)
+}
+
+func TestJSONShape(t *testing.T) {
+	tt := []struct {
+		value    interface{}
+		expected string
+	}{
+		{value: nil, expected: "null"},
+		{value: "x", expected: "string"},
+		{value: 1.5, expected: "number"},
+		{value: json.Number("1"), expected: "number"},
+		{value: false, expected: "bool"},
+		{value: []interface{}{}, expected: "[]"},
+		{value: []interface{}{"a", 1.0}, expected: "[string]"},
+		{value: map[string]interface{}{}, expected: "{}"},
+		{
+			value:    map[string]interface{}{"b": []interface{}{map[string]interface{}{"id": 1.0}}, "a": "x"},
+			expected: `{"a":string,"b":[{"id":number}]}`,
+		},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, JSONShape(test.value), test.expected)
+	}
+}
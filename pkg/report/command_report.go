@@ -13,9 +13,12 @@ import (
 	"github.com/slimtoolkit/slim/pkg/docker/dockerfile/reverse"
 	"github.com/slimtoolkit/slim/pkg/docker/dockerimage"
 	"github.com/slimtoolkit/slim/pkg/docker/linter/check"
+	"github.com/slimtoolkit/slim/pkg/docker/linter/fix"
+	"github.com/slimtoolkit/slim/pkg/probediff"
 	"github.com/slimtoolkit/slim/pkg/system"
 	"github.com/slimtoolkit/slim/pkg/util/errutil"
 	"github.com/slimtoolkit/slim/pkg/version"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/scan"
 )
 
 // DefaultFilename is the default name for the command report
@@ -125,9 +128,14 @@ type BuildCommand struct {
 	ContainerReportName    string               `json:"container_report_name"`
 	SeccompProfileName     string               `json:"seccomp_profile_name"`
 	AppArmorProfileName    string               `json:"apparmor_profile_name"`
+	NetworkPolicyName      string               `json:"network_policy_name,omitempty"`
+	DockerNetworkName      string               `json:"docker_network_name,omitempty"`
+	SBOMFiles              []string             `json:"sbom_files,omitempty"`
+	MinifiedSBOMFiles      []string             `json:"minified_sbom_files,omitempty"`
 	ImageStack             []*reverse.ImageInfo `json:"image_stack"`
 	ImageCreated           bool                 `json:"image_created"`
 	ImageBuildEngine       string               `json:"image_build_engine"`
+	ProbeVerification      *probediff.Report    `json:"probe_verification,omitempty"`
 }
 
 // Output Version for 'profile'
@@ -148,6 +156,8 @@ type ProfileCommand struct {
 	ContainerReportName    string  `json:"container_report_name"`
 	SeccompProfileName     string  `json:"seccomp_profile_name"`
 	AppArmorProfileName    string  `json:"apparmor_profile_name"`
+	NetworkPolicyName      string  `json:"network_policy_name,omitempty"`
+	DockerNetworkName      string  `json:"docker_network_name,omitempty"`
 }
 
 // Output Version for 'xray'
@@ -165,6 +175,7 @@ type XrayCommand struct {
 	ImageArchiveLocation string                            `json:"image_archive_location"`
 	RawImageManifest     *dockerimage.DockerManifestObject `json:"raw_image_manifest,omitempty"`
 	RawImageConfig       *dockerimage.ConfigObject         `json:"raw_image_config,omitempty"`
+	SBOMFiles            []string                          `json:"sbom_files,omitempty"`
 }
 
 // Output Version for 'lint'
@@ -176,11 +187,15 @@ type LintCommand struct {
 	TargetType      string                   `json:"target_type"`
 	TargetReference string                   `json:"target_reference"`
 	BuildContextDir string                   `json:"build_context_dir,omitempty"`
+	OutputFormat    string                   `json:"output_format,omitempty"`
+	OutputFile      string                   `json:"output_file,omitempty"`
 	HitsCount       int                      `json:"hits_count"`
 	NoHitsCount     int                      `json:"nohits_count"`
 	ErrorsCount     int                      `json:"errors_count"`
 	Hits            map[string]*check.Result `json:"hits,omitempty"`   //map[CHECK_ID]CHECK_RESULT
 	Errors          map[string]error         `json:"errors,omitempty"` //map[CHECK_ID]ERROR_INFO
+	Fixes           []*fix.Edit              `json:"fixes,omitempty"`
+	FixesSaved      bool                     `json:"fixes_saved,omitempty"`
 }
 
 // Output Version for 'images'
@@ -197,6 +212,13 @@ const OVContainerizeCommand = "1.0"
 // ContainerizeCommand is the 'containerize' command report data
 type ContainerizeCommand struct {
 	Command
+	AppPath           string   `json:"app_path"`
+	AppArgs           []string `json:"app_args,omitempty"`
+	OutputImage       string   `json:"output_image,omitempty"`
+	OutputImageID     string   `json:"output_image_id,omitempty"`
+	OutputImageDigest string   `json:"output_image_digest,omitempty"`
+	OutputImageTar    string   `json:"output_image_tar,omitempty"`
+	ArtifactCount     int      `json:"artifact_count"`
 }
 
 // Output Version for 'convert'
@@ -205,6 +227,13 @@ const OVConvertCommand = "1.0"
 // ConvertCommand is the 'convert' command report data
 type ConvertCommand struct {
 	Command
+	SourceImage       string `json:"source_image"`
+	OutputImage       string `json:"output_image"`
+	Squashed          bool   `json:"squashed"`
+	MediaTypes        string `json:"media_types,omitempty"`
+	OutputImageID     string `json:"output_image_id,omitempty"`
+	OutputImageDigest string `json:"output_image_digest,omitempty"`
+	OutputLayerCount  int    `json:"output_layer_count"`
 }
 
 // Output Version for 'merge'
@@ -224,6 +253,13 @@ const OVEditCommand = "1.0"
 // EditCommand is the 'edit' command report data
 type EditCommand struct {
 	Command
+	TargetReference   string `json:"target_reference"`
+	OutputImage       string `json:"output_image,omitempty"`
+	OutputImageID     string `json:"output_image_id,omitempty"`
+	OutputImageDigest string `json:"output_image_digest,omitempty"`
+	OutputImageTar    string `json:"output_image_tar,omitempty"`
+	AddedFiles        int    `json:"added_files"`
+	RemovedFiles      int    `json:"removed_files"`
 }
 
 // Output Version for 'debug'
@@ -248,6 +284,9 @@ const OVServerCommand = "1.0"
 // ServerCommand is the 'server' command report data
 type ServerCommand struct {
 	Command
+	Address  string `json:"address,omitempty"`
+	JobsDir  string `json:"jobs_dir,omitempty"`
+	JobCount int    `json:"job_count"`
 }
 
 // Output Version for 'run'
@@ -266,6 +305,9 @@ const OVRegistryCommand = "1.0"
 type RegistryCommand struct {
 	Command
 	TargetReference string `json:"target_reference"`
+	SourceReference string `json:"source_reference,omitempty"`
+	SourceDigest    string `json:"source_digest,omitempty"`
+	TargetDigest    string `json:"target_digest,omitempty"`
 }
 
 // Output Version for 'vulnerability'
@@ -274,7 +316,16 @@ const OVVulnerabilityCommand = "1.0"
 // VulnerabilityCommand is the 'vulnerability' command report data
 type VulnerabilityCommand struct {
 	Command
-	Operation string `json:"operation"`
+	Operation string                 `json:"operation"`
+	Scan      *VulnerabilityScanInfo `json:"scan,omitempty"`
+}
+
+// VulnerabilityScanInfo is the 'vulnerability scan' command result data
+type VulnerabilityScanInfo struct {
+	Advisories int              `json:"advisories"`
+	Original   *scan.Result     `json:"original"`
+	Minified   *scan.Result     `json:"minified,omitempty"`
+	Comparison *scan.Comparison `json:"comparison,omitempty"`
 }
 
 func (cmd *Command) init(containerized bool) {
@@ -62,6 +62,8 @@ type ProcessInfo struct {
 	Cwd       string `json:"cwd"`
 	Root      string `json:"root"`
 	ParentPid int32  `json:"ppid"`
+	UID       int    `json:"uid"` //file system UID
+	GID       int    `json:"gid"` //file system GID
 }
 
 // FileInfo contains various file object and activity metadata
@@ -97,6 +99,15 @@ type SyscallStatInfo struct {
 	Count  uint64 `json:"count"`
 }
 
+// SyscallArgInfo contains the observed values (and their counts)
+// of a sensitive syscall argument (e.g., the 'clone' flags)
+type SyscallArgInfo struct {
+	Number uint32            `json:"num"`
+	Name   string            `json:"name"`
+	Index  uint              `json:"index"`
+	Values map[uint64]uint64 `json:"values"`
+}
+
 // PtMonitorReport contains various process execution metadata
 type PtMonitorReport struct {
 	Enabled      bool                       `json:"enabled"`
@@ -104,6 +115,7 @@ type PtMonitorReport struct {
 	SyscallCount uint64                     `json:"syscall_count"`
 	SyscallNum   uint32                     `json:"syscall_num"`
 	SyscallStats map[string]SyscallStatInfo `json:"syscall_stats"`
+	SyscallArgs  map[string]*SyscallArgInfo `json:"syscall_args,omitempty"`
 	FSActivity   map[string]*FSActivityInfo `json:"fs_activity"`
 }
 
@@ -115,6 +127,76 @@ type FSActivityInfo struct {
 	IsSubdir     bool             `json:"is_subdir"`
 }
 
+// Network operations
+const (
+	NetOpConnect = "connect"
+	NetOpBind    = "bind"
+	NetOpListen  = "listen"
+	NetOpAccept  = "accept"
+	NetOpSend    = "send"
+	NetOpDNS     = "dns"
+)
+
+// Network protocols
+const (
+	NetProtoTCP     = "tcp"
+	NetProtoUDP     = "udp"
+	NetProtoRaw     = "raw"
+	NetProtoUnix    = "unix"
+	NetProtoUnknown = "unknown"
+)
+
+// Network address families
+const (
+	NetFamilyIPv4 = "ipv4"
+	NetFamilyIPv6 = "ipv6"
+	NetFamilyUnix = "unix"
+)
+
+// NetMonitorReport is a network activity monitoring report.
+// The process activity is captured tracing the target app (if it's traced)
+// and the listeners and connections are sampled from the /proc/net socket tables
+// of the container network namespace.
+type NetMonitorReport struct {
+	Enabled     bool                        `json:"enabled"`
+	Traced      bool                        `json:"traced"`
+	EventCount  uint64                      `json:"event_count"`
+	SampleCount uint64                      `json:"sample_count"`
+	Processes   map[string]*NetProcessInfo  `json:"processes"`
+	Listeners   map[string]*NetActivityInfo `json:"listeners,omitempty"`
+	Connections map[string]*NetActivityInfo `json:"connections,omitempty"`
+	Hostnames   []string                    `json:"hostnames,omitempty"`
+}
+
+// NetProcessInfo contains the network activity of a process
+type NetProcessInfo struct {
+	Pid         int32                       `json:"pid"`
+	Path        string                      `json:"path,omitempty"`
+	Connections map[string]*NetActivityInfo `json:"connections,omitempty"`
+	Listeners   map[string]*NetActivityInfo `json:"listeners,omitempty"`
+	DNSLookups  map[string]*DNSLookupInfo   `json:"dns_lookups,omitempty"`
+}
+
+// NetActivityInfo contains various network endpoint activity metadata
+// (remote endpoints for connections and local endpoints for listeners)
+type NetActivityInfo struct {
+	Protocol   string            `json:"protocol"`
+	Family     string            `json:"family"`
+	Address    string            `json:"address"`
+	Port       int               `json:"port,omitempty"`
+	Ops        map[string]uint64 `json:"ops"`
+	ErrorCount uint64            `json:"errors,omitempty"`
+	Peers      map[string]uint64 `json:"peers,omitempty"`
+}
+
+// DNSLookupInfo contains various DNS lookup activity metadata
+type DNSLookupInfo struct {
+	Name       string            `json:"name"`
+	Count      uint64            `json:"count"`
+	QueryTypes map[string]uint64 `json:"query_types"`
+	Resolvers  map[string]uint64 `json:"resolvers,omitempty"`
+}
+
 // ArtifactProps contains various file system artifact properties
 type ArtifactProps struct {
 	FileType   ArtifactType    `json:"-"` //todo
@@ -125,6 +207,8 @@ type ArtifactProps struct {
 	Flags      map[string]bool `json:"flags,omitempty"`
 	DataType   string          `json:"data_type,omitempty"`
 	FileSize   int64           `json:"file_size"`
+	UID        int             `json:"uid"`
+	GID        int             `json:"gid"`
 	Sha1Hash   string          `json:"sha1_hash,omitempty"`
 	AppType    string          `json:"app_type,omitempty"`
 	FileInode  uint64          `json:"-"` //todo
@@ -176,6 +260,7 @@ type ImageReport struct {
 type MonitorReports struct {
 	Fan *FanMonitorReport `json:"fan"`
 	Pt  *PtMonitorReport  `json:"pt"`
+	Net *NetMonitorReport `json:"net,omitempty"`
 }
 
 // SystemReport provides a basic system report for the container environment
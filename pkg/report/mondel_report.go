@@ -8,6 +8,7 @@ const (
 	MDESourceDel = ".del" //Data Event Logger event
 	MDESourceFan = "m.fa" //FaNotify monitor event
 	MDESourcePT  = "m.pt" //PTrace monitor event
+	MDESourceNet = "m.nt" //Network monitor event
 )
 
 // Event types
@@ -15,6 +16,7 @@ const (
 	MDETypeArtifact = "a" //Artifact event type
 	MDETypeProcess  = "p" //Process event type
 	MDETypeState    = "s" //State event
+	MDETypeNetwork  = "n" //Network activity event
 )
 
 // Operation types
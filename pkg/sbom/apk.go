@@ -0,0 +1,68 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+)
+
+// parseApkInstalled parses the apk database ('installed' file).
+// The package records are separated with empty lines
+// and each field line uses the "K:VALUE" format.
+func parseApkInstalled(location string, data []byte) []*Package {
+	var result []*Package
+	var current *Package
+	var currentDir string
+	flush := func() {
+		if current != nil && current.Name != "" && current.Version != "" {
+			result = append(result, current)
+		}
+
+		current = nil
+		currentDir = ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+
+		if current == nil {
+			current = &Package{
+				Type:     TypeApk,
+				Location: location,
+			}
+		}
+
+		value := line[2:]
+		switch line[0] {
+		case 'P':
+			current.Name = value
+		case 'V':
+			current.Version = value
+		case 'A':
+			current.Arch = value
+		case 'L':
+			current.License = value
+		case 'm':
+			current.Supplier = value
+		case 'o':
+			current.Source = value
+		case 'F':
+			currentDir = "/" + value
+		case 'R':
+			current.Files = append(current.Files, path.Join("/", currentDir, value))
+		}
+	}
+
+	flush()
+	return result
+}
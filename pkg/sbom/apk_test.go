@@ -0,0 +1,60 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const apkInstalledData = `C:Q1abc=
+P:musl
+V:1.2.4-r2
+A:x86_64
+S:383152
+L:MIT
+o:musl
+m:Timo Teräs <timo.teras@iki.fi>
+F:lib
+R:ld-musl-x86_64.so.1
+R:libc.musl-x86_64.so.1
+
+P:busybox
+V:1.36.1-r5
+A:x86_64
+L:GPL-2.0-only
+F:bin
+R:busybox
+F:etc/busybox-paths.d
+R:busybox
+
+P:no-version
+A:x86_64
+`
+
+func TestParseApkInstalled(t *testing.T) {
+	expected := []*Package{
+		{
+			Type:     TypeApk,
+			Name:     "musl",
+			Version:  "1.2.4-r2",
+			Arch:     "x86_64",
+			License:  "MIT",
+			Supplier: "Timo Teräs <timo.teras@iki.fi>",
+			Source:   "musl",
+			Location: apkInstalledPath,
+			Files:    []string{"/lib/ld-musl-x86_64.so.1", "/lib/libc.musl-x86_64.so.1"},
+		},
+		{
+			Type:     TypeApk,
+			Name:     "busybox",
+			Version:  "1.36.1-r5",
+			Arch:     "x86_64",
+			License:  "GPL-2.0-only",
+			Location: apkInstalledPath,
+			Files:    []string{"/bin/busybox", "/etc/busybox-paths.d/busybox"},
+		},
+	}
+
+	assert.Equal(t, expected, parseApkInstalled(apkInstalledPath, []byte(apkInstalledData)))
+	assert.Empty(t, parseApkInstalled(apkInstalledPath, []byte("P:musl\nbroken line\n")))
+}
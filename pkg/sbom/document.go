@@ -0,0 +1,377 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/slimtoolkit/slim/pkg/consts"
+	v "github.com/slimtoolkit/slim/pkg/version"
+)
+
+// DocumentInfo describes the image for the SBOM document
+type DocumentInfo struct {
+	ImageName string
+	ImageID   string
+}
+
+// Document creates the SBOM document in the selected format
+func (ref *Report) Document(format string, info DocumentInfo) ([]byte, error) {
+	var doc interface{}
+	created := time.Now().UTC()
+	switch format {
+	case FormatSPDXJSON:
+		doc = ref.spdxDocument(info, created)
+	case FormatCycloneDXJSON:
+		doc = ref.cycloneDXDocument(info, created)
+	default:
+		return nil, fmt.Errorf("sbom: unknown format - %s", format)
+	}
+
+	//not escaping the '&' characters in the package URLs
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SaveDocument creates the SBOM document in the selected format and saves it to the file
+func (ref *Report) SaveDocument(format string, filePath string, info DocumentInfo) error {
+	data, err := ref.Document(format, info)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// SaveDocuments saves the SBOM documents in the selected formats to the output directory
+// (returns the saved file names)
+func (ref *Report) SaveDocuments(outputDir string, formats []string, minified bool, info DocumentInfo) ([]string, error) {
+	var result []string
+	for _, format := range formats {
+		fileName := FileName(format, minified)
+		if err := ref.SaveDocument(format, filepath.Join(outputDir, fileName), info); err != nil {
+			return result, err
+		}
+
+		result = append(result, fileName)
+	}
+
+	return result, nil
+}
+
+// SPDX 2.3 (JSON)
+// See https://spdx.github.io/spdx-spec/v2.3/
+
+const (
+	spdxVersion      = "SPDX-2.3"
+	spdxDataLicense  = "CC0-1.0"
+	spdxDocumentID   = "SPDXRef-DOCUMENT"
+	spdxImageID      = "SPDXRef-Image"
+	spdxNoAssertion  = "NOASSERTION"
+	spdxNamespaceURL = "https://slimtoolkit.org/spdxdocs"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	SourceInfo            string            `json:"sourceInfo,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// simple license expressions (the other license values are not valid SPDX expressions)
+var spdxLicenseExpression = regexp.MustCompile(`^\(?[A-Za-z0-9.+-]+(\s+(AND|OR|WITH)\s+\(?[A-Za-z0-9.+-]+\)?)*\)?$`)
+
+func (ref *Report) spdxDocument(info DocumentInfo, created time.Time) *spdxDocument {
+	docName := info.ImageName
+	if docName == "" {
+		docName = info.ImageID
+	}
+
+	doc := &spdxDocument{
+		SPDXVersion: spdxVersion,
+		DataLicense: spdxDataLicense,
+		SPDXID:      spdxDocumentID,
+		Name:        docName,
+		DocumentNamespace: fmt.Sprintf("%s/%s-%s",
+			spdxNamespaceURL,
+			spdxIDInvalidChars.ReplaceAllString(docName, "-"),
+			uuid.New().String()),
+		CreationInfo: spdxCreationInfo{
+			Created:  created.Format(time.RFC3339),
+			Creators: []string{fmt.Sprintf("Tool: %s-%s", consts.AppName, v.Tag())},
+		},
+		Packages: []spdxPackage{},
+		Relationships: []spdxRelationship{
+			{
+				SPDXElementID:      spdxDocumentID,
+				RelationshipType:   "DESCRIBES",
+				RelatedSPDXElement: spdxImageID,
+			},
+		},
+	}
+
+	doc.Packages = append(doc.Packages, spdxPackage{
+		SPDXID:                spdxImageID,
+		Name:                  docName,
+		VersionInfo:           info.ImageID,
+		Supplier:              spdxNoAssertion,
+		DownloadLocation:      spdxNoAssertion,
+		LicenseConcluded:      spdxNoAssertion,
+		LicenseDeclared:       spdxNoAssertion,
+		CopyrightText:         spdxNoAssertion,
+		PrimaryPackagePurpose: "CONTAINER",
+	})
+
+	for idx, pkg := range ref.Packages {
+		spdxID := fmt.Sprintf("SPDXRef-Package-%s-%s-%d",
+			pkg.Type,
+			spdxIDInvalidChars.ReplaceAllString(pkg.Name, "-"),
+			idx+1)
+
+		spkg := spdxPackage{
+			SPDXID:           spdxID,
+			Name:             pkg.Name,
+			VersionInfo:      pkg.Version,
+			Supplier:         spdxSupplier(pkg.Supplier),
+			DownloadLocation: spdxNoAssertion,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			SourceInfo:       fmt.Sprintf("package found in: %s", pkg.Location),
+			ExternalRefs: []spdxExternalRef{
+				{
+					ReferenceCategory: "PACKAGE-MANAGER",
+					ReferenceType:     "purl",
+					ReferenceLocator:  pkg.PURL,
+				},
+			},
+		}
+
+		if pkg.License != "" && spdxLicenseExpression.MatchString(pkg.License) {
+			spkg.LicenseDeclared = pkg.License
+		}
+
+		doc.Packages = append(doc.Packages, spkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxImageID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: spdxID,
+		})
+	}
+
+	return doc
+}
+
+// spdxSupplier returns the SPDX supplier value ("Organization: NAME (EMAIL)")
+func spdxSupplier(supplier string) string {
+	supplier = strings.TrimSpace(supplier)
+	if supplier == "" {
+		return spdxNoAssertion
+	}
+
+	supplier = strings.NewReplacer("<", "(", ">", ")").Replace(supplier)
+	return "Organization: " + supplier
+}
+
+// CycloneDX 1.5 (JSON)
+// See https://cyclonedx.org/docs/1.5/json/
+
+const (
+	cdxFormat      = "CycloneDX"
+	cdxSpecVersion = "1.5"
+	cdxImageRef    = "image"
+	cdxDistroRef   = "os"
+
+	cdxPropertyLocation = "slim:package:location"
+	cdxPropertyType     = "slim:package:type"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef     string           `json:"bom-ref,omitempty"`
+	Type       string           `json:"type"`
+	Name       string           `json:"name"`
+	Version    string           `json:"version,omitempty"`
+	Supplier   *cdxOrganization `json:"supplier,omitempty"`
+	Licenses   []cdxLicense     `json:"licenses,omitempty"`
+	PURL       string           `json:"purl,omitempty"`
+	Properties []cdxProperty    `json:"properties,omitempty"`
+}
+
+type cdxOrganization struct {
+	Name string `json:"name"`
+}
+
+type cdxLicense struct {
+	License    *cdxLicenseName `json:"license,omitempty"`
+	Expression string          `json:"expression,omitempty"`
+}
+
+type cdxLicenseName struct {
+	Name string `json:"name"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func (ref *Report) cycloneDXDocument(info DocumentInfo, created time.Time) *cdxDocument {
+	imageName := info.ImageName
+	if imageName == "" {
+		imageName = info.ImageID
+	}
+
+	doc := &cdxDocument{
+		BOMFormat:    cdxFormat,
+		SpecVersion:  cdxSpecVersion,
+		SerialNumber: "urn:uuid:" + uuid.New().String(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: created.Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{
+					{
+						Type:    "application",
+						Name:    consts.AppName,
+						Version: v.Tag(),
+					},
+				},
+			},
+			Component: cdxComponent{
+				BOMRef:  cdxImageRef,
+				Type:    "container",
+				Name:    imageName,
+				Version: info.ImageID,
+			},
+		},
+		Components: []cdxComponent{},
+	}
+
+	imageDeps := cdxDependency{Ref: cdxImageRef}
+	if ref.Distro != nil {
+		doc.Components = append(doc.Components, cdxComponent{
+			BOMRef:  cdxDistroRef,
+			Type:    "operating-system",
+			Name:    ref.Distro.ID,
+			Version: ref.Distro.VersionID,
+		})
+
+		imageDeps.DependsOn = append(imageDeps.DependsOn, cdxDistroRef)
+	}
+
+	seenRefs := map[string]struct{}{}
+	for idx, pkg := range ref.Packages {
+		bomRef := pkg.PURL
+		if _, found := seenRefs[bomRef]; found {
+			bomRef = fmt.Sprintf("%s#%d", pkg.PURL, idx+1)
+		}
+		seenRefs[bomRef] = struct{}{}
+
+		component := cdxComponent{
+			BOMRef:  bomRef,
+			Type:    "library",
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			PURL:    pkg.PURL,
+			Properties: []cdxProperty{
+				{Name: cdxPropertyType, Value: pkg.Type},
+				{Name: cdxPropertyLocation, Value: pkg.Location},
+			},
+		}
+
+		if pkg.Supplier != "" {
+			component.Supplier = &cdxOrganization{Name: pkg.Supplier}
+		}
+
+		if pkg.License != "" {
+			if spdxLicenseExpression.MatchString(pkg.License) {
+				component.Licenses = []cdxLicense{{Expression: pkg.License}}
+			} else {
+				component.Licenses = []cdxLicense{{License: &cdxLicenseName{Name: pkg.License}}}
+			}
+		}
+
+		doc.Components = append(doc.Components, component)
+		imageDeps.DependsOn = append(imageDeps.DependsOn, bomRef)
+	}
+
+	doc.Dependencies = []cdxDependency{imageDeps}
+	return doc
+}
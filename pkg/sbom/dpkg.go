@@ -0,0 +1,161 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// dpkgFileLists returns the package file lists from the dpkg info directory
+// ('.list' files) and the package md5sums files (used by the distroless images).
+// The keys are the package names with and without the architecture qualifier.
+func dpkgFileLists(files map[string][]byte) map[string][]string {
+	result := map[string][]string{}
+	for fpath, data := range files {
+		dir, name := filepath.Split(fpath)
+		dir = strings.TrimSuffix(dir, "/")
+		if dir != dpkgInfoDirPath && dir != dpkgStatusDirPath {
+			continue
+		}
+
+		var pkgName string
+		var pkgFiles []string
+		switch {
+		case strings.HasSuffix(name, ".list"):
+			pkgName = strings.TrimSuffix(name, ".list")
+			pkgFiles = parseDpkgList(data)
+		case strings.HasSuffix(name, ".md5sums"):
+			pkgName = strings.TrimSuffix(name, ".md5sums")
+			pkgFiles = parseDpkgMD5Sums(data)
+		default:
+			continue
+		}
+
+		keys := []string{pkgName}
+		if base, _, found := strings.Cut(pkgName, ":"); found {
+			keys = append(keys, base)
+		}
+
+		for _, key := range keys {
+			//the '.list' files have the directories too, so they are preferred
+			if _, found := result[key]; found && strings.HasSuffix(name, ".md5sums") {
+				continue
+			}
+
+			result[key] = pkgFiles
+		}
+	}
+
+	return result
+}
+
+func parseDpkgList(data []byte) []string {
+	var files []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && line != "/." {
+			files = append(files, line)
+		}
+	}
+
+	return files
+}
+
+// parseDpkgMD5Sums parses the "MD5SUM  RELATIVE_PATH" lines
+func parseDpkgMD5Sums(data []byte) []string {
+	var files []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		fpath := strings.Join(fields[1:], " ")
+		if !strings.HasPrefix(fpath, "/") {
+			fpath = "/" + fpath
+		}
+
+		files = append(files, fpath)
+	}
+
+	return files
+}
+
+// parseDpkgStatus parses the dpkg status file (or the distroless status.d package files)
+func parseDpkgStatus(location string, data []byte, fileLists map[string][]string) []*Package {
+	var result []*Package
+	for _, stanza := range parseControlStanzas(data) {
+		name := stanza["Package"]
+		version := stanza["Version"]
+		if name == "" || version == "" {
+			continue
+		}
+
+		//the distroless package files don't have the status field
+		if status, found := stanza["Status"]; found && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+
+		pkg := &Package{
+			Type:     TypeDeb,
+			Name:     name,
+			Version:  version,
+			Arch:     stanza["Architecture"],
+			Supplier: stanza["Maintainer"],
+			Location: location,
+		}
+
+		if source := stanza["Source"]; source != "" {
+			//the source field might include the source version: "NAME (VERSION)"
+			pkg.Source = strings.TrimSpace(strings.Split(source, "(")[0])
+		}
+
+		if files, found := fileLists[name+":"+pkg.Arch]; found {
+			pkg.Files = files
+		} else {
+			pkg.Files = fileLists[name]
+		}
+
+		result = append(result, pkg)
+	}
+
+	return result
+}
+
+// parseControlStanzas parses the Debian control file format
+// (the continuation lines are ignored because the package fields
+// used for the SBOM are all single line fields)
+func parseControlStanzas(data []byte) []map[string]string {
+	var result []map[string]string
+	current := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				result = append(result, current)
+				current = map[string]string{}
+			}
+
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		if key, value, found := strings.Cut(line, ":"); found {
+			current[key] = strings.TrimSpace(value)
+		}
+	}
+
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+
+	return result
+}
@@ -0,0 +1,97 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dpkgStatusData = `Package: libc6
+Status: install ok installed
+Priority: optional
+Architecture: amd64
+Multi-Arch: same
+Source: glibc (2.36-9)
+Version: 2.36-9+deb12u4
+Maintainer: GNU Libc Maintainers <debian-glibc@lists.debian.org>
+Description: GNU C Library: Shared libraries
+ Contains the standard libraries that are used by nearly all programs on
+ the system.
+ Version: not-a-field (continuation line)
+
+Package: removed-pkg
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 1.0
+
+Package: base-files
+Status: install ok installed
+Architecture: amd64
+Version: 12.4+deb12u5
+
+Package: no-version
+Status: install ok installed
+`
+
+func TestParseDpkgStatus(t *testing.T) {
+	fileLists := map[string][]string{
+		"libc6:amd64": {"/lib/x86_64-linux-gnu/libc.so.6"},
+		"libc6":       {"/wrong"},
+		"base-files":  {"/etc/debian_version"},
+	}
+
+	expected := []*Package{
+		{
+			Type:     TypeDeb,
+			Name:     "libc6",
+			Version:  "2.36-9+deb12u4",
+			Arch:     "amd64",
+			Supplier: "GNU Libc Maintainers <debian-glibc@lists.debian.org>",
+			Source:   "glibc",
+			Location: dpkgStatusPath,
+			Files:    []string{"/lib/x86_64-linux-gnu/libc.so.6"},
+		},
+		{
+			Type:     TypeDeb,
+			Name:     "base-files",
+			Version:  "12.4+deb12u5",
+			Arch:     "amd64",
+			Location: dpkgStatusPath,
+			Files:    []string{"/etc/debian_version"},
+		},
+	}
+
+	assert.Equal(t, expected, parseDpkgStatus(dpkgStatusPath, []byte(dpkgStatusData), fileLists))
+
+	//the distroless package files don't have the status field
+	distroless := "Package: tzdata\nVersion: 2024a-0+deb12u1\nArchitecture: all\n"
+	pkgs := parseDpkgStatus(dpkgStatusDirPath+"/tzdata", []byte(distroless), nil)
+	if assert.Len(t, pkgs, 1) {
+		assert.Equal(t, "tzdata", pkgs[0].Name)
+		assert.Equal(t, "all", pkgs[0].Arch)
+	}
+
+	assert.Empty(t, parseDpkgStatus(dpkgStatusPath, nil, nil))
+}
+
+func TestDpkgFileLists(t *testing.T) {
+	files := map[string][]byte{
+		dpkgInfoDirPath + "/libc6:amd64.list":        []byte("/.\n/lib\n/lib/x86_64-linux-gnu/libc.so.6\n\n"),
+		dpkgInfoDirPath + "/libc6:amd64.md5sums":     []byte("0123  lib/x86_64-linux-gnu/libc.so.6\n"),
+		dpkgInfoDirPath + "/libc6:amd64.conffiles":   []byte("/etc/ld.so.conf\n"),
+		dpkgStatusDirPath + "/tzdata.md5sums":        []byte("abcd  usr/share/zoneinfo/UTC\nabcd  usr/share/doc/my file\nbroken\n"),
+		"/usr/share/doc/libc6/changelog.list":        []byte("/not/a/file/list\n"),
+		dpkgInfoDirPath + "/base-files.md5sums":      []byte("abcd  etc/debian_version\n"),
+		dpkgInfoDirPath + "/base-files.preinst.list": nil,
+	}
+
+	expected := map[string][]string{
+		"libc6:amd64":        {"/lib", "/lib/x86_64-linux-gnu/libc.so.6"},
+		"libc6":              {"/lib", "/lib/x86_64-linux-gnu/libc.so.6"},
+		"tzdata":             {"/usr/share/zoneinfo/UTC", "/usr/share/doc/my file"},
+		"base-files":         {"/etc/debian_version"},
+		"base-files.preinst": nil,
+	}
+
+	assert.Equal(t, expected, dpkgFileLists(files))
+}
@@ -0,0 +1,191 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/mail"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type npmManifest struct {
+	Name    string          `json:"name"`
+	Version string          `json:"version"`
+	License json.RawMessage `json:"license"`
+	Author  json.RawMessage `json:"author"`
+}
+
+// parseNpmManifest parses the package.json file of an installed npm package
+func parseNpmManifest(location string, data []byte) []*Package {
+	var manifest npmManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+
+	if manifest.Name == "" || manifest.Version == "" {
+		return nil
+	}
+
+	pkg := &Package{
+		Type:     TypeNpm,
+		Name:     manifest.Name,
+		Version:  manifest.Version,
+		License:  jsonNameValue(manifest.License, "type"),
+		Supplier: jsonNameValue(manifest.Author, "name"),
+		Location: location,
+		Dir:      path.Dir(location),
+	}
+
+	return []*Package{pkg}
+}
+
+// jsonNameValue returns the string value or the named field value from the object value
+// (e.g., "author": "NAME <EMAIL>" or "author": {"name": "NAME"})
+func jsonNameValue(raw json.RawMessage, field string) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		if value, ok := obj[field].(string); ok {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// parsePythonMetadata parses the installed python package metadata
+// (the METADATA file in the .dist-info directory or the PKG-INFO file in the .egg-info directory).
+// The package files come from the RECORD file (if it's available).
+func parsePythonMetadata(location string, data []byte, record []byte) []*Package {
+	msg, err := mail.ReadMessage(bufio.NewReader(bytes.NewReader(pythonMetadataHeaders(data))))
+	if err != nil {
+		return nil
+	}
+
+	name := msg.Header.Get("Name")
+	version := msg.Header.Get("Version")
+	if name == "" || version == "" {
+		return nil
+	}
+
+	pkg := &Package{
+		Type:     TypePyPI,
+		Name:     name,
+		Version:  version,
+		License:  msg.Header.Get("License-Expression"),
+		Supplier: msg.Header.Get("Author"),
+		Location: location,
+	}
+
+	if pkg.License == "" {
+		//the legacy License field can have the full license text
+		if license := msg.Header.Get("License"); len(license) < 64 {
+			pkg.License = license
+		}
+	}
+
+	if pkg.Supplier == "" {
+		pkg.Supplier = msg.Header.Get("Author-email")
+	}
+
+	infoDir := path.Dir(location)
+	sitePackagesDir := path.Dir(infoDir)
+	for _, fpath := range parsePythonRecord(record) {
+		if !strings.HasPrefix(fpath, "/") {
+			fpath = path.Join(sitePackagesDir, fpath)
+		}
+
+		pkg.Files = append(pkg.Files, fpath)
+	}
+
+	if len(pkg.Files) == 0 {
+		//no RECORD file (e.g., egg-info packages): using the metadata directory
+		pkg.Dir = infoDir
+	}
+
+	return []*Package{pkg}
+}
+
+// pythonMetadataHeaders returns the metadata header section
+// (the package description can follow the headers after an empty line)
+func pythonMetadataHeaders(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if idx := bytes.Index(data, []byte("\n\n")); idx >= 0 {
+		data = data[:idx+1]
+	}
+
+	return append(data, '\n')
+}
+
+// parsePythonRecord returns the file paths from the RECORD file ("PATH,HASH,SIZE" CSV lines)
+func parsePythonRecord(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var files []string
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return files
+		}
+
+		if len(fields) > 0 && fields[0] != "" {
+			files = append(files, path.Clean(fields[0]))
+		}
+	}
+
+	return files
+}
+
+// parseGemSpecName extracts the gem name and version from the installed gem specification file name
+// ('specifications/NAME-VERSION.gemspec'). The gem files are in the 'gems/NAME-VERSION' directory.
+func parseGemSpecName(location string) []*Package {
+	specName := strings.TrimSuffix(filepath.Base(location), gemSpecExtension)
+	//the version is the first name part that starts with a digit
+	//(the platform gems have the platform suffix: NAME-VERSION-PLATFORM)
+	parts := strings.Split(specName, "-")
+	vidx := -1
+	for idx := 1; idx < len(parts); idx++ {
+		if parts[idx] != "" && parts[idx][0] >= '0' && parts[idx][0] <= '9' {
+			vidx = idx
+			break
+		}
+	}
+
+	if vidx < 0 {
+		return nil
+	}
+
+	name := strings.Join(parts[:vidx], "-")
+	version := parts[vidx]
+
+	gemsRoot := path.Dir(path.Dir(location))
+	pkg := &Package{
+		Type:     TypeGem,
+		Name:     name,
+		Version:  version,
+		Location: location,
+		Dir:      path.Join(gemsRoot, "gems", specName),
+	}
+
+	return []*Package{pkg}
+}
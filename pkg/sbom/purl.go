@@ -0,0 +1,103 @@
+package sbom
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// packageURL returns the package URL (purl) for the package
+// See https://github.com/package-url/purl-spec
+func packageURL(pkg *Package, distro *Distro) string {
+	namespace := ""
+	name := pkg.Name
+	version := pkg.Version
+	qualifiers := map[string]string{}
+
+	distroQualifier := func() {
+		if distro != nil {
+			namespace = distro.ID
+			if distro.VersionID != "" {
+				qualifiers["distro"] = distro.ID + "-" + distro.VersionID
+			} else {
+				qualifiers["distro"] = distro.ID
+			}
+		}
+	}
+
+	switch pkg.Type {
+	case TypeDeb:
+		namespace = "debian"
+		distroQualifier()
+		if pkg.Arch != "" {
+			qualifiers["arch"] = pkg.Arch
+		}
+	case TypeApk:
+		namespace = "alpine"
+		distroQualifier()
+		if pkg.Arch != "" {
+			qualifiers["arch"] = pkg.Arch
+		}
+	case TypeRpm:
+		distroQualifier()
+		if epoch, rest, found := strings.Cut(version, ":"); found {
+			qualifiers["epoch"] = epoch
+			version = rest
+		}
+
+		if pkg.Arch != "" {
+			qualifiers["arch"] = pkg.Arch
+		}
+	case TypeNpm:
+		if strings.HasPrefix(name, "@") {
+			if scope, rest, found := strings.Cut(name, "/"); found {
+				namespace = scope
+				name = rest
+			}
+		}
+	case TypePyPI:
+		//normalized python package names
+		name = strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+	}
+
+	var b strings.Builder
+	b.WriteString("pkg:")
+	b.WriteString(pkg.Type)
+	b.WriteString("/")
+	if namespace != "" {
+		b.WriteString(purlEscape(namespace))
+		b.WriteString("/")
+	}
+
+	b.WriteString(purlEscape(name))
+	if version != "" {
+		b.WriteString("@")
+		b.WriteString(purlEscape(version))
+	}
+
+	if len(qualifiers) > 0 {
+		var keys []string
+		for key := range qualifiers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for idx, key := range keys {
+			if idx == 0 {
+				b.WriteString("?")
+			} else {
+				b.WriteString("&")
+			}
+
+			b.WriteString(key)
+			b.WriteString("=")
+			b.WriteString(purlEscape(qualifiers[key]))
+		}
+	}
+
+	return b.String()
+}
+
+func purlEscape(value string) string {
+	return strings.ReplaceAll(url.PathEscape(value), "+", "%2B")
+}
@@ -0,0 +1,215 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path"
+)
+
+const (
+	rpmPackagesTable = "Packages"
+	rpmGPGKeyName    = "gpg-pubkey"
+)
+
+// RPM header tags
+const (
+	rpmTagName       = 1000
+	rpmTagVersion    = 1001
+	rpmTagRelease    = 1002
+	rpmTagEpoch      = 1003
+	rpmTagVendor     = 1011
+	rpmTagLicense    = 1014
+	rpmTagArch       = 1022
+	rpmTagSourceRPM  = 1044
+	rpmTagDirIndexes = 1116
+	rpmTagBaseNames  = 1117
+	rpmTagDirNames   = 1118
+)
+
+// RPM header tag types
+const (
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeStringArray = 8
+	rpmTypeI18NString  = 9
+)
+
+const (
+	rpmIndexEntrySize = 16
+	rpmMaxIndexCount  = 0x10000
+	rpmMaxDataSize    = 256 * 1024 * 1024
+)
+
+// parseRpmSqlite reads the package headers from the rpm sqlite database
+func parseRpmSqlite(location string, data []byte) ([]*Package, error) {
+	db, err := newSqliteDB(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rootPage, err := db.tableRootPage(rpmPackagesTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Package
+	err = db.scanTable(rootPage, func(rowID int64, values []interface{}) error {
+		if len(values) < 2 {
+			return nil
+		}
+
+		blob, ok := values[1].([]byte)
+		if !ok {
+			return nil
+		}
+
+		pkg, err := parseRpmHeader(blob)
+		if err != nil {
+			return fmt.Errorf("rpm: package header (%d) - %w", rowID, err)
+		}
+
+		if pkg.Name == "" || pkg.Name == rpmGPGKeyName {
+			return nil
+		}
+
+		pkg.Location = location
+		result = append(result, pkg)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type rpmIndexEntry struct {
+	tag    int32
+	typ    uint32
+	offset int32
+	count  uint32
+}
+
+// parseRpmHeader parses the rpm header blob (the header without the lead and the header magic)
+func parseRpmHeader(blob []byte) (*Package, error) {
+	if len(blob) < 8 {
+		return nil, fmt.Errorf("header is too short")
+	}
+
+	indexCount := binary.BigEndian.Uint32(blob[0:4])
+	dataSize := binary.BigEndian.Uint32(blob[4:8])
+	if indexCount > rpmMaxIndexCount || dataSize > rpmMaxDataSize {
+		return nil, fmt.Errorf("header is too big")
+	}
+
+	dataStart := 8 + int(indexCount)*rpmIndexEntrySize
+	if dataStart+int(dataSize) > len(blob) {
+		return nil, fmt.Errorf("header is truncated")
+	}
+
+	store := blob[dataStart : dataStart+int(dataSize)]
+	entries := map[int32]rpmIndexEntry{}
+	for i := 0; i < int(indexCount); i++ {
+		raw := blob[8+i*rpmIndexEntrySize:]
+		e := rpmIndexEntry{
+			tag:    int32(binary.BigEndian.Uint32(raw[0:4])),
+			typ:    binary.BigEndian.Uint32(raw[4:8]),
+			offset: int32(binary.BigEndian.Uint32(raw[8:12])),
+			count:  binary.BigEndian.Uint32(raw[12:16]),
+		}
+
+		if e.offset < 0 || int(e.offset) > len(store) {
+			continue
+		}
+
+		entries[e.tag] = e
+	}
+
+	str := func(tag int32) string {
+		if values := rpmStrings(store, entries, tag); len(values) > 0 {
+			return values[0]
+		}
+
+		return ""
+	}
+
+	pkg := &Package{
+		Type:     TypeRpm,
+		Name:     str(rpmTagName),
+		Arch:     str(rpmTagArch),
+		License:  str(rpmTagLicense),
+		Supplier: str(rpmTagVendor),
+		Source:   str(rpmTagSourceRPM),
+	}
+
+	pkg.Version = str(rpmTagVersion)
+	if release := str(rpmTagRelease); release != "" {
+		pkg.Version = fmt.Sprintf("%s-%s", pkg.Version, release)
+	}
+
+	if epoch := rpmInts(store, entries, rpmTagEpoch); len(epoch) > 0 && epoch[0] > 0 {
+		pkg.Version = fmt.Sprintf("%d:%s", epoch[0], pkg.Version)
+	}
+
+	dirNames := rpmStrings(store, entries, rpmTagDirNames)
+	dirIndexes := rpmInts(store, entries, rpmTagDirIndexes)
+	for i, baseName := range rpmStrings(store, entries, rpmTagBaseNames) {
+		if i >= len(dirIndexes) || int(dirIndexes[i]) >= len(dirNames) || dirIndexes[i] < 0 {
+			break
+		}
+
+		pkg.Files = append(pkg.Files, path.Join(dirNames[dirIndexes[i]], baseName))
+	}
+
+	return pkg, nil
+}
+
+func rpmStrings(store []byte, entries map[int32]rpmIndexEntry, tag int32) []string {
+	e, found := entries[tag]
+	if !found {
+		return nil
+	}
+
+	switch e.typ {
+	case rpmTypeString, rpmTypeStringArray, rpmTypeI18NString:
+	default:
+		return nil
+	}
+
+	count := int(e.count)
+	if e.typ == rpmTypeString {
+		count = 1
+	}
+
+	var result []string
+	data := store[e.offset:]
+	for i := 0; i < count; i++ {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			break
+		}
+
+		result = append(result, string(data[:end]))
+		data = data[end+1:]
+	}
+
+	return result
+}
+
+func rpmInts(store []byte, entries map[int32]rpmIndexEntry, tag int32) []int32 {
+	e, found := entries[tag]
+	if !found || e.typ != rpmTypeInt32 {
+		return nil
+	}
+
+	var result []int32
+	data := store[e.offset:]
+	for i := 0; i < int(e.count) && len(data) >= 4; i++ {
+		result = append(result, int32(binary.BigEndian.Uint32(data)))
+		data = data[4:]
+	}
+
+	return result
+}
@@ -0,0 +1,167 @@
+package sbom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRpmSqlite(t *testing.T) {
+	var filesystemFiles []string
+	for i := 0; i < 120; i++ {
+		filesystemFiles = append(filesystemFiles, fmt.Sprintf("/usr/share/locale/l%03d/LC_MESSAGES", i))
+	}
+
+	tt := []struct {
+		fixture  string
+		count    int
+		expected []*Package
+	}{
+		{
+			fixture: "single.sqlite",
+			count:   2,
+			expected: []*Package{
+				{
+					Type:     TypeRpm,
+					Name:     "bash",
+					Version:  "5.1.8-6.el9",
+					Arch:     "x86_64",
+					License:  "GPLv3+",
+					Supplier: "Rocky",
+					Source:   "bash-5.1.8-6.el9.src.rpm",
+					Location: rpmSqlitePath,
+					Files:    []string{"/usr/bin/bash", "/usr/bin/sh", "/etc/skel/.bashrc"},
+				},
+				{
+					Type:     TypeRpm,
+					Name:     "openssl-libs",
+					Version:  "1:3.0.7-24.el9",
+					Arch:     "x86_64",
+					License:  "ASL 2.0",
+					Supplier: "Rocky",
+					Source:   "openssl-3.0.7-24.el9.src.rpm",
+					Location: rpmSqlitePath,
+					Files:    []string{"/usr/lib64/libssl.so.3"},
+				},
+			},
+		},
+		{
+			//the first package header is stored in the overflow pages
+			fixture: "multipage.sqlite",
+			count:   41,
+			expected: []*Package{
+				{
+					Type:     TypeRpm,
+					Name:     "filesystem",
+					Version:  "3.16-2.el9",
+					Arch:     "x86_64",
+					License:  "MIT",
+					Supplier: "Rocky",
+					Source:   "filesystem-3.16-2.el9.src.rpm",
+					Location: rpmSqlitePath,
+					Files:    filesystemFiles,
+				},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		pkgs, err := parseRpmSqlite(rpmSqlitePath, loadRpmdbFixture(t, test.fixture))
+		require.NoError(t, err, test.fixture)
+		require.Len(t, pkgs, test.count, test.fixture)
+		assert.Equal(t, test.expected, pkgs[:len(test.expected)], test.fixture)
+	}
+}
+
+func TestParseRpmSqliteErrors(t *testing.T) {
+	fixture := loadRpmdbFixture(t, "multipage.sqlite")
+	db, err := newSqliteDB(fixture)
+	require.NoError(t, err)
+	rootPage, err := db.tableRootPage(rpmPackagesTable)
+	require.NoError(t, err)
+	rootOffset := (rootPage - 1) * db.pageSize
+
+	tt := []struct {
+		name   string
+		modify func(data []byte) []byte
+	}{
+		{
+			name:   "not a database",
+			modify: func(data []byte) []byte { return []byte("not a database") },
+		},
+		{
+			name:   "truncated",
+			modify: func(data []byte) []byte { return data[:db.pageSize*rootPage-1] },
+		},
+		{
+			name: "page cycle",
+			modify: func(data []byte) []byte {
+				binary.BigEndian.PutUint32(data[rootOffset+8:], uint32(rootPage))
+				return data
+			},
+		},
+		{
+			name: "child page past the end",
+			modify: func(data []byte) []byte {
+				binary.BigEndian.PutUint32(data[rootOffset+8:], 0xffff)
+				return data
+			},
+		},
+		{
+			name: "unexpected page type",
+			modify: func(data []byte) []byte {
+				data[rootOffset] = 0x0a
+				return data
+			},
+		},
+	}
+
+	for _, test := range tt {
+		data := test.modify(append([]byte{}, fixture...))
+		_, err := parseRpmSqlite(rpmSqlitePath, data)
+		assert.Error(t, err, test.name)
+	}
+}
+
+func rpmHeaderData(indexCount, dataSize uint32, rest ...byte) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint32(data[0:4], indexCount)
+	binary.BigEndian.PutUint32(data[4:8], dataSize)
+	return append(data, rest...)
+}
+
+func TestParseRpmHeader(t *testing.T) {
+	nameEntry := []byte{0, 0, 0x03, 0xe8, 0, 0, 0, rpmTypeString, 0, 0, 0, 0, 0, 0, 0, 1}
+	badOffsetEntry := []byte{0, 0, 0x03, 0xe8, 0, 0, 0, rpmTypeString, 0, 0, 0x10, 0, 0, 0, 0, 1}
+	wrongTypeEntry := []byte{0, 0, 0x03, 0xe8, 0, 0, 0, rpmTypeInt32, 0, 0, 0, 0, 0, 0, 0, 1}
+
+	tt := []struct {
+		name     string
+		blob     []byte
+		expected string
+		isErr    bool
+	}{
+		{name: "name", blob: rpmHeaderData(1, 4, append(nameEntry, 'z', 'l', 'b', 0)...), expected: "zlb"},
+		{name: "unterminated name", blob: rpmHeaderData(1, 3, append(nameEntry, 'z', 'l', 'b')...)},
+		{name: "bad offset", blob: rpmHeaderData(1, 4, append(badOffsetEntry, 'z', 'l', 'b', 0)...)},
+		{name: "wrong type", blob: rpmHeaderData(1, 4, append(wrongTypeEntry, 'z', 'l', 'b', 0)...)},
+		{name: "short", blob: []byte{0, 0, 0, 1}, isErr: true},
+		{name: "too big", blob: rpmHeaderData(rpmMaxIndexCount+1, 0), isErr: true},
+		{name: "truncated", blob: rpmHeaderData(1, 4, nameEntry...), isErr: true},
+	}
+
+	for _, test := range tt {
+		pkg, err := parseRpmHeader(test.blob)
+		if test.isErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+		assert.Equal(t, TypeRpm, pkg.Type)
+		assert.Equal(t, test.expected, pkg.Name, test.name)
+	}
+}
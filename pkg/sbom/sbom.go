@@ -0,0 +1,406 @@
+// Package sbom collects the OS and language package data from the image layers
+// and creates the SBOM documents (SPDX and CycloneDX) for the images.
+package sbom
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/slimtoolkit/slim/pkg/system"
+)
+
+// Package types
+const (
+	TypeDeb  = "deb"
+	TypeApk  = "apk"
+	TypeRpm  = "rpm"
+	TypeNpm  = "npm"
+	TypePyPI = "pypi"
+	TypeGem  = "gem"
+)
+
+// SBOM formats
+const (
+	FormatSPDXJSON      = "spdx-json"
+	FormatCycloneDXJSON = "cyclonedx-json"
+)
+
+var Formats = map[string]struct{}{
+	FormatSPDXJSON:      {},
+	FormatCycloneDXJSON: {},
+}
+
+// ParseFormats validates the SBOM format names (and removes the duplicates)
+func ParseFormats(values []string) ([]string, error) {
+	var result []string
+	seen := map[string]struct{}{}
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		if _, found := Formats[value]; !found {
+			return nil, fmt.Errorf("unknown SBOM format - %s", value)
+		}
+
+		if _, found := seen[value]; !found {
+			seen[value] = struct{}{}
+			result = append(result, value)
+		}
+	}
+
+	return result, nil
+}
+
+// FileName returns the SBOM file name for the format
+// (e.g., "sbom.spdx.json" or "sbom.slim.cdx.json" for the minified images)
+func FileName(format string, minified bool) string {
+	name := "sbom"
+	if minified {
+		name = "sbom.slim"
+	}
+
+	switch format {
+	case FormatSPDXJSON:
+		return name + ".spdx.json"
+	case FormatCycloneDXJSON:
+		return name + ".cdx.json"
+	default:
+		return name + ".json"
+	}
+}
+
+// Package is an installed OS or language package
+type Package struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Arch     string `json:"arch,omitempty"`
+	License  string `json:"license,omitempty"`
+	Supplier string `json:"supplier,omitempty"`
+	Source   string `json:"source,omitempty"`
+	PURL     string `json:"purl"`
+	//Location is the package database or the package manifest path
+	Location string `json:"location"`
+	//Dir is the language package directory (all files there belong to the package)
+	Dir string `json:"dir,omitempty"`
+	//Files are the package files (from the package database)
+	Files []string `json:"-"`
+}
+
+// Distro is the OS distribution info (from the os-release file)
+type Distro struct {
+	ID        string `json:"id"`
+	VersionID string `json:"version_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Report is the list of packages found in the image
+type Report struct {
+	Distro   *Distro    `json:"distro,omitempty"`
+	Packages []*Package `json:"packages"`
+}
+
+// Minified returns the report with the packages that have at least one file
+// in the minified image (the packages without file info are excluded).
+// The symlinks in the minified image (link path -> link target) are used
+// to resolve the package file paths (e.g., with the merged /usr directories).
+func (ref *Report) Minified(imageFiles map[string]struct{}, links map[string]string) *Report {
+	result := &Report{Distro: ref.Distro}
+	for _, pkg := range ref.Packages {
+		if pkg.hasFileIn(imageFiles, links) {
+			result.Packages = append(result.Packages, pkg)
+		}
+	}
+
+	return result
+}
+
+func (ref *Package) hasFileIn(imageFiles map[string]struct{}, links map[string]string) bool {
+	for _, fpath := range ref.Files {
+		if _, found := imageFiles[fpath]; found {
+			return true
+		}
+
+		if resolved := resolveLinks(fpath, links); resolved != fpath {
+			if _, found := imageFiles[resolved]; found {
+				return true
+			}
+		}
+	}
+
+	if ref.Dir != "" {
+		prefixes := []string{ref.Dir + "/"}
+		if resolved := resolveLinks(ref.Dir, links); resolved != ref.Dir {
+			prefixes = append(prefixes, resolved+"/")
+		}
+
+		for fpath := range imageFiles {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(fpath, prefix) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+const maxLinkResolveCount = 16
+
+// resolveLinks resolves the symlinks in the parent directories of the path
+func resolveLinks(fpath string, links map[string]string) string {
+	if len(links) == 0 {
+		return fpath
+	}
+
+	for i := 0; i < maxLinkResolveCount; i++ {
+		resolved := false
+		for dir := filepath.Dir(fpath); dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+			target, found := links[dir]
+			if !found {
+				continue
+			}
+
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(dir), target)
+			}
+
+			fpath = filepath.Join(target, strings.TrimPrefix(fpath, dir))
+			resolved = true
+			break
+		}
+
+		if !resolved {
+			break
+		}
+	}
+
+	return fpath
+}
+
+// Package database and manifest locations
+const (
+	dpkgStatusPath    = "/var/lib/dpkg/status"
+	dpkgStatusDirPath = "/var/lib/dpkg/status.d"
+	dpkgInfoDirPath   = "/var/lib/dpkg/info"
+	apkInstalledPath  = "/lib/apk/db/installed"
+	rpmSqlitePath     = "/var/lib/rpm/rpmdb.sqlite"
+	rpmSqliteAltPath  = "/usr/lib/sysimage/rpm/rpmdb.sqlite"
+
+	nodeModulesDir   = "/node_modules/"
+	npmManifestName  = "package.json"
+	distInfoSuffix   = ".dist-info"
+	eggInfoSuffix    = ".egg-info"
+	pyMetadataName   = "METADATA"
+	pyPkgInfoName    = "PKG-INFO"
+	pyRecordName     = "RECORD"
+	gemSpecDir       = "/specifications/"
+	gemSpecExtension = ".gemspec"
+)
+
+// IsSourceFile returns true for the package database and package manifest files
+func IsSourceFile(fullPath string) bool {
+	switch fullPath {
+	case dpkgStatusPath, apkInstalledPath, rpmSqlitePath, rpmSqliteAltPath:
+		return true
+	}
+
+	if system.IsOSReleaseFile(fullPath) {
+		return true
+	}
+
+	dir, name := filepath.Split(fullPath)
+	dir = strings.TrimSuffix(dir, "/")
+	switch dir {
+	case dpkgStatusDirPath:
+		return true
+	case dpkgInfoDirPath:
+		return strings.HasSuffix(name, ".list") || strings.HasSuffix(name, ".md5sums")
+	}
+
+	switch {
+	case name == npmManifestName:
+		return isNpmManifest(fullPath)
+	case name == pyMetadataName || name == pyRecordName:
+		return strings.HasSuffix(dir, distInfoSuffix)
+	case name == pyPkgInfoName:
+		return strings.HasSuffix(dir, eggInfoSuffix)
+	case strings.HasSuffix(name, gemSpecExtension):
+		return strings.HasSuffix(dir+"/", gemSpecDir)
+	}
+
+	return false
+}
+
+// isNpmManifest returns true for the package.json files in the node_modules package directories
+// (node_modules/NAME/package.json or node_modules/@SCOPE/NAME/package.json)
+func isNpmManifest(fullPath string) bool {
+	idx := strings.LastIndex(fullPath, nodeModulesDir)
+	if idx < 0 {
+		return false
+	}
+
+	parts := strings.Split(fullPath[idx+len(nodeModulesDir):], "/")
+	switch len(parts) {
+	case 2:
+		return !strings.HasPrefix(parts[0], "@") && !strings.HasPrefix(parts[0], ".")
+	case 3:
+		return strings.HasPrefix(parts[0], "@")
+	}
+
+	return false
+}
+
+// DataSet has the package database and manifest file data from the image layers
+// (the layers can be processed in any order, so the data is saved for each layer)
+type DataSet struct {
+	Layers map[string]*LayerData
+}
+
+// LayerData has the package source file data and the deleted paths from one layer
+type LayerData struct {
+	Files   map[string][]byte
+	Deleted []string
+}
+
+func NewDataSet() *DataSet {
+	return &DataSet{
+		Layers: map[string]*LayerData{},
+	}
+}
+
+func (ref *DataSet) layer(layerID string) *LayerData {
+	ld, found := ref.Layers[layerID]
+	if !found {
+		ld = &LayerData{
+			Files: map[string][]byte{},
+		}
+		ref.Layers[layerID] = ld
+	}
+
+	return ld
+}
+
+// AddData saves the package source file data from the layer
+func (ref *DataSet) AddData(layerID string, filePath string, data []byte) {
+	ref.layer(layerID).Files[filePath] = data
+}
+
+// RemoveData records the deleted path (file or directory) in the layer
+func (ref *DataSet) RemoveData(layerID string, filePath string) {
+	ld := ref.layer(layerID)
+	ld.Deleted = append(ld.Deleted, filePath)
+}
+
+// Files returns the package source file data from the image filesystem
+// (applying the layer changes in the layer order)
+func (ref *DataSet) Files(layerOrder []string) map[string][]byte {
+	result := map[string][]byte{}
+	for _, layerID := range layerOrder {
+		ld, found := ref.Layers[layerID]
+		if !found {
+			continue
+		}
+
+		for _, deleted := range ld.Deleted {
+			prefix := deleted + "/"
+			for fpath := range result {
+				if fpath == deleted || strings.HasPrefix(fpath, prefix) {
+					delete(result, fpath)
+				}
+			}
+		}
+
+		for fpath, data := range ld.Files {
+			result[fpath] = data
+		}
+	}
+
+	return result
+}
+
+// NewReportFromData creates the package report from the collected package data
+func NewReportFromData(ds *DataSet, layerOrder []string) *Report {
+	result := &Report{}
+	if ds == nil {
+		return result
+	}
+
+	files := ds.Files(layerOrder)
+
+	for _, osrPath := range []string{system.OSReleaseFileNew, system.OSReleaseFile} {
+		if data, found := files[osrPath]; found {
+			if osr, err := system.NewOsRelease(data); err == nil && osr.ID != "" {
+				result.Distro = &Distro{
+					ID:        osr.ID,
+					VersionID: osr.VersionID,
+					Name:      osr.PrettyName,
+				}
+				break
+			}
+		}
+	}
+
+	var paths []string
+	for fpath := range files {
+		paths = append(paths, fpath)
+	}
+	sort.Strings(paths)
+
+	dpkgFiles := dpkgFileLists(files)
+	for _, fpath := range paths {
+		data := files[fpath]
+		var pkgs []*Package
+		var err error
+		dir, name := filepath.Split(fpath)
+		dir = strings.TrimSuffix(dir, "/")
+		switch {
+		case fpath == dpkgStatusPath:
+			pkgs = parseDpkgStatus(fpath, data, dpkgFiles)
+		case dir == dpkgStatusDirPath && !strings.Contains(name, "."):
+			pkgs = parseDpkgStatus(fpath, data, dpkgFiles)
+		case fpath == apkInstalledPath:
+			pkgs = parseApkInstalled(fpath, data)
+		case fpath == rpmSqlitePath || fpath == rpmSqliteAltPath:
+			pkgs, err = parseRpmSqlite(fpath, data)
+		case name == npmManifestName:
+			pkgs = parseNpmManifest(fpath, data)
+		case name == pyMetadataName || name == pyPkgInfoName:
+			pkgs = parsePythonMetadata(fpath, data, files[filepath.Join(dir, pyRecordName)])
+		case strings.HasSuffix(name, gemSpecExtension):
+			pkgs = parseGemSpecName(fpath)
+		}
+
+		if err != nil {
+			log.Debugf("sbom.NewReportFromData: error parsing package data (%s) - %v", fpath, err)
+			continue
+		}
+
+		result.Packages = append(result.Packages, pkgs...)
+	}
+
+	for _, pkg := range result.Packages {
+		pkg.PURL = packageURL(pkg, result.Distro)
+	}
+
+	sort.SliceStable(result.Packages, func(i, j int) bool {
+		if result.Packages[i].Type != result.Packages[j].Type {
+			return result.Packages[i].Type < result.Packages[j].Type
+		}
+
+		if result.Packages[i].Name != result.Packages[j].Name {
+			return result.Packages[i].Name < result.Packages[j].Name
+		}
+
+		return result.Packages[i].Version < result.Packages[j].Version
+	})
+
+	return result
+}
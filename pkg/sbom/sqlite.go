@@ -0,0 +1,361 @@
+package sbom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Minimal read-only SQLite reader (enough to read the table rows from the rpm sqlite database).
+// See https://www.sqlite.org/fileformat.html
+
+const (
+	sqliteHeaderMagic  = "SQLite format 3\x00"
+	sqliteHeaderSize   = 100
+	sqliteMasterPageNo = 1
+
+	sqlitePageInteriorTable = 0x05
+	sqlitePageLeafTable     = 0x0d
+
+	sqliteMaxTreeDepth = 64
+
+	//the smallest usable page size allowed by the file format
+	sqliteMinUsableSize = 480
+)
+
+var errSqliteFormat = errors.New("sqlite: malformed database")
+
+type sqliteDB struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+	pageCount  int
+}
+
+func newSqliteDB(data []byte) (*sqliteDB, error) {
+	if len(data) < sqliteHeaderSize || string(data[:len(sqliteHeaderMagic)]) != sqliteHeaderMagic {
+		return nil, fmt.Errorf("sqlite: not a database file")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, errSqliteFormat
+	}
+
+	db := &sqliteDB{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: pageSize - int(data[20]),
+		pageCount:  len(data) / pageSize,
+	}
+
+	if db.usableSize < sqliteMinUsableSize {
+		return nil, errSqliteFormat
+	}
+
+	return db, nil
+}
+
+func (db *sqliteDB) page(pageNo int) ([]byte, error) {
+	if pageNo < 1 || pageNo > db.pageCount {
+		return nil, errSqliteFormat
+	}
+
+	start := (pageNo - 1) * db.pageSize
+	return db.data[start : start+db.pageSize], nil
+}
+
+// tableRootPage returns the root page of the table from the schema table
+func (db *sqliteDB) tableRootPage(name string) (int, error) {
+	rootPage := 0
+	err := db.scanTable(sqliteMasterPageNo, func(rowID int64, values []interface{}) error {
+		if len(values) < 4 {
+			return nil
+		}
+
+		rtype, _ := values[0].(string)
+		rname, _ := values[1].(string)
+		if rtype == "table" && strings.EqualFold(rname, name) {
+			if pageNo, ok := values[3].(int64); ok {
+				rootPage = int(pageNo)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	if rootPage == 0 {
+		return 0, fmt.Errorf("sqlite: no table - %s", name)
+	}
+
+	return rootPage, nil
+}
+
+// scanTable calls the callback for each table row (in the rowid order)
+func (db *sqliteDB) scanTable(rootPage int, fn func(rowID int64, values []interface{}) error) error {
+	return db.scanTablePage(rootPage, 0, map[int]struct{}{}, fn)
+}
+
+// scanTablePage scans the table b-tree page
+// (each page is visited once, so the page cycles in the malformed databases are errors)
+func (db *sqliteDB) scanTablePage(pageNo int, depth int, visited map[int]struct{}, fn func(rowID int64, values []interface{}) error) error {
+	if depth > sqliteMaxTreeDepth {
+		return errSqliteFormat
+	}
+
+	if _, found := visited[pageNo]; found {
+		return errSqliteFormat
+	}
+
+	visited[pageNo] = struct{}{}
+
+	pageData, err := db.page(pageNo)
+	if err != nil {
+		return err
+	}
+
+	hdrOffset := 0
+	if pageNo == 1 {
+		hdrOffset = sqliteHeaderSize
+	}
+
+	if len(pageData) < hdrOffset+12 {
+		return errSqliteFormat
+	}
+
+	pageType := pageData[hdrOffset]
+	cellCount := int(binary.BigEndian.Uint16(pageData[hdrOffset+3 : hdrOffset+5]))
+	switch pageType {
+	case sqlitePageInteriorTable:
+		cellPtrStart := hdrOffset + 12
+		for i := 0; i < cellCount; i++ {
+			ptrOffset := cellPtrStart + i*2
+			if ptrOffset+2 > len(pageData) {
+				return errSqliteFormat
+			}
+
+			cellOffset := int(binary.BigEndian.Uint16(pageData[ptrOffset:]))
+			if cellOffset+4 > len(pageData) {
+				return errSqliteFormat
+			}
+
+			childPage := int(binary.BigEndian.Uint32(pageData[cellOffset:]))
+			if err := db.scanTablePage(childPage, depth+1, visited, fn); err != nil {
+				return err
+			}
+		}
+
+		rightPage := int(binary.BigEndian.Uint32(pageData[hdrOffset+8:]))
+		return db.scanTablePage(rightPage, depth+1, visited, fn)
+	case sqlitePageLeafTable:
+		cellPtrStart := hdrOffset + 8
+		for i := 0; i < cellCount; i++ {
+			ptrOffset := cellPtrStart + i*2
+			if ptrOffset+2 > len(pageData) {
+				return errSqliteFormat
+			}
+
+			cellOffset := int(binary.BigEndian.Uint16(pageData[ptrOffset:]))
+			rowID, payload, err := db.leafCell(pageData, cellOffset)
+			if err != nil {
+				return err
+			}
+
+			values, err := parseSqliteRecord(payload)
+			if err != nil {
+				return err
+			}
+
+			if err := fn(rowID, values); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("sqlite: unexpected page type (%d) in table b-tree", pageType)
+	}
+}
+
+// leafCell returns the rowid and the full payload (including the overflow pages) of the table leaf cell
+func (db *sqliteDB) leafCell(pageData []byte, offset int) (int64, []byte, error) {
+	if offset >= len(pageData) {
+		return 0, nil, errSqliteFormat
+	}
+
+	payloadSize, n := sqliteVarint(pageData[offset:])
+	if n == 0 {
+		return 0, nil, errSqliteFormat
+	}
+
+	offset += n
+	rowID, n := sqliteVarint(pageData[offset:])
+	if n == 0 {
+		return 0, nil, errSqliteFormat
+	}
+
+	offset += n
+	total := int(payloadSize)
+	if total < 0 || total > len(db.data) {
+		return 0, nil, errSqliteFormat
+	}
+
+	local := db.localPayloadSize(total)
+	if offset+local > len(pageData) {
+		return 0, nil, errSqliteFormat
+	}
+
+	payload := make([]byte, 0, total)
+	payload = append(payload, pageData[offset:offset+local]...)
+	if local == total {
+		return int64(rowID), payload, nil
+	}
+
+	if offset+local+4 > len(pageData) {
+		return 0, nil, errSqliteFormat
+	}
+
+	overflowPage := int(binary.BigEndian.Uint32(pageData[offset+local:]))
+	for len(payload) < total {
+		ovData, err := db.page(overflowPage)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		chunk := ovData[4:db.usableSize]
+		if remaining := total - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+
+		payload = append(payload, chunk...)
+		overflowPage = int(binary.BigEndian.Uint32(ovData))
+	}
+
+	return int64(rowID), payload, nil
+}
+
+// localPayloadSize returns the size of the payload part stored on the table leaf page
+func (db *sqliteDB) localPayloadSize(total int) int {
+	maxLocal := db.usableSize - 35
+	if total <= maxLocal {
+		return total
+	}
+
+	minLocal := ((db.usableSize-12)*32)/255 - 23
+	local := minLocal + (total-minLocal)%(db.usableSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+
+	return local
+}
+
+// parseSqliteRecord parses the record values (int64, string, []byte or nil)
+func parseSqliteRecord(payload []byte) ([]interface{}, error) {
+	hdrSize, n := sqliteVarint(payload)
+	if n == 0 || int(hdrSize) > len(payload) || int(hdrSize) < n {
+		return nil, errSqliteFormat
+	}
+
+	var serialTypes []uint64
+	for pos := n; pos < int(hdrSize); {
+		stype, n := sqliteVarint(payload[pos:])
+		if n == 0 {
+			return nil, errSqliteFormat
+		}
+
+		serialTypes = append(serialTypes, stype)
+		pos += n
+	}
+
+	var values []interface{}
+	pos := int(hdrSize)
+	for _, stype := range serialTypes {
+		var size int
+		switch {
+		case stype == 0, stype == 8, stype == 9:
+			size = 0
+		case stype >= 1 && stype <= 4:
+			size = int(stype)
+		case stype == 5:
+			size = 6
+		case stype == 6, stype == 7:
+			size = 8
+		case stype >= 12:
+			size = int((stype - 12) / 2)
+		default:
+			return nil, errSqliteFormat
+		}
+
+		if pos+size > len(payload) {
+			return nil, errSqliteFormat
+		}
+
+		raw := payload[pos : pos+size]
+		pos += size
+		switch {
+		case stype == 0:
+			values = append(values, nil)
+		case stype == 8:
+			values = append(values, int64(0))
+		case stype == 9:
+			values = append(values, int64(1))
+		case stype <= 6:
+			values = append(values, sqliteInt(raw))
+		case stype == 7:
+			//floating point values are not used
+			values = append(values, nil)
+		case stype%2 == 0:
+			values = append(values, raw)
+		default:
+			values = append(values, string(raw))
+		}
+	}
+
+	return values, nil
+}
+
+// sqliteInt decodes the big-endian two's complement integer
+func sqliteInt(raw []byte) int64 {
+	var value int64
+	for i, b := range raw {
+		if i == 0 {
+			value = int64(int8(b))
+			continue
+		}
+
+		value = value<<8 | int64(b)
+	}
+
+	return value
+}
+
+// sqliteVarint decodes the SQLite varint (returns 0 bytes read if the varint is truncated)
+func sqliteVarint(data []byte) (uint64, int) {
+	var value uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(data) {
+			return 0, 0
+		}
+
+		if i == 8 {
+			return value<<8 | uint64(data[i]), 9
+		}
+
+		value = value<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return value, i + 1
+		}
+	}
+
+	return value, 9
+}
@@ -0,0 +1,148 @@
+package sbom
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The testdata rpmdb databases are created with sqlite3 (512 byte pages):
+// single.sqlite has a few package rows on one leaf page and
+// multipage.sqlite has an interior table page and the overflow pages.
+func loadRpmdbFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/rpmdb/" + name)
+	require.NoError(t, err)
+	return data
+}
+
+func TestNewSqliteDB(t *testing.T) {
+	valid := loadRpmdbFixture(t, "single.sqlite")
+	withByte := func(offset int, value byte) []byte {
+		data := append([]byte{}, valid...)
+		data[offset] = value
+		return data
+	}
+
+	tt := []struct {
+		name  string
+		data  []byte
+		isErr bool
+	}{
+		{name: "valid", data: valid},
+		{name: "short", data: valid[:sqliteHeaderSize-1], isErr: true},
+		{name: "bad magic", data: withByte(0, 'X'), isErr: true},
+		{name: "bad page size", data: withByte(17, 0x10), isErr: true},
+		{name: "small usable size", data: withByte(20, 64), isErr: true},
+		{name: "no usable size", data: withByte(20, 255), isErr: true},
+	}
+
+	for _, test := range tt {
+		db, err := newSqliteDB(test.data)
+		if test.isErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+		assert.Equal(t, 512, db.pageSize)
+		assert.Equal(t, 512, db.usableSize)
+	}
+}
+
+func TestSqliteTableRootPage(t *testing.T) {
+	db, err := newSqliteDB(loadRpmdbFixture(t, "single.sqlite"))
+	require.NoError(t, err)
+
+	rootPage, err := db.tableRootPage("packages")
+	require.NoError(t, err)
+	assert.Greater(t, rootPage, sqliteMasterPageNo)
+
+	_, err = db.tableRootPage("Providename")
+	assert.Error(t, err)
+}
+
+func TestSqliteScanTableCycles(t *testing.T) {
+	data := loadRpmdbFixture(t, "multipage.sqlite")
+	db, err := newSqliteDB(data)
+	require.NoError(t, err)
+
+	rootPage, err := db.tableRootPage(rpmPackagesTable)
+	require.NoError(t, err)
+
+	pageData, err := db.page(rootPage)
+	require.NoError(t, err)
+	require.Equal(t, byte(sqlitePageInteriorTable), pageData[0])
+
+	rows := 0
+	require.NoError(t, db.scanTable(rootPage, func(rowID int64, values []interface{}) error {
+		rows++
+		return nil
+	}))
+	assert.Equal(t, 41, rows)
+
+	//the right-most pointer of the root page points back to the root page
+	binary.BigEndian.PutUint32(pageData[8:], uint32(rootPage))
+	assert.Error(t, db.scanTable(rootPage, func(int64, []interface{}) error { return nil }))
+
+	//the first child page is the right-most page too
+	cellOffset := binary.BigEndian.Uint16(pageData[12:])
+	binary.BigEndian.PutUint32(pageData[8:], binary.BigEndian.Uint32(pageData[cellOffset:]))
+	assert.Error(t, db.scanTable(rootPage, func(int64, []interface{}) error { return nil }))
+}
+
+func TestSqliteVarint(t *testing.T) {
+	tt := []struct {
+		data     []byte
+		expected uint64
+		size     int
+	}{
+		{data: []byte{0x00}, expected: 0, size: 1},
+		{data: []byte{0x7f, 0xff}, expected: 0x7f, size: 1},
+		{data: []byte{0x81, 0x00}, expected: 0x80, size: 2},
+		{data: []byte{0x82, 0x80, 0x01}, expected: 0x8001, size: 3},
+		{data: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, expected: 0xffffffffffffffff, size: 9},
+		{data: []byte{0x81}, size: 0},
+		{data: nil, size: 0},
+	}
+
+	for _, test := range tt {
+		value, size := sqliteVarint(test.data)
+		assert.Equal(t, test.size, size, "%x", test.data)
+		assert.Equal(t, test.expected, value, "%x", test.data)
+	}
+}
+
+func TestParseSqliteRecord(t *testing.T) {
+	tt := []struct {
+		name     string
+		payload  []byte
+		expected []interface{}
+		isErr    bool
+	}{
+		{
+			name:     "values",
+			payload:  []byte{8, 0, 1, 2, 8, 9, 0x13, 0x10, 0xfe, 0x01, 0x00, 'a', 'b', 'c', 0xca, 0xfe},
+			expected: []interface{}{nil, int64(-2), int64(256), int64(0), int64(1), "abc", []byte{0xca, 0xfe}},
+		},
+		{name: "float", payload: []byte{2, 7, 0, 0, 0, 0, 0, 0, 0, 0}, expected: []interface{}{nil}},
+		{name: "reserved type", payload: []byte{2, 10}, isErr: true},
+		{name: "truncated value", payload: []byte{2, 0x13, 'a'}, isErr: true},
+		{name: "header past the end", payload: []byte{5, 1}, isErr: true},
+		{name: "truncated header varint", payload: []byte{2, 0x81}, isErr: true},
+		{name: "empty", payload: nil, isErr: true},
+	}
+
+	for _, test := range tt {
+		values, err := parseSqliteRecord(test.payload)
+		if test.isErr {
+			assert.Error(t, err, test.name)
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, values, test.name)
+	}
+}
@@ -0,0 +1,311 @@
+package secretdiscover
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+)
+
+var (
+	ErrNoRules          = errors.New("no secret rules")
+	ErrMissingRuleID    = errors.New("missing secret rule ID")
+	ErrDuplicateRule    = errors.New("duplicate secret rule ID")
+	ErrMissingRuleMatch = errors.New("missing secret rule pattern")
+)
+
+// RuleFile is a secret rule file (YAML or JSON)
+type RuleFile struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// Rule is a pattern and entropy based secret detection rule
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	//Pattern is the secret regex (the first capture group is the secret value if the pattern has groups)
+	Pattern string `json:"pattern"`
+	//PathPattern limits the rule to the matching file paths (the rule also applies to the image history if it's empty)
+	PathPattern string `json:"path,omitempty"`
+	//MinEntropy is the minimum Shannon entropy (bits per char) for the secret value
+	MinEntropy float64 `json:"min_entropy,omitempty"`
+
+	matcher     *regexp.Regexp
+	pathMatcher *regexp.Regexp
+}
+
+// Compile compiles the rule patterns
+func (ref *Rule) Compile() error {
+	if ref.ID == "" {
+		return ErrMissingRuleID
+	}
+
+	if ref.Pattern == "" {
+		return fmt.Errorf("%w - %s", ErrMissingRuleMatch, ref.ID)
+	}
+
+	var err error
+	ref.matcher, err = regexp.Compile(ref.Pattern)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ref.ID, err)
+	}
+
+	if ref.PathPattern != "" {
+		ref.pathMatcher, err = regexp.Compile(ref.PathPattern)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ref.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Name returns the rule ID
+func (ref *Rule) Name() string {
+	return ref.ID
+}
+
+// AppliesTo returns true if the rule applies to the file path
+// (the empty path is used for the image history and config data)
+func (ref *Rule) AppliesTo(filePath string) bool {
+	if ref.pathMatcher == nil {
+		return true
+	}
+
+	if filePath == "" {
+		return false
+	}
+
+	return ref.pathMatcher.MatchString(filePath)
+}
+
+// Detect returns the secret matches in the data
+func (ref *Rule) Detect(filePath string, data []byte) []*Match {
+	if ref.matcher == nil || !ref.AppliesTo(filePath) {
+		return nil
+	}
+
+	var matches []*Match
+	for _, loc := range ref.matcher.FindAllSubmatchIndex(data, -1) {
+		start, end := loc[0], loc[1]
+		if len(loc) > 3 && loc[2] > -1 {
+			start, end = loc[2], loc[3]
+		}
+
+		secret := string(data[start:end])
+		if isPlaceholder(secret) {
+			continue
+		}
+
+		if end < len(data) && data[end] == '(' {
+			//function calls (not literal values)
+			continue
+		}
+
+		entropy := Entropy(secret)
+		if ref.MinEntropy > 0 && entropy < ref.MinEntropy {
+			continue
+		}
+
+		matches = append(matches, &Match{
+			RuleID:      ref.ID,
+			Description: ref.Description,
+			Line:        lineNumber(data, start),
+			Secret:      Redact(secret),
+			Context:     redactedContext(data, loc[0], loc[1], start, end),
+			Entropy:     math.Round(entropy*100) / 100,
+			offset:      start,
+			end:         end,
+		})
+	}
+
+	return matches
+}
+
+const (
+	genericKeyPattern = `[A-Za-z0-9_.\-]*(?:passw(?:or)?d|secret|token|api_?key|access_?key|private_?key|client_?secret|credential|auth_?key)s?[A-Za-z0-9_.\-]*["']?\s*(?:=|:=|:)\s*["']?`
+	genericValue      = `([^\s"'#;,\\]{8,})`
+)
+
+// DefaultRules returns the built-in secret detection rules
+// (the specific rules are before the generic rules, so they take priority for the same secret)
+func DefaultRules() []*Rule {
+	return []*Rule{
+		{
+			ID:          "aws-access-key-id",
+			Description: "AWS access key ID",
+			Pattern:     `\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`,
+		},
+		{
+			ID:          "aws-secret-access-key",
+			Description: "AWS secret access key",
+			Pattern:     `(?i)aws_?secret_?(?:access_?)?key["']?\s*(?:=|:)\s*["']?([A-Za-z0-9/+=]{40})\b`,
+			MinEntropy:  3.5,
+		},
+		{
+			ID:          "github-token",
+			Description: "GitHub token",
+			Pattern:     `\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{60,255})\b`,
+		},
+		{
+			ID:          "gitlab-token",
+			Description: "GitLab personal access token",
+			Pattern:     `\b(glpat-[A-Za-z0-9_\-]{20,})\b`,
+		},
+		{
+			ID:          "slack-token",
+			Description: "Slack token",
+			Pattern:     `\b(xox[baprs]-[A-Za-z0-9\-]{10,})\b`,
+		},
+		{
+			ID:          "slack-webhook",
+			Description: "Slack webhook URL",
+			Pattern:     `https://hooks\.slack\.com/services/([A-Za-z0-9_/]{20,})`,
+		},
+		{
+			ID:          "stripe-key",
+			Description: "Stripe live API key",
+			Pattern:     `\b((?:sk|rk)_live_[A-Za-z0-9]{20,})\b`,
+		},
+		{
+			ID:          "google-api-key",
+			Description: "Google API key",
+			Pattern:     `\b(AIza[0-9A-Za-z_\-]{35})\b`,
+		},
+		{
+			ID:          "gcp-service-account-key",
+			Description: "GCP service account private key ID",
+			Pattern:     `"private_key_id"\s*:\s*"([a-f0-9]{40})"`,
+		},
+		{
+			ID:          "azure-storage-key",
+			Description: "Azure storage account key",
+			Pattern:     `AccountKey=([A-Za-z0-9+/=]{86,88})`,
+		},
+		{
+			ID:          "npm-token",
+			Description: "npm access token",
+			Pattern:     `\b(npm_[A-Za-z0-9]{36})\b`,
+		},
+		{
+			ID:          "npmrc-auth",
+			Description: "npm registry credentials in .npmrc",
+			Pattern:     `(?m)(?:_authToken|_auth|_password)\s*=\s*["']?([^\s"']+)`,
+			PathPattern: `(^|/)\.?npmrc$`,
+		},
+		{
+			ID:          "pypi-token",
+			Description: "PyPI API token",
+			Pattern:     `\b(pypi-[A-Za-z0-9_\-]{50,})\b`,
+		},
+		{
+			ID:          "pypirc-password",
+			Description: "Python package index password in .pypirc",
+			Pattern:     `(?m)^\s*password\s*[:=]\s*(\S+)`,
+			PathPattern: `(^|/)\.pypirc$`,
+		},
+		{
+			ID:          "docker-config-auth",
+			Description: "Docker registry credentials",
+			Pattern:     `"(?:auth|identitytoken)"\s*:\s*"([A-Za-z0-9+/=_\-.]{8,})"`,
+			PathPattern: `(^|/)(\.docker/config\.json|\.dockercfg)$`,
+		},
+		{
+			ID:          "netrc-password",
+			Description: "Password in .netrc",
+			Pattern:     `\bpassword\s+(\S+)`,
+			PathPattern: `(^|/)[._]netrc$`,
+		},
+		{
+			ID:          "private-key",
+			Description: "Private key",
+			Pattern:     `-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----\s*(?:[A-Za-z-]+:.*\s+)*([A-Za-z0-9+/=]{16,})`,
+		},
+		{
+			ID:          "jwt",
+			Description: "JSON web token",
+			Pattern:     `\b(eyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,})`,
+		},
+		{
+			ID:          "sendgrid-key",
+			Description: "SendGrid API key",
+			Pattern:     `\b(SG\.[A-Za-z0-9_\-]{22}\.[A-Za-z0-9_\-]{43})\b`,
+		},
+		{
+			ID:          "openai-key",
+			Description: "OpenAI API key",
+			Pattern:     `\b(sk-(?:proj-)?[A-Za-z0-9_\-]{20,}T3BlbkFJ[A-Za-z0-9_\-]{20,})\b`,
+		},
+		{
+			ID:          "anthropic-key",
+			Description: "Anthropic API key",
+			Pattern:     `\b(sk-ant-[a-z0-9]+-[A-Za-z0-9_\-]{80,})`,
+		},
+		{
+			ID:          "url-credentials",
+			Description: "Password in URL",
+			Pattern:     `\b[a-zA-Z][a-zA-Z0-9+.\-]*://[^/\s:@"']+:([^/\s:@"']{3,})@[^/\s"']+`,
+			MinEntropy:  2.5,
+		},
+		{
+			ID:          "dotenv-secret",
+			Description: "Secret in .env file",
+			Pattern:     `(?im)^\s*(?:export\s+)?` + genericKeyPattern + genericValue,
+			PathPattern: `(^|/)\.env(\.[A-Za-z0-9_\-]+)?$`,
+			MinEntropy:  3.0,
+		},
+		{
+			ID:          "generic-secret",
+			Description: "Secret assignment",
+			Pattern:     `(?i)\b` + genericKeyPattern + genericValue,
+			MinEntropy:  3.5,
+		},
+	}
+}
+
+// LoadRules loads the secret rules from the rule files
+func LoadRules(filePaths []string) ([]*Rule, error) {
+	var rules []*Rule
+	ids := map[string]string{}
+	for _, rule := range DefaultRules() {
+		ids[rule.ID] = "built-in rules"
+	}
+
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		var rf RuleFile
+		if err := yaml.Unmarshal(data, &rf); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+
+		for _, rule := range rf.Rules {
+			if rule == nil {
+				continue
+			}
+
+			if source, found := ids[rule.ID]; found {
+				return nil, fmt.Errorf("%s: %w - %s (%s)", filePath, ErrDuplicateRule, rule.ID, source)
+			}
+
+			if err := rule.Compile(); err != nil {
+				return nil, fmt.Errorf("%s: %w", filePath, err)
+			}
+
+			ids[rule.ID] = filePath
+			rules = append(rules, rule)
+		}
+	}
+
+	if len(rules) == 0 {
+		return nil, ErrNoRules
+	}
+
+	return rules, nil
+}
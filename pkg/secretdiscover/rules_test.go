@@ -0,0 +1,123 @@
+package secretdiscover
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRules(t *testing.T) {
+	ids := map[string]struct{}{}
+	for _, rule := range DefaultRules() {
+		require.NoError(t, rule.Compile(), rule.ID)
+		_, found := ids[rule.ID]
+		assert.False(t, found, rule.ID)
+		ids[rule.ID] = struct{}{}
+	}
+}
+
+func TestRuleCompile(t *testing.T) {
+	tt := []struct {
+		name     string
+		rule     *Rule
+		expected error
+	}{
+		{name: "no id", rule: &Rule{Pattern: "x"}, expected: ErrMissingRuleID},
+		{name: "no pattern", rule: &Rule{ID: "x"}, expected: ErrMissingRuleMatch},
+		{name: "bad pattern", rule: &Rule{ID: "x", Pattern: "("}},
+		{name: "bad path pattern", rule: &Rule{ID: "x", Pattern: "x", PathPattern: "["}},
+	}
+
+	for _, test := range tt {
+		err := test.rule.Compile()
+		require.Error(t, err, test.name)
+		if test.expected != nil {
+			assert.True(t, errors.Is(err, test.expected), test.name)
+		}
+	}
+}
+
+func TestRuleDetect(t *testing.T) {
+	rule := &Rule{
+		ID:          "internal-token",
+		Description: "Internal token",
+		Pattern:     `itk_([A-Za-z0-9]{12,})`,
+		PathPattern: `\.conf$`,
+		MinEntropy:  3,
+	}
+
+	require.NoError(t, rule.Compile())
+	assert.Equal(t, "internal-token", rule.Name())
+	assert.True(t, rule.AppliesTo("/etc/app/app.conf"))
+	assert.False(t, rule.AppliesTo("/etc/app/app.yaml"))
+	assert.False(t, rule.AppliesTo(""))
+
+	data := []byte("a = itk_aaaaaaaaaaaaaaaa\nb = itk_Zq8xLm2wPk4R\nc = itk_Zq8xLm2wPk4Rs(1)\n")
+	matches := rule.Detect("/etc/app/app.conf", data)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "internal-token", matches[0].RuleID)
+	assert.Equal(t, "Internal token", matches[0].Description)
+	assert.Equal(t, 2, matches[0].Line)
+	assert.Equal(t, "Zq8x*****", matches[0].Secret)
+	assert.Equal(t, "itk_Zq8x*****", matches[0].Context)
+	assert.Equal(t, 3.58, matches[0].Entropy)
+
+	assert.Empty(t, rule.Detect("/etc/app/app.yaml", data))
+	assert.Empty(t, (&Rule{ID: "uncompiled", Pattern: "x"}).Detect("", []byte("x")))
+
+	//the whole match is the secret if the pattern has no groups
+	whole := &Rule{ID: "whole", Pattern: `itk_[A-Za-z0-9]{12}`}
+	require.NoError(t, whole.Compile())
+	matches = whole.Detect("", data)
+	require.Len(t, matches, 3)
+	assert.Equal(t, "itk_*****", matches[1].Secret)
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	writeRules := func(name, data string) string {
+		fpath := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(fpath, []byte(data), 0644))
+		return fpath
+	}
+
+	rulesFile := writeRules("rules.yaml", `
+rules:
+- id: internal-token
+  pattern: 'itk_([A-Za-z0-9]{12,})'
+  min_entropy: 3
+-
+`)
+
+	rules, err := LoadRules([]string{rulesFile, writeRules("more.json", `{"rules":[{"id":"other","pattern":"x"}]}`)})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "internal-token", rules[0].ID)
+	assert.Equal(t, 3.0, rules[0].MinEntropy)
+	assert.NotNil(t, rules[0].matcher)
+
+	tt := []struct {
+		name     string
+		files    []string
+		expected error
+	}{
+		{name: "no files", expected: ErrNoRules},
+		{name: "missing file", files: []string{filepath.Join(dir, "missing.yaml")}},
+		{name: "bad yaml", files: []string{writeRules("bad.yaml", "rules: [\n")}},
+		{name: "duplicate", files: []string{rulesFile, rulesFile}, expected: ErrDuplicateRule},
+		{name: "built-in rule", files: []string{writeRules("builtin.yaml", "rules:\n- id: jwt\n  pattern: x\n")}, expected: ErrDuplicateRule},
+		{name: "no pattern", files: []string{writeRules("nopattern.yaml", "rules:\n- id: x\n")}, expected: ErrMissingRuleMatch},
+	}
+
+	for _, test := range tt {
+		_, err := LoadRules(test.files)
+		require.Error(t, err, test.name)
+		if test.expected != nil {
+			assert.True(t, errors.Is(err, test.expected), "%s: %v", test.name, err)
+		}
+	}
+}
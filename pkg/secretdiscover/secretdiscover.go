@@ -0,0 +1,412 @@
+// Package secretdiscover detects the secrets and credentials in the image files
+// and in the image history and config data using pattern and entropy rules.
+package secretdiscover
+
+import (
+	"bytes"
+	"math"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultMaxFileSize = 1024 * 1024
+	redactedSuffix     = "*****"
+	maxContextLength   = 120
+	//the data is considered to be binary if it has a NUL byte in the prefix
+	binaryCheckLength = 8000
+)
+
+// Finding sources
+const (
+	SourceFile      = "file"
+	SourceHistory   = "history"
+	SourceConfigEnv = "config.env"
+)
+
+// Finding statuses
+const (
+	//StatusActive is for the secrets in the final image filesystem or config
+	StatusActive = "active"
+	//StatusDeleted is for the secrets in the files deleted in a later layer
+	//(they still exist in the earlier layer)
+	StatusDeleted = "deleted"
+	//StatusOverwritten is for the secrets in the files replaced in a later layer
+	//(they still exist in the earlier layer)
+	StatusOverwritten = "overwritten"
+)
+
+// Detector is a secret detector (the Rule type is the pattern and entropy based detector)
+type Detector interface {
+	Name() string
+	//Detect returns the secret matches in the file data
+	//(the file path is empty for the image history and config data)
+	Detect(filePath string, data []byte) []*Match
+}
+
+// Match is a detected secret
+type Match struct {
+	RuleID      string  `json:"rule_id"`
+	Description string  `json:"description,omitempty"`
+	Line        int     `json:"line,omitempty"`
+	Secret      string  `json:"secret"`  //redacted
+	Context     string  `json:"context"` //redacted
+	Entropy     float64 `json:"entropy"`
+
+	offset int
+	end    int
+}
+
+// Finding is a detected secret in the image
+type Finding struct {
+	Match
+	Source       string `json:"source"`
+	Status       string `json:"status"`
+	FilePath     string `json:"file_path,omitempty"`
+	LayerIndex   int    `json:"layer_index"`
+	LayerID      string `json:"layer_id,omitempty"`
+	HistoryIndex *int   `json:"history_index,omitempty"`
+}
+
+// Report has the secrets detected in the image
+type Report struct {
+	Findings []*Finding `json:"findings"`
+	//Stats has the finding counts by status
+	Stats map[string]int `json:"stats"`
+}
+
+// NewReport creates a report for the findings
+func NewReport(findings []*Finding) *Report {
+	report := &Report{
+		Findings: findings,
+		Stats:    map[string]int{},
+	}
+
+	if report.Findings == nil {
+		report.Findings = []*Finding{}
+	}
+
+	for _, finding := range report.Findings {
+		report.Stats[finding.Status]++
+	}
+
+	return report
+}
+
+// Scanner runs the secret detectors
+type Scanner struct {
+	Detectors   []Detector
+	MaxFileSize int64
+}
+
+// NewScanner creates a scanner with the built-in rules and the extra detectors
+func NewScanner(extra ...Detector) (*Scanner, error) {
+	scanner := &Scanner{
+		MaxFileSize: DefaultMaxFileSize,
+	}
+
+	for _, rule := range DefaultRules() {
+		if err := rule.Compile(); err != nil {
+			return nil, err
+		}
+
+		scanner.Detectors = append(scanner.Detectors, rule)
+	}
+
+	scanner.Detectors = append(scanner.Detectors, extra...)
+	return scanner, nil
+}
+
+// AddDetector adds a secret detector
+func (ref *Scanner) AddDetector(detector Detector) {
+	ref.Detectors = append(ref.Detectors, detector)
+}
+
+var skipDirs = []string{
+	"/proc/",
+	"/sys/",
+	"/dev/",
+	"/usr/share/doc/",
+	"/usr/share/man/",
+	"/usr/share/locale/",
+	"/usr/share/i18n/",
+	"/usr/share/zoneinfo/",
+	"/usr/share/mime/",
+	"/var/lib/dpkg/",
+	"/var/lib/apk/",
+	"/var/lib/rpm/",
+	"/var/cache/",
+}
+
+var skipExtensions = map[string]struct{}{
+	".png": {}, ".jpg": {}, ".jpeg": {}, ".gif": {}, ".ico": {}, ".svg": {}, ".webp": {},
+	".gz": {}, ".tgz": {}, ".zip": {}, ".xz": {}, ".bz2": {}, ".zst": {}, ".jar": {}, ".whl": {},
+	".so": {}, ".a": {}, ".o": {}, ".pyc": {}, ".class": {}, ".wasm": {},
+	".ttf": {}, ".otf": {}, ".woff": {}, ".woff2": {},
+	".mo": {}, ".map": {},
+}
+
+// IsCandidate returns true if the file needs to be scanned
+func (ref *Scanner) IsCandidate(filePath string, size int64) bool {
+	if ref == nil || size <= 0 || (ref.MaxFileSize > 0 && size > ref.MaxFileSize) {
+		return false
+	}
+
+	for _, dir := range skipDirs {
+		if strings.HasPrefix(filePath, dir) {
+			return false
+		}
+	}
+
+	if _, found := skipExtensions[strings.ToLower(path.Ext(filePath))]; found {
+		return false
+	}
+
+	return true
+}
+
+// Scan returns the secret matches in the data
+// (one match for each secret location; the earlier detectors have priority)
+func (ref *Scanner) Scan(filePath string, data []byte) []*Match {
+	if ref == nil || len(data) == 0 || isBinary(data) {
+		return nil
+	}
+
+	var matches []*Match
+	type span struct{ start, end int }
+	var spans []span
+	for _, detector := range ref.Detectors {
+		for _, match := range detector.Detect(filePath, data) {
+			current := span{start: match.offset, end: match.end}
+			var overlaps bool
+			for _, other := range spans {
+				if current.start < other.end && other.start < current.end {
+					overlaps = true
+					break
+				}
+			}
+
+			if overlaps {
+				continue
+			}
+
+			spans = append(spans, current)
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].offset < matches[j].offset
+	})
+
+	return matches
+}
+
+// ScanHistory returns the findings in the image history ('ENV', 'ARG' and the build args in 'RUN')
+// and in the image config environment variables
+func (ref *Scanner) ScanHistory(createdBy []string, historyLayers []int, env []string) []*Finding {
+	if ref == nil {
+		return nil
+	}
+
+	var findings []*Finding
+	for idx, text := range createdBy {
+		layerIndex := -1
+		if idx < len(historyLayers) {
+			layerIndex = historyLayers[idx]
+		}
+
+		historyIndex := idx
+		for _, match := range ref.Scan("", []byte(text)) {
+			match.Line = 0
+			findings = append(findings, &Finding{
+				Match:        *match,
+				Source:       SourceHistory,
+				Status:       StatusActive,
+				LayerIndex:   layerIndex,
+				HistoryIndex: &historyIndex,
+			})
+		}
+	}
+
+	for _, match := range ref.Scan("", []byte(strings.Join(env, "\n"))) {
+		match.Line = 0
+		findings = append(findings, &Finding{
+			Match:      *match,
+			Source:     SourceConfigEnv,
+			Status:     StatusActive,
+			LayerIndex: -1,
+		})
+	}
+
+	return findings
+}
+
+// DataSet has the secret matches from the image layers
+// (the layers can be processed in any order, so the matches are saved for each layer)
+type DataSet struct {
+	Layers map[string]map[string][]*Match
+}
+
+func NewDataSet() *DataSet {
+	return &DataSet{
+		Layers: map[string]map[string][]*Match{},
+	}
+}
+
+// AddMatches saves the secret matches for the layer file
+func (ref *DataSet) AddMatches(layerID string, filePath string, matches []*Match) {
+	if len(matches) == 0 {
+		return
+	}
+
+	files, found := ref.Layers[layerID]
+	if !found {
+		files = map[string][]*Match{}
+		ref.Layers[layerID] = files
+	}
+
+	files[filePath] = matches
+}
+
+// Findings returns the file findings in the layer order
+// (finalLayers has the layer index for the files in the final image filesystem)
+func (ref *DataSet) Findings(layerOrder []string, finalLayers map[string]int) []*Finding {
+	var findings []*Finding
+	for layerIndex, layerID := range layerOrder {
+		files := ref.Layers[layerID]
+		var paths []string
+		for fpath := range files {
+			paths = append(paths, fpath)
+		}
+
+		sort.Strings(paths)
+		for _, fpath := range paths {
+			status := StatusActive
+			if finalIndex, found := finalLayers[fpath]; !found {
+				status = StatusDeleted
+			} else if finalIndex != layerIndex {
+				status = StatusOverwritten
+			}
+
+			for _, match := range files[fpath] {
+				findings = append(findings, &Finding{
+					Match:      *match,
+					Source:     SourceFile,
+					Status:     status,
+					FilePath:   fpath,
+					LayerIndex: layerIndex,
+					LayerID:    layerID,
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// Entropy returns the Shannon entropy (bits per char) for the value
+func Entropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+
+	counts := map[rune]int{}
+	var total int
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// Redact returns the redacted secret value
+// (a short prefix is kept to help identify the secret; the length is not preserved)
+func Redact(value string) string {
+	keep := 4
+	if len(value) < 12 {
+		keep = 1
+	}
+
+	if len(value) <= keep {
+		return redactedSuffix
+	}
+
+	return value[:keep] + redactedSuffix
+}
+
+var placeholderValues = []string{
+	"example",
+	"changeme",
+	"change_me",
+	"placeholder",
+	"dummy",
+	"your_",
+	"your-",
+	"xxxx",
+	"****",
+	"redacted",
+	"<",
+	"${",
+	"{{",
+	"$(",
+	"%(",
+}
+
+func isPlaceholder(value string) bool {
+	lowered := strings.ToLower(value)
+	if strings.HasPrefix(lowered, "$") || strings.HasPrefix(lowered, "%") {
+		//variable references
+		return true
+	}
+
+	for _, placeholder := range placeholderValues {
+		if strings.Contains(lowered, placeholder) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBinary(data []byte) bool {
+	prefix := data
+	if len(prefix) > binaryCheckLength {
+		prefix = prefix[:binaryCheckLength]
+	}
+
+	return bytes.IndexByte(prefix, 0) > -1
+}
+
+func lineNumber(data []byte, offset int) int {
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// redactedContext returns the matched text with the redacted secret value
+// (limited to the matched line)
+func redactedContext(data []byte, matchStart, matchEnd, secretStart, secretEnd int) string {
+	before := string(data[matchStart:secretStart])
+	if idx := strings.LastIndex(before, "\n"); idx > -1 {
+		before = before[idx+1:]
+	}
+
+	after := string(data[secretEnd:matchEnd])
+	if idx := strings.Index(after, "\n"); idx > -1 {
+		after = after[:idx]
+	}
+
+	context := strings.TrimSpace(before + Redact(string(data[secretStart:secretEnd])) + after)
+	if len(context) > maxContextLength {
+		context = context[:maxContextLength]
+	}
+
+	return context
+}
@@ -0,0 +1,358 @@
+// Package syshook detects the system hooks in the image filesystem that run code
+// implicitly (shell profile scripts, preloaded libraries, udev rule programs and PAM modules).
+package syshook
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Hook types
+const (
+	TypeShellProfile = "shell.profile"
+	TypeLDPreload    = "ld.preload"
+	TypeUdevRule     = "udev.rule"
+	TypePAM          = "pam"
+)
+
+const (
+	ProfileFile       = "/etc/profile"
+	ProfileDir        = "/etc/profile.d"
+	BashrcFile        = "/etc/bash.bashrc"
+	BashrcAltFile     = "/etc/bashrc"
+	ZshenvFile        = "/etc/zsh/zshenv"
+	ZshrcFile         = "/etc/zsh/zshrc"
+	EnvironmentFile   = "/etc/environment"
+	LDPreloadFile     = "/etc/ld.so.preload"
+	PAMConfigFile     = "/etc/pam.conf"
+	PAMConfigDir      = "/etc/pam.d"
+	ldPreloadEnvVar   = "LD_PRELOAD"
+	udevRuleExtension = ".rules"
+)
+
+// UdevRuleDirs are the udev rule directories
+var UdevRuleDirs = []string{
+	"/etc/udev/rules.d",
+	"/run/udev/rules.d",
+	"/lib/udev/rules.d",
+	"/usr/lib/udev/rules.d",
+}
+
+var shellProfileFiles = map[string]struct{}{
+	ProfileFile:   {},
+	BashrcFile:    {},
+	BashrcAltFile: {},
+	ZshenvFile:    {},
+	ZshrcFile:     {},
+}
+
+// IsSourceFile returns true if the file is a system hook source
+func IsSourceFile(fullPath string) bool {
+	switch fullPath {
+	case EnvironmentFile, LDPreloadFile, PAMConfigFile:
+		return true
+	}
+
+	if _, found := shellProfileFiles[fullPath]; found {
+		return true
+	}
+
+	dir := path.Dir(fullPath)
+	switch dir {
+	case ProfileDir, PAMConfigDir:
+		return true
+	}
+
+	return isUdevRuleFile(fullPath)
+}
+
+func isUdevRuleFile(fullPath string) bool {
+	if !strings.HasSuffix(fullPath, udevRuleExtension) {
+		return false
+	}
+
+	dir := path.Dir(fullPath)
+	for _, ruleDir := range UdevRuleDirs {
+		if dir == ruleDir {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Report has the system hooks detected in the image
+type Report struct {
+	Hooks   []*Hook  `json:"hooks"`
+	Sources []string `json:"sources"`
+}
+
+// Hook is a system hook
+type Hook struct {
+	Type     string `json:"type"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line,omitempty"`
+	//Target is what triggers the hook (e.g., the PAM service and module type or the udev rule match)
+	Target  string `json:"target,omitempty"`
+	Command string `json:"command"`
+	RawData string `json:"raw_data,omitempty"`
+}
+
+// NewReportFromData creates the system hook report from the hook source files
+func NewReportFromData(files map[string][]byte) *Report {
+	report := &Report{
+		Hooks: []*Hook{},
+	}
+
+	var paths []string
+	for fpath := range files {
+		paths = append(paths, fpath)
+	}
+
+	sort.Strings(paths)
+	for _, fpath := range paths {
+		data := files[fpath]
+		dir := path.Dir(fpath)
+		var hooks []*Hook
+		switch {
+		case fpath == LDPreloadFile:
+			hooks = parseLDPreload(fpath, data)
+		case fpath == EnvironmentFile:
+			hooks = parseEnvironment(fpath, data)
+		case dir == ProfileDir:
+			//the login shells source the '.sh' scripts
+			if !strings.HasSuffix(fpath, ".sh") {
+				continue
+			}
+
+			hooks = parseProfile(fpath, data)
+		case fpath == PAMConfigFile:
+			hooks = parsePAMConfig(fpath, data, "")
+		case dir == PAMConfigDir:
+			hooks = parsePAMConfig(fpath, data, path.Base(fpath))
+		case isUdevRuleFile(fpath):
+			hooks = parseUdevRules(fpath, data)
+		default:
+			if _, found := shellProfileFiles[fpath]; found {
+				hooks = parseProfile(fpath, data)
+			}
+		}
+
+		if len(hooks) > 0 {
+			report.Sources = append(report.Sources, fpath)
+			report.Hooks = append(report.Hooks, hooks...)
+		}
+	}
+
+	return report
+}
+
+type dataLine struct {
+	num  int
+	text string
+}
+
+// dataLines returns the non-empty lines without the '#' comment lines
+func dataLines(data []byte) []dataLine {
+	var lines []dataLine
+	num := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		num++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		lines = append(lines, dataLine{num: num, text: text})
+	}
+
+	return lines
+}
+
+// parseLDPreload parses the preloaded library list (whitespace or ':' separated)
+func parseLDPreload(filePath string, data []byte) []*Hook {
+	var hooks []*Hook
+	for _, line := range dataLines(data) {
+		for _, lib := range strings.FieldsFunc(line.text, func(r rune) bool {
+			return r == ':' || r == ' ' || r == '\t'
+		}) {
+			hooks = append(hooks, &Hook{
+				Type:     TypeLDPreload,
+				FilePath: filePath,
+				Line:     line.num,
+				Target:   "all dynamically linked executables",
+				Command:  lib,
+				RawData:  line.text,
+			})
+		}
+	}
+
+	return hooks
+}
+
+var envPreload = regexp.MustCompile(`^(export\s+)?` + ldPreloadEnvVar + `=["']?([^"'\s]+)`)
+
+// parseEnvironment parses the LD_PRELOAD assignments in the pam_env environment file
+func parseEnvironment(filePath string, data []byte) []*Hook {
+	var hooks []*Hook
+	for _, line := range dataLines(data) {
+		if m := envPreload.FindStringSubmatch(line.text); m != nil {
+			hooks = append(hooks, &Hook{
+				Type:     TypeLDPreload,
+				FilePath: filePath,
+				Line:     line.num,
+				Target:   "login sessions",
+				Command:  m[2],
+				RawData:  line.text,
+			})
+		}
+	}
+
+	return hooks
+}
+
+// parseProfile returns the shell profile script hook
+// (the LD_PRELOAD assignments are reported separately)
+func parseProfile(filePath string, data []byte) []*Hook {
+	lines := dataLines(data)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	hooks := []*Hook{
+		{
+			Type:     TypeShellProfile,
+			FilePath: filePath,
+			Target:   "shell sessions",
+			Command:  filePath,
+		},
+	}
+
+	for _, line := range lines {
+		if m := envPreload.FindStringSubmatch(line.text); m != nil {
+			hooks = append(hooks, &Hook{
+				Type:     TypeLDPreload,
+				FilePath: filePath,
+				Line:     line.num,
+				Target:   "shell sessions",
+				Command:  m[2],
+				RawData:  line.text,
+			})
+		}
+	}
+
+	return hooks
+}
+
+// PAM modules that run external programs or scripts
+var pamExecModules = map[string]struct{}{
+	"pam_exec.so":   {},
+	"pam_script.so": {},
+	"pam_python.so": {},
+}
+
+// parsePAMConfig parses the PAM config lines ('[SERVICE] TYPE CONTROL MODULE [ARGS]')
+// and returns the modules that run programs and the modules loaded from non-standard paths
+func parsePAMConfig(filePath string, data []byte, service string) []*Hook {
+	var hooks []*Hook
+	for _, line := range dataLines(data) {
+		fields := strings.Fields(line.text)
+		lineService := service
+		if service == "" {
+			//'/etc/pam.conf' lines have the service field
+			if len(fields) < 1 {
+				continue
+			}
+
+			lineService = fields[0]
+			fields = fields[1:]
+		}
+
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "@") {
+			//'@include' lines
+			continue
+		}
+
+		moduleType := strings.TrimPrefix(fields[0], "-")
+		rest := fields[1:]
+		if strings.HasPrefix(rest[0], "[") {
+			//'[success=1 default=ignore]' controls
+			for len(rest) > 0 && !strings.HasSuffix(rest[0], "]") {
+				rest = rest[1:]
+			}
+		}
+
+		if len(rest) < 2 {
+			continue
+		}
+
+		module := rest[1]
+		_, isExec := pamExecModules[path.Base(module)]
+		if !isExec && !path.IsAbs(module) {
+			continue
+		}
+
+		command := module
+		if isExec {
+			//the program (and the module options)
+			command = strings.Join(rest[1:], " ")
+		}
+
+		hooks = append(hooks, &Hook{
+			Type:     TypePAM,
+			FilePath: filePath,
+			Line:     line.num,
+			Target:   lineService + " " + moduleType,
+			Command:  command,
+			RawData:  line.text,
+		})
+	}
+
+	return hooks
+}
+
+// 'PROGRAM' is a match key (it runs the program to match its output)
+var udevProgramKey = regexp.MustCompile(`(RUN(\{program\})?|PROGRAM|IMPORT\{program\})\s*(==|\+=|:=|=)\s*"([^"]*)"`)
+
+// parseUdevRules returns the programs the udev rules run
+func parseUdevRules(filePath string, data []byte) []*Hook {
+	var hooks []*Hook
+	for _, line := range dataLines(data) {
+		matches := udevProgramKey.FindAllStringSubmatch(line.text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var target []string
+		for _, part := range strings.Split(line.text, ",") {
+			part = strings.TrimSpace(part)
+			if strings.Contains(part, "==") && !udevProgramKey.MatchString(part) {
+				target = append(target, part)
+			}
+		}
+
+		for _, m := range matches {
+			command := m[4]
+			if strings.HasPrefix(command, "builtin:") {
+				continue
+			}
+
+			hooks = append(hooks, &Hook{
+				Type:     TypeUdevRule,
+				FilePath: filePath,
+				Line:     line.num,
+				Target:   strings.Join(target, ", "),
+				Command:  command,
+				RawData:  line.text,
+			})
+		}
+	}
+
+	return hooks
+}
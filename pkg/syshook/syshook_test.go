@@ -0,0 +1,248 @@
+package syshook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSourceFile(t *testing.T) {
+	tt := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/etc/profile", expected: true},
+		{path: "/etc/profile.d/env.sh", expected: true},
+		{path: "/etc/bash.bashrc", expected: true},
+		{path: "/etc/zsh/zshrc", expected: true},
+		{path: "/etc/environment", expected: true},
+		{path: "/etc/ld.so.preload", expected: true},
+		{path: "/etc/pam.conf", expected: true},
+		{path: "/etc/pam.d/sshd", expected: true},
+		{path: "/lib/udev/rules.d/99-app.rules", expected: true},
+		{path: "/etc/udev/rules.d/10-net.rules", expected: true},
+		{path: "/etc/udev/rules.d/README"},
+		{path: "/etc/udev/udev.conf"},
+		{path: "/etc/ld.so.conf"},
+		{path: "/root/.bashrc"},
+	}
+
+	for _, test := range tt {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsSourceFile(test.path))
+		})
+	}
+}
+
+func TestParseLDPreload(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expected []*Hook
+	}{
+		{
+			name: "libraries",
+			data: "# preloaded\n/lib/libhook.so /usr/lib/libtrace.so:/lib/libother.so\n",
+			expected: []*Hook{
+				{Type: TypeLDPreload, FilePath: LDPreloadFile, Line: 2, Target: "all dynamically linked executables", Command: "/lib/libhook.so", RawData: "/lib/libhook.so /usr/lib/libtrace.so:/lib/libother.so"},
+				{Type: TypeLDPreload, FilePath: LDPreloadFile, Line: 2, Target: "all dynamically linked executables", Command: "/usr/lib/libtrace.so", RawData: "/lib/libhook.so /usr/lib/libtrace.so:/lib/libother.so"},
+				{Type: TypeLDPreload, FilePath: LDPreloadFile, Line: 2, Target: "all dynamically linked executables", Command: "/lib/libother.so", RawData: "/lib/libhook.so /usr/lib/libtrace.so:/lib/libother.so"},
+			},
+		},
+		{
+			name: "separators only",
+			data: " : \t:\n",
+		},
+		{
+			name: "comments only",
+			data: "# /lib/libhook.so\n\n",
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseLDPreload(LDPreloadFile, []byte(test.data)))
+		})
+	}
+}
+
+func TestParseEnvironment(t *testing.T) {
+	data := `PATH="/usr/local/sbin:/usr/local/bin"
+LD_PRELOAD="/lib/libhook.so"
+export LD_PRELOAD=/lib/libexported.so
+# LD_PRELOAD=/lib/commented.so
+MY_LD_PRELOAD=/lib/other.so
+LD_PRELOAD=
+`
+
+	assert.Equal(t, []*Hook{
+		{Type: TypeLDPreload, FilePath: EnvironmentFile, Line: 2, Target: "login sessions", Command: "/lib/libhook.so", RawData: `LD_PRELOAD="/lib/libhook.so"`},
+		{Type: TypeLDPreload, FilePath: EnvironmentFile, Line: 3, Target: "login sessions", Command: "/lib/libexported.so", RawData: "export LD_PRELOAD=/lib/libexported.so"},
+	}, parseEnvironment(EnvironmentFile, []byte(data)))
+}
+
+func TestParseProfile(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expected []*Hook
+	}{
+		{
+			name: "script",
+			data: "#!/bin/sh\nexport PATH=$PATH:/opt/bin\n",
+			expected: []*Hook{
+				{Type: TypeShellProfile, FilePath: "/etc/profile.d/app.sh", Target: "shell sessions", Command: "/etc/profile.d/app.sh"},
+			},
+		},
+		{
+			name: "preload",
+			data: "export LD_PRELOAD='/opt/lib/libhook.so'\n",
+			expected: []*Hook{
+				{Type: TypeShellProfile, FilePath: "/etc/profile.d/app.sh", Target: "shell sessions", Command: "/etc/profile.d/app.sh"},
+				{Type: TypeLDPreload, FilePath: "/etc/profile.d/app.sh", Line: 1, Target: "shell sessions", Command: "/opt/lib/libhook.so", RawData: "export LD_PRELOAD='/opt/lib/libhook.so'"},
+			},
+		},
+		{
+			name: "comments only",
+			data: "#!/bin/sh\n# nothing to do\n",
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseProfile("/etc/profile.d/app.sh", []byte(test.data)))
+		})
+	}
+}
+
+func TestParsePAMConfig(t *testing.T) {
+	tt := []struct {
+		name     string
+		filePath string
+		service  string
+		data     string
+		expected []*Hook
+	}{
+		{
+			name:     "service config",
+			filePath: "/etc/pam.d/sshd",
+			service:  "sshd",
+			data: `# PAM config
+@include common-auth
+auth       required     pam_unix.so
+session    optional     pam_exec.so seteuid /usr/local/bin/on-login.sh
+-session   optional     /opt/pam/pam_custom.so debug
+account [success=1 default=ignore] pam_exec.so quiet /usr/bin/check
+auth [default=die] pam_script.so
+`,
+			expected: []*Hook{
+				{Type: TypePAM, FilePath: "/etc/pam.d/sshd", Line: 4, Target: "sshd session", Command: "pam_exec.so seteuid /usr/local/bin/on-login.sh", RawData: "session    optional     pam_exec.so seteuid /usr/local/bin/on-login.sh"},
+				{Type: TypePAM, FilePath: "/etc/pam.d/sshd", Line: 5, Target: "sshd session", Command: "/opt/pam/pam_custom.so", RawData: "-session   optional     /opt/pam/pam_custom.so debug"},
+				{Type: TypePAM, FilePath: "/etc/pam.d/sshd", Line: 6, Target: "sshd account", Command: "pam_exec.so quiet /usr/bin/check", RawData: "account [success=1 default=ignore] pam_exec.so quiet /usr/bin/check"},
+				{Type: TypePAM, FilePath: "/etc/pam.d/sshd", Line: 7, Target: "sshd auth", Command: "pam_script.so", RawData: "auth [default=die] pam_script.so"},
+			},
+		},
+		{
+			name:     "pam.conf",
+			filePath: PAMConfigFile,
+			data: `login auth required pam_unix.so
+su session optional /lib/security/pam_exec.so /usr/bin/audit
+`,
+			expected: []*Hook{
+				{Type: TypePAM, FilePath: PAMConfigFile, Line: 2, Target: "su session", Command: "/lib/security/pam_exec.so /usr/bin/audit", RawData: "su session optional /lib/security/pam_exec.so /usr/bin/audit"},
+			},
+		},
+		{
+			name:     "malformed",
+			filePath: "/etc/pam.d/login",
+			service:  "login",
+			data: `auth required
+session
+account [success=1 default=ignore
+auth [default=die]
+`,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parsePAMConfig(test.filePath, []byte(test.data), test.service))
+		})
+	}
+}
+
+func TestParseUdevRules(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expected []*Hook
+	}{
+		{
+			name: "run",
+			data: `# udev rules
+ACTION=="add", SUBSYSTEM=="net", RUN+="/usr/local/bin/net-up.sh %k"
+KERNEL=="sd*", PROGRAM=="/lib/udev/scsi_id -g", IMPORT{program}="/lib/udev/path_id"
+SUBSYSTEM=="usb", RUN{builtin}+="usb_id", RUN+="builtin:kmod load"
+SUBSYSTEM=="block", ENV{ID_FS_TYPE}="ext4"
+`,
+			expected: []*Hook{
+				{Type: TypeUdevRule, FilePath: "/etc/udev/rules.d/99-app.rules", Line: 2, Target: `ACTION=="add", SUBSYSTEM=="net"`, Command: "/usr/local/bin/net-up.sh %k", RawData: `ACTION=="add", SUBSYSTEM=="net", RUN+="/usr/local/bin/net-up.sh %k"`},
+				{Type: TypeUdevRule, FilePath: "/etc/udev/rules.d/99-app.rules", Line: 3, Target: `KERNEL=="sd*"`, Command: "/lib/udev/scsi_id -g", RawData: `KERNEL=="sd*", PROGRAM=="/lib/udev/scsi_id -g", IMPORT{program}="/lib/udev/path_id"`},
+				{Type: TypeUdevRule, FilePath: "/etc/udev/rules.d/99-app.rules", Line: 3, Target: `KERNEL=="sd*"`, Command: "/lib/udev/path_id", RawData: `KERNEL=="sd*", PROGRAM=="/lib/udev/scsi_id -g", IMPORT{program}="/lib/udev/path_id"`},
+			},
+		},
+		{
+			name: "malformed",
+			data: "RUN+=/usr/bin/unquoted\nRUN+=\"/usr/bin/unterminated\n",
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseUdevRules("/etc/udev/rules.d/99-app.rules", []byte(test.data)))
+		})
+	}
+}
+
+func TestNewReportFromData(t *testing.T) {
+	files := map[string][]byte{
+		"/etc/profile":                   []byte("export PATH=/usr/bin\n"),
+		"/etc/profile.d/app.sh":          []byte("export APP_HOME=/app\n"),
+		"/etc/profile.d/readme.txt":      []byte("not sourced\n"),
+		"/etc/profile.d/empty.sh":        []byte("# nothing\n"),
+		"/etc/ld.so.preload":             []byte("/lib/libhook.so\n"),
+		"/etc/environment":               []byte("LANG=C.UTF-8\n"),
+		"/etc/pam.d/sshd":                []byte("auth required pam_unix.so\n"),
+		"/etc/pam.d/login":               []byte("session optional pam_exec.so /usr/bin/notify\n"),
+		"/lib/udev/rules.d/99-app.rules": []byte(`ACTION=="add", RUN+="/usr/bin/app-hotplug"` + "\n"),
+	}
+
+	report := NewReportFromData(files)
+	var hooks []string
+	for _, hook := range report.Hooks {
+		hooks = append(hooks, hook.Type+" "+hook.Command)
+	}
+
+	assert.Equal(t, []string{
+		"ld.preload /lib/libhook.so",
+		"pam pam_exec.so /usr/bin/notify",
+		"shell.profile /etc/profile",
+		"shell.profile /etc/profile.d/app.sh",
+		"udev.rule /usr/bin/app-hotplug",
+	}, hooks)
+
+	assert.Equal(t, []string{
+		"/etc/ld.so.preload",
+		"/etc/pam.d/login",
+		"/etc/profile",
+		"/etc/profile.d/app.sh",
+		"/lib/udev/rules.d/99-app.rules",
+	}, report.Sources)
+
+	empty := NewReportFromData(nil)
+	assert.Equal(t, []*Hook{}, empty.Hooks)
+	assert.Empty(t, empty.Sources)
+}
@@ -0,0 +1,185 @@
+package sysservice
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// ConfigFile is a parsed INI style config file (systemd units and supervisord configs)
+type ConfigFile struct {
+	//Sections are the config sections in the file order
+	Sections []*ConfigSection
+}
+
+// ConfigSection is a config file section
+// (the same key can have multiple values, e.g., 'ExecStartPre' in systemd units)
+type ConfigSection struct {
+	Name   string
+	Keys   []string
+	Values map[string][]string
+}
+
+// Section returns the last section with the name
+func (ref *ConfigFile) Section(name string) *ConfigSection {
+	var result *ConfigSection
+	for _, section := range ref.Sections {
+		if section.Name == name {
+			result = section
+		}
+	}
+
+	return result
+}
+
+// Value returns the last value for the section key
+func (ref *ConfigFile) Value(section, key string) string {
+	if s := ref.Section(section); s != nil {
+		return s.Value(key)
+	}
+
+	return ""
+}
+
+// Values returns all values for the section key
+func (ref *ConfigFile) Values(section, key string) []string {
+	if s := ref.Section(section); s != nil {
+		return s.Values[key]
+	}
+
+	return nil
+}
+
+// Value returns the last value for the key
+func (ref *ConfigSection) Value(key string) string {
+	values := ref.Values[key]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[len(values)-1]
+}
+
+func (ref *ConfigSection) add(key, value string) {
+	if _, found := ref.Values[key]; !found {
+		ref.Keys = append(ref.Keys, key)
+	}
+
+	ref.Values[key] = append(ref.Values[key], value)
+}
+
+func (ref *ConfigSection) reset(key string) {
+	if _, found := ref.Values[key]; !found {
+		return
+	}
+
+	delete(ref.Values, key)
+	for i, name := range ref.Keys {
+		if name == key {
+			ref.Keys = append(ref.Keys[:i], ref.Keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// ParseUnitData parses the systemd unit file data
+// (the empty value assignments reset the key values like in systemd)
+func ParseUnitData(data []byte) *ConfigFile {
+	return parseConfigData(data, false, true)
+}
+
+// ParseSupervisordData parses the supervisord config file data
+func ParseSupervisordData(data []byte) *ConfigFile {
+	return parseConfigData(data, true, false)
+}
+
+// MergeUnitData merges the systemd unit drop-in data
+func (ref *ConfigFile) MergeUnitData(data []byte) {
+	//keeping the empty values to reset the main unit values
+	dropin := parseConfigData(data, false, false)
+	for _, ds := range dropin.Sections {
+		section := ref.Section(ds.Name)
+		if section == nil {
+			ref.Sections = append(ref.Sections, ds)
+			continue
+		}
+
+		for _, key := range ds.Keys {
+			for _, value := range ds.Values[key] {
+				if value == "" {
+					section.reset(key)
+					continue
+				}
+
+				section.add(key, value)
+			}
+		}
+	}
+}
+
+func parseConfigData(data []byte, inlineComments bool, emptyResets bool) *ConfigFile {
+	result := &ConfigFile{}
+	var section *ConfigSection
+	var pending string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if pending != "" {
+			line = pending + " " + line
+			pending = ""
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			pending = strings.TrimSuffix(line, "\\")
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = &ConfigSection{
+				Name:   strings.TrimSpace(line[1 : len(line)-1]),
+				Values: map[string][]string{},
+			}
+
+			result.Sections = append(result.Sections, section)
+			continue
+		}
+
+		if section == nil {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if inlineComments {
+			if idx := strings.Index(value, " ;"); idx > -1 {
+				value = strings.TrimSpace(value[:idx])
+			}
+		}
+
+		if value == "" && emptyResets {
+			section.reset(key)
+			continue
+		}
+
+		section.add(key, value)
+	}
+
+	if pending != "" && section != nil {
+		if key, value, found := strings.Cut(pending, "="); found {
+			section.add(strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	}
+
+	return result
+}
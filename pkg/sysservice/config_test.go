@@ -0,0 +1,117 @@
+package sysservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnitData(t *testing.T) {
+	data := `# nginx unit
+[Unit]
+Description=A high performance web server
+After=network.target
+
+[Service]
+Type=forking
+ExecStartPre=/usr/sbin/nginx -t -q
+ExecStartPre=/usr/sbin/nginx -T
+ExecStart=/usr/sbin/nginx \
+  -g 'daemon on;'
+Environment=A=1
+Environment=
+Environment=B=2
+; comment
+not a key value line
+
+[Install]
+WantedBy=multi-user.target
+`
+
+	config := ParseUnitData([]byte(data))
+	require.Len(t, config.Sections, 3)
+	assert.Equal(t, "A high performance web server", config.Value("Unit", "Description"))
+	assert.Equal(t, []string{"/usr/sbin/nginx -t -q", "/usr/sbin/nginx -T"}, config.Values("Service", "ExecStartPre"))
+	assert.Equal(t, "/usr/sbin/nginx  -g 'daemon on;'", config.Value("Service", "ExecStart"))
+	//the empty assignment resets the values
+	assert.Equal(t, []string{"B=2"}, config.Values("Service", "Environment"))
+	assert.Equal(t, []string{"Type", "ExecStartPre", "ExecStart", "Environment"}, config.Section("Service").Keys)
+	assert.Equal(t, "multi-user.target", config.Value("Install", "WantedBy"))
+	assert.Empty(t, config.Value("Install", "Missing"))
+	assert.Nil(t, config.Values("Missing", "Key"))
+	assert.Nil(t, config.Section("Missing"))
+}
+
+func TestParseConfigDataMalformed(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expected []*ConfigSection
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "keys before the first section",
+			data: "Key=value\n[Section]\nName=test\n",
+			expected: []*ConfigSection{
+				{Name: "Section", Keys: []string{"Name"}, Values: map[string][]string{"Name": {"test"}}},
+			},
+		},
+		{
+			name: "unterminated section",
+			data: "[Section\nName=test\n",
+		},
+		{
+			name: "trailing continuation",
+			data: "[Section]\nExecStart=/bin/app \\",
+			expected: []*ConfigSection{
+				{Name: "Section", Keys: []string{"ExecStart"}, Values: map[string][]string{"ExecStart": {"/bin/app"}}},
+			},
+		},
+		{
+			name: "empty section name",
+			data: "[ ]\nKey = value \n",
+			expected: []*ConfigSection{
+				{Name: "", Keys: []string{"Key"}, Values: map[string][]string{"Key": {"value"}}},
+			},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			config := ParseUnitData([]byte(test.data))
+			assert.Equal(t, test.expected, config.Sections)
+		})
+	}
+}
+
+func TestParseSupervisordData(t *testing.T) {
+	data := `[supervisord]
+nodaemon=true
+
+[program:app]
+command=/usr/local/bin/app --port 8080 ; the app
+user=app
+autostart=false
+`
+
+	config := ParseSupervisordData([]byte(data))
+	require.Len(t, config.Sections, 2)
+	assert.Equal(t, "/usr/local/bin/app --port 8080", config.Value("program:app", "command"))
+	assert.Equal(t, "app", config.Value("program:app", "user"))
+	assert.Equal(t, "false", config.Value("program:app", "autostart"))
+	assert.Equal(t, "true", config.Value("supervisord", "nodaemon"))
+}
+
+func TestMergeUnitData(t *testing.T) {
+	config := ParseUnitData([]byte("[Service]\nExecStart=/usr/bin/app\nUser=app\nRestart=always\n"))
+	config.MergeUnitData([]byte("[Service]\nExecStart=\nExecStart=/usr/bin/app --debug\nUser=root\n\n[Unit]\nDescription=Overridden\n"))
+
+	assert.Equal(t, []string{"/usr/bin/app --debug"}, config.Values("Service", "ExecStart"))
+	assert.Equal(t, "root", config.Value("Service", "User"))
+	assert.Equal(t, "always", config.Value("Service", "Restart"))
+	assert.Equal(t, []string{"User", "Restart", "ExecStart"}, config.Section("Service").Keys)
+	assert.Equal(t, "Overridden", config.Value("Unit", "Description"))
+}
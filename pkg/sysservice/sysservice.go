@@ -0,0 +1,591 @@
+// Package sysservice detects the service definitions in the image filesystem
+// (systemd units, init.d scripts, supervisord programs, s6 and runit services).
+package sysservice
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Service types
+const (
+	TypeSystemd     = "systemd"
+	TypeInitd       = "initd"
+	TypeSupervisord = "supervisord"
+	TypeS6          = "s6"
+	TypeRunit       = "runit"
+)
+
+// Systemd unit scopes
+const (
+	ScopeSystem = "system"
+	ScopeUser   = "user"
+)
+
+// SystemdSystemUnitDirs are the systemd system unit directories (in the priority order)
+var SystemdSystemUnitDirs = []string{
+	"/etc/systemd/system",
+	"/usr/local/lib/systemd/system",
+	"/lib/systemd/system",
+	"/usr/lib/systemd/system",
+}
+
+// SystemdUserUnitDirs are the systemd user unit directories (in the priority order)
+var SystemdUserUnitDirs = []string{
+	"/etc/systemd/user",
+	"/usr/local/lib/systemd/user",
+	"/lib/systemd/user",
+	"/usr/lib/systemd/user",
+}
+
+const (
+	InitdDir               = "/etc/init.d"
+	OpenRCRunlevelsDir     = "/etc/runlevels"
+	SupervisordConfigFile  = "/etc/supervisord.conf"
+	SupervisorConfigFile   = "/etc/supervisor/supervisord.conf"
+	SupervisorConfigDir    = "/etc/supervisor/conf.d"
+	SupervisordConfigDir   = "/etc/supervisord.d"
+	S6ServicesDir          = "/etc/services.d"
+	S6ContInitDir          = "/etc/cont-init.d"
+	S6RCDir                = "/etc/s6-overlay/s6-rc.d"
+	S6RCUserContentsDir    = "/etc/s6-overlay/s6-rc.d/user/contents.d"
+	RunitServicesDir       = "/etc/sv"
+	RunitActiveServicesDir = "/etc/service"
+	RunitDefaultDir        = "/etc/runit/runsvdir/default"
+)
+
+var sysvRCDirPattern = regexp.MustCompile(`^/etc/(rc\.d/)?rc[0-6sS]\.d$`)
+
+// IsSourceFile returns true if the file (or symlink) is a service definition source
+// (including the service enablement links)
+func IsSourceFile(fullPath string) bool {
+	if IsSystemdSourceFile(fullPath) {
+		return true
+	}
+
+	dir := path.Dir(fullPath)
+	name := path.Base(fullPath)
+
+	switch fullPath {
+	case SupervisordConfigFile, SupervisorConfigFile:
+		return true
+	}
+
+	switch dir {
+	case InitdDir,
+		SupervisorConfigDir,
+		SupervisordConfigDir,
+		S6ContInitDir,
+		S6RCUserContentsDir,
+		RunitActiveServicesDir,
+		RunitDefaultDir:
+		return true
+	}
+
+	if sysvRCDirPattern.MatchString(dir) || path.Dir(dir) == OpenRCRunlevelsDir {
+		return true
+	}
+
+	parent := path.Dir(dir)
+	switch parent {
+	case S6ServicesDir, RunitServicesDir, RunitActiveServicesDir:
+		return name == "run"
+	case S6RCDir:
+		return name == "type" || name == "run" || name == "up"
+	}
+
+	return false
+}
+
+// IsSystemdSourceFile returns true if the file (or symlink) is a systemd unit,
+// a unit drop-in ('NAME.d/*.conf') or a unit enablement link ('TARGET.wants/NAME')
+func IsSystemdSourceFile(fullPath string) bool {
+	dir := path.Dir(fullPath)
+	return isSystemdUnitDir(dir) || isSystemdUnitDir(path.Dir(dir))
+}
+
+func isSystemdUnitDir(dir string) bool {
+	for _, unitDir := range SystemdSystemUnitDirs {
+		if dir == unitDir {
+			return true
+		}
+	}
+
+	for _, unitDir := range SystemdUserUnitDirs {
+		if dir == unitDir {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Report has the service definitions detected in the image
+type Report struct {
+	Services []*Service `json:"services"`
+	Sources  []string   `json:"sources"`
+}
+
+// Service is a service definition
+type Service struct {
+	Type        string   `json:"type"`
+	Name        string   `json:"name"`
+	FilePath    string   `json:"file_path"`
+	Scope       string   `json:"scope,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Command     string   `json:"command,omitempty"`
+	User        string   `json:"user,omitempty"`
+	Enabled     bool     `json:"enabled"`
+	Masked      bool     `json:"masked,omitempty"`
+	Restart     string   `json:"restart,omitempty"`
+	WantedBy    []string `json:"wanted_by,omitempty"`
+	DropIns     []string `json:"drop_ins,omitempty"`
+}
+
+// NewReportFromData creates the service report from the service source files
+// and the symlinks in the image filesystem
+func NewReportFromData(files map[string][]byte, links map[string]string) *Report {
+	report := &Report{
+		Services: []*Service{},
+	}
+
+	sources := map[string]struct{}{}
+	addSource := func(filePath string) {
+		sources[filePath] = struct{}{}
+	}
+
+	report.Services = append(report.Services, systemdServices(files, links, addSource)...)
+	report.Services = append(report.Services, initdServices(files, links, addSource)...)
+	report.Services = append(report.Services, supervisordServices(files, addSource)...)
+	report.Services = append(report.Services, s6Services(files, links, addSource)...)
+	report.Services = append(report.Services, runitServices(files, links, addSource)...)
+
+	for source := range sources {
+		report.Sources = append(report.Sources, source)
+	}
+
+	sort.Strings(report.Sources)
+	return report
+}
+
+// SystemdUnit is a systemd unit file (with the merged drop-ins)
+type SystemdUnit struct {
+	Name     string
+	FilePath string
+	Scope    string
+	Config   *ConfigFile
+	DropIns  []string
+	Enabled  bool
+	Masked   bool
+}
+
+// SystemdUnits returns the systemd units with the unit suffix (e.g., '.service' or '.timer')
+// (the units in the higher priority unit directories override the other units with the same name)
+func SystemdUnits(files map[string][]byte, links map[string]string, suffix string) []*SystemdUnit {
+	var units []*SystemdUnit
+	for _, scopeDirs := range []struct {
+		scope string
+		dirs  []string
+	}{
+		{scope: ScopeSystem, dirs: SystemdSystemUnitDirs},
+		{scope: ScopeUser, dirs: SystemdUserUnitDirs},
+	} {
+		seen := map[string]struct{}{}
+		for _, dir := range scopeDirs.dirs {
+			for _, fpath := range sortedPaths(files, links) {
+				if path.Dir(fpath) != dir || !strings.HasSuffix(fpath, suffix) {
+					continue
+				}
+
+				name := path.Base(fpath)
+				if _, found := seen[name]; found {
+					continue
+				}
+
+				seen[name] = struct{}{}
+				unit := &SystemdUnit{
+					Name:     name,
+					FilePath: fpath,
+					Scope:    scopeDirs.scope,
+				}
+
+				if target, found := links[fpath]; found {
+					if target == "/dev/null" {
+						unit.Masked = true
+						units = append(units, unit)
+						continue
+					}
+
+					//the unit file is a link to another unit file
+					fpath = linkTarget(fpath, target)
+				}
+
+				data, found := files[fpath]
+				if !found {
+					continue
+				}
+
+				unit.Config = ParseUnitData(data)
+				for _, dropinDir := range scopeDirs.dirs {
+					prefix := path.Join(dropinDir, name+".d") + "/"
+					for _, dpath := range sortedPaths(files, nil) {
+						if strings.HasPrefix(dpath, prefix) && strings.HasSuffix(dpath, ".conf") {
+							unit.Config.MergeUnitData(files[dpath])
+							unit.DropIns = append(unit.DropIns, dpath)
+						}
+					}
+				}
+
+				unit.Enabled = isSystemdUnitEnabled(files, links, scopeDirs.dirs, name)
+				units = append(units, unit)
+			}
+		}
+	}
+
+	return units
+}
+
+// isSystemdUnitEnabled returns true if the unit is in one of the '.wants' or '.requires' directories
+func isSystemdUnitEnabled(files map[string][]byte, links map[string]string, dirs []string, name string) bool {
+	check := func(fpath string) bool {
+		parent := path.Dir(fpath)
+		if path.Base(fpath) != name ||
+			!(strings.HasSuffix(parent, ".wants") || strings.HasSuffix(parent, ".requires")) {
+			return false
+		}
+
+		return isSystemdUnitDir(path.Dir(parent))
+	}
+
+	for fpath := range links {
+		if check(fpath) {
+			return true
+		}
+	}
+
+	for fpath := range files {
+		if check(fpath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func systemdServices(files map[string][]byte, links map[string]string, addSource func(string)) []*Service {
+	var services []*Service
+	for _, unit := range SystemdUnits(files, links, ".service") {
+		addSource(unit.FilePath)
+		for _, dropin := range unit.DropIns {
+			addSource(dropin)
+		}
+
+		service := &Service{
+			Type:     TypeSystemd,
+			Name:     strings.TrimSuffix(unit.Name, ".service"),
+			FilePath: unit.FilePath,
+			Scope:    unit.Scope,
+			Enabled:  unit.Enabled,
+			Masked:   unit.Masked,
+			DropIns:  unit.DropIns,
+		}
+
+		if unit.Config != nil {
+			service.Description = unit.Config.Value("Unit", "Description")
+			service.Command = unit.Config.Value("Service", "ExecStart")
+			service.User = unit.Config.Value("Service", "User")
+			service.Restart = unit.Config.Value("Service", "Restart")
+			for _, value := range unit.Config.Values("Install", "WantedBy") {
+				service.WantedBy = append(service.WantedBy, strings.Fields(value)...)
+			}
+		}
+
+		services = append(services, service)
+	}
+
+	return services
+}
+
+var initdSkipNames = map[string]struct{}{
+	"README":    {},
+	"skeleton":  {},
+	"functions": {},
+	"rcS":       {},
+	"rc":        {},
+}
+
+var (
+	lsbDescription    = regexp.MustCompile(`(?m)^#\s*(Short-Description|Description):\s*(.+)$`)
+	openrcDescription = regexp.MustCompile(`(?m)^description="?([^"\n]*)"?`)
+	openrcCommand     = regexp.MustCompile(`(?m)^command="?([^"\n]*)"?`)
+	openrcCommandArgs = regexp.MustCompile(`(?m)^command_args="?([^"\n]*)"?`)
+	openrcCommandUser = regexp.MustCompile(`(?m)^command_user="?([^"\n]*)"?`)
+)
+
+func initdServices(files map[string][]byte, links map[string]string, addSource func(string)) []*Service {
+	enabled := map[string]struct{}{}
+	for _, fpath := range sortedPaths(files, links) {
+		dir := path.Dir(fpath)
+		name := path.Base(fpath)
+		switch {
+		case sysvRCDirPattern.MatchString(dir):
+			//'S20name' start links
+			if len(name) > 3 && name[0] == 'S' {
+				enabled[strings.TrimLeft(name[1:], "0123456789")] = struct{}{}
+				addSource(fpath)
+			}
+		case path.Dir(dir) == OpenRCRunlevelsDir:
+			enabled[name] = struct{}{}
+			addSource(fpath)
+		}
+	}
+
+	var services []*Service
+	for _, fpath := range sortedPaths(files, nil) {
+		name := path.Base(fpath)
+		if path.Dir(fpath) != InitdDir || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if _, found := initdSkipNames[name]; found {
+			continue
+		}
+
+		addSource(fpath)
+		data := files[fpath]
+		service := &Service{
+			Type:     TypeInitd,
+			Name:     name,
+			FilePath: fpath,
+			Command:  fpath,
+		}
+
+		if _, found := enabled[name]; found {
+			service.Enabled = true
+		}
+
+		if m := lsbDescription.FindSubmatch(data); m != nil {
+			service.Description = strings.TrimSpace(string(m[2]))
+		} else if m := openrcDescription.FindSubmatch(data); m != nil {
+			service.Description = strings.TrimSpace(string(m[1]))
+		}
+
+		if m := openrcCommand.FindSubmatch(data); m != nil {
+			service.Command = strings.TrimSpace(string(m[1]))
+			if m := openrcCommandArgs.FindSubmatch(data); m != nil {
+				service.Command = strings.TrimSpace(service.Command + " " + string(m[1]))
+			}
+		}
+
+		if m := openrcCommandUser.FindSubmatch(data); m != nil {
+			service.User = strings.TrimSpace(string(m[1]))
+		}
+
+		services = append(services, service)
+	}
+
+	return services
+}
+
+func supervisordServices(files map[string][]byte, addSource func(string)) []*Service {
+	var services []*Service
+	for _, fpath := range sortedPaths(files, nil) {
+		dir := path.Dir(fpath)
+		if fpath != SupervisordConfigFile &&
+			fpath != SupervisorConfigFile &&
+			dir != SupervisorConfigDir &&
+			dir != SupervisordConfigDir {
+			continue
+		}
+
+		config := ParseSupervisordData(files[fpath])
+		for _, section := range config.Sections {
+			name, found := strings.CutPrefix(section.Name, "program:")
+			if !found {
+				continue
+			}
+
+			addSource(fpath)
+			service := &Service{
+				Type:     TypeSupervisord,
+				Name:     strings.TrimSpace(name),
+				FilePath: fpath,
+				Command:  section.Value("command"),
+				User:     section.Value("user"),
+				Restart:  section.Value("autorestart"),
+				//'autostart' is true by default
+				Enabled: !strings.EqualFold(section.Value("autostart"), "false"),
+			}
+
+			services = append(services, service)
+		}
+	}
+
+	return services
+}
+
+func s6Services(files map[string][]byte, links map[string]string, addSource func(string)) []*Service {
+	var services []*Service
+	for _, fpath := range sortedPaths(files, nil) {
+		dir := path.Dir(fpath)
+		name := path.Base(fpath)
+		switch {
+		case path.Dir(dir) == S6ServicesDir && name == "run":
+			addSource(fpath)
+			services = append(services, &Service{
+				Type:     TypeS6,
+				Name:     path.Base(dir),
+				FilePath: fpath,
+				Command:  runScriptCommand(files[fpath]),
+				Enabled:  true,
+			})
+		case dir == S6ContInitDir:
+			addSource(fpath)
+			services = append(services, &Service{
+				Type:        TypeS6,
+				Name:        name,
+				FilePath:    fpath,
+				Description: "container init script",
+				Command:     fpath,
+				Enabled:     true,
+			})
+		case path.Dir(dir) == S6RCDir && name == "type":
+			serviceType := strings.TrimSpace(string(files[fpath]))
+			var scriptPath string
+			switch serviceType {
+			case "longrun":
+				scriptPath = path.Join(dir, "run")
+			case "oneshot":
+				scriptPath = path.Join(dir, "up")
+			default:
+				//bundles
+				continue
+			}
+
+			addSource(fpath)
+			serviceName := path.Base(dir)
+			service := &Service{
+				Type:        TypeS6,
+				Name:        serviceName,
+				FilePath:    scriptPath,
+				Description: serviceType,
+			}
+
+			if data, found := files[scriptPath]; found {
+				addSource(scriptPath)
+				service.Command = runScriptCommand(data)
+			}
+
+			enabledPath := path.Join(S6RCUserContentsDir, serviceName)
+			if _, found := files[enabledPath]; found {
+				service.Enabled = true
+				addSource(enabledPath)
+			} else if _, found := links[enabledPath]; found {
+				service.Enabled = true
+				addSource(enabledPath)
+			}
+
+			services = append(services, service)
+		}
+	}
+
+	return services
+}
+
+func runitServices(files map[string][]byte, links map[string]string, addSource func(string)) []*Service {
+	active := map[string]struct{}{}
+	for _, fpath := range sortedPaths(files, links) {
+		dir := path.Dir(fpath)
+		switch {
+		case dir == RunitActiveServicesDir || dir == RunitDefaultDir:
+			//'/etc/service/NAME -> /etc/sv/NAME' links
+			active[path.Base(fpath)] = struct{}{}
+			addSource(fpath)
+		case path.Dir(dir) == RunitActiveServicesDir:
+			active[path.Base(dir)] = struct{}{}
+		}
+	}
+
+	var services []*Service
+	seen := map[string]struct{}{}
+	for _, fpath := range sortedPaths(files, nil) {
+		dir := path.Dir(fpath)
+		parent := path.Dir(dir)
+		if path.Base(fpath) != "run" ||
+			(parent != RunitServicesDir && parent != RunitActiveServicesDir) {
+			continue
+		}
+
+		name := path.Base(dir)
+		if _, found := seen[name]; found {
+			continue
+		}
+
+		seen[name] = struct{}{}
+		addSource(fpath)
+
+		_, enabled := active[name]
+		services = append(services, &Service{
+			Type:     TypeRunit,
+			Name:     name,
+			FilePath: fpath,
+			Command:  runScriptCommand(files[fpath]),
+			Enabled:  enabled,
+		})
+	}
+
+	return services
+}
+
+// runScriptCommand returns the command from the s6 or runit run script
+// (the last 'exec' command or the last command line)
+func runScriptCommand(data []byte) string {
+	var lastCommand, lastExec string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lastCommand = line
+		if strings.HasPrefix(line, "exec ") {
+			lastExec = strings.TrimSpace(strings.TrimPrefix(line, "exec "))
+		}
+	}
+
+	if lastExec != "" {
+		return lastExec
+	}
+
+	return lastCommand
+}
+
+func linkTarget(fullPath, target string) string {
+	if path.IsAbs(target) {
+		return path.Clean(target)
+	}
+
+	return path.Join(path.Dir(fullPath), target)
+}
+
+// sortedPaths returns the sorted file and link paths
+func sortedPaths(files map[string][]byte, links map[string]string) []string {
+	var paths []string
+	for fpath := range files {
+		paths = append(paths, fpath)
+	}
+
+	for fpath := range links {
+		if _, found := files[fpath]; !found {
+			paths = append(paths, fpath)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,274 @@
+package sysservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func servicesByName(services []*Service) map[string]*Service {
+	out := map[string]*Service{}
+	for _, service := range services {
+		out[service.Type+":"+service.Name] = service
+	}
+
+	return out
+}
+
+func TestIsSourceFile(t *testing.T) {
+	tt := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/lib/systemd/system/nginx.service", expected: true},
+		{path: "/etc/systemd/system/multi-user.target.wants/nginx.service", expected: true},
+		{path: "/etc/systemd/system/nginx.service.d/override.conf", expected: true},
+		{path: "/usr/lib/systemd/user/app.service", expected: true},
+		{path: "/etc/init.d/nginx", expected: true},
+		{path: "/etc/rc2.d/S01nginx", expected: true},
+		{path: "/etc/rc.d/rc3.d/S20app", expected: true},
+		{path: "/etc/runlevels/default/nginx", expected: true},
+		{path: "/etc/supervisord.conf", expected: true},
+		{path: "/etc/supervisor/conf.d/app.conf", expected: true},
+		{path: "/etc/services.d/app/run", expected: true},
+		{path: "/etc/services.d/app/finish"},
+		{path: "/etc/cont-init.d/10-setup", expected: true},
+		{path: "/etc/s6-overlay/s6-rc.d/app/type", expected: true},
+		{path: "/etc/s6-overlay/s6-rc.d/app/dependencies"},
+		{path: "/etc/sv/app/run", expected: true},
+		{path: "/etc/sv/app/log/run"},
+		{path: "/etc/service/app", expected: true},
+		{path: "/etc/systemd/system.conf"},
+		{path: "/etc/rc7.d/S01nginx"},
+		{path: "/usr/share/doc/nginx/README"},
+	}
+
+	for _, test := range tt {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsSourceFile(test.path))
+		})
+	}
+}
+
+func TestSystemdUnits(t *testing.T) {
+	files := map[string][]byte{
+		"/lib/systemd/system/nginx.service":                  []byte("[Unit]\nDescription=nginx\n[Service]\nExecStart=/usr/sbin/nginx\n[Install]\nWantedBy=multi-user.target graphical.target\n"),
+		"/etc/systemd/system/nginx.service.d/override.conf":  []byte("[Service]\nUser=www-data\nRestart=always\n"),
+		"/etc/systemd/system/nginx.service.d/notes.txt":      []byte("[Service]\nUser=nobody\n"),
+		"/lib/systemd/system/ssh.service":                    []byte("[Service]\nExecStart=/usr/sbin/sshd -D\n"),
+		"/etc/systemd/system/ssh.service":                    []byte("[Service]\nExecStart=/usr/sbin/sshd -D -e\n"),
+		"/lib/systemd/system/alias-target.service":           []byte("[Service]\nExecStart=/usr/bin/aliased\n"),
+		"/usr/lib/systemd/user/agent.service":                []byte("[Service]\nExecStart=/usr/bin/agent\n"),
+		"/lib/systemd/system/multi-user.target.wants/x.conf": []byte(""),
+	}
+
+	links := map[string]string{
+		"/etc/systemd/system/multi-user.target.wants/nginx.service": "/lib/systemd/system/nginx.service",
+		"/etc/systemd/system/getty.service":                         "/dev/null",
+		"/etc/systemd/system/alias.service":                         "../../../lib/systemd/system/alias-target.service",
+		"/etc/systemd/system/broken.service":                        "/missing/broken.service",
+	}
+
+	report := NewReportFromData(files, links)
+	services := servicesByName(report.Services)
+
+	nginx := services["systemd:nginx"]
+	require.NotNil(t, nginx)
+	assert.Equal(t, "/lib/systemd/system/nginx.service", nginx.FilePath)
+	assert.Equal(t, ScopeSystem, nginx.Scope)
+	assert.Equal(t, "nginx", nginx.Description)
+	assert.Equal(t, "/usr/sbin/nginx", nginx.Command)
+	assert.Equal(t, "www-data", nginx.User)
+	assert.Equal(t, "always", nginx.Restart)
+	assert.True(t, nginx.Enabled)
+	assert.Equal(t, []string{"multi-user.target", "graphical.target"}, nginx.WantedBy)
+	assert.Equal(t, []string{"/etc/systemd/system/nginx.service.d/override.conf"}, nginx.DropIns)
+
+	//the /etc units override the /lib units
+	ssh := services["systemd:ssh"]
+	require.NotNil(t, ssh)
+	assert.Equal(t, "/etc/systemd/system/ssh.service", ssh.FilePath)
+	assert.Equal(t, "/usr/sbin/sshd -D -e", ssh.Command)
+	assert.False(t, ssh.Enabled)
+
+	getty := services["systemd:getty"]
+	require.NotNil(t, getty)
+	assert.True(t, getty.Masked)
+	assert.Empty(t, getty.Command)
+
+	alias := services["systemd:alias"]
+	require.NotNil(t, alias)
+	assert.Equal(t, "/usr/bin/aliased", alias.Command)
+
+	agent := services["systemd:agent"]
+	require.NotNil(t, agent)
+	assert.Equal(t, ScopeUser, agent.Scope)
+
+	assert.NotContains(t, services, "systemd:broken")
+	assert.Contains(t, report.Sources, "/etc/systemd/system/nginx.service.d/override.conf")
+	assert.NotContains(t, report.Sources, "/etc/systemd/system/nginx.service.d/notes.txt")
+}
+
+func TestInitdServices(t *testing.T) {
+	files := map[string][]byte{
+		"/etc/init.d/nginx": []byte(`#!/bin/sh
+### BEGIN INIT INFO
+# Provides:          nginx
+# Short-Description: starts the nginx web server
+### END INIT INFO
+`),
+		"/etc/init.d/app": []byte(`#!/sbin/openrc-run
+description="The app service"
+command="/usr/bin/app"
+command_args="--port 8080"
+command_user="app:app"
+`),
+		"/etc/init.d/README":    []byte("readme"),
+		"/etc/init.d/.depend":   []byte(""),
+		"/etc/init.d/skeleton":  []byte("#!/bin/sh"),
+		"/etc/init.d/plain":     []byte("#!/bin/sh\n"),
+		"/etc/init.d/functions": []byte(""),
+	}
+
+	links := map[string]string{
+		"/etc/rc2.d/S01nginx":          "../init.d/nginx",
+		"/etc/rc2.d/K01plain":          "../init.d/plain",
+		"/etc/runlevels/default/app":   "/etc/init.d/app",
+		"/etc/rc2.d/README":            "../init.d/README",
+		"/etc/rc.d/rc3.d/S99unrelated": "../init.d/unrelated",
+	}
+
+	report := NewReportFromData(files, links)
+	require.Len(t, report.Services, 3)
+	services := servicesByName(report.Services)
+
+	nginx := services["initd:nginx"]
+	require.NotNil(t, nginx)
+	assert.Equal(t, "starts the nginx web server", nginx.Description)
+	assert.Equal(t, "/etc/init.d/nginx", nginx.Command)
+	assert.True(t, nginx.Enabled)
+
+	app := services["initd:app"]
+	require.NotNil(t, app)
+	assert.Equal(t, "The app service", app.Description)
+	assert.Equal(t, "/usr/bin/app --port 8080", app.Command)
+	assert.Equal(t, "app:app", app.User)
+	assert.True(t, app.Enabled)
+
+	plain := services["initd:plain"]
+	require.NotNil(t, plain)
+	assert.False(t, plain.Enabled)
+	assert.Empty(t, plain.Description)
+}
+
+func TestSupervisordServices(t *testing.T) {
+	files := map[string][]byte{
+		"/etc/supervisor/conf.d/app.conf": []byte(`[program:web]
+command=/usr/bin/gunicorn app:app ; the web app
+user=web
+autorestart=true
+
+[program:worker]
+command=/usr/bin/celery worker
+autostart=False
+
+[group:all]
+programs=web,worker
+`),
+		"/etc/supervisord.conf": []byte("[supervisord]\nnodaemon=true\n"),
+	}
+
+	report := NewReportFromData(files, nil)
+	assert.Equal(t, []*Service{
+		{
+			Type:     TypeSupervisord,
+			Name:     "web",
+			FilePath: "/etc/supervisor/conf.d/app.conf",
+			Command:  "/usr/bin/gunicorn app:app",
+			User:     "web",
+			Restart:  "true",
+			Enabled:  true,
+		},
+		{
+			Type:     TypeSupervisord,
+			Name:     "worker",
+			FilePath: "/etc/supervisor/conf.d/app.conf",
+			Command:  "/usr/bin/celery worker",
+			Enabled:  false,
+		},
+	}, report.Services)
+	assert.Equal(t, []string{"/etc/supervisor/conf.d/app.conf"}, report.Sources)
+}
+
+func TestS6AndRunitServices(t *testing.T) {
+	files := map[string][]byte{
+		"/etc/services.d/nginx/run":                     []byte("#!/usr/bin/with-contenv sh\n# comment\nexec nginx -g 'daemon off;'\n"),
+		"/etc/cont-init.d/10-setup":                     []byte("#!/bin/sh\n"),
+		"/etc/s6-overlay/s6-rc.d/app/type":              []byte("longrun\n"),
+		"/etc/s6-overlay/s6-rc.d/app/run":               []byte("#!/command/execlineb -P\n/usr/bin/app\n"),
+		"/etc/s6-overlay/s6-rc.d/init/type":             []byte("oneshot\n"),
+		"/etc/s6-overlay/s6-rc.d/init/up":               []byte("/etc/s6-overlay/scripts/init\n"),
+		"/etc/s6-overlay/s6-rc.d/user/type":             []byte("bundle\n"),
+		"/etc/s6-overlay/s6-rc.d/user/contents.d/app":   []byte(""),
+		"/etc/sv/cron/run":                              []byte("#!/bin/sh\nexec 2>&1\nexec crond -f\n"),
+		"/etc/sv/idle/run":                              []byte("#!/bin/sh\nsleep infinity\n"),
+		"/etc/sv/cron/log/run":                          []byte("#!/bin/sh\nexec svlogd -tt /var/log/cron\n"),
+		"/etc/runit/runsvdir/default/.placeholder-file": []byte(""),
+	}
+
+	links := map[string]string{
+		"/etc/service/cron":                          "/etc/sv/cron",
+		"/etc/s6-overlay/s6-rc.d/user/contents.d/xx": "/dev/null",
+	}
+
+	report := NewReportFromData(files, links)
+	services := servicesByName(report.Services)
+	require.Len(t, services, 6)
+
+	assert.Equal(t, "nginx -g 'daemon off;'", services["s6:nginx"].Command)
+	assert.True(t, services["s6:nginx"].Enabled)
+	assert.Equal(t, "/etc/cont-init.d/10-setup", services["s6:10-setup"].Command)
+
+	app := services["s6:app"]
+	require.NotNil(t, app)
+	assert.Equal(t, "/etc/s6-overlay/s6-rc.d/app/run", app.FilePath)
+	assert.Equal(t, "/usr/bin/app", app.Command)
+	assert.Equal(t, "longrun", app.Description)
+	assert.True(t, app.Enabled)
+
+	init := services["s6:init"]
+	require.NotNil(t, init)
+	assert.Equal(t, "/etc/s6-overlay/scripts/init", init.Command)
+	assert.False(t, init.Enabled)
+
+	cron := services["runit:cron"]
+	require.NotNil(t, cron)
+	assert.Equal(t, "crond -f", cron.Command)
+	assert.True(t, cron.Enabled)
+
+	idle := services["runit:idle"]
+	require.NotNil(t, idle)
+	assert.Equal(t, "sleep infinity", idle.Command)
+	assert.False(t, idle.Enabled)
+}
+
+func TestRunScriptCommand(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{name: "exec", data: "#!/bin/sh\nexec /usr/bin/app --flag\n", expected: "/usr/bin/app --flag"},
+		{name: "last exec", data: "exec 2>&1\nexec /usr/bin/app\necho done\n", expected: "/usr/bin/app"},
+		{name: "last command", data: "#!/bin/sh\ncd /app\n./start.sh\n", expected: "./start.sh"},
+		{name: "comments only", data: "#!/bin/sh\n# nothing\n"},
+		{name: "empty"},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, runScriptCommand([]byte(test.data)))
+		})
+	}
+}
@@ -0,0 +1,363 @@
+// Package systask detects the scheduled tasks in the image filesystem
+// (crontabs, periodic script directories, anacron jobs and systemd timers).
+package systask
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/slimtoolkit/slim/pkg/sysservice"
+)
+
+// Task sources
+const (
+	SourceCrontab      = "crontab"
+	SourceUserCrontab  = "crontab.user"
+	SourcePeriodic     = "periodic"
+	SourceAnacron      = "anacron"
+	SourceSystemdTimer = "systemd.timer"
+)
+
+const (
+	SystemCrontabFile  = "/etc/crontab"
+	SystemCrontabDir   = "/etc/cron.d"
+	AnacrontabFile     = "/etc/anacrontab"
+	PeriodicDirPrefix  = "/etc/cron."
+	BusyboxPeriodicDir = "/etc/periodic"
+	systemdTimerSuffix = ".timer"
+)
+
+// UserCrontabDirs are the user crontab directories (the file names are the user names)
+var UserCrontabDirs = []string{
+	"/var/spool/cron/crontabs",
+	"/var/spool/cron",
+	"/etc/crontabs",
+	"/var/spool/cron/tabs",
+}
+
+var periodicSchedules = map[string]string{
+	"hourly":  "@hourly",
+	"daily":   "@daily",
+	"weekly":  "@weekly",
+	"monthly": "@monthly",
+	"yearly":  "@yearly",
+	"15min":   "*/15 * * * *",
+}
+
+// IsSourceFile returns true if the file (or symlink) is a scheduled task source
+func IsSourceFile(fullPath string) bool {
+	if fullPath == SystemCrontabFile || fullPath == AnacrontabFile {
+		return true
+	}
+
+	dir := path.Dir(fullPath)
+	if dir == SystemCrontabDir || isUserCrontabDir(dir) {
+		return true
+	}
+
+	if _, found := periodicSchedule(dir); found {
+		return true
+	}
+
+	//systemd timers need their service units (and the drop-ins and enablement links)
+	if sysservice.IsSystemdSourceFile(fullPath) {
+		return true
+	}
+
+	return false
+}
+
+func isUserCrontabDir(dir string) bool {
+	for _, crontabDir := range UserCrontabDirs {
+		if dir == crontabDir {
+			return true
+		}
+	}
+
+	return false
+}
+
+// periodicSchedule returns the schedule for the periodic script directory
+// ('/etc/cron.daily' or '/etc/periodic/daily')
+func periodicSchedule(dir string) (string, bool) {
+	var period string
+	switch {
+	case strings.HasPrefix(dir, PeriodicDirPrefix) && path.Dir(dir) == "/etc":
+		period = strings.TrimPrefix(dir, PeriodicDirPrefix)
+	case path.Dir(dir) == BusyboxPeriodicDir:
+		period = path.Base(dir)
+	default:
+		return "", false
+	}
+
+	schedule, found := periodicSchedules[period]
+	return schedule, found
+}
+
+// Report has the scheduled tasks detected in the image
+type Report struct {
+	Tasks   []*Task  `json:"tasks"`
+	Sources []string `json:"sources"`
+}
+
+// Task is a scheduled task
+type Task struct {
+	Source   string `json:"source"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line,omitempty"`
+	Schedule string `json:"schedule"`
+	User     string `json:"user,omitempty"`
+	Command  string `json:"command"`
+	Unit     string `json:"unit,omitempty"`
+	Enabled  bool   `json:"enabled"`
+	RawData  string `json:"raw_data,omitempty"`
+}
+
+// NewReportFromData creates the scheduled task report from the task source files
+// and the symlinks in the image filesystem
+func NewReportFromData(files map[string][]byte, links map[string]string) *Report {
+	report := &Report{
+		Tasks: []*Task{},
+	}
+
+	sources := map[string]struct{}{}
+	var paths []string
+	for fpath := range files {
+		paths = append(paths, fpath)
+	}
+
+	sort.Strings(paths)
+	for _, fpath := range paths {
+		dir := path.Dir(fpath)
+		name := path.Base(fpath)
+		var tasks []*Task
+		switch {
+		case fpath == SystemCrontabFile || dir == SystemCrontabDir:
+			if dir == SystemCrontabDir && isIgnoredName(name) {
+				continue
+			}
+
+			tasks = parseCrontab(fpath, files[fpath], SourceCrontab, "")
+		case isUserCrontabDir(dir):
+			if isIgnoredName(name) {
+				continue
+			}
+
+			tasks = parseCrontab(fpath, files[fpath], SourceUserCrontab, name)
+		case fpath == AnacrontabFile:
+			tasks = parseAnacrontab(fpath, files[fpath])
+		default:
+			schedule, found := periodicSchedule(dir)
+			if !found || isIgnoredName(name) {
+				continue
+			}
+
+			//run-parts runs the executable scripts as root
+			tasks = []*Task{
+				{
+					Source:   SourcePeriodic,
+					FilePath: fpath,
+					Schedule: schedule,
+					User:     "root",
+					Command:  fpath,
+					Enabled:  true,
+				},
+			}
+		}
+
+		if len(tasks) > 0 {
+			sources[fpath] = struct{}{}
+			report.Tasks = append(report.Tasks, tasks...)
+		}
+	}
+
+	timerTasks, timerSources := systemdTimerTasks(files, links)
+	report.Tasks = append(report.Tasks, timerTasks...)
+	for _, source := range timerSources {
+		sources[source] = struct{}{}
+	}
+
+	for source := range sources {
+		report.Sources = append(report.Sources, source)
+	}
+
+	sort.Strings(report.Sources)
+	return report
+}
+
+// isIgnoredName returns true for the file names cron and run-parts ignore
+func isIgnoredName(name string) bool {
+	return strings.HasPrefix(name, ".") ||
+		strings.HasSuffix(name, "~") ||
+		strings.Contains(name, ".dpkg-") ||
+		name == "README" ||
+		name == "placeholder"
+}
+
+// parseCrontab parses the crontab data
+// (the system crontabs have the user field and the user crontabs don't)
+func parseCrontab(filePath string, data []byte, source string, user string) []*Task {
+	var tasks []*Task
+	lineNum := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		var scheduleFields int
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields = 1
+		} else {
+			if isEnvAssignment(line) {
+				continue
+			}
+
+			scheduleFields = 5
+		}
+
+		minFields := scheduleFields + 1
+		if user == "" {
+			//the user field
+			minFields++
+		}
+
+		if len(fields) < minFields {
+			continue
+		}
+
+		task := &Task{
+			Source:   source,
+			FilePath: filePath,
+			Line:     lineNum,
+			Schedule: strings.Join(fields[:scheduleFields], " "),
+			User:     user,
+			Enabled:  true,
+			RawData:  line,
+		}
+
+		rest := fields[scheduleFields:]
+		if user == "" {
+			task.User = rest[0]
+			rest = rest[1:]
+		}
+
+		task.Command = strings.Join(rest, " ")
+		tasks = append(tasks, task)
+	}
+
+	return tasks
+}
+
+// isEnvAssignment returns true for the 'NAME=value' crontab lines
+func isEnvAssignment(line string) bool {
+	name, _, found := strings.Cut(line, "=")
+	if !found {
+		return false
+	}
+
+	name = strings.TrimSpace(name)
+	return name != "" && !strings.ContainsAny(name, " \t*/")
+}
+
+// parseAnacrontab parses the anacron job lines ('PERIOD DELAY ID COMMAND')
+func parseAnacrontab(filePath string, data []byte) []*Task {
+	var tasks []*Task
+	lineNum := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || isEnvAssignment(line) {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+
+		schedule := fields[0]
+		if !strings.HasPrefix(schedule, "@") {
+			schedule = fmt.Sprintf("every %s day(s)", schedule)
+		}
+
+		tasks = append(tasks, &Task{
+			Source:   SourceAnacron,
+			FilePath: filePath,
+			Line:     lineNum,
+			Schedule: fmt.Sprintf("%s (delay %s min)", schedule, fields[1]),
+			User:     "root",
+			Command:  strings.Join(fields[3:], " "),
+			Unit:     fields[2],
+			Enabled:  true,
+			RawData:  line,
+		})
+	}
+
+	return tasks
+}
+
+var timerScheduleKeys = []string{
+	"OnCalendar",
+	"OnActiveSec",
+	"OnBootSec",
+	"OnStartupSec",
+	"OnUnitActiveSec",
+	"OnUnitInactiveSec",
+}
+
+// systemdTimerTasks returns the tasks for the systemd timers
+// (the tasks run the timer service commands)
+func systemdTimerTasks(files map[string][]byte, links map[string]string) ([]*Task, []string) {
+	var tasks []*Task
+	var sources []string
+
+	services := map[string]*sysservice.SystemdUnit{}
+	for _, unit := range sysservice.SystemdUnits(files, links, ".service") {
+		services[unit.Scope+":"+unit.Name] = unit
+	}
+
+	for _, unit := range sysservice.SystemdUnits(files, links, systemdTimerSuffix) {
+		sources = append(sources, unit.FilePath)
+		sources = append(sources, unit.DropIns...)
+
+		task := &Task{
+			Source:   SourceSystemdTimer,
+			FilePath: unit.FilePath,
+			Enabled:  unit.Enabled && !unit.Masked,
+			Unit:     strings.TrimSuffix(unit.Name, systemdTimerSuffix) + ".service",
+		}
+
+		if unit.Config != nil {
+			var schedule []string
+			for _, key := range timerScheduleKeys {
+				for _, value := range unit.Config.Values("Timer", key) {
+					schedule = append(schedule, fmt.Sprintf("%s=%s", key, value))
+				}
+			}
+
+			task.Schedule = strings.Join(schedule, " ")
+			if name := unit.Config.Value("Timer", "Unit"); name != "" {
+				task.Unit = name
+			}
+		}
+
+		if service, found := services[unit.Scope+":"+task.Unit]; found && service.Config != nil {
+			sources = append(sources, service.FilePath)
+			task.Command = service.Config.Value("Service", "ExecStart")
+			task.User = service.Config.Value("Service", "User")
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, sources
+}
@@ -0,0 +1,274 @@
+package systask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCrontab(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		source   string
+		user     string
+		expected []*Task
+	}{
+		{
+			name: "system crontab",
+			data: `# /etc/crontab
+SHELL=/bin/sh
+PATH=/usr/local/sbin:/usr/local/bin:/sbin:/bin
+
+17 *	* * *	root    cd / && run-parts --report /etc/cron.hourly
+@reboot www-data /usr/local/bin/warmup --all
+`,
+			source: SourceCrontab,
+			expected: []*Task{
+				{
+					Source:   SourceCrontab,
+					FilePath: "/etc/crontab",
+					Line:     5,
+					Schedule: "17 * * * *",
+					User:     "root",
+					Command:  "cd / && run-parts --report /etc/cron.hourly",
+					Enabled:  true,
+					RawData:  "17 *\t* * *\troot    cd / && run-parts --report /etc/cron.hourly",
+				},
+				{
+					Source:   SourceCrontab,
+					FilePath: "/etc/crontab",
+					Line:     6,
+					Schedule: "@reboot",
+					User:     "www-data",
+					Command:  "/usr/local/bin/warmup --all",
+					Enabled:  true,
+					RawData:  "@reboot www-data /usr/local/bin/warmup --all",
+				},
+			},
+		},
+		{
+			name:   "user crontab",
+			data:   "MAILTO=\"\"\n*/5 * * * * /app/sync.sh >/dev/null 2>&1\n@daily /app/cleanup\n",
+			source: SourceUserCrontab,
+			user:   "app",
+			expected: []*Task{
+				{
+					Source:   SourceUserCrontab,
+					FilePath: "/etc/crontab",
+					Line:     2,
+					Schedule: "*/5 * * * *",
+					User:     "app",
+					Command:  "/app/sync.sh >/dev/null 2>&1",
+					Enabled:  true,
+					RawData:  "*/5 * * * * /app/sync.sh >/dev/null 2>&1",
+				},
+				{
+					Source:   SourceUserCrontab,
+					FilePath: "/etc/crontab",
+					Line:     3,
+					Schedule: "@daily",
+					User:     "app",
+					Command:  "/app/cleanup",
+					Enabled:  true,
+					RawData:  "@daily /app/cleanup",
+				},
+			},
+		},
+		{
+			name: "command with an assignment",
+			data: "0 0 * * * root FOO=bar /app/job\n",
+			expected: []*Task{
+				{
+					Source:   SourceCrontab,
+					FilePath: "/etc/crontab",
+					Line:     1,
+					Schedule: "0 0 * * *",
+					User:     "root",
+					Command:  "FOO=bar /app/job",
+					Enabled:  true,
+					RawData:  "0 0 * * * root FOO=bar /app/job",
+				},
+			},
+			source: SourceCrontab,
+		},
+		{
+			name: "malformed",
+			data: `* * * * *
+0 0 * * * root
+@hourly root
+@hourly
+   # comment
+`,
+			source: SourceCrontab,
+		},
+		{
+			name:   "empty",
+			source: SourceCrontab,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			tasks := parseCrontab("/etc/crontab", []byte(test.data), test.source, test.user)
+			assert.Equal(t, test.expected, tasks)
+		})
+	}
+}
+
+func TestParseAnacrontab(t *testing.T) {
+	data := `# /etc/anacrontab
+SHELL=/bin/sh
+START_HOURS_RANGE=3-22
+
+1	5	cron.daily	run-parts --report /etc/cron.daily
+@monthly 15 cron.monthly run-parts --report /etc/cron.monthly
+7 10 cron.weekly
+`
+
+	tasks := parseAnacrontab(AnacrontabFile, []byte(data))
+	assert.Equal(t, []*Task{
+		{
+			Source:   SourceAnacron,
+			FilePath: AnacrontabFile,
+			Line:     5,
+			Schedule: "every 1 day(s) (delay 5 min)",
+			User:     "root",
+			Command:  "run-parts --report /etc/cron.daily",
+			Unit:     "cron.daily",
+			Enabled:  true,
+			RawData:  "1\t5\tcron.daily\trun-parts --report /etc/cron.daily",
+		},
+		{
+			Source:   SourceAnacron,
+			FilePath: AnacrontabFile,
+			Line:     6,
+			Schedule: "@monthly (delay 15 min)",
+			User:     "root",
+			Command:  "run-parts --report /etc/cron.monthly",
+			Unit:     "cron.monthly",
+			Enabled:  true,
+			RawData:  "@monthly 15 cron.monthly run-parts --report /etc/cron.monthly",
+		},
+	}, tasks)
+}
+
+func TestIsSourceFile(t *testing.T) {
+	tt := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/etc/crontab", expected: true},
+		{path: "/etc/anacrontab", expected: true},
+		{path: "/etc/cron.d/php", expected: true},
+		{path: "/etc/cron.daily/logrotate", expected: true},
+		{path: "/etc/cron.15min/job", expected: true},
+		{path: "/etc/periodic/hourly/job", expected: true},
+		{path: "/var/spool/cron/crontabs/root", expected: true},
+		{path: "/etc/crontabs/root", expected: true},
+		{path: "/lib/systemd/system/apt-daily.timer", expected: true},
+		{path: "/etc/systemd/system/timers.target.wants/apt-daily.timer", expected: true},
+		{path: "/etc/cron.allow"},
+		{path: "/etc/cron.biweekly/job"},
+		{path: "/etc/periodic/daily"},
+		{path: "/etc/cron.d"},
+		{path: "/usr/share/cron/crontab"},
+	}
+
+	for _, test := range tt {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsSourceFile(test.path))
+		})
+	}
+}
+
+func TestNewReportFromData(t *testing.T) {
+	files := map[string][]byte{
+		"/etc/crontab":                           []byte("0 * * * * root /usr/bin/hourly\n"),
+		"/etc/cron.d/php":                        []byte("09,39 * * * * root [ -x /usr/lib/php/sessionclean ] && /usr/lib/php/sessionclean\n"),
+		"/etc/cron.d/.placeholder":               []byte("# placeholder\n"),
+		"/etc/cron.d/php.dpkg-old":               []byte("0 0 * * * root /old\n"),
+		"/etc/cron.d/empty":                      []byte("# nothing\n"),
+		"/etc/crontabs/nobody":                   []byte("@weekly /bin/true\n"),
+		"/etc/cron.daily/logrotate":              []byte("#!/bin/sh\n"),
+		"/etc/cron.daily/README":                 []byte("readme\n"),
+		"/etc/periodic/15min/poll":               []byte("#!/bin/sh\n"),
+		"/lib/systemd/system/apt-daily.timer":    []byte("[Timer]\nOnCalendar=*-*-* 6,18:00\nOnBootSec=15min\n\n[Install]\nWantedBy=timers.target\n"),
+		"/lib/systemd/system/apt-daily.service":  []byte("[Service]\nExecStart=/usr/lib/apt/apt.systemd.daily update\n"),
+		"/lib/systemd/system/backup.timer":       []byte("[Timer]\nOnUnitActiveSec=1h\nUnit=backup-job.service\n"),
+		"/lib/systemd/system/backup-job.service": []byte("[Service]\nUser=backup\nExecStart=/usr/bin/backup\n"),
+	}
+
+	links := map[string]string{
+		"/etc/systemd/system/timers.target.wants/apt-daily.timer": "/lib/systemd/system/apt-daily.timer",
+	}
+
+	report := NewReportFromData(files, links)
+	require.Len(t, report.Tasks, 7)
+
+	type summary struct {
+		source   string
+		filePath string
+		schedule string
+		user     string
+		command  string
+		unit     string
+		enabled  bool
+	}
+
+	var tasks []summary
+	for _, task := range report.Tasks {
+		tasks = append(tasks, summary{
+			source:   task.Source,
+			filePath: task.FilePath,
+			schedule: task.Schedule,
+			user:     task.User,
+			command:  task.Command,
+			unit:     task.Unit,
+			enabled:  task.Enabled,
+		})
+	}
+
+	assert.Equal(t, []summary{
+		{source: SourceCrontab, filePath: "/etc/cron.d/php", schedule: "09,39 * * * *", user: "root", command: "[ -x /usr/lib/php/sessionclean ] && /usr/lib/php/sessionclean", enabled: true},
+		{source: SourcePeriodic, filePath: "/etc/cron.daily/logrotate", schedule: "@daily", user: "root", command: "/etc/cron.daily/logrotate", enabled: true},
+		{source: SourceCrontab, filePath: "/etc/crontab", schedule: "0 * * * *", user: "root", command: "/usr/bin/hourly", enabled: true},
+		{source: SourceUserCrontab, filePath: "/etc/crontabs/nobody", schedule: "@weekly", user: "nobody", command: "/bin/true", enabled: true},
+		{source: SourcePeriodic, filePath: "/etc/periodic/15min/poll", schedule: "*/15 * * * *", user: "root", command: "/etc/periodic/15min/poll", enabled: true},
+		{source: SourceSystemdTimer, filePath: "/lib/systemd/system/apt-daily.timer", schedule: "OnCalendar=*-*-* 6,18:00 OnBootSec=15min", command: "/usr/lib/apt/apt.systemd.daily update", unit: "apt-daily.service", enabled: true},
+		{source: SourceSystemdTimer, filePath: "/lib/systemd/system/backup.timer", schedule: "OnUnitActiveSec=1h", user: "backup", command: "/usr/bin/backup", unit: "backup-job.service"},
+	}, tasks)
+
+	assert.Equal(t, []string{
+		"/etc/cron.d/php",
+		"/etc/cron.daily/logrotate",
+		"/etc/crontab",
+		"/etc/crontabs/nobody",
+		"/etc/periodic/15min/poll",
+		"/lib/systemd/system/apt-daily.service",
+		"/lib/systemd/system/apt-daily.timer",
+		"/lib/systemd/system/backup-job.service",
+		"/lib/systemd/system/backup.timer",
+	}, report.Sources)
+}
+
+func TestNewReportFromDataMaskedTimer(t *testing.T) {
+	files := map[string][]byte{
+		"/lib/systemd/system/fstrim.timer":   []byte("[Timer]\nOnCalendar=weekly\n"),
+		"/lib/systemd/system/fstrim.service": []byte("[Service]\nExecStart=/sbin/fstrim -a\n"),
+	}
+
+	links := map[string]string{
+		"/etc/systemd/system/fstrim.timer":                     "/dev/null",
+		"/etc/systemd/system/timers.target.wants/fstrim.timer": "/lib/systemd/system/fstrim.timer",
+	}
+
+	report := NewReportFromData(files, links)
+	require.Len(t, report.Tasks, 1)
+	task := report.Tasks[0]
+	assert.Equal(t, "/etc/systemd/system/fstrim.timer", task.FilePath)
+	assert.False(t, task.Enabled)
+	assert.Empty(t, task.Schedule)
+	assert.Equal(t, "/sbin/fstrim -a", task.Command)
+}
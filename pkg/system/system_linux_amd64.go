@@ -50,6 +50,14 @@ func CallFourthParam(regs syscall.PtraceRegs) uint64 {
 	return regs.Rcx
 }
 
+func CallFifthParam(regs syscall.PtraceRegs) uint64 {
+	return regs.R8
+}
+
+func CallSixthParam(regs syscall.PtraceRegs) uint64 {
+	return regs.R9
+}
+
 /*
 X86_32 SYSCALL REGISTER USE:
 
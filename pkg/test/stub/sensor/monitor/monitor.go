@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/slimtoolkit/slim/pkg/app/sensor/monitor/fanotify"
+	"github.com/slimtoolkit/slim/pkg/app/sensor/monitor/network"
 	"github.com/slimtoolkit/slim/pkg/app/sensor/monitor/ptrace"
 	"github.com/slimtoolkit/slim/pkg/report"
 )
@@ -76,3 +77,20 @@ func NewPtMonitor(ctx context.Context) *PtMonitorStub {
 func (m *PtMonitorStub) Status() (*report.PtMonitorReport, error) {
 	return nil, nil
 }
+
+// network monitor stub implements network.Monitor
+type NetMonitorStub struct {
+	*monitorStub
+}
+
+var _ network.Monitor = &NetMonitorStub{}
+
+func NewNetMonitor(ctx context.Context) *NetMonitorStub {
+	return &NetMonitorStub{
+		monitorStub: newMonitorStub(ctx),
+	}
+}
+
+func (m *NetMonitorStub) Status() (*report.NetMonitorReport, error) {
+	return nil, nil
+}
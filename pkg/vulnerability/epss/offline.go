@@ -0,0 +1,101 @@
+package epss
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidOfflineData = errors.New("invalid EPSS offline data")
+
+// LoadOfflineScores loads the EPSS scores from the EPSS offline data file
+// (the 'epss_scores-YYYY-MM-DD.csv' file or its '.csv.gz' version)
+// and returns the scores indexed by CVE ID
+func LoadOfflineScores(filePath string) (map[string]*Score, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(filePath, ".gz") {
+		gzReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gzReader.Close()
+
+		reader = gzReader
+	}
+
+	return ReadOfflineScores(reader)
+}
+
+// ReadOfflineScores reads the EPSS offline data CSV records
+// The data starts with a comment line with the model version and the score date
+// ('#model_version:v2023.03.01,score_date:2023-03-06T00:00:00+0000')
+// followed by the 'cve,epss,percentile' records.
+func ReadOfflineScores(input io.Reader) (map[string]*Score, error) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+
+	var date time.Time
+	scores := map[string]*Score{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(record[0], "#") {
+			for _, field := range record {
+				if value, found := strings.CutPrefix(field, "score_date:"); found {
+					if len(value) >= len(time.DateOnly) {
+						date, _ = DateFromString(value[:len(time.DateOnly)])
+					}
+				}
+			}
+
+			continue
+		}
+
+		if len(record) < 3 || record[0] == "cve" {
+			continue
+		}
+
+		epss, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, ErrInvalidOfflineData
+		}
+
+		percentile, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, ErrInvalidOfflineData
+		}
+
+		scores[record[0]] = &Score{
+			CVE: record[0],
+			ScoreData: ScoreData{
+				Date:       date,
+				EPSS:       epss,
+				Percentile: percentile,
+			},
+		}
+	}
+
+	return scores, nil
+}
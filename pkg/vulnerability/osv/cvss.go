@@ -0,0 +1,130 @@
+package osv
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var ErrInvalidCVSSVector = errors.New("invalid CVSS vector")
+
+// CVSS v3 severity ratings
+const (
+	SeverityNone     = "NONE"
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+var cvssV3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// CVSSv3BaseScore calculates the CVSS v3.x base score from the vector string
+// (e.g., 'CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H')
+func CVSSv3BaseScore(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) < 9 || !strings.HasPrefix(parts[0], "CVSS:3") {
+		return 0, ErrInvalidCVSSVector
+	}
+
+	metrics := map[string]string{}
+	for _, part := range parts[1:] {
+		if key, value, found := strings.Cut(part, ":"); found {
+			metrics[key] = value
+		}
+	}
+
+	weight := func(name string) (float64, bool) {
+		value, found := cvssV3Weights[name][metrics[name]]
+		return value, found
+	}
+
+	av, okAV := weight("AV")
+	ac, okAC := weight("AC")
+	ui, okUI := weight("UI")
+	c, okC := weight("C")
+	i, okI := weight("I")
+	a, okA := weight("A")
+	if !okAV || !okAC || !okUI || !okC || !okI || !okA {
+		return 0, ErrInvalidCVSSVector
+	}
+
+	var scopeChanged bool
+	switch metrics["S"] {
+	case "U":
+	case "C":
+		scopeChanged = true
+	default:
+		return 0, ErrInvalidCVSSVector
+	}
+
+	var pr float64
+	switch metrics["PR"] {
+	case "N":
+		pr = 0.85
+	case "L":
+		pr = 0.62
+		if scopeChanged {
+			pr = 0.68
+		}
+	case "H":
+		pr = 0.27
+		if scopeChanged {
+			pr = 0.5
+		}
+	default:
+		return 0, ErrInvalidCVSSVector
+	}
+
+	iss := 1 - ((1 - c) * (1 - i) * (1 - a))
+	var impact float64
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * av * ac * pr * ui
+	if scopeChanged {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp implements the CVSS v3.1 'Roundup' function
+func roundUp(value float64) float64 {
+	intValue := int64(math.Round(value * 100000))
+	if intValue%10000 == 0 {
+		return float64(intValue) / 100000
+	}
+
+	return (math.Floor(float64(intValue)/10000) + 1) / 10
+}
+
+// CVSSv3Rating returns the CVSS v3 qualitative severity rating for the score
+func CVSSv3Rating(score float64) string {
+	switch {
+	case score == 0:
+		return SeverityNone
+	case score < 4:
+		return SeverityLow
+	case score < 7:
+		return SeverityMedium
+	case score < 9:
+		return SeverityHigh
+	}
+
+	return SeverityCritical
+}
@@ -0,0 +1,503 @@
+// Package osv provides a local (file based) vulnerability advisory database
+// using the OSV (Open Source Vulnerability) advisory format.
+//
+// The advisory data can be loaded from individual OSV JSON files,
+// from directories with OSV JSON files or from the OSV ecosystem archives
+// (e.g., https://osv-vulnerabilities.storage.googleapis.com/Debian/all.zip).
+package osv
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/////////////////////////////////////////////////////////////
+//
+// OSV SCHEMA DOCS:
+//
+// https://ossf.github.io/osv-schema/
+//
+/////////////////////////////////////////////////////////////
+
+// Ecosystem names
+const (
+	EcosystemDebian     = "Debian"
+	EcosystemUbuntu     = "Ubuntu"
+	EcosystemAlpine     = "Alpine"
+	EcosystemRedHat     = "Red Hat"
+	EcosystemAlmaLinux  = "AlmaLinux"
+	EcosystemRockyLinux = "Rocky Linux"
+	EcosystemWolfi      = "Wolfi"
+	EcosystemChainguard = "Chainguard"
+	EcosystemNpm        = "npm"
+	EcosystemPyPI       = "PyPI"
+	EcosystemRubyGems   = "RubyGems"
+)
+
+// Range types
+const (
+	RangeSemver    = "SEMVER"
+	RangeEcosystem = "ECOSYSTEM"
+	RangeGit       = "GIT"
+)
+
+// Severity types
+const (
+	SeverityCVSSV2 = "CVSS_V2"
+	SeverityCVSSV3 = "CVSS_V3"
+	SeverityCVSSV4 = "CVSS_V4"
+)
+
+var ErrNoAdvisoryData = errors.New("no advisory data")
+
+type Vulnerability struct {
+	ID               string                 `json:"id"`
+	Modified         string                 `json:"modified,omitempty"`
+	Published        string                 `json:"published,omitempty"`
+	Withdrawn        string                 `json:"withdrawn,omitempty"`
+	Aliases          []string               `json:"aliases,omitempty"`
+	Upstream         []string               `json:"upstream,omitempty"`
+	Related          []string               `json:"related,omitempty"`
+	Summary          string                 `json:"summary,omitempty"`
+	Details          string                 `json:"details,omitempty"`
+	Severity         []Severity             `json:"severity,omitempty"`
+	Affected         []Affected             `json:"affected,omitempty"`
+	References       []Reference            `json:"references,omitempty"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type Affected struct {
+	Package           Package                `json:"package"`
+	Severity          []Severity             `json:"severity,omitempty"`
+	Ranges            []Range                `json:"ranges,omitempty"`
+	Versions          []string               `json:"versions,omitempty"`
+	EcosystemSpecific map[string]interface{} `json:"ecosystem_specific,omitempty"`
+	DatabaseSpecific  map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	PURL      string `json:"purl,omitempty"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Repo   string  `json:"repo,omitempty"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// CVEs returns the CVE IDs for the advisory (the advisory ID, its aliases and its upstream IDs)
+func (ref *Vulnerability) CVEs() []string {
+	var result []string
+	seen := map[string]struct{}{}
+	ids := append([]string{ref.ID}, ref.Aliases...)
+	ids = append(ids, ref.Upstream...)
+	for _, id := range ids {
+		if !strings.HasPrefix(id, "CVE-") {
+			continue
+		}
+
+		if _, found := seen[id]; found {
+			continue
+		}
+
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+
+	return result
+}
+
+// SeverityInfo returns the advisory severity (CVSS severity rating or the database specific severity)
+// and the CVSS base score (if the advisory has a CVSS v3 vector)
+func (ref *Vulnerability) SeverityInfo(affected *Affected) (string, float64) {
+	severities := ref.Severity
+	if affected != nil && len(affected.Severity) > 0 {
+		severities = affected.Severity
+	}
+
+	for _, severity := range severities {
+		if severity.Type != SeverityCVSSV3 {
+			continue
+		}
+
+		if score, err := CVSSv3BaseScore(severity.Score); err == nil {
+			return CVSSv3Rating(score), score
+		}
+	}
+
+	if affected != nil {
+		if value, ok := affected.EcosystemSpecific["severity"].(string); ok && value != "" {
+			return strings.ToUpper(value), 0
+		}
+
+		if value, ok := affected.DatabaseSpecific["severity"].(string); ok && value != "" {
+			return strings.ToUpper(value), 0
+		}
+	}
+
+	if value, ok := ref.DatabaseSpecific["severity"].(string); ok && value != "" {
+		return strings.ToUpper(value), 0
+	}
+
+	return "", 0
+}
+
+type entry struct {
+	vuln     *Vulnerability
+	affected *Affected
+}
+
+// DB is an in-memory OSV advisory database indexed by ecosystem and package name
+type DB struct {
+	entries    map[string][]entry
+	Advisories int
+}
+
+// NewDB creates an empty advisory database
+func NewDB() *DB {
+	return &DB{
+		entries: map[string][]entry{},
+	}
+}
+
+// LoadDB creates an advisory database from the OSV data in the target paths
+// (OSV JSON files, directories with OSV JSON files or OSV zip archives)
+func LoadDB(paths []string) (*DB, error) {
+	db := NewDB()
+	for _, fpath := range paths {
+		if err := db.Load(fpath); err != nil {
+			return nil, err
+		}
+	}
+
+	if db.Advisories == 0 {
+		return nil, ErrNoAdvisoryData
+	}
+
+	return db, nil
+}
+
+// Load adds the OSV data from the target path to the database
+func (ref *DB) Load(target string) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return ref.loadFile(target)
+	}
+
+	return filepath.Walk(target, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(fpath)) {
+		case ".json", ".zip":
+			return ref.loadFile(fpath)
+		}
+
+		return nil
+	})
+}
+
+func (ref *DB) loadFile(fpath string) error {
+	if strings.ToLower(filepath.Ext(fpath)) == ".zip" {
+		return ref.loadArchive(fpath)
+	}
+
+	data, err := os.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+
+	if err := ref.loadData(data); err != nil {
+		return fmt.Errorf("osv: error loading advisory data from %s - %w", fpath, err)
+	}
+
+	return nil
+}
+
+func (ref *DB) loadArchive(fpath string) error {
+	archive, err := zip.OpenReader(fpath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() ||
+			strings.ToLower(filepath.Ext(file.Name)) != ".json" {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+
+		if err := ref.loadData(data); err != nil {
+			log.Debugf("osv.DB.loadArchive: skipping %s/%s - %v", fpath, file.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// loadData loads one advisory or a list of advisories
+func (ref *DB) loadData(data []byte) error {
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) > 0 && data[0] == '[' {
+		var vulns []*Vulnerability
+		if err := json.Unmarshal(data, &vulns); err != nil {
+			return err
+		}
+
+		for _, vuln := range vulns {
+			ref.Add(vuln)
+		}
+
+		return nil
+	}
+
+	var vuln Vulnerability
+	if err := json.Unmarshal(data, &vuln); err != nil {
+		return err
+	}
+
+	ref.Add(&vuln)
+	return nil
+}
+
+// Add adds the advisory to the database (the withdrawn advisories are ignored)
+func (ref *DB) Add(vuln *Vulnerability) {
+	if vuln == nil || vuln.ID == "" || vuln.Withdrawn != "" {
+		return
+	}
+
+	ref.Advisories++
+	for idx := range vuln.Affected {
+		affected := &vuln.Affected[idx]
+		ecosystem, _ := SplitEcosystem(affected.Package.Ecosystem)
+		key := indexKey(ecosystem, affected.Package.Name)
+		ref.entries[key] = append(ref.entries[key], entry{vuln: vuln, affected: affected})
+	}
+}
+
+// Match is an advisory that affects the target package version
+type Match struct {
+	Vulnerability *Vulnerability
+	Affected      *Affected
+	FixedVersion  string
+}
+
+// Lookup returns the advisories that affect the package version in the target ecosystem.
+// The ecosystem can include the release ('Debian:12') to select the release specific advisories.
+func (ref *DB) Lookup(ecosystem, name, version string) []*Match {
+	base, release := SplitEcosystem(ecosystem)
+	var result []*Match
+	seen := map[string]struct{}{}
+	for _, e := range ref.entries[indexKey(base, name)] {
+		_, advisoryRelease := SplitEcosystem(e.affected.Package.Ecosystem)
+		if !releaseMatches(release, advisoryRelease) {
+			continue
+		}
+
+		fixed, affected := isAffected(base, version, e.affected)
+		if !affected {
+			continue
+		}
+
+		if _, found := seen[e.vuln.ID]; found {
+			continue
+		}
+
+		seen[e.vuln.ID] = struct{}{}
+		result = append(result, &Match{
+			Vulnerability: e.vuln,
+			Affected:      e.affected,
+			FixedVersion:  fixed,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Vulnerability.ID < result[j].Vulnerability.ID
+	})
+
+	return result
+}
+
+// SplitEcosystem splits the ecosystem name into the base ecosystem name and its release
+// (e.g., 'Debian:12' -> 'Debian' and '12' or 'Ubuntu:22.04:LTS' -> 'Ubuntu' and '22.04:LTS')
+func SplitEcosystem(ecosystem string) (string, string) {
+	base, release, _ := strings.Cut(ecosystem, ":")
+	return base, release
+}
+
+// releaseMatches checks if the target release matches the advisory release.
+// The advisory releases can have extra qualifiers ('22.04:LTS', 'enterprise_linux:9::appstream'),
+// so it's enough to match one of the advisory release parts.
+func releaseMatches(target, advisory string) bool {
+	if target == "" || advisory == "" || target == advisory {
+		return true
+	}
+
+	for _, part := range strings.Split(advisory, ":") {
+		if part == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+func indexKey(ecosystem, name string) string {
+	return ecosystem + "/" + NormalizeName(ecosystem, name)
+}
+
+// NormalizeName returns the normalized package name for the ecosystem
+func NormalizeName(ecosystem, name string) string {
+	switch ecosystem {
+	case EcosystemPyPI:
+		return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+	}
+
+	return name
+}
+
+// isAffected checks if the version is affected (and returns the fixed version if it's known)
+func isAffected(ecosystem, version string, affected *Affected) (string, bool) {
+	for _, v := range affected.Versions {
+		if v == version {
+			return fixedVersion(ecosystem, version, affected.Ranges), true
+		}
+	}
+
+	for _, r := range affected.Ranges {
+		if r.Type != RangeSemver && r.Type != RangeEcosystem {
+			continue
+		}
+
+		if fixed, ok := rangeAffected(ecosystem, r.Type, version, r.Events); ok {
+			return fixed, true
+		}
+	}
+
+	return "", false
+}
+
+// rangeAffected evaluates the range events (see the OSV schema 'evaluation' section)
+func rangeAffected(ecosystem, rangeType, version string, events []Event) (string, bool) {
+	compare := func(a, b string) int {
+		if rangeType == RangeSemver {
+			return CompareSemver(a, b)
+		}
+
+		return CompareVersions(ecosystem, a, b)
+	}
+
+	eventVersion := func(e Event) string {
+		switch {
+		case e.Introduced != "":
+			return e.Introduced
+		case e.Fixed != "":
+			return e.Fixed
+		case e.LastAffected != "":
+			return e.LastAffected
+		}
+
+		return e.Limit
+	}
+
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		vi, vj := eventVersion(sorted[i]), eventVersion(sorted[j])
+		if sorted[i].Introduced == "0" {
+			return sorted[j].Introduced != "0"
+		}
+
+		if sorted[j].Introduced == "0" {
+			return false
+		}
+
+		return compare(vi, vj) < 0
+	})
+
+	var vulnerable bool
+	var fixed string
+	for _, e := range sorted {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || compare(version, e.Introduced) >= 0 {
+				vulnerable = true
+				fixed = ""
+			}
+		case e.Fixed != "":
+			if compare(version, e.Fixed) >= 0 {
+				vulnerable = false
+			} else if vulnerable && fixed == "" {
+				fixed = e.Fixed
+			}
+		case e.LastAffected != "":
+			if compare(version, e.LastAffected) > 0 {
+				vulnerable = false
+			}
+		case e.Limit != "":
+			if compare(version, e.Limit) >= 0 {
+				vulnerable = false
+			}
+		}
+	}
+
+	return fixed, vulnerable
+}
+
+func fixedVersion(ecosystem, version string, ranges []Range) string {
+	for _, r := range ranges {
+		if fixed, ok := rangeAffected(ecosystem, r.Type, version, r.Events); ok {
+			return fixed
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,672 @@
+package osv
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares the package versions using the ecosystem version rules
+// (returns -1 if a < b, 0 if a == b and 1 if a > b)
+func CompareVersions(ecosystem, a, b string) int {
+	switch ecosystem {
+	case EcosystemDebian, EcosystemUbuntu:
+		return CompareDpkg(a, b)
+	case EcosystemAlpine, EcosystemWolfi, EcosystemChainguard:
+		return CompareApk(a, b)
+	case EcosystemRedHat, EcosystemAlmaLinux, EcosystemRockyLinux:
+		return CompareRpm(a, b)
+	case EcosystemNpm:
+		return CompareSemver(a, b)
+	case EcosystemPyPI:
+		return ComparePEP440(a, b)
+	case EcosystemRubyGems:
+		return CompareGem(a, b)
+	}
+
+	return compareSegments(a, b)
+}
+
+func sign(value int) int {
+	switch {
+	case value < 0:
+		return -1
+	case value > 0:
+		return 1
+	}
+
+	return 0
+}
+
+// splitEpoch splits the "EPOCH:VERSION" value
+func splitEpoch(version string) (int, string) {
+	if prefix, rest, found := strings.Cut(version, ":"); found {
+		if epoch, err := strconv.Atoi(prefix); err == nil {
+			return epoch, rest
+		}
+	}
+
+	return 0, version
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// compareNumbers compares the decimal number strings (of any length)
+func compareNumbers(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return sign(len(a) - len(b))
+	}
+
+	return strings.Compare(a, b)
+}
+
+// CompareDpkg compares the Debian package versions ([EPOCH:]UPSTREAM[-REVISION])
+func CompareDpkg(a, b string) int {
+	epochA, a := splitEpoch(a)
+	epochB, b := splitEpoch(b)
+	if epochA != epochB {
+		return sign(epochA - epochB)
+	}
+
+	upstreamA, revisionA := a, ""
+	if idx := strings.LastIndex(a, "-"); idx >= 0 {
+		upstreamA, revisionA = a[:idx], a[idx+1:]
+	}
+
+	upstreamB, revisionB := b, ""
+	if idx := strings.LastIndex(b, "-"); idx >= 0 {
+		upstreamB, revisionB = b[:idx], b[idx+1:]
+	}
+
+	if result := compareDpkgPart(upstreamA, upstreamB); result != 0 {
+		return result
+	}
+
+	return compareDpkgPart(revisionA, revisionB)
+}
+
+// dpkgOrder returns the sort weight for the non-digit version characters
+// ('~' sorts before everything, even the end of the part, and the letters sort before the non-letters)
+func dpkgOrder(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	}
+
+	return int(c) + 256
+}
+
+func compareDpkgPart(a, b string) int {
+	for a != "" || b != "" {
+		for (a != "" && !isDigit(a[0])) || (b != "" && !isDigit(b[0])) {
+			var ca, cb int
+			if a != "" {
+				ca = dpkgOrder(a[0])
+			}
+
+			if b != "" {
+				cb = dpkgOrder(b[0])
+			}
+
+			if ca != cb {
+				return sign(ca - cb)
+			}
+
+			a, b = a[1:], b[1:]
+		}
+
+		var numA, numB string
+		numA, a = leadingDigits(a)
+		numB, b = leadingDigits(b)
+		if result := compareNumbers(numA, numB); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+func leadingDigits(value string) (string, string) {
+	idx := 0
+	for idx < len(value) && isDigit(value[idx]) {
+		idx++
+	}
+
+	return value[:idx], value[idx:]
+}
+
+func leadingAlpha(value string) (string, string) {
+	idx := 0
+	for idx < len(value) && isAlpha(value[idx]) {
+		idx++
+	}
+
+	return value[:idx], value[idx:]
+}
+
+// CompareRpm compares the RPM package versions ([EPOCH:]VERSION[-RELEASE])
+func CompareRpm(a, b string) int {
+	epochA, a := splitEpoch(a)
+	epochB, b := splitEpoch(b)
+	if epochA != epochB {
+		return sign(epochA - epochB)
+	}
+
+	versionA, releaseA, _ := strings.Cut(a, "-")
+	versionB, releaseB, _ := strings.Cut(b, "-")
+	if result := rpmVerCmp(versionA, versionB); result != 0 {
+		return result
+	}
+
+	if releaseA == "" || releaseB == "" {
+		//the release is not compared if it's not available for one of the versions
+		return 0
+	}
+
+	return rpmVerCmp(releaseA, releaseB)
+}
+
+// rpmVerCmp implements the 'rpmvercmp' version comparison
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	isSeparator := func(c byte) bool {
+		return !isDigit(c) && !isAlpha(c) && c != '~' && c != '^'
+	}
+
+	for a != "" || b != "" {
+		for a != "" && isSeparator(a[0]) {
+			a = a[1:]
+		}
+
+		for b != "" && isSeparator(b[0]) {
+			b = b[1:]
+		}
+
+		//'~' sorts before everything
+		if (a != "" && a[0] == '~') || (b != "" && b[0] == '~') {
+			if a == "" || a[0] != '~' {
+				return 1
+			}
+
+			if b == "" || b[0] != '~' {
+				return -1
+			}
+
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		//'^' sorts after the end of the version, but before everything else
+		if (a != "" && a[0] == '^') || (b != "" && b[0] == '^') {
+			if a == "" {
+				return -1
+			}
+
+			if b == "" {
+				return 1
+			}
+
+			if a[0] != '^' {
+				return 1
+			}
+
+			if b[0] != '^' {
+				return -1
+			}
+
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		var segA, segB string
+		numeric := isDigit(a[0])
+		if numeric {
+			segA, a = leadingDigits(a)
+			segB, b = leadingDigits(b)
+		} else {
+			segA, a = leadingAlpha(a)
+			segB, b = leadingAlpha(b)
+		}
+
+		if segB == "" {
+			//the numeric segments are newer than the alpha segments
+			if numeric {
+				return 1
+			}
+
+			return -1
+		}
+
+		var result int
+		if numeric {
+			result = compareNumbers(segA, segB)
+		} else {
+			result = strings.Compare(segA, segB)
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	}
+
+	return 1
+}
+
+// apkSuffixOrder has the Alpine version suffix weights
+// (the pre-release suffixes sort before the versions without suffixes)
+var apkSuffixOrder = map[string]int{
+	"alpha": -4,
+	"beta":  -3,
+	"pre":   -2,
+	"rc":    -1,
+	"":      0,
+	"cvs":   1,
+	"svn":   2,
+	"git":   3,
+	"hg":    4,
+	"p":     5,
+}
+
+type apkVersion struct {
+	numbers  []string
+	letter   string
+	suffixes []apkSuffix
+	revision string
+}
+
+type apkSuffix struct {
+	name   string
+	number string
+}
+
+func parseApkVersion(version string) apkVersion {
+	var result apkVersion
+	if idx := strings.LastIndex(version, "-r"); idx >= 0 {
+		result.revision = version[idx+2:]
+		version = version[:idx]
+	}
+
+	main, suffixes, _ := strings.Cut(version, "_")
+	for main != "" {
+		var number string
+		number, main = leadingDigits(main)
+		if number == "" {
+			break
+		}
+
+		result.numbers = append(result.numbers, number)
+		if main != "" && main[0] == '.' {
+			main = main[1:]
+			continue
+		}
+
+		result.letter = main
+		break
+	}
+
+	if suffixes != "" {
+		for _, suffix := range strings.Split(suffixes, "_") {
+			name, number := leadingAlpha(suffix)
+			result.suffixes = append(result.suffixes, apkSuffix{name: name, number: number})
+		}
+	}
+
+	return result
+}
+
+// CompareApk compares the Alpine package versions (NUMBERS[LETTER][_SUFFIX[NUMBER]...][-rREVISION])
+func CompareApk(a, b string) int {
+	va := parseApkVersion(a)
+	vb := parseApkVersion(b)
+	for idx := 0; idx < len(va.numbers) || idx < len(vb.numbers); idx++ {
+		if idx >= len(va.numbers) {
+			return -1
+		}
+
+		if idx >= len(vb.numbers) {
+			return 1
+		}
+
+		var result int
+		if idx > 0 && (strings.HasPrefix(va.numbers[idx], "0") || strings.HasPrefix(vb.numbers[idx], "0")) {
+			//the numbers with the leading zeros are compared as fractions
+			result = strings.Compare(strings.TrimRight(va.numbers[idx], "0"), strings.TrimRight(vb.numbers[idx], "0"))
+		} else {
+			result = compareNumbers(va.numbers[idx], vb.numbers[idx])
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	if result := strings.Compare(va.letter, vb.letter); result != 0 {
+		return result
+	}
+
+	for idx := 0; idx < len(va.suffixes) || idx < len(vb.suffixes); idx++ {
+		var sa, sb apkSuffix
+		if idx < len(va.suffixes) {
+			sa = va.suffixes[idx]
+		}
+
+		if idx < len(vb.suffixes) {
+			sb = vb.suffixes[idx]
+		}
+
+		if sa.name != sb.name {
+			return sign(apkSuffixOrder[sa.name] - apkSuffixOrder[sb.name])
+		}
+
+		if result := compareNumbers(sa.number, sb.number); result != 0 {
+			return result
+		}
+	}
+
+	return compareNumbers(va.revision, vb.revision)
+}
+
+// CompareSemver compares the semantic versions (MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD])
+func CompareSemver(a, b string) int {
+	a = strings.TrimPrefix(a, "v")
+	b = strings.TrimPrefix(b, "v")
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+
+	coreA, preA, _ := strings.Cut(a, "-")
+	coreB, preB, _ := strings.Cut(b, "-")
+
+	partsA := strings.Split(coreA, ".")
+	partsB := strings.Split(coreB, ".")
+	for idx := 0; idx < 3; idx++ {
+		var pa, pb string
+		if idx < len(partsA) {
+			pa = partsA[idx]
+		}
+
+		if idx < len(partsB) {
+			pb = partsB[idx]
+		}
+
+		if result := compareNumbers(pa, pb); result != 0 {
+			return result
+		}
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+
+	idsA := strings.Split(preA, ".")
+	idsB := strings.Split(preB, ".")
+	for idx := 0; idx < len(idsA) && idx < len(idsB); idx++ {
+		numA := isNumeric(idsA[idx])
+		numB := isNumeric(idsB[idx])
+		var result int
+		switch {
+		case numA && numB:
+			result = compareNumbers(idsA[idx], idsB[idx])
+		case numA:
+			result = -1
+		case numB:
+			result = 1
+		default:
+			result = strings.Compare(idsA[idx], idsB[idx])
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	return sign(len(idsA) - len(idsB))
+}
+
+func isNumeric(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for idx := 0; idx < len(value); idx++ {
+		if !isDigit(value[idx]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pep440PreOrder has the pre-release phase weights
+var pep440PreOrder = map[string]int{
+	"dev":   -4,
+	"a":     -3,
+	"alpha": -3,
+	"b":     -2,
+	"beta":  -2,
+	"c":     -1,
+	"rc":    -1,
+	"pre":   -1,
+}
+
+type pep440Version struct {
+	epoch   int
+	release []string
+	pre     int //0 - no pre-release
+	preNum  string
+	post    string
+	hasPost bool
+	dev     string
+	hasDev  bool
+}
+
+func parsePEP440(version string) pep440Version {
+	var result pep440Version
+	version = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(version), "v"))
+	version, _, _ = strings.Cut(version, "+")
+	if prefix, rest, found := strings.Cut(version, "!"); found {
+		result.epoch, _ = strconv.Atoi(prefix)
+		version = rest
+	}
+
+	for version != "" {
+		var number string
+		number, version = leadingDigits(version)
+		if number == "" {
+			break
+		}
+
+		result.release = append(result.release, number)
+		if version != "" && version[0] == '.' && len(version) > 1 && isDigit(version[1]) {
+			version = version[1:]
+			continue
+		}
+
+		break
+	}
+
+	for version != "" {
+		version = strings.TrimLeft(version, ".-_")
+		var label, number string
+		label, version = leadingAlpha(version)
+		version = strings.TrimLeft(version, ".-_")
+		number, version = leadingDigits(version)
+		switch {
+		case label == "post" || label == "rev" || label == "r" || (label == "" && number != ""):
+			result.hasPost = true
+			result.post = number
+		case label == "dev":
+			result.hasDev = true
+			result.dev = number
+		case pep440PreOrder[label] != 0:
+			result.pre = pep440PreOrder[label]
+			result.preNum = number
+		default:
+			return result
+		}
+	}
+
+	return result
+}
+
+// ComparePEP440 compares the Python package versions (a simplified PEP 440 comparison)
+func ComparePEP440(a, b string) int {
+	va := parsePEP440(a)
+	vb := parsePEP440(b)
+	if va.epoch != vb.epoch {
+		return sign(va.epoch - vb.epoch)
+	}
+
+	for idx := 0; idx < len(va.release) || idx < len(vb.release); idx++ {
+		var ra, rb string
+		if idx < len(va.release) {
+			ra = va.release[idx]
+		}
+
+		if idx < len(vb.release) {
+			rb = vb.release[idx]
+		}
+
+		if result := compareNumbers(ra, rb); result != 0 {
+			return result
+		}
+	}
+
+	//the dev releases without pre/post release sort before the pre-releases
+	preA, preB := va.pre, vb.pre
+	if va.hasDev && preA == 0 && !va.hasPost {
+		preA = pep440PreOrder["dev"]
+	}
+
+	if vb.hasDev && preB == 0 && !vb.hasPost {
+		preB = pep440PreOrder["dev"]
+	}
+
+	if preA != preB {
+		return sign(preA - preB)
+	}
+
+	if result := compareNumbers(va.preNum, vb.preNum); result != 0 {
+		return result
+	}
+
+	if va.hasPost != vb.hasPost {
+		if va.hasPost {
+			return 1
+		}
+
+		return -1
+	}
+
+	if result := compareNumbers(va.post, vb.post); result != 0 {
+		return result
+	}
+
+	if va.hasDev != vb.hasDev {
+		if va.hasDev {
+			return -1
+		}
+
+		return 1
+	}
+
+	return compareNumbers(va.dev, vb.dev)
+}
+
+// CompareGem compares the RubyGems versions
+// (the segments with letters are pre-release segments and they sort before the numeric segments)
+func CompareGem(a, b string) int {
+	segsA := gemSegments(a)
+	segsB := gemSegments(b)
+	for idx := 0; idx < len(segsA) || idx < len(segsB); idx++ {
+		sa, sb := "0", "0"
+		if idx < len(segsA) {
+			sa = segsA[idx]
+		}
+
+		if idx < len(segsB) {
+			sb = segsB[idx]
+		}
+
+		numA := isNumeric(sa)
+		numB := isNumeric(sb)
+		var result int
+		switch {
+		case numA && numB:
+			result = compareNumbers(sa, sb)
+		case numA:
+			result = 1
+		case numB:
+			result = -1
+		default:
+			result = strings.Compare(sa, sb)
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+// gemSegments splits the gem version into the numeric and the alpha segments
+// (the trailing zero segments are dropped, so '1.0' == '1')
+func gemSegments(version string) []string {
+	var result []string
+	for _, part := range strings.Split(strings.TrimSpace(version), ".") {
+		for part != "" {
+			var seg string
+			if isDigit(part[0]) {
+				seg, part = leadingDigits(part)
+			} else if isAlpha(part[0]) {
+				seg, part = leadingAlpha(part)
+			} else {
+				part = part[1:]
+				continue
+			}
+
+			result = append(result, seg)
+		}
+	}
+
+	for len(result) > 0 && isNumeric(result[len(result)-1]) && strings.Trim(result[len(result)-1], "0") == "" {
+		result = result[:len(result)-1]
+	}
+
+	return result
+}
+
+// compareSegments is the generic version comparison for the ecosystems without the custom rules
+func compareSegments(a, b string) int {
+	return rpmVerCmp(a, b)
+}
@@ -0,0 +1,253 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tt := []struct {
+		ecosystem string
+		a         string
+		b         string
+		expected  int
+	}{
+		//dpkg: epochs, tildes and revisions
+		{ecosystem: EcosystemDebian, a: "1.2.3-1", b: "1.2.3-1", expected: 0},
+		{ecosystem: EcosystemDebian, a: "1:1.0-1", b: "2.0-1", expected: 1},
+		{ecosystem: EcosystemDebian, a: "0:1.0", b: "1.0", expected: 0},
+		{ecosystem: EcosystemDebian, a: "1.0~rc1-1", b: "1.0-1", expected: -1},
+		{ecosystem: EcosystemDebian, a: "1.0~~", b: "1.0~", expected: -1},
+		{ecosystem: EcosystemDebian, a: "1.0-1", b: "1.0-1+deb12u1", expected: -1},
+		{ecosystem: EcosystemDebian, a: "2.36-9+deb12u4", b: "2.36-9+deb12u10", expected: -1},
+		{ecosystem: EcosystemDebian, a: "1.0a", b: "1.0+", expected: -1},
+		{ecosystem: EcosystemDebian, a: "1.10", b: "1.9", expected: 1},
+		{ecosystem: EcosystemDebian, a: "1.001", b: "1.1", expected: 0},
+		{ecosystem: EcosystemUbuntu, a: "2.4.52-1ubuntu4.6", b: "2.4.52-1ubuntu4.10", expected: -1},
+		{ecosystem: EcosystemDebian, a: "1.2-3-4", b: "1.2-3-5", expected: -1},
+		//rpm: epochs, tildes, carets and missing releases
+		{ecosystem: EcosystemRedHat, a: "1:3.0.7-24.el9", b: "3.1.0-1.el9", expected: 1},
+		{ecosystem: EcosystemRedHat, a: "3.0.7-24.el9", b: "3.0.7-25.el9", expected: -1},
+		{ecosystem: EcosystemRockyLinux, a: "3.0.7", b: "3.0.7-25.el9", expected: 0},
+		{ecosystem: EcosystemAlmaLinux, a: "1.0~rc1", b: "1.0", expected: -1},
+		{ecosystem: EcosystemRedHat, a: "1.0^git1", b: "1.0", expected: 1},
+		{ecosystem: EcosystemRedHat, a: "1.0^git1", b: "1.0.1", expected: -1},
+		{ecosystem: EcosystemRedHat, a: "1.0a", b: "1.0.1", expected: -1},
+		{ecosystem: EcosystemRedHat, a: "2.0.1", b: "2.0.a", expected: 1},
+		{ecosystem: EcosystemRedHat, a: "1.0_1", b: "1.0.1", expected: 0},
+		//apk: suffixes, letters and revisions
+		{ecosystem: EcosystemAlpine, a: "1.2.4-r2", b: "1.2.4-r10", expected: -1},
+		{ecosystem: EcosystemAlpine, a: "1.2.4_rc1-r0", b: "1.2.4-r0", expected: -1},
+		{ecosystem: EcosystemAlpine, a: "1.2.4_alpha2", b: "1.2.4_beta1", expected: -1},
+		{ecosystem: EcosystemAlpine, a: "1.2.4_p1", b: "1.2.4", expected: 1},
+		{ecosystem: EcosystemAlpine, a: "1.2.4a", b: "1.2.4", expected: 1},
+		{ecosystem: EcosystemAlpine, a: "1.2", b: "1.2.1", expected: -1},
+		{ecosystem: EcosystemAlpine, a: "1.02", b: "1.1", expected: -1},
+		{ecosystem: EcosystemWolfi, a: "3.1.4-r0", b: "3.1.4-r0", expected: 0},
+		//npm: semver pre-releases and build metadata
+		{ecosystem: EcosystemNpm, a: "1.0.0-alpha", b: "1.0.0", expected: -1},
+		{ecosystem: EcosystemNpm, a: "1.0.0-alpha", b: "1.0.0-alpha.1", expected: -1},
+		{ecosystem: EcosystemNpm, a: "1.0.0-alpha.1", b: "1.0.0-alpha.beta", expected: -1},
+		{ecosystem: EcosystemNpm, a: "1.0.0-beta.2", b: "1.0.0-beta.11", expected: -1},
+		{ecosystem: EcosystemNpm, a: "1.0.0-rc.1", b: "1.0.0-beta.11", expected: 1},
+		{ecosystem: EcosystemNpm, a: "1.0.0+build.1", b: "1.0.0+build.2", expected: 0},
+		{ecosystem: EcosystemNpm, a: "v1.10.0", b: "1.9.0", expected: 1},
+		{ecosystem: EcosystemNpm, a: "1.2", b: "1.2.0", expected: 0},
+		//pypi: pre, post and dev releases
+		{ecosystem: EcosystemPyPI, a: "1.0.dev1", b: "1.0a1", expected: -1},
+		{ecosystem: EcosystemPyPI, a: "1.0a1", b: "1.0b1", expected: -1},
+		{ecosystem: EcosystemPyPI, a: "1.0rc1", b: "1.0", expected: -1},
+		{ecosystem: EcosystemPyPI, a: "1.0", b: "1.0.post1", expected: -1},
+		{ecosystem: EcosystemPyPI, a: "1.0.post1.dev1", b: "1.0.post1", expected: -1},
+		{ecosystem: EcosystemPyPI, a: "1.0a1.dev1", b: "1.0a1", expected: -1},
+		{ecosystem: EcosystemPyPI, a: "1!0.1", b: "2.0", expected: 1},
+		{ecosystem: EcosystemPyPI, a: "1.0-1", b: "1.0.post1", expected: 0},
+		{ecosystem: EcosystemPyPI, a: "1.0.0", b: "1.0", expected: 0},
+		{ecosystem: EcosystemPyPI, a: "2.0+local.1", b: "2.0", expected: 0},
+		{ecosystem: EcosystemPyPI, a: "1.0RC1", b: "1.0c1", expected: 0},
+		//rubygems: pre-release segments
+		{ecosystem: EcosystemRubyGems, a: "1.0.0.pre", b: "1.0.0", expected: -1},
+		{ecosystem: EcosystemRubyGems, a: "1.0.0.rc1", b: "1.0.0.beta2", expected: 1},
+		{ecosystem: EcosystemRubyGems, a: "1.0", b: "1", expected: 0},
+		{ecosystem: EcosystemRubyGems, a: "1.10", b: "1.9.9", expected: 1},
+		//other ecosystems
+		{ecosystem: "Go", a: "1.2.10", b: "1.2.9", expected: 1},
+		{ecosystem: "Go", a: "1.2", b: "1.2", expected: 0},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, CompareVersions(test.ecosystem, test.a, test.b),
+			"%s: %s <=> %s", test.ecosystem, test.a, test.b)
+		assert.Equal(t, -test.expected, CompareVersions(test.ecosystem, test.b, test.a),
+			"%s: %s <=> %s", test.ecosystem, test.b, test.a)
+	}
+}
+
+func TestCompareSemverGoPseudoVersions(t *testing.T) {
+	tt := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "v0.0.0-20210101000000-abcdef123456", b: "v0.0.0-20220101000000-123456abcdef", expected: -1},
+		{a: "v0.0.0-20210101000000-abcdef123456", b: "v0.1.0", expected: -1},
+		//pseudo-version for a commit after v1.2.3 (before v1.2.4)
+		{a: "v1.2.4-0.20210101000000-abcdef123456", b: "v1.2.3", expected: 1},
+		{a: "v1.2.4-0.20210101000000-abcdef123456", b: "v1.2.4", expected: -1},
+		{a: "v1.2.4-0.20210101000000-abcdef123456", b: "v1.2.4-0.20200101000000-abcdef123456", expected: 1},
+		//pseudo-version for a commit after the v1.2.4-pre pre-release
+		{a: "v1.2.4-pre.0.20210101000000-abcdef123456", b: "v1.2.4-pre", expected: 1},
+		{a: "v2.0.0+incompatible", b: "v2.0.0", expected: 0},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, CompareSemver(test.a, test.b), "%s <=> %s", test.a, test.b)
+		assert.Equal(t, -test.expected, CompareSemver(test.b, test.a), "%s <=> %s", test.b, test.a)
+	}
+}
+
+func TestRangeAffected(t *testing.T) {
+	tt := []struct {
+		name      string
+		ecosystem string
+		rangeType string
+		version   string
+		events    []Event
+		fixed     string
+		affected  bool
+	}{
+		{
+			name:      "introduced zero, fixed",
+			ecosystem: EcosystemDebian,
+			rangeType: RangeEcosystem,
+			version:   "1.0-1",
+			events:    []Event{{Introduced: "0"}, {Fixed: "1.0-2"}},
+			fixed:     "1.0-2",
+			affected:  true,
+		},
+		{
+			name:      "fixed version",
+			ecosystem: EcosystemDebian,
+			rangeType: RangeEcosystem,
+			version:   "1.0-2",
+			events:    []Event{{Introduced: "0"}, {Fixed: "1.0-2"}},
+		},
+		{
+			name:      "before introduced",
+			ecosystem: EcosystemDebian,
+			rangeType: RangeEcosystem,
+			version:   "0.9-1",
+			events:    []Event{{Introduced: "1.0-1"}, {Fixed: "1.0-2"}},
+		},
+		{
+			name:      "epoch after fixed",
+			ecosystem: EcosystemDebian,
+			rangeType: RangeEcosystem,
+			version:   "1:0.5-1",
+			events:    []Event{{Introduced: "0"}, {Fixed: "1.0-2"}},
+		},
+		{
+			name:      "tilde pre-release before fixed",
+			ecosystem: EcosystemDebian,
+			rangeType: RangeEcosystem,
+			version:   "1.0-2~bpo1",
+			events:    []Event{{Introduced: "0"}, {Fixed: "1.0-2"}},
+			fixed:     "1.0-2",
+			affected:  true,
+		},
+		{
+			name:      "unsorted events, second range",
+			ecosystem: EcosystemNpm,
+			rangeType: RangeSemver,
+			version:   "2.1.0",
+			events: []Event{
+				{Fixed: "2.2.0"},
+				{Introduced: "2.0.0"},
+				{Fixed: "1.5.0"},
+				{Introduced: "1.0.0"},
+			},
+			fixed:    "2.2.0",
+			affected: true,
+		},
+		{
+			name:      "between ranges",
+			ecosystem: EcosystemNpm,
+			rangeType: RangeSemver,
+			version:   "1.7.0",
+			events: []Event{
+				{Introduced: "1.0.0"},
+				{Fixed: "1.5.0"},
+				{Introduced: "2.0.0"},
+				{Fixed: "2.2.0"},
+			},
+		},
+		{
+			name:      "semver pre-release before introduced",
+			ecosystem: EcosystemNpm,
+			rangeType: RangeSemver,
+			version:   "2.0.0-rc.1",
+			events:    []Event{{Introduced: "2.0.0"}, {Fixed: "2.2.0"}},
+		},
+		{
+			name:      "last affected",
+			ecosystem: EcosystemAlpine,
+			rangeType: RangeEcosystem,
+			version:   "1.2.4-r2",
+			events:    []Event{{Introduced: "0"}, {LastAffected: "1.2.4-r2"}},
+			affected:  true,
+		},
+		{
+			name:      "after last affected",
+			ecosystem: EcosystemAlpine,
+			rangeType: RangeEcosystem,
+			version:   "1.2.4-r3",
+			events:    []Event{{Introduced: "0"}, {LastAffected: "1.2.4-r2"}},
+		},
+		{
+			name:      "limit",
+			ecosystem: EcosystemPyPI,
+			rangeType: RangeEcosystem,
+			version:   "2.0",
+			events:    []Event{{Introduced: "1.0"}, {Limit: "2.0"}},
+		},
+		{
+			name:      "below limit",
+			ecosystem: EcosystemPyPI,
+			rangeType: RangeEcosystem,
+			version:   "2.0rc1",
+			events:    []Event{{Introduced: "1.0"}, {Limit: "2.0"}},
+			affected:  true,
+		},
+		{
+			name:      "go pseudo-version",
+			ecosystem: "Go",
+			rangeType: RangeSemver,
+			version:   "v0.0.0-20210101000000-abcdef123456",
+			events:    []Event{{Introduced: "0"}, {Fixed: "0.1.0"}},
+			fixed:     "0.1.0",
+			affected:  true,
+		},
+		{
+			name:      "rpm epoch",
+			ecosystem: EcosystemRedHat,
+			rangeType: RangeEcosystem,
+			version:   "1:3.0.7-24.el9",
+			events:    []Event{{Introduced: "0"}, {Fixed: "1:3.0.7-25.el9"}},
+			fixed:     "1:3.0.7-25.el9",
+			affected:  true,
+		},
+		{
+			name:      "no events",
+			ecosystem: EcosystemDebian,
+			rangeType: RangeEcosystem,
+			version:   "1.0",
+		},
+	}
+
+	for _, test := range tt {
+		fixed, affected := rangeAffected(test.ecosystem, test.rangeType, test.version, test.events)
+		assert.Equal(t, test.affected, affected, test.name)
+		assert.Equal(t, test.fixed, fixed, test.name)
+	}
+}
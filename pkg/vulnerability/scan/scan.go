@@ -0,0 +1,326 @@
+// Package scan matches the image packages (from the image package inventory)
+// with the vulnerability advisories in the local advisory database.
+package scan
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/slimtoolkit/slim/pkg/sbom"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/epss"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/osv"
+)
+
+// Finding is a vulnerability advisory that affects an image package
+type Finding struct {
+	ID              string   `json:"id"`
+	CVEs            []string `json:"cves,omitempty"`
+	Summary         string   `json:"summary,omitempty"`
+	Severity        string   `json:"severity,omitempty"`
+	CVSSScore       float64  `json:"cvss_score,omitempty"`
+	EPSS            float64  `json:"epss,omitempty"`
+	EPSSPercentile  float64  `json:"epss_percentile,omitempty"`
+	PackageType     string   `json:"package_type"`
+	PackageName     string   `json:"package_name"`
+	PackageVersion  string   `json:"package_version"`
+	PackageLocation string   `json:"package_location,omitempty"`
+	FixedVersion    string   `json:"fixed_version,omitempty"`
+}
+
+// Key returns the finding key (the advisory and the affected package version)
+func (ref *Finding) Key() string {
+	return strings.Join([]string{ref.ID, ref.PackageType, ref.PackageName, ref.PackageVersion}, "|")
+}
+
+// Result is the vulnerability scan result for an image
+type Result struct {
+	Image        string       `json:"image,omitempty"`
+	ImageID      string       `json:"image_id,omitempty"`
+	Distro       *sbom.Distro `json:"distro,omitempty"`
+	OSEcosystem  string       `json:"os_ecosystem,omitempty"`
+	PackageCount int          `json:"package_count"`
+	Findings     []*Finding   `json:"findings"`
+}
+
+// Comparison is the vulnerability scan result comparison for the original and minified images
+type Comparison struct {
+	//Removed are the findings for the packages that are not in the minified image
+	Removed []*Finding `json:"removed"`
+	//Remaining are the findings that are still in the minified image
+	Remaining []*Finding `json:"remaining"`
+}
+
+// Scan matches the packages in the package report with the advisory database
+func Scan(db *osv.DB, pkgReport *sbom.Report) *Result {
+	result := &Result{
+		Findings: []*Finding{},
+	}
+
+	if pkgReport == nil {
+		return result
+	}
+
+	result.Distro = pkgReport.Distro
+	result.OSEcosystem = OSEcosystem(pkgReport.Distro)
+	result.PackageCount = len(pkgReport.Packages)
+
+	for _, pkg := range pkgReport.Packages {
+		ecosystem := packageEcosystem(pkg, result.OSEcosystem)
+		if ecosystem == "" {
+			continue
+		}
+
+		seen := map[string]struct{}{}
+		for _, name := range packageNames(pkg) {
+			for _, match := range db.Lookup(ecosystem, name, pkg.Version) {
+				if _, found := seen[match.Vulnerability.ID]; found {
+					continue
+				}
+
+				seen[match.Vulnerability.ID] = struct{}{}
+				result.Findings = append(result.Findings, newFinding(pkg, match))
+			}
+		}
+	}
+
+	sortFindings(result.Findings)
+	return result
+}
+
+func newFinding(pkg *sbom.Package, match *osv.Match) *Finding {
+	severity, score := match.Vulnerability.SeverityInfo(match.Affected)
+	return &Finding{
+		ID:              match.Vulnerability.ID,
+		CVEs:            match.Vulnerability.CVEs(),
+		Summary:         match.Vulnerability.Summary,
+		Severity:        severity,
+		CVSSScore:       score,
+		PackageType:     pkg.Type,
+		PackageName:     pkg.Name,
+		PackageVersion:  pkg.Version,
+		PackageLocation: pkg.Location,
+		FixedVersion:    match.FixedVersion,
+	}
+}
+
+var severityOrder = map[string]int{
+	osv.SeverityCritical: 5,
+	osv.SeverityHigh:     4,
+	"IMPORTANT":          4,
+	osv.SeverityMedium:   3,
+	"MODERATE":           3,
+	osv.SeverityLow:      2,
+	"NEGLIGIBLE":         1,
+}
+
+// SeverityRank returns the sort rank for the severity value (higher is more severe)
+func SeverityRank(severity string) int {
+	return severityOrder[strings.ToUpper(severity)]
+}
+
+// sortFindings sorts the findings by severity, EPSS score, advisory ID and package name
+func sortFindings(findings []*Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if ra, rb := SeverityRank(a.Severity), SeverityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+
+		if a.EPSS != b.EPSS {
+			return a.EPSS > b.EPSS
+		}
+
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+
+		return a.PackageName < b.PackageName
+	})
+}
+
+// OSEcosystem returns the OSV ecosystem (with the release) for the distro
+func OSEcosystem(distro *sbom.Distro) string {
+	if distro == nil {
+		return ""
+	}
+
+	major, _, _ := strings.Cut(distro.VersionID, ".")
+	withRelease := func(name, release string) string {
+		if release == "" {
+			return name
+		}
+
+		return name + ":" + release
+	}
+
+	switch distro.ID {
+	case "debian":
+		return withRelease(osv.EcosystemDebian, major)
+	case "ubuntu":
+		return withRelease(osv.EcosystemUbuntu, distro.VersionID)
+	case "alpine":
+		parts := strings.Split(distro.VersionID, ".")
+		if len(parts) >= 2 {
+			return withRelease(osv.EcosystemAlpine, "v"+parts[0]+"."+parts[1])
+		}
+
+		return osv.EcosystemAlpine
+	case "rhel":
+		return withRelease(osv.EcosystemRedHat, major)
+	case "almalinux":
+		return withRelease(osv.EcosystemAlmaLinux, major)
+	case "rocky":
+		return withRelease(osv.EcosystemRockyLinux, major)
+	case "wolfi":
+		return osv.EcosystemWolfi
+	case "chainguard":
+		return osv.EcosystemChainguard
+	}
+
+	return ""
+}
+
+// packageEcosystem returns the OSV ecosystem for the package
+// (the OS packages are matched only if their type matches the OS ecosystem)
+func packageEcosystem(pkg *sbom.Package, osEcosystem string) string {
+	base, _ := osv.SplitEcosystem(osEcosystem)
+	switch pkg.Type {
+	case sbom.TypeDeb:
+		if base == osv.EcosystemDebian || base == osv.EcosystemUbuntu {
+			return osEcosystem
+		}
+	case sbom.TypeApk:
+		if base == osv.EcosystemAlpine || base == osv.EcosystemWolfi || base == osv.EcosystemChainguard {
+			return osEcosystem
+		}
+	case sbom.TypeRpm:
+		if base == osv.EcosystemRedHat || base == osv.EcosystemAlmaLinux || base == osv.EcosystemRockyLinux {
+			return osEcosystem
+		}
+	case sbom.TypeNpm:
+		return osv.EcosystemNpm
+	case sbom.TypePyPI:
+		return osv.EcosystemPyPI
+	case sbom.TypeGem:
+		return osv.EcosystemRubyGems
+	}
+
+	return ""
+}
+
+// packageNames returns the package names to use for the advisory lookups
+// (the distro advisories usually use the source package names)
+func packageNames(pkg *sbom.Package) []string {
+	var source string
+	switch pkg.Type {
+	case sbom.TypeDeb, sbom.TypeApk:
+		source = pkg.Source
+	case sbom.TypeRpm:
+		source = rpmSourceName(pkg.Source)
+	}
+
+	if source != "" && source != pkg.Name {
+		return []string{source, pkg.Name}
+	}
+
+	return []string{pkg.Name}
+}
+
+// rpmSourceName returns the source package name from the source RPM file name
+// ('NAME-VERSION-RELEASE.src.rpm')
+func rpmSourceName(srpm string) string {
+	name := strings.TrimSuffix(srpm, ".src.rpm")
+	if name == srpm {
+		return ""
+	}
+
+	for i := 0; i < 2; i++ {
+		idx := strings.LastIndex(name, "-")
+		if idx <= 0 {
+			return ""
+		}
+
+		name = name[:idx]
+	}
+
+	return name
+}
+
+// CVEs returns the unique CVE IDs for the findings
+func (ref *Result) CVEs() []string {
+	var result []string
+	seen := map[string]struct{}{}
+	for _, finding := range ref.Findings {
+		for _, cve := range finding.CVEs {
+			if _, found := seen[cve]; found {
+				continue
+			}
+
+			seen[cve] = struct{}{}
+			result = append(result, cve)
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// ApplyEPSS adds the EPSS scores to the findings
+// (the highest score is used if the finding has multiple CVEs)
+func (ref *Result) ApplyEPSS(scores map[string]*epss.Score) {
+	for _, finding := range ref.Findings {
+		for _, cve := range finding.CVEs {
+			score, found := scores[cve]
+			if !found || score.EPSS < finding.EPSS {
+				continue
+			}
+
+			finding.EPSS = score.EPSS
+			finding.EPSSPercentile = score.Percentile
+		}
+	}
+
+	sortFindings(ref.Findings)
+}
+
+// SeverityCounts returns the number of findings for each severity
+func (ref *Result) SeverityCounts() map[string]int {
+	counts := map[string]int{}
+	for _, finding := range ref.Findings {
+		severity := finding.Severity
+		if severity == "" {
+			severity = "UNKNOWN"
+		}
+
+		counts[severity]++
+	}
+
+	return counts
+}
+
+// Compare compares the original and minified image scan results
+func Compare(original, minified *Result) *Comparison {
+	result := &Comparison{
+		Removed:   []*Finding{},
+		Remaining: []*Finding{},
+	}
+
+	if original == nil || minified == nil {
+		return result
+	}
+
+	minifiedKeys := map[string]struct{}{}
+	for _, finding := range minified.Findings {
+		minifiedKeys[finding.Key()] = struct{}{}
+	}
+
+	for _, finding := range original.Findings {
+		if _, found := minifiedKeys[finding.Key()]; found {
+			result.Remaining = append(result.Remaining, finding)
+		} else {
+			result.Removed = append(result.Removed, finding)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,237 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slimtoolkit/slim/pkg/sbom"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/epss"
+	"github.com/slimtoolkit/slim/pkg/vulnerability/osv"
+)
+
+func testAdvisoryDB() *osv.DB {
+	db := osv.NewDB()
+	db.Add(&osv.Vulnerability{
+		ID:      "DSA-0001-1",
+		Aliases: []string{"CVE-2024-0001"},
+		Affected: []osv.Affected{
+			{
+				Package: osv.Package{Ecosystem: "Debian:12", Name: "glibc"},
+				Ranges: []osv.Range{
+					{Type: osv.RangeEcosystem, Events: []osv.Event{{Introduced: "0"}, {Fixed: "2.36-9+deb12u5"}}},
+				},
+				EcosystemSpecific: map[string]interface{}{"severity": "high"},
+			},
+			{
+				Package: osv.Package{Ecosystem: "Debian:11", Name: "glibc"},
+				Ranges: []osv.Range{
+					{Type: osv.RangeEcosystem, Events: []osv.Event{{Introduced: "0"}, {Fixed: "2.31-13+deb11u9"}}},
+				},
+			},
+		},
+	})
+
+	db.Add(&osv.Vulnerability{
+		ID:       "GHSA-0002",
+		Aliases:  []string{"CVE-2024-0002"},
+		Severity: []osv.Severity{{Type: osv.SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+		Affected: []osv.Affected{
+			{
+				Package: osv.Package{Ecosystem: osv.EcosystemNpm, Name: "lodash"},
+				Ranges: []osv.Range{
+					{Type: osv.RangeSemver, Events: []osv.Event{{Introduced: "4.0.0"}, {Fixed: "4.17.21"}}},
+				},
+			},
+		},
+	})
+
+	db.Add(&osv.Vulnerability{
+		ID:      "RLSA-0003",
+		Aliases: []string{"CVE-2024-0001"},
+		Affected: []osv.Affected{
+			{
+				Package:  osv.Package{Ecosystem: "Rocky Linux:9", Name: "openssl"},
+				Versions: []string{"1:3.0.7-24.el9"},
+			},
+		},
+		DatabaseSpecific: map[string]interface{}{"severity": "moderate"},
+	})
+
+	db.Add(&osv.Vulnerability{
+		ID:        "DSA-0004-1",
+		Withdrawn: "2024-01-01T00:00:00Z",
+		Affected: []osv.Affected{
+			{
+				Package: osv.Package{Ecosystem: "Debian:12", Name: "libc6"},
+				Ranges: []osv.Range{
+					{Type: osv.RangeEcosystem, Events: []osv.Event{{Introduced: "0"}}},
+				},
+			},
+		},
+	})
+
+	return db
+}
+
+func TestScan(t *testing.T) {
+	db := testAdvisoryDB()
+	pkgReport := &sbom.Report{
+		Distro: &sbom.Distro{ID: "debian", VersionID: "12"},
+		Packages: []*sbom.Package{
+			{Type: sbom.TypeDeb, Name: "libc6", Version: "2.36-9+deb12u4", Source: "glibc", Location: "/var/lib/dpkg/status"},
+			{Type: sbom.TypeDeb, Name: "libc-bin", Version: "2.36-9+deb12u5", Source: "glibc"},
+			{Type: sbom.TypeNpm, Name: "lodash", Version: "4.17.20", Location: "/app/node_modules/lodash/package.json"},
+			{Type: sbom.TypeNpm, Name: "lodash", Version: "4.17.21"},
+			//the rpm packages are not matched in the debian images
+			{Type: sbom.TypeRpm, Name: "openssl", Version: "1:3.0.7-24.el9"},
+		},
+	}
+
+	result := Scan(db, pkgReport)
+	assert.Equal(t, "Debian:12", result.OSEcosystem)
+	assert.Equal(t, 5, result.PackageCount)
+	require.Len(t, result.Findings, 2)
+
+	//sorted by severity
+	assert.Equal(t, &Finding{
+		ID:              "GHSA-0002",
+		CVEs:            []string{"CVE-2024-0002"},
+		Severity:        osv.SeverityCritical,
+		CVSSScore:       9.8,
+		PackageType:     sbom.TypeNpm,
+		PackageName:     "lodash",
+		PackageVersion:  "4.17.20",
+		PackageLocation: "/app/node_modules/lodash/package.json",
+		FixedVersion:    "4.17.21",
+	}, result.Findings[0])
+
+	assert.Equal(t, &Finding{
+		ID:              "DSA-0001-1",
+		CVEs:            []string{"CVE-2024-0001"},
+		Severity:        osv.SeverityHigh,
+		PackageType:     sbom.TypeDeb,
+		PackageName:     "libc6",
+		PackageVersion:  "2.36-9+deb12u4",
+		PackageLocation: "/var/lib/dpkg/status",
+		FixedVersion:    "2.36-9+deb12u5",
+	}, result.Findings[1])
+
+	assert.Equal(t, []string{"CVE-2024-0001", "CVE-2024-0002"}, result.CVEs())
+	assert.Equal(t, map[string]int{osv.SeverityCritical: 1, osv.SeverityHigh: 1}, result.SeverityCounts())
+
+	rpmResult := Scan(db, &sbom.Report{
+		Distro: &sbom.Distro{ID: "rocky", VersionID: "9.3"},
+		Packages: []*sbom.Package{
+			{Type: sbom.TypeRpm, Name: "openssl-libs", Version: "1:3.0.7-24.el9", Source: "openssl-3.0.7-24.el9.src.rpm"},
+		},
+	})
+
+	require.Len(t, rpmResult.Findings, 1)
+	assert.Equal(t, "RLSA-0003", rpmResult.Findings[0].ID)
+	assert.Equal(t, "MODERATE", rpmResult.Findings[0].Severity)
+
+	empty := Scan(db, nil)
+	assert.NotNil(t, empty.Findings)
+	assert.Empty(t, empty.Findings)
+}
+
+func TestOSEcosystem(t *testing.T) {
+	tt := []struct {
+		distro   *sbom.Distro
+		expected string
+	}{
+		{distro: &sbom.Distro{ID: "debian", VersionID: "12"}, expected: "Debian:12"},
+		{distro: &sbom.Distro{ID: "debian"}, expected: "Debian"},
+		{distro: &sbom.Distro{ID: "ubuntu", VersionID: "22.04"}, expected: "Ubuntu:22.04"},
+		{distro: &sbom.Distro{ID: "alpine", VersionID: "3.19.1"}, expected: "Alpine:v3.19"},
+		{distro: &sbom.Distro{ID: "alpine", VersionID: "edge"}, expected: "Alpine"},
+		{distro: &sbom.Distro{ID: "rhel", VersionID: "9.3"}, expected: "Red Hat:9"},
+		{distro: &sbom.Distro{ID: "almalinux", VersionID: "8.9"}, expected: "AlmaLinux:8"},
+		{distro: &sbom.Distro{ID: "rocky", VersionID: "9.3"}, expected: "Rocky Linux:9"},
+		{distro: &sbom.Distro{ID: "wolfi", VersionID: "20230201"}, expected: "Wolfi"},
+		{distro: &sbom.Distro{ID: "chainguard"}, expected: "Chainguard"},
+		{distro: &sbom.Distro{ID: "arch"}},
+		{},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, OSEcosystem(test.distro), "%+v", test.distro)
+	}
+}
+
+func TestPackageNames(t *testing.T) {
+	tt := []struct {
+		pkg      *sbom.Package
+		expected []string
+	}{
+		{pkg: &sbom.Package{Type: sbom.TypeDeb, Name: "libc6", Source: "glibc"}, expected: []string{"glibc", "libc6"}},
+		{pkg: &sbom.Package{Type: sbom.TypeDeb, Name: "bash", Source: "bash"}, expected: []string{"bash"}},
+		{pkg: &sbom.Package{Type: sbom.TypeApk, Name: "libcrypto3", Source: "openssl"}, expected: []string{"openssl", "libcrypto3"}},
+		{pkg: &sbom.Package{Type: sbom.TypeRpm, Name: "openssl-libs", Source: "openssl-3.0.7-24.el9.src.rpm"}, expected: []string{"openssl", "openssl-libs"}},
+		{pkg: &sbom.Package{Type: sbom.TypeRpm, Name: "bash", Source: "bash.rpm"}, expected: []string{"bash"}},
+		{pkg: &sbom.Package{Type: sbom.TypeNpm, Name: "lodash", Source: "other"}, expected: []string{"lodash"}},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.expected, packageNames(test.pkg), test.pkg.Name)
+	}
+}
+
+func TestRpmSourceName(t *testing.T) {
+	tt := map[string]string{
+		"openssl-3.0.7-24.el9.src.rpm":    "openssl",
+		"python3.11-3.11.5-1.el9.src.rpm": "python3.11",
+		"xz-libs-5.2.5-8.el9.src.rpm":     "xz-libs",
+		"bash-5.1.src.rpm":                "",
+		"bash.src.rpm":                    "",
+		"bash-5.1.8-6.el9.rpm":            "",
+		"":                                "",
+	}
+
+	for srpm, expected := range tt {
+		assert.Equal(t, expected, rpmSourceName(srpm), srpm)
+	}
+}
+
+func TestApplyEPSS(t *testing.T) {
+	result := &Result{
+		Findings: []*Finding{
+			{ID: "A", Severity: osv.SeverityHigh, CVEs: []string{"CVE-1", "CVE-2"}},
+			{ID: "B", Severity: osv.SeverityHigh, CVEs: []string{"CVE-3"}},
+			{ID: "C", Severity: osv.SeverityCritical},
+		},
+	}
+
+	result.ApplyEPSS(map[string]*epss.Score{
+		"CVE-1": {ScoreData: epss.ScoreData{EPSS: 0.1, Percentile: 0.5}},
+		"CVE-2": {ScoreData: epss.ScoreData{EPSS: 0.3, Percentile: 0.8}},
+		"CVE-3": {ScoreData: epss.ScoreData{EPSS: 0.9, Percentile: 0.99}},
+	})
+
+	require.Len(t, result.Findings, 3)
+	assert.Equal(t, "C", result.Findings[0].ID)
+	assert.Equal(t, "B", result.Findings[1].ID)
+	assert.Equal(t, "A", result.Findings[2].ID)
+	assert.Equal(t, 0.3, result.Findings[2].EPSS)
+	assert.Equal(t, 0.8, result.Findings[2].EPSSPercentile)
+}
+
+func TestCompare(t *testing.T) {
+	libc := &Finding{ID: "DSA-0001-1", PackageType: sbom.TypeDeb, PackageName: "libc6", PackageVersion: "2.36-9"}
+	curl := &Finding{ID: "DSA-0002-1", PackageType: sbom.TypeDeb, PackageName: "curl", PackageVersion: "7.88.1-10"}
+
+	original := &Result{Findings: []*Finding{libc, curl}}
+	minified := &Result{Findings: []*Finding{
+		{ID: "DSA-0001-1", PackageType: sbom.TypeDeb, PackageName: "libc6", PackageVersion: "2.36-9"},
+	}}
+
+	comparison := Compare(original, minified)
+	assert.Equal(t, []*Finding{libc}, comparison.Remaining)
+	assert.Equal(t, []*Finding{curl}, comparison.Removed)
+
+	comparison = Compare(original, nil)
+	assert.Empty(t, comparison.Removed)
+	assert.Empty(t, comparison.Remaining)
+}